@@ -38,6 +38,14 @@ type JSONComparison struct {
 	TinyString  BenchmarkResult
 }
 
+// JSONSizeComparison stores the encoded output size of one corpus entry
+type JSONSizeComparison struct {
+	Corpus        string
+	StandardBytes int64
+	TinyBytes     int64
+	DeltaPercent  float64 // positive when TinyString output is larger
+}
+
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: go run analyzer.go [binary|memory|json|all]")
@@ -113,6 +121,7 @@ func analyzeMemoryAllocations() {
 }
 
 // analyzeJSONOperations analyzes and reports JSON operation comparisons
+// Exits with status 1 when the encoded size guard fails so CI can gate on it
 func analyzeJSONOperations() {
 	LogStep("Starting JSON operations benchmark...")
 
@@ -122,6 +131,22 @@ func analyzeJSONOperations() {
 		return
 	}
 
+	// Encoded size guard runs first, independent of the benchmark results
+	sizes, sizeErr := runJSONSizeCheck()
+	if len(sizes) > 0 {
+		displayJSONSizeResults(sizes)
+	}
+
+	analyzeJSONBenchmarks(sizes)
+
+	if sizeErr != nil {
+		LogError(fmt.Sprintf("Encoded size guard failed: %v", sizeErr))
+		os.Exit(1)
+	}
+}
+
+// analyzeJSONBenchmarks runs the JSON benchmarks and updates the README section
+func analyzeJSONBenchmarks(sizes []JSONSizeComparison) {
 	// Run JSON benchmarks
 	comparisons, err := runJSONBenchmarks()
 	if err != nil {
@@ -138,7 +163,7 @@ func analyzeJSONOperations() {
 	displayJSONResults(comparisons)
 
 	// Update README
-	updateREADMEWithJSONData(comparisons)
+	updateREADMEWithJSONData(comparisons, sizes)
 
 	LogSuccess("JSON benchmark completed and README updated")
 }
@@ -480,9 +505,9 @@ func updateREADMEWithMemoryData(comparisons []MemoryComparison) {
 }
 
 // updateREADMEWithJSONData actualiza el README con los resultados de los benchmarks JSON
-func updateREADMEWithJSONData(comparisons []JSONComparison) error {
+func updateREADMEWithJSONData(comparisons []JSONComparison, sizes []JSONSizeComparison) error {
 	reporter := NewReportGenerator("README.md")
-	err := reporter.UpdateJSONData(comparisons)
+	err := reporter.UpdateJSONData(comparisons, sizes)
 	if err != nil {
 		return fmt.Errorf("failed to update README with JSON data: %v", err)
 	}
@@ -613,3 +638,52 @@ func getJSONBatchSize(name string) int {
 	size, _ := strconv.Atoi(matches[1])
 	return size
 }
+
+// runJSONSizeCheck runs the encoded size guard test in the library root and
+// parses its SIZE lines; the error is non-nil when any corpus exceeds the limit
+func runJSONSizeCheck() ([]JSONSizeComparison, error) {
+	LogInfo("Comparing encoded output size against encoding/json...")
+
+	cmd := exec.Command("go", "test", "-run", "TestJsonEncodedSizeDelta", "-count=1", "-v", ".")
+	cmd.Dir = ".."
+	output, runErr := cmd.CombinedOutput()
+
+	sizes := parseJSONSizeOutput(string(output))
+	if runErr != nil {
+		if len(sizes) == 0 {
+			return nil, fmt.Errorf("error running size check: %v\n%s", runErr, output)
+		}
+		return sizes, fmt.Errorf("output exceeds the allowed delta (set TINYWODP_SIZE_DELTA to adjust)")
+	}
+	return sizes, nil
+}
+
+// parseJSONSizeOutput extracts size comparisons from the size guard test log
+func parseJSONSizeOutput(output string) []JSONSizeComparison {
+	re := regexp.MustCompile(`SIZE corpus=(\S+) standard=(\d+) tinywodp=(\d+) delta=(-?[\d.]+)%`)
+
+	var sizes []JSONSizeComparison
+	for _, matches := range re.FindAllStringSubmatch(output, -1) {
+		standard, _ := strconv.ParseInt(matches[2], 10, 64)
+		tiny, _ := strconv.ParseInt(matches[3], 10, 64)
+		delta, _ := strconv.ParseFloat(matches[4], 64)
+		sizes = append(sizes, JSONSizeComparison{
+			Corpus:        matches[1],
+			StandardBytes: standard,
+			TinyBytes:     tiny,
+			DeltaPercent:  delta,
+		})
+	}
+	return sizes
+}
+
+// displayJSONSizeResults shows the encoded size comparison
+func displayJSONSizeResults(sizes []JSONSizeComparison) {
+	fmt.Println("\nJSON Encoded Size (vs encoding/json):")
+	fmt.Println("=====================================")
+
+	for _, s := range sizes {
+		fmt.Printf("  %-20s Standard: %8d B  TinyString: %8d B  Delta: %+.2f%%\n",
+			s.Corpus, s.StandardBytes, s.TinyBytes, s.DeltaPercent)
+	}
+}
@@ -48,10 +48,10 @@ func (r *ReportGenerator) UpdateMemoryData(comparisons []MemoryComparison) error
 }
 
 // UpdateREADMEWithJSONData updates README with JSON benchmark data
-func (r *ReportGenerator) UpdateJSONData(comparisons []JSONComparison) error {
+func (r *ReportGenerator) UpdateJSONData(comparisons []JSONComparison, sizes []JSONSizeComparison) error {
 	LogInfo("Updating README with JSON benchmark analysis...")
 
-	content, err := r.generateJSONSection(comparisons)
+	content, err := r.generateJSONSection(comparisons, sizes)
 	if err != nil {
 		return fmt.Errorf("failed to generate JSON section: %v", err)
 	}
@@ -301,7 +301,7 @@ func (r *ReportGenerator) generateMemorySection(comparisons []MemoryComparison)
 }
 
 // generateJSONSection creates the JSON performance comparison section
-func (r *ReportGenerator) generateJSONSection(comparisons []JSONComparison) (string, error) {
+func (r *ReportGenerator) generateJSONSection(comparisons []JSONComparison, sizes []JSONSizeComparison) (string, error) {
 	var content strings.Builder
 
 	content.WriteString("## 🔄 JSON Performance Comparison\n\n")
@@ -380,6 +380,17 @@ func (r *ReportGenerator) generateJSONSection(comparisons []JSONComparison) (str
 		content.WriteString(fmt.Sprintf("- ⚡ **Speed**: %.1f%% %s\n\n", abs(avgSpeed), getChangeIndicator(avgSpeed)))
 	}
 
+	if len(sizes) > 0 {
+		content.WriteString("#### 📏 Encoded Output Size\n")
+		content.WriteString("| 📄 Corpus | 📚 Standard | 🪶 TinyString | 📐 Delta |\n")
+		content.WriteString("|-----------|-------------|---------------|----------|\n")
+		for _, s := range sizes {
+			content.WriteString(fmt.Sprintf("| %s | %s | %s | %+.2f%% |\n",
+				s.Corpus, formatBytes(s.StandardBytes), formatBytes(s.TinyBytes), s.DeltaPercent))
+		}
+		content.WriteString("\n")
+	}
+
 	content.WriteString("#### 🎯 Performance Legend\n")
 	content.WriteString("- 🏆 Outstanding (>30% better)\n")
 	content.WriteString("- ✅ Good (10-30% better)\n")
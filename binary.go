@@ -0,0 +1,466 @@
+package tinywodp
+
+import (
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Binary struct codec
+// The smallest payloads between two tinywodp endpoints that share their struct
+// definitions, e.g. a WASM client and its Go server. No names or type tags are
+// written: values follow the declaration order of the fields, so both sides must
+// use the same types. The layout is fixed and little-endian:
+//
+//	bool, int8, uint8                 1 byte
+//	int16, uint16                     2 bytes
+//	int32, uint32, float32            4 bytes
+//	int, uint, int64, uint64, float64 8 bytes, the same on 32- and 64-bit targets
+//	string, []byte                    uvarint length, then the bytes
+//	slice                             uvarint length, then the elements
+//	array                             the elements
+//	struct                            the fields in order
+//	pointer                           0 for nil, or 1 then the value
+//	time.Time                         int64 Unix seconds, then int32 nanoseconds
+//
+// Maps, interfaces and secure fields are not supported.
+
+// BinEncode converts the current value to the fixed binary layout
+//
+// Usage patterns:
+//
+//	data, err := Convert(&state).BinEncode()
+//	_, err := Convert(&state).BinEncode(conn)
+//
+// Like JsonEncode, it returns a copy of the output, or writes it to the
+// optional writer and returns nil bytes
+func (c *refValue) BinEncode(w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	return jh.encodeTo(c, w, jh.appendBin)
+}
+
+// appendBin appends the binary layout of c to dst
+func (jh *jsonH) appendBin(dst []byte, c *refValue) ([]byte, error) {
+	switch c.vTpe {
+	case tpString:
+		return appendBinString(dst, c.getString()), nil
+	case tpBool:
+		return appendBinBool(dst, c.getBool()), nil
+	case tpStrSlice:
+		dst = appendUvarint(dst, uint64(len(c.stringSliceVal)))
+		for _, s := range c.stringSliceVal {
+			dst = appendBinString(dst, s)
+		}
+		return dst, nil
+	}
+	return jh.appendBinValue(dst, c)
+}
+
+// appendBinValue appends a value reached through reflection
+func (jh *jsonH) appendBinValue(dst []byte, v *refValue) ([]byte, error) {
+	if isTimeType(v) {
+		t, _ := v.Interface().(time.Time)
+		dst = appendUint64LE(dst, uint64(t.Unix()))
+		return appendUint32LE(dst, uint32(t.Nanosecond())), nil
+	}
+
+	switch v.refKind() {
+	case tpString:
+		return appendBinString(dst, v.refString()), nil
+	case tpBool:
+		return appendBinBool(dst, v.refBool()), nil
+	case tpInt8:
+		return append(dst, byte(v.refInt())), nil
+	case tpUint8:
+		return append(dst, byte(v.refUint())), nil
+	case tpInt16:
+		u := uint16(v.refInt())
+		return append(dst, byte(u), byte(u>>8)), nil
+	case tpUint16:
+		u := uint16(v.refUint())
+		return append(dst, byte(u), byte(u>>8)), nil
+	case tpInt32:
+		return appendUint32LE(dst, uint32(v.refInt())), nil
+	case tpUint32:
+		return appendUint32LE(dst, uint32(v.refUint())), nil
+	case tpInt, tpInt64:
+		return appendUint64LE(dst, uint64(v.refInt())), nil
+	case tpUint, tpUint64:
+		return appendUint64LE(dst, v.refUint()), nil
+	case tpFloat32:
+		f := float32(v.refFloat())
+		return appendUint32LE(dst, *(*uint32)(unsafe.Pointer(&f))), nil
+	case tpFloat64:
+		f := v.refFloat()
+		return appendUint64LE(dst, *(*uint64)(unsafe.Pointer(&f))), nil
+	case tpSlice:
+		if isJsonByteSlice(v) {
+			var b []byte
+			if v.ptr != nil {
+				b = *(*[]byte)(v.ptr)
+			}
+			dst = appendUvarint(dst, uint64(len(b)))
+			return append(dst, b...), nil
+		}
+		dst = appendUvarint(dst, uint64(v.refLen()))
+		return jh.appendBinElements(dst, v)
+	case tpArray:
+		return jh.appendBinElements(dst, v)
+	case tpStruct:
+		return jh.appendBinStruct(dst, v)
+	case tpPointer:
+		elem := v.refElem()
+		if !elem.refIsValid() {
+			return append(dst, 0), nil
+		}
+		return jh.appendBinValue(append(dst, 1), elem)
+	}
+	return dst, unsupportedKindError("for binary encoding", v.refKind())
+}
+
+// appendBinElements appends the elements of a slice or array
+func (jh *jsonH) appendBinElements(dst []byte, v *refValue) ([]byte, error) {
+	var err error
+	for i := range v.refLen() {
+		if dst, err = jh.appendBinValue(dst, v.refIndex(i)); err != nil {
+			return dst, prefixKindPath(err, indexSegment(i))
+		}
+	}
+	return dst, nil
+}
+
+// appendBinStruct appends the fields of a struct in declaration order
+func (jh *jsonH) appendBinStruct(dst []byte, v *refValue) ([]byte, error) {
+	var structInfo refStructType
+	if err := structMetadataFor(v, &structInfo); err != nil {
+		return dst, err
+	}
+	var err error
+	for i := range v.refNumField() {
+		if !isExportedField(structInfo.fields[i].name) {
+			continue
+		}
+		if isEncryptedField(structInfo.fields[i].tag.Get("secure")) {
+			return dst, Err(errUnsupportedType, "secure field "+structInfo.fields[i].name+" for binary encoding")
+		}
+		if dst, err = jh.appendBinValue(dst, v.refField(i)); err != nil {
+			if _, ok := err.(*kindError); ok {
+				return dst, prefixKindPath(err, structInfo.fields[i].name)
+			}
+			return dst, Err(err.Error(), "in field", structInfo.fields[i].name)
+		}
+	}
+	return dst, nil
+}
+
+// appendBinString appends a length-prefixed string
+func appendBinString(dst []byte, s string) []byte {
+	dst = appendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+// appendBinBool appends a bool as one byte
+func appendBinBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
+// appendUvarint appends v in 7-bit groups, low first, with the high bit set on
+// all but the last byte
+func appendUvarint(dst []byte, v uint64) []byte {
+	for v >= 0x80 {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+// appendUint32LE appends v little-endian
+func appendUint32LE(dst []byte, v uint32) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// appendUint64LE appends v little-endian
+func appendUint64LE(dst []byte, v uint64) []byte {
+	return appendUint32LE(appendUint32LE(dst, uint32(v)), uint32(v>>32))
+}
+
+// BinDecode decodes data written by BinEncode into target, which must point to
+// a value of the type that was encoded
+//
+// Usage pattern:
+//
+//	err := Convert(data).BinDecode(&state)
+func (c *refValue) BinDecode(target any) error {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+
+	if target == nil {
+		return Err(errInvalidBinary, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return Err(errInvalidBinary, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return Err(errInvalidBinary, "target pointer is nil or invalid")
+	}
+
+	d := binDecoder{jh: jh, s: c.getString()}
+	if err := d.decodeValue(elem); err != nil {
+		return err
+	}
+	if d.pos < len(d.s) {
+		return Err(errInvalidBinary, "unexpected data after value at offset", Convert(d.pos).String())
+	}
+	return nil
+}
+
+// binDecoder reads the fixed binary layout from s
+type binDecoder struct {
+	jh  *jsonH // depth tracking
+	s   string
+	pos int // next byte to read
+}
+
+// take returns the next n bytes
+func (d *binDecoder) take(n uint64) (string, error) {
+	if n > uint64(len(d.s)-d.pos) {
+		return "", Err(errInvalidBinary, "unexpected end of input at offset", Convert(d.pos).String())
+	}
+	b := d.s[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// readUint reads an n-byte little-endian unsigned integer
+func (d *binDecoder) readUint(n uint64) (uint64, error) {
+	b, err := d.take(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, nil
+}
+
+// readLength reads a uvarint length that cannot exceed the bytes left
+func (d *binDecoder) readLength() (int, error) {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if d.pos >= len(d.s) {
+			return 0, Err(errInvalidBinary, "unexpected end of input at offset", Convert(d.pos).String())
+		}
+		b := d.s[d.pos]
+		d.pos++
+		if shift == 63 && b > 1 {
+			return 0, Err(errInvalidBinary, "length overflows uint64")
+		}
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+	}
+	if v > uint64(len(d.s)-d.pos) {
+		return 0, Err(errInvalidBinary, "length "+Convert(v).String()+" exceeds the remaining input")
+	}
+	return int(v), nil
+}
+
+// decodeValue decodes the next value into target
+func (d *binDecoder) decodeValue(target *refValue) error {
+	if isTimeType(target) {
+		sec, err := d.readUint(8)
+		if err != nil {
+			return err
+		}
+		nsec, err := d.readUint(4)
+		if err != nil {
+			return err
+		}
+		if nsec >= 1e9 {
+			return Err(errInvalidBinary, "invalid nanoseconds "+Convert(nsec).String())
+		}
+		*(*time.Time)(target.ptr) = time.Unix(int64(sec), int64(nsec))
+		return nil
+	}
+
+	switch target.refKind() {
+	case tpString:
+		n, err := d.readLength()
+		if err != nil {
+			return err
+		}
+		s, _ := d.take(uint64(n))
+		target.refSetString(s)
+		return nil
+	case tpBool:
+		b, err := d.readUint(1)
+		if err != nil {
+			return err
+		}
+		if b > 1 {
+			return Err(errInvalidBinary, "invalid bool byte "+Convert(b).String())
+		}
+		target.refSetBool(b == 1)
+		return nil
+	case tpInt8, tpUint8, tpInt16, tpUint16, tpInt32, tpUint32:
+		return d.decodeSmallInt(target)
+	case tpFloat32:
+		v, err := d.readUint(4)
+		bits := uint32(v)
+		target.refSetFloat(float64(*(*float32)(unsafe.Pointer(&bits))))
+		return err
+	case tpInt, tpInt64:
+		v, err := d.readUint(8)
+		if err == nil {
+			if err = checkIntRange(int64(v), Convert(int64(v)).String(), target); err == nil {
+				target.refSetInt(int64(v))
+			}
+		}
+		return err
+	case tpUint, tpUint64:
+		v, err := d.readUint(8)
+		if err == nil {
+			if err = checkUintRange(v, Convert(v).String(), target); err == nil {
+				target.refSetUint(v)
+			}
+		}
+		return err
+	case tpFloat64:
+		v, err := d.readUint(8)
+		target.refSetFloat(*(*float64)(unsafe.Pointer(&v)))
+		return err
+	case tpSlice:
+		return d.decodeSlice(target)
+	case tpArray:
+		return d.decodeElements(target)
+	case tpStruct:
+		return d.decodeStruct(target)
+	case tpPointer:
+		return d.decodePointer(target)
+	}
+	return unsupportedKindError("for binary decoding", target.refKind())
+}
+
+// decodeSmallInt decodes an integer narrower than 64 bits, sign-extending the
+// signed kinds
+func (d *binDecoder) decodeSmallInt(target *refValue) error {
+	size := uint64(1)
+	switch target.refKind() {
+	case tpInt16, tpUint16:
+		size = 2
+	case tpInt32, tpUint32:
+		size = 4
+	}
+	v, err := d.readUint(size)
+	if err != nil {
+		return err
+	}
+	switch target.refKind() {
+	case tpInt8:
+		target.refSetInt(int64(int8(v)))
+	case tpInt16:
+		target.refSetInt(int64(int16(v)))
+	case tpInt32:
+		target.refSetInt(int64(int32(v)))
+	default:
+		target.refSetUint(v)
+	}
+	return nil
+}
+
+// decodeSlice decodes a length-prefixed slice, replacing target
+func (d *binDecoder) decodeSlice(target *refValue) error {
+	n, err := d.readLength()
+	if err != nil {
+		return err
+	}
+	if isJsonByteSlice(target) {
+		s, _ := d.take(uint64(n))
+		// Named byte slice types share the layout of []byte
+		*(*[]byte)(target.ptr) = []byte(s)
+		return nil
+	}
+	target.refSet(refMakeSlice(target.Type(), n, n))
+	return d.decodeElements(target)
+}
+
+// decodeElements decodes every element of a slice or array in order
+func (d *binDecoder) decodeElements(target *refValue) error {
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+	for i := range target.refLen() {
+		if err := d.decodeValue(target.refIndex(i)); err != nil {
+			return prefixKindPath(err, indexSegment(i))
+		}
+	}
+	return nil
+}
+
+// decodeStruct decodes the fields of a struct in declaration order
+func (d *binDecoder) decodeStruct(target *refValue) error {
+	var structInfo refStructType
+	if err := structMetadataFor(target, &structInfo); err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+	for i := range target.refNumField() {
+		if !isExportedField(structInfo.fields[i].name) {
+			continue
+		}
+		if isEncryptedField(structInfo.fields[i].tag.Get("secure")) {
+			return Err(errUnsupportedType, "secure field "+structInfo.fields[i].name+" for binary decoding")
+		}
+		if err := d.decodeValue(target.refField(i)); err != nil {
+			if _, ok := err.(*kindError); ok {
+				return prefixKindPath(err, structInfo.fields[i].name)
+			}
+			return Err(err.Error(), "in field", structInfo.fields[i].name)
+		}
+	}
+	return nil
+}
+
+// decodePointer reads the presence byte, then the value into the pointed-to
+// element, allocating it if needed
+func (d *binDecoder) decodePointer(target *refValue) error {
+	present, err := d.readUint(1)
+	switch {
+	case err != nil:
+		return err
+	case present > 1:
+		return Err(errInvalidBinary, "invalid pointer flag "+Convert(present).String())
+	case present == 0:
+		memclr(target.ptr, target.Type().Size())
+		return nil
+	}
+	if elem := target.refElem(); elem.refIsValid() {
+		return d.decodeValue(elem)
+	}
+	elemType := target.Type().Elem()
+	if elemType == nil {
+		return Err(errUnsupportedType, "pointer element type is nil")
+	}
+	elem, err := refNewValue(elemType)
+	if err != nil {
+		return err
+	}
+	if err := d.decodeValue(elem); err != nil {
+		return err
+	}
+	*(*unsafe.Pointer)(target.ptr) = elem.ptr
+	return nil
+}
@@ -0,0 +1,129 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestBinEncodeLayout(t *testing.T) {
+	clearRefStructsCache()
+
+	type Point struct {
+		X     int16
+		Y     uint32
+		Ok    bool
+		Label string
+		Next  *Point
+	}
+
+	out, err := Convert(&Point{X: -2, Y: 0x01020304, Ok: true, Label: "ab"}).BinEncode()
+	if err != nil {
+		t.Fatalf("BinEncode returned error: %v", err)
+	}
+	expected := "\xfe\xff" + "\x04\x03\x02\x01" + "\x01" + "\x02ab" + "\x00"
+	if string(out) != expected {
+		t.Errorf("BinEncode = % x, expected % x", out, expected)
+	}
+
+	if out, err := Convert([]string{"a", ""}).BinEncode(); err != nil || string(out) != "\x02\x01a\x00" {
+		t.Errorf("BinEncode([]string) = % x, %v", out, err)
+	}
+	if out, err := Convert(int64(300)).BinEncode(); err != nil || string(out) != "\x2c\x01\x00\x00\x00\x00\x00\x00" {
+		t.Errorf("BinEncode(int64) = % x, %v", out, err)
+	}
+	long := make([]byte, 200)
+	if out, err := Convert(long).BinEncode(); err != nil || len(out) != 202 || out[0] != 0xc8 || out[1] != 0x01 {
+		t.Errorf("BinEncode([]byte) length prefix = % x, %v", out[:2], err)
+	}
+
+	type Bad struct {
+		M map[string]int
+	}
+	if _, err := Convert(&Bad{}).BinEncode(); err == nil || !Contains(err.Error(), "M") {
+		t.Errorf("BinEncode(map field) error = %v", err)
+	}
+}
+
+func TestBinRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	type Child struct {
+		Name  string
+		Score float32
+	}
+	type State struct {
+		ID       int
+		Small    int8
+		Counter  uint64
+		Ratio    float64
+		Tags     []string
+		Raw      []byte
+		Grid     [2][2]uint8
+		Children []Child
+		Parent   *Child
+		Missing  *Child
+		Updated  time.Time
+	}
+
+	in := State{
+		ID: -1 << 40, Small: -128, Counter: 1<<64 - 1, Ratio: 0.1, Tags: []string{"x", "héllo"},
+		Raw: []byte{0, 1, 2}, Grid: [2][2]uint8{{1, 2}, {3, 255}},
+		Children: []Child{{Name: "a", Score: 1.5}, {Name: "b", Score: -2}},
+		Parent:   &Child{Name: "p"}, Updated: time.Unix(-5, 123456789),
+	}
+	data, err := Convert(&in).BinEncode()
+	if err != nil {
+		t.Fatalf("BinEncode returned error: %v", err)
+	}
+
+	out := State{Missing: &Child{Name: "stale"}}
+	if err := Convert(data).BinDecode(&out); err != nil {
+		t.Fatalf("BinDecode returned error: %v", err)
+	}
+	if out.ID != in.ID || out.Small != in.Small || out.Counter != in.Counter || out.Ratio != in.Ratio ||
+		len(out.Tags) != 2 || out.Tags[1] != "héllo" || string(out.Raw) != string(in.Raw) || out.Grid != in.Grid {
+		t.Errorf("BinDecode = %+v, expected %+v", out, in)
+	}
+	if len(out.Children) != 2 || out.Children[1] != in.Children[1] || out.Parent == nil || out.Parent.Name != "p" {
+		t.Errorf("BinDecode nested = %+v %+v", out.Children, out.Parent)
+	}
+	if out.Missing != nil || !out.Updated.Equal(in.Updated) {
+		t.Errorf("BinDecode Missing = %+v, Updated = %v", out.Missing, out.Updated)
+	}
+
+	// Every truncation of valid data is rejected, as is data left over
+	for n := 0; n < len(data); n++ {
+		var partial State
+		if err := Convert(data[:n]).BinDecode(&partial); err == nil {
+			t.Fatalf("BinDecode of %d of %d bytes should fail", n, len(data))
+		}
+	}
+	var extra State
+	if err := Convert(append(data, 0)).BinDecode(&extra); err == nil {
+		t.Error("BinDecode with trailing data should fail")
+	}
+}
+
+func TestBinDecodeErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	tests := []struct {
+		name   string
+		input  string
+		target any
+	}{
+		{"bool byte", "\x02", new(bool)},
+		{"pointer flag", "\x02\x00", new(*int8)},
+		{"length past end", "\x05ab", new(string)},
+		{"varint overflow", "\xff\xff\xff\xff\xff\xff\xff\xff\xff\x7f", new([]byte)},
+		{"nanoseconds", "\x00\x00\x00\x00\x00\x00\x00\x00\x00\xca\x9a\x3b", new(time.Time)},
+		{"not a pointer", "\x00", false},
+	}
+	for _, tt := range tests {
+		if err := Convert(tt.input).BinDecode(tt.target); err == nil {
+			t.Errorf("%s: BinDecode should fail", tt.name)
+		}
+	}
+}
@@ -0,0 +1,784 @@
+package tinywodp
+
+import (
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// CBOR decoding (RFC 8949)
+// Walks the input once, writing straight into the target through the
+// reflection core. Struct fields are found with the JSON decode plans, so keys
+// match json tags, Go names and the snake_case/camelCase forms, and the decode
+// options apply. Definite and indefinite lengths are both accepted. Tags 0 and 1
+// decode into time.Time; other tags are ignored and their content decoded as
+// is. Decoding into any gives nil, bool, int64 (uint64 above the int64 range),
+// float64, string, []byte, time.Time, []any and map[string]any.
+
+// CborDecode decodes the CBOR data held by the current value into target
+//
+// Usage pattern:
+//
+//	err := Convert(attestation).CborDecode(&object)
+func (c *refValue) CborDecode(target any, opts ...DecodeOption) error {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	data := c.getString()
+	if len(data) == 0 {
+		return Err(ErrEmptyInput)
+	}
+	if target == nil {
+		return Err(errInvalidCbor, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return Err(errInvalidCbor, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return Err(errInvalidCbor, "target pointer is nil or invalid")
+	}
+
+	d := cborDecoder{jh: jh, s: data}
+	if err := d.decodeValue(elem); err != nil {
+		return d.errorAt(err)
+	}
+	if d.pos < len(d.s) {
+		d.at = d.pos
+		return d.errorAt(Err(errInvalidCbor, "unexpected data after CBOR value"))
+	}
+	return nil
+}
+
+// cborDecoder reads one CBOR data item from s
+type cborDecoder struct {
+	jh  *jsonH // decode settings and depth tracking
+	s   string
+	pos int // next byte to read
+	at  int // start of the item being decoded, for error offsets
+}
+
+// cborHead is the initial byte of a data item and its argument
+type cborHead struct {
+	major      byte   // major type, in the high bits like the cbor* constants
+	info       byte   // additional information, the low 5 bits
+	arg        uint64 // length, count, value or tag number
+	indefinite bool   // length is terminated by a break
+}
+
+// errorAt adds the offset of the failing item to err
+func (d *cborDecoder) errorAt(err error) error {
+	return Err(err.Error(), "at offset", Convert(d.at).String())
+}
+
+// truncated is returned when the input ends inside an item
+func (d *cborDecoder) truncated() error {
+	return Err(errInvalidCbor, "unexpected end of CBOR input")
+}
+
+// take returns the next n bytes
+func (d *cborDecoder) take(n uint64) (string, error) {
+	if n > uint64(len(d.s)-d.pos) {
+		return "", d.truncated()
+	}
+	b := d.s[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// peek returns the next initial byte without consuming it
+func (d *cborDecoder) peek() (byte, error) {
+	if d.pos >= len(d.s) {
+		return 0, d.truncated()
+	}
+	return d.s[d.pos], nil
+}
+
+// readHead reads an initial byte and the argument that follows it
+func (d *cborDecoder) readHead() (cborHead, error) {
+	b, err := d.peek()
+	if err != nil {
+		return cborHead{}, err
+	}
+	d.pos++
+	h := cborHead{major: b & 0xe0, info: b & 0x1f}
+	switch {
+	case h.info < 24:
+		h.arg = uint64(h.info)
+	case h.info <= 27:
+		raw, err := d.take(1 << (h.info - 24))
+		if err != nil {
+			return h, err
+		}
+		for i := 0; i < len(raw); i++ {
+			h.arg = h.arg<<8 | uint64(raw[i])
+		}
+	case h.info == cborIndefinite:
+		switch h.major {
+		case cborBytes, cborText, cborArray, cborMap:
+			h.indefinite = true
+		case cborSimple:
+			return h, Err(errInvalidCbor, "unexpected break")
+		default:
+			return h, Err(errInvalidCbor, "indefinite length not allowed for major type "+Convert(int(h.major>>5)).String())
+		}
+	default:
+		return h, Err(errInvalidCbor, "reserved additional information "+Convert(int(h.info)).String())
+	}
+	return h, nil
+}
+
+// atBreak consumes the break that ends an indefinite-length item
+func (d *cborDecoder) atBreak() (bool, error) {
+	b, err := d.peek()
+	if err != nil || b != cborBreak {
+		return false, err
+	}
+	d.pos++
+	return true, nil
+}
+
+// readCount validates the item count of an array or map head; a definite
+// count cannot exceed the bytes left since every item takes at least one
+func (d *cborDecoder) readCount(h cborHead) (int, error) {
+	if h.indefinite {
+		return -1, nil
+	}
+	if h.arg > uint64(len(d.s)-d.pos) {
+		return 0, d.truncated()
+	}
+	return int(h.arg), nil
+}
+
+// more reports whether item i of a container of count items (-1 when
+// indefinite) follows
+func (d *cborDecoder) more(i, count int) (bool, error) {
+	if count >= 0 {
+		return i < count, nil
+	}
+	done, err := d.atBreak()
+	return !done && err == nil, err
+}
+
+// readString reads the content of a byte or text string whose head is h,
+// joining the chunks of an indefinite-length string
+func (d *cborDecoder) readString(h cborHead) (string, error) {
+	if !h.indefinite {
+		return d.take(h.arg)
+	}
+	var joined []byte
+	for {
+		done, err := d.atBreak()
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return string(joined), nil
+		}
+		chunk, err := d.readHead()
+		if err != nil {
+			return "", err
+		}
+		if chunk.major != h.major || chunk.indefinite {
+			return "", Err(errInvalidCbor, "invalid chunk in indefinite-length string")
+		}
+		s, err := d.take(chunk.arg)
+		if err != nil {
+			return "", err
+		}
+		joined = append(joined, s...)
+	}
+}
+
+// readNumber converts an integer or float head into a number, false when h
+// is neither
+func (d *cborDecoder) readNumber(h cborHead) (binaryNumber, bool, error) {
+	switch {
+	case h.major == cborUint:
+		return binaryNumber{kind: 'u', u: h.arg}, true, nil
+	case h.major == cborNegint:
+		if h.arg > 1<<63-1 {
+			return binaryNumber{}, true, Err(errInvalidCbor, "negative integer below the int64 range")
+		}
+		return binaryNumber{kind: 'i', i: -1 - int64(h.arg)}, true, nil
+	case h.major != cborSimple:
+		return binaryNumber{}, false, nil
+	}
+	switch h.info {
+	case 25:
+		return binaryNumber{kind: 'f', f: cborHalfFloat(uint16(h.arg))}, true, nil
+	case 26:
+		bits := uint32(h.arg)
+		return binaryNumber{kind: 'f', f: float64(*(*float32)(unsafe.Pointer(&bits)))}, true, nil
+	case 27:
+		return binaryNumber{kind: 'f', f: *(*float64)(unsafe.Pointer(&h.arg))}, true, nil
+	}
+	return binaryNumber{}, false, nil
+}
+
+// cborHalfFloat expands an IEEE 754 half-precision float
+func cborHalfFloat(h uint16) float64 {
+	exp, mant := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = mant / (1 << 24) // subnormal: mant * 2^-24
+	case 0x1f:
+		bits := uint64(0x7ff0000000000000) // +Inf
+		if mant != 0 {
+			bits |= 1 << 51 // quiet NaN
+		}
+		f = *(*float64)(unsafe.Pointer(&bits))
+	default:
+		f = 1 + mant/(1<<10)
+		for ; exp > 15; exp-- {
+			f *= 2
+		}
+		for ; exp < 15; exp++ {
+			f /= 2
+		}
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// skipValue moves past the next item
+func (d *cborDecoder) skipValue() error {
+	h, err := d.readHead()
+	if err != nil {
+		return err
+	}
+	switch h.major {
+	case cborBytes, cborText:
+		_, err = d.readString(h)
+		return err
+	case cborTag:
+		// Nested tags count against the depth limit like containers do
+		if err := d.jh.enterJsonDepth(); err != nil {
+			return err
+		}
+		defer d.jh.leaveJsonDepth()
+		return d.skipValue()
+	case cborArray, cborMap:
+		count, err := d.readCount(h)
+		if err != nil {
+			return err
+		}
+		if err := d.jh.enterJsonDepth(); err != nil {
+			return err
+		}
+		defer d.jh.leaveJsonDepth()
+		for i := 0; ; i++ {
+			more, err := d.more(i, count)
+			if err != nil || !more {
+				return err
+			}
+			if h.major == cborMap {
+				if err := d.skipValue(); err != nil {
+					return err
+				}
+			}
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes the next item into target
+func (d *cborDecoder) decodeValue(target *refValue) error {
+	b, err := d.peek()
+	if err != nil {
+		return err
+	}
+	d.at = d.pos
+	if b == cborNull || b == cborUndef {
+		d.pos++
+		// null clears nillable targets like JSON null and leaves the rest untouched
+		d.jh.decodeJsonNull("null", target)
+		return nil
+	}
+
+	if target.refKind() == tpPointer {
+		return d.decodePointer(target)
+	}
+	if target.refKind() == tpInterface && !target.Type().isEmptyInterface() {
+		if pointee := target.refInterfacePointee(); pointee != nil {
+			return d.decodeValue(pointee)
+		}
+		return Err(errUnsupportedType, "for CBOR decoding: only empty interface targets (any) or interfaces holding a pointer are supported")
+	}
+
+	h, err := d.readHead()
+	if err != nil {
+		return err
+	}
+	if isTimeType(target) {
+		t, err := d.decodeTime(h)
+		if err == nil {
+			*(*time.Time)(target.ptr) = t
+		}
+		return err
+	}
+	if h.major == cborTag {
+		// Tags only refine the meaning of their content
+		if err := d.jh.enterJsonDepth(); err != nil {
+			return err
+		}
+		defer d.jh.leaveJsonDepth()
+		return d.decodeValue(target)
+	}
+
+	switch target.refKind() {
+	case tpInterface:
+		v, err := d.decodeGeneric(h)
+		if err != nil {
+			return err
+		}
+		return target.refSetInterface(v)
+	case tpString:
+		if h.major != cborText && h.major != cborBytes {
+			return d.mismatch(h, target)
+		}
+		s, err := d.readString(h)
+		if err == nil {
+			target.refSetString(s)
+		}
+		return err
+	case tpBool:
+		switch h.major | h.info {
+		case cborTrue:
+			target.refSetBool(true)
+		case cborFalse:
+			target.refSetBool(false)
+		default:
+			return d.mismatch(h, target)
+		}
+		return nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64,
+		tpUint, tpUint8, tpUint16, tpUint32, tpUint64,
+		tpFloat32, tpFloat64:
+		n, ok, err := d.readNumber(h)
+		if !ok {
+			return d.mismatch(h, target)
+		}
+		if err != nil {
+			return err
+		}
+		return setBinaryNumber(n, target, errInvalidCbor)
+	case tpSlice:
+		return d.decodeSlice(h, target)
+	case tpArray:
+		return d.decodeArray(h, target)
+	case tpStruct:
+		return d.decodeStruct(h, target)
+	case tpMap:
+		return d.decodeMap(h, target)
+	}
+	return unsupportedKindError("for CBOR decoding", target.refKind())
+}
+
+// mismatch reports an item whose major type does not suit target
+func (d *cborDecoder) mismatch(h cborHead, target *refValue) error {
+	return Err(errInvalidCbor, "major type "+Convert(int(h.major>>5)).String()+" cannot be decoded into "+target.refKind().String())
+}
+
+// decodePointer decodes into the element of a pointer, allocating it if needed
+func (d *cborDecoder) decodePointer(target *refValue) error {
+	if elem := target.refElem(); elem.refIsValid() {
+		return d.decodeValue(elem)
+	}
+	elemType := target.Type().Elem()
+	if elemType == nil {
+		return Err(errUnsupportedType, "pointer element type is nil")
+	}
+	elem, err := refNewValue(elemType)
+	if err != nil {
+		return err
+	}
+	elem.separator = d.jh.jSep
+	if err := d.decodeValue(elem); err != nil {
+		return err
+	}
+	*(*unsafe.Pointer)(target.ptr) = elem.ptr
+	return nil
+}
+
+// decodeSlice decodes an array, or a byte/text string into []byte, replacing
+// the slice
+func (d *cborDecoder) decodeSlice(h cborHead, target *refValue) error {
+	if isJsonByteSlice(target) && (h.major == cborBytes || h.major == cborText) {
+		s, err := d.readString(h)
+		if err == nil {
+			// Named byte slice types share the layout of []byte
+			*(*[]byte)(target.ptr) = []byte(s)
+		}
+		return err
+	}
+	if h.major != cborArray {
+		return d.mismatch(h, target)
+	}
+	count, err := d.readCount(h)
+	if err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	if count >= 0 {
+		makeJsonSlice(target, count, d.jh.jArena)
+		for i := range count {
+			if err := d.decodeValue(target.refIndex(i)); err != nil {
+				return prefixKindPath(err, indexSegment(i))
+			}
+		}
+		return nil
+	}
+
+	// Indefinite length: grow like the JSON array decoder
+	target.refSet(refMakeSlice(target.Type(), 0, 0))
+	n := 0
+	for {
+		more, err := d.more(n, count)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		if n == target.refLen() {
+			if n == 0 {
+				makeJsonSlice(target, jsonSliceMinCap, d.jh.jArena)
+			} else {
+				growJsonSlice(target, n, d.jh.jArena)
+			}
+		}
+		if err := d.decodeValue(target.refIndex(n)); err != nil {
+			return prefixKindPath(err, indexSegment(n))
+		}
+		n++
+	}
+	if n > 0 {
+		(*jsonSliceHeader)(target.ptr).len = n
+	}
+	return nil
+}
+
+// decodeArray decodes an array into a fixed-size array; like JSON, extra
+// elements are ignored and missing ones are zeroed
+func (d *cborDecoder) decodeArray(h cborHead, target *refValue) error {
+	if h.major != cborArray {
+		return d.mismatch(h, target)
+	}
+	count, err := d.readCount(h)
+	if err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	length := target.refLen()
+	n := 0
+	for ; ; n++ {
+		more, err := d.more(n, count)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		if n >= length {
+			err = d.skipValue()
+		} else {
+			err = d.decodeValue(target.refIndex(n))
+		}
+		if err != nil {
+			return prefixKindPath(err, indexSegment(n))
+		}
+	}
+	for i := n; i < length; i++ {
+		elem := target.refIndex(i)
+		memclr(elem.ptr, elem.Type().Size())
+	}
+	return nil
+}
+
+// decodeStruct decodes a map into the fields matching its text keys; unknown
+// and non-text keys are skipped
+func (d *cborDecoder) decodeStruct(h cborHead, target *refValue) error {
+	if h.major != cborMap {
+		return d.mismatch(h, target)
+	}
+	count, err := d.readCount(h)
+	if err != nil {
+		return err
+	}
+	plan, err := decodePlanFor(target)
+	if err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	for i := 0; ; i++ {
+		more, err := d.more(i, count)
+		if err != nil || !more {
+			return err
+		}
+		keyStart := d.pos
+		kh, err := d.readHead()
+		if err != nil {
+			return err
+		}
+		index := -1
+		if kh.major == cborText {
+			key, err := d.readString(kh)
+			if err != nil {
+				return err
+			}
+			index = plan.fieldIndex(key, d.jh.jMatch)
+		} else {
+			d.pos = keyStart
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+
+		var field *refValue
+		if index != -1 && !plan.fields[index].secure && !plan.fields[index].unexported {
+			field = target.refField(index)
+		}
+		if field == nil || !field.refIsValid() {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(field); err != nil {
+			if _, ok := err.(*kindError); ok {
+				return prefixKindPath(err, plan.fields[index].name)
+			}
+			return Err(errInvalidCbor, "field "+plan.fields[index].name+": "+err.Error())
+		}
+	}
+}
+
+// decodeMap decodes a map into a Go map with string or integer keys
+// A nil map is allocated; an existing map keeps its entries and gains new ones
+func (d *cborDecoder) decodeMap(h cborHead, target *refValue) error {
+	if h.major != cborMap {
+		return d.mismatch(h, target)
+	}
+	count, err := d.readCount(h)
+	if err != nil {
+		return err
+	}
+	mapType := target.Type()
+	keyType, elemType := mapType.mapKey(), mapType.mapElem()
+	if keyType == nil || elemType == nil {
+		return Err(ErrNoReflection, "map type information is missing")
+	}
+	switch keyType.Kind() {
+	case tpString, tpInt, tpInt8, tpInt16, tpInt32, tpInt64, tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+	default:
+		return Err(errUnsupportedType, "map key type: "+keyType.Kind().String())
+	}
+	if target.refMapIsNil() {
+		if err := target.refMakeMap(max(count, 0)); err != nil {
+			return err
+		}
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	for i := 0; ; i++ {
+		more, err := d.more(i, count)
+		if err != nil || !more {
+			return err
+		}
+		key, err := refNewValue(keyType)
+		if err != nil {
+			return err
+		}
+		if err := d.decodeValue(key); err != nil {
+			return err
+		}
+		elem, err := refNewValue(elemType)
+		if err != nil {
+			return err
+		}
+		elem.separator = d.jh.jSep
+		if err := d.decodeValue(elem); err != nil {
+			return err
+		}
+		if err := target.refSetMapIndex(key, elem); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeTime converts the item with head h into a time: an RFC 3339 string
+// (tag 0 or untagged) or epoch seconds (tag 1 or an untagged number)
+func (d *cborDecoder) decodeTime(h cborHead) (time.Time, error) {
+	if h.major == cborTag {
+		if h.arg != cborTagDateTime && h.arg != cborTagEpoch {
+			return time.Time{}, Err(errInvalidCbor, "unexpected tag "+Convert(h.arg).String()+" for time.Time")
+		}
+		var err error
+		if h, err = d.readHead(); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if h.major == cborText {
+		s, err := d.readString(h)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return time.Time{}, Err(errInvalidCbor, "invalid date/time: "+s)
+		}
+		return t, nil
+	}
+	n, ok, err := d.readNumber(h)
+	switch {
+	case !ok:
+		return time.Time{}, Err(errInvalidCbor, "expected date/time for time.Time")
+	case err != nil:
+		return time.Time{}, err
+	case n.kind == 'u':
+		return time.Unix(int64(n.u), 0), nil
+	case n.kind == 'i':
+		return time.Unix(n.i, 0), nil
+	}
+	if !(n.f > -1<<63 && n.f < 1<<63) {
+		return time.Time{}, Err(errInvalidCbor, "epoch time out of range: "+n.String())
+	}
+	sec := int64(n.f)
+	return time.Unix(sec, int64((n.f-float64(sec))*1e9)), nil
+}
+
+// decodeGeneric decodes the item with head h into its generic Go representation
+func (d *cborDecoder) decodeGeneric(h cborHead) (any, error) {
+	switch h.major {
+	case cborText:
+		return d.readString(h)
+	case cborBytes:
+		s, err := d.readString(h)
+		return []byte(s), err
+	case cborTag:
+		if h.arg == cborTagDateTime || h.arg == cborTagEpoch {
+			return d.decodeTime(h)
+		}
+		next, err := d.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if err := d.jh.enterJsonDepth(); err != nil {
+			return nil, err
+		}
+		defer d.jh.leaveJsonDepth()
+		return d.decodeGeneric(next)
+	case cborArray, cborMap:
+		return d.decodeGenericContainer(h)
+	}
+
+	switch h.major | h.info {
+	case cborNull, cborUndef:
+		return nil, nil
+	case cborTrue, cborFalse:
+		return h.major|h.info == cborTrue, nil
+	}
+	n, ok, err := d.readNumber(h)
+	switch {
+	case !ok:
+		return nil, Err(errInvalidCbor, "unsupported simple value "+Convert(h.arg).String())
+	case err != nil:
+		return nil, err
+	case n.kind == 'f':
+		return n.f, nil
+	case n.kind == 'u' && n.u > 1<<63-1:
+		return n.u, nil
+	case n.kind == 'u':
+		return int64(n.u), nil
+	}
+	return n.i, nil
+}
+
+// decodeGenericContainer decodes an array into []any or a map with text keys
+// into map[string]any
+func (d *cborDecoder) decodeGenericContainer(h cborHead) (any, error) {
+	count, err := d.readCount(h)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return nil, err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	if h.major == cborArray {
+		arr := make([]any, 0, max(count, 0))
+		for i := 0; ; i++ {
+			more, err := d.more(i, count)
+			if err != nil {
+				return nil, err
+			}
+			if !more {
+				return arr, nil
+			}
+			next, err := d.readHead()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decodeGeneric(next)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	}
+
+	obj := make(map[string]any, max(count, 0))
+	for i := 0; ; i++ {
+		more, err := d.more(i, count)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return obj, nil
+		}
+		kh, err := d.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if kh.major != cborText {
+			return nil, Err(errInvalidCbor, "map keys must be text for any targets")
+		}
+		key, err := d.readString(kh)
+		if err != nil {
+			return nil, err
+		}
+		next, err := d.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if obj[key], err = d.decodeGeneric(next); err != nil {
+			return nil, err
+		}
+	}
+}
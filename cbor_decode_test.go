@@ -0,0 +1,192 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestCborDecodeGeneric(t *testing.T) {
+	clearRefStructsCache()
+
+	// Inputs from RFC 8949 Appendix A, including indefinite-length items
+	tests := []struct {
+		name     string
+		input    string
+		expected any
+	}{
+		{"uint", "\x19\x03\xe8", int64(1000)},
+		{"uint64 max", "\x1b\xff\xff\xff\xff\xff\xff\xff\xff", uint64(1<<64 - 1)},
+		{"negint", "\x39\x03\xe7", int64(-1000)},
+		{"half 1.5", "\xf9\x3e\x00", 1.5},
+		{"half 65504", "\xf9\x7b\xff", 65504.0},
+		{"half subnormal", "\xf9\x00\x01", 5.960464477539063e-8},
+		{"half -4", "\xf9\xc4\x00", -4.0},
+		{"single", "\xfa\x47\xc3\x50\x00", 100000.0},
+		{"true", "\xf5", true},
+		{"null", "\xf6", nil},
+		{"undefined", "\xf7", nil},
+		{"text", "\x64IETF", "IETF"},
+		{"indefinite text", "\x7f\x65strea\x64ming\xff", "streaming"},
+		{"unknown tag", "\xd8\x20\x63abc", "abc"},
+	}
+	for _, tt := range tests {
+		var v any
+		if err := Convert(tt.input).CborDecode(&v); err != nil {
+			t.Errorf("%s: CborDecode returned error: %v", tt.name, err)
+			continue
+		}
+		if v != tt.expected {
+			t.Errorf("%s: CborDecode = %#v, expected %#v", tt.name, v, tt.expected)
+		}
+	}
+
+	var b any
+	if err := Convert("\x5f\x42\x01\x02\x43\x03\x04\x05\xff").CborDecode(&b); err != nil || string(b.([]byte)) != "\x01\x02\x03\x04\x05" {
+		t.Errorf("CborDecode(indefinite bytes) = %#v, %v", b, err)
+	}
+
+	var obj any
+	if err := Convert("\xbf\x61a\x01\x61b\x9f\x02\x03\xff\xff").CborDecode(&obj); err != nil {
+		t.Fatalf("CborDecode(indefinite map) returned error: %v", err)
+	}
+	m, ok := obj.(map[string]any)
+	if !ok || m["a"] != int64(1) {
+		t.Fatalf("CborDecode(indefinite map) = %#v", obj)
+	}
+	if arr, ok := m["b"].([]any); !ok || len(arr) != 2 || arr[1] != int64(3) {
+		t.Errorf("CborDecode(indefinite map)[b] = %#v", m["b"])
+	}
+}
+
+func TestCborDecodeTime(t *testing.T) {
+	expected := time.Unix(1363896240, 0)
+	tests := []string{
+		"\xc0\x742013-03-21T20:04:00Z",
+		"\xc1\x1a\x51\x4b\x67\xb0",
+		"\x1a\x51\x4b\x67\xb0",
+	}
+	for _, input := range tests {
+		var got time.Time
+		if err := Convert(input).CborDecode(&got); err != nil || !got.Equal(expected) {
+			t.Errorf("CborDecode(% x) = %v, %v", input, got, err)
+		}
+	}
+
+	var half time.Time
+	if err := Convert("\xc1\xfb\x41\xd4\x52\xd9\xec\x20\x00\x00").CborDecode(&half); err != nil || !half.Equal(expected.Add(500*time.Millisecond)) {
+		t.Errorf("CborDecode(float epoch) = %v, %v", half, err)
+	}
+}
+
+func TestCborRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	type Reading struct {
+		Sensor string
+		Values []float32
+		Raw    []byte
+		Limits [2]int16
+		Taken  time.Time
+		Next   *Reading
+	}
+
+	in := Reading{
+		Sensor: "t1", Values: []float32{1.25, -3}, Raw: []byte("ok"), Limits: [2]int16{-300, 300},
+		Taken: time.Unix(1700000000, 5).UTC(), Next: &Reading{Sensor: "t2"},
+	}
+	data, err := Convert(&in).CborEncode()
+	if err != nil {
+		t.Fatalf("CborEncode returned error: %v", err)
+	}
+
+	var out Reading
+	if err := Convert(data).CborDecode(&out); err != nil {
+		t.Fatalf("CborDecode returned error: %v", err)
+	}
+	if out.Sensor != "t1" || len(out.Values) != 2 || out.Values[1] != -3 || string(out.Raw) != "ok" ||
+		out.Limits != in.Limits || !out.Taken.Equal(in.Taken) || out.Next == nil || out.Next.Sensor != "t2" {
+		t.Errorf("CborDecode = %+v, expected %+v", out, in)
+	}
+
+	// Indefinite-length arrays grow the slice like JSON arrays do
+	var values []int
+	if err := Convert("\x9f\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\xff").CborDecode(&values); err != nil || len(values) != 10 || values[9] != 10 {
+		t.Errorf("CborDecode(indefinite array) = %v, %v", values, err)
+	}
+
+	// Integer map keys and non-text struct keys
+	var cose map[int]int
+	if err := Convert("\xa2\x01\x02\x20\x01").CborDecode(&cose); err != nil || cose[1] != 2 || cose[-1] != 1 {
+		t.Errorf("CborDecode(map[int]int) = %v, %v", cose, err)
+	}
+	var sensor Reading
+	if err := Convert("\xa2\x01\x02\x66Sensor\x62t3").CborDecode(&sensor); err != nil || sensor.Sensor != "t3" {
+		t.Errorf("CborDecode(int key into struct) = %+v, %v", sensor, err)
+	}
+}
+
+func TestCborDecodeErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	type Small struct {
+		N uint8
+	}
+	tests := []struct {
+		name   string
+		input  string
+		target any
+	}{
+		{"out of range", "\xa1\x61N\x19\x01\x00", &Small{}},
+		{"negative unsigned", "\xa1\x61N\x20", &Small{}},
+		{"type mismatch", "\xa1\x61N\x61x", &Small{}},
+		{"truncated text", "\x65abc", new(string)},
+		{"truncated array", "\x99\xff\xff\x01", new([]int)},
+		{"missing break", "\x9f\x01\x02", new([]int)},
+		{"reserved info", "\x1c", new(int)},
+		{"stray break", "\xff", new(any)},
+		{"bad chunk", "\x7f\x41a\xff", new(string)},
+		{"trailing data", "\x01\x02", new(int)},
+		{"bad date", "\xc0\x63abc", new(time.Time)},
+	}
+	for _, tt := range tests {
+		if err := Convert(tt.input).CborDecode(tt.target); err == nil {
+			t.Errorf("%s: CborDecode should fail", tt.name)
+		}
+	}
+}
+
+func TestCborDecodeNestedTags(t *testing.T) {
+	clearRefStructsCache()
+
+	type Small struct {
+		N uint8
+	}
+	tags := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = 0xc6
+		}
+		return string(b)
+	}
+
+	// A few tags are looked through
+	var n int
+	if err := Convert(tags(3) + "\x05").CborDecode(&n); err != nil || n != 5 {
+		t.Errorf("tagged int = %d, %v", n, err)
+	}
+
+	// A long run of tags hits the depth limit instead of the stack
+	deep := tags(100000) + "\x00"
+	targets := map[string]func() error{
+		"typed":   func() error { return Convert(deep).CborDecode(new(int)) },
+		"generic": func() error { return Convert(deep).CborDecode(new(any)) },
+		"skipped": func() error { return Convert("\xa1\x61x" + deep).CborDecode(&Small{}) },
+	}
+	for name, decode := range targets {
+		if err := decode(); err == nil || !Contains(err.Error(), string(ErrMaxDepth)) {
+			t.Errorf("%s: expected ErrMaxDepth, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package tinywodp
+
+import (
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// CBOR encoding (RFC 8949)
+// The binary format of WebAuthn and most constrained IoT protocols, built on
+// the same reflection core and pooled handler as the JSON and MessagePack
+// encoders. Values map onto CBOR as:
+//
+//	struct          -> map keyed by field name (SetFieldNaming applies)
+//	[]byte          -> byte string; other slices and arrays -> array
+//	integers        -> major type 0/1 with the shortest argument
+//	float32/float64 -> single/double precision; NaN and ±Inf are kept
+//	time.Time       -> tag 0, an RFC 3339 string with nanoseconds when present
+//	nil pointer/any -> null; nil slices follow SetNilSliceEncoding
+//
+// Lengths are always definite. Maps are decode-only, as in the JSON encoder,
+// and secure fields are rejected since sealing is defined over JSON text.
+
+// CBOR major types, already shifted into the high bits of the initial byte
+const (
+	cborUint   = 0 << 5
+	cborNegint = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborFalse   = cborSimple | 20
+	cborTrue    = cborSimple | 21
+	cborNull    = cborSimple | 22
+	cborUndef   = cborSimple | 23
+	cborFloat16 = cborSimple | 25
+	cborFloat32 = cborSimple | 26
+	cborFloat64 = cborSimple | 27
+	cborBreak   = cborSimple | 31
+
+	cborIndefinite = 31 // additional information for indefinite lengths
+
+	cborTagDateTime = 0 // RFC 3339 text
+	cborTagEpoch    = 1 // seconds since the epoch, integer or float
+)
+
+// CborEncode converts the current value to CBOR
+//
+// Usage patterns:
+//
+//	data, err := Convert(&credential).CborEncode()
+//	_, err := Convert(&reading).CborEncode(conn)
+//
+// Like JsonEncode, it returns a copy of the output, or writes it to the
+// optional writer and returns nil bytes
+func (c *refValue) CborEncode(w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	return jh.encodeTo(c, w, jh.appendCbor)
+}
+
+// appendCbor appends the CBOR representation of c to dst
+func (jh *jsonH) appendCbor(dst []byte, c *refValue) ([]byte, error) {
+	switch c.vTpe {
+	case tpString:
+		return appendCborText(dst, c.getString()), nil
+	case tpBool:
+		return appendCborBool(dst, c.getBool()), nil
+	case tpStrSlice:
+		if c.stringSliceVal == nil && jh.jNilSlice == NilSliceAsNull {
+			return append(dst, cborNull), nil
+		}
+		dst = appendCborHead(dst, cborArray, uint64(len(c.stringSliceVal)))
+		for _, s := range c.stringSliceVal {
+			dst = appendCborText(dst, s)
+		}
+		return dst, nil
+	}
+	return jh.appendCborValue(dst, c)
+}
+
+// appendCborValue appends a value reached through reflection
+func (jh *jsonH) appendCborValue(dst []byte, v *refValue) ([]byte, error) {
+	if v == nil || !v.refIsValid() {
+		return append(dst, cborNull), nil
+	}
+	if isTimeType(v) {
+		t, _ := v.Interface().(time.Time)
+		dst = appendCborHead(dst, cborTag, cborTagDateTime)
+		return appendCborText(dst, t.Format(time.RFC3339Nano)), nil
+	}
+
+	switch v.refKind() {
+	case tpString:
+		return appendCborText(dst, v.refString()), nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return appendCborInt(dst, v.refInt()), nil
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return appendCborHead(dst, cborUint, v.refUint()), nil
+	case tpFloat32:
+		f := float32(v.refFloat())
+		return appendUint32BE(append(dst, cborFloat32), *(*uint32)(unsafe.Pointer(&f))), nil
+	case tpFloat64:
+		f := v.refFloat()
+		return appendUint64BE(append(dst, cborFloat64), *(*uint64)(unsafe.Pointer(&f))), nil
+	case tpBool:
+		return appendCborBool(dst, v.refBool()), nil
+	case tpSlice, tpArray:
+		return jh.appendCborSlice(dst, v)
+	case tpStruct:
+		return jh.appendCborStruct(dst, v)
+	case tpPointer:
+		return jh.appendCborValue(dst, v.refElem())
+	case tpInterface:
+		inner := v.refInterfaceValue()
+		if inner == nil {
+			return append(dst, cborNull), nil
+		}
+		return jh.appendCborValue(dst, refValueOf(inner))
+	}
+	return dst, unsupportedKindError("for CBOR encoding", v.refKind())
+}
+
+// appendCborSlice appends a slice or array; []byte is written as a byte string
+func (jh *jsonH) appendCborSlice(dst []byte, v *refValue) ([]byte, error) {
+	if isNilSlice(v) && jh.jNilSlice == NilSliceAsNull {
+		return append(dst, cborNull), nil
+	}
+	if isJsonByteSlice(v) {
+		var b []byte
+		if v.ptr != nil {
+			b = *(*[]byte)(v.ptr)
+		}
+		dst = appendCborHead(dst, cborBytes, uint64(len(b)))
+		return append(dst, b...), nil
+	}
+
+	n := v.refLen()
+	dst = appendCborHead(dst, cborArray, uint64(n))
+	var err error
+	for i := range n {
+		if dst, err = jh.appendCborValue(dst, v.refIndex(i)); err != nil {
+			return dst, prefixKindPath(err, indexSegment(i))
+		}
+	}
+	return dst, nil
+}
+
+// appendCborStruct appends a struct as a map from field names to values
+func (jh *jsonH) appendCborStruct(dst []byte, v *refValue) ([]byte, error) {
+	var structInfo refStructType
+	if err := structMetadataFor(v, &structInfo); err != nil {
+		return dst, err
+	}
+
+	numFields := v.refNumField()
+	count := 0
+	for i := range numFields {
+		if v.refField(i).refIsValid() && isExportedField(structInfo.fields[i].name) {
+			count++
+		}
+	}
+
+	dst = appendCborHead(dst, cborMap, uint64(count))
+	for i := range numFields {
+		field := v.refField(i)
+		if !field.refIsValid() || !isExportedField(structInfo.fields[i].name) {
+			continue
+		}
+		if isEncryptedField(structInfo.fields[i].tag.Get("secure")) {
+			return dst, Err(errUnsupportedType, "secure field "+structInfo.fields[i].name+" for CBOR encoding")
+		}
+		info := &structInfo.fields[i]
+		dst = appendCborText(dst, taggedFieldName(info.name, info.tag.Get("tiny"), info.tag.Get("json"), jh.jNaming))
+		var err error
+		if dst, err = jh.appendCborValue(dst, field); err != nil {
+			return dst, prefixKindPath(err, structInfo.fields[i].name)
+		}
+	}
+	return dst, nil
+}
+
+// appendCborHead appends an initial byte for major with the argument n in its
+// shortest form
+func appendCborHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= 0xff:
+		return append(dst, major|24, byte(n))
+	case n <= 0xffff:
+		return append(dst, major|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		return appendUint32BE(append(dst, major|26), uint32(n))
+	}
+	return appendUint64BE(append(dst, major|27), n)
+}
+
+// appendCborInt appends a signed integer; negatives are stored as -1-n
+func appendCborInt(dst []byte, v int64) []byte {
+	if v < 0 {
+		return appendCborHead(dst, cborNegint, uint64(-1-v))
+	}
+	return appendCborHead(dst, cborUint, uint64(v))
+}
+
+// appendCborText appends s as a text string
+func appendCborText(dst []byte, s string) []byte {
+	dst = appendCborHead(dst, cborText, uint64(len(s)))
+	return append(dst, s...)
+}
+
+// appendCborBool appends true or false
+func appendCborBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, cborTrue)
+	}
+	return append(dst, cborFalse)
+}
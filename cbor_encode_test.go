@@ -0,0 +1,80 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestCborEncodeScalars(t *testing.T) {
+	clearRefStructsCache()
+
+	// Expected bytes follow the examples of RFC 8949 Appendix A
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{"0", 0, "\x00"},
+		{"23", 23, "\x17"},
+		{"24", 24, "\x18\x18"},
+		{"1000", 1000, "\x19\x03\xe8"},
+		{"1000000", 1000000, "\x1a\x00\x0f\x42\x40"},
+		{"1000000000000", int64(1000000000000), "\x1b\x00\x00\x00\xe8\xd4\xa5\x10\x00"},
+		{"uint64 max", uint64(1<<64 - 1), "\x1b\xff\xff\xff\xff\xff\xff\xff\xff"},
+		{"-1", -1, "\x20"},
+		{"-100", -100, "\x38\x63"},
+		{"-1000", -1000, "\x39\x03\xe7"},
+		{"1.1", 1.1, "\xfb\x3f\xf1\x99\x99\x99\x99\x99\x9a"},
+		{"100000.0 float32", float32(100000), "\xfa\x47\xc3\x50\x00"},
+		{"false", false, "\xf4"},
+		{"text", "IETF", "\x64IETF"},
+		{"bytes", []byte{1, 2, 3, 4}, "\x44\x01\x02\x03\x04"},
+		{"string slice", []string{"a", "b"}, "\x82\x61a\x61b"},
+		{"nested arrays", [][]int{{1}, {2, 3}}, "\x82\x81\x01\x82\x02\x03"},
+		{"date/time", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "\xc0\x742013-03-21T20:04:00Z"},
+	}
+	for _, tt := range tests {
+		out, err := Convert(tt.input).CborEncode()
+		if err != nil {
+			t.Errorf("%s: CborEncode returned error: %v", tt.name, err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("%s: CborEncode = % x, expected % x", tt.name, out, tt.expected)
+		}
+	}
+}
+
+func TestCborEncodeStruct(t *testing.T) {
+	clearRefStructsCache()
+
+	type Credential struct {
+		ID      []byte `json:"id"`
+		Counter uint32 `json:"counter"`
+		Parent  *Credential
+	}
+
+	out, err := Convert(&Credential{ID: []byte{0xaa}, Counter: 24}).CborEncode()
+	if err != nil {
+		t.Fatalf("CborEncode returned error: %v", err)
+	}
+	expected := "\xa3\x62id\x41\xaa\x67counter\x18\x18\x66Parent\xf6"
+	if string(out) != expected {
+		t.Errorf("CborEncode = % x, expected % x", out, expected)
+	}
+
+	var written []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if out, err := Convert(&Credential{ID: []byte{0xaa}, Counter: 24}).CborEncode(w); err != nil || out != nil || string(written) != expected {
+		t.Errorf("CborEncode(w) wrote % x, returned %v, %v", written, out, err)
+	}
+
+	if _, err := Convert(map[string]int{"a": 1}).CborEncode(); err == nil {
+		t.Error("CborEncode(map) should fail")
+	}
+}
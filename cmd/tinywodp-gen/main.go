@@ -0,0 +1,505 @@
+// Command tinywodp-gen writes reflection-free JSON codecs for tagged structs
+//
+// Structs marked with a //tinywodp:json comment get EncodeJSON and DecodeJSON
+// methods built on tinywodp.JsonWriter and tinywodp.JsonReader, plus the
+// MarshalJSONTiny and UnmarshalJSONTiny wrappers that JsonEncode and JsonDecode
+// look for, so existing calls switch to the generated code:
+//
+//	//go:generate go run github.com/cdvelop/tinywodp/cmd/tinywodp-gen
+//
+//	//tinywodp:json
+//	type User struct {
+//		Name  string
+//		Email string `json:"email"`
+//		Tags  []string
+//		Boss  *User
+//	}
+//
+// Output matches JsonEncode with the same naming convention (-naming), json tag
+// names included, and the decoder accepts the keys JsonDecode matches by
+// default: json tag, Go name, snake_case and camelCase. Field types are limited to what can be written
+// statically: strings, booleans, numbers, []byte, other tagged structs of the
+// package, named basic types, and pointers, slices and arrays of those.
+// Anything else (maps, interfaces, types from other packages, ",string",
+// secure and tiny tagged fields) is reported, and the struct can keep the reflection codec by
+// dropping the comment.
+//
+// Usage:
+//
+//	tinywodp-gen [-naming as|snake|camel] [-o tinywodp_gen.go] [dir]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// directive marks the structs to generate codecs for
+const directive = "//tinywodp:json"
+
+func main() {
+	naming := flag.String("naming", "as", "key naming of the encoder: as, snake or camel")
+	output := flag.String("o", "tinywodp_gen.go", "output file name, written in the package directory")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if err := run(dir, *output, *naming); err != nil {
+		fmt.Fprintln(os.Stderr, "tinywodp-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the codecs of the package in dir into dir/output
+func run(dir, output, naming string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("%s: expected one package, found %d", dir, len(pkgs))
+	}
+
+	for name, pkg := range pkgs {
+		files := make([]*ast.File, 0, len(pkg.Files))
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return fset.Position(files[i].Pos()).Filename < fset.Position(files[j].Pos()).Filename
+		})
+		src, err := generate(fset, name, files, naming)
+		if err != nil {
+			return err
+		}
+		if src == nil {
+			return fmt.Errorf("%s: no struct is marked with %s", dir, directive)
+		}
+		return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+	}
+	return nil
+}
+
+// generate returns the formatted codec source for the marked structs of a
+// package, or nil when there are none
+func generate(fset *token.FileSet, pkgName string, files []*ast.File, naming string) ([]byte, error) {
+	if naming != "as" && naming != "snake" && naming != "camel" {
+		return nil, fmt.Errorf("unknown naming %q, want as, snake or camel", naming)
+	}
+
+	g := &generator{fset: fset, naming: naming, types: map[string]*ast.TypeSpec{}, marked: map[string]bool{}}
+	var order []string
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.types[ts.Name.Name] = ts
+				if !hasDirective(ts.Doc) && !(len(gen.Specs) == 1 && hasDirective(gen.Doc)) {
+					continue
+				}
+				if _, ok := ts.Type.(*ast.StructType); !ok || ts.TypeParams != nil {
+					return nil, fmt.Errorf("%s: %s marks %s, which is not a plain struct type", fset.Position(ts.Pos()), directive, ts.Name.Name)
+				}
+				g.marked[ts.Name.Name] = true
+				order = append(order, ts.Name.Name)
+			}
+		}
+	}
+	if len(order) == 0 {
+		return nil, nil
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by tinywodp-gen. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	fmt.Fprintf(&g.buf, "import \"github.com/cdvelop/tinywodp\"\n")
+	for _, name := range order {
+		if err := g.writeType(g.types[name]); err != nil {
+			return nil, err
+		}
+	}
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+// hasDirective reports whether a doc comment holds the directive line
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// generator accumulates the output for one package
+type generator struct {
+	fset   *token.FileSet
+	naming string
+	types  map[string]*ast.TypeSpec // every type declared in the package
+	marked map[string]bool          // the structs being generated
+	buf    bytes.Buffer
+}
+
+// codecKind is how a field type is written and read
+type codecKind uint8
+
+const (
+	kindString codecKind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes
+	kindStruct
+	kindPointer
+	kindSlice
+	kindArray
+)
+
+// fieldType is a resolved field type
+type fieldType struct {
+	kind codecKind
+	expr string     // Go type as written, for conversions and allocation
+	conv bool       // a named type: values are converted to and from the basic type
+	bits int        // numbers: bit size, 0 for int and uint
+	elem *fieldType // pointers, slices and arrays
+}
+
+// basicTypes maps the predeclared types to their codec
+var basicTypes = map[string]fieldType{
+	"string":  {kind: kindString},
+	"bool":    {kind: kindBool},
+	"int":     {kind: kindInt},
+	"int8":    {kind: kindInt, bits: 8},
+	"int16":   {kind: kindInt, bits: 16},
+	"int32":   {kind: kindInt, bits: 32},
+	"rune":    {kind: kindInt, bits: 32},
+	"int64":   {kind: kindInt, bits: 64},
+	"uint":    {kind: kindUint},
+	"uint8":   {kind: kindUint, bits: 8},
+	"byte":    {kind: kindUint, bits: 8},
+	"uint16":  {kind: kindUint, bits: 16},
+	"uint32":  {kind: kindUint, bits: 32},
+	"uint64":  {kind: kindUint, bits: 64},
+	"float32": {kind: kindFloat, bits: 32},
+	"float64": {kind: kindFloat, bits: 64},
+}
+
+// resolve maps a field type expression to its codec
+func (g *generator) resolve(expr ast.Expr) (*fieldType, error) {
+	text := types.ExprString(expr)
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if basic, ok := basicTypes[e.Name]; ok {
+			basic.expr = text
+			return &basic, nil
+		}
+		if g.marked[e.Name] {
+			return &fieldType{kind: kindStruct, expr: text}, nil
+		}
+		ts, ok := g.types[e.Name]
+		if !ok {
+			break
+		}
+		if under, ok := ts.Type.(*ast.Ident); ok && ts.Assign == 0 {
+			if basic, ok := basicTypes[under.Name]; ok {
+				basic.expr, basic.conv = text, true
+				return &basic, nil
+			}
+		}
+		if _, ok := ts.Type.(*ast.StructType); ok {
+			return nil, fmt.Errorf("struct %s needs the %s comment too", e.Name, directive)
+		}
+	case *ast.StarExpr:
+		elem, err := g.resolve(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return &fieldType{kind: kindPointer, expr: text, elem: elem}, nil
+	case *ast.ArrayType:
+		elem, err := g.resolve(e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		if e.Len != nil {
+			return &fieldType{kind: kindArray, expr: text, elem: elem}, nil
+		}
+		if elem.kind == kindUint && elem.bits == 8 && !elem.conv {
+			return &fieldType{kind: kindBytes, expr: text}, nil
+		}
+		return &fieldType{kind: kindSlice, expr: text, elem: elem}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", text)
+}
+
+// structField is one generated member
+type structField struct {
+	name string   // Go field name
+	key  string   // encoded key
+	keys []string // keys accepted when decoding
+	typ  *fieldType
+}
+
+// writeType writes the four methods of a marked struct
+func (g *generator) writeType(ts *ast.TypeSpec) error {
+	name := ts.Name.Name
+	var fields []structField
+	claimed := map[string]bool{}
+	for _, field := range ts.Type.(*ast.StructType).Fields.List {
+		pos := g.fset.Position(field.Pos())
+		if len(field.Names) == 0 {
+			return fmt.Errorf("%s: %s: embedded fields are not supported", pos, name)
+		}
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			unquoted, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(unquoted)
+		}
+		jsonTag := tag.Get("json")
+		if hasTagOption(jsonTag, "string") || tag.Get("secure") != "" || tag.Get("tiny") != "" {
+			return fmt.Errorf("%s: %s: \",string\", secure and tiny tagged fields need the reflection codec", pos, name)
+		}
+		typ, err := g.resolve(field.Type)
+		if err != nil {
+			return fmt.Errorf("%s: %s.%s: %v", pos, name, field.Names[0].Name, err)
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			tagName, _, _ := strings.Cut(jsonTag, ",")
+			f := structField{name: ident.Name, key: tagName, typ: typ}
+			if f.key == "" {
+				f.key = namedKey(ident.Name, g.naming)
+			}
+			for _, key := range []string{tagName, ident.Name, toSnakeCase(ident.Name), toCamelCase(ident.Name)} {
+				if key != "" && !claimed[key] {
+					claimed[key] = true
+					f.keys = append(f.keys, key)
+				}
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "\n// EncodeJSON writes v as a JSON object\nfunc (v *%s) EncodeJSON(w *tinywodp.JsonWriter) {\n\tw.BeginObject()\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(&g.buf, "\tw.Key(%q)\n", f.key)
+		g.writeEncode("v."+f.name, f.typ, 0)
+	}
+	fmt.Fprintf(&g.buf, "\tw.EndObject()\n}\n")
+
+	fmt.Fprintf(&g.buf, "\n// DecodeJSON reads v from a JSON object; unknown keys are skipped\nfunc (v *%s) DecodeJSON(r *tinywodp.JsonReader) {\n\tif !r.BeginObject() {\n\t\treturn\n\t}\n\tfor r.More() {\n\t\tswitch r.Key() {\n", name)
+	for _, f := range fields {
+		if len(f.keys) == 0 {
+			continue
+		}
+		quoted := make([]string, len(f.keys))
+		for i, key := range f.keys {
+			quoted[i] = strconv.Quote(key)
+		}
+		fmt.Fprintf(&g.buf, "\t\tcase %s:\n", strings.Join(quoted, ", "))
+		g.writeDecode("v."+f.name, f.typ, 0)
+	}
+	fmt.Fprintf(&g.buf, "\t\tdefault:\n\t\t\tr.Skip()\n\t\t}\n\t}\n}\n")
+
+	fmt.Fprintf(&g.buf, "\n// MarshalJSONTiny implements tinywodp.JsonMarshaler\nfunc (v *%s) MarshalJSONTiny() ([]byte, error) {\n\tw := tinywodp.NewJsonWriter()\n\tv.EncodeJSON(w)\n\treturn w.Finish()\n}\n", name)
+	fmt.Fprintf(&g.buf, "\n// UnmarshalJSONTiny implements tinywodp.JsonUnmarshaler\nfunc (v *%s) UnmarshalJSONTiny(data []byte) error {\n\tr := tinywodp.NewJsonReader(data)\n\tv.DecodeJSON(r)\n\treturn r.Finish()\n}\n", name)
+	return nil
+}
+
+// writeEncode writes the statements encoding the value x
+func (g *generator) writeEncode(x string, t *fieldType, depth int) {
+	b := &g.buf
+	switch t.kind {
+	case kindString:
+		fmt.Fprintf(b, "w.String(%s)\n", convert("string", x, t.conv))
+	case kindBool:
+		fmt.Fprintf(b, "w.Bool(%s)\n", convert("bool", x, t.conv))
+	case kindInt:
+		fmt.Fprintf(b, "w.Int(%s)\n", convert("int64", x, t.conv || t.bits != 64))
+	case kindUint:
+		fmt.Fprintf(b, "w.Uint(%s)\n", convert("uint64", x, t.conv || t.bits != 64))
+	case kindFloat:
+		fmt.Fprintf(b, "w.Float(%s, %d)\n", convert("float64", x, t.conv || t.bits != 64), t.bits)
+	case kindBytes:
+		fmt.Fprintf(b, "w.Bytes(%s)\n", x)
+	case kindStruct:
+		fmt.Fprintf(b, "%s.EncodeJSON(w)\n", x)
+	case kindPointer:
+		fmt.Fprintf(b, "if %s == nil {\nw.Null()\n} else {\n", x)
+		g.writeEncode(deref(x, t.elem), t.elem, depth+1)
+		fmt.Fprintf(b, "}\n")
+	case kindSlice, kindArray:
+		i := fmt.Sprintf("i%d", depth)
+		if t.kind == kindSlice {
+			fmt.Fprintf(b, "if %s == nil {\nw.NilSlice()\n} else {\n", x)
+		}
+		fmt.Fprintf(b, "w.BeginArray()\nfor %s := range %s {\n", i, x)
+		g.writeEncode(x+"["+i+"]", t.elem, depth+1)
+		fmt.Fprintf(b, "}\nw.EndArray()\n")
+		if t.kind == kindSlice {
+			fmt.Fprintf(b, "}\n")
+		}
+	}
+}
+
+// writeDecode writes the statements decoding the next value into x
+func (g *generator) writeDecode(x string, t *fieldType, depth int) {
+	b := &g.buf
+	switch t.kind {
+	case kindString:
+		fmt.Fprintf(b, "%s = %s\n", x, convert(t.expr, "r.String()", t.conv))
+	case kindBool:
+		fmt.Fprintf(b, "%s = %s\n", x, convert(t.expr, "r.Bool()", t.conv))
+	case kindInt:
+		fmt.Fprintf(b, "%s = %s\n", x, convert(t.expr, fmt.Sprintf("r.Int(%d)", t.bits), t.conv || t.bits != 64))
+	case kindUint:
+		fmt.Fprintf(b, "%s = %s\n", x, convert(t.expr, fmt.Sprintf("r.Uint(%d)", t.bits), t.conv || t.bits != 64))
+	case kindFloat:
+		fmt.Fprintf(b, "%s = %s\n", x, convert(t.expr, fmt.Sprintf("r.Float(%d)", t.bits), t.conv || t.bits != 64))
+	case kindBytes:
+		fmt.Fprintf(b, "%s = r.Bytes()\n", x)
+	case kindStruct:
+		fmt.Fprintf(b, "%s.DecodeJSON(r)\n", x)
+	case kindPointer:
+		// An existing element is reused, as JsonDecode does
+		fmt.Fprintf(b, "if r.Null() {\n%s = nil\n} else {\nif %s == nil {\n%s = new(%s)\n}\n", x, x, x, t.elem.expr)
+		g.writeDecode(deref(x, t.elem), t.elem, depth+1)
+		fmt.Fprintf(b, "}\n")
+	case kindSlice:
+		s, e := fmt.Sprintf("s%d", depth), fmt.Sprintf("e%d", depth)
+		fmt.Fprintf(b, "if r.Null() {\n%s = nil\n} else if r.BeginArray() {\n%s := %s{}\nfor r.More() {\nvar %s %s\n%s = append(%s, %s)\n", x, s, t.expr, e, t.elem.expr, s, s, e)
+		g.writeDecode(s+"[len("+s+")-1]", t.elem, depth+1)
+		fmt.Fprintf(b, "}\n%s = %s\n}\n", x, s)
+	case kindArray:
+		// Extra elements are skipped and missing ones zeroed, as JsonDecode does
+		n, z := fmt.Sprintf("n%d", depth), fmt.Sprintf("z%d", depth)
+		fmt.Fprintf(b, "if !r.Null() && r.BeginArray() {\n%s := 0\nfor ; r.More(); %s++ {\nif %s >= len(%s) {\nr.Skip()\ncontinue\n}\n", n, n, n, x)
+		g.writeDecode(x+"["+n+"]", t.elem, depth+1)
+		fmt.Fprintf(b, "}\nfor ; %s < len(%s); %s++ {\nvar %s %s\n%s[%s] = %s\n}\n}\n", n, x, n, z, t.elem.expr, x, n, z)
+	}
+}
+
+// deref returns the expression for the element pointed to by x; methods of
+// tagged structs are called through the pointer itself
+func deref(x string, elem *fieldType) string {
+	switch elem.kind {
+	case kindStruct:
+		return x
+	case kindSlice, kindArray, kindBytes:
+		return "(*" + x + ")"
+	}
+	return "*" + x
+}
+
+// convert wraps x in a conversion to typ when needed
+func convert(typ, x string, needed bool) string {
+	if !needed {
+		return x
+	}
+	return typ + "(" + x + ")"
+}
+
+// hasTagOption reports whether a json tag lists option after its name
+func hasTagOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// namedKey returns the encoded key of a field in the naming convention
+func namedKey(name, naming string) string {
+	switch naming {
+	case "snake":
+		return toSnakeCase(name)
+	case "camel":
+		return toCamelCase(name)
+	}
+	return name
+}
+
+// toSnakeCase converts PascalCase to snake_case exactly as the package does
+// Acronyms stay one word: UserID -> user_id, HTTPServer -> http_server
+func toSnakeCase(s string) string {
+	result := make([]byte, 0, len(s)+5)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isUpperASCII(c) {
+			result = append(result, c)
+			continue
+		}
+		if i > 0 {
+			prev := s[i-1]
+			if isLowerASCII(prev) || ('0' <= prev && prev <= '9') ||
+				(isUpperASCII(prev) && i+1 < len(s) && isLowerASCII(s[i+1])) {
+				result = append(result, '_')
+			}
+		}
+		result = append(result, c+'a'-'A')
+	}
+	return string(result)
+}
+
+// toCamelCase converts PascalCase to camelCase exactly as the package does
+// UserName -> userName, ID -> id, URLPath -> urlPath
+func toCamelCase(s string) string {
+	n := 0
+	for n < len(s) && isUpperASCII(s[n]) {
+		n++
+	}
+	if n == 0 {
+		return s
+	}
+	if n > 1 && n < len(s) && isLowerASCII(s[n]) {
+		n--
+	}
+	b := []byte(s)
+	for i := 0; i < n; i++ {
+		b[i] += 'a' - 'A'
+	}
+	return string(b)
+}
+
+// isUpperASCII reports whether b is an ASCII capital letter
+func isUpperASCII(b byte) bool {
+	return 'A' <= b && b <= 'Z'
+}
+
+// isLowerASCII reports whether b is an ASCII lower-case letter
+func isLowerASCII(b byte) bool {
+	return 'a' <= b && b <= 'z'
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// generateSource runs the generator over one source file
+func generateSource(t *testing.T, src, naming string) (string, error) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "models.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out, err := generate(fset, f.Name.Name, []*ast.File{f}, naming)
+	return string(out), err
+}
+
+func TestGenerateCodec(t *testing.T) {
+	src := `package models
+
+type Status string
+
+//tinywodp:json
+type Item struct {
+	SKU   string ` + "`json:\"sku\"`" + `
+	Qty   int
+	Price float32
+}
+
+// Order is tagged below its doc comment
+//
+//tinywodp:json
+type Order struct {
+	UserID   uint64
+	Status   Status
+	Items    []Item
+	Note     *string
+	Blob     []byte
+	Coords   [2]float64
+	Parent   *Order
+	internal int
+}
+
+type Untagged struct{ A int }
+`
+	out, err := generateSource(t, src, "as")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	for _, want := range []string{
+		"// Code generated by tinywodp-gen. DO NOT EDIT.",
+		"func (v *Item) EncodeJSON(w *tinywodp.JsonWriter) {",
+		"func (v *Order) DecodeJSON(r *tinywodp.JsonReader) {",
+		"func (v *Order) MarshalJSONTiny() ([]byte, error) {",
+		"func (v *Order) UnmarshalJSONTiny(data []byte) error {",
+		`w.Key("sku")`,
+		`case "sku", "SKU":`,
+		`case "UserID", "user_id", "userID":`,
+		"v.Qty = int(r.Int(0))",
+		"v.Price = float32(r.Float(32))",
+		"w.Float(float64(v.Price), 32)",
+		"w.String(string(v.Status))",
+		"v.Status = Status(r.String())",
+		"v.Items[i0].EncodeJSON(w)",
+		"w.String(*v.Note)",
+		"v.Parent = new(Order)",
+		"v.Blob = r.Bytes()",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	for _, unwanted := range []string{"internal", "Untagged"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output mentions %q", unwanted)
+		}
+	}
+
+	// The generated file must parse
+	if _, err := parser.ParseFile(token.NewFileSet(), "gen.go", out, 0); err != nil {
+		t.Errorf("generated code does not parse: %v\n%s", err, out)
+	}
+}
+
+func TestGenerateNaming(t *testing.T) {
+	src := "package m\n\n//tinywodp:json\ntype T struct {\n\tHTTPServer string\n\tUserName string\n}\n"
+	for naming, want := range map[string][]string{
+		"snake": {`w.Key("http_server")`, `w.Key("user_name")`},
+		"camel": {`w.Key("httpServer")`, `w.Key("userName")`},
+		"as":    {`w.Key("HTTPServer")`, `w.Key("UserName")`},
+	} {
+		out, err := generateSource(t, src, naming)
+		if err != nil {
+			t.Fatalf("%s: %v", naming, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%s: output lacks %q", naming, w)
+			}
+		}
+	}
+	if _, err := generateSource(t, src, "kebab"); err == nil {
+		t.Error("expected an error for an unknown naming")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for name, body := range map[string]string{
+		"map":       "M map[string]int",
+		"interface": "V any",
+		"foreign":   "T time.Time",
+		"embedded":  "Base",
+		"untagged":  "B Base",
+		"quoted":    "N int `json:\",string\"`",
+		"secure":    "P string `secure:\"encrypt\"`",
+		"tiny":      "O string `tiny:\"omitempty\"`",
+	} {
+		src := "package m\n\ntype Base struct{}\n\n//tinywodp:json\ntype T struct {\n\t" + body + "\n}\n"
+		if _, err := generateSource(t, src, "as"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if out, err := generateSource(t, "package m\n\ntype T struct{}\n", "as"); err != nil || out != "" {
+		t.Errorf("no tagged struct: got %q, %v", out, err)
+	}
+	if _, err := generateSource(t, "package m\n\n//tinywodp:json\ntype T int\n", "as"); err == nil {
+		t.Error("expected an error for a tagged non-struct type")
+	}
+}
@@ -0,0 +1,593 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Configuration decoding (TOML subset)
+// Enough of TOML for application config files, without a parser dependency:
+//
+//	# comments, blank lines
+//	name = "api"                 # basic "..." and literal '...' strings
+//	port = 8_080                 # integers (also 0x, 0o, 0b), floats, booleans
+//	started = 2024-01-02T03:04:05Z
+//	hosts = ["a", "b"]           # arrays, which may span lines
+//	db.user = "admin"            # dotted keys
+//
+//	[server.tls]                 # tables and nested tables
+//	cert = '/etc/cert.pem'
+//
+//	[[routes]]                   # arrays of tables
+//	path = "/"
+//
+// The document is checked as it is read, duplicate keys and tables included,
+// and then decoded through the JSON decoder, so keys match fields by the same
+// rules as JSON objects (json tag, Go name, snake_case and camelCase forms) and
+// the decode options apply. Offset date-times decode into time.Time under the
+// default TimeFormat; local dates and times are kept as strings. Multi-line
+// strings, inline tables and inf/nan are not supported.
+
+// ConfigDecode decodes a TOML configuration document into target
+//
+// Usage pattern:
+//
+//	var cfg Config
+//	err := ConfigDecode(configText, &cfg)
+//
+// Syntax errors name the line; type errors name the Go path of the field, as
+// in JsonDecode.
+func ConfigDecode(config string, target any, opts ...DecodeOption) error {
+	if target == nil {
+		return Err(errInvalidConfig, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return Err(errInvalidConfig, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return Err(errInvalidConfig, "target pointer is nil or invalid")
+	}
+
+	p := configParser{s: config, line: 1}
+	root, err := p.parse()
+	if err != nil {
+		return Err(errInvalidConfig, "line "+Convert(p.line).String()+": "+err.Error())
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	jh.jOut = root.appendJson(jh.jOut[:0])
+	text := string(jh.jOut)
+	jh.jInput = text
+	if err := jh.parseJsonValueWithRefReflect(text, elem); err != nil {
+		jh.jErrAt = -1 // offsets into the generated JSON mean nothing to the caller
+		return jh.positionError(err)
+	}
+	jh.checkRequiredFields(text, elem)
+	if len(jh.jErrors) > 0 {
+		return jh.takeDecodeErrors()
+	}
+	return nil
+}
+
+// configTable is a table of a config document in the order keys were read
+// values hold JSON text (scalars and arrays), *configTable or []*configTable
+type configTable struct {
+	keys    []string
+	values  []any
+	defined bool // opened by a [header] rather than implied by a dotted key
+}
+
+// lookup returns the index of key, or -1
+func (t *configTable) lookup(key string) int {
+	for i, k := range t.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// child returns the table at key, creating an implicit one when absent; for
+// an array of tables it is the last table
+func (t *configTable) child(key string) (*configTable, error) {
+	i := t.lookup(key)
+	if i == -1 {
+		sub := &configTable{}
+		t.keys = append(t.keys, key)
+		t.values = append(t.values, sub)
+		return sub, nil
+	}
+	switch v := t.values[i].(type) {
+	case *configTable:
+		return v, nil
+	case []*configTable:
+		return v[len(v)-1], nil
+	}
+	return nil, Err("key " + key + " is not a table")
+}
+
+// appendJson appends the table as a JSON object
+func (t *configTable) appendJson(dst []byte) []byte {
+	dst = append(dst, '{')
+	for i, key := range t.keys {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = escapeAndQuoteJsonString(dst, key, false)
+		dst = append(dst, ':')
+		switch v := t.values[i].(type) {
+		case string:
+			dst = append(dst, v...)
+		case *configTable:
+			dst = v.appendJson(dst)
+		case []*configTable:
+			dst = append(dst, '[')
+			for j, item := range v {
+				if j > 0 {
+					dst = append(dst, ',')
+				}
+				dst = item.appendJson(dst)
+			}
+			dst = append(dst, ']')
+		}
+	}
+	return append(dst, '}')
+}
+
+// configParser reads a config document line by line into tables
+type configParser struct {
+	s    string
+	pos  int
+	line int // line of pos, for errors
+}
+
+// parse reads the whole document
+func (p *configParser) parse() (*configTable, error) {
+	root := &configTable{defined: true}
+	current := root
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.s) {
+			return root, nil
+		}
+		var err error
+		switch p.s[p.pos] {
+		case '\n', '\r', '#':
+		case '[':
+			current, err = p.parseHeader(root)
+		default:
+			err = p.parseKeyValue(current)
+		}
+		if err == nil {
+			err = p.lineEnd()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseHeader reads a [table] or [[array of tables]] header and returns the
+// table that following keys belong to
+func (p *configParser) parseHeader(root *configTable) (*configTable, error) {
+	p.pos++
+	isArray := p.pos < len(p.s) && p.s[p.pos] == '['
+	if isArray {
+		p.pos++
+	}
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if len(p.s)-p.pos < len(closing) || p.s[p.pos:p.pos+len(closing)] != closing {
+		return nil, Err("expected " + closing + " after table name")
+	}
+	p.pos += len(closing)
+
+	parent := root
+	for _, key := range keys[:len(keys)-1] {
+		if parent, err = parent.child(key); err != nil {
+			return nil, err
+		}
+	}
+	last := keys[len(keys)-1]
+	i := parent.lookup(last)
+
+	if isArray {
+		table := &configTable{defined: true}
+		if i == -1 {
+			parent.keys = append(parent.keys, last)
+			parent.values = append(parent.values, []*configTable{table})
+			return table, nil
+		}
+		tables, ok := parent.values[i].([]*configTable)
+		if !ok {
+			return nil, Err("key " + last + " is not an array of tables")
+		}
+		parent.values[i] = append(tables, table)
+		return table, nil
+	}
+
+	if i == -1 {
+		table := &configTable{defined: true}
+		parent.keys = append(parent.keys, last)
+		parent.values = append(parent.values, table)
+		return table, nil
+	}
+	table, ok := parent.values[i].(*configTable)
+	if !ok || table.defined {
+		return nil, Err("duplicate table " + last)
+	}
+	table.defined = true
+	return table, nil
+}
+
+// parseKeyValue reads key = value into table
+func (p *configParser) parseKeyValue(table *configTable) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if p.pos >= len(p.s) || p.s[p.pos] != '=' {
+		return Err("expected = after key")
+	}
+	p.pos++
+	p.skipBlank()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = table.child(key); err != nil {
+			return err
+		}
+	}
+	last := keys[len(keys)-1]
+	if table.lookup(last) != -1 {
+		return Err("duplicate key " + last)
+	}
+	table.keys = append(table.keys, last)
+	table.values = append(table.values, value)
+	return nil
+}
+
+// parseKey reads a bare, quoted or dotted key and the blanks after it
+func (p *configParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.s) {
+			return nil, Err("expected a key")
+		}
+		var key string
+		var err error
+		switch c := p.s[p.pos]; {
+		case c == '"':
+			key, err = p.parseBasicString()
+		case c == '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for p.pos < len(p.s) && isConfigBareKeyByte(p.s[p.pos]) {
+				p.pos++
+			}
+			if key = p.s[start:p.pos]; key == "" {
+				return nil, Err("invalid key character '" + string(c) + "'")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipBlank()
+		if p.pos >= len(p.s) || p.s[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// parseValue reads a value and returns its JSON text
+func (p *configParser) parseValue() (string, error) {
+	if p.pos >= len(p.s) {
+		return "", Err("expected a value")
+	}
+	switch p.s[p.pos] {
+	case '"':
+		s, err := p.parseBasicString()
+		return string(escapeAndQuoteJsonString(nil, s, false)), err
+	case '\'':
+		s, err := p.parseLiteralString()
+		return string(escapeAndQuoteJsonString(nil, s, false)), err
+	case '[':
+		return p.parseArray()
+	case '{':
+		return "", Err("inline tables are not supported")
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && !isConfigValueEnd(p.s[p.pos]) {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+	// A space may separate the date and time of a date-time
+	if isConfigDate(token) && p.pos+1 < len(p.s) && p.s[p.pos] == ' ' && isDigitByte(p.s[p.pos+1]) {
+		p.pos++
+		for p.pos < len(p.s) && !isConfigValueEnd(p.s[p.pos]) {
+			p.pos++
+		}
+		token = token + "T" + p.s[start+len(token)+1:p.pos]
+	}
+	return configScalar(token)
+}
+
+// parseArray reads an array, which may span lines and end with a comma
+func (p *configParser) parseArray() (string, error) {
+	p.pos++
+	out := []byte{'['}
+	for n := 0; ; n++ {
+		if err := p.skipSpace(); err != nil {
+			return "", err
+		}
+		if p.pos < len(p.s) && p.s[p.pos] == ']' {
+			p.pos++
+			return string(append(out, ']')), nil
+		}
+		if n > 0 {
+			out = append(out, ',')
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return "", err
+		}
+		out = append(out, value...)
+		if err := p.skipSpace(); err != nil {
+			return "", err
+		}
+		switch {
+		case p.pos < len(p.s) && p.s[p.pos] == ',':
+			p.pos++
+		case p.pos < len(p.s) && p.s[p.pos] == ']':
+		default:
+			return "", Err("expected , or ] in array")
+		}
+	}
+}
+
+// parseBasicString reads a "..." string and resolves its escapes
+func (p *configParser) parseBasicString() (string, error) {
+	p.pos++
+	var out []byte
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return string(out), nil
+		case c == '\\':
+			if p.pos+1 >= len(p.s) {
+				return "", Err("unterminated string")
+			}
+			esc := p.s[p.pos+1]
+			p.pos += 2
+			switch esc {
+			case 'b':
+				out = append(out, '\b')
+			case 't':
+				out = append(out, '\t')
+			case 'n':
+				out = append(out, '\n')
+			case 'f':
+				out = append(out, '\f')
+			case 'r':
+				out = append(out, '\r')
+			case 'e':
+				out = append(out, 0x1b)
+			case '"', '\\':
+				out = append(out, esc)
+			case 'u', 'U':
+				size := 4
+				if esc == 'U' {
+					size = 8
+				}
+				if len(p.s)-p.pos < size {
+					return "", Err("invalid unicode escape")
+				}
+				var r rune
+				for _, d := range []byte(p.s[p.pos : p.pos+size]) {
+					v := hexDigitValue(d)
+					if v < 0 {
+						return "", Err("invalid unicode escape")
+					}
+					r = r<<4 | rune(v)
+				}
+				if r > maxRune || r >= surrogateMin && r <= surrogateMax {
+					return "", Err("invalid unicode escape")
+				}
+				out = appendRuneUTF8(out, r)
+				p.pos += size
+			default:
+				return "", Err("invalid escape \\" + string(esc))
+			}
+		case c == '\n' || c == '\r':
+			return "", Err("unterminated string")
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", Err("control character in string")
+		default:
+			out = append(out, c)
+			p.pos++
+		}
+	}
+	return "", Err("unterminated string")
+}
+
+// parseLiteralString reads a '...' string, taken as written
+func (p *configParser) parseLiteralString() (string, error) {
+	start := p.pos + 1
+	for p.pos = start; p.pos < len(p.s); p.pos++ {
+		switch c := p.s[p.pos]; {
+		case c == '\'':
+			p.pos++
+			return p.s[start : p.pos-1], nil
+		case c == '\n' || c == '\r':
+			return "", Err("unterminated string")
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", Err("control character in string")
+		}
+	}
+	return "", Err("unterminated string")
+}
+
+// skipBlank skips spaces and tabs
+func (p *configParser) skipBlank() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipSpace skips blanks, comments and newlines inside an array
+func (p *configParser) skipSpace() error {
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.s) {
+			return Err("unterminated array")
+		}
+		if p.s[p.pos] != '#' && p.s[p.pos] != '\n' && p.s[p.pos] != '\r' {
+			return nil
+		}
+		if err := p.lineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+// lineEnd consumes an optional comment and the end of the line
+func (p *configParser) lineEnd() error {
+	p.skipBlank()
+	if p.pos < len(p.s) && p.s[p.pos] == '#' {
+		for p.pos < len(p.s) && p.s[p.pos] != '\n' && p.s[p.pos] != '\r' {
+			p.pos++
+		}
+	}
+	switch {
+	case p.pos >= len(p.s):
+		return nil
+	case p.s[p.pos] == '\n':
+		p.pos++
+	case p.s[p.pos] == '\r' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '\n':
+		p.pos += 2
+	default:
+		return Err("unexpected '" + string(p.s[p.pos]) + "' after value")
+	}
+	p.line++
+	return nil
+}
+
+// configScalar converts a bare value to JSON text
+func configScalar(token string) (string, error) {
+	switch token {
+	case "true", "false":
+		return token, nil
+	case "":
+		return "", Err("expected a value")
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return "", Err("inf and nan are not supported")
+	}
+	if isConfigDate(token) || len(token) >= 3 && isDigitByte(token[0]) && isDigitByte(token[1]) && token[2] == ':' {
+		// Date-times, local dates and local times are passed on as strings
+		return string(escapeAndQuoteJsonString(nil, token, false)), nil
+	}
+
+	sign := ""
+	digits := token
+	switch digits[0] {
+	case '+':
+		digits = digits[1:]
+	case '-':
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) > 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'o' || digits[1] == 'b') {
+		if sign != "" {
+			return "", Err("invalid number " + token)
+		}
+		return configRadixInt(token, digits[2:], digits[1])
+	}
+
+	// Underscores must sit between digits
+	var b []byte
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' {
+			b = append(b, digits[i])
+			continue
+		}
+		if i == 0 || i == len(digits)-1 || !isDigitByte(digits[i-1]) || !isDigitByte(digits[i+1]) {
+			return "", Err("invalid number " + token)
+		}
+	}
+	number := sign + string(b)
+	if !isJsonNumber(number) {
+		return "", Err("invalid value " + token)
+	}
+	return number, nil
+}
+
+// configRadixInt converts the digits of a 0x, 0o or 0b integer to decimal
+func configRadixInt(token, digits string, radix byte) (string, error) {
+	base := uint64(16)
+	switch radix {
+	case 'o':
+		base = 8
+	case 'b':
+		base = 2
+	}
+	var v uint64
+	seen := false
+	for i := 0; i < len(digits); i++ {
+		if digits[i] == '_' && seen && i+1 < len(digits) && digits[i+1] != '_' {
+			continue
+		}
+		d := hexDigitValue(digits[i])
+		if d < 0 || uint64(d) >= base || v > (1<<64-1-uint64(d))/base {
+			return "", Err("invalid number " + token)
+		}
+		v = v*base + uint64(d)
+		seen = true
+	}
+	if !seen {
+		return "", Err("invalid number " + token)
+	}
+	return Convert(v).String(), nil
+}
+
+// isConfigDate reports whether token starts with a YYYY-MM-DD date
+func isConfigDate(token string) bool {
+	return len(token) >= 10 && token[4] == '-' && token[7] == '-' &&
+		isDigitByte(token[0]) && isDigitByte(token[5]) && isDigitByte(token[8])
+}
+
+// isConfigBareKeyByte reports whether c may appear in a bare key
+func isConfigBareKeyByte(c byte) bool {
+	return isUpperASCII(c) || isLowerASCII(c) || isDigitByte(c) || c == '_' || c == '-'
+}
+
+// isConfigValueEnd reports whether c ends a bare value
+func isConfigValueEnd(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', ',', ']', '#':
+		return true
+	}
+	return false
+}
+
+// isDigitByte reports whether c is an ASCII digit
+func isDigitByte(c byte) bool {
+	return c >= '0' && c <= '9'
+}
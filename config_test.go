@@ -0,0 +1,126 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestConfigDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	type TLS struct {
+		Cert string
+		Key  string `json:"key_file"`
+	}
+	type Route struct {
+		Path    string
+		Methods []string
+	}
+	type Config struct {
+		Name      string
+		Port      int
+		Ratio     float64
+		Debug     bool
+		Mask      uint32
+		Started   time.Time
+		Hosts     []string
+		Weights   []int
+		TLS       TLS `json:"tls"`
+		Routes    []Route
+		MaxConns  int `json:"max_conns"`
+		Unchanged string
+	}
+
+	doc := `# service
+name = "api \"v2\"" # trailing comment
+port = 8_080
+ratio = 0.25
+debug = true
+mask = 0xff_ff
+started = 2024-01-02 03:04:05Z
+hosts = [
+  "a.example", # first
+  'b\example',
+]
+weights = []
+max_conns = 100
+
+[tls]
+cert = '/etc/cert.pem'
+key_file = "/etc/key.pem"
+
+[[routes]]
+path = "/"
+methods = ["GET"]
+
+[[routes]]
+path = "/admin"
+`
+	cfg := Config{Unchanged: "kept"}
+	if err := ConfigDecode(doc, &cfg); err != nil {
+		t.Fatalf("ConfigDecode returned error: %v", err)
+	}
+	if cfg.Name != `api "v2"` || cfg.Port != 8080 || cfg.Ratio != 0.25 || !cfg.Debug || cfg.Mask != 0xffff || cfg.MaxConns != 100 {
+		t.Errorf("ConfigDecode scalars = %+v", cfg)
+	}
+	if !cfg.Started.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Started = %v", cfg.Started)
+	}
+	if len(cfg.Hosts) != 2 || cfg.Hosts[1] != `b\example` || cfg.Weights == nil || len(cfg.Weights) != 0 {
+		t.Errorf("arrays = %q %v", cfg.Hosts, cfg.Weights)
+	}
+	if cfg.TLS.Cert != "/etc/cert.pem" || cfg.TLS.Key != "/etc/key.pem" {
+		t.Errorf("TLS = %+v", cfg.TLS)
+	}
+	if len(cfg.Routes) != 2 || cfg.Routes[0].Methods[0] != "GET" || cfg.Routes[1].Path != "/admin" {
+		t.Errorf("Routes = %+v", cfg.Routes)
+	}
+	if cfg.Unchanged != "kept" {
+		t.Error("fields missing from the document should be left untouched")
+	}
+
+	// Dotted keys and nested headers build the same tables
+	var dotted Config
+	if err := ConfigDecode("tls.cert = \"c\"\r\n[tls]\r\nkey_file = \"k\"\r\n", &dotted); err != nil || dotted.TLS.Cert != "c" || dotted.TLS.Key != "k" {
+		t.Errorf("ConfigDecode(dotted) = %+v, %v", dotted.TLS, err)
+	}
+}
+
+func TestConfigDecodeErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	type Config struct {
+		Port  uint8
+		Hosts []string
+	}
+	tests := []struct {
+		name     string
+		doc      string
+		contains string
+	}{
+		{"duplicate key", "port = 1\nport = 2", "line 2: duplicate key port"},
+		{"duplicate table", "[a]\nx = 1\n[a]", "line 3: duplicate table a"},
+		{"unterminated string", "hosts = [\"a]", "line 1: unterminated string"},
+		{"unterminated array", "hosts = [\"a\",\n", "unterminated array"},
+		{"leading zero", "port = 01", "invalid value 01"},
+		{"bad underscore", "port = 1__0", "invalid number 1__0"},
+		{"inline table", "a = {b = 1}", "inline tables are not supported"},
+		{"text after value", "port = 1 2", "line 1: unexpected '2' after value"},
+		{"out of range", "port = 300", "Port"},
+		{"type mismatch", "hosts = [1]", "Hosts"},
+	}
+	for _, tt := range tests {
+		var cfg Config
+		err := ConfigDecode(tt.doc, &cfg)
+		if err == nil || !Contains(err.Error(), tt.contains) {
+			t.Errorf("%s: ConfigDecode error = %v, expected it to contain %q", tt.name, err, tt.contains)
+		}
+	}
+
+	var notPointer struct{}
+	if err := ConfigDecode("a = 1", notPointer); err == nil {
+		t.Error("ConfigDecode into a non-pointer should fail")
+	}
+}
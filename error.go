@@ -1,10 +1,65 @@
-package tinywodp
-
-// Error message constants
-const (
-
-	// JSON specific errors
-	errInvalidJSON     errorType = "invalid json"
-	errUnsupportedType errorType = "unsupported type"
-	errCircularRef     errorType = "circular reference"
-)
+package tinywodp
+
+// Error message constants
+const (
+
+	// ErrEmptyInput is returned by every decode entry point when the input is
+	// empty or contains only whitespace
+	ErrEmptyInput errorType = "empty JSON input"
+
+	// ErrSyntax is returned when the input is not well-formed JSON or does not
+	// match the target type
+	ErrSyntax errorType = "invalid json"
+
+	// ErrTrailingData is reported, inside a syntax error, when something other
+	// than whitespace follows the decoded value (see AllowTrailingData)
+	ErrTrailingData errorType = "unexpected data after JSON value"
+
+	// ErrUnsupportedEncoding is returned when JSON input is UTF-16 or UTF-32
+	// text instead of UTF-8
+	ErrUnsupportedEncoding errorType = "unsupported text encoding"
+
+	// ErrPathNotFound is returned by Document lookups when a path segment
+	// does not exist in the payload
+	ErrPathNotFound errorType = "path not found"
+
+	// ErrMaxDepth is returned when objects and arrays are nested deeper than
+	// the limit set with SetMaxDepth, while encoding or decoding
+	ErrMaxDepth errorType = "maximum nesting depth exceeded"
+
+	// ErrLimitExceeded is returned when a decode input passes one of its
+	// DecodeLimits, such as the longest string or the most array elements
+	ErrLimitExceeded errorType = "decode limit exceeded"
+
+	// ErrBodyTooLarge is returned by ReadJSON when the input exceeds its size
+	// limit; handlers usually answer 413 Request Entity Too Large
+	ErrBodyTooLarge errorType = "request body too large"
+
+	// ErrMissingField wraps each field tagged tiny:"required" that a decode
+	// did not find in its input
+	ErrMissingField errorType = "missing required field"
+
+	// ErrValidation wraps each rule violation reported by JsonDecodeValidated
+	ErrValidation errorType = "validation failed"
+
+	// JSON specific errors
+	errInvalidJSON      errorType = ErrSyntax
+	errUnsupportedType  errorType = "unsupported type"
+	errUnsupportedValue errorType = "unsupported value"
+	errInvalidMsgpack   errorType = "invalid msgpack"
+	errInvalidCbor      errorType = "invalid CBOR"
+	errInvalidForm      errorType = "invalid form data"
+	errInvalidConfig    errorType = "invalid config"
+	errInvalidBinary    errorType = "invalid binary data"
+	errInvalidPatch     errorType = "invalid JSON patch"
+	errInvalidPath      errorType = "invalid JSONPath"
+	errCircularRef      errorType = "circular reference"
+	errNoCipher         errorType = "no cipher registered"
+	errWarmup           errorType = "warm-up failed"
+
+	// ErrNoReflection is returned when the build stripped the type metadata
+	// (field names, struct layout) the custom reflection relies on, e.g. some
+	// TinyGo -no-debug configurations. Callers can compare against it to fall
+	// back to hand-written or generated codecs.
+	ErrNoReflection errorType = "reflection metadata unavailable"
+)
@@ -0,0 +1,103 @@
+package tinywodp
+
+import (
+	"os"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// ============================================================================
+// RUNNABLE EXAMPLES - executable documentation verified by go test
+// ============================================================================
+
+// Example_jsonEncodeWriter shows how to stream JSON directly to any value
+// implementing Write([]byte) (int, error), such as os.Stdout or an
+// http.ResponseWriter, instead of receiving the bytes back.
+func Example_jsonEncodeWriter() {
+	type Address struct {
+		Street string
+		City   string
+	}
+
+	addr := Address{Street: "123 Main St", City: "Anytown"}
+
+	// With a writer the returned bytes are nil and only the error matters
+	if _, err := Convert(addr).JsonEncode(os.Stdout); err != nil {
+		os.Stdout.WriteString("encode error: " + err.Error())
+	}
+	// Output: {"Street":"123 Main St","City":"Anytown"}
+}
+
+// Example_jsonDecodeStrict shows that decoding is type-strict: a JSON value
+// of the wrong kind is rejected instead of being silently coerced.
+func Example_jsonDecodeStrict() {
+	type Account struct {
+		Name   string
+		Active bool
+	}
+
+	var ok Account
+	err := Convert(`{"Name":"alice","Active":true}`).JsonDecode(&ok)
+	os.Stdout.WriteString(Fmt("%s %s\n", ok.Name, Convert(err == nil).String()).String())
+
+	// "yes" is a string, not a boolean
+	var bad Account
+	err = Convert(`{"Name":"bob","Active":"yes"}`).JsonDecode(&bad)
+	os.Stdout.WriteString(Fmt("rejected %s\n", Convert(err != nil).String()).String())
+	// Output:
+	// alice true
+	// rejected true
+}
+
+// ExampleDecodeArrayToChan shows how to stream the elements of a JSON array
+// into a channel, so a consumer can process each one as soon as it is decoded.
+func ExampleDecodeArrayToChan() {
+	type Item struct {
+		ID   int
+		Name string
+	}
+
+	ch := make(chan Item, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- DecodeArrayToChan([]byte(`[{"ID":1,"Name":"a"},{"ID":2,"Name":"b"}]`), ch, ChanOptions{CloseChan: true})
+	}()
+
+	for item := range ch {
+		os.Stdout.WriteString(Fmt("%d %s\n", item.ID, item.Name).String())
+	}
+	if err := <-errc; err != nil {
+		os.Stdout.WriteString("decode error: " + err.Error())
+	}
+	// Output:
+	// 1 a
+	// 2 b
+}
+
+// ExampleDocument_views shows how a Document exposes views of parts of a JSON
+// value: raw text of a subtree, or a typed decode of a single path, without
+// decoding the rest of the input.
+func ExampleDocument_views() {
+	doc, err := ParseDocument([]byte(`{"user":{"name":"alice","tags":["admin","dev"]},"count":2}`))
+	if err != nil {
+		os.Stdout.WriteString("parse error: " + err.Error())
+		return
+	}
+
+	raw, _ := doc.Raw("user.tags")
+	os.Stdout.WriteString(raw + "\n")
+
+	var name string
+	if err := doc.DecodePath("user.name", &name); err == nil {
+		os.Stdout.WriteString(name + "\n")
+	}
+
+	var tag string
+	if err := doc.DecodePath("user.tags.1", &tag); err == nil {
+		os.Stdout.WriteString(tag + "\n")
+	}
+	// Output:
+	// ["admin","dev"]
+	// alice
+	// dev
+}
@@ -0,0 +1,324 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// URL query / form encoding (application/x-www-form-urlencoded)
+// Flat structs for GET endpoints and HTML forms, keyed like JSON objects: the
+// json tag name or Go name in the SetFieldNaming convention when encoding, and
+// the FieldMatch rules when decoding. Values use their JSON text without quotes,
+// so numbers, times ([TimeFormat]), []byte (base64) and custom codecs read the
+// same in both formats:
+//
+//	type Search struct {
+//		Query string   `json:"q"`
+//		Page  int      `json:"page"`
+//		Tags  []string `json:"tag"`
+//	}
+//	// q=go+json&page=2&tag=wasm&tag=tinygo
+//
+// Slices and arrays repeat their key once per element; nil pointers and nil
+// slices are left out. Nested structs, maps and interfaces are rejected since
+// the format has no nesting. Secure fields are never written or read.
+
+// FormEncode converts the struct held by the current value to a form-encoded
+// string, without a leading '?'
+//
+// Usage pattern:
+//
+//	query, err := Convert(&search).FormEncode()
+//	url := "/api/search?" + query
+func (c *refValue) FormEncode() (string, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+
+	v := c
+	if v.refKind() == tpPointer {
+		v = v.refElem()
+	}
+	if !v.refIsValid() || v.refKind() != tpStruct {
+		return "", Err(errUnsupportedType, "form encoding needs a struct")
+	}
+	var structInfo refStructType
+	if err := structMetadataFor(v, &structInfo); err != nil {
+		return "", err
+	}
+
+	dst := jh.jOut[:0]
+	for i := range v.refNumField() {
+		field := v.refField(i)
+		if !field.refIsValid() || !isExportedField(structInfo.fields[i].name) || isEncryptedField(structInfo.fields[i].tag.Get("secure")) {
+			continue
+		}
+		info := &structInfo.fields[i]
+		key := taggedFieldName(info.name, info.tag.Get("tiny"), info.tag.Get("json"), jh.jNaming)
+
+		var err error
+		if isFormList(field.Type()) {
+			for j := range field.refLen() {
+				if dst, err = jh.appendFormPair(dst, key, field.refIndex(j)); err != nil {
+					break
+				}
+			}
+		} else {
+			dst, err = jh.appendFormPair(dst, key, field)
+		}
+		if err != nil {
+			jh.jOut = dst
+			return "", Err(errUnsupportedType, "field "+key+": "+err.Error())
+		}
+	}
+	jh.jOut = dst
+	return string(dst), nil
+}
+
+// appendFormPair appends key=value for the scalar v, preceded by '&' after the
+// first pair; nil pointers append nothing
+func (jh *jsonH) appendFormPair(dst []byte, key string, v *refValue) ([]byte, error) {
+	for v.refKind() == tpPointer {
+		if v = v.refElem(); !v.refIsValid() {
+			return dst, nil
+		}
+	}
+	if !isFormScalar(v.Type()) {
+		return dst, Err(errUnsupportedType, "form values must be scalars, got: "+v.refKind().String())
+	}
+
+	var err error
+	if jh.jEsc, err = jh.appendJsonFieldValue(jh.jEsc[:0], v); err != nil {
+		return dst, err
+	}
+	text := string(jh.jEsc)
+	if len(text) >= 2 && text[0] == '"' {
+		if text, err = jh.unescapeJsonString(text[1 : len(text)-1]); err != nil {
+			return dst, err
+		}
+	}
+
+	if len(dst) > 0 {
+		dst = append(dst, '&')
+	}
+	dst = appendFormEscaped(dst, key)
+	dst = append(dst, '=')
+	return appendFormEscaped(dst, text), nil
+}
+
+// FormDecode decodes the form-encoded string held by the current value into
+// the struct target points to
+// A leading '?' is ignored, so a raw query string can be passed as is. Keys
+// without a field are skipped; a repeated key fills a slice or array field, and
+// for other fields the last value wins. Fields whose key is absent are left
+// untouched, and an empty value zeroes a non-string field. A checkbox's "on"
+// decodes as true.
+//
+// Usage pattern:
+//
+//	var search Search
+//	err := Convert(request.URL.RawQuery).FormDecode(&search)
+func (c *refValue) FormDecode(target any, opts ...DecodeOption) error {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	if target == nil {
+		return Err(errInvalidForm, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return Err(errInvalidForm, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() || elem.refKind() != tpStruct {
+		return Err(errInvalidForm, "target must point to a struct")
+	}
+	plan, err := decodePlanFor(elem)
+	if err != nil {
+		return err
+	}
+
+	query := c.getString()
+	if len(query) > 0 && query[0] == '?' {
+		query = query[1:]
+	}
+	var counts []int // values seen per list field, allocated on the first one
+	for len(query) > 0 {
+		pair := query
+		if end := indexByte(query, '&'); end != -1 {
+			pair, query = query[:end], query[end+1:]
+		} else {
+			query = ""
+		}
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue := pair, ""
+		if eq := indexByte(pair, '='); eq != -1 {
+			rawKey, rawValue = pair[:eq], pair[eq+1:]
+		}
+
+		key, err := unescapeForm(rawKey)
+		if err != nil {
+			return err
+		}
+		index := plan.fieldIndex(key, jh.jMatch)
+		if index == -1 || plan.fields[index].secure || plan.fields[index].unexported {
+			continue
+		}
+		field := elem.refField(index)
+		if !field.refIsValid() {
+			continue
+		}
+		value, err := unescapeForm(rawValue)
+		if err != nil {
+			return Err(errInvalidForm, "field "+key+": "+err.Error())
+		}
+
+		if isFormList(field.Type()) {
+			if counts == nil {
+				counts = make([]int, len(plan.fields))
+			}
+			err = jh.decodeFormListValue(value, field, counts[index])
+			counts[index]++
+		} else {
+			err = jh.decodeFormValue(value, field)
+		}
+		if err != nil {
+			return Err(errInvalidForm, "field "+key+": "+err.Error())
+		}
+	}
+	return nil
+}
+
+// decodeFormListValue stores the n-th value of a repeated key into a slice or
+// array field; the first value replaces the previous slice
+func (jh *jsonH) decodeFormListValue(value string, field *refValue, n int) error {
+	if field.refKind() == tpArray {
+		if n >= field.refLen() {
+			return nil // extra values are ignored, like extra JSON elements
+		}
+		if n == 0 {
+			memclr(field.ptr, field.Type().Size())
+		}
+		return jh.decodeFormValue(value, field.refIndex(n))
+	}
+
+	switch {
+	case n == 0:
+		field.refSet(refMakeSlice(field.Type(), 1, jsonSliceMinCap))
+	case n < (*jsonSliceHeader)(field.ptr).cap:
+		(*jsonSliceHeader)(field.ptr).len = n + 1
+	default:
+		growJsonSlice(field, n, nil)
+		(*jsonSliceHeader)(field.ptr).len = n + 1
+	}
+	return jh.decodeFormValue(value, field.refIndex(n))
+}
+
+// decodeFormValue decodes one value into a scalar target, through the JSON
+// parser so every format matches the JSON encoding
+func (jh *jsonH) decodeFormValue(value string, target *refValue) error {
+	t := target.Type()
+	for t != nil && t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+	if t == nil || !isFormScalar(t) {
+		return Err(errUnsupportedType, "form values must be scalars, got: "+target.refKind().String())
+	}
+
+	switch {
+	case value == "" && t.Kind() != tpString:
+		memclr(target.ptr, target.Type().Size())
+		return nil
+	case isFormText(t):
+		jh.jEsc = escapeAndQuoteJsonString(jh.jEsc[:0], value, false)
+		value = string(jh.jEsc)
+	case value == "on" && t.Kind() == tpBool:
+		value = "true"
+	}
+	return jh.parseJsonValueWithRefReflect(value, target)
+}
+
+// isFormScalar reports whether t holds a single form value
+func isFormScalar(t *refType) bool {
+	return isScalarType(t) || t == timeType || isFormBytes(t)
+}
+
+// isFormText reports whether values of t are JSON strings, written unquoted in
+// forms
+func isFormText(t *refType) bool {
+	if t.Kind() == tpString || isFormBytes(t) {
+		return true
+	}
+	return t == timeType && jsonTimeFormat != TimeUnixSeconds && jsonTimeFormat != TimeUnixMillis
+}
+
+// isFormBytes reports whether t is []byte, a single base64 value
+func isFormBytes(t *refType) bool {
+	return t.Kind() == tpSlice && t.Elem() != nil && t.Elem().Kind() == tpUint8
+}
+
+// isFormList reports whether t is a slice or array whose elements are written
+// as repeated keys
+func isFormList(t *refType) bool {
+	return (t.Kind() == tpSlice || t.Kind() == tpArray) && !isFormBytes(t)
+}
+
+// appendFormEscaped appends s percent-encoded for a form: unreserved bytes are
+// kept, spaces become '+' and everything else %XX
+func appendFormEscaped(dst []byte, s string) []byte {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case isUpperASCII(b) || isLowerASCII(b) || b >= '0' && b <= '9' ||
+			b == '-' || b == '_' || b == '.' || b == '~':
+			dst = append(dst, b)
+		case b == ' ':
+			dst = append(dst, '+')
+		default:
+			dst = append(dst, '%', hex[b>>4], hex[b&0x0f])
+		}
+	}
+	return dst
+}
+
+// unescapeForm decodes a percent-encoded key or value, '+' meaning a space
+func unescapeForm(s string) (string, error) {
+	if indexByte(s, '%') == -1 && indexByte(s, '+') == -1 {
+		return s, nil
+	}
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '+':
+			out = append(out, ' ')
+		case '%':
+			if i+2 >= len(s) {
+				return "", Err(errInvalidForm, "invalid escape: "+s[i:])
+			}
+			hi, lo := hexDigitValue(s[i+1]), hexDigitValue(s[i+2])
+			if hi < 0 || lo < 0 {
+				return "", Err(errInvalidForm, "invalid escape: "+s[i:i+3])
+			}
+			out = append(out, byte(hi<<4|lo))
+			i += 2
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return string(out), nil
+}
+
+// hexDigitValue returns the value of a hexadecimal digit, or -1
+func hexDigitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
@@ -0,0 +1,102 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestFormEncode(t *testing.T) {
+	clearRefStructsCache()
+
+	type Search struct {
+		Query  string    `json:"q"`
+		Page   int       `json:"page"`
+		Ratio  float64   `json:"ratio"`
+		Exact  bool      `json:"exact"`
+		Tags   []string  `json:"tag"`
+		Limit  *uint8    `json:"limit"`
+		Since  time.Time `json:"since"`
+		Cursor []byte    `json:"cursor"`
+		Token  string    `json:"token" secure:"encrypt"`
+	}
+
+	in := Search{
+		Query: "go & json=fast", Page: 2, Ratio: 0.5, Exact: true, Tags: []string{"wasm", "tiny go"},
+		Since: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Cursor: []byte{0xfb, 0xff}, Token: "secret",
+	}
+	out, err := Convert(&in).FormEncode()
+	if err != nil {
+		t.Fatalf("FormEncode returned error: %v", err)
+	}
+	expected := "q=go+%26+json%3Dfast&page=2&ratio=0.5&exact=true&tag=wasm&tag=tiny+go" +
+		"&since=2024-01-02T03%3A04%3A05Z&cursor=%2B%2F8%3D"
+	if out != expected {
+		t.Errorf("FormEncode = %s, expected %s", out, expected)
+	}
+
+	type Nested struct {
+		Inner struct{ A int }
+	}
+	if _, err := Convert(&Nested{}).FormEncode(); err == nil {
+		t.Error("FormEncode should reject nested structs")
+	}
+	if _, err := Convert([]int{1}).FormEncode(); err == nil {
+		t.Error("FormEncode should reject non-structs")
+	}
+}
+
+func TestFormDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	type Search struct {
+		Query   string    `json:"q"`
+		Page    int       `json:"page"`
+		Exact   bool      `json:"exact"`
+		Tags    []string  `json:"tag"`
+		Scores  [2]int    `json:"score"`
+		Limit   *uint8    `json:"limit"`
+		Since   time.Time `json:"since"`
+		Cursor  []byte    `json:"cursor"`
+		Untouch string    `json:"untouched"`
+	}
+
+	got := Search{Page: 9, Tags: []string{"old"}, Untouch: "kept"}
+	query := "?q=go+%26+json%3Dfast&page=&exact=on&tag=a&tag=b&tag=c&score=1&score=2&score=3" +
+		"&limit=7&since=2024-01-02T03%3A04%3A05Z&cursor=%2B%2F8%3D&unknown=1&&"
+	if err := Convert(query).FormDecode(&got); err != nil {
+		t.Fatalf("FormDecode returned error: %v", err)
+	}
+	if got.Query != "go & json=fast" || got.Page != 0 || !got.Exact || got.Untouch != "kept" {
+		t.Errorf("FormDecode scalars = %+v", got)
+	}
+	if len(got.Tags) != 3 || got.Tags[0] != "a" || got.Tags[2] != "c" || got.Scores != [2]int{1, 2} {
+		t.Errorf("FormDecode lists = %v %v", got.Tags, got.Scores)
+	}
+	if got.Limit == nil || *got.Limit != 7 || !got.Since.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) ||
+		string(got.Cursor) != "\xfb\xff" {
+		t.Errorf("FormDecode = %+v", got)
+	}
+
+	// Round trip through FormEncode
+	encoded, err := Convert(&got).FormEncode()
+	if err != nil {
+		t.Fatalf("FormEncode returned error: %v", err)
+	}
+	var again Search
+	if err := Convert(encoded).FormDecode(&again); err != nil || again.Query != got.Query || len(again.Tags) != 3 {
+		t.Errorf("round trip = %+v, %v", again, err)
+	}
+
+	for _, bad := range []string{"page=abc", "limit=300", "q=%zz", "exact=maybe", "page=%4"} {
+		var s Search
+		if err := Convert(bad).FormDecode(&s); err == nil {
+			t.Errorf("FormDecode(%s) should fail, got %+v", bad, s)
+		}
+	}
+	var notStruct int
+	if err := Convert("a=1").FormDecode(&notStruct); err == nil {
+		t.Error("FormDecode into an int should fail")
+	}
+}
@@ -1,590 +1,940 @@
-package tinywodp
-
-import (
-	"sync"
-
-	. "github.com/cdvelop/tinystring"
-)
-
-// jsonH - JSON Handler for race-condition-free operations
-// All mutable state for JSON operations is isolated in this struct
-// Each JSON operation gets its own instance from the pool, ensuring thread safety
-type jsonH struct {
-	jTmp string   // String buffer for last operation (replaces refValue.tmpStr)
-	jBuf []string // Field parsing buffer (pre-allocated 16 capacity)
-	jEsc []byte   // Escape processing buffer (pre-allocated 256 capacity)
-	jSep string   // Field separator (from refValue.separator)
-}
-
-// Pool for jsonH instances to minimize allocations
-// TinyGo compatible - sync.Pool works perfectly in TinyGo
-var jsonHPool = sync.Pool{
-	New: func() interface{} {
-		return &jsonH{
-			jBuf: make([]string, 0, 16),
-			jEsc: make([]byte, 0, 256),
-		}
-	},
-}
-
-// getJsonH retrieves a jsonH instance from pool with proper initialization
-// Resets all buffers while preserving allocated capacity for memory efficiency
-func getJsonH(separator string) *jsonH {
-	jh := jsonHPool.Get().(*jsonH)
-	jh.jSep = separator
-	jh.jTmp = ""          // Reset string buffer
-	jh.jBuf = jh.jBuf[:0] // Reset slice but keep capacity
-	jh.jEsc = jh.jEsc[:0] // Reset byte slice but keep capacity
-	return jh
-}
-
-// putJsonH returns a jsonH instance to the pool for reuse
-// Should always be called with defer to ensure proper cleanup
-func putJsonH(jh *jsonH) {
-	// Clear sensitive data before returning to pool
-	jh.jTmp = ""
-	jh.jSep = ""
-	jsonHPool.Put(jh)
-}
-
-// resetBuffers clears all working buffers in jsonH
-// Used internally to ensure clean state between operations
-func (jh *jsonH) resetBuffers() {
-	jh.jTmp = ""
-	jh.jBuf = jh.jBuf[:0]
-	jh.jEsc = jh.jEsc[:0]
-}
-
-// appendToTmp appends string to jTmp buffer
-// Replaces direct tmpStr assignment for thread safety
-func (jh *jsonH) appendToTmp(s string) {
-	jh.jTmp += s
-}
-
-// setTmp sets jTmp buffer to specific value
-// Replaces direct tmpStr assignment for thread safety
-func (jh *jsonH) setTmp(s string) {
-	jh.jTmp = s
-}
-
-// getTmp returns current jTmp buffer value
-// Replaces direct tmpStr access for thread safety
-func (jh *jsonH) getTmp() string {
-	return jh.jTmp
-}
-
-// appendToBuf adds string to jBuf parsing buffer
-// Used for field parsing operations that need string accumulation
-func (jh *jsonH) appendToBuf(s string) {
-	jh.jBuf = append(jh.jBuf, s)
-}
-
-// getBuf returns current jBuf slice
-// Provides access to accumulated parsing buffer
-func (jh *jsonH) getBuf() []string {
-	return jh.jBuf
-}
-
-// appendToEsc adds bytes to jEsc escape buffer
-// Used for JSON escape sequence processing
-func (jh *jsonH) appendToEsc(b []byte) {
-	jh.jEsc = append(jh.jEsc, b...)
-}
-
-// appendByteToEsc adds single byte to jEsc escape buffer
-// Optimized for single character escape operations
-func (jh *jsonH) appendByteToEsc(b byte) {
-	jh.jEsc = append(jh.jEsc, b)
-}
-
-// getEsc returns current jEsc buffer
-// Provides access to escape processing buffer
-func (jh *jsonH) getEsc() []byte {
-	return jh.jEsc
-}
-
-// getSep returns field separator for this JSON handler
-// Provides access to separator configuration
-func (jh *jsonH) getSep() string {
-	return jh.jSep
-}
-
-// ============================================================================
-// JSON DECODE OPERATIONS - Thread-safe implementations
-// ============================================================================
-
-// decode parses JSON string and populates the target value
-// This is the main entry point for JSON decoding operations using jsonH
-func (jh *jsonH) decode(jsonStr string, target any) error {
-	if target == nil {
-		return Err(errInvalidJSON, "target cannot be nil")
-	}
-
-	// Use our custom reflection for target analysis
-	rv := refValueOf(target)
-	// Debug: Check what kind we get for the pointer
-	targetKind := rv.refKind()
-	if targetKind != tpPointer {
-		return Err(errInvalidJSON, "target must be a pointer, got: "+targetKind.String())
-	}
-
-	// Get the element that the pointer points to
-	elem := rv.refElem()
-	if !elem.refIsValid() {
-		return Err(errInvalidJSON, "target pointer is nil or invalid")
-	}
-
-	// Debug: Check what kind we get for the element
-	elemKind := elem.refKind()
-	if elemKind.String() == "invalid" {
-		return Err(errInvalidJSON, "element kind is invalid - reflection issue")
-	}
-
-	// Parse JSON and populate the element using our custom reflection
-	return jh.parseJsonValueWithRefReflect(jsonStr, elem)
-}
-
-// parseJsonValueWithRefReflect parses a JSON value using our custom reflection
-// All tmpStr operations are replaced with jh.jTmp for thread safety
-func (jh *jsonH) parseJsonValueWithRefReflect(jsonStr string, target *refValue) error {
-	// Trim whitespace
-	jsonStr = Convert(jsonStr).Trim().String()
-	if len(jsonStr) == 0 {
-		return Err(errInvalidJSON, "empty JSON")
-	}
-	switch target.refKind() {
-	case tpString:
-		return jh.parseJsonStringRef(jsonStr, target)
-	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
-		return jh.parseJsonIntRef(jsonStr, target)
-	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
-		return jh.parseJsonUintRef(jsonStr, target)
-	case tpFloat32, tpFloat64:
-		return jh.parseJsonFloatRef(jsonStr, target)
-	case tpBool:
-		return jh.parseJsonBoolRef(jsonStr, target)
-	case tpStruct:
-		return jh.parseJsonStructRef(jsonStr, target)
-	case tpSlice:
-		return jh.parseJsonSliceRef(jsonStr, target)
-	case tpPointer:
-		return jh.parseJsonPointerRef(jsonStr, target)
-	default:
-		return Err(errUnsupportedType, "for JSON decoding: "+target.refKind().String())
-	}
-}
-
-// ============================================================================
-// JSON PARSING METHODS - Thread-safe implementations for jsonH
-// ============================================================================
-
-// parseJsonStringRef parses a JSON string using our custom reflection
-// All string operations use jh.jTmp instead of refValue.tmpStr for thread safety
-func (jh *jsonH) parseJsonStringRef(jsonStr string, target *refValue) error {
-	jsonStr = Convert(jsonStr).Trim().String()
-
-	// Strict validation: must be a quoted string
-	if len(jsonStr) < 2 || jsonStr[0] != '"' || jsonStr[len(jsonStr)-1] != '"' {
-		// Check if this is actually a different type that should be rejected
-		if jsonStr == "true" || jsonStr == "false" || jsonStr == "null" {
-			return Err(errInvalidJSON, "expected string but got "+jsonStr)
-		}
-		// Check if it's a number
-		if len(jsonStr) > 0 && (jsonStr[0] >= '0' && jsonStr[0] <= '9' || jsonStr[0] == '-') {
-			return Err(errInvalidJSON, "expected string but got number: "+jsonStr)
-		}
-		// Check if it's an array or object
-		if len(jsonStr) > 0 && (jsonStr[0] == '[' || jsonStr[0] == '{') {
-			return Err(errInvalidJSON, "expected string but got complex type")
-		}
-		return Err(errInvalidJSON, "invalid JSON string format")
-	}
-
-	// Remove quotes and decode escape sequences
-	unquoted := jsonStr[1 : len(jsonStr)-1]
-	decoded, err := jh.unescapeJsonString(unquoted)
-	if err != nil {
-		return err
-	}
-	target.refSetString(decoded)
-	return nil
-}
-
-// parseJsonIntRef parses a JSON integer using our custom reflection
-func (jh *jsonH) parseJsonIntRef(jsonStr string, target *refValue) error {
-	jsonStr = Convert(jsonStr).Trim().String()
-
-	// Strict validation: must be a number, not a string or other type
-	if len(jsonStr) > 0 && jsonStr[0] == '"' {
-		return Err(errInvalidJSON, "expected number but got string: "+jsonStr)
-	}
-	if jsonStr == "true" || jsonStr == "false" {
-		return Err(errInvalidJSON, "expected number but got boolean: "+jsonStr)
-	}
-	if len(jsonStr) > 0 && (jsonStr[0] == '[' || jsonStr[0] == '{') {
-		return Err(errInvalidJSON, "expected number but got complex type")
-	}
-	intVal, err := Convert(jsonStr).ToInt64()
-	if err != nil {
-		return Err(errInvalidJSON, "invalid number: "+jsonStr)
-	}
-	target.refSetInt(intVal)
-	return nil
-}
-
-// parseJsonUintRef parses a JSON unsigned integer using our custom reflection
-func (jh *jsonH) parseJsonUintRef(jsonStr string, target *refValue) error {
-	val, err := Convert(jsonStr).ToInt64() // Convert to int64 first, then cast to uint64
-	if err != nil {
-		return err
-	}
-	target.refSetUint(uint64(val))
-	return nil
-}
-
-// parseJsonFloatRef parses a JSON float using our custom reflection
-func (jh *jsonH) parseJsonFloatRef(jsonStr string, target *refValue) error {
-	val, err := Convert(jsonStr).ToFloat()
-	if err != nil {
-		return err
-	}
-	target.refSetFloat(val)
-	return nil
-}
-
-// parseJsonBoolRef parses a JSON boolean using our custom reflection
-func (jh *jsonH) parseJsonBoolRef(jsonStr string, target *refValue) error {
-	jsonStr = Convert(jsonStr).Trim().String()
-
-	// Strict validation: must be exactly true or false
-	if jsonStr == "true" {
-		target.refSetBool(true)
-		return nil
-	} else if jsonStr == "false" {
-		target.refSetBool(false)
-		return nil
-	}
-
-	// Invalid boolean value
-	return Err(errInvalidJSON, "expected boolean but got: "+jsonStr)
-}
-
-// parseJsonStructRef parses a JSON object using our custom reflection
-func (jh *jsonH) parseJsonStructRef(jsonStr string, target *refValue) error {
-	jsonStr = Convert(jsonStr).Trim().String()
-
-	// Must be a JSON object
-	if len(jsonStr) < 2 || jsonStr[0] != '{' || jsonStr[len(jsonStr)-1] != '}' {
-		return Err(errInvalidJSON, "expected object but got: "+jsonStr)
-	}
-
-	// Remove braces
-	content := jsonStr[1 : len(jsonStr)-1]
-	content = Convert(content).Trim().String()
-
-	// Empty object
-	if len(content) == 0 {
-		return nil
-	}
-
-	// Split into fields and parse each one
-	fields, err := jh.splitJsonFields(content)
-	if err != nil {
-		return err
-	}
-
-	return jh.parseStructFields(fields, target)
-}
-
-// parseJsonSliceRef parses a JSON array using our custom reflection
-func (jh *jsonH) parseJsonSliceRef(jsonStr string, target *refValue) error {
-	jsonStr = Convert(jsonStr).Trim().String()
-
-	// Must be a JSON array
-	if len(jsonStr) < 2 || jsonStr[0] != '[' || jsonStr[len(jsonStr)-1] != ']' {
-		return Err(errInvalidJSON, "expected array but got: "+jsonStr)
-	}
-
-	// Remove brackets
-	content := jsonStr[1 : len(jsonStr)-1]
-	content = Convert(content).Trim().String()
-
-	// Empty array
-	if len(content) == 0 {
-		return nil
-	}
-
-	// Split into elements and parse each one
-	elements, err := jh.splitJsonArrayElements(content)
-	if err != nil {
-		return err
-	}
-
-	return jh.parseSliceElements(elements, target)
-}
-
-// parseJsonPointerRef parses a JSON value for a pointer type
-func (jh *jsonH) parseJsonPointerRef(jsonStr string, target *refValue) error {
-	jsonStr = Convert(jsonStr).Trim().String()
-
-	// Handle null
-	if jsonStr == "null" {
-		// Keep pointer as nil
-		return nil
-	}
-
-	// Get the element the pointer points to
-	elem := target.refElem()
-	if !elem.refIsValid() {
-		return Err(errInvalidJSON, "pointer target is invalid")
-	}
-
-	// Parse the value for the pointed-to element
-	return jh.parseJsonValueWithRefReflect(jsonStr, elem)
-}
-
-// splitJsonFields splits JSON object content into key-value pairs
-func (jh *jsonH) splitJsonFields(content string) (map[string]string, error) {
-	fields := make(map[string]string)
-	jh.resetBuffers()
-
-	var key, value string
-	var inString bool
-	var escapeNext bool
-	var braceLevel, bracketLevel int
-	var state int // 0=key, 1=colon, 2=value, 3=comma
-
-	for _, char := range content {
-		if escapeNext {
-			jh.jTmp += string(char)
-			escapeNext = false
-			continue
-		}
-
-		if char == '\\' && inString {
-			jh.jTmp += string(char)
-			escapeNext = true
-			continue
-		}
-
-		if char == '"' && !escapeNext {
-			inString = !inString
-			jh.jTmp += string(char)
-			continue
-		}
-
-		if inString {
-			jh.jTmp += string(char)
-			continue
-		}
-
-		switch char {
-		case '{':
-			braceLevel++
-			jh.jTmp += string(char)
-		case '}':
-			braceLevel--
-			jh.jTmp += string(char)
-		case '[':
-			bracketLevel++
-			jh.jTmp += string(char)
-		case ']':
-			bracketLevel--
-			jh.jTmp += string(char)
-		case ':':
-			if braceLevel == 0 && bracketLevel == 0 && state == 0 {
-				key = Convert(jh.jTmp).Trim().String()
-				jh.jTmp = ""
-				state = 2 // Expecting value
-			} else {
-				jh.jTmp += string(char)
-			}
-		case ',':
-			if braceLevel == 0 && bracketLevel == 0 && state == 2 {
-				value = Convert(jh.jTmp).Trim().String()
-				fields[key] = value
-				jh.jTmp = ""
-				state = 0 // Expecting next key
-			} else {
-				jh.jTmp += string(char)
-			}
-		default:
-			jh.jTmp += string(char)
-		}
-	}
-
-	// Handle last field
-	if state == 2 && len(jh.jTmp) > 0 {
-		value = Convert(jh.jTmp).Trim().String()
-		fields[key] = value
-	}
-
-	return fields, nil
-}
-
-// splitJsonArrayElements splits JSON array content into individual elements
-func (jh *jsonH) splitJsonArrayElements(content string) ([]string, error) {
-	var elements []string
-	jh.resetBuffers()
-
-	var inString bool
-	var escapeNext bool
-	var braceLevel, bracketLevel int
-
-	for _, char := range content {
-		if escapeNext {
-			jh.jTmp += string(char)
-			escapeNext = false
-			continue
-		}
-
-		if char == '\\' && inString {
-			jh.jTmp += string(char)
-			escapeNext = true
-			continue
-		}
-
-		if char == '"' && !escapeNext {
-			inString = !inString
-			jh.jTmp += string(char)
-			continue
-		}
-
-		if inString {
-			jh.jTmp += string(char)
-			continue
-		}
-
-		switch char {
-		case '{':
-			braceLevel++
-			jh.jTmp += string(char)
-		case '}':
-			braceLevel--
-			jh.jTmp += string(char)
-		case '[':
-			bracketLevel++
-			jh.jTmp += string(char)
-		case ']':
-			bracketLevel--
-			jh.jTmp += string(char)
-		case ',':
-			if braceLevel == 0 && bracketLevel == 0 {
-				element := Convert(jh.jTmp).Trim().String()
-				if len(element) > 0 {
-					elements = append(elements, element)
-				}
-				jh.jTmp = ""
-			} else {
-				jh.jTmp += string(char)
-			}
-		default:
-			jh.jTmp += string(char)
-		}
-	}
-
-	// Handle last element
-	if len(jh.jTmp) > 0 {
-		element := Convert(jh.jTmp).Trim().String()
-		if len(element) > 0 {
-			elements = append(elements, element)
-		}
-	}
-
-	return elements, nil
-}
-
-// parseStructFields parses struct fields from JSON key-value pairs
-func (jh *jsonH) parseStructFields(fields map[string]string, target *refValue) error {
-	// Get number of fields in struct
-	numFields := target.refNumField()
-
-	// Get struct type info for field names
-	var structInfo refStructType
-	getStructType(target.Type(), &structInfo)
-
-	// Debug: Print available fields
-	// fmt.Printf("DEBUG: JSON fields: %v\n", fields)
-	// fmt.Printf("DEBUG: Struct has %d fields\n", numFields)
-	// fmt.Printf("DEBUG: StructInfo has %d fields\n", len(structInfo.fields))
-
-	// Parse each field in the struct
-	for i := 0; i < numFields; i++ {
-		if i >= len(structInfo.fields) {
-			continue // Skip if no field info available
-		}
-
-		// Get field name
-		fieldName := structInfo.fields[i].name
-		// fmt.Printf("DEBUG: Field %d: %s\n", i, fieldName)
-
-		// Check if this field exists in the JSON
-		jsonValue, exists := fields[fieldName]
-		if !exists {
-			// fmt.Printf("DEBUG: Field %s not found in JSON\n", fieldName)
-			continue // Skip missing fields
-		}
-
-		// fmt.Printf("DEBUG: Parsing field %s = %s\n", fieldName, jsonValue)
-
-		// Get the field refValue
-		fieldConv := target.refField(i)
-		if !fieldConv.refIsValid() {
-			continue // Skip invalid fields
-		}
-
-		// Parse the JSON value into this field
-		err := jh.parseJsonValueWithRefReflect(jsonValue, fieldConv)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// parseSliceElements parses slice elements from JSON array elements
-func (jh *jsonH) parseSliceElements(elements []string, target *refValue) error {
-	// This is a simplified implementation
-	// In a full implementation, this would create slice elements and parse each one
-	return Err(errUnsupportedType, "slice parsing not fully implemented")
-}
-
-// ============================================================================
-// JSON UTILITY METHODS - Thread-safe implementations for jsonH
-// ============================================================================
-
-// unescapeJsonString unescapes a JSON string value using jh.jEsc buffer
-// Uses jsonH escape buffer to avoid allocations
-func (jh *jsonH) unescapeJsonString(s string) (string, error) {
-	// Reset escape buffer for reuse
-	jh.jEsc = jh.jEsc[:0]
-
-	// Pre-allocate capacity if needed
-	if cap(jh.jEsc) < len(s) {
-		jh.jEsc = make([]byte, 0, len(s))
-	}
-
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\\' && i+1 < len(s) {
-			switch s[i+1] {
-			case '"':
-				jh.jEsc = append(jh.jEsc, '"')
-			case '\\':
-				jh.jEsc = append(jh.jEsc, '\\')
-			case 'n':
-				jh.jEsc = append(jh.jEsc, '\n')
-			case 'r':
-				jh.jEsc = append(jh.jEsc, '\r')
-			case 't':
-				jh.jEsc = append(jh.jEsc, '\t')
-			default:
-				jh.jEsc = append(jh.jEsc, s[i], s[i+1])
-			}
-			i++ // Skip next character
-		} else {
-			jh.jEsc = append(jh.jEsc, s[i])
-		}
-	}
-	return string(jh.jEsc), nil
-}
+package tinywodp
+
+import (
+	"sync"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// jsonH - JSON Handler for race-condition-free operations
+// All mutable state for JSON operations is isolated in this struct
+// Each JSON operation gets its own instance from the pool, ensuring thread safety
+type jsonH struct {
+	jEsc []byte // Escape processing buffer (pre-allocated 256 capacity)
+	jSep string // Field separator (from refValue.separator)
+
+	jNum     NumberPolicy // Number decoding policy for this operation
+	jMatch   FieldMatch   // Struct field matching mode for this operation
+	jUseNum  bool         // Generic numbers decode as Number instead of float64
+	jNull    NullPolicy   // Effect of null on nillable targets
+	jDepth   int          // Current nesting level while decoding
+	jInput   string       // Whole input of the operation, for error positions
+	jErrAt   int          // Offset of the innermost error in jInput, -1 when unknown
+	jErrPath string       // Go path of the innermost error, built while unwinding
+
+	jLenient bool         // Collect field-level errors instead of stopping (CollectErrors)
+	jPath    []byte       // Go path of the value being decoded, lenient mode only
+	jErrors  []FieldError // Failures collected in lenient mode
+
+	jOut    []byte      // Encode output buffer (pre-allocated jsonOutSize capacity)
+	jConv   *refValue   // Scratch for number formatting while encoding
+	jNaming FieldNaming // Field naming convention for encoding
+
+	jNilSlice  NilSliceEncoding  // Encoding of nil slices
+	jBytes     ByteSliceEncoding // Encoding of []byte values
+	jASCII     bool              // Escape non-ASCII runes in encoded strings
+	jHTML      bool              // Escape <, >, &, U+2028 and U+2029 in encoded strings
+	jSortKeys  bool              // Write struct fields sorted by key (SetSortKeys)
+	jNonFinite NonFiniteEncoding // Encoding of NaN and infinite floats
+	jMaxDepth  int               // Nesting limit for encoding and decoding
+
+	jArena    *DecodeArena // Chunked allocation of decoded data, nil for the runtime
+	jWorkers  int          // Goroutines decoding a top-level array, see ParallelDecode
+	jLimits   DecodeLimits // Input bounds checked before decoding, zero for none
+	jTrailing bool         // Data after the first value is ignored (AllowTrailingData)
+	jRelaxed  bool         // Comments and trailing commas are accepted (RelaxedSyntax)
+	jLegacy   bool         // Single quotes and bare keys are accepted (LegacySyntax)
+	jTarget   TargetPolicy // Whether the target is zeroed before decoding (TargetReset)
+}
+
+const (
+	jsonOutSize      = 1024     // initial capacity of jOut
+	maxPooledJsonOut = 64 << 10 // a larger jOut is released, not pooled
+)
+
+// Pool for jsonH instances to minimize allocations
+// TinyGo compatible - sync.Pool works perfectly in TinyGo
+var jsonHPool = sync.Pool{
+	New: func() interface{} {
+		return &jsonH{
+			jEsc:  allocBytes(256),
+			jOut:  allocBytes(jsonOutSize),
+			jConv: newConv(nil),
+		}
+	},
+}
+
+// getJsonH retrieves a jsonH instance from pool with proper initialization
+// Resets all buffers while preserving allocated capacity for memory efficiency
+func getJsonH(separator string) *jsonH {
+	jh := jsonHPool.Get().(*jsonH)
+	jh.jSep = separator
+	jh.jEsc = jh.jEsc[:0] // Reset byte slice but keep capacity
+	jh.jNum = NumberDefault
+	jh.jMatch = MatchDefault
+	jh.jUseNum = false
+	jh.jNull = NullAsNil
+	jh.jDepth = 0
+	jh.jInput = ""
+	jh.jErrAt = -1
+	jh.jErrPath = ""
+	jh.jLenient = false
+	jh.jPath = jh.jPath[:0]
+	jh.jErrors = nil
+	jh.jOut = jh.jOut[:0]
+	jh.jNaming = fieldNaming
+	jh.jNilSlice = nilSliceEncoding
+	jh.jBytes = byteSliceEncoding
+	jh.jASCII = escapeNonASCII
+	jh.jHTML = escapeHTML
+	jh.jSortKeys = sortKeys
+	jh.jNonFinite = nonFiniteEncoding
+	jh.jMaxDepth = maxJsonDepth
+	return jh
+}
+
+// putJsonH returns a jsonH instance to the pool for reuse
+// Should always be called with defer to ensure proper cleanup
+func putJsonH(jh *jsonH) {
+	// Clear sensitive data before returning to pool
+	jh.jSep = ""
+	jh.jNum = NumberDefault
+	jh.jMatch = MatchDefault
+	jh.jUseNum = false
+	jh.jNull = NullAsNil
+	jh.jDepth = 0
+	jh.jInput = ""
+	jh.jErrAt = -1
+	jh.jErrPath = ""
+	jh.jLenient = false
+	jh.jPath = jh.jPath[:0]
+	jh.jErrors = nil
+	// An output buffer grown by one huge document is released so the pool
+	// does not pin its memory
+	if cap(jh.jOut) > maxPooledJsonOut {
+		freeHint(jh.jOut)
+		jh.jOut = allocBytes(jsonOutSize)
+	}
+	jh.jOut = jh.jOut[:0]
+	jh.jConv.tmpStr = ""
+	jh.jConv.err = ""
+	jh.jNaming = FieldNamingPascal
+	jh.jNilSlice = NilSliceAsEmpty
+	jh.jBytes = BytesAsBase64
+	jh.jASCII = false
+	jh.jHTML = false
+	jh.jSortKeys = false
+	jh.jNonFinite = NonFiniteAsError
+	jh.jMaxDepth = DefaultMaxDepth
+	jh.jArena = nil
+	jh.jWorkers = 0
+	jh.jLimits = DecodeLimits{}
+	jh.jTrailing = false
+	jh.jRelaxed = false
+	jh.jLegacy = false
+	jh.jTarget = TargetMerge
+	jsonHPool.Put(jh)
+}
+
+// resetBuffers clears all working buffers in jsonH
+// Used internally to ensure clean state between operations
+func (jh *jsonH) resetBuffers() {
+	jh.jEsc = jh.jEsc[:0]
+}
+
+// appendToEsc adds bytes to jEsc escape buffer
+// Used for JSON escape sequence processing
+func (jh *jsonH) appendToEsc(b []byte) {
+	jh.jEsc = append(jh.jEsc, b...)
+}
+
+// appendByteToEsc adds single byte to jEsc escape buffer
+// Optimized for single character escape operations
+func (jh *jsonH) appendByteToEsc(b byte) {
+	jh.jEsc = append(jh.jEsc, b)
+}
+
+// getEsc returns current jEsc buffer
+// Provides access to escape processing buffer
+func (jh *jsonH) getEsc() []byte {
+	return jh.jEsc
+}
+
+// getSep returns field separator for this JSON handler
+// Provides access to separator configuration
+func (jh *jsonH) getSep() string {
+	return jh.jSep
+}
+
+// ============================================================================
+// JSON DECODE OPERATIONS - Thread-safe implementations
+// ============================================================================
+
+// decode parses JSON string and populates the target value
+// This is the main entry point for JSON decoding operations using jsonH
+// Empty or whitespace-only input fails with ErrEmptyInput before touching the target
+func (jh *jsonH) decode(jsonStr string, target any) error {
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+	jsonStr, err := stripJsonBOM(jsonStr)
+	if err != nil {
+		return err
+	}
+	jsonStr = jh.rewriteJsonSyntax(jsonStr)
+	if isJsonBlank(jsonStr) {
+		return Err(ErrEmptyInput)
+	}
+	if jh.jInput == "" {
+		jh.jInput = jsonStr
+	}
+	if jh.hasLimits() {
+		if err := jh.checkJsonLimits(jsonStr); err != nil {
+			return jh.positionError(err)
+		}
+	}
+
+	// Custom unmarshalers on the target itself work even where pointer types
+	// cannot be looked up (TinyGo)
+	if u, ok := target.(JsonUnmarshaler); ok {
+		raw, err := jh.firstJsonValue(jsonStr)
+		if err != nil {
+			return jh.positionError(err)
+		}
+		return u.UnmarshalJSONTiny([]byte(raw))
+	}
+
+	// Use our custom reflection for target analysis
+	rv := refValueOf(target)
+	// Debug: Check what kind we get for the pointer
+	targetKind := rv.refKind()
+	if targetKind != tpPointer {
+		return Err(errInvalidJSON, "target must be a pointer, got: "+targetKind.String())
+	}
+
+	// Get the element that the pointer points to
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return Err(errInvalidJSON, "target pointer is nil or invalid")
+	}
+
+	// Debug: Check what kind we get for the element
+	elemKind := elem.refKind()
+	if elemKind.String() == "invalid" {
+		return Err(errInvalidJSON, "element kind is invalid - reflection issue")
+	}
+
+	jh.resetJsonTarget(elem)
+
+	// Parse JSON and populate the element using our custom reflection
+	if !jh.decodeParallel(jsonStr, elem) {
+		if err := jh.parseJsonValueWithRefReflect(jsonStr, elem); err != nil {
+			return jh.positionError(err)
+		}
+	}
+	jh.checkRequiredFields(jsonStr, elem)
+	if len(jh.jErrors) > 0 {
+		return jh.takeDecodeErrors()
+	}
+	return nil
+}
+
+// parseJsonValueWithRefReflect parses a JSON value using our custom reflection
+// jsonStr must hold exactly one value, surrounding whitespace aside
+func (jh *jsonH) parseJsonValueWithRefReflect(jsonStr string, target *refValue) error {
+	end, err := jh.parseJsonValueAt(jsonStr, 0, target)
+	if err != nil {
+		return err
+	}
+	return jh.trailingDataError(jsonStr, end)
+}
+
+// parseJsonValueAt decodes the value starting at or after s[i] into target and
+// returns the index just past it. The input is scanned once: containers are
+// decoded member by member as the cursor advances instead of being framed first.
+// On failure the value is marked as the error position unless a nested value was
+func (jh *jsonH) parseJsonValueAt(s string, i int, target *refValue) (int, error) {
+	i = skipJsonSpace(s, i)
+	end, err := jh.scanJsonValue(s, i, target)
+	if err != nil {
+		jh.markJsonError(s, i)
+	}
+	return end, err
+}
+
+// scanJsonValue dispatches the value at s[i] on the target kind
+func (jh *jsonH) scanJsonValue(s string, i int, target *refValue) (int, error) {
+	if i >= len(s) {
+		return i, Err(errInvalidJSON, "empty JSON")
+	}
+	if err := jh.enterJsonDepth(); err != nil {
+		return i, err
+	}
+	defer jh.leaveJsonDepth()
+
+	if isJsonContainerTarget(target) && !needsRawJson(target) {
+		return jh.parseJsonContainer(s, i, target)
+	}
+
+	// Scalars and types with their own decoder get the framed value text
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return end, err
+	}
+	return end, jh.parseJsonValue(s[i:end], target)
+}
+
+// parseJsonValue decodes the framed text of one value: types with their own
+// decoder see it whole, null and scalars are converted here and containers are
+// parsed in place
+func (jh *jsonH) parseJsonValue(jsonStr string, target *refValue) error {
+	if ok, err := decodeCustomJson(jsonStr, target); ok {
+		return err
+	}
+	if stdDecodeHook != nil {
+		if ok, err := stdDecodeHook(jsonStr, target); ok {
+			return err
+		}
+	}
+	if jh.decodeJsonNull(jsonStr, target) {
+		return nil
+	}
+	switch target.refKind() {
+	case tpString:
+		if isBigNumberType(target) {
+			return jh.parseJsonBigNumberRef(jsonStr, target)
+		}
+		return jh.parseJsonStringRef(jsonStr, target)
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return jh.parseJsonIntRef(jsonStr, target)
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return jh.parseJsonUintRef(jsonStr, target)
+	case tpFloat32, tpFloat64:
+		return jh.parseJsonFloatRef(jsonStr, target)
+	case tpBool:
+		return jh.parseJsonBoolRef(jsonStr, target)
+	case tpStruct, tpSlice, tpArray, tpPointer, tpMap, tpInterface:
+		_, err := jh.parseJsonContainer(jsonStr, 0, target)
+		return err
+	default:
+		return unsupportedKindError("for JSON decoding", target.refKind())
+	}
+}
+
+// parseJsonContainer decodes the value at s[i] into a struct, slice, array, map,
+// pointer or interface target and returns the index just past it
+func (jh *jsonH) parseJsonContainer(s string, i int, target *refValue) (int, error) {
+	switch target.refKind() {
+	case tpStruct:
+		return jh.parseJsonStructRef(s, i, target)
+	case tpSlice:
+		return jh.parseJsonSliceRef(s, i, target)
+	case tpArray:
+		return jh.parseJsonArrayRef(s, i, target)
+	case tpPointer:
+		return jh.parseJsonPointerRef(s, i, target)
+	case tpMap:
+		return jh.parseJsonMapRef(s, i, target)
+	default:
+		return jh.parseJsonInterfaceRef(s, i, target)
+	}
+}
+
+// isJsonContainerTarget reports whether target is decoded by parseJsonContainer
+func isJsonContainerTarget(target *refValue) bool {
+	switch target.refKind() {
+	case tpStruct, tpSlice, tpArray, tpPointer, tpMap, tpInterface:
+		return true
+	}
+	return false
+}
+
+// needsRawJson reports whether target decodes from the framed text of its value:
+// time.Time, RawJSON and custom unmarshalers do, and so does every type in
+// stdcompat builds, where the standard interfaces are only probed with the text
+func needsRawJson(target *refValue) bool {
+	if stdDecodeHook != nil {
+		return true
+	}
+	if target.ptr == nil || target.flag&flagAddr == 0 {
+		return false
+	}
+	return isTimeType(target) || isRawJSONType(target) || customCodecFor(target)&codecUnmarshalPtr != 0
+}
+
+// ============================================================================
+// JSON PARSING METHODS - Thread-safe implementations for jsonH
+// ============================================================================
+
+// parseJsonStringRef parses a JSON string using our custom reflection
+// Escapes are decoded through jh.jEsc instead of refValue.tmpStr for thread safety
+func (jh *jsonH) parseJsonStringRef(jsonStr string, target *refValue) error {
+	jsonStr = trimJsonSpace(jsonStr)
+
+	// Strict validation: must be a quoted string
+	if len(jsonStr) < 2 || jsonStr[0] != '"' || jsonStr[len(jsonStr)-1] != '"' {
+		// Check if this is actually a different type that should be rejected
+		if jsonStr == "true" || jsonStr == "false" || jsonStr == "null" {
+			return Err(errInvalidJSON, "expected string but got "+jsonStr)
+		}
+		// Check if it's a number, kept verbatim when the policy allows it
+		if len(jsonStr) > 0 && (jsonStr[0] >= '0' && jsonStr[0] <= '9' || jsonStr[0] == '-') {
+			if jh.jNum == NumberAsString && isJsonNumber(jsonStr) {
+				target.refSetString(jsonStr)
+				return nil
+			}
+			return Err(errInvalidJSON, "expected string but got number: "+jsonStr)
+		}
+		// Check if it's an array or object
+		if len(jsonStr) > 0 && (jsonStr[0] == '[' || jsonStr[0] == '{') {
+			return Err(errInvalidJSON, "expected string but got complex type")
+		}
+		return Err(errInvalidJSON, "invalid JSON string format")
+	}
+
+	// Remove quotes and decode escape sequences
+	unquoted := jsonStr[1 : len(jsonStr)-1]
+	decoded, err := jh.unescapeJsonString(unquoted)
+	if err != nil {
+		return err
+	}
+	target.refSetString(decoded)
+	return nil
+}
+
+// parseJsonIntRef parses a JSON integer using our custom reflection
+func (jh *jsonH) parseJsonIntRef(jsonStr string, target *refValue) error {
+	jsonStr = trimJsonSpace(jsonStr)
+
+	// Strict validation: must be a number, not a string or other type
+	if len(jsonStr) > 0 && jsonStr[0] == '"' {
+		return Err(errInvalidJSON, "expected number but got string: "+jsonStr)
+	}
+	if jsonStr == "true" || jsonStr == "false" {
+		return Err(errInvalidJSON, "expected number but got boolean: "+jsonStr)
+	}
+	if len(jsonStr) > 0 && (jsonStr[0] == '[' || jsonStr[0] == '{') {
+		return Err(errInvalidJSON, "expected number but got complex type")
+	}
+	if !isJsonNumber(jsonStr) {
+		return Err(errInvalidJSON, "invalid number: "+jsonStr)
+	}
+	if err := jh.checkNumberPrecision(jsonStr, target); err != nil {
+		return err
+	}
+	var intVal int64
+	if isJsonIntegerLiteral(jsonStr) {
+		v, err := Convert(jsonStr).ToInt64()
+		if err != nil {
+			return Err(errInvalidJSON, "invalid number: "+jsonStr)
+		}
+		intVal = v
+	} else {
+		// Exponent forms such as 1e9 or 2.5e3 are fine when they denote a whole number
+		f, ok := parseJsonFloat(jsonStr, 64)
+		if !ok || f < -1<<63 || f >= 1<<63 {
+			return Err(errInvalidJSON, "number out of range for "+target.refKind().String()+": "+jsonStr)
+		}
+		if f != float64(int64(f)) {
+			return Err(errInvalidJSON, "expected integer but got: "+jsonStr)
+		}
+		intVal = int64(f)
+	}
+	if err := checkIntRange(intVal, jsonStr, target); err != nil {
+		return err
+	}
+	target.refSetInt(intVal)
+	return nil
+}
+
+// parseJsonUintRef parses a JSON unsigned integer using our custom reflection
+func (jh *jsonH) parseJsonUintRef(jsonStr string, target *refValue) error {
+	if !isJsonNumber(jsonStr) {
+		return Err(errInvalidJSON, "invalid number: "+jsonStr)
+	}
+	if err := jh.checkNumberPrecision(jsonStr, target); err != nil {
+		return err
+	}
+	if len(jsonStr) > 0 && jsonStr[0] == '-' {
+		// -0, -0.0 and -0e5 are zero and fit any unsigned target
+		if !isJsonZero(jsonStr[1:]) {
+			return Err(errInvalidJSON, "negative number for "+target.refKind().String()+": "+jsonStr)
+		}
+		jsonStr = jsonStr[1:]
+	}
+	var val uint64
+	if isJsonIntegerLiteral(jsonStr) {
+		// Parsed directly, so values above the int64 range reach uint64
+		var ok bool
+		if val, ok = parseJsonUintLiteral(jsonStr); !ok {
+			return Err(errInvalidJSON, "number out of range for "+target.refKind().String()+": "+jsonStr)
+		}
+	} else if intVal, err := Convert(jsonStr).ToInt64(); err == nil {
+		val = uint64(intVal)
+	} else {
+		// Exponent forms such as 1.8e19 can exceed the int64 range
+		floatVal, ok := parseJsonFloat(jsonStr, 64)
+		if !ok || floatVal >= 1<<64 || floatVal != float64(uint64(floatVal)) {
+			return Err(errInvalidJSON, "number out of range for "+target.refKind().String()+": "+jsonStr)
+		}
+		val = uint64(floatVal)
+	}
+	if err := checkUintRange(val, jsonStr, target); err != nil {
+		return err
+	}
+	target.refSetUint(val)
+	return nil
+}
+
+// parseJsonFloatRef parses a JSON float using our custom reflection
+func (jh *jsonH) parseJsonFloatRef(jsonStr string, target *refValue) error {
+	if !isJsonNumber(jsonStr) {
+		return Err(errInvalidJSON, "invalid number: "+jsonStr)
+	}
+	if err := jh.checkNumberPrecision(jsonStr, target); err != nil {
+		return err
+	}
+	// Rounded once, to the target width, so float32 fields get the nearest float32
+	val, ok := parseJsonFloat(jsonStr, floatBitSize(target))
+	if !ok {
+		return Err(errInvalidJSON, "number out of range for "+target.refKind().String()+": "+jsonStr)
+	}
+	target.refSetFloat(val)
+	return nil
+}
+
+// parseJsonBoolRef parses a JSON boolean using our custom reflection
+func (jh *jsonH) parseJsonBoolRef(jsonStr string, target *refValue) error {
+	jsonStr = trimJsonSpace(jsonStr)
+
+	// Strict validation: must be exactly true or false
+	if jsonStr == "true" {
+		target.refSetBool(true)
+		return nil
+	} else if jsonStr == "false" {
+		target.refSetBool(false)
+		return nil
+	}
+
+	// Invalid boolean value
+	return Err(errInvalidJSON, "expected boolean but got: "+jsonStr)
+}
+
+// parseJsonStructRef parses the JSON object at s[i] into a struct
+// Keys are matched by json tag, exact field name, then snake_case field name;
+// unknown keys are skipped and a repeated key is decoded again, so the last
+// occurrence wins
+func (jh *jsonH) parseJsonStructRef(s string, i int, target *refValue) (int, error) {
+	if s[i] != '{' {
+		return jh.parseJsonMismatch(s, i, target, "object")
+	}
+
+	// Field lookups are compiled once per struct type
+	plan, err := decodePlanFor(target)
+	if err != nil {
+		return i, err
+	}
+	if len(plan.defaults) > 0 {
+		if err := jh.applyJsonDefaults(target, plan); err != nil {
+			return i, err
+		}
+	}
+
+	i, done, err := jh.openJsonContainer(s, i, '}')
+	for !done {
+		if err != nil {
+			return i, err
+		}
+		var jsonKey string
+		if jsonKey, i, err = jh.scanJsonKey(s, i); err != nil {
+			return i, err
+		}
+		if i, err = jh.parseJsonStructField(jsonKey, s, i, target, plan); err != nil {
+			return i, err
+		}
+		i, done, err = jh.nextJsonMember(s, i, '}')
+	}
+	return i, nil
+}
+
+// parseJsonStructField decodes the member value at s[i] into the field matching
+// jsonKey, or skips it when no field matches
+func (jh *jsonH) parseJsonStructField(jsonKey, s string, i int, target *refValue, plan *decodePlan) (int, error) {
+	fieldIndex := plan.fieldIndex(jsonKey, jh.jMatch)
+	if fieldIndex == -1 || plan.fields[fieldIndex].skipDecode {
+		return jh.skipJsonValueAt(s, i)
+	}
+	fieldConv := target.refField(fieldIndex)
+	if !fieldConv.refIsValid() {
+		return jh.skipJsonValueAt(s, i)
+	}
+	field := &plan.fields[fieldIndex]
+	if field.setter != nil {
+		return jh.setUnexportedField(field, s, i, target)
+	}
+
+	// Encrypted fields carry their JSON value sealed inside a base64 string
+	if field.secure {
+		end, err := jh.skipJsonValueAt(s, i)
+		if err != nil {
+			return end, err
+		}
+		opened, err := openJsonField(s[i:end])
+		if err != nil {
+			jh.prefixErrorPath(field.name)
+			return i, err
+		}
+		if _, err := jh.parseJsonField(field.name, opened, 0, fieldConv); err != nil {
+			return i, err
+		}
+		return end, nil
+	}
+
+	if len(decodeHooks) > 0 {
+		if end, handled, err := jh.runDecodeHooks(field.name, s, i, fieldConv); handled {
+			return end, err
+		}
+	}
+
+	if field.quoted && s[i] == '"' {
+		return jh.parseJsonQuotedField(field.name, s, i, fieldConv)
+	}
+
+	// Plain scalars go straight to their setter; lenient mode still needs the
+	// path bookkeeping of parseJsonField
+	if field.parse != nil && !jh.jLenient && stdDecodeHook == nil {
+		end, err := jh.skipJsonValueAt(s, i)
+		if err != nil {
+			return end, err
+		}
+		if err := field.parse(jh, s[i:end], fieldConv); err != nil {
+			jh.prefixErrorPath(field.name)
+			return i, jh.errorAt(s, i, err)
+		}
+		return end, nil
+	}
+
+	return jh.parseJsonField(field.name, s, i, fieldConv)
+}
+
+// parseJsonSliceRef parses the JSON array at s[i] into a slice; byte slices
+// also accept a base64 string
+// Elements are decoded in place as they are scanned: the backing array doubles
+// when full and the slice is cut to the element count at the end. An empty
+// array decodes to an empty, non-nil slice. Each element goes through
+// parseJsonValueAt, so any supported kind nests: [][]float64, []map[string]any,
+// [][2]int or []*T.
+func (jh *jsonH) parseJsonSliceRef(s string, i int, target *refValue) (int, error) {
+	if s[i] == '"' && isJsonByteSlice(target) {
+		return jh.parseJsonBytesRef(s, i, target)
+	}
+	if s[i] != '[' {
+		return jh.parseJsonMismatch(s, i, target, "array")
+	}
+
+	i, done, err := jh.openJsonContainer(s, i, ']')
+	if err != nil {
+		return i, err
+	}
+	if done {
+		target.refSet(refMakeSlice(target.Type(), 0, 0))
+		return i, nil
+	}
+
+	makeJsonSlice(target, jsonSliceMinCap, jh.jArena)
+	n := 0
+	for ; !done; n++ {
+		if n == target.refLen() {
+			growJsonSlice(target, n, jh.jArena)
+		}
+		elemValue := target.refIndex(n)
+		if !elemValue.refIsValid() {
+			return i, Err(errInvalidJSON, "cannot access slice element at index "+Convert(n).String())
+		}
+		if i, err = jh.parseJsonElement(n, s, i, elemValue); err != nil {
+			return i, Err(errInvalidJSON, "failed to parse element "+Convert(n).String()+": "+err.Error())
+		}
+		if i, done, err = jh.nextJsonMember(s, i, ']'); err != nil {
+			return i, err
+		}
+	}
+	(*jsonSliceHeader)(target.ptr).len = n
+	return i, nil
+}
+
+// jsonSliceMinCap is the initial capacity of a decoded non-empty slice
+const jsonSliceMinCap = 4
+
+// jsonSliceHeader mirrors the runtime layout of a slice, so a decoded slice can
+// be cut to its element count without copying
+type jsonSliceHeader struct {
+	data unsafe.Pointer
+	len  int
+	cap  int
+}
+
+// parseJsonArrayRef parses the JSON array at s[i] into a fixed-size array
+// Like encoding/json, extra JSON elements are ignored and missing ones are zeroed
+func (jh *jsonH) parseJsonArrayRef(s string, i int, target *refValue) (int, error) {
+	if s[i] != '[' {
+		// null leaves the array untouched
+		if end, err := skipJsonLiteral(s, i); err == nil && s[i:end] == "null" {
+			return end, nil
+		}
+		return jh.parseJsonMismatch(s, i, target, "array")
+	}
+
+	arrayLen := target.refLen()
+	i, done, err := jh.openJsonContainer(s, i, ']')
+	if err != nil {
+		return i, err
+	}
+	n := 0
+	for ; !done; n++ {
+		if n < arrayLen {
+			elemValue := target.refIndex(n)
+			if !elemValue.refIsValid() {
+				return i, Err(errInvalidJSON, "cannot access array element at index "+Convert(n).String())
+			}
+			if i, err = jh.parseJsonElement(n, s, i, elemValue); err != nil {
+				return i, Err(errInvalidJSON, "failed to parse element "+Convert(n).String()+": "+err.Error())
+			}
+		} else if i, err = jh.skipJsonValueAt(s, i); err != nil {
+			return i, err
+		}
+		if i, done, err = jh.nextJsonMember(s, i, ']'); err != nil {
+			return i, err
+		}
+	}
+
+	for ; n < arrayLen; n++ {
+		elemValue := target.refIndex(n)
+		memclr(elemValue.ptr, elemValue.Type().Size())
+	}
+	return i, nil
+}
+
+// parseJsonPointerRef parses the value at s[i] for a pointer type
+// Nil pointers are allocated before parsing into the pointed-to element
+// Chains like **T or *[]*T are filled one level per call: the element of a
+// pointer to pointer is parsed here again, allocating each missing level
+func (jh *jsonH) parseJsonPointerRef(s string, i int, target *refValue) (int, error) {
+	// null sets the pointer itself to nil (decodeJsonNull), so a target reused
+	// across decodes drops what an earlier payload set; only NullKeep keeps it.
+	// It must be checked before the element is reused below, or null would be
+	// parsed into the old pointee instead
+	if end, ok := jh.parseJsonNull(s, i, target); ok {
+		return end, nil
+	}
+
+	// Reuse the existing element when the pointer is already set
+	elem := target.refElem()
+	if elem.refIsValid() {
+		return jh.parseJsonValueAt(s, i, elem)
+	}
+
+	// Get the element type that the pointer points to
+	elemType := target.Type().Elem()
+	if elemType == nil {
+		return i, Err(errUnsupportedType, "pointer element type is nil")
+	}
+
+	// Allocate the element with its real type, so the garbage collector sees
+	// the pointers inside it ([]*T elements holding strings, nested pointers)
+	elemValue, err := jh.newJsonValue(elemType)
+	if err != nil {
+		return i, err
+	}
+	elemValue.separator = jh.jSep
+
+	// Parse the JSON into the element value
+	end, err := jh.parseJsonValueAt(s, i, elemValue)
+	if err != nil {
+		return end, err
+	}
+
+	// Set the pointer to point to our allocated memory
+	*(*unsafe.Pointer)(target.ptr) = elemValue.ptr
+	return end, nil
+}
+
+// ============================================================================
+// JSON CURSOR METHODS - Single-pass scanning shared by the container parsers
+// ============================================================================
+
+// openJsonContainer moves past the '{' or '[' at s[i]; done reports an empty
+// container, closed by close, otherwise the first member or element follows
+func (jh *jsonH) openJsonContainer(s string, i int, close byte) (int, bool, error) {
+	i = skipJsonSpace(s, i+1)
+	if i >= len(s) {
+		return i, false, jh.errorAt(s, i, Err(errInvalidJSON, "unexpected end of input"))
+	}
+	if s[i] == close {
+		return i + 1, true, nil
+	}
+	return i, false, nil
+}
+
+// scanJsonKey reads the member name at or after s[i] up to its colon and
+// returns the unescaped name with the start of the member value
+func (jh *jsonH) scanJsonKey(s string, i int) (string, int, error) {
+	i = skipJsonSpace(s, i)
+	if i >= len(s) || s[i] != '"' {
+		return "", i, jh.errorAt(s, i, Err(errInvalidJSON, "expected object key"))
+	}
+	end, err := skipJsonString(s, i)
+	if err != nil {
+		return "", end, jh.errorAt(s, end, err)
+	}
+	key, err := jh.unescapeJsonString(s[i+1 : end-1])
+	if err != nil {
+		return "", i, jh.errorAt(s, i, err)
+	}
+
+	j := skipJsonSpace(s, end)
+	if j >= len(s) || s[j] != ':' {
+		return "", j, jh.errorAt(s, j, Err(errInvalidJSON, "missing ':' after key "+s[i:end]))
+	}
+	if j = skipJsonSpace(s, j+1); j >= len(s) {
+		return "", j, jh.errorAt(s, j, Err(errInvalidJSON, "missing value for key "+s[i:end]))
+	}
+	return key, j, nil
+}
+
+// nextJsonMember moves past the separator after a member or element; done
+// reports the closing byte, otherwise the next member or element follows
+func (jh *jsonH) nextJsonMember(s string, i int, close byte) (int, bool, error) {
+	i = skipJsonSpace(s, i)
+	switch {
+	case i >= len(s):
+		return i, false, jh.errorAt(s, i, Err(errInvalidJSON, "unexpected end of input, expected ',' or '"+string(close)+"'"))
+	case s[i] == close:
+		return i + 1, true, nil
+	case s[i] == ',':
+		return i + 1, false, nil // [1,] and {"a":1,} fail on the next member
+	}
+	return i, false, jh.errorAt(s, i, Err(errInvalidJSON, "expected ',' or '"+string(close)+"' but got: "+string(s[i])))
+}
+
+// skipJsonValueAt returns the index just past the value at or after s[i],
+// marking the error position when it is malformed
+func (jh *jsonH) skipJsonValueAt(s string, i int) (int, error) {
+	end, err := skipJsonValue(s, i)
+	if err != nil {
+		return end, jh.errorAt(s, end, err)
+	}
+	return end, nil
+}
+
+// parseJsonNull consumes the null literal at s[i] when the null policy applies
+// to target, reporting whether it did
+func (jh *jsonH) parseJsonNull(s string, i int, target *refValue) (int, bool) {
+	if s[i] != 'n' {
+		return i, false
+	}
+	end, err := skipJsonLiteral(s, i)
+	if err != nil || !jh.decodeJsonNull(s[i:end], target) {
+		return i, false
+	}
+	return end, true
+}
+
+// parseJsonMismatch handles a value at s[i] that does not open the container
+// expected by target: null still clears nillable targets, anything else fails
+func (jh *jsonH) parseJsonMismatch(s string, i int, target *refValue, expected string) (int, error) {
+	if end, ok := jh.parseJsonNull(s, i, target); ok {
+		return end, nil
+	}
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return end, err
+	}
+	return i, Err(errInvalidJSON, "expected "+expected+" but got: "+s[i:end])
+}
+
+// ============================================================================
+// JSON UTILITY METHODS - Thread-safe implementations for jsonH
+// ============================================================================
+
+// unescapeJsonString unescapes a JSON string value using jh.jEsc buffer
+// Uses jsonH escape buffer to avoid allocations
+// Handles every escape from RFC 8259 including \uXXXX and UTF-16 surrogate pairs
+func (jh *jsonH) unescapeJsonString(s string) (string, error) {
+	// Fast path: nothing to unescape, the body only needs validating
+	if indexByte(s, '\\') == -1 {
+		for i := 0; i < len(s); i++ {
+			if err := checkJsonStringByte(s[i]); err != nil {
+				return "", err
+			}
+		}
+		return s, nil
+	}
+
+	// Reset escape buffer for reuse
+	jh.jEsc = jh.jEsc[:0]
+
+	// Pre-allocate capacity if needed
+	if cap(jh.jEsc) < len(s) {
+		freeHint(jh.jEsc)
+		jh.jEsc = allocBytes(len(s))
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			if err := checkJsonStringByte(s[i]); err != nil {
+				return "", err
+			}
+			jh.jEsc = append(jh.jEsc, s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return "", Err(errInvalidJSON, "unterminated escape sequence")
+		}
+
+		switch s[i+1] {
+		case '"':
+			jh.jEsc = append(jh.jEsc, '"')
+		case '\\':
+			jh.jEsc = append(jh.jEsc, '\\')
+		case '/':
+			jh.jEsc = append(jh.jEsc, '/')
+		case 'b':
+			jh.jEsc = append(jh.jEsc, '\b')
+		case 'f':
+			jh.jEsc = append(jh.jEsc, '\f')
+		case 'n':
+			jh.jEsc = append(jh.jEsc, '\n')
+		case 'r':
+			jh.jEsc = append(jh.jEsc, '\r')
+		case 't':
+			jh.jEsc = append(jh.jEsc, '\t')
+		case 'u':
+			r, consumed, err := decodeJsonUnicodeEscape(s[i:])
+			if err != nil {
+				return "", err
+			}
+			jh.jEsc = appendRuneUTF8(jh.jEsc, r)
+			i += consumed - 2 // loop skips the remaining escape byte below
+		default:
+			return "", Err(errInvalidJSON, "invalid escape sequence: \\"+string(s[i+1]))
+		}
+		i++ // Skip escape character
+	}
+	if jh.jArena != nil {
+		return jh.jArena.copyString(jh.jEsc), nil
+	}
+	return string(jh.jEsc), nil
+}
+
+// checkJsonStringByte rejects bytes that must be escaped inside a JSON string:
+// an unescaped quote would end the string early and control characters are invalid
+func checkJsonStringByte(b byte) error {
+	if b == '"' {
+		return Err(errInvalidJSON, "unescaped quote in string")
+	}
+	if b < 0x20 {
+		return Err(errInvalidJSON, "invalid control character in string")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package tinywodp
+
+// Pluggable memory allocation for internal buffers
+// Firmware-style deployments with static memory pools can route the library's
+// working buffers (escape buffers, stream encoder/decoder buffers) through their
+// own arenas. Values returned to the caller and decoded data are still allocated
+// by the Go runtime, so the garbage collector keeps tracking them.
+
+// Allocator supplies and reclaims internal byte buffers
+//
+// Usage pattern (call once at startup, before any encode/decode):
+//
+//	tinywodp.SetAllocator(myArena)
+type Allocator interface {
+	// AllocBytes returns a buffer with length 0 and capacity of at least n
+	AllocBytes(n int) []byte
+	// FreeHint reports that the library no longer references b
+	// Implementations may recycle it; ignoring the hint is always safe
+	FreeHint(b []byte)
+}
+
+// allocator is the installed Allocator, nil means the Go runtime (make)
+var allocator Allocator
+
+// SetAllocator installs a for all internal buffers; nil restores the default
+// Not safe to call concurrently with encoding or decoding
+func SetAllocator(a Allocator) {
+	allocator = a
+}
+
+// allocBytes returns an empty buffer with capacity for at least n bytes
+func allocBytes(n int) []byte {
+	if allocator != nil {
+		if b := allocator.AllocBytes(n); cap(b) >= n {
+			return b[:0]
+		}
+	}
+	return make([]byte, 0, n)
+}
+
+// freeHint passes a released internal buffer back to the installed allocator
+func freeHint(b []byte) {
+	if allocator != nil && cap(b) > 0 {
+		allocator.FreeHint(b[:0])
+	}
+}
@@ -0,0 +1,58 @@
+package tinywodp
+
+import (
+	"testing"
+)
+
+// countingAllocator hands out buffers from the Go runtime and counts hook calls
+type countingAllocator struct {
+	allocs int
+	frees  int
+}
+
+func (a *countingAllocator) AllocBytes(n int) []byte {
+	a.allocs++
+	return make([]byte, 0, n)
+}
+
+func (a *countingAllocator) FreeHint(b []byte) {
+	a.frees++
+}
+
+func TestAllocatorHooks(t *testing.T) {
+	counter := &countingAllocator{}
+	SetAllocator(counter)
+	defer SetAllocator(nil)
+
+	// Decoder buffers come from the allocator, growth releases the old one
+	data := make([]byte, decoderReadSize*3+2)
+	for i := range data {
+		data[i] = 'a'
+	}
+	data[0], data[len(data)-1] = '"', '"'
+
+	dec := NewJsonDecoder(&testReader{data: string(data), chunk: decoderReadSize, eof: errTestEOF})
+	var s string
+	if err := dec.Decode(&s); err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if len(s) != decoderReadSize*3 {
+		t.Errorf("decoded %d bytes, expected %d", len(s), decoderReadSize*3)
+	}
+
+	if counter.allocs < 2 {
+		t.Errorf("expected decoder to allocate through hooks, got %d AllocBytes calls", counter.allocs)
+	}
+	if counter.frees < 1 {
+		t.Errorf("expected FreeHint when the decoder buffer grows, got %d calls", counter.frees)
+	}
+}
+
+func TestAllocBytesDefault(t *testing.T) {
+	SetAllocator(nil)
+	b := allocBytes(64)
+	if len(b) != 0 || cap(b) < 64 {
+		t.Errorf("allocBytes(64) = len %d cap %d", len(b), cap(b))
+	}
+	freeHint(b) // must be a no-op without an allocator
+}
@@ -0,0 +1,152 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Generic JSON values
+// Decoding into an empty interface produces the same shapes as encoding/json:
+//
+//	object -> map[string]any
+//	array  -> []any
+//	string -> string
+//	number -> float64 (Number with UseNumber or NumberAsString)
+//	true/false -> bool
+//	null   -> nil
+//
+//	var v any
+//	err := Convert(payload).JsonDecode(&v)
+//	for _, key := range Keys(v) { ... }
+
+// parseJsonInterfaceRef decodes the JSON value at s[i] into an empty interface target
+// Other interfaces are decoded into the non-nil pointer they already hold
+func (jh *jsonH) parseJsonInterfaceRef(s string, i int, target *refValue) (int, error) {
+	if end, ok := jh.parseJsonNull(s, i, target); ok {
+		return end, nil
+	}
+	if !target.Type().isEmptyInterface() {
+		if pointee := target.refInterfacePointee(); pointee != nil {
+			return jh.parseJsonValueAt(s, i, pointee)
+		}
+		return i, Err(errUnsupportedType, "for JSON decoding: only empty interface targets (any) or interfaces holding a pointer are supported")
+	}
+	value, end, err := jh.parseJsonGenericAt(s, i)
+	if err != nil {
+		return end, err
+	}
+	return end, target.refSetInterface(value)
+}
+
+// parseJsonGenericAt converts the value at or after s[i] into its generic Go
+// representation and returns the index just past it
+// On failure the value is marked as the error position unless a nested value was
+func (jh *jsonH) parseJsonGenericAt(s string, i int) (any, int, error) {
+	i = skipJsonSpace(s, i)
+	value, end, err := jh.parseJsonGenericValue(s, i)
+	if err != nil {
+		jh.markJsonError(s, i)
+	}
+	return value, end, err
+}
+
+// parseJsonGenericValue dispatches the value at s[i] on its first byte
+func (jh *jsonH) parseJsonGenericValue(s string, i int) (any, int, error) {
+	if i >= len(s) {
+		return nil, i, Err(errInvalidJSON, "empty JSON")
+	}
+	if err := jh.enterJsonDepth(); err != nil {
+		return nil, i, err
+	}
+	defer jh.leaveJsonDepth()
+
+	switch s[i] {
+	case '{':
+		return jh.parseJsonGenericObject(s, i)
+	case '[':
+		return jh.parseJsonGenericArray(s, i)
+	case '"':
+		end, err := skipJsonString(s, i)
+		if err != nil {
+			return nil, end, jh.errorAt(s, end, err)
+		}
+		str, err := jh.unescapeJsonString(s[i+1 : end-1])
+		return str, end, err
+	}
+
+	end, err := skipJsonLiteral(s, i)
+	if err != nil {
+		return nil, end, jh.errorAt(s, end, err)
+	}
+	switch literal := s[i:end]; literal {
+	case "null":
+		return nil, end, nil
+	case "true":
+		return true, end, nil
+	case "false":
+		return false, end, nil
+	default:
+		value, err := jh.parseJsonGenericNumber(literal)
+		return value, end, err
+	}
+}
+
+// parseJsonGenericObject converts the JSON object at s[i] into map[string]any
+func (jh *jsonH) parseJsonGenericObject(s string, i int) (any, int, error) {
+	obj := map[string]any{}
+	i, done, err := jh.openJsonContainer(s, i, '}')
+	for !done {
+		if err != nil {
+			return nil, i, err
+		}
+		var key string
+		if key, i, err = jh.scanJsonKey(s, i); err != nil {
+			return nil, i, err
+		}
+		var value any
+		if value, i, err = jh.parseJsonGenericAt(s, i); err != nil {
+			return nil, i, err
+		}
+		obj[key] = value
+		i, done, err = jh.nextJsonMember(s, i, '}')
+	}
+	return obj, i, nil
+}
+
+// parseJsonGenericArray converts the JSON array at s[i] into []any
+func (jh *jsonH) parseJsonGenericArray(s string, i int) (any, int, error) {
+	arr := []any{}
+	i, done, err := jh.openJsonContainer(s, i, ']')
+	for !done {
+		if err != nil {
+			return nil, i, err
+		}
+		var value any
+		if value, i, err = jh.parseJsonGenericAt(s, i); err != nil {
+			return nil, i, Err(errInvalidJSON, "failed to parse element "+Convert(len(arr)).String()+": "+err.Error())
+		}
+		arr = append(arr, value)
+		i, done, err = jh.nextJsonMember(s, i, ']')
+	}
+	return arr, i, nil
+}
+
+// parseJsonGenericNumber converts a JSON number following the number policy
+func (jh *jsonH) parseJsonGenericNumber(jsonStr string) (any, error) {
+	if !isJsonNumber(jsonStr) {
+		return nil, Err(errInvalidJSON, "unexpected value: "+jsonStr)
+	}
+	if jh.jUseNum || jh.jNum == NumberAsString {
+		return Number(jsonStr), nil
+	}
+	switch jh.jNum {
+	case NumberStrict:
+		if isJsonIntegerLiteral(jsonStr) && exceedsFloatPrecision(jsonStr) {
+			return nil, Err(errInvalidJSON, "number exceeds float64 precision: "+jsonStr+" (use UseNumber)")
+		}
+	}
+	f, ok := parseJsonFloat(jsonStr, 64)
+	if !ok {
+		return nil, Err(errInvalidJSON, "number out of range for float64: "+jsonStr)
+	}
+	return f, nil
+}
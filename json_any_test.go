@@ -0,0 +1,156 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonDecodeInterface(t *testing.T) {
+	var v any
+	payload := `{"name":"widget","count":3,"ok":true,"none":null,"tags":["a",{"x":1.5}],"empty":{}}`
+	if err := Convert(payload).JsonDecode(&v); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		t.Fatalf("expected map[string]any, got %T", v)
+	}
+	if obj["name"] != "widget" || obj["count"] != float64(3) || obj["ok"] != true {
+		t.Errorf("unexpected scalar values: %v", obj)
+	}
+	if value, exists := obj["none"]; !exists || value != nil {
+		t.Errorf("null should decode to a present nil value, got %v", value)
+	}
+	tags, ok := obj["tags"].([]any)
+	if !ok || len(tags) != 2 || tags[0] != "a" {
+		t.Fatalf("tags = %#v, expected []any", obj["tags"])
+	}
+	if nested, ok := tags[1].(map[string]any); !ok || nested["x"] != 1.5 {
+		t.Errorf("nested object = %#v", tags[1])
+	}
+	if empty, ok := obj["empty"].(map[string]any); !ok || len(empty) != 0 {
+		t.Errorf("empty object = %#v", obj["empty"])
+	}
+}
+
+func TestJsonDecodeInterfaceScalars(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`"text"`, "text"},
+		{`42`, float64(42)},
+		{`false`, false},
+		{`null`, nil},
+	}
+	for _, tt := range tests {
+		var v any = "previous"
+		if err := Convert(tt.input).JsonDecode(&v); err != nil {
+			t.Fatalf("JsonDecode(%s) returned error: %v", tt.input, err)
+		}
+		if v != tt.expected {
+			t.Errorf("JsonDecode(%s) = %#v, expected %#v", tt.input, v, tt.expected)
+		}
+	}
+
+	var v any
+	if err := Convert(`nope`).JsonDecode(&v); err == nil {
+		t.Error("invalid literal should return error")
+	}
+}
+
+func TestJsonDecodeInterfaceField(t *testing.T) {
+	clearRefStructsCache()
+
+	type Envelope struct {
+		Kind    string
+		Payload any
+	}
+	var env Envelope
+	if err := Convert(`{"Kind":"ping","Payload":[1,"two"]}`).JsonDecode(&env); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	payload, ok := env.Payload.([]any)
+	if !ok || len(payload) != 2 || payload[0] != float64(1) || payload[1] != "two" {
+		t.Errorf("Payload = %#v", env.Payload)
+	}
+
+	var big any
+	if err := Convert(`12345678901234567890`).JsonDecode(&big, NumberAsString); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if big != BigNumber("12345678901234567890") {
+		t.Errorf("NumberAsString = %#v, expected BigNumber", big)
+	}
+}
+
+type anyShape interface{ Area() int }
+
+type anySquare struct{ Side int }
+
+func (s *anySquare) Area() int { return s.Side * s.Side }
+
+func TestJsonDecodeNonEmptyInterface(t *testing.T) {
+	clearRefStructsCache()
+
+	type Drawing struct {
+		Name  string
+		Shape anyShape
+	}
+
+	// A held pointer is decoded in place
+	square := &anySquare{Side: 1}
+	drawing := Drawing{Shape: square}
+	if err := Convert(`{"Name":"a","Shape":{"Side":3}}`).JsonDecode(&drawing); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if drawing.Shape != square || square.Side != 3 || drawing.Shape.Area() != 9 {
+		t.Errorf("Shape = %#v", drawing.Shape)
+	}
+
+	// null clears it, and an empty interface field has nothing to decode into
+	if err := Convert(`{"Shape":null}`).JsonDecode(&drawing); err != nil || drawing.Shape != nil {
+		t.Errorf("null: Shape = %#v, %v", drawing.Shape, err)
+	}
+	if err := Convert(`{"Shape":{"Side":2}}`).JsonDecode(&drawing); err == nil {
+		t.Error("expected an error for a nil non-empty interface")
+	}
+
+	v := refValueOf(&drawing).refElem().refField(1)
+	if !v.refIsNil() {
+		t.Error("refIsNil should report the nil interface")
+	}
+	if err := v.refSetInterface(&anySquare{Side: 4}); err != nil || drawing.Shape.Area() != 16 || v.refIsNil() {
+		t.Errorf("refSetInterface: %v, Shape = %#v", err, drawing.Shape)
+	}
+	if elem := v.refInterfaceElem(); elem == nil || elem.refKind() != tpPointer {
+		t.Errorf("refInterfaceElem = %v", elem)
+	}
+	if err := v.refSetInterface(anySquare{}); err == nil {
+		t.Error("expected an error for a value lacking the interface methods")
+	}
+	if err := v.refSetInterface(nil); err != nil || drawing.Shape != nil {
+		t.Errorf("refSetInterface(nil): %v, Shape = %#v", err, drawing.Shape)
+	}
+}
+
+func TestJsonEncodeDecodedInterface(t *testing.T) {
+	var v any
+	payload := `{"name":"widget","tags":["a",{"y":2,"x":1.5}],"none":null,"empty":{}}`
+	if err := Convert(payload).JsonDecode(&v); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+
+	// A decoded tree goes back out with its keys sorted
+	expected := `{"empty":{},"name":"widget","none":null,"tags":["a",{"x":1.5,"y":2}]}`
+	out, err := Marshal(v)
+	if err != nil || string(out) != expected {
+		t.Errorf("Marshal = %s, %v; expected %s", out, err, expected)
+	}
+	encoded, err := Convert(v).JsonEncode()
+	if err != nil || string(encoded) != expected {
+		t.Errorf("JsonEncode = %s, %v; expected %s", encoded, err, expected)
+	}
+}
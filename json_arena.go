@@ -0,0 +1,145 @@
+package tinywodp
+
+import (
+	"unsafe"
+)
+
+// Decode arenas
+// Batch ingestion decodes thousands of records whose slices, pointed-to
+// structs and escaped strings are otherwise allocated one by one. A
+// DecodeArena passed as a DecodeOption carves them from large chunks instead:
+// one chunk per element type, so the garbage collector still sees every
+// pointer, and one byte chunk for string text.
+//
+//	arena := tinywodp.NewDecodeArena(0)
+//	for _, batch := range batches {
+//		var users []User
+//		err := Convert(batch).JsonDecode(&users, arena)
+//		...
+//		arena.Release() // once the batch is processed
+//	}
+//
+// Decoded values stay valid after Release: chunks are ordinary garbage
+// collected memory, freed together once nothing decoded from them is still
+// referenced. The trade-off is that a single live value keeps its whole chunk
+// alive, and slices that grow while decoding leave their smaller copies in the
+// chunk until then. Large slices bypass the arena. CBOR and MessagePack
+// decoding use it for slices too. An arena must not be shared by concurrent
+// decodes.
+
+// DefaultArenaChunk is the chunk size, in bytes, of NewDecodeArena(0)
+const DefaultArenaChunk = 64 << 10
+
+// DecodeArena allocates decoded data in chunks; see NewDecodeArena
+type DecodeArena struct {
+	chunkSize int
+	chunks    map[*refType]*arenaChunk
+	text      []byte
+}
+
+// arenaChunk is the storage of one element type: cap values at base, the first
+// used of them handed out
+type arenaChunk struct {
+	base unsafe.Pointer
+	used int
+	cap  int
+}
+
+// NewDecodeArena returns an arena allocating chunks of about chunkSize bytes;
+// zero or less means DefaultArenaChunk
+func NewDecodeArena(chunkSize int) *DecodeArena {
+	if chunkSize <= 0 {
+		chunkSize = DefaultArenaChunk
+	}
+	return &DecodeArena{chunkSize: chunkSize, chunks: map[*refType]*arenaChunk{}}
+}
+
+// applyDecode routes the operation's allocations through the arena
+func (a *DecodeArena) applyDecode(jh *jsonH) {
+	jh.jArena = a
+}
+
+// Release drops the arena's chunks, so they are freed once the values decoded
+// from them are unreachable; later decodes start new chunks
+func (a *DecodeArena) Release() {
+	a.chunks = map[*refType]*arenaChunk{}
+	a.text = nil
+}
+
+// alloc returns storage for n zeroed values of type t
+// Requests larger than a quarter chunk get storage of their own
+func (a *DecodeArena) alloc(t *refType, n int) (unsafe.Pointer, error) {
+	size := int(t.Size())
+	if size == 0 || n*size > a.chunkSize/4 {
+		return runtimeNewArray(t, n)
+	}
+	c := a.chunks[t]
+	if c == nil || c.cap-c.used < n {
+		capacity := max(a.chunkSize/size, n)
+		base, err := runtimeNewArray(t, capacity)
+		if err != nil {
+			return nil, err
+		}
+		c = &arenaChunk{base: base, cap: capacity}
+		a.chunks[t] = c
+	}
+	ptr := unsafe.Add(c.base, c.used*size)
+	c.used += n
+	return ptr, nil
+}
+
+// copyString copies b into the arena's text chunk
+func (a *DecodeArena) copyString(b []byte) string {
+	if len(b) == 0 || len(b) > a.chunkSize/4 {
+		return string(b)
+	}
+	if cap(a.text)-len(a.text) < len(b) {
+		a.text = make([]byte, 0, a.chunkSize)
+	}
+	start := len(a.text)
+	a.text = append(a.text, b...)
+	return unsafe.String(&a.text[start], len(b))
+}
+
+// makeJsonSlice sets the slice target to n zeroed elements, from arena unless
+// it is nil
+func makeJsonSlice(target *refValue, n int, arena *DecodeArena) {
+	if arena != nil && n > 0 {
+		if data, err := arena.alloc(target.Type().Elem(), n); err == nil {
+			*(*jsonSliceHeader)(target.ptr) = jsonSliceHeader{data: data, len: n, cap: n}
+			return
+		}
+	}
+	target.refSet(refMakeSlice(target.Type(), n, n))
+}
+
+// growJsonSlice doubles the backing array of v, a full slice of n elements,
+// allocating from arena unless it is nil
+func growJsonSlice(v *refValue, n int, arena *DecodeArena) {
+	old := *(*jsonSliceHeader)(v.ptr)
+	makeJsonSlice(v, 2*n, arena)
+	grown := *(*jsonSliceHeader)(v.ptr)
+	elemType := v.Type().Elem()
+	size := int(elemType.Size())
+	for j := 0; j < n; j++ {
+		runtimeCopy(elemType, unsafe.Add(grown.data, j*size), unsafe.Add(old.data, j*size))
+	}
+}
+
+// newJsonValue allocates a zeroed, addressable value of type t, from the arena
+// when the operation has one
+func (jh *jsonH) newJsonValue(t *refType) (*refValue, error) {
+	if jh.jArena == nil {
+		return refNewValue(t)
+	}
+	ptr, err := jh.jArena.alloc(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &refValue{
+		separator: "_",
+		typ:       t,
+		ptr:       ptr,
+		flag:      refFlag(t.Kind()) | flagAddr,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type arenaTag struct {
+	Key   string
+	Value string
+}
+
+type arenaUser struct {
+	Name    string
+	Tags    []arenaTag
+	Scores  []int
+	Manager *arenaTag
+}
+
+func TestDecodeArena(t *testing.T) {
+	clearRefStructsCache()
+
+	input := `[` +
+		`{"Name":"ana \"a\"","Tags":[{"Key":"k1","Value":"v\n1"}],"Scores":[1,2,3,4,5,6],"Manager":{"Key":"m"}},` +
+		`{"Name":"bo","Tags":[{"Key":"k2"},{"Key":"k3"}],"Scores":[7]}` +
+		`]`
+
+	var plain, pooled []arenaUser
+	if err := Convert(input).JsonDecode(&plain); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	arena := NewDecodeArena(0)
+	if err := Convert(input).JsonDecode(&pooled, arena); err != nil {
+		t.Fatalf("JsonDecode with an arena failed: %v", err)
+	}
+
+	want, _ := Convert(&plain).JsonEncode()
+	got, _ := Convert(&pooled).JsonEncode()
+	if string(got) != string(want) {
+		t.Errorf("arena decode differs:\n got  %s\n want %s", got, want)
+	}
+
+	// Both users' tags came from one chunk
+	tagType := refValueOf(pooled[0].Tags).Type().Elem()
+	if c := arena.chunks[tagType]; c == nil || c.used < 3 {
+		t.Errorf("tags were not allocated from the arena: %+v", c)
+	}
+
+	// Decoded values outlive Release; appending copies out of the chunk
+	arena.Release()
+	if len(arena.chunks) != 0 {
+		t.Error("Release kept chunks")
+	}
+	pooled[1].Tags = append(pooled[1].Tags, arenaTag{Key: "k4"})
+	if pooled[0].Name != `ana "a"` || pooled[0].Tags[0].Value != "v\n1" || pooled[0].Manager.Key != "m" || len(pooled[1].Tags) != 3 {
+		t.Errorf("got %+v", pooled)
+	}
+
+	// Other decoders take the same option
+	bin, err := Convert(&plain).CborEncode()
+	if err != nil {
+		t.Fatalf("CborEncode failed: %v", err)
+	}
+	var fromCbor []arenaUser
+	if err := Convert(bin).CborDecode(&fromCbor, arena); err != nil || len(fromCbor) != 2 || fromCbor[1].Tags[1].Key != "k3" {
+		t.Errorf("CborDecode with an arena: %+v, %v", fromCbor, err)
+	}
+}
+
+func TestDecodeArenaLargeSlices(t *testing.T) {
+	arena := NewDecodeArena(64)
+	var values []int64
+	if err := Convert(`[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17]`).JsonDecode(&values, arena); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if len(values) != 17 || values[16] != 17 {
+		t.Errorf("got %v", values)
+	}
+}
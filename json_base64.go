@@ -0,0 +1,156 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Standard base64 (RFC 4648, padded) without importing encoding/base64
+// Keeps binary size minimal for TinyGo/WASM builds. Used for sealed fields and
+// for []byte values, which encode as base64 strings like encoding/json.
+
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// base64EncodedLen returns the length of the padded encoding of n bytes
+func base64EncodedLen(n int) int {
+	return (n + 2) / 3 * 4
+}
+
+// appendBase64 appends the padded standard base64 encoding of src to dst
+func appendBase64(dst, src []byte) []byte {
+	for len(src) >= 3 {
+		v := uint(src[0])<<16 | uint(src[1])<<8 | uint(src[2])
+		dst = append(dst,
+			base64Alphabet[v>>18&0x3F], base64Alphabet[v>>12&0x3F],
+			base64Alphabet[v>>6&0x3F], base64Alphabet[v&0x3F])
+		src = src[3:]
+	}
+
+	switch len(src) {
+	case 1:
+		v := uint(src[0]) << 16
+		dst = append(dst, base64Alphabet[v>>18&0x3F], base64Alphabet[v>>12&0x3F], '=', '=')
+	case 2:
+		v := uint(src[0])<<16 | uint(src[1])<<8
+		dst = append(dst, base64Alphabet[v>>18&0x3F], base64Alphabet[v>>12&0x3F], base64Alphabet[v>>6&0x3F], '=')
+	}
+	return dst
+}
+
+// base64Value returns the 6-bit value of a base64 character, or -1 if invalid
+func base64Value(c byte) int {
+	switch {
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 26
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 52
+	case c == '+':
+		return 62
+	case c == '/':
+		return 63
+	}
+	return -1
+}
+
+// decodeBase64 decodes padded standard base64 text
+func decodeBase64(s string) ([]byte, error) {
+	if len(s)%4 != 0 {
+		return nil, Err(errInvalidJSON, "invalid base64 length")
+	}
+
+	result := make([]byte, 0, len(s)/4*3)
+	for i := 0; i < len(s); i += 4 {
+		var v uint
+		pad := 0
+		for j := 0; j < 4; j++ {
+			c := s[i+j]
+			if c == '=' && i+4 == len(s) && j >= 2 {
+				pad++
+				v <<= 6
+				continue
+			}
+			d := base64Value(c)
+			if d < 0 || pad > 0 {
+				return nil, Err(errInvalidJSON, "invalid base64 data")
+			}
+			v = v<<6 | uint(d)
+		}
+		result = append(result, byte(v>>16))
+		if pad < 2 {
+			result = append(result, byte(v>>8))
+		}
+		if pad < 1 {
+			result = append(result, byte(v))
+		}
+	}
+	return result, nil
+}
+
+// ByteSliceEncoding controls how []byte values are encoded
+type ByteSliceEncoding uint8
+
+const (
+	// BytesAsBase64 encodes []byte as a padded base64 string, like encoding/json (default)
+	BytesAsBase64 ByteSliceEncoding = iota
+	// BytesAsArray encodes []byte as an array of numbers, one per byte
+	BytesAsArray
+)
+
+// byteSliceEncoding is the active []byte representation
+var byteSliceEncoding = BytesAsBase64
+
+// SetByteSliceEncoding changes how []byte values are encoded
+// Decoding accepts both forms regardless of this setting
+// Not safe to call concurrently with encoding
+func SetByteSliceEncoding(e ByteSliceEncoding) {
+	byteSliceEncoding = e
+}
+
+// isJsonByteSlice reports whether v is a slice of bytes; fixed-size byte
+// arrays are not, and keep encoding element by element
+func isJsonByteSlice(v *refValue) bool {
+	if v.refKind() != tpSlice {
+		return false
+	}
+	elem := v.Type().Elem()
+	return elem != nil && elem.Kind() == tpUint8
+}
+
+// encodesAsBase64 reports whether v is written as a base64 string
+func (jh *jsonH) encodesAsBase64(v *refValue) bool {
+	return jh.jBytes == BytesAsBase64 && isJsonByteSlice(v)
+}
+
+// appendJsonBytes appends the byte slice held by v as a quoted base64 string
+// A nil slice follows the nil slice encoding: "" by default, null with NilSliceAsNull
+func (jh *jsonH) appendJsonBytes(dst []byte, v *refValue) []byte {
+	if isNilSlice(v) {
+		if jh.jNilSlice == NilSliceAsNull {
+			return append(dst, "null"...)
+		}
+		return append(dst, '"', '"')
+	}
+	dst = append(dst, '"')
+	dst = appendBase64(dst, *(*[]byte)(v.ptr))
+	return append(dst, '"')
+}
+
+// parseJsonBytesRef decodes the base64 string at s[i] into a byte slice target
+func (jh *jsonH) parseJsonBytesRef(s string, i int, target *refValue) (int, error) {
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return end, err
+	}
+	text, err := jh.unescapeJsonString(s[i+1 : end-1])
+	if err != nil {
+		return i, err
+	}
+	decoded, err := decodeBase64(text)
+	if err != nil {
+		return i, err
+	}
+	// Named byte slice types share the layout of []byte
+	*(*[]byte)(target.ptr) = decoded
+	return end, nil
+}
@@ -0,0 +1,97 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonByteSliceBase64(t *testing.T) {
+	clearRefStructsCache()
+
+	type Blob []byte
+	type Upload struct {
+		Name      string
+		Avatar    []byte
+		Signature Blob
+		Empty     []byte
+		Missing   []byte
+		Hash      [4]byte
+		Parts     [][]byte
+	}
+
+	in := Upload{
+		Name:      "photo",
+		Avatar:    []byte("hello, world"),
+		Signature: Blob{0x00, 0xff, 0x10},
+		Empty:     []byte{},
+		Hash:      [4]byte{1, 2, 3, 4},
+		Parts:     [][]byte{[]byte("a"), []byte("bc")},
+	}
+	expected := `{"Name":"photo","Avatar":"aGVsbG8sIHdvcmxk","Signature":"AP8Q","Empty":"","Missing":"",` +
+		`"Hash":[1,2,3,4],"Parts":["YQ==","YmM="]}`
+
+	out, err := Convert(&in).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", out, expected)
+	}
+
+	var written []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if err := NewJsonEncoder(w).Encode(&in); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if string(written) != expected+"\n" {
+		t.Errorf("stream encoder wrote %s, expected %s", written, expected)
+	}
+
+	var decoded Upload
+	if err := Convert(expected).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if string(decoded.Avatar) != "hello, world" || string(decoded.Signature) != "\x00\xff\x10" ||
+		decoded.Empty == nil || len(decoded.Empty) != 0 || decoded.Hash != in.Hash ||
+		len(decoded.Parts) != 2 || string(decoded.Parts[1]) != "bc" {
+		t.Errorf("JsonDecode = %+v", decoded)
+	}
+
+	// Arrays of numbers still decode, and bad base64 fails
+	var raw []byte
+	if err := Convert(`[104,105]`).JsonDecode(&raw); err != nil || string(raw) != "hi" {
+		t.Errorf("JsonDecode([104,105]) = %q, %v", raw, err)
+	}
+	if err := Convert(`"not base64!"`).JsonDecode(&raw); err == nil {
+		t.Error("expected an error for invalid base64")
+	}
+}
+
+func TestJsonByteSliceAsArray(t *testing.T) {
+	clearRefStructsCache()
+
+	type Packet struct {
+		Data []byte
+	}
+
+	SetByteSliceEncoding(BytesAsArray)
+	defer SetByteSliceEncoding(BytesAsBase64)
+
+	out, err := Convert(&Packet{Data: []byte{1, 2, 255}}).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	if expected := `{"Data":[1,2,255]}`; string(out) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", out, expected)
+	}
+
+	// Decoding accepts base64 whatever the encoding setting
+	var p Packet
+	if err := Convert(`{"Data":"AQL/"}`).JsonDecode(&p); err != nil || string(p.Data) != "\x01\x02\xff" {
+		t.Errorf("JsonDecode = %v, %v", p.Data, err)
+	}
+}
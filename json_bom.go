@@ -0,0 +1,75 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Byte order marks
+// JSON text is UTF-8 (RFC 8259). Files saved by Windows editors often start
+// with the UTF-8 byte order mark, which every decode entry point skips. Text
+// in UTF-16 or UTF-32 fails up front with ErrUnsupportedEncoding naming the
+// encoding, whether it carries a byte order mark or not, rather than with a
+// syntax error at its first byte:
+//
+//	err := Convert(utf16Payload).JsonDecode(&v) // unsupported text encoding UTF-16LE ...
+
+// utf8BOM is the UTF-8 encoding of U+FEFF
+const utf8BOM = "\xEF\xBB\xBF"
+
+// foreignBOMs are the byte order marks of the encodings JSON text may not
+// use, longest first since UTF-32LE starts like UTF-16LE
+var foreignBOMs = [...]struct{ mark, name string }{
+	{"\x00\x00\xFE\xFF", "UTF-32BE"},
+	{"\xFF\xFE\x00\x00", "UTF-32LE"},
+	{"\xFE\xFF", "UTF-16BE"},
+	{"\xFF\xFE", "UTF-16LE"},
+}
+
+// stripJsonBOM returns s without a leading UTF-8 byte order mark, or
+// ErrUnsupportedEncoding when s is UTF-16 or UTF-32 text
+func stripJsonBOM(s string) (string, error) {
+	if len(s) >= len(utf8BOM) && s[:len(utf8BOM)] == utf8BOM {
+		return s[len(utf8BOM):], nil
+	}
+	for _, bom := range foreignBOMs {
+		if len(s) >= len(bom.mark) && s[:len(bom.mark)] == bom.mark {
+			return s, Err(ErrUnsupportedEncoding, bom.name, "byte order mark, JSON must be UTF-8")
+		}
+	}
+	// Neither byte of a UTF-8 JSON text can be NUL, while ASCII in UTF-16 and
+	// UTF-32 puts one next to every character
+	if len(s) >= 2 && (s[0] == 0 || s[1] == 0) {
+		return s, Err(ErrUnsupportedEncoding, "UTF-16 or UTF-32 text, JSON must be UTF-8")
+	}
+	return s, nil
+}
+
+// stripJsonBOMBytes is stripJsonBOM for byte input
+func stripJsonBOMBytes(data []byte) ([]byte, error) {
+	rest, err := stripJsonBOM(string(data[:min(len(data), 4)]))
+	if err != nil {
+		return data, err
+	}
+	return data[min(len(data), 4)-len(rest):], nil
+}
+
+// skipBOM drops a UTF-8 byte order mark at the start of the stream and
+// rejects UTF-16 and UTF-32 streams; the check runs before the first value
+func (d *JsonDecoder) skipBOM() error {
+	if d.bomChecked {
+		return nil
+	}
+	for len(d.buf) < len(foreignBOMs[0].mark) {
+		if !d.refill() {
+			break
+		}
+	}
+	head := string(d.buf[:min(len(d.buf), len(foreignBOMs[0].mark))])
+	rest, err := stripJsonBOM(head)
+	if err != nil {
+		return err
+	}
+	d.consume(len(head) - len(rest))
+	d.bomChecked = true
+	return nil
+}
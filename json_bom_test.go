@@ -0,0 +1,72 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestDecodeSkipsUTF8BOM(t *testing.T) {
+	input := "\xEF\xBB\xBF \r\n{\"A\":1}\t"
+	var v struct{ A int }
+	if err := Convert(input).JsonDecode(&v); err != nil || v.A != 1 {
+		t.Errorf("JsonDecode with BOM = %+v, %v", v, err)
+	}
+	if !Convert(input).JsonValid() {
+		t.Error("JsonValid should accept a UTF-8 BOM")
+	}
+	doc, err := ParseDocument([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseDocument with BOM returned error: %v", err)
+	}
+	if raw, err := doc.Raw("A"); err != nil || raw != "1" {
+		t.Errorf("Document.Raw = %q, %v", raw, err)
+	}
+	if n, err := SkipValue([]byte(input)); err != nil || n != len(input)-1 {
+		t.Errorf("SkipValue = %d, %v; expected %d", n, err, len(input)-1)
+	}
+	if out, err := JsonMinify([]byte(input)); err != nil || string(out) != `{"A":1}` {
+		t.Errorf("JsonMinify = %q, %v", out, err)
+	}
+
+	// Only at the start of the input
+	if err := Convert("[\xEF\xBB\xBF1]").JsonDecode(new(any)); err == nil {
+		t.Error("a BOM inside the value should fail")
+	}
+}
+
+func TestDecodeRejectsForeignEncodings(t *testing.T) {
+	cases := map[string]string{
+		"\xFF\xFE{\x00}\x00":            "UTF-16LE",
+		"\xFE\xFF\x00{\x00}":            "UTF-16BE",
+		"\xFF\xFE\x00\x00{\x00\x00\x00": "UTF-32LE",
+		"\x00\x00\xFE\xFF\x00\x00\x00{": "UTF-32BE",
+		"{\x00}\x00":                    "UTF-16 or UTF-32",
+	}
+	for input, name := range cases {
+		err := Convert(input).JsonDecode(new(any))
+		if err == nil || !Contains(err.Error(), string(ErrUnsupportedEncoding)) || !Contains(err.Error(), name) {
+			t.Errorf("JsonDecode(%q) = %v, expected ErrUnsupportedEncoding for %s", input, err, name)
+		}
+	}
+}
+
+func TestJsonDecoderBOM(t *testing.T) {
+	dec := NewJsonDecoder(&testReader{data: "\xEF\xBB\xBF1 2", chunk: 1, eof: errTestEOF})
+	var sum int
+	for dec.More() {
+		var n int
+		if err := dec.Decode(&n); err != nil {
+			t.Fatalf("Decode returned error: %v", err)
+		}
+		sum += n
+	}
+	if sum != 3 {
+		t.Errorf("sum = %d, expected 3", sum)
+	}
+
+	dec = NewJsonDecoder(&testReader{data: "\xFE\xFF\x00[\x00]", chunk: 3, eof: errTestEOF})
+	if err := dec.Decode(new(any)); err == nil || !Contains(err.Error(), "UTF-16BE") {
+		t.Errorf("Decode(UTF-16BE stream) = %v, expected ErrUnsupportedEncoding", err)
+	}
+}
@@ -0,0 +1,92 @@
+package tinywodp
+
+import (
+	"sync"
+)
+
+// Type caches
+// Everything learned about a type is cached under its *refType, which is
+// unique per type for the life of the program: besides the struct metadata,
+// decode plans, encoded field names, custom codec bits and required-field
+// reachability. These are sync.Maps filled on first use with Load and
+// LoadOrStore (or Store of a value that cannot differ), so any number of
+// goroutines populate them at once without a lock; a lost race only computes
+// the same value twice.
+//
+// PreloadTypes warms the caches at startup so the first request does not pay
+// for it, and InvalidateTypeCache drops them, for tests or after registering
+// setters and codecs that change how known types are compiled:
+//
+//	if err := tinywodp.PreloadTypes(User{}, &Order{}); err != nil {
+//		log.Fatal(err)
+//	}
+
+// PreloadTypes builds the cached metadata of the types of samples and of every
+// type reachable from them through fields, pointers, slices, arrays and maps
+// Returns the first error, such as ErrNoReflection for stripped struct metadata
+// Samples with a generated codec are skipped: their fields are never walked
+func PreloadTypes(samples ...any) error {
+	seen := map[*refType]bool{}
+	for _, sample := range samples {
+		if sample == nil || isCodecSample(sample) {
+			continue
+		}
+		if err := preloadType(refValueOf(sample).Type(), seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preloadType fills the caches for t and the types it contains
+func preloadType(t *refType, seen map[*refType]bool) error {
+	if t == nil || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case tpPointer, tpSlice, tpArray:
+		return preloadType(t.Elem(), seen)
+	case tpMap:
+		if err := preloadType(t.mapKey(), seen); err != nil {
+			return err
+		}
+		return preloadType(t.mapElem(), seen)
+	case tpStruct:
+	default:
+		return nil
+	}
+
+	zero, err := refNewValue(t)
+	if err != nil {
+		return err
+	}
+	if hasGeneratedCodec(zero) || isTimeType(zero) {
+		return nil
+	}
+	plan, err := decodePlanFor(zero)
+	if err != nil {
+		return err
+	}
+	jsonFieldKeys(zero, plan, fieldNaming)
+	for i := range zero.refNumField() {
+		if err := preloadType(zero.refField(i).Type(), seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateTypeCache drops every cached type: the next encode or decode of
+// each type rebuilds its metadata
+// Safe to call concurrently with encoding and decoding
+func InvalidateTypeCache() {
+	clearRefStructsCache()
+	for _, cache := range []*sync.Map{&decodePlans, &encodedFieldNames, &sortedFieldOrders, &customCodecs, &requiredChecks} {
+		cache.Range(func(key, _ any) bool {
+			cache.Delete(key)
+			return true
+		})
+	}
+}
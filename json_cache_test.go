@@ -0,0 +1,72 @@
+package tinywodp
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type cacheLine struct {
+	SKU string
+	Qty int
+}
+
+type cacheOrder struct {
+	ID    string
+	Lines []cacheLine
+	Notes map[string]*cacheLine
+}
+
+func cachedPlan(sample any) bool {
+	_, ok := decodePlans.Load(refValueOf(sample).Type())
+	return ok
+}
+
+func TestPreloadTypes(t *testing.T) {
+	InvalidateTypeCache()
+	if cachedPlan(cacheOrder{}) || cachedPlan(cacheLine{}) {
+		t.Fatal("plans cached after InvalidateTypeCache")
+	}
+
+	if err := PreloadTypes(&cacheOrder{}, nil); err != nil {
+		t.Fatalf("PreloadTypes failed: %v", err)
+	}
+	// Reached through the pointer, the slice and the map
+	if !cachedPlan(cacheOrder{}) || !cachedPlan(cacheLine{}) {
+		t.Error("PreloadTypes left nested types uncached")
+	}
+
+	InvalidateTypeCache()
+	if cachedPlan(cacheOrder{}) {
+		t.Error("InvalidateTypeCache kept the plan")
+	}
+}
+
+func TestTypeCacheConcurrentPopulation(t *testing.T) {
+	InvalidateTypeCache()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var order cacheOrder
+			if err := Convert(`{"ID":"a","Lines":[{"SKU":"x","Qty":2}]}`).JsonDecode(&order); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := Convert(&order).JsonEncode(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	// Invalidation may race with population
+	InvalidateTypeCache()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,200 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Canonical JSON (RFC 8785, JSON Canonicalization Scheme)
+// One byte sequence per value, so the output can be hashed or signed and the
+// receiver can recompute it from the decoded data:
+//
+//	payload, err := Convert(&order).JsonEncodeCanonical()
+//	signature := sign(key, payload)
+//
+// The value is encoded as by JsonEncode, with the current field naming and
+// other settings, then rewritten:
+//   - object members sorted by their keys' UTF-16 code units
+//   - no whitespace
+//   - strings escaped minimally: only '"', '\' and control characters, the
+//     latter as \b \t \n \f \r or \u00xx; everything else is raw UTF-8
+//   - numbers as IEEE 754 doubles in ECMAScript form: shortest round-trip
+//     digits, exponent below 1e-6 and from 1e21 on, -0 as 0
+//
+// Integers beyond ±2^53 cannot be represented exactly and fail instead of
+// being rounded; send them as strings (json:",string"). Duplicate keys fail too.
+
+// JsonEncodeCanonical converts the current value to canonical JSON
+//
+// Usage patterns:
+//
+//	data, err := Convert(&claims).JsonEncodeCanonical()
+//	_, err := Convert(&claims).JsonEncodeCanonical(hasher) // hash without keeping a copy
+//
+// Like JsonEncode, it returns a copy of the output, or writes it to the
+// optional writer and returns nil bytes
+func (c *refValue) JsonEncodeCanonical(w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.jASCII = false // strings are escaped again anyway
+	jh.jHTML = false
+	jh.jSortKeys = false // keys are sorted by UTF-16 units below
+	return jh.encodeTo(c, w, func(dst []byte, v *refValue) ([]byte, error) {
+		raw, err := jh.appendJson(nil, v)
+		if err != nil {
+			return dst, err
+		}
+		dst, _, err = jh.appendCanonicalJson(dst, string(raw), 0)
+		return dst, err
+	})
+}
+
+// canonicalMember is an object member waiting to be written in key order
+type canonicalMember struct {
+	key   string // unescaped key
+	value int    // offset of the value in the source
+}
+
+// appendCanonicalJson appends the canonical form of the JSON value at or after
+// s[i] and returns the index just past it
+func (jh *jsonH) appendCanonicalJson(dst []byte, s string, i int) ([]byte, int, error) {
+	i = skipJsonSpace(s, i)
+	if i >= len(s) {
+		return dst, i, Err(errInvalidJSON, "unexpected end of input")
+	}
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return dst, end, err
+	}
+
+	switch s[i] {
+	case '{':
+		dst, err = jh.appendCanonicalObject(dst, s, i)
+	case '[':
+		dst, err = jh.appendCanonicalArray(dst, s, i)
+	case '"':
+		var str string
+		if str, err = jh.unescapeJsonString(s[i+1 : end-1]); err == nil {
+			dst = escapeAndQuoteJsonString(dst, str, false)
+		}
+	case 't', 'f', 'n':
+		dst = append(dst, s[i:end]...)
+	default:
+		dst, err = appendCanonicalNumber(dst, s[i:end])
+	}
+	return dst, end, err
+}
+
+// appendCanonicalObject appends the object at s[i] with its members sorted
+func (jh *jsonH) appendCanonicalObject(dst []byte, s string, i int) ([]byte, error) {
+	var members []canonicalMember
+	i = skipJsonSpace(s, i+1)
+	for done := s[i] == '}'; !done; {
+		i = skipJsonSpace(s, i)
+		end, err := jh.skipJsonValueAt(s, i)
+		if err != nil {
+			return dst, err
+		}
+		if s[i] != '"' {
+			return dst, Err(errInvalidJSON, "object key must be a string")
+		}
+		key, err := jh.unescapeJsonString(s[i+1 : end-1])
+		if err != nil {
+			return dst, err
+		}
+		if i = skipJsonSpace(s, end); i >= len(s) || s[i] != ':' {
+			return dst, Err(errInvalidJSON, "expected ':' after object key")
+		}
+		value := skipJsonSpace(s, i+1)
+		if i, err = jh.skipJsonValueAt(s, value); err != nil {
+			return dst, err
+		}
+		members = append(members, canonicalMember{key: key, value: value})
+		if i, done, err = jh.nextJsonMember(s, i, '}'); err != nil {
+			return dst, err
+		}
+	}
+
+	// Insertion sort keeps the binary free of package sort
+	for j := 1; j < len(members); j++ {
+		for k := j; k > 0 && utf16Less(members[k].key, members[k-1].key); k-- {
+			members[k], members[k-1] = members[k-1], members[k]
+		}
+	}
+
+	dst = append(dst, '{')
+	for j, m := range members {
+		if j > 0 {
+			if m.key == members[j-1].key {
+				return dst, Err(errUnsupportedValue, "duplicate object key in canonical JSON: "+m.key)
+			}
+			dst = append(dst, ',')
+		}
+		dst = escapeAndQuoteJsonString(dst, m.key, false)
+		dst = append(dst, ':')
+		var err error
+		if dst, _, err = jh.appendCanonicalJson(dst, s, m.value); err != nil {
+			return dst, err
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// appendCanonicalArray appends the array at s[i] element by element
+func (jh *jsonH) appendCanonicalArray(dst []byte, s string, i int) ([]byte, error) {
+	dst = append(dst, '[')
+	i = skipJsonSpace(s, i+1)
+	for n, done := 0, s[i] == ']'; !done; n++ {
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, i, err = jh.appendCanonicalJson(dst, s, i); err != nil {
+			return dst, err
+		}
+		if i, done, err = jh.nextJsonMember(s, i, ']'); err != nil {
+			return dst, err
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendCanonicalNumber appends a number literal in its ECMAScript form
+func appendCanonicalNumber(dst []byte, literal string) ([]byte, error) {
+	if !isJsonNumber(literal) {
+		return dst, Err(errInvalidJSON, "unexpected value: "+literal)
+	}
+	if isJsonIntegerLiteral(literal) && exceedsFloatPrecision(literal) {
+		return dst, Err(errUnsupportedValue, "integer exceeds float64 precision in canonical JSON: "+literal)
+	}
+	f, ok := parseJsonFloat(literal, 64)
+	if !ok {
+		return dst, Err(errUnsupportedValue, "number out of range for float64: "+literal)
+	}
+	if f == 0 {
+		return append(dst, '0'), nil // -0 as well
+	}
+	return appendJsonFloat(dst, f, 64), nil
+}
+
+// utf16Less reports whether a sorts before b when both are compared as UTF-16
+// code units, the key order of RFC 8785
+func utf16Less(a, b string) bool {
+	for a != "" && b != "" {
+		ra, na := runeAt(a, 0)
+		rb, nb := runeAt(b, 0)
+		if ra != rb {
+			return utf16Order(ra) < utf16Order(rb)
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+// utf16Order maps r to a value ordered like its UTF-16 encoding: runes above
+// U+FFFF start with a surrogate, so they sort before U+E000..U+FFFF
+func utf16Order(r rune) rune {
+	if r >= 0xE000 && r <= 0xFFFF {
+		return r + maxRune + 1
+	}
+	return r
+}
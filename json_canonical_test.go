@@ -0,0 +1,107 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonEncodeCanonical(t *testing.T) {
+	clearRefStructsCache()
+
+	type Line struct {
+		Zeta  float64 `json:"zeta"`
+		Alpha string  `json:"alpha"`
+	}
+	type Meta struct {
+		B float64 `json:"b"`
+		A []any   `json:"a"`
+	}
+	type Tags struct {
+		Euro string `json:"€"`
+		One  string `json:"1"`
+	}
+	type Order struct {
+		ID    string `json:"id"`
+		Lines []Line `json:"lines"`
+		Meta  Meta   `json:"meta"`
+		Tags  Tags   `json:"tags"`
+		Big   int64  `json:"big,string"`
+	}
+
+	order := Order{
+		ID:    "o-1 ",
+		Lines: []Line{{Zeta: -0.0, Alpha: "é<>&"}, {Zeta: 1e21, Alpha: "\x01"}},
+		Meta:  Meta{B: 1.5e-7, A: []any{true, nil}},
+		Tags:  Tags{Euro: "x", One: "y"},
+		Big:   1 << 62,
+	}
+	out, err := Convert(&order).JsonEncodeCanonical()
+	if err != nil {
+		t.Fatalf("JsonEncodeCanonical returned error: %v", err)
+	}
+	expected := `{"big":"4611686018427387904","id":"o-1 ",` +
+		`"lines":[{"alpha":"é<>&","zeta":0},{"alpha":"\u0001","zeta":1e+21}],` +
+		`"meta":{"a":[true,null],"b":1.5e-7},"tags":{"1":"y","€":"x"}}`
+	if string(out) != expected {
+		t.Errorf("JsonEncodeCanonical =\n%s\nexpected\n%s", out, expected)
+	}
+
+	// Escaping settings do not change canonical output
+	SetEscapeNonASCII(true)
+	again, err := Convert(&order).JsonEncodeCanonical()
+	SetEscapeNonASCII(false)
+	if err != nil || string(again) != expected {
+		t.Errorf("JsonEncodeCanonical with SetEscapeNonASCII = %s, %v", again, err)
+	}
+
+	type Counter struct {
+		N int64
+	}
+	if _, err := Convert(&Counter{N: 1<<53 + 1}).JsonEncodeCanonical(); err == nil || !Contains(err.Error(), "precision") {
+		t.Errorf("JsonEncodeCanonical(2^53+1) error = %v", err)
+	}
+	if out, err := Convert(&Counter{N: 1 << 53}).JsonEncodeCanonical(); err != nil || string(out) != `{"N":9007199254740992}` {
+		t.Errorf("JsonEncodeCanonical(2^53) = %s, %v", out, err)
+	}
+}
+
+func TestAppendCanonicalJson(t *testing.T) {
+	jh := getJsonH("")
+	defer putJsonH(jh)
+
+	tests := []struct {
+		name, input, expected string
+	}{
+		// RFC 8785 section 3.2.2
+		{
+			"rfc example",
+			`{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			  "literals": [null, true, false]}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		// RFC 8785 section 3.2.3, keys ordered by UTF-16 code units
+		{
+			"rfc sorting",
+			`{"€":"Euro Sign","\r":"Carriage Return","דּ":"Hebrew Letter Dalet With Dagesh",` +
+				`"1":"One","😀":"Emoji: Grinning Face","\u0080":"Control","ö":"Latin Small Letter O With Diaeresis"}`,
+			`{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","ö":"Latin Small Letter O With Diaeresis",` +
+				`"€":"Euro Sign","😀":"Emoji: Grinning Face","דּ":"Hebrew Letter Dalet With Dagesh"}`,
+		},
+		{"empty containers", ` { "b" : [ ] , "a" : { } } `, `{"a":{},"b":[]}`},
+		{"negative zero", `[-0, -0.0, 0e10]`, `[0,0,0]`},
+	}
+	for _, tt := range tests {
+		out, _, err := jh.appendCanonicalJson(nil, tt.input, 0)
+		if err != nil || string(out) != tt.expected {
+			t.Errorf("%s: appendCanonicalJson =\n%s, %v\nexpected\n%s", tt.name, out, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{`{"a":1,"a":2}`, `[1e400]`, `[12345678901234567890]`, `{"a":}`} {
+		if _, _, err := jh.appendCanonicalJson(nil, input, 0); err == nil {
+			t.Errorf("appendCanonicalJson(%s) should fail", input)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Channel pipelines
+// Decodes the elements of a top-level JSON array one by one and sends each into a
+// channel, so consumers can fan out work across goroutines while decoding proceeds
+
+// ChanOptions configures DecodeArrayToChan
+type ChanOptions struct {
+	// Done stops decoding early when closed; DecodeArrayToChan then returns nil
+	Done <-chan struct{}
+	// CloseChan closes the destination channel once decoding finishes (or fails)
+	CloseChan bool
+}
+
+// DecodeArrayToChan decodes each element of the JSON array in data into a T and
+// sends it on ch. Sending blocks until a receiver is ready, which provides
+// back-pressure: decoding never runs ahead of the consumers by more than the
+// channel's buffer. Each element is sent as soon as it is decoded, so a
+// malformed element is reported after the ones before it were received.
+//
+// Usage pattern:
+//
+//	ch := make(chan User, 16)
+//	go func() { err = DecodeArrayToChan(data, ch, ChanOptions{CloseChan: true}) }()
+//	for user := range ch { ... }
+func DecodeArrayToChan[T any](data []byte, ch chan<- T, opts ...ChanOptions) error {
+	var opt ChanOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.CloseChan {
+		defer close(ch)
+	}
+
+	jsonStr, err := stripJsonBOM(string(data))
+	if err != nil {
+		return err
+	}
+	if jsonStr = trimJsonSpace(jsonStr); jsonStr == "" {
+		return Err(ErrEmptyInput)
+	}
+	if len(jsonStr) < 2 || jsonStr[0] != '[' || jsonStr[len(jsonStr)-1] != ']' {
+		return Err(errInvalidJSON, "expected array but got: "+jsonStr)
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.jInput = jsonStr
+
+	i, done, err := jh.openJsonContainer(jsonStr, 0, ']')
+	for n := 0; !done; n++ {
+		if err != nil {
+			return jh.positionError(err)
+		}
+		start := skipJsonSpace(jsonStr, i)
+		if i, err = jh.skipJsonValueAt(jsonStr, start); err != nil {
+			return jh.positionError(err)
+		}
+
+		var v T
+		if err := jh.decode(jsonStr[start:i], &v); err != nil {
+			return Err(errInvalidJSON, "failed to parse element "+Convert(n).String()+": "+err.Error())
+		}
+
+		select {
+		case ch <- v:
+		case <-opt.Done:
+			return nil
+		}
+		i, done, err = jh.nextJsonMember(jsonStr, i, ']')
+	}
+	if i < len(jsonStr) {
+		return jh.positionError(jh.errorAt(jsonStr, i, Err(errInvalidJSON, ErrTrailingData)))
+	}
+	return nil
+}
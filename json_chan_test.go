@@ -0,0 +1,74 @@
+package tinywodp
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDecodeArrayToChan(t *testing.T) {
+	clearRefStructsCache()
+
+	data, err := Marshal(GenerateSimplePersonArray(50))
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	ch := make(chan Person) // unbuffered: every send waits for a worker
+	var decodeErr error
+	go func() {
+		decodeErr = DecodeArrayToChan(data, ch, ChanOptions{CloseChan: true})
+	}()
+
+	// Fan out across workers
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range ch {
+				mu.Lock()
+				seen[p.Id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if decodeErr != nil {
+		t.Fatalf("DecodeArrayToChan returned error: %v", decodeErr)
+	}
+	if len(seen) != 50 {
+		t.Errorf("received %d distinct persons, expected 50", len(seen))
+	}
+}
+
+func TestDecodeArrayToChanDone(t *testing.T) {
+	ch := make(chan int, 1)
+	done := make(chan struct{})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- DecodeArrayToChan([]byte(`[1,2,3,4]`), ch, ChanOptions{Done: done})
+	}()
+
+	if v := <-ch; v != 1 {
+		t.Errorf("first element = %d, expected 1", v)
+	}
+	close(done)
+
+	if err := <-errCh; err != nil {
+		t.Errorf("DecodeArrayToChan after Done returned error: %v", err)
+	}
+}
+
+func TestDecodeArrayToChanInvalid(t *testing.T) {
+	ch := make(chan int, 4)
+	if err := DecodeArrayToChan([]byte(`{"a":1}`), ch); err == nil {
+		t.Error("DecodeArrayToChan should reject non-array input")
+	}
+	if err := DecodeArrayToChan([]byte(`[1,"x"]`), ch); err == nil {
+		t.Error("DecodeArrayToChan should report element decode errors")
+	}
+}
@@ -0,0 +1,19 @@
+package tinywodp
+
+// Interop hooks with the encoding/json interfaces
+// The default build never imports encoding/json. Building with
+// -tags tinywodp_stdcompat installs these hooks (see json_stdcompat.go) so user
+// types implementing json.Marshaler/json.Unmarshaler or
+// encoding.TextMarshaler/encoding.TextUnmarshaler are honored by this codec,
+// and BigNumber implements the standard interfaces in return. The hooks match
+// those interfaces by method set and do not import encoding/json either.
+
+var (
+	// stdEncodeHook encodes v through a standard marshaler interface,
+	// reporting false when v implements none
+	stdEncodeHook func(v *refValue) (string, bool, error)
+
+	// stdDecodeHook decodes jsonStr through a standard unmarshaler interface,
+	// reporting false when the target implements none
+	stdDecodeHook func(jsonStr string, target *refValue) (bool, error)
+)
@@ -1,998 +1,1302 @@
-package tinywodp
-
-import (
-	"testing"
-
-	. "github.com/cdvelop/tinystring"
-)
-
-// Test complete ComplexUser structure decoding (encode-decode cycle)
-func TestJsonDecodeComplexUser(t *testing.T) {
-	// Note: This test verifies that complex JSON encoding/decoding doesn't crash
-	// Full validation is disabled due to memory complexity issues with the validation functions
-
-	// Generate test data and encode it
-	testUsers := GenerateComplexTestData(1)
-	originalUser := testUsers[0]
-
-	// Encode to JSON
-	jsonBytes, err := Convert(originalUser).JsonEncode()
-	if err != nil {
-		t.Fatalf("JsonEncode(ComplexUser) failed: %v", err)
-	}
-	jsonStr := string(jsonBytes)
-	t.Logf("Generated JSON length: %d bytes", len(jsonStr))
-
-	// DEBUG: Show Permissions section of JSON
-	if pos := findInString(jsonStr, "\"Permissions\""); pos >= 0 {
-		start := pos
-		end := start + 200
-		if end > len(jsonStr) {
-			end = len(jsonStr)
-		}
-		t.Logf("Permissions JSON section: %s", jsonStr[start:end])
-	}
-
-	// Test decoding doesn't crash
-	var decodedUser ComplexUser
-	err = Convert(jsonStr).JsonDecode(&decodedUser)
-	if err != nil {
-		t.Fatalf("JsonDecode(ComplexUser) returned error: %v", err)
-	}
-	// Basic validation - just check that main fields are populated
-	// (Detailed validation disabled due to memory explosion in assertEqual)
-	if decodedUser.ID == "" {
-		t.Errorf("ID should not be empty after decode")
-	}
-	if decodedUser.Username == "" {
-		t.Errorf("Username should not be empty after decode")
-	}
-	if decodedUser.Email == "" {
-		t.Errorf("Email should not be empty after decode")
-	}
-
-	// Validate nested structures are not corrupted
-	validateNestedStructures(t, originalUser, decodedUser)
-
-	t.Logf("ComplexUser JSON encode/decode completed successfully")
-	t.Logf("Basic validation: ID=%s, Username=%s, Email=%s",
-		safeFormat(decodedUser.ID),
-		safeFormat(decodedUser.Username),
-		safeFormat(decodedUser.Email))
-}
-
-// validateNestedStructures checks that nested structures are properly decoded
-func validateNestedStructures(t *testing.T, expected, actual ComplexUser) {
-	// Test Profile nested struct
-	t.Logf("Profile validation:")
-	t.Logf("  Expected FirstName: %s", safeFormat(expected.Profile.FirstName))
-	t.Logf("  Actual FirstName: %s", safeFormat(actual.Profile.FirstName))
-
-	if expected.Profile.FirstName != actual.Profile.FirstName {
-		t.Errorf("Profile.FirstName mismatch: expected %s, got %s",
-			safeFormat(expected.Profile.FirstName),
-			safeFormat(actual.Profile.FirstName))
-	}
-
-	if expected.Profile.LastName != actual.Profile.LastName {
-		t.Errorf("Profile.LastName mismatch: expected %s, got %s",
-			safeFormat(expected.Profile.LastName),
-			safeFormat(actual.Profile.LastName))
-	}
-
-	// Test PhoneNumbers slice
-	t.Logf("PhoneNumbers validation:")
-	t.Logf("  Expected count: %d", len(expected.Profile.PhoneNumbers))
-	t.Logf("  Actual count: %d", len(actual.Profile.PhoneNumbers))
-
-	if len(expected.Profile.PhoneNumbers) != len(actual.Profile.PhoneNumbers) {
-		t.Errorf("PhoneNumbers count mismatch: expected %d, got %d",
-			len(expected.Profile.PhoneNumbers),
-			len(actual.Profile.PhoneNumbers))
-		return
-	}
-
-	// Check first phone number if exists
-	if len(expected.Profile.PhoneNumbers) > 0 && len(actual.Profile.PhoneNumbers) > 0 {
-		expectedPhone := expected.Profile.PhoneNumbers[0]
-		actualPhone := actual.Profile.PhoneNumbers[0]
-
-		t.Logf("  First Phone ID - Expected: %s, Actual: %s",
-			safeFormat(expectedPhone.ID),
-			safeFormat(actualPhone.ID))
-		t.Logf("  First Phone Type - Expected: %s, Actual: %s",
-			safeFormat(expectedPhone.Type),
-			safeFormat(actualPhone.Type))
-		t.Logf("  First Phone Number - Expected: %s, Actual: %s",
-			safeFormat(expectedPhone.Number),
-			safeFormat(actualPhone.Number))
-
-		if expectedPhone.ID != actualPhone.ID {
-			t.Errorf("PhoneNumbers[0].ID corruption detected: expected %s, got %s",
-				safeFormat(expectedPhone.ID),
-				safeFormat(actualPhone.ID))
-		}
-
-		if expectedPhone.Type != actualPhone.Type {
-			t.Errorf("PhoneNumbers[0].Type corruption detected: expected %s, got %s",
-				safeFormat(expectedPhone.Type),
-				safeFormat(actualPhone.Type))
-		}
-
-		if expectedPhone.Number != actualPhone.Number {
-			t.Errorf("PhoneNumbers[0].Number corruption detected: expected %s, got %s",
-				safeFormat(expectedPhone.Number),
-				safeFormat(actualPhone.Number))
-		}
-	}
-
-	// Test Addresses slice
-	t.Logf("Addresses validation:")
-	t.Logf("  Expected count: %d", len(expected.Profile.Addresses))
-	t.Logf("  Actual count: %d", len(actual.Profile.Addresses))
-
-	if len(expected.Profile.Addresses) != len(actual.Profile.Addresses) {
-		t.Errorf("Addresses count mismatch: expected %d, got %d",
-			len(expected.Profile.Addresses),
-			len(actual.Profile.Addresses))
-		return
-	}
-
-	// Check first address if exists
-	if len(expected.Profile.Addresses) > 0 && len(actual.Profile.Addresses) > 0 {
-		expectedAddr := expected.Profile.Addresses[0]
-		actualAddr := actual.Profile.Addresses[0]
-
-		t.Logf("  First Address ID - Expected: %s, Actual: %s",
-			safeFormat(expectedAddr.ID),
-			safeFormat(actualAddr.ID))
-		t.Logf("  First Address Street - Expected: %s, Actual: %s",
-			safeFormat(expectedAddr.Street),
-			safeFormat(actualAddr.Street))
-		t.Logf("  First Address City - Expected: %s, Actual: %s",
-			safeFormat(expectedAddr.City),
-			safeFormat(actualAddr.City))
-
-		if expectedAddr.ID != actualAddr.ID {
-			t.Errorf("Addresses[0].ID corruption detected: expected %s, got %s",
-				safeFormat(expectedAddr.ID),
-				safeFormat(actualAddr.ID))
-		}
-
-		if expectedAddr.Street != actualAddr.Street {
-			t.Errorf("Addresses[0].Street corruption detected: expected %s, got %s",
-				safeFormat(expectedAddr.Street),
-				safeFormat(actualAddr.Street))
-		}
-
-		if expectedAddr.City != actualAddr.City {
-			t.Errorf("Addresses[0].City corruption detected: expected %s, got %s",
-				safeFormat(expectedAddr.City),
-				safeFormat(actualAddr.City))
-		}
-
-		// Test nested Coordinates
-		t.Logf("  First Address Coordinates - Expected: Lat=%f, Lng=%f",
-			expectedAddr.Coordinates.Latitude,
-			expectedAddr.Coordinates.Longitude)
-		t.Logf("  First Address Coordinates - Actual: Lat=%f, Lng=%f",
-			actualAddr.Coordinates.Latitude,
-			actualAddr.Coordinates.Longitude)
-
-		if expectedAddr.Coordinates.Latitude != actualAddr.Coordinates.Latitude {
-			t.Errorf("Addresses[0].Coordinates.Latitude corruption detected: expected %f, got %f",
-				expectedAddr.Coordinates.Latitude,
-				actualAddr.Coordinates.Latitude)
-		}
-	}
-	// Test Permissions slice (simple strings) - has known encoding issue
-	if len(expected.Permissions) != len(actual.Permissions) {
-		t.Logf("WARNING: Permissions count mismatch (known issue with string array encoding): expected %d, got %d",
-			len(expected.Permissions),
-			len(actual.Permissions))
-	} else {
-		t.Logf("Permissions count matches: %d", len(actual.Permissions))
-		// Note: String array values are currently encoding as empty strings - known issue
-		// The decode structure works correctly but encoding needs investigation
-	}
-}
-
-func validateComplexUserDecoding(t *testing.T, expected, actual ComplexUser) {
-	// Top-level fields
-	assertEqual(t, expected.ID, actual.ID, "ID")
-	assertEqual(t, expected.Username, actual.Username, "Username")
-	assertEqual(t, expected.Email, actual.Email, "Email")
-	assertEqual(t, expected.CreatedAt, actual.CreatedAt, "CreatedAt")
-	assertEqual(t, expected.LastLogin, actual.LastLogin, "LastLogin")
-	assertEqual(t, expected.IsActive, actual.IsActive, "IsActive")
-
-	// Profile validation
-	validateComplexProfile(t, expected.Profile, actual.Profile)
-
-	// Permissions slice
-	assertSliceEqual(t, expected.Permissions, actual.Permissions, "Permissions")
-
-	// Metadata
-	validateMetadata(t, expected.Metadata, actual.Metadata)
-
-	// Stats
-	validateComplexStats(t, expected.Stats, actual.Stats)
-}
-
-func validateComplexProfile(t *testing.T, expected, actual ComplexProfile) {
-	assertEqual(t, expected.FirstName, actual.FirstName, "Profile.FirstName")
-	assertEqual(t, expected.LastName, actual.LastName, "Profile.LastName")
-	assertEqual(t, expected.DisplayName, actual.DisplayName, "Profile.DisplayName")
-	assertEqual(t, expected.Bio, actual.Bio, "Profile.Bio")
-	assertEqual(t, expected.AvatarURL, actual.AvatarURL, "Profile.AvatarURL")
-	assertEqual(t, expected.BirthDate, actual.BirthDate, "Profile.BirthDate")
-
-	// Phone numbers
-	if len(expected.PhoneNumbers) != len(actual.PhoneNumbers) {
-		t.Errorf("PhoneNumbers length mismatch: expected %d, got %d", len(expected.PhoneNumbers), len(actual.PhoneNumbers))
-		return
-	}
-
-	for i, expectedPhone := range expected.PhoneNumbers {
-		if i >= len(actual.PhoneNumbers) {
-			t.Errorf("Missing phone number at index %d", i)
-			continue
-		}
-		actualPhone := actual.PhoneNumbers[i]
-		assertEqual(t, expectedPhone.ID, actualPhone.ID, Fmt("PhoneNumbers[%d].ID", i).String())
-		assertEqual(t, expectedPhone.Type, actualPhone.Type, Fmt("PhoneNumbers[%d].Type", i).String())
-		assertEqual(t, expectedPhone.Number, actualPhone.Number, Fmt("PhoneNumbers[%d].Number", i).String())
-		assertEqual(t, expectedPhone.Extension, actualPhone.Extension, Fmt("PhoneNumbers[%d].Extension", i).String())
-		assertEqual(t, expectedPhone.IsPrimary, actualPhone.IsPrimary, Fmt("PhoneNumbers[%d].IsPrimary", i).String())
-		assertEqual(t, expectedPhone.IsVerified, actualPhone.IsVerified, Fmt("PhoneNumbers[%d].IsVerified", i).String())
-	}
-
-	// Addresses with coordinates
-	if len(expected.Addresses) != len(actual.Addresses) {
-		t.Errorf("Addresses length mismatch: expected %d, got %d", len(expected.Addresses), len(actual.Addresses))
-		return
-	}
-
-	for i, expectedAddr := range expected.Addresses {
-		if i >= len(actual.Addresses) {
-			t.Errorf("Missing address at index %d", i)
-			continue
-		}
-		actualAddr := actual.Addresses[i]
-		validateComplexAddress(t, expectedAddr, actualAddr, i)
-	}
-
-	// Social links
-	if len(expected.SocialLinks) != len(actual.SocialLinks) {
-		t.Errorf("SocialLinks length mismatch: expected %d, got %d", len(expected.SocialLinks), len(actual.SocialLinks))
-		return
-	}
-
-	for i, expectedLink := range expected.SocialLinks {
-		if i >= len(actual.SocialLinks) {
-			t.Errorf("Missing social link at index %d", i)
-			continue
-		}
-		actualLink := actual.SocialLinks[i]
-		assertEqual(t, expectedLink.Platform, actualLink.Platform, Fmt("SocialLinks[%d].Platform", i).String())
-		assertEqual(t, expectedLink.URL, actualLink.URL, Fmt("SocialLinks[%d].URL", i).String())
-		assertEqual(t, expectedLink.Username, actualLink.Username, Fmt("SocialLinks[%d].Username", i).String())
-		assertEqual(t, expectedLink.Verified, actualLink.Verified, Fmt("SocialLinks[%d].Verified", i).String())
-	}
-
-	// Preferences
-	validateComplexPreferences(t, expected.Preferences, actual.Preferences)
-
-	// Custom fields
-	validateCustomFields(t, expected.CustomFields, actual.CustomFields)
-}
-
-func validateComplexAddress(t *testing.T, expected, actual ComplexAddress, index int) {
-	prefix := Fmt("Addresses[%d]", index).String()
-	assertEqual(t, expected.ID, actual.ID, prefix+".ID")
-	assertEqual(t, expected.Type, actual.Type, prefix+".Type")
-	assertEqual(t, expected.Street, actual.Street, prefix+".Street")
-	assertEqual(t, expected.Street2, actual.Street2, prefix+".Street2")
-	assertEqual(t, expected.City, actual.City, prefix+".City")
-	assertEqual(t, expected.State, actual.State, prefix+".State")
-	assertEqual(t, expected.Country, actual.Country, prefix+".Country")
-	assertEqual(t, expected.PostalCode, actual.PostalCode, prefix+".PostalCode")
-	assertEqual(t, expected.IsPrimary, actual.IsPrimary, prefix+".IsPrimary")
-	assertEqual(t, expected.IsVerified, actual.IsVerified, prefix+".IsVerified")
-
-	// Coordinates pointer handling
-	if expected.Coordinates == nil && actual.Coordinates == nil {
-		return // Both nil, OK
-	}
-	if expected.Coordinates == nil && actual.Coordinates != nil {
-		t.Errorf("%s.Coordinates: expected nil, got non-nil", prefix)
-		return
-	}
-	if expected.Coordinates != nil && actual.Coordinates == nil {
-		t.Errorf("%s.Coordinates: expected non-nil, got nil", prefix)
-		return
-	}
-
-	// Both non-nil, compare values
-	assertEqual(t, expected.Coordinates.Latitude, actual.Coordinates.Latitude, prefix+".Coordinates.Latitude")
-	assertEqual(t, expected.Coordinates.Longitude, actual.Coordinates.Longitude, prefix+".Coordinates.Longitude")
-	assertEqual(t, expected.Coordinates.Accuracy, actual.Coordinates.Accuracy, prefix+".Coordinates.Accuracy")
-}
-
-func validateComplexPreferences(t *testing.T, expected, actual ComplexPreferences) {
-	assertEqual(t, expected.Language, actual.Language, "Preferences.Language")
-	assertEqual(t, expected.Timezone, actual.Timezone, "Preferences.Timezone")
-	assertEqual(t, expected.Theme, actual.Theme, "Preferences.Theme")
-	assertEqual(t, expected.Currency, actual.Currency, "Preferences.Currency")
-	assertEqual(t, expected.DateFormat, actual.DateFormat, "Preferences.DateFormat")
-	assertEqual(t, expected.TimeFormat, actual.TimeFormat, "Preferences.TimeFormat")
-
-	// Notifications
-	assertEqual(t, expected.Notifications.Email, actual.Notifications.Email, "Preferences.Notifications.Email")
-	assertEqual(t, expected.Notifications.SMS, actual.Notifications.SMS, "Preferences.Notifications.SMS")
-	assertEqual(t, expected.Notifications.Push, actual.Notifications.Push, "Preferences.Notifications.Push")
-	assertEqual(t, expected.Notifications.InApp, actual.Notifications.InApp, "Preferences.Notifications.InApp")
-	assertEqual(t, expected.Notifications.Marketing, actual.Notifications.Marketing, "Preferences.Notifications.Marketing")
-
-	// Privacy
-	assertEqual(t, expected.Privacy.ProfileVisibility, actual.Privacy.ProfileVisibility, "Preferences.Privacy.ProfileVisibility")
-	assertEqual(t, expected.Privacy.ShowEmail, actual.Privacy.ShowEmail, "Preferences.Privacy.ShowEmail")
-	assertEqual(t, expected.Privacy.ShowPhone, actual.Privacy.ShowPhone, "Preferences.Privacy.ShowPhone")
-	assertEqual(t, expected.Privacy.AllowMessaging, actual.Privacy.AllowMessaging, "Preferences.Privacy.AllowMessaging")
-	assertSliceEqual(t, expected.Privacy.BlockedUsers, actual.Privacy.BlockedUsers, "Preferences.Privacy.BlockedUsers")
-
-	// Features
-	assertEqual(t, expected.Features.BetaFeatures, actual.Features.BetaFeatures, "Preferences.Features.BetaFeatures")
-	assertEqual(t, expected.Features.Analytics, actual.Features.Analytics, "Preferences.Features.Analytics")
-	assertEqual(t, expected.Features.AdvancedSearch, actual.Features.AdvancedSearch, "Preferences.Features.AdvancedSearch")
-}
-
-func validateCustomFields(t *testing.T, expected, actual CustomFields) {
-	assertEqual(t, expected.EmployeeID, actual.EmployeeID, "CustomFields.EmployeeID")
-	assertEqual(t, expected.Department, actual.Department, "CustomFields.Department")
-	assertEqual(t, expected.Team, actual.Team, "CustomFields.Team")
-}
-
-func validateMetadata(t *testing.T, expected, actual Metadata) {
-	assertEqual(t, expected.Source, actual.Source, "Metadata.Source")
-	assertEqual(t, expected.Campaign, actual.Campaign, "Metadata.Campaign")
-	assertEqual(t, expected.Referrer, actual.Referrer, "Metadata.Referrer")
-	assertEqual(t, expected.Score, actual.Score, "Metadata.Score")
-	assertSliceEqual(t, expected.Experiments, actual.Experiments, "Metadata.Experiments")
-}
-
-func validateComplexStats(t *testing.T, expected, actual ComplexStats) {
-	assertEqual(t, expected.LoginCount, actual.LoginCount, "Stats.LoginCount")
-	assertEqual(t, expected.LastActivity, actual.LastActivity, "Stats.LastActivity")
-	assertEqual(t, expected.SessionDuration, actual.SessionDuration, "Stats.SessionDuration")
-	assertEqual(t, expected.PageViews, actual.PageViews, "Stats.PageViews")
-	assertEqual(t, expected.ActionsCount, actual.ActionsCount, "Stats.ActionsCount")
-	assertEqual(t, expected.SubscriptionTier, actual.SubscriptionTier, "Stats.SubscriptionTier")
-	assertEqual(t, expected.StorageUsed, actual.StorageUsed, "Stats.StorageUsed")
-	assertEqual(t, expected.BandwidthUsed, actual.BandwidthUsed, "Stats.BandwidthUsed")
-}
-
-// Test multiple ComplexUser array decoding
-func TestJsonDecodeComplexUserArray(t *testing.T) {
-	// Note: Previously disabled due to memory issues in validation - now fixed
-}
-
-// Test individual complex structures
-func TestJsonDecodeComplexProfile(t *testing.T) {
-	clearRefStructsCache()
-
-	profile := ComplexProfile{
-		FirstName:   "Alice",
-		LastName:    "Johnson",
-		DisplayName: "Alice J.",
-		Bio:         "Data scientist and AI researcher",
-		AvatarURL:   "https://example.com/alice.jpg",
-		BirthDate:   "1988-07-20",
-		PhoneNumbers: []ComplexPhoneNumber{
-			{ID: "ph_alice_1", Type: "mobile", Number: "+1-555-888-7777", IsPrimary: true, IsVerified: true},
-			{ID: "ph_alice_2", Type: "home", Number: "+1-555-666-5555", Extension: "", IsPrimary: false, IsVerified: false},
-		},
-		Addresses: []ComplexAddress{
-			{
-				ID: "addr_alice_1", Type: "home", Street: "789 Science Drive", City: "Tech City",
-				State: "TX", Country: "USA", PostalCode: "75001", IsPrimary: true, IsVerified: true,
-				Coordinates: &ComplexCoordinates{Latitude: 32.7767, Longitude: -96.7970, Accuracy: 8},
-			},
-		},
-		SocialLinks: []ComplexSocialLink{
-			{Platform: "researchgate", URL: "https://researchgate.net/alice", Username: "alice_research", Verified: true},
-			{Platform: "twitter", URL: "https://twitter.com/alicescience", Username: "@alicescience", Verified: false},
-		},
-		Preferences: ComplexPreferences{
-			Language:      "en-GB",
-			Theme:         "auto",
-			Notifications: ComplexNotificationPrefs{Email: false, Push: true, SMS: false, InApp: true, Marketing: false},
-			Privacy: ComplexPrivacySettings{
-				ProfileVisibility: "private",
-				ShowEmail:         false,
-				ShowPhone:         true,
-				AllowMessaging:    false,
-				BlockedUsers:      []string{"spammer1", "troll2", "bot3"},
-			},
-			Features: Features{BetaFeatures: true, Analytics: false, AdvancedSearch: true},
-		},
-		CustomFields: CustomFields{EmployeeID: "SCI001", Department: "Research", Team: "AI"},
-	}
-
-	// Encode
-	jsonBytes, err := Convert(profile).JsonEncode()
-	if err != nil {
-		t.Fatalf("JsonEncode(ComplexProfile) failed: %v", err)
-	}
-
-	// Decode
-	var decodedProfile ComplexProfile
-	err = Convert(string(jsonBytes)).JsonDecode(&decodedProfile)
-	if err != nil {
-		t.Fatalf("JsonDecode(ComplexProfile) returned error: %v", err)
-	}
-
-	// Validate
-	validateComplexProfile(t, profile, decodedProfile)
-}
-
-// Test coordinates pointer handling
-func TestJsonDecodeCoordinatesPointer(t *testing.T) {
-	clearRefStructsCache()
-
-	// Test nil coordinates
-	addr1 := ComplexAddress{
-		ID:          "test_nil",
-		Street:      "No GPS Street",
-		City:        "Unknown",
-		Coordinates: nil,
-	}
-
-	jsonBytes1, err := Convert(addr1).JsonEncode()
-	if err != nil {
-		t.Fatalf("JsonEncode(address with nil coordinates) failed: %v", err)
-	}
-
-	var decodedAddr1 ComplexAddress
-	err = Convert(string(jsonBytes1)).JsonDecode(&decodedAddr1)
-	if err != nil {
-		t.Fatalf("JsonDecode(address with nil coordinates) failed: %v", err)
-	}
-
-	if decodedAddr1.Coordinates != nil {
-		t.Errorf("Expected nil coordinates, got: %+v", decodedAddr1.Coordinates)
-	}
-
-	// Test valid coordinates
-	addr2 := ComplexAddress{
-		ID:     "test_coords",
-		Street: "GPS Street",
-		City:   "Located",
-		Coordinates: &ComplexCoordinates{
-			Latitude:  40.7589,
-			Longitude: -73.9851,
-			Accuracy:  12,
-		},
-	}
-
-	jsonBytes2, err := Convert(addr2).JsonEncode()
-	if err != nil {
-		t.Fatalf("JsonEncode(address with coordinates) failed: %v", err)
-	}
-
-	var decodedAddr2 ComplexAddress
-	err = Convert(string(jsonBytes2)).JsonDecode(&decodedAddr2)
-	if err != nil {
-		t.Fatalf("JsonDecode(address with coordinates) failed: %v", err)
-	}
-
-	if decodedAddr2.Coordinates == nil {
-		t.Fatalf("Expected non-nil coordinates, got nil")
-	}
-
-	assertEqual(t, addr2.Coordinates.Latitude, decodedAddr2.Coordinates.Latitude, "Coordinates.Latitude")
-	assertEqual(t, addr2.Coordinates.Longitude, decodedAddr2.Coordinates.Longitude, "Coordinates.Longitude")
-	assertEqual(t, addr2.Coordinates.Accuracy, decodedAddr2.Coordinates.Accuracy, "Coordinates.Accuracy")
-}
-
-// Test empty slices and null values
-func TestJsonDecodeEmptySlicesAndNulls(t *testing.T) {
-	clearRefStructsCache()
-
-	emptyUser := ComplexUser{
-		ID:          "empty_test",
-		Username:    "empty_user",
-		Email:       "empty@test.com",
-		Permissions: []string{},
-		Profile: ComplexProfile{
-			FirstName:    "Empty",
-			LastName:     "User",
-			PhoneNumbers: []ComplexPhoneNumber{},
-			Addresses:    []ComplexAddress{},
-			SocialLinks:  []ComplexSocialLink{},
-			Preferences: ComplexPreferences{
-				Privacy: ComplexPrivacySettings{
-					BlockedUsers: []string{},
-				},
-			},
-		},
-		Metadata: Metadata{
-			Experiments: []string{},
-		},
-	}
-
-	// Encode
-	jsonBytes, err := Convert(emptyUser).JsonEncode()
-	if err != nil {
-		t.Fatalf("JsonEncode(empty user) failed: %v", err)
-	}
-
-	// Decode
-	var decodedUser ComplexUser
-	err = Convert(string(jsonBytes)).JsonDecode(&decodedUser)
-	if err != nil {
-		t.Fatalf("JsonDecode(empty user) failed: %v", err)
-	}
-
-	// Validate empty slices are preserved
-	if len(decodedUser.Permissions) != 0 {
-		t.Errorf("Expected empty Permissions slice, got length %d", len(decodedUser.Permissions))
-	}
-	if len(decodedUser.Profile.PhoneNumbers) != 0 {
-		t.Errorf("Expected empty PhoneNumbers slice, got length %d", len(decodedUser.Profile.PhoneNumbers))
-	}
-	if len(decodedUser.Profile.Addresses) != 0 {
-		t.Errorf("Expected empty Addresses slice, got length %d", len(decodedUser.Profile.Addresses))
-	}
-	if len(decodedUser.Profile.SocialLinks) != 0 {
-		t.Errorf("Expected empty SocialLinks slice, got length %d", len(decodedUser.Profile.SocialLinks))
-	}
-	if len(decodedUser.Profile.Preferences.Privacy.BlockedUsers) != 0 {
-		t.Errorf("Expected empty BlockedUsers slice, got length %d", len(decodedUser.Profile.Preferences.Privacy.BlockedUsers))
-	}
-	if len(decodedUser.Metadata.Experiments) != 0 {
-		t.Errorf("Expected empty Experiments slice, got length %d", len(decodedUser.Metadata.Experiments))
-	}
-}
-
-// Test error handling with invalid JSON
-func TestJsonDecodeInvalidComplexJSON(t *testing.T) {
-	clearRefStructsCache()
-	invalidJSONTests := []struct {
-		json        string
-		description string
-		shouldFail  bool
-	}{
-		// Malformed JSON - should fail
-		{`{"id": "user_1", "username": "test", "email": "test@example.com"`, "malformed JSON (missing closing brace)", true},
-		// Wrong types - should fail
-		{`{"id": 123, "username": true, "email": ["not", "valid"]}`, "wrong types", true},
-		// Partial JSON - should NOT fail (valid but incomplete)
-		{`{"id": "test"}`, "partial JSON with valid field", false},
-		// Truncated nested structure - should fail
-		{`{"id": "test", "profile": {"first_name": "John", "last_name":`, "truncated nested structure", true},
-		// Invalid coordinates - should fail
-		{`{"id": "test", "profile": {"addresses": [{"coordinates": "invalid"}]}}`, "invalid coordinates", true},
-	}
-
-	for i, test := range invalidJSONTests {
-		var result ComplexUser
-		err := Convert(test.json).JsonDecode(&result)
-		if test.shouldFail {
-			if err == nil {
-				t.Errorf("Test %d (%s): JsonDecode should return error for invalid JSON: %s", i, test.description, test.json)
-			} else {
-				t.Logf("Test %d (%s): Correctly rejected invalid JSON with error: %v", i, test.description, err)
-			}
-		} else {
-			if err != nil {
-				t.Errorf("Test %d (%s): JsonDecode should NOT return error for valid JSON: %s, got error: %v", i, test.description, test.json, err)
-			} else {
-				t.Logf("Test %d (%s): Correctly accepted valid JSON", i, test.description)
-			}
-		}
-	}
-}
-
-// Test field name mapping for complex structures
-func TestJsonDecodeFieldNameMapping(t *testing.T) {
-	clearRefStructsCache()
-
-	// Test with PascalCase JSON (common in APIs)
-	pascalCaseJSON := `{
-		"ID": "test_mapping",
-		"Username": "mapper_user", 
-		"Email": "mapper@example.com",
-		"CreatedAt": "2024-01-01T00:00:00Z",
-		"LastLogin": "2024-01-02T00:00:00Z",
-		"IsActive": true,
-		"Profile": {
-			"FirstName": "Map",
-			"LastName": "Test",
-			"DisplayName": "Mapper",
-			"PhoneNumbers": [
-				{
-					"ID": "phone_1",
-					"Type": "mobile",
-					"Number": "+1-555-MAP-TEST",
-					"IsPrimary": true,
-					"IsVerified": false
-				}
-			]
-		}
-	}`
-
-	var user ComplexUser
-	err := Convert(pascalCaseJSON).JsonDecode(&user)
-	if err != nil {
-		t.Fatalf("JsonDecode(PascalCase JSON) failed: %v", err)
-	}
-
-	// Validate mapping worked
-	assertEqual(t, "test_mapping", user.ID, "ID mapping")
-	assertEqual(t, "mapper_user", user.Username, "Username mapping")
-	assertEqual(t, "mapper@example.com", user.Email, "Email mapping")
-	assertEqual(t, "Map", user.Profile.FirstName, "Profile.FirstName mapping")
-	assertEqual(t, "Test", user.Profile.LastName, "Profile.LastName mapping")
-
-	if len(user.Profile.PhoneNumbers) > 0 {
-		assertEqual(t, "phone_1", user.Profile.PhoneNumbers[0].ID, "PhoneNumber.ID mapping")
-		assertEqual(t, "mobile", user.Profile.PhoneNumbers[0].Type, "PhoneNumber.Type mapping")
-		assertEqual(t, true, user.Profile.PhoneNumbers[0].IsPrimary, "PhoneNumber.IsPrimary mapping")
-	} else {
-		t.Error("PhoneNumbers array is empty after decoding")
-	}
-}
-
-// TestFieldMappingDebug tests field name mapping issue
-func TestFieldMappingDebug(t *testing.T) {
-	clearRefStructsCache()
-
-	type TestStruct struct {
-		ID       string `json:"id"`
-		Username string `json:"username"`
-	}
-
-	// Debug the field mapping
-	var test TestStruct
-	target := refValueOf(&test)
-	elem := target.refElem()
-
-	var structInfo refStructType
-	getStructType(elem.Type(), &structInfo)
-
-	t.Logf("Struct fields count: %d", len(structInfo.fields))
-	for i, field := range structInfo.fields {
-		t.Logf("  Field %d: name='%s'", i, field.name)
-	}
-
-	// Test specific field lookups that should work
-	refValue := &refValue{}
-
-	// These should find the fields
-	index1 := refValue.findStructFieldByJsonName("ID", &structInfo)
-	t.Logf("Looking for 'ID': found at index %d", index1)
-
-	index2 := refValue.findStructFieldByJsonName("Username", &structInfo)
-	t.Logf("Looking for 'Username': found at index %d", index2)
-
-	// These are what the JSON actually contains
-	index3 := refValue.findStructFieldByJsonName("id", &structInfo)
-	t.Logf("Looking for 'id': found at index %d", index3)
-
-	index4 := refValue.findStructFieldByJsonName("username", &structInfo)
-	t.Logf("Looking for 'username': found at index %d", index4)
-}
-
-// findInString simple helper to find substring
-func findInString(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
-		}
-	}
-	return -1
-}
-
-// ============================================================================
-// HELPER FUNCTIONS
-// ============================================================================
-
-func assertEqual(t *testing.T, expected, actual interface{}, field string) {
-	if expected != actual {
-		// Avoid memory explosion by safely formatting values
-		expectedStr := safeFormat(expected)
-		actualStr := safeFormat(actual)
-		t.Errorf("%s: expected %s, got %s", field, expectedStr, actualStr)
-	}
-}
-
-// safeFormat safely formats values avoiding memory explosion
-func safeFormat(v interface{}) string {
-	// Use defer to handle any panics
-	defer func() {
-		if r := recover(); r != nil {
-			// Panic recovered, return safe fallback
-		}
-	}()
-
-	if v == nil {
-		return "<nil>"
-	}
-
-	// For simple types, use direct conversion
-	switch val := v.(type) {
-	case string:
-		if len(val) > 100 {
-			return "\"" + val[:100] + "...[truncated]\""
-		}
-		return "\"" + val + "\""
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		return Convert(val).String()
-	case bool:
-		if val {
-			return "true"
-		}
-		return "false"
-	case float32, float64:
-		return Convert(val).String()
-	default:
-		// For complex types, just return type info to avoid memory explosion
-		return "<complex-type>"
-	}
-}
-
-func assertSliceEqual(t *testing.T, expected, actual []string, field string) {
-	if len(expected) != len(actual) {
-		t.Errorf("%s: slice length mismatch, expected %d, got %d", field, len(expected), len(actual))
-		return
-	}
-
-	for i := 0; i < len(expected); i++ {
-		if expected[i] != actual[i] {
-			t.Errorf("%s[%d]: expected %q, got %q", field, i, expected[i], actual[i])
-		}
-	}
-}
-
-// ============================================================================
-// LEGACY COMPATIBILITY TESTS (for backward compatibility)
-// ============================================================================
-
-// Basic type decoding tests (kept for compatibility)
-func TestJsonDecodeBasicString(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{`"hello"`, "hello"},
-		{`""`, ""},
-		{`"hello\nworld"`, "hello\nworld"},
-	}
-
-	for _, test := range tests {
-		var result string
-		err := Convert(test.input).JsonDecode(&result)
-		if err != nil {
-			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
-			continue
-		}
-
-		if result != test.expected {
-			t.Errorf("JsonDecode(%s) = %q, expected %q", test.input, result, test.expected)
-		}
-	}
-}
-
-func TestJsonDecodeBasicInt(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"42", 42},
-		{"-123", -123},
-		{"0", 0},
-	}
-
-	for _, test := range tests {
-		var result int64
-		err := Convert(test.input).JsonDecode(&result)
-		if err != nil {
-			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
-			continue
-		}
-
-		if result != test.expected {
-			t.Errorf("JsonDecode(%s) = %d, expected %d", test.input, result, test.expected)
-		}
-	}
-}
-
-func TestJsonDecodeBasicBool(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"true", true},
-		{"false", false},
-	}
-
-	for _, test := range tests {
-		var result bool
-		err := Convert(test.input).JsonDecode(&result)
-		if err != nil {
-			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
-			continue
-		}
-
-		if result != test.expected {
-			t.Errorf("JsonDecode(%s) = %t, expected %t", test.input, result, test.expected)
-		}
-	}
-}
-
-func TestJsonDecodeInvalidJson(t *testing.T) {
-	tests := []string{
-		"invalid",
-		`"unterminated string`,
-		`{"invalid": json}`,
-		`[1, 2, 3`,
-	}
-
-	for _, test := range tests {
-		var result interface{}
-		err := Convert(test).JsonDecode(&result)
-		if err == nil {
-			t.Errorf("JsonDecode(%s) should return error for invalid JSON", test)
-		}
-	}
-}
-
-func TestParseJsonUintRef(t *testing.T) {
-	tests := []struct {
-		name        string
-		jsonStr     string
-		expectError bool
-		expected    uint64
-	}{{"positive integer", "123", false, 123},
-		{"zero", "0", false, 0},
-		{"large positive", "999999", false, 999999},
-		{"negative becomes positive", "-123", false, 123}, // Converts via int64 then cast
-		{"float gets truncated", "123.45", false, 123},    // ToInt64 truncates floats
-		{"invalid json", "abc", true, 0},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			c := &refValue{}
-			target := &refValue{}
-
-			err := c.parseJsonUintRef(tt.jsonStr, target)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("Expected error for input %q, but got none", tt.jsonStr)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Unexpected error for input %q: %v", tt.jsonStr, err)
-				}
-				// Note: We can't easily test the actual uint value set by refSetUint
-				// without more complex reflection setup
-			}
-		})
-	}
-}
-
-func TestParseIntSlice(t *testing.T) {
-	tests := []struct {
-		name        string
-		elements    []string
-		expectError bool
-		expected    []int
-	}{
-		{"valid integers", []string{"1", "2", "3"}, false, []int{1, 2, 3}},
-		{"single element", []string{"42"}, false, []int{42}},
-		{"empty slice", []string{}, false, []int{}},
-		{"with whitespace", []string{" 1 ", " 2 ", " 3 "}, false, []int{1, 2, 3}}, {"negative numbers", []string{"-1", "-2", "-3"}, false, []int{-1, -2, -3}},
-		{"float elements get truncated", []string{"1", "2.5", "3"}, false, []int{1, 2, 3}},
-		{"invalid element", []string{"1", "abc", "3"}, true, nil},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			c := &refValue{}
-			target := &refValue{}
-
-			err := c.parseIntSlice(tt.elements, target)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("Expected error for elements %v, but got none", tt.elements)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Unexpected error for elements %v: %v", tt.elements, err)
-				}
-				// Note: We can't easily test the actual slice value set by refSet
-				// without more complex reflection setup
-			}
-		})
-	}
-}
-
-func TestParseFloatSlice(t *testing.T) {
-	tests := []struct {
-		name        string
-		elements    []string
-		expectError bool
-		expected    []float64
-	}{
-		{"valid floats", []string{"1.1", "2.2", "3.3"}, false, []float64{1.1, 2.2, 3.3}},
-		{"integers as floats", []string{"1", "2", "3"}, false, []float64{1.0, 2.0, 3.0}},
-		{"single element", []string{"3.14159"}, false, []float64{3.14159}},
-		{"empty slice", []string{}, false, []float64{}},
-		{"with whitespace", []string{" 1.5 ", " 2.5 ", " 3.5 "}, false, []float64{1.5, 2.5, 3.5}},
-		{"negative numbers", []string{"-1.1", "-2.2", "-3.3"}, false, []float64{-1.1, -2.2, -3.3}},
-		{"invalid element", []string{"1.1", "abc", "3.3"}, true, nil},
-		{"mixed valid/invalid", []string{"1.0", "invalid", "3.0"}, true, nil},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			c := &refValue{}
-			target := &refValue{}
-
-			err := c.parseFloatSlice(tt.elements, target)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("Expected error for elements %v, but got none", tt.elements)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Unexpected error for elements %v: %v", tt.elements, err)
-				}
-			}
-		})
-	}
-}
-
-func TestParseBoolSlice(t *testing.T) {
-	tests := []struct {
-		name        string
-		elements    []string
-		expectError bool
-		expected    []bool
-	}{
-		{"valid bools", []string{"true", "false", "true"}, false, []bool{true, false, true}},
-		{"all true", []string{"true", "true", "true"}, false, []bool{true, true, true}},
-		{"all false", []string{"false", "false", "false"}, false, []bool{false, false, false}},
-		{"single true", []string{"true"}, false, []bool{true}},
-		{"single false", []string{"false"}, false, []bool{false}},
-		{"empty slice", []string{}, false, []bool{}},
-		{"with whitespace", []string{" true ", " false ", " true "}, false, []bool{true, false, true}},
-		{"invalid element", []string{"true", "invalid", "false"}, true, nil},
-		{"numeric bool", []string{"true", "1", "false"}, true, nil},
-		{"case sensitive", []string{"True", "False"}, true, nil},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			c := &refValue{}
-			target := &refValue{}
-
-			err := c.parseBoolSlice(tt.elements, target)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("Expected error for elements %v, but got none", tt.elements)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Unexpected error for elements %v: %v", tt.elements, err)
-				}
-			}
-		})
-	}
-}
+package tinywodp
+
+import (
+	"runtime"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Test complete ComplexUser structure decoding (encode-decode cycle)
+func TestJsonDecodeComplexUser(t *testing.T) {
+	// Note: This test verifies that complex JSON encoding/decoding doesn't crash
+	// Full validation is disabled due to memory complexity issues with the validation functions
+
+	// Generate test data and encode it
+	testUsers := GenerateComplexTestData(1)
+	originalUser := testUsers[0]
+
+	// Encode to JSON
+	jsonBytes, err := Convert(originalUser).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode(ComplexUser) failed: %v", err)
+	}
+	jsonStr := string(jsonBytes)
+	t.Logf("Generated JSON length: %d bytes", len(jsonStr))
+
+	// Test decoding doesn't crash
+	var decodedUser ComplexUser
+	err = Convert(jsonStr).JsonDecode(&decodedUser)
+	if err != nil {
+		t.Fatalf("JsonDecode(ComplexUser) returned error: %v", err)
+	}
+	// Basic validation - just check that main fields are populated
+	// (Detailed validation disabled due to memory explosion in assertEqual)
+	if decodedUser.ID == "" {
+		t.Errorf("ID should not be empty after decode")
+	}
+	if decodedUser.Username == "" {
+		t.Errorf("Username should not be empty after decode")
+	}
+	if decodedUser.Email == "" {
+		t.Errorf("Email should not be empty after decode")
+	}
+
+	// Validate nested structures are not corrupted
+	validateNestedStructures(t, originalUser, decodedUser)
+
+	t.Logf("ComplexUser JSON encode/decode completed successfully")
+	t.Logf("Basic validation: ID=%s, Username=%s, Email=%s",
+		safeFormat(decodedUser.ID),
+		safeFormat(decodedUser.Username),
+		safeFormat(decodedUser.Email))
+}
+
+// validateNestedStructures checks that nested structures are properly decoded
+func validateNestedStructures(t *testing.T, expected, actual ComplexUser) {
+	// Test Profile nested struct
+	t.Logf("Profile validation:")
+	t.Logf("  Expected FirstName: %s", safeFormat(expected.Profile.FirstName))
+	t.Logf("  Actual FirstName: %s", safeFormat(actual.Profile.FirstName))
+
+	if expected.Profile.FirstName != actual.Profile.FirstName {
+		t.Errorf("Profile.FirstName mismatch: expected %s, got %s",
+			safeFormat(expected.Profile.FirstName),
+			safeFormat(actual.Profile.FirstName))
+	}
+
+	if expected.Profile.LastName != actual.Profile.LastName {
+		t.Errorf("Profile.LastName mismatch: expected %s, got %s",
+			safeFormat(expected.Profile.LastName),
+			safeFormat(actual.Profile.LastName))
+	}
+
+	// Test PhoneNumbers slice
+	t.Logf("PhoneNumbers validation:")
+	t.Logf("  Expected count: %d", len(expected.Profile.PhoneNumbers))
+	t.Logf("  Actual count: %d", len(actual.Profile.PhoneNumbers))
+
+	if len(expected.Profile.PhoneNumbers) != len(actual.Profile.PhoneNumbers) {
+		t.Errorf("PhoneNumbers count mismatch: expected %d, got %d",
+			len(expected.Profile.PhoneNumbers),
+			len(actual.Profile.PhoneNumbers))
+		return
+	}
+
+	// Check first phone number if exists
+	if len(expected.Profile.PhoneNumbers) > 0 && len(actual.Profile.PhoneNumbers) > 0 {
+		expectedPhone := expected.Profile.PhoneNumbers[0]
+		actualPhone := actual.Profile.PhoneNumbers[0]
+
+		t.Logf("  First Phone ID - Expected: %s, Actual: %s",
+			safeFormat(expectedPhone.ID),
+			safeFormat(actualPhone.ID))
+		t.Logf("  First Phone Type - Expected: %s, Actual: %s",
+			safeFormat(expectedPhone.Type),
+			safeFormat(actualPhone.Type))
+		t.Logf("  First Phone Number - Expected: %s, Actual: %s",
+			safeFormat(expectedPhone.Number),
+			safeFormat(actualPhone.Number))
+
+		if expectedPhone.ID != actualPhone.ID {
+			t.Errorf("PhoneNumbers[0].ID corruption detected: expected %s, got %s",
+				safeFormat(expectedPhone.ID),
+				safeFormat(actualPhone.ID))
+		}
+
+		if expectedPhone.Type != actualPhone.Type {
+			t.Errorf("PhoneNumbers[0].Type corruption detected: expected %s, got %s",
+				safeFormat(expectedPhone.Type),
+				safeFormat(actualPhone.Type))
+		}
+
+		if expectedPhone.Number != actualPhone.Number {
+			t.Errorf("PhoneNumbers[0].Number corruption detected: expected %s, got %s",
+				safeFormat(expectedPhone.Number),
+				safeFormat(actualPhone.Number))
+		}
+	}
+
+	// Test Addresses slice
+	t.Logf("Addresses validation:")
+	t.Logf("  Expected count: %d", len(expected.Profile.Addresses))
+	t.Logf("  Actual count: %d", len(actual.Profile.Addresses))
+
+	if len(expected.Profile.Addresses) != len(actual.Profile.Addresses) {
+		t.Errorf("Addresses count mismatch: expected %d, got %d",
+			len(expected.Profile.Addresses),
+			len(actual.Profile.Addresses))
+		return
+	}
+
+	// Check first address if exists
+	if len(expected.Profile.Addresses) > 0 && len(actual.Profile.Addresses) > 0 {
+		expectedAddr := expected.Profile.Addresses[0]
+		actualAddr := actual.Profile.Addresses[0]
+
+		t.Logf("  First Address ID - Expected: %s, Actual: %s",
+			safeFormat(expectedAddr.ID),
+			safeFormat(actualAddr.ID))
+		t.Logf("  First Address Street - Expected: %s, Actual: %s",
+			safeFormat(expectedAddr.Street),
+			safeFormat(actualAddr.Street))
+		t.Logf("  First Address City - Expected: %s, Actual: %s",
+			safeFormat(expectedAddr.City),
+			safeFormat(actualAddr.City))
+
+		if expectedAddr.ID != actualAddr.ID {
+			t.Errorf("Addresses[0].ID corruption detected: expected %s, got %s",
+				safeFormat(expectedAddr.ID),
+				safeFormat(actualAddr.ID))
+		}
+
+		if expectedAddr.Street != actualAddr.Street {
+			t.Errorf("Addresses[0].Street corruption detected: expected %s, got %s",
+				safeFormat(expectedAddr.Street),
+				safeFormat(actualAddr.Street))
+		}
+
+		if expectedAddr.City != actualAddr.City {
+			t.Errorf("Addresses[0].City corruption detected: expected %s, got %s",
+				safeFormat(expectedAddr.City),
+				safeFormat(actualAddr.City))
+		}
+
+		// Test nested Coordinates
+		t.Logf("  First Address Coordinates - Expected: Lat=%f, Lng=%f",
+			expectedAddr.Coordinates.Latitude,
+			expectedAddr.Coordinates.Longitude)
+		t.Logf("  First Address Coordinates - Actual: Lat=%f, Lng=%f",
+			actualAddr.Coordinates.Latitude,
+			actualAddr.Coordinates.Longitude)
+
+		if expectedAddr.Coordinates.Latitude != actualAddr.Coordinates.Latitude {
+			t.Errorf("Addresses[0].Coordinates.Latitude corruption detected: expected %f, got %f",
+				expectedAddr.Coordinates.Latitude,
+				actualAddr.Coordinates.Latitude)
+		}
+	}
+	// Permissions slice (simple strings) must keep its contents
+	assertSliceEqual(t, expected.Permissions, actual.Permissions, "Permissions")
+}
+
+func validateComplexUserDecoding(t *testing.T, expected, actual ComplexUser) {
+	// Top-level fields
+	assertEqual(t, expected.ID, actual.ID, "ID")
+	assertEqual(t, expected.Username, actual.Username, "Username")
+	assertEqual(t, expected.Email, actual.Email, "Email")
+	assertEqual(t, expected.CreatedAt, actual.CreatedAt, "CreatedAt")
+	assertEqual(t, expected.LastLogin, actual.LastLogin, "LastLogin")
+	assertEqual(t, expected.IsActive, actual.IsActive, "IsActive")
+
+	// Profile validation
+	validateComplexProfile(t, expected.Profile, actual.Profile)
+
+	// Permissions slice
+	assertSliceEqual(t, expected.Permissions, actual.Permissions, "Permissions")
+
+	// Metadata
+	validateMetadata(t, expected.Metadata, actual.Metadata)
+
+	// Stats
+	validateComplexStats(t, expected.Stats, actual.Stats)
+}
+
+func validateComplexProfile(t *testing.T, expected, actual ComplexProfile) {
+	assertEqual(t, expected.FirstName, actual.FirstName, "Profile.FirstName")
+	assertEqual(t, expected.LastName, actual.LastName, "Profile.LastName")
+	assertEqual(t, expected.DisplayName, actual.DisplayName, "Profile.DisplayName")
+	assertEqual(t, expected.Bio, actual.Bio, "Profile.Bio")
+	assertEqual(t, expected.AvatarURL, actual.AvatarURL, "Profile.AvatarURL")
+	assertEqual(t, expected.BirthDate, actual.BirthDate, "Profile.BirthDate")
+
+	// Phone numbers
+	if len(expected.PhoneNumbers) != len(actual.PhoneNumbers) {
+		t.Errorf("PhoneNumbers length mismatch: expected %d, got %d", len(expected.PhoneNumbers), len(actual.PhoneNumbers))
+		return
+	}
+
+	for i, expectedPhone := range expected.PhoneNumbers {
+		if i >= len(actual.PhoneNumbers) {
+			t.Errorf("Missing phone number at index %d", i)
+			continue
+		}
+		actualPhone := actual.PhoneNumbers[i]
+		assertEqual(t, expectedPhone.ID, actualPhone.ID, Fmt("PhoneNumbers[%d].ID", i).String())
+		assertEqual(t, expectedPhone.Type, actualPhone.Type, Fmt("PhoneNumbers[%d].Type", i).String())
+		assertEqual(t, expectedPhone.Number, actualPhone.Number, Fmt("PhoneNumbers[%d].Number", i).String())
+		assertEqual(t, expectedPhone.Extension, actualPhone.Extension, Fmt("PhoneNumbers[%d].Extension", i).String())
+		assertEqual(t, expectedPhone.IsPrimary, actualPhone.IsPrimary, Fmt("PhoneNumbers[%d].IsPrimary", i).String())
+		assertEqual(t, expectedPhone.IsVerified, actualPhone.IsVerified, Fmt("PhoneNumbers[%d].IsVerified", i).String())
+	}
+
+	// Addresses with coordinates
+	if len(expected.Addresses) != len(actual.Addresses) {
+		t.Errorf("Addresses length mismatch: expected %d, got %d", len(expected.Addresses), len(actual.Addresses))
+		return
+	}
+
+	for i, expectedAddr := range expected.Addresses {
+		if i >= len(actual.Addresses) {
+			t.Errorf("Missing address at index %d", i)
+			continue
+		}
+		actualAddr := actual.Addresses[i]
+		validateComplexAddress(t, expectedAddr, actualAddr, i)
+	}
+
+	// Social links
+	if len(expected.SocialLinks) != len(actual.SocialLinks) {
+		t.Errorf("SocialLinks length mismatch: expected %d, got %d", len(expected.SocialLinks), len(actual.SocialLinks))
+		return
+	}
+
+	for i, expectedLink := range expected.SocialLinks {
+		if i >= len(actual.SocialLinks) {
+			t.Errorf("Missing social link at index %d", i)
+			continue
+		}
+		actualLink := actual.SocialLinks[i]
+		assertEqual(t, expectedLink.Platform, actualLink.Platform, Fmt("SocialLinks[%d].Platform", i).String())
+		assertEqual(t, expectedLink.URL, actualLink.URL, Fmt("SocialLinks[%d].URL", i).String())
+		assertEqual(t, expectedLink.Username, actualLink.Username, Fmt("SocialLinks[%d].Username", i).String())
+		assertEqual(t, expectedLink.Verified, actualLink.Verified, Fmt("SocialLinks[%d].Verified", i).String())
+	}
+
+	// Preferences
+	validateComplexPreferences(t, expected.Preferences, actual.Preferences)
+
+	// Custom fields
+	validateCustomFields(t, expected.CustomFields, actual.CustomFields)
+}
+
+func validateComplexAddress(t *testing.T, expected, actual ComplexAddress, index int) {
+	prefix := Fmt("Addresses[%d]", index).String()
+	assertEqual(t, expected.ID, actual.ID, prefix+".ID")
+	assertEqual(t, expected.Type, actual.Type, prefix+".Type")
+	assertEqual(t, expected.Street, actual.Street, prefix+".Street")
+	assertEqual(t, expected.Street2, actual.Street2, prefix+".Street2")
+	assertEqual(t, expected.City, actual.City, prefix+".City")
+	assertEqual(t, expected.State, actual.State, prefix+".State")
+	assertEqual(t, expected.Country, actual.Country, prefix+".Country")
+	assertEqual(t, expected.PostalCode, actual.PostalCode, prefix+".PostalCode")
+	assertEqual(t, expected.IsPrimary, actual.IsPrimary, prefix+".IsPrimary")
+	assertEqual(t, expected.IsVerified, actual.IsVerified, prefix+".IsVerified")
+
+	// Coordinates pointer handling
+	if expected.Coordinates == nil && actual.Coordinates == nil {
+		return // Both nil, OK
+	}
+	if expected.Coordinates == nil && actual.Coordinates != nil {
+		t.Errorf("%s.Coordinates: expected nil, got non-nil", prefix)
+		return
+	}
+	if expected.Coordinates != nil && actual.Coordinates == nil {
+		t.Errorf("%s.Coordinates: expected non-nil, got nil", prefix)
+		return
+	}
+
+	// Both non-nil, compare values
+	assertEqual(t, expected.Coordinates.Latitude, actual.Coordinates.Latitude, prefix+".Coordinates.Latitude")
+	assertEqual(t, expected.Coordinates.Longitude, actual.Coordinates.Longitude, prefix+".Coordinates.Longitude")
+	assertEqual(t, expected.Coordinates.Accuracy, actual.Coordinates.Accuracy, prefix+".Coordinates.Accuracy")
+}
+
+func validateComplexPreferences(t *testing.T, expected, actual ComplexPreferences) {
+	assertEqual(t, expected.Language, actual.Language, "Preferences.Language")
+	assertEqual(t, expected.Timezone, actual.Timezone, "Preferences.Timezone")
+	assertEqual(t, expected.Theme, actual.Theme, "Preferences.Theme")
+	assertEqual(t, expected.Currency, actual.Currency, "Preferences.Currency")
+	assertEqual(t, expected.DateFormat, actual.DateFormat, "Preferences.DateFormat")
+	assertEqual(t, expected.TimeFormat, actual.TimeFormat, "Preferences.TimeFormat")
+
+	// Notifications
+	assertEqual(t, expected.Notifications.Email, actual.Notifications.Email, "Preferences.Notifications.Email")
+	assertEqual(t, expected.Notifications.SMS, actual.Notifications.SMS, "Preferences.Notifications.SMS")
+	assertEqual(t, expected.Notifications.Push, actual.Notifications.Push, "Preferences.Notifications.Push")
+	assertEqual(t, expected.Notifications.InApp, actual.Notifications.InApp, "Preferences.Notifications.InApp")
+	assertEqual(t, expected.Notifications.Marketing, actual.Notifications.Marketing, "Preferences.Notifications.Marketing")
+
+	// Privacy
+	assertEqual(t, expected.Privacy.ProfileVisibility, actual.Privacy.ProfileVisibility, "Preferences.Privacy.ProfileVisibility")
+	assertEqual(t, expected.Privacy.ShowEmail, actual.Privacy.ShowEmail, "Preferences.Privacy.ShowEmail")
+	assertEqual(t, expected.Privacy.ShowPhone, actual.Privacy.ShowPhone, "Preferences.Privacy.ShowPhone")
+	assertEqual(t, expected.Privacy.AllowMessaging, actual.Privacy.AllowMessaging, "Preferences.Privacy.AllowMessaging")
+	assertSliceEqual(t, expected.Privacy.BlockedUsers, actual.Privacy.BlockedUsers, "Preferences.Privacy.BlockedUsers")
+
+	// Features
+	assertEqual(t, expected.Features.BetaFeatures, actual.Features.BetaFeatures, "Preferences.Features.BetaFeatures")
+	assertEqual(t, expected.Features.Analytics, actual.Features.Analytics, "Preferences.Features.Analytics")
+	assertEqual(t, expected.Features.AdvancedSearch, actual.Features.AdvancedSearch, "Preferences.Features.AdvancedSearch")
+}
+
+func validateCustomFields(t *testing.T, expected, actual CustomFields) {
+	assertEqual(t, expected.EmployeeID, actual.EmployeeID, "CustomFields.EmployeeID")
+	assertEqual(t, expected.Department, actual.Department, "CustomFields.Department")
+	assertEqual(t, expected.Team, actual.Team, "CustomFields.Team")
+}
+
+func validateMetadata(t *testing.T, expected, actual Metadata) {
+	assertEqual(t, expected.Source, actual.Source, "Metadata.Source")
+	assertEqual(t, expected.Campaign, actual.Campaign, "Metadata.Campaign")
+	assertEqual(t, expected.Referrer, actual.Referrer, "Metadata.Referrer")
+	assertEqual(t, expected.Score, actual.Score, "Metadata.Score")
+	assertSliceEqual(t, expected.Experiments, actual.Experiments, "Metadata.Experiments")
+}
+
+func validateComplexStats(t *testing.T, expected, actual ComplexStats) {
+	assertEqual(t, expected.LoginCount, actual.LoginCount, "Stats.LoginCount")
+	assertEqual(t, expected.LastActivity, actual.LastActivity, "Stats.LastActivity")
+	assertEqual(t, expected.SessionDuration, actual.SessionDuration, "Stats.SessionDuration")
+	assertEqual(t, expected.PageViews, actual.PageViews, "Stats.PageViews")
+	assertEqual(t, expected.ActionsCount, actual.ActionsCount, "Stats.ActionsCount")
+	assertEqual(t, expected.SubscriptionTier, actual.SubscriptionTier, "Stats.SubscriptionTier")
+	assertEqual(t, expected.StorageUsed, actual.StorageUsed, "Stats.StorageUsed")
+	assertEqual(t, expected.BandwidthUsed, actual.BandwidthUsed, "Stats.BandwidthUsed")
+}
+
+// Test multiple ComplexUser array decoding
+func TestJsonDecodeComplexUserArray(t *testing.T) {
+	// Note: Previously disabled due to memory issues in validation - now fixed
+}
+
+// Test individual complex structures
+func TestJsonDecodeComplexProfile(t *testing.T) {
+	clearRefStructsCache()
+
+	profile := ComplexProfile{
+		FirstName:   "Alice",
+		LastName:    "Johnson",
+		DisplayName: "Alice J.",
+		Bio:         "Data scientist and AI researcher",
+		AvatarURL:   "https://example.com/alice.jpg",
+		BirthDate:   "1988-07-20",
+		PhoneNumbers: []ComplexPhoneNumber{
+			{ID: "ph_alice_1", Type: "mobile", Number: "+1-555-888-7777", IsPrimary: true, IsVerified: true},
+			{ID: "ph_alice_2", Type: "home", Number: "+1-555-666-5555", Extension: "", IsPrimary: false, IsVerified: false},
+		},
+		Addresses: []ComplexAddress{
+			{
+				ID: "addr_alice_1", Type: "home", Street: "789 Science Drive", City: "Tech City",
+				State: "TX", Country: "USA", PostalCode: "75001", IsPrimary: true, IsVerified: true,
+				Coordinates: &ComplexCoordinates{Latitude: 32.7767, Longitude: -96.7970, Accuracy: 8},
+			},
+		},
+		SocialLinks: []ComplexSocialLink{
+			{Platform: "researchgate", URL: "https://researchgate.net/alice", Username: "alice_research", Verified: true},
+			{Platform: "twitter", URL: "https://twitter.com/alicescience", Username: "@alicescience", Verified: false},
+		},
+		Preferences: ComplexPreferences{
+			Language:      "en-GB",
+			Theme:         "auto",
+			Notifications: ComplexNotificationPrefs{Email: false, Push: true, SMS: false, InApp: true, Marketing: false},
+			Privacy: ComplexPrivacySettings{
+				ProfileVisibility: "private",
+				ShowEmail:         false,
+				ShowPhone:         true,
+				AllowMessaging:    false,
+				BlockedUsers:      []string{"spammer1", "troll2", "bot3"},
+			},
+			Features: Features{BetaFeatures: true, Analytics: false, AdvancedSearch: true},
+		},
+		CustomFields: CustomFields{EmployeeID: "SCI001", Department: "Research", Team: "AI"},
+	}
+
+	// Encode
+	jsonBytes, err := Convert(profile).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode(ComplexProfile) failed: %v", err)
+	}
+
+	// Decode
+	var decodedProfile ComplexProfile
+	err = Convert(string(jsonBytes)).JsonDecode(&decodedProfile)
+	if err != nil {
+		t.Fatalf("JsonDecode(ComplexProfile) returned error: %v", err)
+	}
+
+	// Validate
+	validateComplexProfile(t, profile, decodedProfile)
+}
+
+// Test coordinates pointer handling
+func TestJsonDecodeCoordinatesPointer(t *testing.T) {
+	clearRefStructsCache()
+
+	// Test nil coordinates
+	addr1 := ComplexAddress{
+		ID:          "test_nil",
+		Street:      "No GPS Street",
+		City:        "Unknown",
+		Coordinates: nil,
+	}
+
+	jsonBytes1, err := Convert(addr1).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode(address with nil coordinates) failed: %v", err)
+	}
+
+	var decodedAddr1 ComplexAddress
+	err = Convert(string(jsonBytes1)).JsonDecode(&decodedAddr1)
+	if err != nil {
+		t.Fatalf("JsonDecode(address with nil coordinates) failed: %v", err)
+	}
+
+	if decodedAddr1.Coordinates != nil {
+		t.Errorf("Expected nil coordinates, got: %+v", decodedAddr1.Coordinates)
+	}
+
+	// Test valid coordinates
+	addr2 := ComplexAddress{
+		ID:     "test_coords",
+		Street: "GPS Street",
+		City:   "Located",
+		Coordinates: &ComplexCoordinates{
+			Latitude:  40.7589,
+			Longitude: -73.9851,
+			Accuracy:  12,
+		},
+	}
+
+	jsonBytes2, err := Convert(addr2).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode(address with coordinates) failed: %v", err)
+	}
+
+	var decodedAddr2 ComplexAddress
+	err = Convert(string(jsonBytes2)).JsonDecode(&decodedAddr2)
+	if err != nil {
+		t.Fatalf("JsonDecode(address with coordinates) failed: %v", err)
+	}
+
+	if decodedAddr2.Coordinates == nil {
+		t.Fatalf("Expected non-nil coordinates, got nil")
+	}
+
+	assertEqual(t, addr2.Coordinates.Latitude, decodedAddr2.Coordinates.Latitude, "Coordinates.Latitude")
+	assertEqual(t, addr2.Coordinates.Longitude, decodedAddr2.Coordinates.Longitude, "Coordinates.Longitude")
+	assertEqual(t, addr2.Coordinates.Accuracy, decodedAddr2.Coordinates.Accuracy, "Coordinates.Accuracy")
+}
+
+// Test empty slices and null values
+func TestJsonDecodeEmptySlicesAndNulls(t *testing.T) {
+	clearRefStructsCache()
+
+	emptyUser := ComplexUser{
+		ID:          "empty_test",
+		Username:    "empty_user",
+		Email:       "empty@test.com",
+		Permissions: []string{},
+		Profile: ComplexProfile{
+			FirstName:    "Empty",
+			LastName:     "User",
+			PhoneNumbers: []ComplexPhoneNumber{},
+			Addresses:    []ComplexAddress{},
+			SocialLinks:  []ComplexSocialLink{},
+			Preferences: ComplexPreferences{
+				Privacy: ComplexPrivacySettings{
+					BlockedUsers: []string{},
+				},
+			},
+		},
+		Metadata: Metadata{
+			Experiments: []string{},
+		},
+	}
+
+	// Encode
+	jsonBytes, err := Convert(emptyUser).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode(empty user) failed: %v", err)
+	}
+
+	// Decode
+	var decodedUser ComplexUser
+	err = Convert(string(jsonBytes)).JsonDecode(&decodedUser)
+	if err != nil {
+		t.Fatalf("JsonDecode(empty user) failed: %v", err)
+	}
+
+	// Validate empty slices are preserved
+	if len(decodedUser.Permissions) != 0 {
+		t.Errorf("Expected empty Permissions slice, got length %d", len(decodedUser.Permissions))
+	}
+	if len(decodedUser.Profile.PhoneNumbers) != 0 {
+		t.Errorf("Expected empty PhoneNumbers slice, got length %d", len(decodedUser.Profile.PhoneNumbers))
+	}
+	if len(decodedUser.Profile.Addresses) != 0 {
+		t.Errorf("Expected empty Addresses slice, got length %d", len(decodedUser.Profile.Addresses))
+	}
+	if len(decodedUser.Profile.SocialLinks) != 0 {
+		t.Errorf("Expected empty SocialLinks slice, got length %d", len(decodedUser.Profile.SocialLinks))
+	}
+	if len(decodedUser.Profile.Preferences.Privacy.BlockedUsers) != 0 {
+		t.Errorf("Expected empty BlockedUsers slice, got length %d", len(decodedUser.Profile.Preferences.Privacy.BlockedUsers))
+	}
+	if len(decodedUser.Metadata.Experiments) != 0 {
+		t.Errorf("Expected empty Experiments slice, got length %d", len(decodedUser.Metadata.Experiments))
+	}
+}
+
+// Test error handling with invalid JSON
+func TestJsonDecodeInvalidComplexJSON(t *testing.T) {
+	clearRefStructsCache()
+	invalidJSONTests := []struct {
+		json        string
+		description string
+		shouldFail  bool
+	}{
+		// Malformed JSON - should fail
+		{`{"id": "user_1", "username": "test", "email": "test@example.com"`, "malformed JSON (missing closing brace)", true},
+		// Wrong types - should fail
+		{`{"id": 123, "username": true, "email": ["not", "valid"]}`, "wrong types", true},
+		// Partial JSON - should NOT fail (valid but incomplete)
+		{`{"id": "test"}`, "partial JSON with valid field", false},
+		// Truncated nested structure - should fail
+		{`{"id": "test", "profile": {"first_name": "John", "last_name":`, "truncated nested structure", true},
+		// Invalid coordinates - should fail
+		{`{"id": "test", "profile": {"addresses": [{"coordinates": "invalid"}]}}`, "invalid coordinates", true},
+	}
+
+	for i, test := range invalidJSONTests {
+		var result ComplexUser
+		err := Convert(test.json).JsonDecode(&result)
+		if test.shouldFail {
+			if err == nil {
+				t.Errorf("Test %d (%s): JsonDecode should return error for invalid JSON: %s", i, test.description, test.json)
+			} else {
+				t.Logf("Test %d (%s): Correctly rejected invalid JSON with error: %v", i, test.description, err)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %d (%s): JsonDecode should NOT return error for valid JSON: %s, got error: %v", i, test.description, test.json, err)
+			} else {
+				t.Logf("Test %d (%s): Correctly accepted valid JSON", i, test.description)
+			}
+		}
+	}
+}
+
+// Test field name mapping for complex structures
+func TestJsonDecodeFieldNameMapping(t *testing.T) {
+	clearRefStructsCache()
+
+	// Test with PascalCase JSON (common in APIs)
+	pascalCaseJSON := `{
+		"ID": "test_mapping",
+		"Username": "mapper_user", 
+		"Email": "mapper@example.com",
+		"CreatedAt": "2024-01-01T00:00:00Z",
+		"LastLogin": "2024-01-02T00:00:00Z",
+		"IsActive": true,
+		"Profile": {
+			"FirstName": "Map",
+			"LastName": "Test",
+			"DisplayName": "Mapper",
+			"PhoneNumbers": [
+				{
+					"ID": "phone_1",
+					"Type": "mobile",
+					"Number": "+1-555-MAP-TEST",
+					"IsPrimary": true,
+					"IsVerified": false
+				}
+			]
+		}
+	}`
+
+	var user ComplexUser
+	err := Convert(pascalCaseJSON).JsonDecode(&user)
+	if err != nil {
+		t.Fatalf("JsonDecode(PascalCase JSON) failed: %v", err)
+	}
+
+	// Validate mapping worked
+	assertEqual(t, "test_mapping", user.ID, "ID mapping")
+	assertEqual(t, "mapper_user", user.Username, "Username mapping")
+	assertEqual(t, "mapper@example.com", user.Email, "Email mapping")
+	assertEqual(t, "Map", user.Profile.FirstName, "Profile.FirstName mapping")
+	assertEqual(t, "Test", user.Profile.LastName, "Profile.LastName mapping")
+
+	if len(user.Profile.PhoneNumbers) > 0 {
+		assertEqual(t, "phone_1", user.Profile.PhoneNumbers[0].ID, "PhoneNumber.ID mapping")
+		assertEqual(t, "mobile", user.Profile.PhoneNumbers[0].Type, "PhoneNumber.Type mapping")
+		assertEqual(t, true, user.Profile.PhoneNumbers[0].IsPrimary, "PhoneNumber.IsPrimary mapping")
+	} else {
+		t.Error("PhoneNumbers array is empty after decoding")
+	}
+}
+
+// TestFieldMappingDebug tests field name mapping issue
+func TestFieldMappingDebug(t *testing.T) {
+	clearRefStructsCache()
+
+	type TestStruct struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	}
+
+	// Debug the field mapping
+	var test TestStruct
+	target := refValueOf(&test)
+	elem := target.refElem()
+
+	var structInfo refStructType
+	getStructType(elem.Type(), &structInfo)
+
+	t.Logf("Struct fields count: %d", len(structInfo.fields))
+	for i, field := range structInfo.fields {
+		t.Logf("  Field %d: name='%s'", i, field.name)
+	}
+
+	// Test specific field lookups that should work
+	// These should find the fields
+	index1 := findStructFieldByJsonName("ID", &structInfo, MatchDefault)
+	t.Logf("Looking for 'ID': found at index %d", index1)
+
+	index2 := findStructFieldByJsonName("Username", &structInfo, MatchDefault)
+	t.Logf("Looking for 'Username': found at index %d", index2)
+
+	// These are what the JSON actually contains
+	index3 := findStructFieldByJsonName("id", &structInfo, MatchDefault)
+	t.Logf("Looking for 'id': found at index %d", index3)
+
+	index4 := findStructFieldByJsonName("username", &structInfo, MatchDefault)
+	t.Logf("Looking for 'username': found at index %d", index4)
+}
+
+// ============================================================================
+// HELPER FUNCTIONS
+// ============================================================================
+
+func assertEqual(t *testing.T, expected, actual interface{}, field string) {
+	if expected != actual {
+		// Avoid memory explosion by safely formatting values
+		expectedStr := safeFormat(expected)
+		actualStr := safeFormat(actual)
+		t.Errorf("%s: expected %s, got %s", field, expectedStr, actualStr)
+	}
+}
+
+// safeFormat safely formats values avoiding memory explosion
+func safeFormat(v interface{}) string {
+	// Use defer to handle any panics
+	defer func() {
+		if r := recover(); r != nil {
+			// Panic recovered, return safe fallback
+		}
+	}()
+
+	if v == nil {
+		return "<nil>"
+	}
+
+	// For simple types, use direct conversion
+	switch val := v.(type) {
+	case string:
+		if len(val) > 100 {
+			return "\"" + val[:100] + "...[truncated]\""
+		}
+		return "\"" + val + "\""
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return Convert(val).String()
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	case float32, float64:
+		return Convert(val).String()
+	default:
+		// For complex types, just return type info to avoid memory explosion
+		return "<complex-type>"
+	}
+}
+
+func assertSliceEqual(t *testing.T, expected, actual []string, field string) {
+	if len(expected) != len(actual) {
+		t.Errorf("%s: slice length mismatch, expected %d, got %d", field, len(expected), len(actual))
+		return
+	}
+
+	for i := 0; i < len(expected); i++ {
+		if expected[i] != actual[i] {
+			t.Errorf("%s[%d]: expected %q, got %q", field, i, expected[i], actual[i])
+		}
+	}
+}
+
+// ============================================================================
+// LEGACY COMPATIBILITY TESTS (for backward compatibility)
+// ============================================================================
+
+// Basic type decoding tests (kept for compatibility)
+func TestJsonDecodeBasicString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello"`, "hello"},
+		{`""`, ""},
+		{`"hello\nworld"`, "hello\nworld"},
+	}
+
+	for _, test := range tests {
+		var result string
+		err := Convert(test.input).JsonDecode(&result)
+		if err != nil {
+			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
+			continue
+		}
+
+		if result != test.expected {
+			t.Errorf("JsonDecode(%s) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestJsonDecodeBasicInt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"42", 42},
+		{"-123", -123},
+		{"0", 0},
+	}
+
+	for _, test := range tests {
+		var result int64
+		err := Convert(test.input).JsonDecode(&result)
+		if err != nil {
+			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
+			continue
+		}
+
+		if result != test.expected {
+			t.Errorf("JsonDecode(%s) = %d, expected %d", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestJsonDecodeBasicBool(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+	}
+
+	for _, test := range tests {
+		var result bool
+		err := Convert(test.input).JsonDecode(&result)
+		if err != nil {
+			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
+			continue
+		}
+
+		if result != test.expected {
+			t.Errorf("JsonDecode(%s) = %t, expected %t", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestJsonDecodeInvalidJson(t *testing.T) {
+	tests := []string{
+		"invalid",
+		`"unterminated string`,
+		`{"invalid": json}`,
+		`[1, 2, 3`,
+	}
+
+	for _, test := range tests {
+		var result interface{}
+		err := Convert(test).JsonDecode(&result)
+		if err == nil {
+			t.Errorf("JsonDecode(%s) should return error for invalid JSON", test)
+		}
+	}
+}
+
+func TestParseJsonUintRef(t *testing.T) {
+	tests := []struct {
+		name        string
+		jsonStr     string
+		expectError bool
+		expected    uint64
+	}{{"positive integer", "123", false, 123},
+		{"zero", "0", false, 0},
+		{"large positive", "999999", false, 999999},
+		{"negative becomes positive", "-123", false, 123}, // Converts via int64 then cast
+		{"float gets truncated", "123.45", false, 123},    // ToInt64 truncates floats
+		{"invalid json", "abc", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jh := &jsonH{}
+			target := &refValue{}
+
+			err := jh.parseJsonUintRef(tt.jsonStr, target)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for input %q, but got none", tt.jsonStr)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Unexpected error for input %q: %v", tt.jsonStr, err)
+				}
+				// Note: We can't easily test the actual uint value set by refSetUint
+				// without more complex reflection setup
+			}
+		})
+	}
+}
+
+func TestParseIntSlice(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectError bool
+		expected    []int
+	}{
+		{"valid integers", `[1, 2, 3]`, false, []int{1, 2, 3}},
+		{"single element", `[42]`, false, []int{42}},
+		{"empty slice", `[]`, false, []int{}},
+		{"with whitespace", `[ 1 , 2 , 3 ]`, false, []int{1, 2, 3}},
+		{"negative numbers", `[-1, -2, -3]`, false, []int{-1, -2, -3}},
+		{"float elements get truncated", `[1, 2.5, 3]`, false, []int{1, 2, 3}},
+		{"invalid element", `[1, abc, 3]`, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []int
+			err := Convert(tt.input).JsonDecode(&result)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for input %s, but got none", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for input %s: %v", tt.input, err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("JsonDecode(%s) = %v, expected %v", tt.input, result, tt.expected)
+			}
+			for i := range tt.expected {
+				if result[i] != tt.expected[i] {
+					t.Errorf("JsonDecode(%s)[%d] = %d, expected %d", tt.input, i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseFloatSlice(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectError bool
+		expected    []float64
+	}{
+		{"valid floats", `[1.1, 2.2, 3.3]`, false, []float64{1.1, 2.2, 3.3}},
+		{"integers as floats", `[1, 2, 3]`, false, []float64{1.0, 2.0, 3.0}},
+		{"single element", `[3.14159]`, false, []float64{3.14159}},
+		{"empty slice", `[]`, false, []float64{}},
+		{"with whitespace", `[ 1.5 , 2.5 , 3.5 ]`, false, []float64{1.5, 2.5, 3.5}},
+		{"negative numbers", `[-1.1, -2.2, -3.3]`, false, []float64{-1.1, -2.2, -3.3}},
+		{"invalid element", `[1.1, abc, 3.3]`, true, nil},
+		{"mixed valid/invalid", `[1.0, invalid, 3.0]`, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []float64
+			err := Convert(tt.input).JsonDecode(&result)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for input %s, but got none", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for input %s: %v", tt.input, err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("JsonDecode(%s) = %v, expected %v", tt.input, result, tt.expected)
+			}
+			for i := range tt.expected {
+				if result[i] != tt.expected[i] {
+					t.Errorf("JsonDecode(%s)[%d] = %v, expected %v", tt.input, i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseBoolSlice(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectError bool
+		expected    []bool
+	}{
+		{"valid bools", `[true, false, true]`, false, []bool{true, false, true}},
+		{"all true", `[true, true, true]`, false, []bool{true, true, true}},
+		{"all false", `[false, false, false]`, false, []bool{false, false, false}},
+		{"single true", `[true]`, false, []bool{true}},
+		{"single false", `[false]`, false, []bool{false}},
+		{"empty slice", `[]`, false, []bool{}},
+		{"with whitespace", `[ true , false , true ]`, false, []bool{true, false, true}},
+		{"invalid element", `[true, invalid, false]`, true, nil},
+		{"numeric bool", `[true, 1, false]`, true, nil},
+		{"case sensitive", `[True, False]`, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []bool
+			err := Convert(tt.input).JsonDecode(&result)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for input %s, but got none", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for input %s: %v", tt.input, err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("JsonDecode(%s) = %v, expected %v", tt.input, result, tt.expected)
+			}
+			for i := range tt.expected {
+				if result[i] != tt.expected[i] {
+					t.Errorf("JsonDecode(%s)[%d] = %t, expected %t", tt.input, i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+// Slice decoding through the pooled jsonH path
+func TestJsonDecodeSlices(t *testing.T) {
+	clearRefStructsCache()
+
+	t.Run("string slice", func(t *testing.T) {
+		var result []string
+		if err := Convert(`["a", "b\"c", ""]`).JsonDecode(&result); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		assertSliceEqual(t, []string{"a", `b"c`, ""}, result, "strings")
+	})
+
+	t.Run("struct slice", func(t *testing.T) {
+		var result []Address
+		err := Convert(`[{"Id":"1","City":"NYC"},{"Id":"2","City":"LA"}]`).JsonDecode(&result)
+		if err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(result) != 2 || result[0].City != "NYC" || result[1].Id != "2" {
+			t.Errorf("JsonDecode([]Address) = %+v", result)
+		}
+	})
+
+	t.Run("pointer slice", func(t *testing.T) {
+		var result []*Address
+		if err := Convert(`[{"Id":"1"}, null]`).JsonDecode(&result); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(result) != 2 || result[0] == nil || result[0].Id != "1" || result[1] != nil {
+			t.Errorf("JsonDecode([]*Address) = %+v", result)
+		}
+	})
+
+	t.Run("pointer slice with nested pointers", func(t *testing.T) {
+		var result []*ComplexAddress
+		input := `[{"ID":"a1","City":"Lima","Coordinates":{"Latitude":-12.04}},null,{"ID":"a2","Coordinates":null}]`
+		if err := Convert(input).JsonDecode(&result); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		// Elements must stay valid after a collection
+		runtime.GC()
+		if len(result) != 3 || result[0] == nil || result[1] != nil || result[2] == nil {
+			t.Fatalf("JsonDecode([]*ComplexAddress) = %+v", result)
+		}
+		if result[0].City != "Lima" || result[0].Coordinates == nil || result[0].Coordinates.Latitude != -12.04 {
+			t.Errorf("first element = %+v", result[0])
+		}
+		if result[2].ID != "a2" || result[2].Coordinates != nil {
+			t.Errorf("third element = %+v", result[2])
+		}
+	})
+
+	t.Run("string pointer slice", func(t *testing.T) {
+		var result []*string
+		if err := Convert(`["x", null, "ñ"]`).JsonDecode(&result); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		runtime.GC()
+		if len(result) != 3 || result[0] == nil || *result[0] != "x" || result[1] != nil || result[2] == nil || *result[2] != "ñ" {
+			t.Errorf("JsonDecode([]*string) = %v", result)
+		}
+	})
+
+	t.Run("nested slice", func(t *testing.T) {
+		var result [][]int
+		if err := Convert(`[[1,2],[],[3]]`).JsonDecode(&result); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(result) != 3 || len(result[0]) != 2 || len(result[1]) != 0 || result[2][0] != 3 {
+			t.Errorf("JsonDecode([][]int) = %v", result)
+		}
+	})
+
+	t.Run("struct with slice field", func(t *testing.T) {
+		var person Person
+		err := Convert(`{"Id":"p1","Addresses":[{"Street":"Main St"}]}`).JsonDecode(&person)
+		if err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(person.Addresses) != 1 || person.Addresses[0].Street != "Main St" {
+			t.Errorf("JsonDecode(Person).Addresses = %+v", person.Addresses)
+		}
+	})
+}
+
+func TestJsonDecodeFieldMatchModes(t *testing.T) {
+	clearRefStructsCache()
+
+	type Account struct {
+		UserName string
+		Email    string `json:"mail"`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		match    FieldMatch
+		expected Account
+	}{
+		{"default snake_case", `{"user_name":"a","mail":"m"}`, MatchDefault, Account{"a", "m"}},
+		{"default ignores case variants", `{"USERNAME":"a","MAIL":"m"}`, MatchDefault, Account{}},
+		{"case-insensitive name", `{"username":"a"}`, MatchCaseInsensitive, Account{UserName: "a"}},
+		{"case-insensitive upper", `{"USERNAME":"a","MAIL":"m"}`, MatchCaseInsensitive, Account{"a", "m"}},
+		{"exact accepts name and tag", `{"UserName":"a","mail":"m"}`, MatchExact, Account{"a", "m"}},
+		{"exact rejects snake_case", `{"user_name":"a"}`, MatchExact, Account{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result Account
+			if err := Convert(tt.input).JsonDecode(&result, tt.match); err != nil {
+				t.Fatalf("JsonDecode returned error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("JsonDecode(%s) = %+v, expected %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// Test that empty and whitespace-only input is reported distinctly from syntax errors
+func TestJsonDecodeEmptyInput(t *testing.T) {
+	clearRefStructsCache()
+
+	targets := map[string]func() any{
+		"string":  func() any { return new(string) },
+		"int":     func() any { return new(int) },
+		"uint":    func() any { return new(uint8) },
+		"float":   func() any { return new(float64) },
+		"bool":    func() any { return new(bool) },
+		"struct":  func() any { return new(Person) },
+		"slice":   func() any { return new([]string) },
+		"pointer": func() any { return new(*Address) },
+		"map":     func() any { return new(map[string]int) },
+		"any":     func() any { return new(any) },
+	}
+
+	for kind, newTarget := range targets {
+		for _, input := range []string{"", " ", "\n\t\r "} {
+			if err := Convert(input).JsonDecode(newTarget()); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+				t.Errorf("%s: JsonDecode(%q) = %v, expected ErrEmptyInput", kind, input, err)
+			}
+			if err := Unmarshal([]byte(input), newTarget()); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+				t.Errorf("%s: Unmarshal(%q) = %v, expected ErrEmptyInput", kind, input, err)
+			}
+		}
+
+		for _, input := range []string{"{", "[1,", "tru", `"open`} {
+			err := Convert(input).JsonDecode(newTarget())
+			if err == nil || !Contains(err.Error(), string(ErrSyntax)) || Contains(err.Error(), string(ErrEmptyInput)) {
+				t.Errorf("%s: JsonDecode(%q) = %v, expected ErrSyntax", kind, input, err)
+			}
+		}
+	}
+
+	if err := DecodeArrayToChan([]byte("  "), make(chan int, 1)); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("DecodeArrayToChan(blank) = %v, expected ErrEmptyInput", err)
+	}
+}
+
+// Test fixed-size arrays round-trip and follow encoding/json length rules
+func TestJsonArrays(t *testing.T) {
+	clearRefStructsCache()
+
+	type Point struct {
+		X, Y int
+	}
+	type Shape struct {
+		Origin  [3]float64
+		Hash    [16]byte
+		Corners [2]Point
+		Grid    [2][2]int
+	}
+
+	shape := Shape{
+		Origin:  [3]float64{1.5, -2, 0.25},
+		Corners: [2]Point{{1, 2}, {3, 4}},
+		Grid:    [2][2]int{{1, 2}, {3, 4}},
+	}
+	shape.Hash[0], shape.Hash[15] = 0xde, 0xad
+
+	encoded, err := Convert(shape).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	expected := `{"Origin":[1.5,-2,0.25],"Hash":[222,0,0,0,0,0,0,0,0,0,0,0,0,0,0,173],"Corners":[{"X":1,"Y":2},{"X":3,"Y":4}],"Grid":[[1,2],[3,4]]}`
+	if string(encoded) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", string(encoded), expected)
+	}
+
+	var decoded Shape
+	if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if decoded != shape {
+		t.Errorf("round trip = %+v, expected %+v", decoded, shape)
+	}
+
+	// Extra elements are dropped, missing ones are zero-filled
+	short := [3]int{7, 8, 9}
+	if err := Convert(`[1,2,3,4,5]`).JsonDecode(&short); err != nil || short != [3]int{1, 2, 3} {
+		t.Errorf("truncate: got %v, err %v", short, err)
+	}
+	if err := Convert(`[5]`).JsonDecode(&short); err != nil || short != [3]int{5, 0, 0} {
+		t.Errorf("zero-fill: got %v, err %v", short, err)
+	}
+	if err := Convert(`{"a":1}`).JsonDecode(&short); err == nil {
+		t.Error("object into array should return error")
+	}
+}
+
+func TestJsonDecodeSinglePass(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		ID   int
+		Tags []string
+	}
+
+	// Slices grow past their initial capacity and keep the exact length
+	input := "["
+	for i := 0; i < 37; i++ {
+		if i > 0 {
+			input += ","
+		}
+		input += `{"ID":` + Convert(i).String() + `,"Tags":["t"]}`
+	}
+	input += "]"
+	var items []Item
+	if err := Convert(input).JsonDecode(&items); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if len(items) != 37 || items[36].ID != 36 || items[20].Tags[0] != "t" {
+		t.Errorf("decoded %d items, last %+v", len(items), items[len(items)-1])
+	}
+
+	// The last occurrence of a repeated key wins and unknown members are skipped
+	var item Item
+	if err := Convert(`{"ID":1,"Extra":{"a":[1,{}]},"ID":2}`).JsonDecode(&item); err != nil || item.ID != 2 {
+		t.Errorf("repeated key: got %+v, err %v", item, err)
+	}
+
+	for _, bad := range []string{`{"ID":1} {"ID":2}`, `{"ID":1,}`, `[1 2]`, `{"ID":1`, `{"Extra":[}`} {
+		var v any
+		if err := Convert(bad).JsonDecode(&item); err == nil {
+			t.Errorf("JsonDecode(%q) into struct should fail", bad)
+		}
+		if err := Convert(bad).JsonDecode(&v); err == nil {
+			t.Errorf("JsonDecode(%q) into any should fail", bad)
+		}
+	}
+}
+
+func TestJsonDecodeNestedSlicesAndMaps(t *testing.T) {
+	clearRefStructsCache()
+
+	t.Run("polygon", func(t *testing.T) {
+		var polygon [][]float64
+		input := `[[-77.03, -12.04], [-77.01, -12.05], [], [-77.03, -12.04, 0.5]]`
+		if err := Convert(input).JsonDecode(&polygon); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(polygon) != 4 || len(polygon[0]) != 2 || polygon[1][1] != -12.05 || polygon[2] == nil || len(polygon[3]) != 3 {
+			t.Errorf("JsonDecode([][]float64) = %v", polygon)
+		}
+	})
+
+	t.Run("matrix of fixed rows", func(t *testing.T) {
+		var matrix [][2]int
+		if err := Convert(`[[1,2],[3],[5,6,7]]`).JsonDecode(&matrix); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(matrix) != 3 || matrix[0] != [2]int{1, 2} || matrix[1] != [2]int{3, 0} || matrix[2] != [2]int{5, 6} {
+			t.Errorf("JsonDecode([][2]int) = %v", matrix)
+		}
+	})
+
+	t.Run("slice of maps", func(t *testing.T) {
+		var rows []map[string]any
+		input := `[{"id":1,"tags":["a","b"]},{},null,{"nested":{"ok":true}}]`
+		if err := Convert(input).JsonDecode(&rows); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(rows) != 4 || rows[0]["id"] != float64(1) || len(rows[1]) != 0 || rows[2] != nil {
+			t.Fatalf("JsonDecode([]map[string]any) = %v", rows)
+		}
+		if tags, ok := rows[0]["tags"].([]any); !ok || len(tags) != 2 || tags[1] != "b" {
+			t.Errorf("rows[0][tags] = %v", rows[0]["tags"])
+		}
+		if nested, ok := rows[3]["nested"].(map[string]any); !ok || nested["ok"] != true {
+			t.Errorf("rows[3][nested] = %v", rows[3]["nested"])
+		}
+	})
+
+	t.Run("struct with matrix and map slice fields", func(t *testing.T) {
+		type Layer struct {
+			Grid  [][]int
+			Props []map[string]string
+		}
+		var layers []Layer
+		input := `[{"Grid":[[1],[2,3]],"Props":[{"k":"v"}]},{"Grid":[],"Props":[]}]`
+		if err := Convert(input).JsonDecode(&layers); err != nil {
+			t.Fatalf("JsonDecode returned error: %v", err)
+		}
+		if len(layers) != 2 || layers[0].Grid[1][1] != 3 || layers[0].Props[0]["k"] != "v" || len(layers[1].Grid) != 0 {
+			t.Errorf("JsonDecode([]Layer) = %+v", layers)
+		}
+	})
+
+	// A wrong element type deep inside reports its element
+	var grid [][]int
+	if err := Convert(`[[1],[2,"x"]]`).JsonDecode(&grid); err == nil {
+		t.Error("expected an error for a string inside [][]int")
+	}
+}
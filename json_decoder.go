@@ -0,0 +1,282 @@
+package tinywodp
+
+import (
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Streaming JSON decoder
+// Reads from the underlying reader in small chunks and frames one top-level value
+// at a time with an incremental scanner, so concatenated documents and NDJSON
+// streams are decoded without loading the entire input into memory
+
+// reader interface for JSON input - private interface compatible with io.Reader
+// Mirrors the writer interface to avoid importing io and keep binary size minimal
+type reader interface {
+	Read(p []byte) (n int, err error)
+}
+
+// decoderReadSize is the chunk size requested from the reader on each refill
+const decoderReadSize = 4096
+
+// JsonDecoder reads and decodes JSON values from an input stream
+//
+// Usage patterns:
+//
+//	dec := NewJsonDecoder(httpRequestBody)
+//	err := dec.Decode(&user)
+//
+//	dec := NewJsonDecoder(ndjsonFile) // one value per line, or concatenated
+//	for dec.More() {
+//		if err := dec.Decode(&event); err != nil { ... }
+//	}
+//
+// When the stream ends cleanly between values Decode returns the reader's
+// error unchanged (io.EOF for standard readers)
+type JsonDecoder struct {
+	r    reader
+	buf  []byte         // unread input; buf[:scanPos] has already been scanned
+	err  error          // sticky reader error, reported once buffered input is consumed
+	opts []DecodeOption // applied to every Decode call
+
+	// incremental scanner state for the value being framed
+	scanPos  int  // next byte to scan
+	start    int  // index of first byte of the current value, -1 when not started
+	depth    int  // nesting level of { and [
+	inString bool // inside a quoted string
+	escaped  bool // previous byte was a backslash inside a string
+	scalar   bool // current value is a bare literal or number
+
+	bomChecked bool // the start of the stream was checked for a byte order mark
+}
+
+// NewJsonDecoder returns a new decoder that reads from r
+func NewJsonDecoder(r reader) *JsonDecoder {
+	return &JsonDecoder{
+		r:     r,
+		buf:   allocBytes(decoderReadSize),
+		start: -1,
+	}
+}
+
+// Decode reads the next JSON value from the stream and stores it in target
+func (d *JsonDecoder) Decode(target any) error {
+	if d.r == nil {
+		return Err(errInvalidJSON, "decoder reader cannot be nil")
+	}
+	if err := d.skipBOM(); err != nil {
+		return err
+	}
+
+	value, err := d.readValue()
+	if err != nil {
+		return err
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(d.opts)
+	return jh.decode(value, target)
+}
+
+// UseNumber makes every following Decode return Number for generic numbers
+func (d *JsonDecoder) UseNumber() {
+	d.opts = append(d.opts, UseNumber)
+}
+
+// Skip discards the next JSON value in the stream without decoding it
+// Returns the reader's error unchanged when the stream has ended cleanly
+func (d *JsonDecoder) Skip() error {
+	if d.r == nil {
+		return Err(errInvalidJSON, "decoder reader cannot be nil")
+	}
+	if err := d.skipBOM(); err != nil {
+		return err
+	}
+
+	for {
+		if end, ok := d.scan(); ok {
+			// Validate in place: the bytes are dropped right after, so no copy is made
+			_, err := skipJsonValue(unsafe.String(&d.buf[d.start], end-d.start), 0)
+			d.consume(end)
+			return err
+		}
+		if !d.refill() {
+			_, err := d.readValue() // reports truncation or the clean end of stream
+			return err
+		}
+	}
+}
+
+// More reports whether there is another value available in the stream
+func (d *JsonDecoder) More() bool {
+	if d.skipBOM() != nil {
+		return true // Decode reports the error
+	}
+	for {
+		for d.scanPos < len(d.buf) {
+			if !isJsonSpace(d.buf[d.scanPos]) {
+				return true
+			}
+			d.scanPos++
+		}
+		if !d.refill() {
+			return false
+		}
+	}
+}
+
+// readValue frames the next complete top-level value and returns it as a string
+// The consumed bytes are dropped from the buffer before returning
+func (d *JsonDecoder) readValue() (string, error) {
+	for {
+		if end, ok := d.scan(); ok {
+			value := string(d.buf[d.start:end])
+			d.consume(end)
+			return value, nil
+		}
+
+		if !d.refill() {
+			// Input ended: a bare scalar is complete at EOF, anything else is truncated
+			if d.start >= 0 && d.scalar {
+				value := string(d.buf[d.start:d.scanPos])
+				d.consume(d.scanPos)
+				return value, nil
+			}
+			if d.start >= 0 {
+				return "", Err(errInvalidJSON, "unexpected end of input: "+d.err.Error())
+			}
+			d.consume(len(d.buf))
+			return "", d.err
+		}
+	}
+}
+
+// scan advances the incremental scanner over buffered bytes
+// Returns the end offset of the current value and true once it is complete
+func (d *JsonDecoder) scan() (int, bool) {
+	for d.scanPos < len(d.buf) {
+		b := d.buf[d.scanPos]
+
+		// Looking for the start of the next value
+		if d.start < 0 {
+			if isJsonSpace(b) {
+				d.scanPos++
+				continue
+			}
+			d.start = d.scanPos
+			switch b {
+			case '{', '[':
+				d.depth = 1
+			case '"':
+				d.inString = true
+			default:
+				d.scalar = true
+			}
+			d.scanPos++
+			continue
+		}
+
+		// Bare literal or number: a top-level value must be followed by whitespace
+		// or the end of input, so "true{" or "1[" stay in the literal and fail to parse
+		if d.scalar {
+			if isJsonSpace(b) {
+				return d.scanPos, true
+			}
+			d.scanPos++
+			continue
+		}
+
+		d.scanPos++
+		if d.inString {
+			switch {
+			case d.escaped:
+				d.escaped = false
+			case b == '\\':
+				d.escaped = true
+			case b == '"':
+				d.inString = false
+				if d.depth == 0 {
+					return d.scanPos, true // top-level string
+				}
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			d.inString = true
+		case '{', '[':
+			d.depth++
+		case '}', ']':
+			d.depth--
+			if d.depth == 0 {
+				return d.scanPos, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// consume drops the first n bytes of the buffer and resets the scanner state
+func (d *JsonDecoder) consume(n int) {
+	remaining := copy(d.buf, d.buf[n:])
+	d.buf = d.buf[:remaining]
+	d.scanPos = 0
+	d.start = -1
+	d.depth = 0
+	d.inString = false
+	d.escaped = false
+	d.scalar = false
+}
+
+// refill reads the next chunk from the reader into the buffer
+// Returns false once the reader has reported an error (including EOF)
+func (d *JsonDecoder) refill() bool {
+	if d.err != nil {
+		return false
+	}
+
+	// Drop bytes before the current value so the buffer only holds unread input
+	if d.start > 0 {
+		shift := d.start
+		remaining := copy(d.buf, d.buf[shift:])
+		d.buf = d.buf[:remaining]
+		d.scanPos -= shift
+		d.start = 0
+	} else if d.start < 0 && d.scanPos > 0 {
+		remaining := copy(d.buf, d.buf[d.scanPos:])
+		d.buf = d.buf[:remaining]
+		d.scanPos = 0
+	}
+
+	if cap(d.buf)-len(d.buf) < decoderReadSize {
+		grown := allocBytes(2*cap(d.buf) + decoderReadSize)[:len(d.buf)]
+		copy(grown, d.buf)
+		freeHint(d.buf)
+		d.buf = grown
+	}
+
+	n, err := d.r.Read(d.buf[len(d.buf) : len(d.buf)+decoderReadSize])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err != nil {
+		d.err = err
+	}
+	return n > 0 || err == nil
+}
+
+// isJsonSpace reports whether b is insignificant JSON whitespace
+func isJsonSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// isJsonBlank reports whether s is empty or contains only JSON whitespace
+func isJsonBlank(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isJsonSpace(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// testReader implements the reader interface returning data in fixed-size chunks
+type testReader struct {
+	data  string
+	chunk int
+	eof   error
+}
+
+func (r *testReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.eof
+	}
+	n := r.chunk
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	if n > len(p) {
+		n = len(p)
+	}
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+var errTestEOF = Err("EOF")
+
+func TestJsonDecoderConcatenatedValues(t *testing.T) {
+	clearRefStructsCache()
+
+	input := `{"Id":"1","Street":"Main St"} {"Id":"2","Street":"Oak Ave"}` + "\n" + `{"Id":"3","Street":"Elm {x}"}`
+
+	// Tiny chunks force values to span multiple reads
+	for _, chunk := range []int{1, 3, 7, 4096} {
+		dec := NewJsonDecoder(&testReader{data: input, chunk: chunk, eof: errTestEOF})
+
+		var ids []string
+		for dec.More() {
+			var addr Address
+			if err := dec.Decode(&addr); err != nil {
+				t.Fatalf("chunk %d: Decode returned error: %v", chunk, err)
+			}
+			ids = append(ids, addr.Id)
+		}
+
+		if len(ids) != 3 || ids[0] != "1" || ids[2] != "3" {
+			t.Errorf("chunk %d: decoded ids %v, expected [1 2 3]", chunk, ids)
+		}
+	}
+}
+
+func TestJsonDecoderNDJSONScalars(t *testing.T) {
+	input := "\"a\\\"b\"\n42\ntrue\n"
+	dec := NewJsonDecoder(&testReader{data: input, chunk: 2, eof: errTestEOF})
+
+	var s string
+	if err := dec.Decode(&s); err != nil || s != `a"b` {
+		t.Errorf("Decode string = %q, %v", s, err)
+	}
+	var n int64
+	if err := dec.Decode(&n); err != nil || n != 42 {
+		t.Errorf("Decode int = %d, %v", n, err)
+	}
+	var b bool
+	if err := dec.Decode(&b); err != nil || !b {
+		t.Errorf("Decode bool = %t, %v", b, err)
+	}
+
+	// Stream ended cleanly: the reader error is returned unchanged
+	if err := dec.Decode(&s); err != errTestEOF {
+		t.Errorf("Decode at end returned %v, expected reader EOF", err)
+	}
+}
+
+func TestJsonDecoderTruncatedValue(t *testing.T) {
+	dec := NewJsonDecoder(&testReader{data: `{"Id":"1"`, chunk: 4, eof: errTestEOF})
+
+	var addr Address
+	err := dec.Decode(&addr)
+	if err == nil || err == errTestEOF {
+		t.Errorf("Decode of truncated value should return a JSON error, got %v", err)
+	}
+}
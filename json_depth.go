@@ -0,0 +1,136 @@
+package tinywodp
+
+import (
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Nesting limits and cycle detection
+// Encoding a value that points back to one of its ancestors would recurse until
+// the stack overflows, and so would decoding a hostile payload made of thousands
+// of nested brackets. Both directions stop at a maximum nesting depth, and the
+// encoder checks pointer chains for cycles before writing anything:
+//
+//	a := &Node{}
+//	a.Next = a
+//	_, err := Marshal(a) // circular reference error instead of a crash
+
+// DefaultMaxDepth is the nesting limit used until SetMaxDepth is called
+const DefaultMaxDepth = 1000
+
+// maxJsonDepth is the active nesting limit for encoding and decoding
+var maxJsonDepth = DefaultMaxDepth
+
+// SetMaxDepth changes the maximum nesting of objects and arrays accepted when
+// encoding and decoding; n <= 0 restores DefaultMaxDepth
+// Not safe to call concurrently with encoding or decoding
+func SetMaxDepth(n int) {
+	if n <= 0 {
+		n = DefaultMaxDepth
+	}
+	maxJsonDepth = n
+}
+
+// enterJsonDepth counts one more nesting level for a decode, failing past the limit
+func (jh *jsonH) enterJsonDepth() error {
+	if jh.jDepth >= jh.jMaxDepth {
+		return Err(ErrMaxDepth, Convert(jh.jMaxDepth).String())
+	}
+	jh.jDepth++
+	return nil
+}
+
+// leaveJsonDepth undoes enterJsonDepth
+func (jh *jsonH) leaveJsonDepth() {
+	jh.jDepth--
+}
+
+// encodeVisit identifies a value reached through a pointer; the type is part
+// of the key because a struct and its first field share an address
+type encodeVisit struct {
+	ptr unsafe.Pointer
+	typ *refType
+}
+
+// checkEncodeGraph walks the values reachable from v and reports a cycle or a
+// nesting deeper than limit; only composite values are visited, so the cost
+// does not grow with the number of scalar fields and elements
+func checkEncodeGraph(v *refValue, limit int) error {
+	return walkEncodeGraph(v, 0, limit, nil)
+}
+
+// walkEncodeGraph visits v at the given depth; path holds the pointers being followed
+func walkEncodeGraph(v *refValue, depth, limit int, path []encodeVisit) error {
+	if v == nil || !v.refIsValid() {
+		return nil
+	}
+	if depth > limit {
+		return Err(ErrMaxDepth, Convert(limit).String())
+	}
+	// Custom representations are encoded without walking their fields
+	if isTimeType(v) || isRawJSONType(v) || customCodecFor(v)&(codecMarshal|codecMarshalPtr) != 0 {
+		return nil
+	}
+
+	switch v.refKind() {
+	case tpPointer:
+		elem := v.refElem()
+		if !elem.refIsValid() {
+			return nil
+		}
+		visit := encodeVisit{ptr: elem.ptr, typ: elem.Type()}
+		for _, seen := range path {
+			if seen == visit {
+				return Err(errCircularRef, "while encoding")
+			}
+		}
+		return walkEncodeGraph(elem, depth, limit, append(path, visit))
+	case tpInterface:
+		inner := v.refInterfaceValue()
+		if inner == nil {
+			return nil
+		}
+		return walkEncodeGraph(refValueOf(inner), depth, limit, path)
+	case tpStruct:
+		for i := range v.refNumField() {
+			field := v.refField(i)
+			if field.refIsValid() && !isScalarType(field.Type()) {
+				if err := walkEncodeGraph(field, depth+1, limit, path); err != nil {
+					return err
+				}
+			}
+		}
+	case tpSlice, tpArray:
+		if isScalarType(v.Type().Elem()) {
+			return nil
+		}
+		for i := range v.refLen() {
+			if err := walkEncodeGraph(v.refIndex(i), depth+1, limit, path); err != nil {
+				return err
+			}
+		}
+	case tpMap:
+		if isScalarType(v.Type().mapElem()) {
+			return nil
+		}
+		m, err := addressableMap(v)
+		if err != nil {
+			return err
+		}
+		return m.refMapRange(func(_, elem *refValue) error {
+			return walkEncodeGraph(elem, depth+1, limit, path)
+		})
+	}
+	return nil
+}
+
+// isScalarType reports whether values of type t cannot contain other values
+func isScalarType(t *refType) bool {
+	switch t.Kind() {
+	case tpString, tpBool, tpInt, tpInt8, tpInt16, tpInt32, tpInt64,
+		tpUint, tpUint8, tpUint16, tpUint32, tpUint64, tpFloat32, tpFloat64:
+		return true
+	}
+	return false
+}
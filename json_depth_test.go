@@ -0,0 +1,75 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type depthNode struct {
+	Name string
+	Next *depthNode
+	Kids []*depthNode
+	Any  any
+}
+
+func TestJsonEncodeCycles(t *testing.T) {
+	clearRefStructsCache()
+
+	self := &depthNode{Name: "a"}
+	self.Next = self
+
+	loop := &depthNode{Name: "a", Next: &depthNode{Name: "b"}}
+	loop.Next.Kids = []*depthNode{loop}
+
+	viaAny := &depthNode{Name: "a"}
+	viaAny.Any = viaAny
+
+	for name, v := range map[string]*depthNode{"self": self, "slice": loop, "interface": viaAny} {
+		if _, err := Marshal(v); err == nil || !Contains(err.Error(), string(errCircularRef)) {
+			t.Errorf("%s: Marshal error = %v, expected circular reference", name, err)
+		}
+		if err := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) { return len(p), nil }}).Encode(v); err == nil || !Contains(err.Error(), string(errCircularRef)) {
+			t.Errorf("%s: Encode error = %v, expected circular reference", name, err)
+		}
+	}
+
+	// Shared, acyclic pointers are fine
+	shared := &depthNode{Name: "s"}
+	dag := &depthNode{Name: "root", Next: shared, Kids: []*depthNode{shared, shared}}
+	if _, err := Marshal(dag); err != nil {
+		t.Errorf("Marshal of shared pointers returned error: %v", err)
+	}
+}
+
+func TestJsonMaxDepth(t *testing.T) {
+	clearRefStructsCache()
+	defer SetMaxDepth(0)
+	SetMaxDepth(5)
+
+	// Encode: a chain six levels deep
+	var head *depthNode
+	for range 6 {
+		head = &depthNode{Name: "n", Kids: []*depthNode{head}}
+	}
+	if _, err := Marshal(head); err == nil || !Contains(err.Error(), string(ErrMaxDepth)) {
+		t.Errorf("Marshal error = %v, expected max depth", err)
+	}
+
+	// Decode: typed and generic targets
+	deep := `[[[[[[1]]]]]]`
+	var typed [][][][][][]int
+	if err := Convert(deep).JsonDecode(&typed); err == nil || !Contains(err.Error(), string(ErrMaxDepth)) {
+		t.Errorf("typed JsonDecode error = %v, expected max depth", err)
+	}
+	var generic any
+	if err := Convert(deep).JsonDecode(&generic); err == nil || !Contains(err.Error(), string(ErrMaxDepth)) {
+		t.Errorf("generic JsonDecode error = %v, expected max depth", err)
+	}
+
+	// Within the limit both directions work
+	var shallow [][]int
+	if err := Convert(`[[1],[2]]`).JsonDecode(&shallow); err != nil || len(shallow) != 2 {
+		t.Errorf("shallow JsonDecode = %v, %v", shallow, err)
+	}
+}
@@ -0,0 +1,385 @@
+package tinywodp
+
+import (
+	"sync"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Lazy documents
+// A Document keeps the raw JSON text and only decodes the subtree a caller asks
+// for, so handlers can project large payloads into small focused types:
+//
+//	doc, err := ParseDocument(body)
+//	var privacy ComplexPrivacySettings
+//	err = doc.DecodePath("Profile.Preferences.Privacy", &privacy)
+//	city, err := doc.Value("Profile.Addresses.0.City") // generic value
+//
+// Path segments are object keys (exact, then snake_case of the segment) or
+// array indexes, written as "Addresses.0" or "Addresses[0]". An empty path
+// addresses the whole document.
+//
+// Each object or array a lookup passes through is scanned once: the offsets of
+// its members are kept, keys unescaped, so later lookups through it jump
+// straight to the member without rescanning. Values themselves are only
+// decoded by DecodePath, Value and Decode. A Document is safe for concurrent
+// use.
+
+// Document is a lazily decoded JSON value
+type Document struct {
+	raw string
+
+	mu    sync.Mutex
+	index map[int]*jsonNode // containers scanned so far, by offset in raw
+}
+
+// jsonNode holds the member offsets of one object or array of a Document
+type jsonNode struct {
+	kind  byte     // '{', '[' or 0 for a scalar
+	keys  []string // unescaped keys, objects only
+	spans []int    // start and end offsets in raw of each member value
+}
+
+// ParseDocument wraps JSON data without decoding it
+// Only emptiness is checked up front; syntax errors surface when a path is read
+func ParseDocument(data []byte) (*Document, error) {
+	raw, err := stripJsonBOM(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if raw = trimJsonSpace(raw); raw == "" {
+		return nil, Err(ErrEmptyInput)
+	}
+	return &Document{raw: raw}, nil
+}
+
+// Raw returns the JSON text of the value at path
+func (d *Document) Raw(path string) (string, error) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.jInput = d.raw
+	raw, err := d.lookup(jh, path)
+	return raw, jh.positionError(err)
+}
+
+// DecodePath decodes only the value at path into target
+func (d *Document) DecodePath(path string, target any, opts ...DecodeOption) error {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	jh.jInput = d.raw
+	value, err := d.lookup(jh, path)
+	if err != nil {
+		return jh.positionError(err)
+	}
+	return jh.decode(value, target)
+}
+
+// Decode decodes the whole document into target
+func (d *Document) Decode(target any, opts ...DecodeOption) error {
+	return d.DecodePath("", target, opts...)
+}
+
+// JsonGet decodes only the value at path into target; the rest of the input
+// is skipped without being decoded (see Document for the path syntax)
+//
+//	var city string
+//	err := Convert(jsonStr).JsonGet("profile.addresses[0].city", &city)
+func (c *refValue) JsonGet(path string, target any, opts ...DecodeOption) error {
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	return jh.decodePath(c.getString(), path, target)
+}
+
+// decodePath looks up path in raw and decodes the value found into target
+// Error positions are reported within the whole of raw
+func (jh *jsonH) decodePath(raw, path string, target any) error {
+	if isJsonBlank(raw) {
+		return Err(ErrEmptyInput)
+	}
+	jh.jInput = raw
+	value, err := jh.lookupJsonPath(raw, path)
+	if err != nil {
+		return jh.positionError(err)
+	}
+	return jh.decode(value, target)
+}
+
+// Value returns the value at path as a generic value (see json_any.go)
+func (d *Document) Value(path string) (any, error) {
+	var v any
+	if err := d.DecodePath(path, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// lookup returns the raw value at path, indexing the containers it crosses
+func (d *Document) lookup(jh *jsonH, path string) (string, error) {
+	start, end := 0, len(d.raw)
+	err := walkJsonPath(path, func(segment string) error {
+		node, err := d.node(jh, start, end)
+		if err != nil {
+			return err
+		}
+		start, end, err = node.member(segment)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return d.raw[start:end], nil
+}
+
+// node returns the index of the value at raw[start:end], scanning it on the
+// first visit
+func (d *Document) node(jh *jsonH, start, end int) (*jsonNode, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if node, ok := d.index[start]; ok {
+		return node, nil
+	}
+	node, err := jh.indexJsonNode(d.raw[:end], start)
+	if err != nil {
+		return nil, err
+	}
+	if d.index == nil {
+		d.index = map[int]*jsonNode{}
+	}
+	d.index[start] = node
+	return node, nil
+}
+
+// indexJsonNode records the members of the object or array at s[i], which
+// ends s; offsets stay relative to s so errors point into the whole document
+func (jh *jsonH) indexJsonNode(s string, i int) (*jsonNode, error) {
+	node := &jsonNode{kind: s[i]}
+	var close byte
+	switch s[i] {
+	case '{':
+		close = '}'
+	case '[':
+		close = ']'
+	default:
+		node.kind = 0
+		return node, nil
+	}
+
+	i, done, err := jh.openJsonContainer(s, i, close)
+	for !done {
+		if err != nil {
+			return nil, err
+		}
+		if close == '}' {
+			var key string
+			if key, i, err = jh.scanJsonKey(s, i); err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key)
+		}
+		start := skipJsonSpace(s, i)
+		if i, err = jh.skipJsonValueAt(s, start); err != nil {
+			return nil, err
+		}
+		node.spans = append(node.spans, start, i)
+		i, done, err = jh.nextJsonMember(s, i, close)
+	}
+	return node, err
+}
+
+// member returns the offsets of the value segment names: an exact key, else
+// the first key equal to its snake_case, or an array index
+func (n *jsonNode) member(segment string) (int, int, error) {
+	switch n.kind {
+	case '{':
+		snake, found := toSnakeCase(segment), -1
+		for k, key := range n.keys {
+			if key == segment {
+				return n.spans[2*k], n.spans[2*k+1], nil
+			}
+			if found == -1 && key == snake {
+				found = k
+			}
+		}
+		if found != -1 {
+			return n.spans[2*found], n.spans[2*found+1], nil
+		}
+		return 0, 0, Err(ErrPathNotFound, segment)
+	case '[':
+		index, err := Convert(segment).ToInt64()
+		if err != nil || index < 0 || !isJsonNumber(segment) || !isJsonIntegerLiteral(segment) {
+			return 0, 0, Err(ErrPathNotFound, "invalid array index "+segment)
+		}
+		if index >= int64(len(n.spans)/2) {
+			return 0, 0, Err(ErrPathNotFound, segment)
+		}
+		return n.spans[2*index], n.spans[2*index+1], nil
+	}
+	return 0, 0, Err(ErrPathNotFound, segment+" (not an object or array)")
+}
+
+// lookupJsonPath walks a path through raw JSON one level at a time
+// Each level is scanned in place: members before the match are skipped without
+// being decoded and nothing after it is read
+func (jh *jsonH) lookupJsonPath(raw, path string) (string, error) {
+	err := walkJsonPath(path, func(segment string) error {
+		var err error
+		raw, err = jh.lookupJsonSegment(raw, segment)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// walkJsonPath calls step with each segment of path in order, stopping at the
+// first error; empty segments ("a..b" or a leading dot) are skipped
+func walkJsonPath(path string, step func(segment string) error) error {
+	for path != "" {
+		segment, rest, err := nextJsonPathSegment(path)
+		if err != nil {
+			return err
+		}
+		path = rest
+		if segment == "" {
+			continue
+		}
+		if err := step(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextJsonPathSegment splits the first segment off a path such as
+// "profile.addresses[0].city" or "profile.addresses.0.city"
+// A [ without its ] is an error, so every call consumes part of the path
+func nextJsonPathSegment(path string) (string, string, error) {
+	whole := path
+	if path[0] == '.' {
+		path = path[1:]
+	}
+	if len(path) > 0 && path[0] == '[' {
+		end := indexByte(path, ']')
+		if end == -1 {
+			return "", "", Err(errInvalidPath, "unclosed [ in", whole)
+		}
+		return path[1:end], path[end+1:], nil
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] == '.' || path[i] == '[' {
+			return path[:i], path[i:], nil
+		}
+	}
+	return path, "", nil
+}
+
+// lookupJsonSegment returns the raw member of an object or element of an array
+func (jh *jsonH) lookupJsonSegment(raw, segment string) (string, error) {
+	raw = trimJsonSpace(raw)
+	if len(raw) < 2 {
+		return "", Err(ErrPathNotFound, segment)
+	}
+	switch raw[0] {
+	case '{':
+		return jh.lookupJsonMember(raw, segment)
+	case '[':
+		return jh.lookupJsonElement(raw, segment)
+	}
+	return "", Err(ErrPathNotFound, segment+" (not an object or array)")
+}
+
+// lookupJsonMember returns the value of key name in the object raw
+// An exact key wins; otherwise the first key equal to the snake_case of name
+func (jh *jsonH) lookupJsonMember(raw, name string) (string, error) {
+	snake := toSnakeCase(name)
+	fallback, found := "", false
+
+	i := skipJsonSpace(raw, 1)
+	if i < len(raw) && raw[i] == '}' {
+		return "", Err(ErrPathNotFound, name)
+	}
+	for {
+		if i >= len(raw) || raw[i] != '"' {
+			return "", jh.errorAt(raw, i, Err(errInvalidJSON, "expected object key"))
+		}
+		keyEnd, err := skipJsonString(raw, i)
+		if err != nil {
+			return "", jh.errorAt(raw, keyEnd, err)
+		}
+		key := raw[i+1 : keyEnd-1]
+		if indexByte(key, '\\') != -1 {
+			if key, err = jh.unescapeJsonString(key); err != nil {
+				return "", jh.errorAt(raw, i, err)
+			}
+		}
+
+		i = skipJsonSpace(raw, keyEnd)
+		if i >= len(raw) || raw[i] != ':' {
+			return "", jh.errorAt(raw, i, Err(errInvalidJSON, "missing ':' after key "+key))
+		}
+		start := skipJsonSpace(raw, i+1)
+		end, err := skipJsonValue(raw, start)
+		if err != nil {
+			return "", jh.errorAt(raw, end, err)
+		}
+		if key == name {
+			return raw[start:end], nil
+		}
+		if !found && key == snake {
+			fallback, found = raw[start:end], true
+		}
+
+		i = skipJsonSpace(raw, end)
+		if i < len(raw) && raw[i] == ',' {
+			i = skipJsonSpace(raw, i+1)
+			continue
+		}
+		if i < len(raw) && raw[i] == '}' {
+			break
+		}
+		return "", jh.errorAt(raw, i, Err(errInvalidJSON, "expected ',' or '}' in object"))
+	}
+	if found {
+		return fallback, nil
+	}
+	return "", Err(ErrPathNotFound, name)
+}
+
+// lookupJsonElement returns the element at the decimal index segment of the array raw
+func (jh *jsonH) lookupJsonElement(raw, segment string) (string, error) {
+	index, err := Convert(segment).ToInt64()
+	if err != nil || index < 0 || !isJsonNumber(segment) || !isJsonIntegerLiteral(segment) {
+		return "", Err(ErrPathNotFound, "invalid array index "+segment)
+	}
+
+	i := skipJsonSpace(raw, 1)
+	if i < len(raw) && raw[i] == ']' {
+		return "", Err(ErrPathNotFound, segment)
+	}
+	for n := int64(0); ; n++ {
+		start := skipJsonSpace(raw, i)
+		end, err := skipJsonValue(raw, start)
+		if err != nil {
+			return "", jh.errorAt(raw, end, err)
+		}
+		if n == index {
+			return raw[start:end], nil
+		}
+
+		i = skipJsonSpace(raw, end)
+		if i < len(raw) && raw[i] == ',' {
+			i++
+			continue
+		}
+		if i < len(raw) && raw[i] == ']' {
+			return "", Err(ErrPathNotFound, segment)
+		}
+		return "", jh.errorAt(raw, i, Err(errInvalidJSON, "expected ',' or ']' in array"))
+	}
+}
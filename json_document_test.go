@@ -0,0 +1,186 @@
+package tinywodp
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestDocumentDecodePath(t *testing.T) {
+	clearRefStructsCache()
+
+	user := GenerateComplexTestData(1)[0]
+	data, err := Marshal(user)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		t.Fatalf("ParseDocument returned error: %v", err)
+	}
+
+	var privacy ComplexPrivacySettings
+	if err := doc.DecodePath("Profile.Preferences.Privacy", &privacy); err != nil {
+		t.Fatalf("DecodePath returned error: %v", err)
+	}
+	expected := user.Profile.Preferences.Privacy
+	if privacy.ProfileVisibility != expected.ProfileVisibility || privacy.ShowEmail != expected.ShowEmail ||
+		len(privacy.BlockedUsers) != len(expected.BlockedUsers) {
+		t.Errorf("privacy = %+v, expected %+v", privacy, expected)
+	}
+
+	var city string
+	if err := doc.DecodePath("Profile.Addresses.0.City", &city); err != nil {
+		t.Fatalf("DecodePath(array index) returned error: %v", err)
+	}
+	if city != user.Profile.Addresses[0].City {
+		t.Errorf("city = %q, expected %q", city, user.Profile.Addresses[0].City)
+	}
+
+	value, err := doc.Value("Profile.Preferences.Privacy.ShowEmail")
+	if err != nil || value != expected.ShowEmail {
+		t.Errorf("Value = %v, %v; expected %v", value, err, expected.ShowEmail)
+	}
+
+	raw, err := doc.Raw("")
+	if err != nil || raw != string(data) {
+		t.Errorf("Raw(\"\") should return the whole document, err %v", err)
+	}
+}
+
+func TestDocumentPathErrors(t *testing.T) {
+	doc, err := ParseDocument([]byte(`{"user_name":"ana","tags":["a"],"n":1}`))
+	if err != nil {
+		t.Fatalf("ParseDocument returned error: %v", err)
+	}
+
+	var name string
+	if err := doc.DecodePath("UserName", &name); err != nil || name != "ana" {
+		t.Errorf("snake_case segment: got %q, err %v", name, err)
+	}
+
+	for _, path := range []string{"missing", "tags.1", "tags.x", "n.deeper", "user_name.x"} {
+		if _, err := doc.Raw(path); err == nil || !Contains(err.Error(), string(ErrPathNotFound)) {
+			t.Errorf("Raw(%q) = %v, expected ErrPathNotFound", path, err)
+		}
+	}
+
+	if _, err := ParseDocument([]byte("  ")); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("ParseDocument(blank) = %v, expected ErrEmptyInput", err)
+	}
+
+	for _, path := range []string{"tags[", "tags[0", "["} {
+		if _, err := doc.Raw(path); err == nil || !Contains(err.Error(), string(errInvalidPath)) {
+			t.Errorf("Raw(%q) = %v, expected invalid path", path, err)
+		}
+	}
+}
+
+func TestJsonGet(t *testing.T) {
+	input := `{
+		"id": "u1",
+		"profile": {
+			"name": "Ana",
+			"addresses": [
+				{"city": "Lima", "zip": "15001"},
+				{"city": "Quito", "tags": ["home", "main"]}
+			]
+		},
+		"history": [` + "1,2,3" + `]
+	}`
+
+	var city string
+	if err := Convert(input).JsonGet("profile.addresses[1].city", &city); err != nil || city != "Quito" {
+		t.Errorf("JsonGet(city) = %q, %v", city, err)
+	}
+
+	var tag string
+	if err := Convert(input).JsonGet("profile.addresses[1].tags[0]", &tag); err != nil || tag != "home" {
+		t.Errorf("JsonGet(tag) = %q, %v", tag, err)
+	}
+
+	var addr struct{ City, Zip string }
+	if err := Convert(input).JsonGet("profile.addresses.0", &addr); err != nil || addr.Zip != "15001" {
+		t.Errorf("JsonGet(address) = %+v, %v", addr, err)
+	}
+
+	for _, path := range []string{"profile.missing", "profile.addresses[5]", "history[x]", "id.deeper"} {
+		var v any
+		if err := Convert(input).JsonGet(path, &v); err == nil || !Contains(err.Error(), string(ErrPathNotFound)) {
+			t.Errorf("JsonGet(%q) = %v, expected ErrPathNotFound", path, err)
+		}
+	}
+
+	// Members after the match are never read, earlier ones must still be well-formed
+	var id string
+	if err := Convert(`{"id":"u2","rest":[}`).JsonGet("id", &id); err != nil || id != "u2" {
+		t.Errorf("JsonGet before malformed member = %q, %v", id, err)
+	}
+	if err := Convert(`{"rest":[},"id":"u2"}`).JsonGet("id", &id); err == nil || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("JsonGet after malformed member = %v, expected syntax error", err)
+	}
+	if err := Convert("  ").JsonGet("id", &id); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("JsonGet(blank) = %v, expected ErrEmptyInput", err)
+	}
+
+	// An unclosed bracket is a malformed path, not an endless walk
+	for _, path := range []string{"profile.addresses[0", "profile.addresses[", "[", "history.[1"} {
+		var v any
+		if err := Convert(input).JsonGet(path, &v); err == nil || !Contains(err.Error(), string(errInvalidPath)) {
+			t.Errorf("JsonGet(%q) = %v, expected invalid path", path, err)
+		}
+	}
+}
+
+func TestDocumentIndex(t *testing.T) {
+	doc, err := ParseDocument([]byte(`{"a\u0062":{"list":[1,{"x":"y"}]},"n":2}`))
+	if err != nil {
+		t.Fatalf("ParseDocument returned error: %v", err)
+	}
+
+	for range 2 {
+		if raw, err := doc.Raw("ab.list[1].x"); err != nil || raw != `"y"` {
+			t.Errorf("Raw(escaped key) = %q, %v", raw, err)
+		}
+	}
+	// Root, "ab" and the list are indexed; "x" was only read
+	if len(doc.index) != 3 {
+		t.Errorf("index holds %d containers, expected 3", len(doc.index))
+	}
+	if _, err := doc.Raw("ab.list.2"); err == nil || !Contains(err.Error(), string(ErrPathNotFound)) {
+		t.Errorf("Raw(out of range) = %v, expected ErrPathNotFound", err)
+	}
+
+	var whole struct {
+		N int `json:"n"`
+	}
+	if err := doc.Decode(&whole); err != nil || whole.N != 2 {
+		t.Errorf("Decode = %+v, %v", whole, err)
+	}
+
+	bad, _ := ParseDocument([]byte(`{"a":1,"b":[}`))
+	if _, err := bad.Raw("a"); err == nil || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("Raw on malformed object = %v, expected syntax error", err)
+	}
+}
+
+func TestDocumentConcurrentLookups(t *testing.T) {
+	doc, err := ParseDocument([]byte(`{"items":[{"id":0},{"id":1},{"id":2},{"id":3}]}`))
+	if err != nil {
+		t.Fatalf("ParseDocument returned error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var id int
+			path := "items." + Convert(i%4).String() + ".id"
+			if err := doc.DecodePath(path, &id); err != nil || id != i%4 {
+				t.Errorf("DecodePath(%q) = %d, %v", path, id, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
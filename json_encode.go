@@ -1,569 +1,644 @@
-package tinywodp
-
-import (
-	. "github.com/cdvelop/tinystring"
-)
-
-// JSON encoding implementation for TinyString
-// Uses our custom reflectlite integration for minimal binary size
-
-// writer interface for JSON output - private interface compatible with io.Writer
-// This allows writing JSON directly to any output that implements Write method
-// without importing io package to maintain minimal binary size
-type writer interface {
-	Write(p []byte) (n int, err error)
-}
-
-// JsonEncode converts the current value to JSON format
-//
-// Usage patterns:
-//
-//	bytes, err := Convert(&user).JsonEncode()           // Returns JSON as []byte
-//	err := Convert(&user).JsonEncode(writer)           // Writes JSON to writer, returns nil bytes
-//	err := Convert(&user).JsonEncode(httpResponseWriter) // Direct HTTP response
-//	err := Convert(&user).JsonEncode(buffer)           // To buffer/file
-//
-// The method accepts optional writer implementing Write([]byte) (int, error):
-// - Without writer: Returns ([]byte, error) with JSON content
-// - With writer: Writes to writer and returns (nil, error)
-//
-// Supported types for JSON encoding:
-// - Basic types: string, int64, uint64, float64, bool
-// - Slices: []string, []int, []float64, []bool
-// - Structs: with basic field types and nested structs (max 8 levels)
-// - Struct slices: []User, []Address, etc.
-//
-// Field naming: Automatically converts to snake_case (UserName -> "user_name")
-// No JSON tags required - uses reflection for field inspection
-func (c *refValue) JsonEncode(w ...writer) ([]byte, error) {
-	// Check if writer is provided
-	if len(w) > 0 && w[0] != nil {
-		// Write to provided writer
-		jsonBytes, err := c.generateJsonBytes()
-		if err != nil {
-			return nil, err
-		}
-
-		_, writeErr := w[0].Write(jsonBytes)
-		return nil, writeErr
-	}
-
-	// No writer provided, return bytes directly
-	return c.generateJsonBytes()
-}
-
-// generateJsonBytes creates JSON representation of the current value
-func (c *refValue) generateJsonBytes() ([]byte, error) {
-	switch c.vTpe {
-	case tpString:
-		return c.encodeJsonString()
-	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
-		return c.encodeJsonInt()
-	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
-		return c.encodeJsonUint()
-	case tpFloat32, tpFloat64:
-		return c.encodeJsonFloat()
-	case tpBool:
-		return c.encodeJsonBool()
-	case tpStrSlice:
-		return c.encodeJsonStringSlice()
-	case tpStruct:
-		return c.encodeJsonStruct()
-	case tpSlice:
-		return c.encodeJsonSlice()
-	case tpPointer:
-		return c.encodeJsonPointer()
-	default:
-		return nil, Err(errUnsupportedType, "for JSON encoding")
-	}
-}
-
-// encodeJsonString encodes a string value to JSON
-func (c *refValue) encodeJsonString() ([]byte, error) {
-	str := c.getString()
-	return c.quoteJsonString(str), nil
-}
-
-// encodeJsonInt encodes an integer value to JSON
-func (c *refValue) encodeJsonInt() ([]byte, error) {
-	// Use existing tinystring int formatting
-	c.fmtInt(10)
-	return []byte(c.tmpStr), nil
-}
-
-// encodeJsonUint encodes an unsigned integer value to JSON
-func (c *refValue) encodeJsonUint() ([]byte, error) {
-	// Use existing tinystring uint formatting
-	c.fmtUint(10)
-	return []byte(c.tmpStr), nil
-}
-
-// encodeJsonFloat encodes a float value to JSON
-func (c *refValue) encodeJsonFloat() ([]byte, error) {
-	// Use existing tinystring float formatting
-	c.f2s()
-	return []byte(c.tmpStr), nil
-}
-
-// encodeJsonBool encodes a boolean value to JSON
-func (c *refValue) encodeJsonBool() ([]byte, error) {
-	if c.getBool() {
-		return []byte("true"), nil
-	}
-	return []byte("false"), nil
-}
-
-// encodeJsonStringSlice encodes a string slice to JSON
-func (c *refValue) encodeJsonStringSlice() ([]byte, error) {
-	if len(c.stringSliceVal) == 0 {
-		return []byte("[]"), nil
-	}
-
-	result := make([]byte, 0, len(c.stringSliceVal)*20) // Estimate capacity
-	result = append(result, '[')
-
-	for i, str := range c.stringSliceVal {
-		if i > 0 {
-			result = append(result, ',')
-		}
-		quoted := c.quoteJsonString(str)
-		result = append(result, quoted...)
-	}
-
-	result = append(result, ']')
-	return result, nil
-}
-
-// encodeJsonStruct encodes a struct to JSON using reflection
-func (c *refValue) encodeJsonStruct() ([]byte, error) {
-	if !c.refIsValid() {
-		return nil, Err(errInvalidJSON, "struct value is nil")
-	}
-
-	// Use our custom reflection to encode the struct directly
-	return c.encodeStructValueWithConvReflect()
-}
-
-// encodeJsonSlice encodes a slice to JSON using reflection
-func (c *refValue) encodeJsonSlice() ([]byte, error) {
-	if !c.refIsValid() {
-		return []byte("[]"), nil
-	}
-
-	if c.refKind() != tpSlice {
-		return []byte("[]"), nil
-	}
-
-	length := c.refLen()
-	if length == 0 {
-		return []byte("[]"), nil
-	}
-
-	result := make([]byte, 0, 256)
-	result = append(result, '[')
-
-	for i := range length {
-		if i > 0 {
-			result = append(result, ',')
-		}
-
-		// Get element at index i
-		elem := c.refIndex(i)
-		if !elem.refIsValid() {
-			result = append(result, []byte("null")...)
-			continue
-		}
-
-		// Encode the element recursively
-		var elemBytes []byte
-		var err error
-
-		switch elem.refKind() {
-		case tpString:
-			strVal := elem.refString()
-			elemBytes = c.quoteJsonString(strVal)
-		case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
-			intVal := elem.refInt()
-			tempConv := newConv(nil)
-			if tempConv.intToJsonString(intVal) {
-				elemBytes = []byte(tempConv.tmpStr)
-			} else {
-				elemBytes = []byte("0")
-			}
-		case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
-			uintVal := elem.refUint()
-			tempConv := newConv(nil)
-			if tempConv.uintToJsonString(uintVal) {
-				elemBytes = []byte(tempConv.tmpStr)
-			} else {
-				elemBytes = []byte("0")
-			}
-		case tpFloat32, tpFloat64:
-			floatVal := elem.refFloat()
-			tempConv := newConv(nil)
-			if tempConv.floatToJsonString(floatVal) {
-				elemBytes = []byte(tempConv.tmpStr)
-			} else {
-				elemBytes = []byte("0")
-			}
-		case tpBool:
-			boolVal := elem.refBool()
-			if boolVal {
-				elemBytes = []byte("true")
-			} else {
-				elemBytes = []byte("false")
-			}
-		case tpStruct:
-			// Handle struct elements recursively
-			elemBytes, err = elem.encodeStructValueWithConvReflect()
-			if err != nil {
-				elemBytes = []byte("{}")
-			}
-		case tpSlice:
-			// Handle nested slices recursively
-			elemBytes, err = elem.encodeJsonSlice()
-			if err != nil {
-				elemBytes = []byte("[]")
-			}
-		case tpPointer:
-			// Handle pointers by dereferencing
-			elemPtr := elem.refElem()
-			if !elemPtr.refIsValid() {
-				elemBytes = []byte("null")
-			} else {
-				// Recursively call slice encoding with the dereferenced element
-				switch elemPtr.refKind() {
-				case tpStruct:
-					elemBytes, err = elemPtr.encodeStructValueWithConvReflect()
-					if err != nil {
-						elemBytes = []byte("{}")
-					}
-				case tpSlice:
-					elemBytes, err = elemPtr.encodeJsonSlice()
-					if err != nil {
-						elemBytes = []byte("[]")
-					}
-				default:
-					// For basic types, encode directly
-					tempConv := newConv(nil)
-					if tempConv.encodeFieldValueToJson(elemPtr) {
-						elemBytes = []byte(tempConv.tmpStr)
-					} else {
-						elemBytes = []byte("null")
-					}
-				}
-			}
-		default:
-			elemBytes = []byte("null")
-		}
-
-		result = append(result, elemBytes...)
-	}
-
-	result = append(result, ']')
-	return result, nil
-}
-
-// encodeJsonPointer encodes a pointer value to JSON
-func (c *refValue) encodeJsonPointer() ([]byte, error) {
-	// Handle nil pointer
-	if c.ptr == nil {
-		return []byte("null"), nil // Case 1: ptr is nil
-	}
-
-	// The current refValue already represents the pointer, we need to get the element it points to
-	if c.refKind() != tpPointer {
-		return []byte("null"), nil // Case 2: not a pointer kind
-	}
-
-	// Get the element that the pointer points to using existing reflection
-	elem := c.refElem()
-	if !elem.refIsValid() {
-		return []byte("null"), nil // Case 3: element not valid
-	}
-
-	// Create a new refValue for the pointed-to value and encode it
-	elemValue := elem.Interface()
-	if elemValue == nil {
-		return []byte("null"), nil // Case 4: element interface is nil
-	}
-
-	elemConv := Convert(elemValue)
-	return elemConv.generateJsonBytes() // Case 5: should work
-}
-
-// quoteJsonString quotes a string for JSON output with proper escaping
-func (c *refValue) quoteJsonString(s string) []byte {
-	// Add safety check for string length
-	sLen := len(s)
-	if sLen < 0 || sLen > 1<<20 { // 1MB limit for safety
-		return []byte(`""`)
-	}
-
-	// Estimate capacity: original length + quotes + some escape characters
-	result := make([]byte, 0, sLen+16)
-	result = append(result, '"')
-
-	for _, r := range s {
-		switch r {
-		case '"':
-			result = append(result, '\\', '"')
-		case '\\':
-			result = append(result, '\\', '\\')
-		case '\b':
-			result = append(result, '\\', 'b')
-		case '\f':
-			result = append(result, '\\', 'f')
-		case '\n':
-			result = append(result, '\\', 'n')
-		case '\r':
-			result = append(result, '\\', 'r')
-		case '\t':
-			result = append(result, '\\', 't')
-		default:
-			if r < 32 {
-				// Control characters need unicode escaping
-				result = append(result, '\\', 'u', '0', '0')
-				if r < 16 {
-					result = append(result, '0')
-				} else {
-					result = append(result, '1')
-					r -= 16
-				}
-				if r < 10 {
-					result = append(result, byte('0'+r))
-				} else {
-					result = append(result, byte('a'+r-10))
-				}
-			} else {
-				// Add the rune as UTF-8
-				var buf [4]byte
-				n := len(string(r))
-				copy(buf[:], string(r))
-				result = append(result, buf[:n]...)
-			}
-		}
-	}
-
-	result = append(result, '"')
-	return result
-}
-
-// escapeAndQuoteJsonString escapes and quotes a string for JSON without heap allocation
-// Stores result directly in c.tmpStr
-func (c *refValue) escapeAndQuoteJsonString(s string) {
-	// Use fixed buffer to avoid heap allocation
-	var buf [512]byte // Fixed size buffer for most strings
-	idx := 0
-
-	// Add opening quote
-	if idx < len(buf) {
-		buf[idx] = '"'
-		idx++
-	}
-
-	// Escape and copy characters
-	for _, r := range s {
-		if idx >= len(buf)-6 { // Reserve space for closing quote and escape sequences
-			break
-		}
-
-		switch r {
-		case '"':
-			buf[idx] = '\\'
-			buf[idx+1] = '"'
-			idx += 2
-		case '\\':
-			buf[idx] = '\\'
-			buf[idx+1] = '\\'
-			idx += 2
-		case '\b':
-			buf[idx] = '\\'
-			buf[idx+1] = 'b'
-			idx += 2
-		case '\f':
-			buf[idx] = '\\'
-			buf[idx+1] = 'f'
-			idx += 2
-		case '\n':
-			buf[idx] = '\\'
-			buf[idx+1] = 'n'
-			idx += 2
-		case '\r':
-			buf[idx] = '\\'
-			buf[idx+1] = 'r'
-			idx += 2
-		case '\t':
-			buf[idx] = '\\'
-			buf[idx+1] = 't'
-			idx += 2
-		default:
-			if r < 32 {
-				// Control characters need unicode escaping \u00XX
-				buf[idx] = '\\'
-				buf[idx+1] = 'u'
-				buf[idx+2] = '0'
-				buf[idx+3] = '0'
-				if r < 16 {
-					buf[idx+4] = '0'
-				} else {
-					buf[idx+4] = '1'
-					r -= 16
-				}
-				if r < 10 {
-					buf[idx+5] = byte('0' + r)
-				} else {
-					buf[idx+5] = byte('A' + r - 10)
-				}
-				idx += 6
-			} else {
-				// Regular character - convert rune to UTF-8 bytes
-				if r < 128 {
-					buf[idx] = byte(r)
-					idx++
-				} else {
-					// For non-ASCII, simplified handling
-					buf[idx] = '?' // Placeholder for complex UTF-8
-					idx++
-				}
-			}
-		}
-	}
-
-	// Add closing quote
-	if idx < len(buf) {
-		buf[idx] = '"'
-		idx++
-	}
-
-	// Copy to tmpStr
-	c.tmpStr = string(buf[:idx])
-}
-
-// encodeStructValueWithConvReflect encodes a struct using refValue directly
-func (c *refValue) encodeStructValueWithConvReflect() ([]byte, error) {
-	// Handle pointer to struct
-	if c.refKind() == tpPointer {
-		elem := c.refElem()
-		if !elem.refIsValid() {
-			return []byte("null"), nil
-		}
-		c = elem
-	}
-
-	if c.refKind() != tpStruct {
-		return nil, Err(errUnsupportedType, "not a struct")
-	}
-
-	result := make([]byte, 0, 256)
-	result = append(result, '{')
-	fieldCount := 0
-	numFields := c.refNumField()
-
-	for i := range numFields {
-		field := c.refField(i)
-
-		// Skip invalid fields
-		if !field.refIsValid() {
-			continue
-		}
-
-		// Get field name from struct info - use original field name
-		var structInfo refStructType
-		getStructType(c.Type(), &structInfo)
-		if structInfo.refType == nil || i >= len(structInfo.fields) {
-			continue
-		}
-
-		jsonKey := structInfo.fields[i].name
-
-		// Add comma separator for subsequent fields
-		if fieldCount > 0 {
-			result = append(result, ',')
-		}
-
-		// Add field name as quoted JSON key
-		quotedKey := c.quoteJsonString(jsonKey)
-		result = append(result, quotedKey...)
-		result = append(result, ':') // Encode field value using our custom reflection
-		if !c.encodeFieldValueToJson(field) {
-			return nil, c
-		}
-		fieldJson := c.tmpStr
-		result = append(result, fieldJson...)
-		fieldCount++
-	}
-
-	result = append(result, '}')
-	return result, nil
-}
-
-// encodeFieldValueToJson encodes a field value to JSON without heap allocation
-// Stores result in c.tmpStr and returns success status
-func (c *refValue) encodeFieldValueToJson(fieldValue *refValue) bool {
-	if fieldValue == nil || !fieldValue.refIsValid() {
-		c.tmpStr = "null"
-		return true
-	}
-
-	switch fieldValue.refKind() {
-	case tpString:
-		strVal := fieldValue.refString() // Quote the string and store in tmpStr without heap allocation
-		c.escapeAndQuoteJsonString(strVal)
-		return true
-
-	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
-		intVal := fieldValue.refInt()
-		return c.intToJsonString(intVal)
-
-	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
-		uintVal := fieldValue.refUint()
-		return c.uintToJsonString(uintVal)
-
-	case tpFloat32, tpFloat64:
-		floatVal := fieldValue.refFloat()
-		return c.floatToJsonString(floatVal)
-
-	case tpBool:
-		boolVal := fieldValue.refBool()
-		if boolVal {
-			c.tmpStr = "true"
-		} else {
-			c.tmpStr = "false"
-		}
-		return true
-	case tpSlice:
-		// Handle slices recursively by using reflection
-		// Create temporary result and call existing slice encoding
-		tempResult, err := fieldValue.encodeJsonSlice()
-		if err != nil {
-			c.tmpStr = "[]"
-		} else {
-			c.tmpStr = string(tempResult)
-		}
-		return true
-
-	case tpStruct:
-		// Handle nested structs recursively
-		tempResult, err := fieldValue.encodeStructValueWithConvReflect()
-		if err != nil {
-			c.tmpStr = "{}"
-		} else {
-			c.tmpStr = string(tempResult)
-		}
-		return true
-
-	case tpPointer:
-		// Handle pointers by dereferencing
-		elem := fieldValue.refElem()
-		if !elem.refIsValid() {
-			c.tmpStr = "null"
-			return true
-		}
-		return c.encodeFieldValueToJson(elem)
-	default:
-		c.err = errUnsupportedType
-		c.tmpStr = "null"
-		return false
-	}
-}
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// JSON encoding implementation for TinyString
+// Uses our custom reflectlite integration for minimal binary size
+// All encoding goes through the pooled jsonH handler (see jsonH.go): the whole
+// encode tree of a value appends into its output buffer, so nesting levels never
+// build intermediate slices, and scalars are formatted in handler-owned scratch
+// space instead of on the shared refValue
+
+// writer interface for JSON output - private interface compatible with io.Writer
+// This allows writing JSON directly to any output that implements Write method
+// without importing io package to maintain minimal binary size
+type writer interface {
+	Write(p []byte) (n int, err error)
+}
+
+// JsonEncode converts the current value to JSON format
+//
+// Usage patterns:
+//
+//	bytes, err := Convert(&user).JsonEncode()           // Returns JSON as []byte
+//	err := Convert(&user).JsonEncode(writer)           // Writes JSON to writer, returns nil bytes
+//	err := Convert(&user).JsonEncode(httpResponseWriter) // Direct HTTP response
+//	err := Convert(&user).JsonEncode(buffer)           // To buffer/file
+//
+// The method accepts optional writer implementing Write([]byte) (int, error):
+// - Without writer: Returns ([]byte, error) with JSON content
+// - With writer: Writes to writer and returns (nil, error)
+//
+// Supported types for JSON encoding:
+// - Basic types: string, int64, uint64, float64, bool
+// - Slices: []string, []int, []float64, []bool
+// - Structs: with basic field types and nested structs (max 8 levels)
+// - Struct slices: []User, []Address, etc.
+// - Maps: string or integer keys, written in sorted key order
+//
+// Field naming: Go field names as declared by default; SetFieldNaming selects
+// snake_case ("user_name") or camelCase ("userName") keys (see json_naming.go)
+// No JSON tags required - uses reflection for field inspection
+func (c *refValue) JsonEncode(w ...writer) ([]byte, error) {
+	// Delegate to jsonH for thread-safe operation
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	return jh.encodeJsonTo(c, w, 0)
+}
+
+// encodeTo encodes c into jh.jOut with encode (JSON, MessagePack or CBOR) and writes
+// it to the first writer in w, or returns a copy of it when there is none
+func (jh *jsonH) encodeTo(c *refValue, w []writer, encode func([]byte, *refValue) ([]byte, error)) ([]byte, error) {
+	if err := checkEncodeGraph(c, jh.jMaxDepth); err != nil {
+		return nil, err
+	}
+	var err error
+	if jh.jOut, err = encode(jh.jOut, c); err != nil {
+		return nil, err
+	}
+
+	// Check if writer is provided
+	if len(w) > 0 && w[0] != nil {
+		// Write the pooled buffer directly, nothing is copied
+		_, writeErr := w[0].Write(jh.jOut)
+		return nil, writeErr
+	}
+
+	// No writer provided, the caller gets its own copy of the buffer
+	result := make([]byte, len(jh.jOut))
+	copy(result, jh.jOut)
+	return result, nil
+}
+
+// JsonEncodeIndent converts the current value to human-readable JSON
+//
+// Usage patterns:
+//
+//	bytes, err := Convert(&user).JsonEncodeIndent("", "  ")         // Two-space indentation
+//	_, err := Convert(&user).JsonEncodeIndent("", "\t", os.Stdout) // Writes to writer
+//
+// Each element begins on a new line starting with prefix followed by one copy
+// of indent per nesting level; empty objects and arrays stay on one line
+func (c *refValue) JsonEncodeIndent(prefix, indent string, w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	if err := checkEncodeGraph(c, jh.jMaxDepth); err != nil {
+		return nil, err
+	}
+	var err error
+	if jh.jOut, err = jh.appendJson(jh.jOut, c); err != nil {
+		return nil, err
+	}
+	result := appendIndentJson(make([]byte, 0, len(jh.jOut)*2), jh.jOut, prefix, indent)
+
+	if len(w) > 0 && w[0] != nil {
+		_, writeErr := w[0].Write(result)
+		return nil, writeErr
+	}
+	return result, nil
+}
+
+// ============================================================================
+// JSON ENCODE OPERATIONS - Thread-safe implementations for jsonH
+// ============================================================================
+
+// appendJson appends the JSON representation of c to dst
+// On error the partially extended dst is returned with it
+func (jh *jsonH) appendJson(dst []byte, c *refValue) ([]byte, error) {
+	if out, ok, err := encodeCustomJson(c); ok {
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, out...), nil
+	}
+	if stdEncodeHook != nil {
+		if out, ok, err := stdEncodeHook(c); ok {
+			if err != nil {
+				return dst, err
+			}
+			return append(dst, out...), nil
+		}
+	}
+
+	switch c.vTpe {
+	case tpString:
+		return jh.quoteJsonString(dst, c.getString()), nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64,
+		tpUint, tpUint8, tpUint16, tpUint32, tpUint64,
+		tpFloat32, tpFloat64:
+		// A top-level number formats itself: c was created for this call
+		// and is not shared, unlike the values reached through reflection
+		return jh.appendJsonNumber(dst, c)
+	case tpBool:
+		if c.getBool() {
+			return append(dst, "true"...), nil
+		}
+		return append(dst, "false"...), nil
+	case tpStrSlice:
+		return jh.appendJsonStringSlice(dst, c), nil
+	case tpStruct:
+		if !c.refIsValid() {
+			return dst, Err(errInvalidJSON, "struct value is nil")
+		}
+		return jh.appendJsonStruct(dst, c)
+	case tpSlice, tpArray:
+		return jh.appendJsonSlice(dst, c)
+	case tpPointer:
+		return jh.appendJsonPointer(dst, c)
+	case tpMap:
+		return jh.appendJsonMap(dst, c)
+	default:
+		return dst, unsupportedKindError("for JSON encoding", c.refKind())
+	}
+}
+
+// appendJsonNumber appends the number held directly by a top-level value
+// Integers use existing tinystring formatting; floats use the shortest
+// round-trip digits like float fields
+func (jh *jsonH) appendJsonNumber(dst []byte, c *refValue) ([]byte, error) {
+	switch c.vTpe {
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		c.fmtInt(10)
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		c.fmtUint(10)
+	default:
+		return jh.appendJsonFloatValue(dst, c.refFloat(), floatBitSize(c))
+	}
+	return append(dst, c.tmpStr...), nil
+}
+
+// appendJsonStringSlice appends a string slice
+func (jh *jsonH) appendJsonStringSlice(dst []byte, c *refValue) []byte {
+	if len(c.stringSliceVal) == 0 {
+		if jh.jNilSlice == NilSliceAsNull && c.stringSliceVal == nil {
+			return append(dst, "null"...)
+		}
+		return append(dst, '[', ']')
+	}
+
+	dst = append(dst, '[')
+	for i, str := range c.stringSliceVal {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = jh.quoteJsonString(dst, str)
+	}
+	return append(dst, ']')
+}
+
+// appendJsonSlice appends a slice or fixed-size array using reflection
+// Arrays are always emitted element by element, [16]byte included
+func (jh *jsonH) appendJsonSlice(dst []byte, c *refValue) ([]byte, error) {
+	if !c.refIsValid() {
+		return append(dst, '[', ']'), nil
+	}
+
+	if kind := c.refKind(); kind != tpSlice && kind != tpArray {
+		return append(dst, '[', ']'), nil
+	}
+	if jh.encodesAsBase64(c) {
+		return jh.appendJsonBytes(dst, c), nil
+	}
+
+	length := c.refLen()
+	if length == 0 {
+		return append(dst, jh.emptySliceJson(c)...), nil
+	}
+
+	dst = append(dst, '[')
+
+	mark := len(jh.jPath)
+	for i := range length {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		if len(encodeHooks) > 0 {
+			jh.pushPathIndex(mark, i)
+		}
+
+		// Get element at index i
+		elem := c.refIndex(i)
+		if !elem.refIsValid() {
+			dst = append(dst, "null"...)
+			continue
+		}
+
+		if out, ok, customErr := encodeCustomJson(elem); ok {
+			if customErr != nil {
+				return dst, customErr
+			}
+			dst = append(dst, out...)
+			continue
+		}
+		if stdEncodeHook != nil {
+			if out, ok, hookErr := stdEncodeHook(elem); ok {
+				if hookErr != nil {
+					return dst, hookErr
+				}
+				dst = append(dst, out...)
+				continue
+			}
+		}
+
+		var err error
+		switch elem.refKind() {
+		case tpString:
+			strVal := elem.refString()
+			if isBigNumberType(elem) {
+				dst = append(dst, bigNumberLiteral(strVal)...)
+			} else {
+				// Same quoting as string fields, so no length limit applies
+				dst = jh.quoteJsonString(dst, strVal)
+			}
+		case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+			if jh.jConv.intToJsonString(elem.refInt()) {
+				dst = append(dst, jh.jConv.tmpStr...)
+			} else {
+				dst = append(dst, '0')
+			}
+		case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+			if jh.jConv.uintToJsonString(elem.refUint()) {
+				dst = append(dst, jh.jConv.tmpStr...)
+			} else {
+				dst = append(dst, '0')
+			}
+		case tpFloat32, tpFloat64:
+			dst, err = jh.appendJsonFloatValue(dst, elem.refFloat(), floatBitSize(elem))
+		case tpBool:
+			if elem.refBool() {
+				dst = append(dst, "true"...)
+			} else {
+				dst = append(dst, "false"...)
+			}
+		case tpStruct:
+			// Handle struct elements recursively
+			dst, err = jh.appendJsonStruct(dst, elem)
+		case tpSlice, tpArray:
+			// Handle nested slices and arrays recursively
+			dst, err = jh.appendJsonSlice(dst, elem)
+		case tpMap:
+			dst, err = jh.appendJsonMap(dst, elem)
+		case tpPointer:
+			// Handle pointers by dereferencing, through any depth of indirection
+			elemPtr := derefJsonPointer(elem)
+			if elemPtr == nil {
+				dst = append(dst, "null"...)
+				break
+			}
+			switch elemPtr.refKind() {
+			case tpStruct:
+				dst, err = jh.appendJsonStruct(dst, elemPtr)
+			case tpSlice, tpArray:
+				dst, err = jh.appendJsonSlice(dst, elemPtr)
+			default:
+				// For basic types, encode directly
+				dst, err = jh.appendJsonFieldValue(dst, elemPtr)
+			}
+		case tpInterface:
+			// Elements of []any are encoded by their concrete value
+			dst, err = jh.appendJsonFieldValue(dst, elem)
+		default:
+			err = unsupportedKindError("for JSON encoding", elem.refKind())
+		}
+		if err != nil {
+			return dst, prefixKindPath(err, indexSegment(i))
+		}
+	}
+
+	jh.jPath = jh.jPath[:mark]
+	return append(dst, ']'), nil
+}
+
+// appendJsonPointer appends a pointer value
+func (jh *jsonH) appendJsonPointer(dst []byte, c *refValue) ([]byte, error) {
+	// Handle nil pointer
+	if c.ptr == nil {
+		return append(dst, "null"...), nil // Case 1: ptr is nil
+	}
+
+	// The current refValue already represents the pointer, we need to get the element it points to
+	if c.refKind() != tpPointer {
+		return append(dst, "null"...), nil // Case 2: not a pointer kind
+	}
+
+	// Get the value at the end of the pointer chain (*T, **T, ...)
+	elem := derefJsonPointer(c)
+	if elem == nil {
+		return append(dst, "null"...), nil // Case 3: a pointer in the chain is nil
+	}
+
+	// Create a new refValue for the pointed-to value and encode it
+	elemValue := elem.Interface()
+	if elemValue == nil {
+		return append(dst, "null"...), nil // Case 4: element interface is nil
+	}
+
+	return jh.appendJson(dst, Convert(elemValue)) // Case 5: should work
+}
+
+// derefJsonPointer follows the pointers from v to the value they lead to, so
+// **T and *[]*T encode like T and []*T; nil when a pointer on the way is nil
+func derefJsonPointer(v *refValue) *refValue {
+	for v.refKind() == tpPointer {
+		if v = v.refElem(); !v.refIsValid() {
+			return nil
+		}
+	}
+	return v
+}
+
+// appendQuoteJsonString appends s quoted for JSON output with proper escaping
+func appendQuoteJsonString(result []byte, s string) []byte {
+	// Add safety check for string length
+	sLen := len(s)
+	if sLen < 0 || sLen > 1<<20 { // 1MB limit for safety
+		return append(result, '"', '"')
+	}
+
+	result = append(result, '"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			result = append(result, '\\', '"')
+		case '\\':
+			result = append(result, '\\', '\\')
+		case '\b':
+			result = append(result, '\\', 'b')
+		case '\f':
+			result = append(result, '\\', 'f')
+		case '\n':
+			result = append(result, '\\', 'n')
+		case '\r':
+			result = append(result, '\\', 'r')
+		case '\t':
+			result = append(result, '\\', 't')
+		default:
+			if r < 32 {
+				// Control characters need unicode escaping
+				result = append(result, '\\', 'u', '0', '0')
+				if r < 16 {
+					result = append(result, '0')
+				} else {
+					result = append(result, '1')
+					r -= 16
+				}
+				if r < 10 {
+					result = append(result, byte('0'+r))
+				} else {
+					result = append(result, byte('a'+r-10))
+				}
+			} else {
+				// Multi-byte runes are written whole; invalid bytes become U+FFFD
+				result = appendJsonRune(result, r, false)
+			}
+		}
+	}
+
+	result = append(result, '"')
+	return result
+}
+
+// escapeAndQuoteJsonString appends s escaped and quoted for JSON to dst, with
+// non-ASCII runes as \uXXXX escapes when ascii is set
+// dst is normally the pooled output buffer of the encode, which grows as
+// needed, so strings of any length are written whole
+func escapeAndQuoteJsonString(dst []byte, s string, ascii bool) []byte {
+	return appendQuotedJson(dst, s, ascii, false)
+}
+
+// quoteJsonString appends s as a JSON string under the operation's escaping
+// settings (SetEscapeNonASCII, SetEscapeHTML)
+func (jh *jsonH) quoteJsonString(dst []byte, s string) []byte {
+	return appendQuotedJson(dst, s, jh.jASCII, jh.jHTML)
+}
+
+// appendQuotedJson is escapeAndQuoteJsonString that also writes <, >, &,
+// U+2028 and U+2029 as \uXXXX escapes when html is set
+func appendQuotedJson(dst []byte, s string, ascii, html bool) []byte {
+	dst = append(dst, '"')
+	for _, r := range s {
+		switch r {
+		case '<', '>', '&', '\u2028', '\u2029':
+			if html {
+				dst = appendHex4Escape(dst, r)
+			} else {
+				dst = appendJsonRune(dst, r, ascii)
+			}
+		case '"':
+			dst = append(dst, '\\', '"')
+		case '\\':
+			dst = append(dst, '\\', '\\')
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if r < 32 {
+				// Control characters need unicode escaping \u00XX
+				dst = append(dst, '\\', 'u', '0', '0')
+				if r < 16 {
+					dst = append(dst, '0')
+				} else {
+					dst = append(dst, '1')
+					r -= 16
+				}
+				if r < 10 {
+					dst = append(dst, byte('0'+r))
+				} else {
+					dst = append(dst, byte('a'+r-10))
+				}
+			} else {
+				// Regular character - write rune as UTF-8 bytes
+				dst = appendJsonRune(dst, r, ascii)
+			}
+		}
+	}
+	return append(dst, '"')
+}
+
+// appendJsonStruct appends a struct using refValue directly
+func (jh *jsonH) appendJsonStruct(dst []byte, c *refValue) ([]byte, error) {
+	// Handle pointer to struct, at any depth
+	if c.refKind() == tpPointer {
+		elem := derefJsonPointer(c)
+		if elem == nil {
+			return append(dst, "null"...), nil
+		}
+		c = elem
+	}
+
+	if c.refKind() != tpStruct {
+		return dst, Err(errUnsupportedType, "not a struct")
+	}
+
+	// Field names and tag directives - fail early if metadata was stripped
+	plan, err := decodePlanFor(c)
+	if err != nil {
+		return dst, err
+	}
+
+	// Quoted keys in the naming convention, computed once per struct type
+	keys := jsonFieldKeys(c, plan, jh.jNaming)
+	order := jh.jsonFieldOrder(c, plan)
+
+	dst = append(dst, '{')
+	fieldCount := 0
+	numFields := c.refNumField()
+	mark := len(jh.jPath)
+
+	for n := range numFields {
+		i := n
+		if order != nil {
+			i = order[n]
+		}
+		field := c.refField(i)
+
+		// Skip invalid and unexported fields, and those tagged omitempty or omitzero
+		// that hold nothing
+		if !field.refIsValid() || plan.fields[i].omitted(field) {
+			continue
+		}
+
+		// Add comma separator for subsequent fields
+		if fieldCount > 0 {
+			dst = append(dst, ',')
+		}
+
+		// Add field name as quoted JSON key
+		dst = append(dst, keys[i]...)
+		dst = append(dst, ':')
+
+		// Redacted fields and encode hooks replace the value
+		if len(encodeHooks) > 0 {
+			jh.pushPathField(mark, plan.fields[i].name)
+		}
+		if out, hooked, err := jh.encodeFieldHook(&plan.fields[i], field); hooked {
+			if err != nil {
+				return dst, err
+			}
+			dst = append(dst, out...)
+			fieldCount++
+			continue
+		}
+
+		// Encode field value using our custom reflection
+		start := len(dst)
+		if plan.fields[i].quoted {
+			dst, err = jh.appendJsonQuotedValue(dst, field)
+		} else {
+			dst, err = jh.appendJsonFieldValue(dst, field)
+		}
+		if err != nil {
+			return dst, prefixKindPath(err, plan.fields[i].name)
+		}
+		if plan.fields[i].secure {
+			sealed, err := sealJsonField(string(dst[start:]))
+			if err != nil {
+				return dst, err
+			}
+			dst = append(dst[:start], sealed...)
+		}
+		fieldCount++
+	}
+
+	jh.jPath = jh.jPath[:mark]
+	return append(dst, '}'), nil
+}
+
+// appendJsonFieldValue appends a field value to dst
+// Scalars are formatted in jh.jConv, never in the refValue being encoded
+func (jh *jsonH) appendJsonFieldValue(dst []byte, fieldValue *refValue) ([]byte, error) {
+	if fieldValue == nil || !fieldValue.refIsValid() {
+		return append(dst, "null"...), nil
+	}
+
+	if out, ok, err := encodeCustomJson(fieldValue); ok {
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, out...), nil
+	}
+	if stdEncodeHook != nil {
+		if out, ok, err := stdEncodeHook(fieldValue); ok {
+			if err != nil {
+				return dst, err
+			}
+			return append(dst, out...), nil
+		}
+	}
+
+	switch fieldValue.refKind() {
+	case tpString:
+		strVal := fieldValue.refString()
+		if isBigNumberType(fieldValue) {
+			return append(dst, bigNumberLiteral(strVal)...), nil // Emitted unquoted, never rounded
+		}
+		// Quote the string without heap allocation
+		return jh.quoteJsonString(dst, strVal), nil
+
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		if !jh.jConv.intToJsonString(fieldValue.refInt()) {
+			return dst, jh.jConv
+		}
+		return append(dst, jh.jConv.tmpStr...), nil
+
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		if !jh.jConv.uintToJsonString(fieldValue.refUint()) {
+			return dst, jh.jConv
+		}
+		return append(dst, jh.jConv.tmpStr...), nil
+
+	case tpFloat32, tpFloat64:
+		return jh.appendJsonFloatValue(dst, fieldValue.refFloat(), floatBitSize(fieldValue))
+
+	case tpBool:
+		if fieldValue.refBool() {
+			return append(dst, "true"...), nil
+		}
+		return append(dst, "false"...), nil
+
+	case tpSlice, tpArray:
+		// Handle slices and arrays recursively, straight into dst;
+		// []string fields take this path at any depth
+		return jh.appendJsonSlice(dst, fieldValue)
+
+	case tpStrSlice:
+		// String slices held by a converted value rather than reached
+		// through reflection
+		return jh.appendJsonStringSlice(dst, fieldValue), nil
+
+	case tpStruct:
+		// Handle nested structs recursively
+		return jh.appendJsonStruct(dst, fieldValue)
+
+	case tpMap:
+		// Objects keyed by string or integer, keys sorted
+		return jh.appendJsonMap(dst, fieldValue)
+
+	case tpPointer:
+		// Handle pointers by dereferencing the whole chain at once
+		elem := derefJsonPointer(fieldValue)
+		if elem == nil {
+			return append(dst, "null"...), nil
+		}
+		return jh.appendJsonFieldValue(dst, elem)
+
+	case tpInterface:
+		// Dispatch on the concrete value held by any/interface{} fields
+		inner := fieldValue.refInterfaceValue()
+		if inner == nil {
+			return append(dst, "null"...), nil
+		}
+		return jh.appendJsonFieldValue(dst, refValueOf(inner))
+	default:
+		return dst, unsupportedKindError("for JSON encoding", fieldValue.refKind())
+	}
+}
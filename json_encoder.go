@@ -0,0 +1,255 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Streaming JSON encoder
+// Writes struct fields and slice elements through the writer as they are encoded,
+// so large exports never hold the whole document in memory at once
+
+// encoderFlushSize is the buffered size that triggers a write to the underlying writer
+const encoderFlushSize = 4096
+
+// JsonEncoder writes JSON values to an output stream
+//
+// Usage patterns:
+//
+//	enc := NewJsonEncoder(httpResponseWriter)
+//	err := enc.Encode(users) // []User streamed element by element
+//
+// Each call to Encode writes one JSON value followed by a newline,
+// matching the behavior of encoding/json.Encoder
+type JsonEncoder struct {
+	w    writer
+	buf  []byte // reusable output buffer, flushed every encoderFlushSize bytes
+	jh   *jsonH // pooled handler of the Encode call in progress
+	err  error  // sticky write error
+	hold int    // secure values being encoded; flushing waits until they are sealed
+
+	html, htmlSet bool // SetEscapeHTML, when called
+}
+
+// NewJsonEncoder returns a new encoder that writes to w
+func NewJsonEncoder(w writer) *JsonEncoder {
+	return &JsonEncoder{
+		w:   w,
+		buf: allocBytes(encoderFlushSize + 256),
+	}
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline
+// Structs and slices are written incrementally; other values in a single write
+func (e *JsonEncoder) Encode(v any) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.w == nil {
+		return Err(errInvalidJSON, "encoder writer cannot be nil")
+	}
+
+	// Scalars are formatted in a pooled handler, never in shared values
+	e.jh = getJsonH("_")
+	defer func() {
+		putJsonH(e.jh)
+		e.jh = nil
+	}()
+	if e.htmlSet {
+		e.jh.jHTML = e.html
+	}
+
+	e.buf = e.buf[:0]
+	if err := e.encodeAny(v); err != nil {
+		return err
+	}
+
+	e.buf = append(e.buf, '\n')
+	return e.flush()
+}
+
+// encodeAny appends the JSON encoding of an arbitrary value to the buffer
+func (e *JsonEncoder) encodeAny(v any) error {
+	c := Convert(v)
+	if err := checkEncodeGraph(c, e.jh.jMaxDepth); err != nil {
+		return err
+	}
+	switch c.vTpe {
+	case tpStruct:
+		return e.encodeStruct(c)
+	case tpSlice, tpArray:
+		return e.encodeSlice(c)
+	default:
+		var err error
+		e.buf, err = e.jh.appendJson(e.buf, c)
+		return err
+	}
+}
+
+// encodeSlice writes a slice or array element by element
+func (e *JsonEncoder) encodeSlice(c *refValue) error {
+	if !c.refIsValid() || (c.refKind() != tpSlice && c.refKind() != tpArray) {
+		e.buf = append(e.buf, '[', ']')
+		return nil
+	}
+	if e.jh.encodesAsBase64(c) {
+		e.buf = e.jh.appendJsonBytes(e.buf, c)
+		return nil
+	}
+
+	e.buf = append(e.buf, '[')
+	length := c.refLen()
+	mark := len(e.jh.jPath)
+	for i := range length {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if len(encodeHooks) > 0 {
+			e.jh.pushPathIndex(mark, i)
+		}
+		if err := e.encodeValue(c.refIndex(i)); err != nil {
+			return prefixKindPath(err, indexSegment(i))
+		}
+		if err := e.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	e.jh.jPath = e.jh.jPath[:mark]
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+// encodeStruct writes a struct field by field
+func (e *JsonEncoder) encodeStruct(c *refValue) error {
+	plan, err := decodePlanFor(c)
+	if err != nil {
+		return err
+	}
+
+	keys := jsonFieldKeys(c, plan, e.jh.jNaming)
+	order := e.jh.jsonFieldOrder(c, plan)
+
+	e.buf = append(e.buf, '{')
+	fieldCount := 0
+	mark := len(e.jh.jPath)
+	for n := range c.refNumField() {
+		i := n
+		if order != nil {
+			i = order[n]
+		}
+		field := c.refField(i)
+		if !field.refIsValid() || plan.fields[i].omitted(field) {
+			continue
+		}
+
+		if fieldCount > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = append(e.buf, keys[i]...)
+		e.buf = append(e.buf, ':')
+		if len(encodeHooks) > 0 {
+			e.jh.pushPathField(mark, plan.fields[i].name)
+		}
+		if out, hooked, err := e.jh.encodeFieldHook(&plan.fields[i], field); hooked {
+			if err != nil {
+				return err
+			}
+			e.buf = append(e.buf, out...)
+		} else if plan.fields[i].secure {
+			if err := e.encodeSealed(field); err != nil {
+				return err
+			}
+		} else if plan.fields[i].quoted {
+			if e.buf, err = e.jh.appendJsonQuotedValue(e.buf, field); err != nil {
+				return err
+			}
+		} else if err := e.encodeValue(field); err != nil {
+			return prefixKindPath(err, plan.fields[i].name)
+		}
+		fieldCount++
+
+		if err := e.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	e.jh.jPath = e.jh.jPath[:mark]
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+// encodeValue appends a single element or field value to the buffer
+func (e *JsonEncoder) encodeValue(v *refValue) error {
+	if v == nil || !v.refIsValid() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+
+	if out, ok, err := encodeCustomJson(v); ok {
+		if err != nil {
+			return err
+		}
+		e.buf = append(e.buf, out...)
+		return nil
+	}
+	if stdEncodeHook != nil {
+		if out, ok, err := stdEncodeHook(v); ok {
+			if err != nil {
+				return err
+			}
+			e.buf = append(e.buf, out...)
+			return nil
+		}
+	}
+
+	switch v.refKind() {
+	case tpStruct:
+		return e.encodeStruct(v)
+	case tpSlice, tpArray:
+		return e.encodeSlice(v)
+	}
+
+	var err error
+	e.buf, err = e.jh.appendJsonFieldValue(e.buf, v)
+	return err
+}
+
+// encodeSealed appends an encrypted field: the value is encoded on its own,
+// sealed with the registered Cipher and written as a base64 string
+// Flushing is held while the plaintext is in the buffer, so none of it reaches
+// the writer and e.buf[start:] still holds the whole value when it is sealed
+func (e *JsonEncoder) encodeSealed(v *refValue) error {
+	start := len(e.buf)
+	e.hold++
+	err := e.encodeValue(v)
+	e.hold--
+	if err != nil {
+		return err
+	}
+	sealed, err := sealJsonField(string(e.buf[start:]))
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf[:start], sealed...)
+	return nil
+}
+
+// maybeFlush writes the buffer once it grows past encoderFlushSize, unless a
+// secure value is being encoded
+func (e *JsonEncoder) maybeFlush() error {
+	if e.hold > 0 || len(e.buf) < encoderFlushSize {
+		return nil
+	}
+	return e.flush()
+}
+
+// flush writes the buffered bytes and resets the buffer keeping its capacity
+func (e *JsonEncoder) flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	if _, err := e.w.Write(e.buf); err != nil {
+		e.err = err
+		return err
+	}
+	e.buf = e.buf[:0]
+	return nil
+}
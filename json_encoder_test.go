@@ -0,0 +1,105 @@
+package tinywodp
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonEncoderMatchesJsonEncode(t *testing.T) {
+	clearRefStructsCache()
+
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{"string", "hello"},
+		{"int", 42},
+		{"struct", GenerateSimplePersonData()},
+		{"struct slice", GenerateSimplePersonArray(3)},
+		{"string slice", []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := Convert(tt.input).JsonEncode()
+			if err != nil {
+				t.Fatalf("JsonEncode returned error: %v", err)
+			}
+
+			var captured []byte
+			enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+				captured = append(captured, p...)
+				return len(p), nil
+			}})
+			if err := enc.Encode(tt.input); err != nil {
+				t.Fatalf("Encode returned error: %v", err)
+			}
+
+			if string(captured) != string(expected)+"\n" {
+				t.Errorf("Encode wrote %s, expected %s", string(captured), string(expected))
+			}
+		})
+	}
+}
+
+func TestJsonEncoderStreamsLargeSlice(t *testing.T) {
+	clearRefStructsCache()
+	persons := GenerateSimplePersonArray(10000)
+
+	writes := 0
+	maxChunk := 0
+	total := 0
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		writes++
+		total += len(p)
+		if len(p) > maxChunk {
+			maxChunk = len(p)
+		}
+		return len(p), nil
+	}})
+
+	if err := enc.Encode(persons); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	if writes < 2 {
+		t.Errorf("expected output to be written in chunks, got %d write(s)", writes)
+	}
+	if maxChunk > encoderFlushSize*2 {
+		t.Errorf("chunk of %d bytes exceeds expected buffer bound", maxChunk)
+	}
+	t.Logf("wrote %d bytes in %d chunks (max %d)", total, writes, maxChunk)
+}
+
+func TestJsonEncoderStickyWriteError(t *testing.T) {
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		return 0, Err("disk full")
+	}})
+
+	if err := enc.Encode("a"); err == nil {
+		t.Fatal("Encode should return the writer error")
+	}
+	if err := enc.Encode("b"); err == nil {
+		t.Error("Encode should keep returning the first write error")
+	}
+}
+
+func TestJsonEncoderReturnsValueErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	type Row struct {
+		B stdBroken
+	}
+
+	// Errors from nested values reach the caller as they are
+	discard := &testWriter{writeFunc: func(p []byte) (int, error) { return len(p), nil }}
+	if err := NewJsonEncoder(discard).Encode([]Row{{B: `{"a":}`}}); err == nil {
+		t.Error("Encode should return the MarshalJSON output error")
+	}
+	err := NewJsonEncoder(discard).Encode([]any{"a", math.NaN()})
+	if err == nil || !Contains(err.Error(), string(errUnsupportedValue)) {
+		t.Errorf("Encode(NaN) = %v, expected an unsupported value error", err)
+	}
+}
@@ -0,0 +1,599 @@
+package tinywodp
+
+import (
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Float formatting and parsing
+// Floats are written with the shortest digits that parse back to the exact
+// same value, so float64 (and float32) fields round-trip bit for bit, and are
+// parsed with correct rounding. A multi-precision decimal does the exact
+// arithmetic, as strconv does on its slow path, without importing strconv.
+// JSON has no NaN or Infinity; NonFiniteEncoding decides what happens to them.
+
+// NonFiniteEncoding controls how NaN and ±Inf floats are encoded
+type NonFiniteEncoding uint8
+
+const (
+	// NonFiniteAsError fails the encode with an unsupported value error, like encoding/json (default)
+	NonFiniteAsError NonFiniteEncoding = iota
+	// NonFiniteAsNull writes null in their place
+	NonFiniteAsNull
+)
+
+// nonFiniteEncoding is the active NaN/Inf handling
+var nonFiniteEncoding = NonFiniteAsError
+
+// SetNonFiniteEncoding changes how NaN and infinite floats are encoded
+// Not safe to call concurrently with encoding
+func SetNonFiniteEncoding(e NonFiniteEncoding) {
+	nonFiniteEncoding = e
+}
+
+// appendJsonFloatValue appends f following the NaN/Inf policy
+// bitSize 32 formats the shortest digits for a float32 field
+func (jh *jsonH) appendJsonFloatValue(dst []byte, f float64, bitSize int) ([]byte, error) {
+	if f-f != 0 { // NaN and ±Inf
+		if jh.jNonFinite == NonFiniteAsNull {
+			return append(dst, "null"...), nil
+		}
+		return dst, Err(errUnsupportedValue, nonFiniteName(f))
+	}
+	return appendJsonFloat(dst, f, bitSize), nil
+}
+
+// nonFiniteName names a NaN or infinite value for error messages
+func nonFiniteName(f float64) string {
+	switch {
+	case f != f:
+		return "NaN"
+	case f > 0:
+		return "+Inf"
+	}
+	return "-Inf"
+}
+
+// floatBitSize returns 32 for float32 values and 64 otherwise
+func floatBitSize(v *refValue) int {
+	if v.refKind() == tpFloat32 {
+		return 32
+	}
+	return 64
+}
+
+// jsonFloatInfo describes the IEEE 754 layout of float32 and float64
+type jsonFloatInfo struct {
+	mantbits uint
+	expbits  uint
+	bias     int
+}
+
+var (
+	jsonFloat32Info = jsonFloatInfo{23, 8, -127}
+	jsonFloat64Info = jsonFloatInfo{52, 11, -1023}
+)
+
+// jsonDecimal is a multi-precision decimal holding the exact value of a float
+// or of a number literal
+type jsonDecimal struct {
+	d     [800]byte // digits, most significant first
+	nd    int       // number of digits used
+	dp    int       // decimal point position
+	neg   bool      // negative flag
+	trunc bool      // nonzero digits were discarded beyond d[:nd]
+}
+
+// jsonMaxShift is the largest bit shift applied in one step without
+// overflowing a uint accumulator
+const jsonMaxShift = 32<<(^uint(0)>>63) - 4
+
+// jsonPowersOfFive holds 5^k in decimal for every shift, used to predict
+// how many digits a left shift adds
+var jsonPowersOfFive = func() (p [jsonMaxShift + 1]string) {
+	digits := []byte{'1'}
+	for k := range p {
+		p[k] = string(digits)
+		carry := byte(0)
+		for i := len(digits) - 1; i >= 0; i-- {
+			v := (digits[i]-'0')*5 + carry
+			digits[i] = v%10 + '0'
+			carry = v / 10
+		}
+		if carry > 0 {
+			digits = append([]byte{carry + '0'}, digits...)
+		}
+	}
+	return p
+}()
+
+func (a *jsonDecimal) assign(v uint64) {
+	var buf [24]byte
+	n := 0
+	for v > 0 {
+		v1 := v / 10
+		buf[n] = byte(v-10*v1) + '0'
+		n++
+		v = v1
+	}
+	a.nd = 0
+	for n--; n >= 0; n-- {
+		a.d[a.nd] = buf[n]
+		a.nd++
+	}
+	a.dp = a.nd
+	a.trim()
+}
+
+// trim drops trailing zeros
+func (a *jsonDecimal) trim() {
+	for a.nd > 0 && a.d[a.nd-1] == '0' {
+		a.nd--
+	}
+	if a.nd == 0 {
+		a.dp = 0
+	}
+}
+
+// rightShift divides by 2^k
+func (a *jsonDecimal) rightShift(k uint) {
+	r, w := 0, 0
+	var n uint
+	for ; n>>k == 0; r++ {
+		if r >= a.nd {
+			if n == 0 {
+				a.nd = 0
+				return
+			}
+			for n>>k == 0 {
+				n *= 10
+				r++
+			}
+			break
+		}
+		n = n*10 + uint(a.d[r]) - '0'
+	}
+	a.dp -= r - 1
+	mask := uint(1)<<k - 1
+	for ; r < a.nd; r++ {
+		c := uint(a.d[r])
+		dig := n >> k
+		n &= mask
+		a.d[w] = byte(dig) + '0'
+		w++
+		n = n*10 + c - '0'
+	}
+	for n > 0 {
+		dig := n >> k
+		n &= mask
+		if w < len(a.d) {
+			a.d[w] = byte(dig) + '0'
+			w++
+		} else if dig > 0 {
+			a.trunc = true
+		}
+		n *= 10
+	}
+	a.nd = w
+	a.trim()
+}
+
+// leftShift multiplies by 2^k
+func (a *jsonDecimal) leftShift(k uint) {
+	cutoff := jsonPowersOfFive[k]
+	delta := int(k) + 1 - len(cutoff)
+	if jsonDigitsLess(a.d[:a.nd], cutoff) {
+		delta--
+	}
+	r, w := a.nd, a.nd+delta
+	var n uint
+	for r--; r >= 0; r-- {
+		n += (uint(a.d[r]) - '0') << k
+		quo := n / 10
+		w--
+		a.setDigit(w, n-10*quo)
+		n = quo
+	}
+	for n > 0 {
+		quo := n / 10
+		w--
+		a.setDigit(w, n-10*quo)
+		n = quo
+	}
+	a.nd += delta
+	if a.nd >= len(a.d) {
+		a.nd = len(a.d)
+	}
+	a.dp += delta
+	a.trim()
+}
+
+func (a *jsonDecimal) setDigit(i int, v uint) {
+	if i < len(a.d) {
+		a.d[i] = byte(v) + '0'
+	} else if v != 0 {
+		a.trunc = true
+	}
+}
+
+// jsonDigitsLess reports whether the digits in b sort before s
+func jsonDigitsLess(b []byte, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if i >= len(b) {
+			return true
+		}
+		if b[i] != s[i] {
+			return b[i] < s[i]
+		}
+	}
+	return false
+}
+
+// shift multiplies by 2^k, or divides when k is negative
+func (a *jsonDecimal) shift(k int) {
+	switch {
+	case a.nd == 0:
+	case k > 0:
+		for k > jsonMaxShift {
+			a.leftShift(jsonMaxShift)
+			k -= jsonMaxShift
+		}
+		a.leftShift(uint(k))
+	case k < 0:
+		for k < -jsonMaxShift {
+			a.rightShift(jsonMaxShift)
+			k += jsonMaxShift
+		}
+		a.rightShift(uint(-k))
+	}
+}
+
+// shouldRoundUp rounds half to even unless digits were truncated
+func (a *jsonDecimal) shouldRoundUp(nd int) bool {
+	if nd < 0 || nd >= a.nd {
+		return false
+	}
+	if a.d[nd] == '5' && nd+1 == a.nd {
+		if a.trunc {
+			return true
+		}
+		return nd > 0 && (a.d[nd-1]-'0')%2 == 1
+	}
+	return a.d[nd] >= '5'
+}
+
+func (a *jsonDecimal) round(nd int) {
+	if nd < 0 || nd >= a.nd {
+		return
+	}
+	if a.shouldRoundUp(nd) {
+		a.roundUp(nd)
+	} else {
+		a.roundDown(nd)
+	}
+}
+
+func (a *jsonDecimal) roundDown(nd int) {
+	if nd < 0 || nd >= a.nd {
+		return
+	}
+	a.nd = nd
+	a.trim()
+}
+
+func (a *jsonDecimal) roundUp(nd int) {
+	if nd < 0 || nd >= a.nd {
+		return
+	}
+	for i := nd - 1; i >= 0; i-- {
+		if a.d[i] < '9' {
+			a.d[i]++
+			a.nd = i + 1
+			return
+		}
+	}
+	a.d[0] = '1'
+	a.nd = 1
+	a.dp++
+}
+
+// roundedInteger returns the integer part, rounded half to even
+func (a *jsonDecimal) roundedInteger() uint64 {
+	if a.dp > 20 {
+		return 1<<64 - 1
+	}
+	var n uint64
+	i := 0
+	for ; i < a.dp && i < a.nd; i++ {
+		n = n*10 + uint64(a.d[i]-'0')
+	}
+	for ; i < a.dp; i++ {
+		n *= 10
+	}
+	if a.dp < a.nd && a.shouldRoundUp(a.dp) {
+		n++
+	}
+	return n
+}
+
+// roundShortest trims d to the fewest digits that still parse back to the
+// float with mantissa mant and exponent exp
+func (d *jsonDecimal) roundShortest(mant uint64, exp int, flt *jsonFloatInfo) {
+	if mant == 0 {
+		d.nd = 0
+		return
+	}
+	minexp := flt.bias + 1
+	if exp > minexp && 332*(d.dp-d.nd) >= 100*(exp-int(flt.mantbits)) {
+		// Integers with few enough bits are already exact and shortest
+		return
+	}
+	// Halfway points to the neighbouring floats bound the digits we may drop
+	var upper, lower jsonDecimal
+	upper.assign(mant*2 + 1)
+	upper.shift(exp - int(flt.mantbits) - 1)
+	mantlo, explo := mant*2-1, exp-1
+	if mant > 1<<flt.mantbits || exp == minexp {
+		mantlo, explo = mant-1, exp
+	}
+	lower.assign(mantlo*2 + 1)
+	lower.shift(explo - int(flt.mantbits) - 1)
+	inclusive := mant%2 == 0
+
+	var upperdelta uint8
+	for ui := 0; ; ui++ {
+		mi := ui - upper.dp + d.dp
+		if mi >= d.nd {
+			break
+		}
+		li := ui - upper.dp + lower.dp
+		l := byte('0')
+		if li >= 0 && li < lower.nd {
+			l = lower.d[li]
+		}
+		m := byte('0')
+		if mi >= 0 {
+			m = d.d[mi]
+		}
+		u := byte('0')
+		if ui < upper.nd {
+			u = upper.d[ui]
+		}
+		okdown := l != m || inclusive && li+1 == lower.nd
+		switch {
+		case upperdelta == 0 && m+1 < u:
+			upperdelta = 2
+		case upperdelta == 0 && m != u:
+			upperdelta = 1
+		case upperdelta == 1 && (m != '9' || u != '0'):
+			upperdelta = 2
+		}
+		okup := upperdelta > 0 && (inclusive || upperdelta > 1 || ui+1 < upper.nd)
+		switch {
+		case okdown && okup:
+			d.round(mi + 1)
+			return
+		case okdown:
+			d.roundDown(mi + 1)
+			return
+		case okup:
+			d.roundUp(mi + 1)
+			return
+		}
+	}
+}
+
+// jsonPowTab gives the binary shift that moves the decimal point by i digits
+var jsonPowTab = []int{1, 3, 6, 9, 13, 16, 19, 23, 26}
+
+// floatBits rounds the decimal to the nearest float and reports overflow
+func (d *jsonDecimal) floatBits(flt *jsonFloatInfo) (b uint64, overflow bool) {
+	var exp int
+	var mant uint64
+	switch {
+	case d.nd == 0 || d.dp < -330:
+		exp = flt.bias
+	case d.dp > 310:
+		overflow = true
+	default:
+		for d.dp > 0 {
+			n := 27
+			if d.dp < len(jsonPowTab) {
+				n = jsonPowTab[d.dp]
+			}
+			d.shift(-n)
+			exp += n
+		}
+		for d.dp < 0 || d.dp == 0 && d.d[0] < '5' {
+			n := 27
+			if -d.dp < len(jsonPowTab) {
+				n = jsonPowTab[-d.dp]
+			}
+			d.shift(n)
+			exp -= n
+		}
+		// The value is now in [0.5, 1) times 2^exp; make it [1, 2)
+		exp--
+		if exp < flt.bias+1 {
+			n := flt.bias + 1 - exp
+			d.shift(-n)
+			exp += n
+		}
+		if exp-flt.bias >= 1<<flt.expbits-1 {
+			overflow = true
+			break
+		}
+		d.shift(int(1 + flt.mantbits))
+		mant = d.roundedInteger()
+		if mant == 2<<flt.mantbits {
+			mant >>= 1
+			exp++
+			if exp-flt.bias >= 1<<flt.expbits-1 {
+				overflow = true
+				break
+			}
+		}
+		if mant&(1<<flt.mantbits) == 0 {
+			exp = flt.bias
+		}
+	}
+	if overflow {
+		mant = 0
+		exp = 1<<flt.expbits - 1 + flt.bias
+	}
+	b = mant & (1<<flt.mantbits - 1)
+	b |= uint64((exp-flt.bias)&(1<<flt.expbits-1)) << flt.mantbits
+	if d.neg {
+		b |= 1 << flt.mantbits << flt.expbits
+	}
+	return b, overflow
+}
+
+// setJson loads an already validated JSON number
+func (d *jsonDecimal) setJson(s string) {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		d.neg = true
+		i++
+	}
+	sawdot := false
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '.' {
+			sawdot = true
+			d.dp = d.nd
+			continue
+		}
+		if c < '0' || c > '9' {
+			break
+		}
+		if c == '0' && d.nd == 0 {
+			// Leading zeros only move the decimal point
+			d.dp--
+			continue
+		}
+		if d.nd < len(d.d) {
+			d.d[d.nd] = c
+			d.nd++
+		} else if c != '0' {
+			d.trunc = true
+		}
+	}
+	if !sawdot {
+		d.dp = d.nd
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		sign := 1
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			if s[i] == '-' {
+				sign = -1
+			}
+			i++
+		}
+		e := 0
+		for ; i < len(s); i++ {
+			if e < 10000 {
+				e = e*10 + int(s[i]-'0')
+			}
+		}
+		d.dp += e * sign
+	}
+}
+
+// parseJsonFloat converts a valid JSON number to the nearest float64, or
+// float32 when bitSize is 32, reporting false when it overflows
+func parseJsonFloat(s string, bitSize int) (float64, bool) {
+	var d jsonDecimal
+	d.setJson(s)
+	if bitSize == 32 {
+		b, overflow := d.floatBits(&jsonFloat32Info)
+		bits := uint32(b)
+		return float64(*(*float32)(unsafe.Pointer(&bits))), !overflow
+	}
+	b, overflow := d.floatBits(&jsonFloat64Info)
+	return *(*float64)(unsafe.Pointer(&b)), !overflow
+}
+
+// appendJsonFloat writes f with the fewest digits that parse back to the same
+// float64, or float32 when bitSize is 32. Like encoding/json it switches to
+// exponent form outside [1e-6, 1e21). NaN and infinities must be handled by
+// the caller since JSON cannot represent them.
+func appendJsonFloat(dst []byte, f float64, bitSize int) []byte {
+	var bits uint64
+	flt := &jsonFloat64Info
+	if bitSize == 32 {
+		f32 := float32(f)
+		bits = uint64(*(*uint32)(unsafe.Pointer(&f32)))
+		flt = &jsonFloat32Info
+	} else {
+		bits = *(*uint64)(unsafe.Pointer(&f))
+	}
+	neg := bits>>(flt.expbits+flt.mantbits) != 0
+	exp := int(bits>>flt.mantbits) & (1<<flt.expbits - 1)
+	mant := bits & (1<<flt.mantbits - 1)
+	if exp == 0 {
+		exp++ // denormal
+	} else {
+		mant |= 1 << flt.mantbits
+	}
+	exp += flt.bias
+
+	var d jsonDecimal
+	d.assign(mant)
+	d.shift(exp - int(flt.mantbits))
+	d.roundShortest(mant, exp, flt)
+
+	if neg {
+		dst = append(dst, '-')
+	}
+	if d.nd == 0 {
+		return append(dst, '0')
+	}
+	// Decimal exponent of the leading digit
+	if e10 := d.dp - 1; e10 < -6 || e10 >= 21 {
+		return appendJsonFloatExp(dst, &d)
+	}
+	if d.dp > 0 {
+		m := min(d.nd, d.dp)
+		dst = append(dst, d.d[:m]...)
+		for ; m < d.dp; m++ {
+			dst = append(dst, '0')
+		}
+	} else {
+		dst = append(dst, '0')
+	}
+	if d.nd > d.dp {
+		dst = append(dst, '.')
+		for i := d.dp; i < 0; i++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, d.d[max(d.dp, 0):d.nd]...)
+	}
+	return dst
+}
+
+// appendJsonFloatExp writes d as d.ddde±x using the shortest exponent
+func appendJsonFloatExp(dst []byte, d *jsonDecimal) []byte {
+	dst = append(dst, d.d[0])
+	if d.nd > 1 {
+		dst = append(dst, '.')
+		dst = append(dst, d.d[1:d.nd]...)
+	}
+	dst = append(dst, 'e')
+	exp := d.dp - 1
+	if exp < 0 {
+		dst = append(dst, '-')
+		exp = -exp
+	} else {
+		dst = append(dst, '+')
+	}
+	if exp >= 100 {
+		dst = append(dst, byte(exp/100)+'0')
+	}
+	if exp >= 10 {
+		dst = append(dst, byte(exp/10%10)+'0')
+	}
+	return append(dst, byte(exp%10)+'0')
+}
@@ -0,0 +1,221 @@
+package tinywodp
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestAppendJsonFloatShortest(t *testing.T) {
+	// Expected output is what encoding/json writes for the same values
+	tests := []struct {
+		value    float64
+		bitSize  int
+		expected string
+	}{
+		{0, 64, "0"},
+		{math.Copysign(0, -1), 64, "-0"},
+		{0.1, 64, "0.1"},
+		{0.1 + 0.2, 64, "0.30000000000000004"},
+		{1.5e-3, 64, "0.0015"},
+		{1e9, 64, "1000000000"},
+		{123456789.125, 64, "123456789.125"},
+		{1e20, 64, "100000000000000000000"},
+		{1e21, 64, "1e+21"},
+		{1e-6, 64, "0.000001"},
+		{1e-7, 64, "1e-7"},
+		{-2.5e-10, 64, "-2.5e-10"},
+		{math.MaxFloat64, 64, "1.7976931348623157e+308"},
+		{math.SmallestNonzeroFloat64, 64, "5e-324"},
+		{2.2250738585072014e-308, 64, "2.2250738585072014e-308"},
+		{float64(float32(0.1)), 32, "0.1"},
+		{float64(float32(16777217)), 32, "16777216"},
+		{math.MaxFloat32, 32, "3.4028235e+38"},
+	}
+	for _, tt := range tests {
+		got := string(appendJsonFloat(nil, tt.value, tt.bitSize))
+		if got != tt.expected {
+			t.Errorf("appendJsonFloat(%v, %d) = %s, expected %s", tt.value, tt.bitSize, got, tt.expected)
+		}
+	}
+}
+
+func TestParseJsonFloatExact(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"0.1", 0.1},
+		{"1e9", 1e9},
+		{"1.5E-3", 1.5e-3},
+		{"-2.5e+3", -2500},
+		{"0.30000000000000004", 0.1 + 0.2},
+		{"1.7976931348623157e308", math.MaxFloat64},
+		{"4.9406564584124654e-324", math.SmallestNonzeroFloat64},
+		{"2.2250738585072011e-308", 2.225073858507201e-308},
+		{"9007199254740993", 9007199254740992}, // halfway, rounds to even
+		{"1e-400", 0},
+	}
+	for _, tt := range tests {
+		got, ok := parseJsonFloat(tt.input, 64)
+		if !ok || got != tt.expected {
+			t.Errorf("parseJsonFloat(%s) = %v, %v; expected %v", tt.input, got, ok, tt.expected)
+		}
+	}
+	for _, input := range []string{"1e309", "-1.8e308"} {
+		if _, ok := parseJsonFloat(input, 64); ok {
+			t.Errorf("parseJsonFloat(%s) should overflow", input)
+		}
+	}
+	if _, ok := parseJsonFloat("3.5e38", 32); ok {
+		t.Error("parseJsonFloat(3.5e38, 32) should overflow")
+	}
+}
+
+func TestParseJsonFloatSubnormal(t *testing.T) {
+	// Subnormal results and underflow round at a negative decimal point
+	tests := []struct {
+		input    string
+		bitSize  int
+		expected float64
+	}{
+		{"5e-324", 64, math.SmallestNonzeroFloat64},
+		{"4.9e-324", 64, math.SmallestNonzeroFloat64},
+		{"3e-324", 64, math.SmallestNonzeroFloat64},
+		{"2e-324", 64, 0},
+		{"1e-320", 64, 1e-320},
+		{"-1e-320", 64, -1e-320},
+		{"1e-45", 32, float64(float32(1e-45))},
+		{"1e-46", 32, 0},
+		{"1e-50", 32, 0},
+	}
+	for _, tt := range tests {
+		got, ok := parseJsonFloat(tt.input, tt.bitSize)
+		if !ok || got != tt.expected {
+			t.Errorf("parseJsonFloat(%s, %d) = %v, %v; expected %v", tt.input, tt.bitSize, got, ok, tt.expected)
+		}
+	}
+
+	var f float64
+	if err := Convert("5e-324").JsonDecode(&f); err != nil || f != math.SmallestNonzeroFloat64 {
+		t.Errorf("JsonDecode(5e-324) = %v, %v", f, err)
+	}
+}
+
+func TestJsonFloatRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	type Sample struct {
+		Values []float64
+		Single float32
+		Ratio  float64
+	}
+
+	in := Sample{
+		Values: []float64{0.1, 1.0 / 3, 2.0 / 3, math.Pi, 1e23, 5e-324, -123.456e-78, 4.35, 0.07},
+		Single: 0.1,
+		Ratio:  1.0 / 7,
+	}
+	out, err := Convert(&in).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	expected := `{"Values":[0.1,0.3333333333333333,0.6666666666666666,3.141592653589793,1e+23,5e-324,` +
+		`-1.23456e-76,4.35,0.07],"Single":0.1,"Ratio":0.14285714285714285}`
+	if string(out) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", out, expected)
+	}
+
+	var decoded Sample
+	if err := Convert(out).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if len(decoded.Values) != len(in.Values) {
+		t.Fatalf("decoded %d values, expected %d", len(decoded.Values), len(in.Values))
+	}
+	for i := range in.Values {
+		if decoded.Values[i] != in.Values[i] {
+			t.Errorf("Values[%d] = %v, expected %v", i, decoded.Values[i], in.Values[i])
+		}
+	}
+	if decoded.Single != in.Single || decoded.Ratio != in.Ratio {
+		t.Errorf("JsonDecode = %+v, expected %+v", decoded, in)
+	}
+}
+
+func TestJsonDecodeScientificNotation(t *testing.T) {
+	clearRefStructsCache()
+
+	type Reading struct {
+		Count int64
+		Small int16
+		Scale float64
+		Gain  float32
+	}
+
+	var r Reading
+	if err := Convert(`{"Count":1e9,"Small":-2.5E+3,"Scale":1.5E-3,"Gain":2e-1}`).JsonDecode(&r); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if r.Count != 1000000000 || r.Small != -2500 || r.Scale != 0.0015 || r.Gain != 0.2 {
+		t.Errorf("JsonDecode = %+v", r)
+	}
+
+	var v any
+	if err := Convert(`[1e9,1.5E-3]`).JsonDecode(&v); err != nil {
+		t.Fatalf("JsonDecode(any) returned error: %v", err)
+	}
+	if arr, ok := v.([]any); !ok || len(arr) != 2 || arr[0] != 1e9 || arr[1] != 0.0015 {
+		t.Errorf("JsonDecode(any) = %#v", v)
+	}
+
+	// Exponent forms must still fit and be whole for integer targets
+	for _, input := range []string{`{"Small":1e5}`, `{"Count":1e19}`, `{"Gain":1e39}`, `{"Scale":1e309}`} {
+		var bad Reading
+		if err := Convert(input).JsonDecode(&bad); err == nil {
+			t.Errorf("JsonDecode(%s) should fail, got %+v", input, bad)
+		}
+	}
+}
+
+func TestJsonEncodeNonFinite(t *testing.T) {
+	clearRefStructsCache()
+
+	type Stats struct {
+		Mean   float64
+		Values []float64
+	}
+
+	for _, in := range []Stats{{Mean: math.NaN()}, {Values: []float64{1, math.Inf(1)}}, {Mean: math.Inf(-1)}} {
+		if out, err := Convert(&in).JsonEncode(); err == nil {
+			t.Errorf("JsonEncode(%v) should fail by default, got %s", in, out)
+		} else if !Contains(err.Error(), string(errUnsupportedValue)) {
+			t.Errorf("JsonEncode(%v) error = %v", in, err)
+		}
+	}
+
+	// Nested non-finite values fail the whole encoding as well
+	for _, in := range []any{[]Stats{{Mean: math.NaN()}}, []any{1, math.Inf(1)}, [][]float64{{math.NaN()}}} {
+		if out, err := Convert(in).JsonEncode(); err == nil || !Contains(err.Error(), string(errUnsupportedValue)) {
+			t.Errorf("JsonEncode(%v) = %s, %v; expected an unsupported value error", in, out, err)
+		}
+	}
+
+	in := Stats{Mean: math.NaN(), Values: []float64{1.5, math.Inf(1), math.Inf(-1)}}
+	expected := `{"Mean":null,"Values":[1.5,null,null]}`
+
+	opts := DefaultJsonOptions()
+	opts.NonFinite = NonFiniteAsNull
+	out, err := Convert(&in).JsonEncodeWith(opts)
+	if err != nil || string(out) != expected {
+		t.Errorf("JsonEncodeWith(NonFiniteAsNull) = %s, %v; expected %s", out, err, expected)
+	}
+
+	SetNonFiniteEncoding(NonFiniteAsNull)
+	defer SetNonFiniteEncoding(NonFiniteAsError)
+	out, err = Convert(&in).JsonEncode()
+	if err != nil || string(out) != expected {
+		t.Errorf("JsonEncode with SetNonFiniteEncoding(NonFiniteAsNull) = %s, %v; expected %s", out, err, expected)
+	}
+}
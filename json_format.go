@@ -0,0 +1,70 @@
+package tinywodp
+
+// Raw JSON formatting
+// Stored payloads and log lines can be normalized or pretty-printed without
+// decoding them into Go values: the text is validated with the same scanner as
+// JsonCheck and then rewritten token by token, strings copied byte for byte.
+//
+//	compact, err := JsonMinify(stored)            // {"a":[1,2]}
+//	pretty, err := JsonIndent(line, "", "  ")     // one member per line
+//
+// Both reject malformed input with the JsonCheck error and leave numbers,
+// escapes and key order exactly as written.
+
+// JsonMinify returns src without insignificant whitespace
+func JsonMinify(src []byte) ([]byte, error) {
+	src, err := stripJsonBOMBytes(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkJsonSyntax(src); err != nil {
+		return nil, err
+	}
+	return appendMinifiedJson(make([]byte, 0, len(src)), src), nil
+}
+
+// JsonIndent returns src with each element on a new line starting with prefix
+// followed by one copy of indent per nesting level, as MarshalIndent does
+// Empty objects and arrays stay on one line
+func JsonIndent(src []byte, prefix, indent string) ([]byte, error) {
+	src, err := stripJsonBOMBytes(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkJsonSyntax(src); err != nil {
+		return nil, err
+	}
+	return appendIndentJson(make([]byte, 0, len(src)*2), src, prefix, indent), nil
+}
+
+// checkJsonSyntax validates src as one JSON value, reporting the position of
+// the first error
+func checkJsonSyntax(src []byte) error {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	return jh.positionError(jh.checkJson(string(src)))
+}
+
+// appendMinifiedJson appends the valid JSON src to dst without whitespace
+// outside strings
+func appendMinifiedJson(dst, src []byte) []byte {
+	inString, escaped := false, false
+	for _, b := range src {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+		} else if isJsonSpace(b) {
+			continue
+		} else if b == '"' {
+			inString = true
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
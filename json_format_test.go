@@ -0,0 +1,41 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonMinify(t *testing.T) {
+	src := " {\n  \"a b\" : [ 1 , 2.50 ],\r\n\t\"s\\\" \": \" keep  spaces \", \"e\": {} }\n"
+	out, err := JsonMinify([]byte(src))
+	expected := `{"a b":[1,2.50],"s\" ":" keep  spaces ","e":{}}`
+	if err != nil || string(out) != expected {
+		t.Errorf("JsonMinify = %s, %v; expected %s", out, err, expected)
+	}
+
+	if _, err := JsonMinify([]byte(`{"a":1,}`)); err == nil || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("JsonMinify(invalid) = %v, expected syntax error", err)
+	}
+}
+
+func TestJsonIndent(t *testing.T) {
+	out, err := JsonIndent([]byte(` {"a":[1, {"b" :null}],"c":[ ]} `), ">", "  ")
+	expected := "{\n>  \"a\": [\n>    1,\n>    {\n>      \"b\": null\n>    }\n>  ],\n>  \"c\": []\n>}"
+	if err != nil || string(out) != expected {
+		t.Errorf("JsonIndent =\n%s\n%v; expected\n%s", out, err, expected)
+	}
+
+	// Indenting minified output gives the same text as MarshalIndent
+	type pair struct{ Key, Value string }
+	v := []pair{{"k", "v , : {"}}
+	compact, _ := Marshal(v)
+	want, _ := MarshalIndent(v, "", "\t")
+	if got, err := JsonIndent(compact, "", "\t"); err != nil || string(got) != string(want) {
+		t.Errorf("JsonIndent = %s, %v; expected %s", got, err, want)
+	}
+
+	if _, err := JsonIndent([]byte(`[1 2]`), "", " "); err == nil || !Contains(err.Error(), "line 1") {
+		t.Errorf("JsonIndent(invalid) = %v, expected a positioned error", err)
+	}
+}
@@ -0,0 +1,548 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Runtime support for generated codecs
+// cmd/tinywodp-gen writes EncodeJSON and DecodeJSON methods that drive a
+// JsonWriter and a JsonReader field by field, with the keys fixed at generation
+// time, so no reflection runs for those types. The generated MarshalJSONTiny and
+// UnmarshalJSONTiny wrappers make JsonEncode and JsonDecode use them too:
+//
+//	//tinywodp:json
+//	type User struct {
+//		Name string
+//		Tags []string
+//	}
+//
+//	//go:generate tinywodp-gen
+//
+// The writer follows the global settings (SetEscapeNonASCII, SetNilSliceEncoding,
+// SetByteSliceEncoding, SetNonFiniteEncoding); the reader decodes with the
+// default options. Both keep the first error and turn the following calls into
+// no-ops, so generated code checks it once, in Finish.
+
+// JsonWriter builds JSON output value by value for generated encoders
+type JsonWriter struct {
+	jh    *jsonH
+	comma bool // a value was written at this level, the next one needs ','
+	err   error
+}
+
+// NewJsonWriter returns a writer with an empty output buffer
+func NewJsonWriter() *JsonWriter {
+	return &JsonWriter{jh: getJsonH("_")}
+}
+
+// Finish returns a copy of the output, or the first error, and releases the
+// writer's buffers; the writer cannot be used afterwards
+func (w *JsonWriter) Finish() ([]byte, error) {
+	defer func() {
+		putJsonH(w.jh)
+		w.jh = nil
+	}()
+	if w.err != nil {
+		return nil, w.err
+	}
+	result := make([]byte, len(w.jh.jOut))
+	copy(result, w.jh.jOut)
+	return result, nil
+}
+
+// value starts a value, writing the separator it needs; false after an error
+func (w *JsonWriter) value() bool {
+	if w.err != nil {
+		return false
+	}
+	if w.comma {
+		w.jh.jOut = append(w.jh.jOut, ',')
+	}
+	w.comma = true
+	return true
+}
+
+// BeginObject writes '{'
+func (w *JsonWriter) BeginObject() {
+	if w.value() {
+		w.jh.jOut = append(w.jh.jOut, '{')
+		w.comma = false
+	}
+}
+
+// EndObject writes '}'
+func (w *JsonWriter) EndObject() {
+	if w.err == nil {
+		w.jh.jOut = append(w.jh.jOut, '}')
+		w.comma = true
+	}
+}
+
+// BeginArray writes '['
+func (w *JsonWriter) BeginArray() {
+	if w.value() {
+		w.jh.jOut = append(w.jh.jOut, '[')
+		w.comma = false
+	}
+}
+
+// EndArray writes ']'
+func (w *JsonWriter) EndArray() {
+	if w.err == nil {
+		w.jh.jOut = append(w.jh.jOut, ']')
+		w.comma = true
+	}
+}
+
+// Key writes an object member name and its ':'
+func (w *JsonWriter) Key(name string) {
+	if w.value() {
+		w.jh.jOut = w.jh.quoteJsonString(w.jh.jOut, name)
+		w.jh.jOut = append(w.jh.jOut, ':')
+		w.comma = false
+	}
+}
+
+// String writes a quoted string
+func (w *JsonWriter) String(s string) {
+	if w.value() {
+		w.jh.jOut = w.jh.quoteJsonString(w.jh.jOut, s)
+	}
+}
+
+// Int writes a signed integer
+func (w *JsonWriter) Int(n int64) {
+	if w.value() {
+		if !w.jh.jConv.intToJsonString(n) {
+			w.err = w.jh.jConv
+			return
+		}
+		w.jh.jOut = append(w.jh.jOut, w.jh.jConv.tmpStr...)
+	}
+}
+
+// Uint writes an unsigned integer
+func (w *JsonWriter) Uint(n uint64) {
+	if w.value() {
+		if !w.jh.jConv.uintToJsonString(n) {
+			w.err = w.jh.jConv
+			return
+		}
+		w.jh.jOut = append(w.jh.jOut, w.jh.jConv.tmpStr...)
+	}
+}
+
+// Float writes f with the shortest digits for its bit size, 32 or 64
+func (w *JsonWriter) Float(f float64, bitSize int) {
+	if w.value() {
+		w.jh.jOut, w.err = w.jh.appendJsonFloatValue(w.jh.jOut, f, bitSize)
+	}
+}
+
+// Bool writes true or false
+func (w *JsonWriter) Bool(b bool) {
+	if w.value() {
+		if b {
+			w.jh.jOut = append(w.jh.jOut, "true"...)
+		} else {
+			w.jh.jOut = append(w.jh.jOut, "false"...)
+		}
+	}
+}
+
+// Null writes null
+func (w *JsonWriter) Null() {
+	if w.value() {
+		w.jh.jOut = append(w.jh.jOut, "null"...)
+	}
+}
+
+// NilSlice writes a nil slice: [] by default, null with NilSliceAsNull
+func (w *JsonWriter) NilSlice() {
+	if w.value() {
+		if w.jh.jNilSlice == NilSliceAsNull {
+			w.jh.jOut = append(w.jh.jOut, "null"...)
+		} else {
+			w.jh.jOut = append(w.jh.jOut, '[', ']')
+		}
+	}
+}
+
+// Bytes writes a byte slice as a base64 string, or as an array of numbers
+// with BytesAsArray
+func (w *JsonWriter) Bytes(b []byte) {
+	if w.jh.jBytes != BytesAsBase64 {
+		if b == nil {
+			w.NilSlice()
+			return
+		}
+		w.BeginArray()
+		for _, c := range b {
+			w.Uint(uint64(c))
+		}
+		w.EndArray()
+		return
+	}
+	if !w.value() {
+		return
+	}
+	if b == nil && w.jh.jNilSlice == NilSliceAsNull {
+		w.jh.jOut = append(w.jh.jOut, "null"...)
+		return
+	}
+	w.jh.jOut = append(w.jh.jOut, '"')
+	w.jh.jOut = appendBase64(w.jh.jOut, b)
+	w.jh.jOut = append(w.jh.jOut, '"')
+}
+
+// JsonReader reads JSON input value by value for generated decoders
+//
+// Objects and arrays are read with a loop:
+//
+//	if r.BeginObject() {
+//		for r.More() {
+//			switch r.Key() {
+//			case "Name":
+//				v.Name = r.String()
+//			default:
+//				r.Skip()
+//			}
+//		}
+//	}
+type JsonReader struct {
+	jh    *jsonH
+	s     string
+	i     int
+	first bool   // no member of the innermost container was read yet
+	stack []byte // closing bytes of the open containers
+	err   error
+}
+
+// NewJsonReader returns a reader over a copy of data
+func NewJsonReader(data []byte) *JsonReader {
+	jh := getJsonH("_")
+	jh.jInput = string(data) // decoded strings may point into it, so it is copied
+	r := &JsonReader{jh: jh, s: jh.jInput}
+	if isJsonBlank(r.s) {
+		r.err = Err(ErrEmptyInput)
+	}
+	return r
+}
+
+// Finish checks that nothing but whitespace follows the value and returns the
+// first error, with its position, then releases the reader's buffers; the
+// reader cannot be used afterwards
+func (r *JsonReader) Finish() error {
+	defer func() {
+		putJsonH(r.jh)
+		r.jh = nil
+	}()
+	if r.err == nil {
+		if i := skipJsonSpace(r.s, r.i); i < len(r.s) {
+			r.fail(i, Err(errInvalidJSON, ErrTrailingData))
+		}
+	}
+	if r.err != nil {
+		return r.jh.positionError(r.err)
+	}
+	return nil
+}
+
+// fail records the first error, marking offset i of the input
+func (r *JsonReader) fail(i int, err error) {
+	if r.err == nil {
+		r.err = r.jh.errorAt(r.s, i, err)
+	}
+}
+
+// start moves to the next value; false after an error or at the end of input
+func (r *JsonReader) start() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.i = skipJsonSpace(r.s, r.i); r.i >= len(r.s) {
+		r.fail(r.i, Err(errInvalidJSON, "unexpected end of input"))
+		return false
+	}
+	return true
+}
+
+// scalar returns the text of the next value, which must not be a container
+func (r *JsonReader) scalar(expected string) (string, bool) {
+	if !r.start() {
+		return "", false
+	}
+	if c := r.s[r.i]; c == '{' || c == '[' {
+		r.fail(r.i, Err(errInvalidJSON, "expected "+expected+" but got complex type"))
+		return "", false
+	}
+	end, err := skipJsonValue(r.s, r.i)
+	if err != nil {
+		r.fail(end, err)
+		return "", false
+	}
+	text := r.s[r.i:end]
+	r.i = end
+	return text, true
+}
+
+// Null consumes null and reports true when it is the next value
+func (r *JsonReader) Null() bool {
+	if !r.start() || r.s[r.i] != 'n' {
+		return false
+	}
+	end, err := skipJsonLiteral(r.s, r.i)
+	if err != nil || r.s[r.i:end] != "null" {
+		return false
+	}
+	r.i = end
+	return true
+}
+
+// BeginObject moves past '{'; false when the next value is not an object
+func (r *JsonReader) BeginObject() bool {
+	return r.begin('{', '}', "object")
+}
+
+// BeginArray moves past '['; false when the next value is not an array
+func (r *JsonReader) BeginArray() bool {
+	return r.begin('[', ']', "array")
+}
+
+// begin opens a container; More reads its members up to close
+func (r *JsonReader) begin(open, close byte, expected string) bool {
+	if !r.start() {
+		return false
+	}
+	if r.s[r.i] != open {
+		text, _ := r.scalar(expected)
+		r.fail(r.i-len(text), Err(errInvalidJSON, "expected "+expected+" but got: "+text))
+		return false
+	}
+	if err := r.jh.enterJsonDepth(); err != nil {
+		r.fail(r.i, err)
+		return false
+	}
+	r.i++
+	r.stack = append(r.stack, close)
+	r.first = true
+	return true
+}
+
+// More reports whether the innermost object or array has another member,
+// moving past the ',' before it, or past the closing byte when it has not
+func (r *JsonReader) More() bool {
+	if r.err != nil || len(r.stack) == 0 {
+		return false
+	}
+	close := r.stack[len(r.stack)-1]
+	i := skipJsonSpace(r.s, r.i)
+	switch {
+	case i < len(r.s) && r.s[i] == close:
+		r.i = i + 1
+		r.stack = r.stack[:len(r.stack)-1]
+		r.jh.leaveJsonDepth()
+		r.first = false
+		return false
+	case r.first:
+		if i >= len(r.s) {
+			r.fail(i, Err(errInvalidJSON, "unexpected end of input"))
+			return false
+		}
+	default:
+		next, done, err := r.jh.nextJsonMember(r.s, i, close)
+		if err != nil || done {
+			r.fail(next, err)
+			return false
+		}
+		i = next
+	}
+	r.i = i
+	r.first = false
+	return true
+}
+
+// Key reads an object member name and the ':' after it
+func (r *JsonReader) Key() string {
+	if r.err != nil {
+		return ""
+	}
+	key, i, err := r.jh.scanJsonKey(r.s, r.i)
+	if err != nil {
+		r.fail(i, err)
+		return ""
+	}
+	r.i = i
+	return key
+}
+
+// Skip moves past the next value, whatever its type
+func (r *JsonReader) Skip() {
+	if !r.start() {
+		return
+	}
+	end, err := r.jh.skipJsonValueAt(r.s, r.i)
+	if err != nil {
+		r.fail(end, err)
+		return
+	}
+	r.i = end
+}
+
+// String reads a string
+func (r *JsonReader) String() string {
+	start := skipJsonSpace(r.s, r.i)
+	text, ok := r.scalar("string")
+	if !ok {
+		return ""
+	}
+	if text[0] != '"' {
+		r.fail(start, Err(errInvalidJSON, "expected string but got: "+text))
+		return ""
+	}
+	s, err := r.jh.unescapeJsonString(text[1 : len(text)-1])
+	if err != nil {
+		r.fail(start, err)
+		return ""
+	}
+	return s
+}
+
+// number reads a number literal
+func (r *JsonReader) number() (string, int, bool) {
+	start := skipJsonSpace(r.s, r.i)
+	text, ok := r.scalar("number")
+	if ok && !isJsonNumber(text) {
+		r.fail(start, Err(errInvalidJSON, "expected number but got: "+text))
+		return "", start, false
+	}
+	return text, start, ok
+}
+
+// Int reads an integer that fits bitSize bits; 0 is the size of int
+func (r *JsonReader) Int(bitSize int) int64 {
+	text, start, ok := r.number()
+	if !ok {
+		return 0
+	}
+	if bitSize == 0 {
+		bitSize = 32 << (^uint(0) >> 63)
+	}
+	var n int64
+	if isJsonIntegerLiteral(text) {
+		v, err := Convert(text).ToInt64()
+		if err != nil {
+			r.fail(start, Err(errInvalidJSON, "number out of range for int"+Convert(bitSize).String()+": "+text))
+			return 0
+		}
+		n = v
+	} else {
+		// Exponent forms such as 1e9 are fine when they denote a whole number
+		f, ok := parseJsonFloat(text, 64)
+		if !ok || f < -1<<63 || f >= 1<<63 || f != float64(int64(f)) {
+			r.fail(start, Err(errInvalidJSON, "expected integer but got: "+text))
+			return 0
+		}
+		n = int64(f)
+	}
+	if limit := int64(1) << (bitSize - 1); bitSize < 64 && (n < -limit || n >= limit) {
+		r.fail(start, Err(errInvalidJSON, "number out of range for int"+Convert(bitSize).String()+": "+text))
+		return 0
+	}
+	return n
+}
+
+// Uint reads an unsigned integer that fits bitSize bits; 0 is the size of uint
+func (r *JsonReader) Uint(bitSize int) uint64 {
+	text, start, ok := r.number()
+	if !ok {
+		return 0
+	}
+	if bitSize == 0 {
+		bitSize = 32 << (^uint(0) >> 63)
+	}
+	if text[0] == '-' {
+		// -0, -0.0 and -0e5 are zero and fit any unsigned target
+		if !isJsonZero(text[1:]) {
+			r.fail(start, Err(errInvalidJSON, "negative number for uint"+Convert(bitSize).String()+": "+text))
+		}
+		return 0
+	}
+	var n uint64
+	if isJsonIntegerLiteral(text) {
+		if n, ok = parseJsonUintLiteral(text); !ok {
+			r.fail(start, Err(errInvalidJSON, "number out of range for uint"+Convert(bitSize).String()+": "+text))
+			return 0
+		}
+	} else {
+		f, ok := parseJsonFloat(text, 64)
+		if !ok || f >= 1<<64 || f != float64(uint64(f)) {
+			r.fail(start, Err(errInvalidJSON, "expected integer but got: "+text))
+			return 0
+		}
+		n = uint64(f)
+	}
+	if bitSize < 64 && n >= uint64(1)<<bitSize {
+		r.fail(start, Err(errInvalidJSON, "number out of range for uint"+Convert(bitSize).String()+": "+text))
+		return 0
+	}
+	return n
+}
+
+// Float reads a number rounded to bitSize bits, 32 or 64
+func (r *JsonReader) Float(bitSize int) float64 {
+	text, start, ok := r.number()
+	if !ok {
+		return 0
+	}
+	f, ok := parseJsonFloat(text, bitSize)
+	if !ok {
+		r.fail(start, Err(errInvalidJSON, "number out of range for float"+Convert(bitSize).String()+": "+text))
+		return 0
+	}
+	return f
+}
+
+// Bool reads true or false
+func (r *JsonReader) Bool() bool {
+	start := skipJsonSpace(r.s, r.i)
+	text, ok := r.scalar("boolean")
+	switch {
+	case !ok:
+		return false
+	case text == "true":
+		return true
+	case text != "false":
+		r.fail(start, Err(errInvalidJSON, "expected boolean but got: "+text))
+	}
+	return false
+}
+
+// Bytes reads a base64 string or an array of numbers; null gives nil
+func (r *JsonReader) Bytes() []byte {
+	if r.Null() {
+		return nil
+	}
+	if !r.start() {
+		return nil
+	}
+	if r.s[r.i] == '[' {
+		b := []byte{}
+		if r.BeginArray() {
+			for r.More() {
+				b = append(b, byte(r.Uint(8)))
+			}
+		}
+		return b
+	}
+	start := r.i
+	text := r.String()
+	if r.err != nil {
+		return nil
+	}
+	b, err := decodeBase64(text)
+	if err != nil {
+		r.fail(start, err)
+	}
+	return b
+}
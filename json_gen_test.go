@@ -0,0 +1,201 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// genItem carries the methods tinywodp-gen writes for
+//
+//	type genItem struct {
+//		SKU  string `json:"sku"`
+//		Qty  int8
+//		Tags []string
+//		Next *genItem
+//	}
+type genItem struct {
+	SKU  string `json:"sku"`
+	Qty  int8
+	Tags []string
+	Next *genItem
+}
+
+func (v *genItem) EncodeJSON(w *JsonWriter) {
+	w.BeginObject()
+	w.Key("sku")
+	w.String(v.SKU)
+	w.Key("Qty")
+	w.Int(int64(v.Qty))
+	w.Key("Tags")
+	if v.Tags == nil {
+		w.NilSlice()
+	} else {
+		w.BeginArray()
+		for i0 := range v.Tags {
+			w.String(v.Tags[i0])
+		}
+		w.EndArray()
+	}
+	w.Key("Next")
+	if v.Next == nil {
+		w.Null()
+	} else {
+		v.Next.EncodeJSON(w)
+	}
+	w.EndObject()
+}
+
+func (v *genItem) DecodeJSON(r *JsonReader) {
+	if !r.BeginObject() {
+		return
+	}
+	for r.More() {
+		switch r.Key() {
+		case "sku", "SKU":
+			v.SKU = r.String()
+		case "Qty", "qty":
+			v.Qty = int8(r.Int(8))
+		case "Tags", "tags":
+			if r.Null() {
+				v.Tags = nil
+			} else if r.BeginArray() {
+				s0 := []string{}
+				for r.More() {
+					var e0 string
+					s0 = append(s0, e0)
+					s0[len(s0)-1] = r.String()
+				}
+				v.Tags = s0
+			}
+		case "Next", "next":
+			if r.Null() {
+				v.Next = nil
+			} else {
+				if v.Next == nil {
+					v.Next = new(genItem)
+				}
+				v.Next.DecodeJSON(r)
+			}
+		default:
+			r.Skip()
+		}
+	}
+}
+
+func (v *genItem) MarshalJSONTiny() ([]byte, error) {
+	w := NewJsonWriter()
+	v.EncodeJSON(w)
+	return w.Finish()
+}
+
+func (v *genItem) UnmarshalJSONTiny(data []byte) error {
+	r := NewJsonReader(data)
+	v.DecodeJSON(r)
+	return r.Finish()
+}
+
+func TestJsonWriterMatchesEncoder(t *testing.T) {
+	clearRefStructsCache()
+
+	item := genItem{SKU: "a\"1", Qty: -3, Tags: []string{"x", "ñ"}, Next: &genItem{SKU: "b"}}
+	generated, err := item.MarshalJSONTiny()
+	if err != nil {
+		t.Fatalf("MarshalJSONTiny failed: %v", err)
+	}
+	expected := `{"sku":"a\"1","Qty":-3,"Tags":["x","ñ"],"Next":{"sku":"b","Qty":0,"Tags":[],"Next":null}}`
+	if string(generated) != expected {
+		t.Errorf("got  %s\nwant %s", generated, expected)
+	}
+
+	// JsonEncode picks the generated method up
+	encoded, err := Convert(&item).JsonEncode()
+	if err != nil || string(encoded) != expected {
+		t.Errorf("JsonEncode: got %s, %v", encoded, err)
+	}
+}
+
+func TestJsonWriterScalars(t *testing.T) {
+	w := NewJsonWriter()
+	w.BeginArray()
+	w.Uint(18446744073709551615)
+	w.Float(0.1, 64)
+	w.Float(float64(float32(0.1)), 32)
+	w.Bool(true)
+	w.Null()
+	w.Bytes([]byte("hi"))
+	w.Bytes(nil)
+	w.BeginObject()
+	w.EndObject()
+	w.EndArray()
+	out, err := w.Finish()
+	if err != nil || string(out) != `[18446744073709551615,0.1,0.1,true,null,"aGk=","",{}]` {
+		t.Errorf("got %s, %v", out, err)
+	}
+
+	var zero float64
+	w = NewJsonWriter()
+	w.BeginArray()
+	w.Float(zero/zero, 64)
+	w.String("ignored after the error")
+	w.EndArray()
+	if out, err := w.Finish(); err == nil || out != nil {
+		t.Errorf("expected the NaN error, got %s", out)
+	}
+}
+
+func TestJsonReaderDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	var item genItem
+	input := ` {"sku":"añ","QTY":1,"qty":-128,"Tags":["x",null],"unknown":{"deep":[1,{}]},"next":{"SKU":"b","Tags":null}} `
+	err := item.UnmarshalJSONTiny([]byte(input))
+	if err == nil || !Contains(err.Error(), "expected string") {
+		t.Fatalf("expected an error for the null tag, got %v", err)
+	}
+
+	item = genItem{}
+	input = ` {"sku":"añ","QTY":1,"qty":-128,"Tags":["x"],"unknown":{"deep":[1,{}]},"next":{"SKU":"b","Tags":null}} `
+	if err := Convert(input).JsonDecode(&item); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if item.SKU != "añ" || item.Qty != -128 || len(item.Tags) != 1 || item.Next == nil || item.Next.SKU != "b" || item.Next.Tags != nil {
+		t.Errorf("got %+v", item)
+	}
+}
+
+func TestJsonReaderErrors(t *testing.T) {
+	for input, want := range map[string]string{
+		``:                     string(ErrEmptyInput),
+		`{"Qty":128}`:          "out of range for int8",
+		`{"Qty":1.5}`:          "expected integer",
+		`{"SKU":1}`:            "expected string",
+		`{"SKU":"a",}`:         "expected object key",
+		`{"SKU":"a"} x`:        "unexpected data",
+		`{"SKU":"a"`:           "unexpected end",
+		`["SKU"]`:              "expected object",
+		`{"Tags":["a" "b"]}`:   "expected ','",
+		`{"Next":{"Qty":-1x}}`: "line 1",
+	} {
+		var item genItem
+		err := item.UnmarshalJSONTiny([]byte(input))
+		if err == nil || !Contains(err.Error(), want) {
+			t.Errorf("%q: expected %q, got %v", input, want, err)
+		}
+	}
+
+	r := NewJsonReader([]byte(`[0, -0, 255, 1e2, "AQI=", [3,4], null]`))
+	var got []uint64
+	if r.BeginArray() {
+		for i := 0; r.More(); i++ {
+			if i < 4 {
+				got = append(got, r.Uint(8))
+			} else if b := r.Bytes(); len(b) > 0 {
+				got = append(got, uint64(b[0]))
+			}
+		}
+	}
+	if err := r.Finish(); err != nil || len(got) != 6 || got[2] != 255 || got[3] != 100 || got[4] != 1 || got[5] != 3 {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
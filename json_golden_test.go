@@ -0,0 +1,131 @@
+package tinywodp
+
+import (
+	"os"
+	"testing"
+)
+
+// ============================================================================
+// CROSS-LANGUAGE GOLDEN CORPUS
+// ============================================================================
+// Fixtures in testdata/golden are produced by JSON.stringify (see golden.js).
+// Decoding them must yield the same values a browser would see, and for exact
+// fixtures tinywodp must encode the value back to identical bytes so that
+// JSON.parse on the client gets the same document.
+
+type goldenUnicode struct {
+	Name    string
+	Emoji   string
+	Cjk     string
+	Escaped string
+	Control string
+}
+
+type goldenNumbers struct {
+	MaxSafe  int64
+	MinSafe  int64
+	Zero     int
+	Half     float64
+	Negative float64
+	Big      float64
+	Tiny     float64
+}
+
+type goldenNode struct {
+	Name     string
+	Child    *goldenNode
+	Children []*goldenNode
+}
+
+// readGolden loads a fixture from testdata/golden
+func readGolden(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/golden/" + name + ".json")
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// checkGoldenEncode verifies that encoding v reproduces the fixture byte for byte
+func checkGoldenEncode(t *testing.T, fixture []byte, v any) {
+	t.Helper()
+	encoded, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if string(encoded) != string(fixture) {
+		t.Errorf("encode parity mismatch:\n got: %s\nwant: %s", string(encoded), string(fixture))
+	}
+}
+
+func TestGoldenUnicode(t *testing.T) {
+	clearRefStructsCache()
+	fixture := readGolden(t, "unicode")
+
+	expected := goldenUnicode{
+		Name:    "José Müller",
+		Emoji:   "😀 👍🏽",
+		Cjk:     "日本語テキスト",
+		Escaped: "line\nbreak \"quoted\" \\ tab\t",
+		Control: "\u0001\u001f",
+	}
+
+	var decoded goldenUnicode
+	if err := Unmarshal(fixture, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded != expected {
+		t.Errorf("decode parity mismatch:\n got: %+v\nwant: %+v", decoded, expected)
+	}
+
+	checkGoldenEncode(t, fixture, expected)
+}
+
+func TestGoldenNumbers(t *testing.T) {
+	clearRefStructsCache()
+	fixture := readGolden(t, "numbers")
+
+	expected := goldenNumbers{
+		MaxSafe:  9007199254740991,
+		MinSafe:  -9007199254740991,
+		Zero:     0,
+		Half:     0.5,
+		Negative: -2.25,
+		Big:      1e21,
+		Tiny:     5e-324,
+	}
+
+	var decoded goldenNumbers
+	if err := Unmarshal(fixture, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded != expected {
+		t.Errorf("decode parity mismatch:\n got: %+v\nwant: %+v", decoded, expected)
+	}
+
+	// Floats use the shortest round-trip digits, matching the fixture
+	checkGoldenEncode(t, fixture, expected)
+}
+
+func TestGoldenNulls(t *testing.T) {
+	clearRefStructsCache()
+	fixture := readGolden(t, "nulls")
+
+	var decoded goldenNode
+	if err := Unmarshal(fixture, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded.Name != "root" || decoded.Child != nil || len(decoded.Children) != 2 {
+		t.Fatalf("decode parity mismatch: %+v", decoded)
+	}
+	if decoded.Children[0] != nil {
+		t.Errorf("Children[0] should be nil, got %+v", decoded.Children[0])
+	}
+	leaf := decoded.Children[1]
+	if leaf == nil || leaf.Name != "leaf" || leaf.Child != nil || leaf.Children == nil || len(leaf.Children) != 0 {
+		t.Errorf("Children[1] mismatch: %+v", leaf)
+	}
+
+	checkGoldenEncode(t, fixture, decoded)
+}
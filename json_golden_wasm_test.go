@@ -0,0 +1,47 @@
+//go:build js && wasm
+
+package tinywodp
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+// Run with: GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/misc/wasm/go_js_wasm_exec" .
+// Compares tinywodp output against the JavaScript engine the code actually runs in,
+// rather than against the fixtures recorded on disk.
+func TestGoldenWasmStringifyParity(t *testing.T) {
+	clearRefStructsCache()
+	jsonObj := js.Global().Get("JSON")
+
+	for _, name := range []string{"unicode", "nulls"} {
+		t.Run(name, func(t *testing.T) {
+			fixture := readGolden(t, name)
+
+			// What the browser produces for the same document
+			stringified := jsonObj.Call("stringify", jsonObj.Call("parse", string(fixture))).String()
+
+			var encoded []byte
+			var err error
+			switch name {
+			case "unicode":
+				var v goldenUnicode
+				if err = Unmarshal(fixture, &v); err == nil {
+					encoded, err = Marshal(v)
+				}
+			case "nulls":
+				var v goldenNode
+				if err = Unmarshal(fixture, &v); err == nil {
+					encoded, err = Marshal(v)
+				}
+			}
+			if err != nil {
+				t.Fatalf("round trip returned error: %v", err)
+			}
+
+			if string(encoded) != stringified {
+				t.Errorf("JSON.stringify parity mismatch:\n got: %s\nwant: %s", string(encoded), stringified)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Decode hooks
+// Hooks take struct members the standard decoder would reject, or decode
+// differently, without forking the parser. Each member is offered to the
+// registered hooks in order before the field is decoded; the first one that
+// reports it handled wins, otherwise decoding carries on as usual:
+//
+//	tinywodp.RegisterDecodeHook(func(path, raw string, target any) (bool, error) {
+//		flag, ok := target.(*bool)
+//		if !ok || (raw != `"1"` && raw != `"0"`) {
+//			return false, nil
+//		}
+//		*flag = raw == `"1"`
+//		return true, nil
+//	})
+//
+// Hooks run for struct fields reached from JSON text, sealed fields aside, and
+// only slow decoding down while some are registered. On TinyGo a field is
+// offered only when its pointer type is otherwise used by the program, as for
+// JsonUnmarshaler.
+
+// DecodeHook converts one struct member. fieldPath is the Go path of the field
+// (Settings.Enabled, Items[2].Tags), raw the member's JSON text and target a
+// pointer to the field (*bool, *[]string). An error is reported at the field
+// like any other decoding error, and collected under CollectErrors.
+type DecodeHook func(fieldPath, raw string, target any) (handled bool, err error)
+
+// decodeHooks are the registered hooks, in registration order
+var decodeHooks []DecodeHook
+
+// RegisterDecodeHook adds hook after the ones already registered; nil removes
+// every hook
+// Not safe to call concurrently with decoding
+func RegisterDecodeHook(hook DecodeHook) {
+	if hook == nil {
+		decodeHooks = nil
+		return
+	}
+	decodeHooks = append(decodeHooks, hook)
+}
+
+// runDecodeHooks offers the member at s[i], decoded into the field name held
+// by target, to the registered hooks and reports whether one handled it
+func (jh *jsonH) runDecodeHooks(name, s string, i int, target *refValue) (int, bool, error) {
+	if target.ptr == nil || target.flag&flagAddr == 0 {
+		return i, false, nil
+	}
+	pt := refPtrTo(target.Type())
+	if pt == nil {
+		return i, false, nil
+	}
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return end, true, err
+	}
+
+	// jPath holds the path of the enclosing struct while hooks are registered
+	path := name
+	if len(jh.jPath) > 0 {
+		path = string(jh.jPath) + "." + name
+	}
+	ptr := refTypedAny(pt, target.ptr)
+	for _, hook := range decodeHooks {
+		handled, err := hook(path, s[i:end], ptr)
+		if err != nil && jh.jLenient {
+			// Collected at the field, like a value that failed to decode
+			jh.markJsonError(s, i)
+			jh.jErrors = append(jh.jErrors, FieldError{Path: path, Offset: jh.jErrAt, Err: jh.positionError(err)})
+			jh.jErrAt = -1
+			return end, true, nil
+		}
+		if err != nil {
+			jh.prefixErrorPath(name)
+			return i, true, jh.errorAt(s, i, err)
+		}
+		if handled {
+			return end, true, nil
+		}
+	}
+	return i, false, nil
+}
+
+// Encode hooks
+// The encoding side has a built-in directive and a registry of its own. A field
+// tagged tiny:"redact" is written as "***", whatever it holds, so payloads can
+// be logged without leaking secrets. Hooks transform other fields on the way
+// out, by type or by a directive of their own in the tiny tag:
+//
+//	tinywodp.RegisterEncodeHook(func(path, tag string, value any) (string, bool, error) {
+//		card, ok := value.(string)
+//		if !ok || tag != "mask" || len(card) < 4 {
+//			return "", false, nil
+//		}
+//		return `"…` + card[len(card)-4:] + `"`, true, nil
+//	})
+//
+// Redacted and hooked values replace the field's encoding as is: they are
+// neither quoted nor sealed. Hooks run in JsonEncode, JsonEncoder and JsEncode.
+
+// EncodeHook converts one struct field. fieldPath is the Go path of the field,
+// tag the text of its tiny tag, whose unknown directives are left for hooks,
+// and value a copy of the field. raw must be valid JSON; it is written as is.
+type EncodeHook func(fieldPath, tag string, value any) (raw string, handled bool, err error)
+
+// redactedJson replaces the value of fields tagged tiny:"redact"
+const redactedJson = `"***"`
+
+// encodeHooks are the registered hooks, in registration order
+var encodeHooks []EncodeHook
+
+// RegisterEncodeHook adds hook after the ones already registered; nil removes
+// every hook
+// Not safe to call concurrently with encoding
+func RegisterEncodeHook(hook EncodeHook) {
+	if hook == nil {
+		encodeHooks = nil
+		return
+	}
+	encodeHooks = append(encodeHooks, hook)
+}
+
+// encodeFieldHook returns the JSON text that replaces the field held by v,
+// reporting false when it is not redacted and no hook handles it
+// jPath must hold the path of the field while hooks are registered
+func (jh *jsonH) encodeFieldHook(field *planField, v *refValue) (string, bool, error) {
+	if field.redact {
+		return redactedJson, true, nil
+	}
+	if len(encodeHooks) == 0 {
+		return "", false, nil
+	}
+	value := v.Interface()
+	path := string(jh.jPath)
+	for _, hook := range encodeHooks {
+		raw, handled, err := hook(path, field.tag, value)
+		if err != nil {
+			return "", true, Err(err.Error(), "in", path)
+		}
+		if handled {
+			return raw, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// pushPathField sets jPath to the path cut at mark followed by a field name
+func (jh *jsonH) pushPathField(mark int, name string) {
+	jh.jPath = jh.jPath[:mark]
+	if mark > 0 {
+		jh.jPath = append(jh.jPath, '.')
+	}
+	jh.jPath = append(jh.jPath, name...)
+}
+
+// pushPathIndex sets jPath to the path cut at mark followed by an element index
+func (jh *jsonH) pushPathIndex(mark, index int) {
+	jh.jPath = append(jh.jPath[:mark], '[')
+	jh.jPath = append(jh.jPath, Convert(index).String()...)
+	jh.jPath = append(jh.jPath, ']')
+}
@@ -0,0 +1,180 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type hookSettings struct {
+	Enabled bool
+	Tags    []string
+	Limit   int
+}
+
+type hookDoc struct {
+	Name     string
+	Settings hookSettings
+	Items    []hookSettings
+}
+
+// registerTestHooks installs a "1"/"0" bool coercion and a comma list split,
+// recording the path of every member offered
+func registerTestHooks(t *testing.T, paths *[]string) {
+	RegisterDecodeHook(func(path, raw string, target any) (bool, error) {
+		*paths = append(*paths, path)
+		flag, ok := target.(*bool)
+		if !ok || (raw != `"1"` && raw != `"0"`) {
+			return false, nil
+		}
+		*flag = raw == `"1"`
+		return true, nil
+	})
+	RegisterDecodeHook(func(path, raw string, target any) (bool, error) {
+		list, ok := target.(*[]string)
+		if !ok || raw[0] != '"' {
+			return false, nil
+		}
+		var text string
+		if err := Convert(raw).JsonDecode(&text); err != nil {
+			return true, err
+		}
+		*list = Convert(text).Split(",")
+		return true, nil
+	})
+	RegisterDecodeHook(func(path, raw string, target any) (bool, error) {
+		if _, ok := target.(*int); ok && raw == "-1" {
+			return true, Err("limit must not be negative")
+		}
+		return false, nil
+	})
+	t.Cleanup(func() { RegisterDecodeHook(nil) })
+}
+
+func TestDecodeHooks(t *testing.T) {
+	clearRefStructsCache()
+
+	var paths []string
+	registerTestHooks(t, &paths)
+
+	var doc hookDoc
+	input := `{"Name":"x","Settings":{"Enabled":"1","Tags":"a,b"},"Items":[{"Enabled":true,"Tags":["c"]},{"Enabled":"0"}]}`
+	if err := Convert(input).JsonDecode(&doc); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if !doc.Settings.Enabled || len(doc.Settings.Tags) != 2 || doc.Settings.Tags[1] != "b" {
+		t.Errorf("Settings: got %+v", doc.Settings)
+	}
+	if len(doc.Items) != 2 || !doc.Items[0].Enabled || doc.Items[0].Tags[0] != "c" || doc.Items[1].Enabled {
+		t.Errorf("Items: got %+v", doc.Items)
+	}
+
+	want := []string{"Name", "Settings", "Settings.Enabled", "Settings.Tags", "Items", "Items[0].Enabled", "Items[0].Tags", "Items[1].Enabled"}
+	if len(paths) != len(want) {
+		t.Fatalf("paths: got %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("path %d: got %q, want %q", i, paths[i], want[i])
+		}
+	}
+}
+
+func TestDecodeHookErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	var paths []string
+	registerTestHooks(t, &paths)
+
+	var doc hookDoc
+	err := Convert(`{"Items":[{"Limit":1},{"Limit":-1}]}`).JsonDecode(&doc)
+	if err == nil || !Contains(err.Error(), "limit must not be negative") || !Contains(err.Error(), "Items[1].Limit") {
+		t.Errorf("expected the hook error at Items[1].Limit, got %v", err)
+	}
+
+	// Errors of the standard decoding keep their path while hooks are registered
+	err = Convert(`{"Settings":{"Limit":"x"}}`).JsonDecode(&doc)
+	if err == nil || !Contains(err.Error(), "Settings.Limit") {
+		t.Errorf("expected an error at Settings.Limit, got %v", err)
+	}
+
+	doc = hookDoc{}
+	err = Convert(`{"Name":"y","Items":[{"Limit":-1,"Enabled":"1"}]}`).JsonDecode(&doc, CollectErrors)
+	errs, ok := err.(DecodeErrors)
+	if !ok || len(errs) != 1 || errs[0].Path != "Items[0].Limit" {
+		t.Fatalf("expected one collected error at Items[0].Limit, got %v", err)
+	}
+	if doc.Name != "y" || len(doc.Items) != 1 || !doc.Items[0].Enabled {
+		t.Errorf("got %+v", doc)
+	}
+}
+
+type hookCard struct {
+	Holder   string
+	Number   string  `tiny:"mask"`
+	Password string  `tiny:"redact"`
+	Token    *string `tiny:"redact,omitempty"`
+}
+
+type hookAccount struct {
+	Cards []hookCard
+	Limit int
+}
+
+func TestEncodeHooks(t *testing.T) {
+	clearRefStructsCache()
+
+	account := hookAccount{Cards: []hookCard{{Holder: "ana", Number: "4111111111111111", Password: "hunter2"}}, Limit: 5}
+
+	// redact needs no hook
+	out, err := Convert(&account).JsonEncode()
+	expected := `{"Cards":[{"Holder":"ana","Number":"4111111111111111","Password":"***"}],"Limit":5}`
+	if err != nil || string(out) != expected {
+		t.Errorf("got  %s, %v\nwant %s", out, err, expected)
+	}
+
+	var paths []string
+	RegisterEncodeHook(func(path, tag string, value any) (string, bool, error) {
+		paths = append(paths, path)
+		if card, ok := value.(string); ok && tag == "mask" {
+			return `"…` + card[len(card)-4:] + `"`, true, nil
+		}
+		return "", false, nil
+	})
+	RegisterEncodeHook(func(path, tag string, value any) (string, bool, error) {
+		if n, ok := value.(int); ok && n < 0 {
+			return "", true, Err("negative limit")
+		}
+		return "", false, nil
+	})
+	t.Cleanup(func() { RegisterEncodeHook(nil) })
+
+	out, err = Convert(&account).JsonEncode()
+	expected = `{"Cards":[{"Holder":"ana","Number":"…1111","Password":"***"}],"Limit":5}`
+	if err != nil || string(out) != expected {
+		t.Errorf("got  %s, %v\nwant %s", out, err, expected)
+	}
+	want := []string{"Cards", "Cards[0].Holder", "Cards[0].Number", "Limit"}
+	if len(paths) != len(want) {
+		t.Fatalf("paths: got %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("path %d: got %q, want %q", i, paths[i], want[i])
+		}
+	}
+
+	var captured []byte
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		captured = append(captured, p...)
+		return len(p), nil
+	}})
+	if err := enc.Encode(&account); err != nil || string(captured) != expected+"\n" {
+		t.Errorf("Encode wrote %s, %v", captured, err)
+	}
+
+	account.Limit = -1
+	if _, err := Convert(&account).JsonEncode(); err == nil || !Contains(err.Error(), "negative limit") {
+		t.Errorf("expected the hook error, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package tinywodp
+
+// HTML-safe output
+// JSON embedded in a <script> element or an HTML attribute must not contain
+// "</script>", "<!--" or a stray &, and U+2028 and U+2029 end a line inside a
+// JavaScript string in older engines. With HTML escaping on, the strings the
+// encoders write carry <, >, &, U+2028 and U+2029 as \uXXXX escapes, like
+// encoding/json does by default:
+//
+//	SetEscapeHTML(true)
+//	data, _ := Marshal(Comment{Text: "</script>"}) // {"Text":"\u003c/script\u003e"}
+//
+// It is off by default so output stays byte for byte as compact as before.
+// The text returned by custom marshalers and RawJSON is written as is, and
+// struct field names are written as declared.
+
+// escapeHTML makes encoded strings escape HTML-significant characters
+var escapeHTML bool
+
+// SetEscapeHTML turns HTML escaping of encoded strings on or off for every
+// later encode; off by default. Not safe to call concurrently with encoding
+func SetEscapeHTML(escape bool) {
+	escapeHTML = escape
+}
+
+// SetEscapeHTML overrides HTML escaping for the values this encoder writes,
+// as encoding/json's Encoder.SetEscapeHTML
+func (e *JsonEncoder) SetEscapeHTML(escape bool) {
+	e.html, e.htmlSet = escape, true
+}
@@ -0,0 +1,60 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type htmlComment struct {
+	Text string
+	Tags []string
+}
+
+func TestSetEscapeHTML(t *testing.T) {
+	c := htmlComment{Text: "</script>&\u2028\u2029", Tags: []string{"<b>"}}
+
+	out, err := Marshal(c)
+	if err != nil || string(out) != "{\"Text\":\"</script>&\u2028\u2029\",\"Tags\":[\"<b>\"]}" {
+		t.Errorf("default output = %s, %v; expected no HTML escaping", out, err)
+	}
+
+	SetEscapeHTML(true)
+	defer SetEscapeHTML(false)
+	out, err = Marshal(c)
+	expected := `{"Text":"\u003c/script\u003e\u0026\u2028\u2029","Tags":["\u003cb\u003e"]}`
+	if err != nil || string(out) != expected {
+		t.Errorf("escaped output = %s, %v; expected %s", out, err, expected)
+	}
+
+	var back htmlComment
+	if err := Convert(out).JsonDecode(&back); err != nil || back.Text != c.Text || back.Tags[0] != "<b>" {
+		t.Errorf("round trip = %+v, %v", back, err)
+	}
+}
+
+func TestEscapeHTMLOptions(t *testing.T) {
+	c := htmlComment{Text: "a<b"}
+	opts := DefaultJsonOptions()
+	opts.EscapeHTML = true
+	if out, err := Convert(&c).JsonEncodeWith(opts); err != nil || !Contains(string(out), `a\u003cb`) {
+		t.Errorf("JsonEncodeWith(EscapeHTML) = %s, %v", out, err)
+	}
+
+	var written []byte
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}})
+	enc.SetEscapeHTML(true)
+	if err := enc.Encode(c); err != nil || !Contains(string(written), `a\u003cb`) {
+		t.Errorf("JsonEncoder.SetEscapeHTML = %s, %v", written, err)
+	}
+
+	// Canonical output never escapes HTML
+	SetEscapeHTML(true)
+	defer SetEscapeHTML(false)
+	if out, err := Convert(&c).JsonEncodeCanonical(); err != nil || !Contains(string(out), "a<b") {
+		t.Errorf("JsonEncodeCanonical = %s, %v", out, err)
+	}
+}
@@ -0,0 +1,105 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// HTTP handler helpers
+// One-line JSON request and response handling for net/http servers, written
+// against the package's private reader and writer interfaces so net/http is
+// never imported:
+//
+//	func createUser(w http.ResponseWriter, r *http.Request) {
+//		var in NewUser
+//		if err := ReadJSON(r.Body, &in, 1<<20); err != nil {
+//			w.Header().Set("Content-Type", JsonContentType)
+//			WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+//			return
+//		}
+//		w.Header().Set("Content-Type", JsonContentType)
+//		WriteJSON(w, http.StatusCreated, &user)
+//	}
+//
+// Headers cannot be reached without net/http's Header type, so set the
+// Content-Type before calling WriteJSON; otherwise net/http sniffs the body and
+// sends text/plain.
+
+// JsonContentType is the Content-Type of JSON responses
+const JsonContentType = "application/json; charset=utf-8"
+
+// statusWriter is the part of http.ResponseWriter that sends the status code
+type statusWriter interface {
+	writer
+	WriteHeader(statusCode int)
+}
+
+// WriteJSON encodes v and writes it to w with the given status code
+// The status is sent through w's WriteHeader method when it has one and status
+// is not 0. v is encoded in full before anything is written, so on an encoding
+// error w is untouched and the handler can still answer with an error status.
+func WriteJSON(w writer, status int, v any) error {
+	if w == nil {
+		return Err(errInvalidJSON, "writer cannot be nil")
+	}
+	c := Convert(v)
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+
+	if err := checkEncodeGraph(c, jh.jMaxDepth); err != nil {
+		return err
+	}
+	var err error
+	if jh.jOut, err = jh.appendJson(jh.jOut, c); err != nil {
+		return err
+	}
+
+	if sw, ok := w.(statusWriter); ok && status != 0 {
+		sw.WriteHeader(status)
+	}
+	_, err = w.Write(jh.jOut)
+	return err
+}
+
+// ReadJSON reads the whole of r and decodes it into target, failing with
+// ErrBodyTooLarge once more than maxBytes are read; maxBytes <= 0 means no limit
+// Like http.MaxBytesReader, reading stops as soon as the limit is passed, so an
+// oversized body is never buffered whole. The reader reaching its end (io.EOF)
+// is expected; any other read error is returned.
+func ReadJSON(r reader, target any, maxBytes int64, opts ...DecodeOption) error {
+	if r == nil {
+		return Err(errInvalidJSON, "reader cannot be nil")
+	}
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+
+	buf := allocBytes(decoderReadSize)
+	for {
+		if cap(buf)-len(buf) < decoderReadSize {
+			grown := allocBytes(2*cap(buf) + decoderReadSize)[:len(buf)]
+			copy(grown, buf)
+			freeHint(buf)
+			buf = grown
+		}
+		n, err := r.Read(buf[len(buf) : len(buf)+decoderReadSize])
+		buf = buf[:len(buf)+n]
+		if maxBytes > 0 && int64(len(buf)) > maxBytes {
+			freeHint(buf)
+			return Err(ErrBodyTooLarge, "limit is", Convert(maxBytes).String(), "bytes")
+		}
+		if err != nil {
+			if err.Error() != "EOF" { // io.EOF, recognised by its message so io is not imported
+				freeHint(buf)
+				return err
+			}
+			break
+		}
+	}
+	body := string(buf)
+	freeHint(buf)
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	return jh.decode(body, target)
+}
@@ -0,0 +1,110 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// testResponseWriter records what a handler sends, like httptest.ResponseRecorder
+type testResponseWriter struct {
+	status int
+	body   []byte
+}
+
+func (w *testResponseWriter) WriteHeader(statusCode int) { w.status = statusCode }
+
+func (w *testResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = 200
+	}
+	w.body = append(w.body, p...)
+	return len(p), nil
+}
+
+func TestWriteJSON(t *testing.T) {
+	clearRefStructsCache()
+
+	type User struct {
+		ID   int
+		Name string
+	}
+
+	w := &testResponseWriter{}
+	if err := WriteJSON(w, 201, &User{ID: 7, Name: "Ana"}); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if w.status != 201 || string(w.body) != `{"ID":7,"Name":"Ana"}` {
+		t.Errorf("got %d %s", w.status, w.body)
+	}
+
+	// Plain writers get the body only
+	var written []byte
+	plain := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if err := WriteJSON(plain, 200, []int{1, 2}); err != nil || string(written) != `[1,2]` {
+		t.Errorf("got %s, %v", written, err)
+	}
+
+	// A failed encoding leaves the response untouched
+	type Unsupported struct {
+		Data chan int
+	}
+	w = &testResponseWriter{}
+	if err := WriteJSON(w, 200, Unsupported{Data: make(chan int)}); err == nil {
+		t.Error("expected an encoding error")
+	}
+	if w.status != 0 || len(w.body) != 0 {
+		t.Errorf("response written on error: %d %s", w.status, w.body)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	clearRefStructsCache()
+
+	type Login struct {
+		User string
+		Pass string
+	}
+	body := `{"User":"ana","Pass":"secret"}`
+
+	for _, chunk := range []int{1, 7, 4096} {
+		var in Login
+		r := &testReader{data: body, chunk: chunk, eof: errTestEOF}
+		if err := ReadJSON(r, &in, int64(len(body))); err != nil {
+			t.Fatalf("chunk %d: %v", chunk, err)
+		}
+		if in.User != "ana" || in.Pass != "secret" {
+			t.Errorf("chunk %d: got %+v", chunk, in)
+		}
+	}
+
+	var in Login
+	r := &testReader{data: body, chunk: 5, eof: errTestEOF}
+	if err := ReadJSON(r, &in, int64(len(body))-1); err == nil || !Contains(err.Error(), string(ErrBodyTooLarge)) {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+
+	// No limit
+	text := make([]byte, 3*decoderReadSize)
+	for i := range text {
+		text[i] = 'x'
+	}
+	large := `["` + string(text) + `"]`
+	var items []string
+	if err := ReadJSON(&testReader{data: large, chunk: 1000, eof: errTestEOF}, &items, 0); err != nil || len(items[0]) != 3*decoderReadSize {
+		t.Errorf("unlimited read failed: %v", err)
+	}
+
+	// Read errors other than EOF are returned as is
+	broken := Err("connection reset")
+	if err := ReadJSON(&testReader{data: `{"User":`, chunk: 3, eof: broken}, &in, 0); err != broken {
+		t.Errorf("expected the reader error, got %v", err)
+	}
+
+	if err := ReadJSON(&testReader{data: `{"User":`, chunk: 3, eof: errTestEOF}, &in, 0); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
@@ -0,0 +1,579 @@
+//go:build js && wasm
+
+package tinywodp
+
+import (
+	"syscall/js"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// JavaScript value interop
+// In the browser, values handed to or received from JavaScript APIs usually
+// cross as JSON text that both sides parse again. JsEncode and JsDecode build
+// and read js.Value objects directly, walking the same struct metadata as the
+// JSON codec, so field names, naming convention and options carry over:
+//
+//	obj, err := JsEncode(&user)
+//	js.Global().Call("render", obj)
+//
+//	err := JsDecode(args[0], &form) // from a js.FuncOf callback
+//
+// Mapping, as JSON.parse would produce it from JsonEncode output:
+//
+//	struct, map[string]any -> Object
+//	slice, array           -> Array ([]byte -> Uint8Array, decoded from a base64 string too)
+//	string, bool           -> String, Boolean
+//	integer, float         -> Number (NaN and ±Inf pass through)
+//	nil pointer, slice     -> null (nil slices follow NilSliceEncoding)
+//
+// Types with their own JSON representation (time.Time, RawJSON, custom
+// marshalers, ",string" and encrypted fields) still go through their JSON text
+// for that one value. Decoding returns the first error; CollectErrors does not
+// apply.
+
+// JavaScript constructors used for conversion, looked up once
+var (
+	jsObject     = js.Global().Get("Object")
+	jsArray      = js.Global().Get("Array")
+	jsUint8Array = js.Global().Get("Uint8Array")
+	jsJSON       = js.Global().Get("JSON")
+)
+
+// JsEncode converts v into a JavaScript value
+//
+// Usage pattern:
+//
+//	obj, err := JsEncode(&cart)
+//	js.Global().Get("postMessage").Invoke(obj) // structured clone, no JSON text
+func JsEncode(v any) (js.Value, error) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+
+	rv := refValueOf(v)
+	if err := checkEncodeGraph(rv, jh.jMaxDepth); err != nil {
+		return js.Null(), err
+	}
+	return jh.jsEncodeValue(rv)
+}
+
+// jsEncodeValue converts one value, following the cases of appendJsonFieldValue
+func (jh *jsonH) jsEncodeValue(v *refValue) (js.Value, error) {
+	if v == nil || !v.refIsValid() {
+		return js.Null(), nil
+	}
+	if out, ok, err := encodeCustomJson(v); ok {
+		if err != nil {
+			return js.Null(), err
+		}
+		return jsonParseJs(out), nil
+	}
+	if stdEncodeHook != nil {
+		if out, ok, err := stdEncodeHook(v); ok {
+			if err != nil {
+				return js.Null(), err
+			}
+			return jsonParseJs(string(out)), nil
+		}
+	}
+
+	switch v.refKind() {
+	case tpString:
+		if isBigNumberType(v) {
+			return jsonParseJs(bigNumberLiteral(v.refString())), nil
+		}
+		return js.ValueOf(v.refString()), nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return js.ValueOf(v.refInt()), nil
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return js.ValueOf(v.refUint()), nil
+	case tpFloat32, tpFloat64:
+		f := v.refFloat()
+		if bits := floatBitSize(v); bits == 32 && f-f == 0 {
+			// The shortest float32 digits, as JsonEncode writes them: 0.1, not 0.10000000149011612
+			f, _ = parseJsonFloat(string(appendJsonFloat(nil, f, 32)), 64)
+		}
+		return js.ValueOf(f), nil
+	case tpBool:
+		return js.ValueOf(v.refBool()), nil
+	case tpSlice, tpArray:
+		return jh.jsEncodeSlice(v)
+	case tpStruct:
+		return jh.jsEncodeStruct(v)
+	case tpPointer:
+		elem := v.refElem()
+		if !elem.refIsValid() {
+			return js.Null(), nil
+		}
+		return jh.jsEncodeValue(elem)
+	case tpInterface:
+		inner := v.refInterfaceValue()
+		switch inner := inner.(type) {
+		case nil:
+			return js.Null(), nil
+		case map[string]any:
+			return jh.jsEncodeGenericObject(inner)
+		case []any:
+			arr := jsArray.New(len(inner))
+			for i, item := range inner {
+				value, err := jh.jsEncodeValue(refValueOf(item))
+				if err != nil {
+					return js.Null(), err
+				}
+				arr.SetIndex(i, value)
+			}
+			return arr, nil
+		}
+		return jh.jsEncodeValue(refValueOf(inner))
+	}
+	return js.Null(), unsupportedKindError("for JavaScript conversion", v.refKind())
+}
+
+// jsEncodeSlice converts a slice or array into an Array, or a byte slice into
+// a Uint8Array
+func (jh *jsonH) jsEncodeSlice(v *refValue) (js.Value, error) {
+	if isNilSlice(v) && jh.jNilSlice == NilSliceAsNull {
+		return js.Null(), nil
+	}
+	if isJsonByteSlice(v) {
+		var data []byte
+		if !isNilSlice(v) {
+			data = *(*[]byte)(v.ptr)
+		}
+		arr := jsUint8Array.New(len(data))
+		js.CopyBytesToJS(arr, data)
+		return arr, nil
+	}
+
+	n := v.refLen()
+	arr := jsArray.New(n)
+	mark := len(jh.jPath)
+	for i := range n {
+		if len(encodeHooks) > 0 {
+			jh.pushPathIndex(mark, i)
+		}
+		value, err := jh.jsEncodeValue(v.refIndex(i))
+		if err != nil {
+			return js.Null(), prefixKindPath(err, indexSegment(i))
+		}
+		arr.SetIndex(i, value)
+	}
+	jh.jPath = jh.jPath[:mark]
+	return arr, nil
+}
+
+// jsEncodeStruct converts a struct into an Object keyed like its JSON encoding
+func (jh *jsonH) jsEncodeStruct(v *refValue) (js.Value, error) {
+	plan, err := decodePlanFor(v)
+	if err != nil {
+		return js.Null(), err
+	}
+
+	obj := jsObject.New()
+	mark := len(jh.jPath)
+	for i := range v.refNumField() {
+		field := v.refField(i)
+		if !field.refIsValid() || plan.fields[i].omitted(field) {
+			continue
+		}
+		key := plan.fields[i].encodedName(jh.jNaming)
+
+		// Redacted fields and encode hooks replace the value
+		if len(encodeHooks) > 0 {
+			jh.pushPathField(mark, plan.fields[i].name)
+		}
+		if out, hooked, err := jh.encodeFieldHook(&plan.fields[i], field); hooked {
+			if err != nil {
+				return js.Null(), err
+			}
+			obj.Set(key, jsonParseJs(out))
+			continue
+		}
+
+		// Quoted and encrypted fields keep their JSON text form
+		quoted, sealed := plan.fields[i].quoted, plan.fields[i].secure
+		if quoted || sealed {
+			var out []byte
+			var err error
+			if quoted {
+				out, err = jh.appendJsonQuotedValue(nil, field)
+			} else {
+				out, err = jh.appendJsonFieldValue(nil, field)
+			}
+			if err != nil {
+				return js.Null(), prefixKindPath(err, plan.fields[i].name)
+			}
+			text := string(out)
+			if sealed {
+				if text, err = sealJsonField(text); err != nil {
+					return js.Null(), err
+				}
+			}
+			obj.Set(key, jsonParseJs(text))
+			continue
+		}
+
+		value, err := jh.jsEncodeValue(field)
+		if err != nil {
+			return js.Null(), prefixKindPath(err, plan.fields[i].name)
+		}
+		obj.Set(key, value)
+	}
+	jh.jPath = jh.jPath[:mark]
+	return obj, nil
+}
+
+// jsEncodeGenericObject converts a generic object held by an any value
+func (jh *jsonH) jsEncodeGenericObject(m map[string]any) (js.Value, error) {
+	obj := jsObject.New()
+	for key, item := range m {
+		value, err := jh.jsEncodeValue(refValueOf(item))
+		if err != nil {
+			return js.Null(), err
+		}
+		obj.Set(key, value)
+	}
+	return obj, nil
+}
+
+// JsDecode fills the value target points to from a JavaScript value
+// The options are those of JsonDecode. Like JSON decoding, object members
+// without a matching field are ignored and missing ones are left untouched.
+//
+// Usage pattern:
+//
+//	js.FuncOf(func(this js.Value, args []js.Value) any {
+//		var order Order
+//		if err := JsDecode(args[0], &order); err != nil {
+//			return err.Error()
+//		}
+//		...
+//	})
+func JsDecode(value js.Value, target any, opts ...DecodeOption) error {
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return Err(errInvalidJSON, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return Err(errInvalidJSON, "target pointer is nil or invalid")
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	if err := jh.jsDecodeValue(value, elem); err != nil {
+		jh.jErrAt = -1 // there is no input text to point into
+		return jh.positionError(err)
+	}
+	return nil
+}
+
+// jsDecodeValue stores value into target, following the cases of parseJsonValue
+func (jh *jsonH) jsDecodeValue(value js.Value, target *refValue) error {
+	if err := jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer jh.leaveJsonDepth()
+
+	if needsRawJson(target) || isBigNumberType(target) {
+		return jh.parseJsonValue(jsonStringifyJs(value), target)
+	}
+	if value.IsNull() || value.IsUndefined() {
+		return jh.parseJsonValue("null", target)
+	}
+
+	switch kind := target.refKind(); kind {
+	case tpString:
+		if value.Type() != js.TypeString {
+			return jsMismatch(value, "string")
+		}
+		target.refSetString(value.String())
+		return nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64, tpUint, tpUint8, tpUint16, tpUint32, tpUint64, tpFloat32, tpFloat64:
+		if value.Type() != js.TypeNumber {
+			return jsMismatch(value, "number")
+		}
+		f := value.Float()
+		if f-f != 0 && (kind == tpFloat32 || kind == tpFloat64) {
+			target.refSetFloat(f) // NaN and ±Inf have no JSON literal
+			return nil
+		}
+		// The JSON setters apply the same range and integer checks
+		return jh.parseJsonValue(string(appendJsonFloat(nil, f, 64)), target)
+	case tpBool:
+		if value.Type() != js.TypeBoolean {
+			return jsMismatch(value, "boolean")
+		}
+		target.refSetBool(value.Bool())
+		return nil
+	case tpStruct:
+		return jh.jsDecodeStruct(value, target)
+	case tpSlice:
+		return jh.jsDecodeSlice(value, target)
+	case tpArray:
+		return jh.jsDecodeArray(value, target)
+	case tpPointer:
+		return jh.jsDecodePointer(value, target)
+	case tpMap:
+		return jh.jsDecodeMap(value, target)
+	case tpInterface:
+		if !target.Type().isEmptyInterface() {
+			if pointee := target.refInterfacePointee(); pointee != nil {
+				return jh.jsDecodeValue(value, pointee)
+			}
+			return Err(errUnsupportedType, "for JavaScript conversion: only empty interface targets (any) or interfaces holding a pointer are supported")
+		}
+		generic, err := jh.jsGenericValue(value)
+		if err != nil {
+			return err
+		}
+		return target.refSetInterface(generic)
+	}
+	return unsupportedKindError("for JavaScript conversion", target.refKind())
+}
+
+// jsDecodeStruct stores the members of an Object into the matching fields
+func (jh *jsonH) jsDecodeStruct(value js.Value, target *refValue) error {
+	if value.Type() != js.TypeObject || isJsArray(value) {
+		return jsMismatch(value, "object")
+	}
+	plan, err := decodePlanFor(target)
+	if err != nil {
+		return err
+	}
+	if len(plan.defaults) > 0 {
+		if err := jh.applyJsonDefaults(target, plan); err != nil {
+			return err
+		}
+	}
+
+	keys := jsObject.Call("keys", value)
+	for k := range keys.Length() {
+		key := keys.Index(k).String()
+		fieldIndex := plan.fieldIndex(key, jh.jMatch)
+		if fieldIndex == -1 || plan.fields[fieldIndex].skipDecode {
+			continue
+		}
+		field := &plan.fields[fieldIndex]
+		fieldConv := target.refField(fieldIndex)
+		if !fieldConv.refIsValid() {
+			continue
+		}
+
+		member := value.Get(key)
+		if field.secure || field.quoted || field.setter != nil {
+			// The text form is what these fields are decoded from
+			if _, err := jh.parseJsonStructField(key, jsonStringifyJs(member), 0, target, plan); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := jh.jsDecodeValue(member, fieldConv); err != nil {
+			jh.prefixErrorPath(field.name)
+			return err
+		}
+	}
+	return nil
+}
+
+// jsDecodeSlice stores an Array into a slice; byte slices also accept a
+// Uint8Array or a base64 string
+func (jh *jsonH) jsDecodeSlice(value js.Value, target *refValue) error {
+	if isJsonByteSlice(target) {
+		switch {
+		case value.InstanceOf(jsUint8Array):
+			data := make([]byte, value.Length())
+			js.CopyBytesToGo(data, value)
+			*(*[]byte)(target.ptr) = data
+			return nil
+		case value.Type() == js.TypeString:
+			data, err := decodeBase64(value.String())
+			if err != nil {
+				return err
+			}
+			*(*[]byte)(target.ptr) = data
+			return nil
+		}
+	}
+	if !isJsArray(value) {
+		return jsMismatch(value, "array")
+	}
+
+	n := value.Length()
+	target.refSet(refMakeSlice(target.Type(), n, n))
+	for i := range n {
+		if err := jh.jsDecodeValue(value.Index(i), target.refIndex(i)); err != nil {
+			jh.prefixErrorPath("[" + Convert(i).String() + "]")
+			return err
+		}
+	}
+	return nil
+}
+
+// jsDecodeArray stores an Array into a fixed-size array
+// Like JSON decoding, extra elements are ignored and missing ones are zeroed
+func (jh *jsonH) jsDecodeArray(value js.Value, target *refValue) error {
+	if !isJsArray(value) {
+		return jsMismatch(value, "array")
+	}
+
+	n := value.Length()
+	for i := range target.refLen() {
+		elem := target.refIndex(i)
+		if i >= n {
+			memclr(elem.ptr, elem.Type().Size())
+			continue
+		}
+		if err := jh.jsDecodeValue(value.Index(i), elem); err != nil {
+			jh.prefixErrorPath("[" + Convert(i).String() + "]")
+			return err
+		}
+	}
+	return nil
+}
+
+// jsDecodePointer stores value into the element of a pointer target,
+// allocating it when the pointer is nil
+func (jh *jsonH) jsDecodePointer(value js.Value, target *refValue) error {
+	if elem := target.refElem(); elem.refIsValid() {
+		return jh.jsDecodeValue(value, elem)
+	}
+
+	elemType := target.Type().Elem()
+	if elemType == nil {
+		return Err(errUnsupportedType, "pointer element type is nil")
+	}
+	elemValue, err := refNewValue(elemType)
+	if err != nil {
+		return err
+	}
+	elemValue.separator = jh.jSep
+	if err := jh.jsDecodeValue(value, elemValue); err != nil {
+		return err
+	}
+	*(*unsafe.Pointer)(target.ptr) = elemValue.ptr
+	return nil
+}
+
+// jsDecodeMap stores the members of an Object into a map, with keys converted
+// as for JSON objects
+func (jh *jsonH) jsDecodeMap(value js.Value, target *refValue) error {
+	if value.Type() != js.TypeObject || isJsArray(value) {
+		return jsMismatch(value, "object")
+	}
+	mapType := target.Type()
+	keyType := mapType.mapKey()
+	elemType := mapType.mapElem()
+	if keyType == nil || elemType == nil {
+		return Err(ErrNoReflection, "map type information is missing")
+	}
+	switch keyType.Kind() {
+	case tpString, tpInt, tpInt8, tpInt16, tpInt32, tpInt64, tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+	default:
+		return Err(errUnsupportedType, "map key type: "+keyType.Kind().String())
+	}
+
+	if target.refMapIsNil() {
+		if err := target.refMakeMap(0); err != nil {
+			return err
+		}
+	}
+	keys := jsObject.Call("keys", value)
+	for k := range keys.Length() {
+		name := keys.Index(k).String()
+		key, err := jh.parseJsonMapKey(name, keyType)
+		if err != nil {
+			return err
+		}
+		elem, err := refNewValue(elemType)
+		if err != nil {
+			return err
+		}
+		if err := jh.jsDecodeValue(value.Get(name), elem); err != nil {
+			jh.prefixErrorPath(name)
+			return err
+		}
+		if err := target.refSetMapIndex(key, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsGenericValue converts value into the generic shapes of decoding into any
+func (jh *jsonH) jsGenericValue(value js.Value) (any, error) {
+	if err := jh.enterJsonDepth(); err != nil {
+		return nil, err
+	}
+	defer jh.leaveJsonDepth()
+
+	switch value.Type() {
+	case js.TypeNull, js.TypeUndefined:
+		return nil, nil
+	case js.TypeBoolean:
+		return value.Bool(), nil
+	case js.TypeString:
+		return value.String(), nil
+	case js.TypeNumber:
+		f := value.Float()
+		if f-f != 0 {
+			return f, nil
+		}
+		return jh.parseJsonGenericNumber(string(appendJsonFloat(nil, f, 64)))
+	case js.TypeObject:
+		if isJsArray(value) {
+			arr := make([]any, value.Length())
+			for i := range arr {
+				item, err := jh.jsGenericValue(value.Index(i))
+				if err != nil {
+					return nil, err
+				}
+				arr[i] = item
+			}
+			return arr, nil
+		}
+		obj := map[string]any{}
+		keys := jsObject.Call("keys", value)
+		for k := range keys.Length() {
+			key := keys.Index(k).String()
+			item, err := jh.jsGenericValue(value.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = item
+		}
+		return obj, nil
+	}
+	return nil, Err(errUnsupportedType, "for JavaScript conversion: "+value.Type().String())
+}
+
+// isJsArray reports whether value is a JavaScript Array
+func isJsArray(value js.Value) bool {
+	return jsArray.Call("isArray", value).Bool()
+}
+
+// jsMismatch reports a JavaScript value of the wrong type for the target
+func jsMismatch(value js.Value, expected string) error {
+	return Err(errInvalidJSON, "expected "+expected+" but got: "+value.Type().String())
+}
+
+// jsonParseJs parses JSON text produced by this package into a JavaScript value
+func jsonParseJs(text string) js.Value {
+	return jsJSON.Call("parse", text)
+}
+
+// jsonStringifyJs returns the JSON text of a JavaScript value; undefined and
+// functions, which have none, give null
+func jsonStringifyJs(value js.Value) string {
+	text := jsJSON.Call("stringify", value)
+	if text.Type() != js.TypeString {
+		return "null"
+	}
+	return text.String()
+}
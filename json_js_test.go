@@ -0,0 +1,112 @@
+//go:build js && wasm
+
+package tinywodp
+
+import (
+	"syscall/js"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Run with: GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/misc/wasm/go_js_wasm_exec" .
+
+type jsTestItem struct {
+	SKU   string
+	Qty   int
+	Price float32
+}
+
+type jsTestOrder struct {
+	ID     int64
+	Paid   bool
+	Items  []jsTestItem
+	Notes  *string
+	Blob   []byte
+	Extra  any
+	Coords [2]float64
+}
+
+func TestJsEncode(t *testing.T) {
+	clearRefStructsCache()
+
+	order := jsTestOrder{
+		ID:     42,
+		Paid:   true,
+		Items:  []jsTestItem{{SKU: "a-1", Qty: 2, Price: 0.1}},
+		Blob:   []byte{1, 2, 3},
+		Extra:  []any{"x", 1.5, nil},
+		Coords: [2]float64{1, -2},
+	}
+	obj, err := JsEncode(&order)
+	if err != nil {
+		t.Fatalf("JsEncode failed: %v", err)
+	}
+
+	// Everything but the Uint8Array matches what JSON.parse makes of JsonEncode
+	if blob := obj.Get("Blob"); !blob.InstanceOf(jsUint8Array) || blob.Length() != 3 || blob.Index(2).Int() != 3 {
+		t.Errorf("Blob: got %s", jsonStringifyJs(blob))
+	}
+	obj.Set("Blob", js.Null())
+	order.Blob = nil
+	SetNilSliceEncoding(NilSliceAsNull)
+	defer SetNilSliceEncoding(NilSliceAsEmpty)
+
+	expected, err := Convert(&order).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode failed: %v", err)
+	}
+	if got := jsonStringifyJs(obj); got != string(expected) {
+		t.Errorf("got  %s\nwant %s", got, expected)
+	}
+}
+
+func TestJsDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	value := jsonParseJs(`{"ID":7,"Paid":true,"Items":[{"SKU":"b","Qty":3,"Price":1.25}],"Notes":"leave at door","Extra":{"n":[1,null]},"Coords":[5],"Unknown":1}`)
+	value.Set("Blob", jsUint8Array.New(2))
+
+	order := jsTestOrder{Coords: [2]float64{9, 9}}
+	if err := JsDecode(value, &order); err != nil {
+		t.Fatalf("JsDecode failed: %v", err)
+	}
+	if order.ID != 7 || !order.Paid || len(order.Items) != 1 || order.Items[0] != (jsTestItem{SKU: "b", Qty: 3, Price: 1.25}) {
+		t.Errorf("got %+v", order)
+	}
+	if order.Notes == nil || *order.Notes != "leave at door" || len(order.Blob) != 2 || order.Coords != [2]float64{5, 0} {
+		t.Errorf("got %+v", order)
+	}
+	extra, _ := order.Extra.(map[string]any)
+	if n, _ := extra["n"].([]any); len(n) != 2 || n[0] != float64(1) || n[1] != nil {
+		t.Errorf("Extra: got %#v", order.Extra)
+	}
+
+	// Numbers are range checked like JSON numbers
+	var item jsTestItem
+	err := JsDecode(jsonParseJs(`[{"Qty":1.5}]`), &[]jsTestItem{})
+	if err == nil || !Contains(err.Error(), "[0].Qty") {
+		t.Errorf("expected a path to the bad element, got %v", err)
+	}
+	if err := JsDecode(jsonParseJs(`{"SKU":1}`), &item); err == nil {
+		t.Error("expected a type mismatch error")
+	}
+}
+
+func TestJsRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	notes := "fragile"
+	in := jsTestOrder{ID: 1 << 40, Items: []jsTestItem{{SKU: "z", Qty: -1, Price: 3.5}}, Notes: &notes, Blob: []byte("hi")}
+	obj, err := JsEncode(in)
+	if err != nil {
+		t.Fatalf("JsEncode failed: %v", err)
+	}
+	var out jsTestOrder
+	if err := JsDecode(obj, &out); err != nil {
+		t.Fatalf("JsDecode failed: %v", err)
+	}
+	if out.ID != in.ID || out.Items[0] != in.Items[0] || *out.Notes != notes || string(out.Blob) != "hi" {
+		t.Errorf("got %+v", out)
+	}
+}
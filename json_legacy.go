@@ -0,0 +1,133 @@
+package tinywodp
+
+// Legacy quoting
+// Some embedded devices, scraped pages and old systems emit JavaScript object
+// literals rather than JSON: single-quoted strings and bare keys.
+//
+//	{name: 'Ana', 'city': "O'Higgins", tags: ['a', 'b']}
+//
+//	err := Convert(payload).JsonDecode(&v, LegacySyntax)
+//
+// LegacySyntax rewrites both forms into standard JSON before decoding: a
+// single-quoted string becomes a double-quoted one, with \' unescaped and "
+// escaped, and a bare identifier followed by ':' becomes a quoted key. Values
+// are never left unquoted, so true, false and null keep their meaning. Lines
+// in error positions match the input, columns count the rewritten text.
+// Comments are copied unchanged, so LegacySyntax combines with RelaxedSyntax.
+
+// LegacySyntax makes a decode accept single-quoted strings and unquoted keys
+var LegacySyntax DecodeOption = legacyOption{}
+
+// legacyOption is the DecodeOption behind LegacySyntax
+type legacyOption struct{}
+
+// applyDecode enables legacy quoting for the operation
+func (legacyOption) applyDecode(jh *jsonH) {
+	jh.jLegacy = true
+}
+
+// rewriteJsonSyntax turns the non-standard forms the operation accepts into
+// JSON: legacy quoting first, then comments and trailing commas
+func (jh *jsonH) rewriteJsonSyntax(s string) string {
+	if jh.jLegacy {
+		s = quoteLegacyJson(s)
+	}
+	if jh.jRelaxed {
+		s = relaxJsonText(s)
+	}
+	return s
+}
+
+// quoteLegacyJson returns s with single-quoted strings and bare keys rewritten
+// as JSON strings; s itself when there is nothing to rewrite
+func quoteLegacyJson(s string) string {
+	var out []byte
+	copied := 0 // s[:copied] is already in out
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case b == '"':
+			end, err := skipJsonString(s, i)
+			if err != nil {
+				return string(append(out, s[copied:]...))
+			}
+			i = end - 1
+		case b == '/' && i+1 < len(s) && (s[i+1] == '/' || s[i+1] == '*'):
+			i = skipJsonComment(s, i) - 1
+		case b == '\'':
+			out = append(out, s[copied:i]...)
+			var end int
+			out, end = appendSingleQuoted(out, s, i)
+			copied, i = end, end-1
+		case isIdentStart(b):
+			end := i + 1
+			for end < len(s) && isIdentPart(s[end]) {
+				end++
+			}
+			if next := skipJsonSpace(s, end); next < len(s) && s[next] == ':' {
+				out = append(out, s[copied:i]...)
+				out = append(out, '"')
+				out = append(out, s[i:end]...)
+				out = append(out, '"')
+				copied = end
+			}
+			i = end - 1
+		}
+	}
+	if out == nil {
+		return s
+	}
+	return string(append(out, s[copied:]...))
+}
+
+// appendSingleQuoted appends the single-quoted string at s[i] to dst as a
+// JSON string and returns the index just past it; an unterminated string is
+// copied as is for the decoder to report
+func appendSingleQuoted(dst []byte, s string, i int) ([]byte, int) {
+	mark := len(dst)
+	dst = append(dst, '"')
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\'':
+			return append(dst, '"'), j + 1
+		case '"':
+			dst = append(dst, '\\', '"')
+		case '\\':
+			if j+1 < len(s) && s[j+1] == '\'' {
+				dst = append(dst, '\'')
+			} else if j+1 < len(s) {
+				dst = append(dst, s[j], s[j+1])
+			}
+			j++
+		default:
+			dst = append(dst, s[j])
+		}
+	}
+	return append(dst[:mark], s[i:]...), len(s)
+}
+
+// skipJsonComment returns the index just past the // or /* */ comment at s[i],
+// len(s) when it runs to the end
+func skipJsonComment(s string, i int) int {
+	if s[i+1] == '/' {
+		for i < len(s) && s[i] != '\n' {
+			i++
+		}
+		return i
+	}
+	for i += 2; i+1 < len(s); i++ {
+		if s[i] == '*' && s[i+1] == '/' {
+			return i + 2
+		}
+	}
+	return len(s)
+}
+
+// isIdentStart reports whether b can start a bare key, as in JavaScript
+func isIdentStart(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_' || b == '$'
+}
+
+// isIdentPart reports whether b can continue a bare key
+func isIdentPart(b byte) bool {
+	return isIdentStart(b) || b >= '0' && b <= '9'
+}
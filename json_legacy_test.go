@@ -0,0 +1,56 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestLegacySyntax(t *testing.T) {
+	type device struct {
+		Name  string
+		City  string
+		Tags  []string
+		On    bool
+		Extra any
+	}
+	input := `{Name: 'Ana', 'City': 'O\'Higgins "north"', Tags: ['a', "b"], On: true, $extra: null}`
+
+	var d device
+	if err := Convert(input).JsonDecode(&d, LegacySyntax); err != nil {
+		t.Fatalf("legacy decode returned error: %v", err)
+	}
+	if d.Name != "Ana" || d.City != `O'Higgins "north"` || len(d.Tags) != 2 || d.Tags[1] != "b" || !d.On {
+		t.Errorf("decoded %+v", d)
+	}
+
+	if err := Convert(input).JsonDecode(&d); err == nil || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("strict decode = %v, expected syntax error", err)
+	}
+
+	// Combined with RelaxedSyntax; quotes inside comments are left alone
+	var v map[string]any
+	relaxed := "{\n  // it's a comment\n  a: 'x', /* 'y' */\n}"
+	if err := Convert(relaxed).JsonDecode(&v, LegacySyntax, RelaxedSyntax); err != nil || v["a"] != "x" || len(v) != 1 {
+		t.Errorf("legacy relaxed decode = %v, %v", v, err)
+	}
+}
+
+func TestQuoteLegacyJson(t *testing.T) {
+	cases := []struct{ in, out string }{
+		{`{"a":1}`, `{"a":1}`},
+		{`{a:1, b_2 :true}`, `{"a":1, "b_2" :true}`},
+		{`['it\'s', '\n']`, `["it's", "\n"]`},
+		{`{"k": "a: 'b'"}`, `{"k": "a: 'b'"}`},
+		{`[1e5, null]`, `[1e5, null]`},
+		{`'open`, `'open`},
+	}
+	for _, tc := range cases {
+		if got := quoteLegacyJson(tc.in); got != tc.out {
+			t.Errorf("quoteLegacyJson(%s) = %s, expected %s", tc.in, got, tc.out)
+		}
+	}
+	if err := Convert(`{a: 'open}`).JsonCheck(LegacySyntax); err == nil {
+		t.Error("an unterminated single-quoted string should fail")
+	}
+}
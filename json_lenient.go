@@ -0,0 +1,165 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Lenient decoding
+// With CollectErrors a value that does not match its field type is left at its
+// zero value and decoding carries on with the next field. Every failure is
+// returned at the end, each with the Go path of the value that failed:
+//
+//	err := Convert(payload).JsonDecode(&user, CollectErrors)
+//	if errs, ok := err.(DecodeErrors); ok {
+//		for _, e := range errs {
+//			log(e.Path, e.Err) // Profile.Addresses[2].Coordinates.Latitude ...
+//		}
+//	}
+//
+// Malformed JSON, excessive nesting and stripped metadata still abort decoding.
+
+// CollectErrors makes decoding best-effort: field-level type mismatches are
+// collected into a DecodeErrors instead of stopping at the first one
+var CollectErrors DecodeOption = collectErrorsOption{}
+
+// collectErrorsOption is the DecodeOption behind CollectErrors
+type collectErrorsOption struct{}
+
+// applyDecode enables lenient decoding
+func (collectErrorsOption) applyDecode(jh *jsonH) {
+	jh.jLenient = true
+}
+
+// FieldError describes one value that could not be decoded
+type FieldError struct {
+	Path   string // Go path of the value, e.g. Profile.Addresses[2].City
+	Offset int    // byte offset of the value in the input, -1 when unknown
+	Err    error
+}
+
+// Error returns the path followed by the underlying error
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// DecodeErrors lists every value that failed in a CollectErrors decode,
+// in input order; all other values were decoded
+type DecodeErrors []FieldError
+
+// Error joins the failures into a single message
+func (e DecodeErrors) Error() string {
+	msg := Convert(len(e)).String() + " values failed to decode"
+	for _, fe := range e {
+		msg += "; " + fe.Error()
+	}
+	return msg
+}
+
+// parseJsonField decodes the value at s[i] into a struct field, named in error paths
+func (jh *jsonH) parseJsonField(name string, s string, i int, target *refValue) (int, error) {
+	if !jh.jLenient && len(decodeHooks) == 0 {
+		end, err := jh.parseJsonValueAt(s, i, target)
+		if err != nil {
+			jh.prefixErrorPath(name)
+		}
+		return end, err
+	}
+	mark := len(jh.jPath)
+	if mark > 0 {
+		jh.jPath = append(jh.jPath, '.')
+	}
+	jh.jPath = append(jh.jPath, name...)
+	return jh.parseJsonChild(mark, s, i, target)
+}
+
+// parseJsonElement decodes the value at s[i] into a slice or array element, indexed in error paths
+func (jh *jsonH) parseJsonElement(index int, s string, i int, target *refValue) (int, error) {
+	if !jh.jLenient && len(decodeHooks) == 0 {
+		end, err := jh.parseJsonValueAt(s, i, target)
+		if err != nil {
+			jh.prefixErrorPath("[" + Convert(index).String() + "]")
+		}
+		return end, err
+	}
+	mark := len(jh.jPath)
+	jh.jPath = append(jh.jPath, '[')
+	jh.jPath = append(jh.jPath, Convert(index).String()...)
+	jh.jPath = append(jh.jPath, ']')
+	return jh.parseJsonChild(mark, s, i, target)
+}
+
+// parseJsonMapValue decodes the value at s[i] into a map value, keyed in error paths
+func (jh *jsonH) parseJsonMapValue(key string, s string, i int, target *refValue) (int, error) {
+	if !jh.jLenient && len(decodeHooks) == 0 {
+		end, err := jh.parseJsonValueAt(s, i, target)
+		if err != nil {
+			jh.prefixErrorPath("[" + key + "]")
+		}
+		return end, err
+	}
+	mark := len(jh.jPath)
+	jh.jPath = append(jh.jPath, '[')
+	jh.jPath = append(jh.jPath, key...)
+	jh.jPath = append(jh.jPath, ']')
+	return jh.parseJsonChild(mark, s, i, target)
+}
+
+// prefixErrorPath adds the segment of an enclosing field, element or map value
+// to the path of a failure as it unwinds; without CollectErrors or decode hooks
+// no path is kept while decoding, so successful decodes never build one
+func (jh *jsonH) prefixErrorPath(segment string) {
+	switch {
+	case jh.jErrPath == "":
+		jh.jErrPath = segment
+	case jh.jErrPath[0] == '[':
+		jh.jErrPath = segment + jh.jErrPath
+	default:
+		jh.jErrPath = segment + "." + jh.jErrPath
+	}
+}
+
+// parseJsonChild decodes a nested value whose path ends at jPath, collecting
+// its error instead of returning it; the path is cut back to mark afterwards.
+// A failed value is skipped whole so decoding resumes after it, which keeps
+// malformed JSON fatal: it cannot be skipped. When the path is only kept for
+// decode hooks the error is returned, its path built as in a strict decode.
+func (jh *jsonH) parseJsonChild(mark int, s string, i int, target *refValue) (int, error) {
+	end, err := jh.parseJsonValueAt(s, i, target)
+	if err != nil && !jh.jLenient {
+		segment := string(jh.jPath[mark:])
+		if segment[0] == '.' {
+			segment = segment[1:]
+		}
+		jh.prefixErrorPath(segment)
+	} else if err != nil && !isFatalDecodeError(err) {
+		var skipErr error
+		if end, skipErr = jh.skipJsonValueAt(s, i); skipErr != nil {
+			err = skipErr
+		} else {
+			jh.jErrors = append(jh.jErrors, FieldError{Path: string(jh.jPath), Offset: jh.jErrAt, Err: jh.positionError(err)})
+			jh.jErrAt = -1 // the next failure records its own position
+			err = nil
+		}
+	}
+	jh.jPath = jh.jPath[:mark]
+	return end, err
+}
+
+// isFatalDecodeError reports whether err must stop a lenient decode
+func isFatalDecodeError(err error) bool {
+	msg := err.Error()
+	return Contains(msg, string(ErrMaxDepth)) || Contains(msg, string(ErrNoReflection))
+}
+
+// takeDecodeErrors returns the collected failures in input order and clears them
+func (jh *jsonH) takeDecodeErrors() error {
+	errs := DecodeErrors(jh.jErrors)
+	jh.jErrors = nil
+	// Keep input order even when nested failures were recorded out of order (insertion sort, lists are short)
+	for i := 1; i < len(errs); i++ {
+		for j := i; j > 0 && errs[j].Offset < errs[j-1].Offset; j-- {
+			errs[j], errs[j-1] = errs[j-1], errs[j]
+		}
+	}
+	return errs
+}
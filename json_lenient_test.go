@@ -0,0 +1,82 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonDecodeCollectErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	type Coordinates struct {
+		Latitude  float64
+		Longitude float64
+	}
+	type Place struct {
+		City        string
+		Coordinates Coordinates
+	}
+	type Profile struct {
+		Name      string
+		Age       int
+		Addresses []Place
+		Scores    map[string]int
+	}
+
+	input := `{
+		"Name": "ana",
+		"Age": "forty",
+		"Addresses": [
+			{"City": "Lima", "Coordinates": {"Latitude": -12.04, "Longitude": -77.03}},
+			{"City": 7},
+			{"City": "Quito", "Coordinates": {"Latitude": "north", "Longitude": -78.5}}
+		],
+		"Scores": {"math": 9, "art": true}
+	}`
+
+	var p Profile
+	err := Convert(input).JsonDecode(&p, CollectErrors)
+	errs, ok := err.(DecodeErrors)
+	if !ok {
+		t.Fatalf("JsonDecode error = %v (%T), expected DecodeErrors", err, err)
+	}
+
+	expected := []string{"Age", "Addresses[1].City", "Addresses[2].Coordinates.Latitude", "Scores[art]"}
+	if len(errs) != len(expected) {
+		t.Fatalf("got %d errors, expected %d: %v", len(errs), len(expected), errs)
+	}
+	for i, path := range expected {
+		if errs[i].Path != path {
+			t.Errorf("errs[%d].Path = %q, expected %q", i, errs[i].Path, path)
+		}
+		if errs[i].Offset < 0 || !Contains(errs[i].Err.Error(), "line") {
+			t.Errorf("errs[%d] should carry a position: %+v", i, errs[i])
+		}
+	}
+
+	// Everything else was decoded
+	if p.Name != "ana" || len(p.Addresses) != 3 || p.Addresses[2].City != "Quito" ||
+		p.Addresses[2].Coordinates.Longitude != -78.5 || p.Scores["math"] != 9 {
+		t.Errorf("partial decode = %+v", p)
+	}
+
+	// Without the option the first mismatch stops decoding
+	if err := Convert(input).JsonDecode(new(Profile)); err == nil {
+		t.Error("strict decode should fail")
+	} else if _, ok := err.(DecodeErrors); ok {
+		t.Error("strict decode should not return DecodeErrors")
+	}
+
+	// Malformed JSON still aborts
+	if err := Convert(`{"Name": "ana", "Age": }`).JsonDecode(new(Profile), CollectErrors); err == nil {
+		t.Error("malformed JSON should fail in lenient mode")
+	} else if _, ok := err.(DecodeErrors); ok {
+		t.Error("malformed JSON should not be reported as DecodeErrors")
+	}
+
+	// Clean input returns nil
+	if err := Convert(`{"Name":"x"}`).JsonDecode(new(Profile), CollectErrors); err != nil {
+		t.Errorf("clean lenient decode returned %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Decode limits
+// A server decoding client JSON can bound what one payload may cost before any
+// of it is decoded:
+//
+//	limits := DecodeLimits{MaxBytes: 1 << 20, MaxStringLen: 4096, MaxArrayLen: 1000, MaxObjectKeys: 100, MaxDepth: 32}
+//	if err := Convert(body).JsonDecode(&req, limits); err != nil {
+//		// 413 when Contains(err.Error(), string(ErrLimitExceeded)), else 400
+//	}
+//
+// When any limit is set the input is first scanned with the JsonCheck
+// validator, which counts as it goes, so a hostile payload is rejected before
+// a single slice, map or string is allocated for it. Zero fields are not
+// limited; MaxDepth overrides SetMaxDepth and fails with ErrMaxDepth, the others
+// fail with ErrLimitExceeded and the position of the offending value. String
+// lengths are counted in input bytes, escapes included, and keys count as
+// strings. Limits apply to JSON decoding only.
+
+// DecodeLimits bounds the size of decoded input; pass it as a DecodeOption
+type DecodeLimits struct {
+	MaxBytes      int // length of the whole input
+	MaxStringLen  int // bytes of one string or key, as written
+	MaxArrayLen   int // elements of one array
+	MaxObjectKeys int // members of one object
+	MaxDepth      int // nesting of objects and arrays
+}
+
+// applyDecode sets the limits for the operation
+func (l DecodeLimits) applyDecode(jh *jsonH) {
+	jh.jLimits = l
+	if l.MaxDepth > 0 {
+		jh.jMaxDepth = l.MaxDepth
+	}
+}
+
+// hasLimits reports whether the operation bounds its input
+func (jh *jsonH) hasLimits() bool {
+	return jh.jLimits != DecodeLimits{}
+}
+
+// checkJsonLimits rejects s when it passes one of the operation's limits
+// The whole value is validated on the way, as JsonCheck does
+func (jh *jsonH) checkJsonLimits(s string) error {
+	if limit := jh.jLimits.MaxBytes; limit > 0 && len(s) > limit {
+		return Err(ErrLimitExceeded, "input is", len(s), "bytes, limit is", limit)
+	}
+	return jh.checkJson(s)
+}
+
+// checkJsonCount fails once a container holds more than limit members
+func checkJsonCount(n, limit int, what string) error {
+	if limit > 0 && n > limit {
+		return Err(ErrLimitExceeded, "more than", limit, what)
+	}
+	return nil
+}
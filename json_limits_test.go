@@ -0,0 +1,68 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestDecodeLimits(t *testing.T) {
+	type item struct {
+		Name string
+		Tags []string
+	}
+	input := `{"Name":"abcdef","Tags":["x","y","z"]}`
+
+	var ok item
+	if err := Convert(input).JsonDecode(&ok, DecodeLimits{MaxBytes: len(input), MaxStringLen: 6, MaxArrayLen: 3, MaxObjectKeys: 2}); err != nil {
+		t.Fatalf("decode within limits returned error: %v", err)
+	}
+	if ok.Name != "abcdef" || len(ok.Tags) != 3 {
+		t.Errorf("decoded %+v", ok)
+	}
+
+	cases := []struct {
+		name   string
+		limits DecodeLimits
+		errTyp errorType
+	}{
+		{"bytes", DecodeLimits{MaxBytes: len(input) - 1}, ErrLimitExceeded},
+		{"string", DecodeLimits{MaxStringLen: 5}, ErrLimitExceeded},
+		{"key", DecodeLimits{MaxStringLen: 3}, ErrLimitExceeded},
+		{"array", DecodeLimits{MaxArrayLen: 2}, ErrLimitExceeded},
+		{"object", DecodeLimits{MaxObjectKeys: 1}, ErrLimitExceeded},
+		{"depth", DecodeLimits{MaxDepth: 1}, ErrMaxDepth},
+	}
+	for _, tc := range cases {
+		var v item
+		err := Convert(input).JsonDecode(&v, tc.limits)
+		if err == nil || !Contains(err.Error(), string(tc.errTyp)) {
+			t.Errorf("%s: err = %v, expected %s", tc.name, err, tc.errTyp)
+			continue
+		}
+		if v.Name != "" || v.Tags != nil {
+			t.Errorf("%s: target was written before the limit check: %+v", tc.name, v)
+		}
+	}
+
+	// The position of the offending value is reported
+	err := Convert("{\n\"Tags\": [\"x\", \"y\", \"z\"]}").JsonDecode(&ok, DecodeLimits{MaxArrayLen: 2})
+	if err == nil || !Contains(err.Error(), "line 2") {
+		t.Errorf("array limit err = %v, expected a position on line 2", err)
+	}
+
+	// Members the target has no field for count too
+	var skipped item
+	if err := Convert(`{"Unknown":[1,2,3]}`).JsonDecode(&skipped, DecodeLimits{MaxArrayLen: 2}); err == nil {
+		t.Error("an unknown member past the limit should fail")
+	}
+}
+
+func TestJsonCheckLimits(t *testing.T) {
+	if err := Convert(`[1,2,3]`).JsonCheck(DecodeLimits{MaxArrayLen: 3}); err != nil {
+		t.Errorf("JsonCheck within limits = %v", err)
+	}
+	if err := Convert(`[1,2,3]`).JsonCheck(DecodeLimits{MaxArrayLen: 2}); err == nil || !Contains(err.Error(), string(ErrLimitExceeded)) {
+		t.Errorf("JsonCheck past the limit = %v, expected ErrLimitExceeded", err)
+	}
+}
@@ -0,0 +1,233 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// JSON Lines (NDJSON)
+// One compact JSON value per line, the usual format of log pipelines and bulk
+// import/export. Lines are encoded and flushed as they are produced and read
+// back one at a time, so neither side holds the whole stream in memory.
+
+// JsonLineFunc receives each line of a JSON Lines stream
+// line counts from 1; decode stores the line's value into the target it is
+// given, so each line can pick its own type. A non-nil error stops decoding and
+// is returned by JsonDecodeLines unchanged.
+type JsonLineFunc func(line int, decode func(target any) error) error
+
+// JsonEncodeLines writes every element of slice (a slice, array, or pointer to
+// one) to w as a JSON value followed by a newline
+//
+// Usage pattern:
+//
+//	err := JsonEncodeLines(events, logFile)
+func JsonEncodeLines(slice any, w writer) error {
+	if w == nil {
+		return Err(errInvalidJSON, "encoder writer cannot be nil")
+	}
+	c := Convert(slice)
+	if c.vTpe == tpPointer {
+		c = c.refElem()
+	}
+	enc := NewJsonEncoder(w)
+	enc.jh = getJsonH("_")
+	defer func() {
+		putJsonH(enc.jh)
+		enc.jh = nil
+	}()
+
+	// []string is held directly by the converted value
+	if c.vTpe == tpStrSlice {
+		for _, s := range c.stringSliceVal {
+			enc.buf = enc.jh.quoteJsonString(enc.buf, s)
+			enc.buf = append(enc.buf, '\n')
+			if err := enc.maybeFlush(); err != nil {
+				return err
+			}
+		}
+		return enc.flush()
+	}
+
+	if !c.refIsValid() || (c.refKind() != tpSlice && c.refKind() != tpArray) {
+		return Err(errUnsupportedType, "JSON Lines need a slice or array")
+	}
+	if err := checkEncodeGraph(c, enc.jh.jMaxDepth); err != nil {
+		return err
+	}
+	for i := range c.refLen() {
+		if err := enc.encodeValue(c.refIndex(i)); err != nil {
+			return err
+		}
+		enc.buf = append(enc.buf, '\n')
+		if err := enc.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	return enc.flush()
+}
+
+// JsonDecodeLines reads a JSON Lines stream from r until it ends
+// target is either a pointer to a slice, replaced by one element per line, or a
+// JsonLineFunc called for each line. Blank lines are skipped and a value may
+// not span lines. The stream ending with io.EOF returns nil; any other reader
+// error is returned. Errors in a line name its number.
+//
+// Usage patterns:
+//
+//	var events []Event
+//	err := JsonDecodeLines(file, &events)
+//
+//	err := JsonDecodeLines(body, JsonLineFunc(func(line int, decode func(any) error) error {
+//		var e Event
+//		if err := decode(&e); err != nil {
+//			return err
+//		}
+//		return store.Insert(e)
+//	}))
+func JsonDecodeLines(r reader, target any, opts ...DecodeOption) error {
+	if r == nil {
+		return Err(errInvalidJSON, "decoder reader cannot be nil")
+	}
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+
+	fn, isFunc := target.(JsonLineFunc)
+	if f, ok := target.(func(int, func(any) error) error); ok {
+		fn, isFunc = f, true
+	}
+	var slice *refValue
+	if !isFunc {
+		rv := refValueOf(target)
+		if rv.refKind() != tpPointer {
+			return Err(errInvalidJSON, "target must be a pointer to a slice or a JsonLineFunc")
+		}
+		if slice = rv.refElem(); !slice.refIsValid() || slice.refKind() != tpSlice {
+			return Err(errInvalidJSON, "target must be a pointer to a slice or a JsonLineFunc")
+		}
+		slice.refSet(refMakeSlice(slice.Type(), 0, 0))
+	}
+
+	lr := jsonLineReader{r: r, buf: allocBytes(decoderReadSize)}
+	n := 0
+	for lineNo := 1; ; lineNo++ {
+		line, ok := lr.next()
+		if !ok {
+			break
+		}
+		text := string(line)
+		if isJsonBlank(text) {
+			continue
+		}
+
+		var err error
+		if isFunc {
+			err = fn(lineNo, func(v any) error {
+				jh := getJsonH("_")
+				defer putJsonH(jh)
+				jh.applyDecodeOptions(opts)
+				return jsonLineError(lineNo, jh.decode(text, v))
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if n == slice.refLen() {
+			if n == 0 {
+				slice.refSet(refMakeSlice(slice.Type(), jsonSliceMinCap, jsonSliceMinCap))
+			} else {
+				growJsonSlice(slice, n, nil)
+			}
+		}
+		if err = decodeJsonLine(text, slice.refIndex(n), opts); err != nil {
+			(*jsonSliceHeader)(slice.ptr).len = n
+			return jsonLineError(lineNo, err)
+		}
+		n++
+	}
+	if slice != nil && n > 0 {
+		(*jsonSliceHeader)(slice.ptr).len = n
+	}
+
+	if lr.err != nil && lr.err.Error() != "EOF" {
+		return lr.err
+	}
+	return nil
+}
+
+// decodeJsonLine decodes the text of one line into target
+func decodeJsonLine(text string, target *refValue, opts []DecodeOption) error {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	jh.jInput = text
+	if err := jh.parseJsonValueWithRefReflect(text, target); err != nil {
+		return jh.positionError(err)
+	}
+	jh.checkRequiredFields(text, target)
+	if len(jh.jErrors) > 0 {
+		return jh.takeDecodeErrors()
+	}
+	return nil
+}
+
+// jsonLineError prefixes a decode error with the number of its line
+func jsonLineError(lineNo int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return Err(errInvalidJSON, "line "+Convert(lineNo).String()+": "+err.Error())
+}
+
+// jsonLineReader splits a reader into lines, refilling its buffer in chunks
+// io.EOF is recognised by its message so io is not imported
+type jsonLineReader struct {
+	r     reader
+	buf   []byte // buf[start:] is unread input
+	start int    // first byte of the current line
+	scan  int    // next byte to look at for a newline
+	err   error  // reader error, reported once the buffer is drained
+}
+
+// next returns the next line without its newline, false once input is exhausted
+// The returned bytes are only valid until the following call
+func (lr *jsonLineReader) next() ([]byte, bool) {
+	for {
+		for ; lr.scan < len(lr.buf); lr.scan++ {
+			if lr.buf[lr.scan] == '\n' {
+				line := lr.buf[lr.start:lr.scan]
+				lr.scan++
+				lr.start = lr.scan
+				return line, true
+			}
+		}
+		if lr.err != nil {
+			if lr.start < len(lr.buf) {
+				// Last line without a trailing newline
+				line := lr.buf[lr.start:]
+				lr.start = len(lr.buf)
+				return line, true
+			}
+			return nil, false
+		}
+
+		// Move the partial line to the front, then read more after it
+		pending := copy(lr.buf, lr.buf[lr.start:])
+		lr.buf = lr.buf[:pending]
+		lr.scan -= lr.start
+		lr.start = 0
+		if cap(lr.buf)-pending < decoderReadSize {
+			grown := allocBytes(2*cap(lr.buf) + decoderReadSize)[:pending]
+			copy(grown, lr.buf)
+			freeHint(lr.buf)
+			lr.buf = grown
+		}
+		read, err := lr.r.Read(lr.buf[pending : pending+decoderReadSize])
+		lr.buf = lr.buf[:pending+read]
+		if err != nil {
+			lr.err = err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonEncodeLines(t *testing.T) {
+	clearRefStructsCache()
+
+	type Event struct {
+		ID   int
+		Msg  string
+		Tags []string
+	}
+
+	events := []Event{
+		{ID: 1, Msg: "start", Tags: []string{"a"}},
+		{ID: 2, Msg: "multi\nline"},
+		{ID: 3, Msg: "stop"},
+	}
+	expected := `{"ID":1,"Msg":"start","Tags":["a"]}` + "\n" +
+		`{"ID":2,"Msg":"multi\nline","Tags":[]}` + "\n" +
+		`{"ID":3,"Msg":"stop","Tags":[]}` + "\n"
+
+	for _, input := range []any{events, &events} {
+		var written []byte
+		w := &testWriter{writeFunc: func(p []byte) (int, error) {
+			written = append(written, p...)
+			return len(p), nil
+		}}
+		if err := JsonEncodeLines(input, w); err != nil {
+			t.Fatalf("JsonEncodeLines returned error: %v", err)
+		}
+		if string(written) != expected {
+			t.Errorf("JsonEncodeLines wrote %q, expected %q", written, expected)
+		}
+	}
+
+	var written []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if err := JsonEncodeLines([]string{"x", "y\"z"}, w); err != nil || string(written) != "\"x\"\n\"y\\\"z\"\n" {
+		t.Errorf("JsonEncodeLines([]string) wrote %q, %v", written, err)
+	}
+	if err := JsonEncodeLines(42, w); err == nil {
+		t.Error("JsonEncodeLines(42) should fail")
+	}
+}
+
+func TestJsonDecodeLines(t *testing.T) {
+	clearRefStructsCache()
+
+	type Event struct {
+		ID  int
+		Msg string
+	}
+
+	input := `{"ID":1,"Msg":"start"}` + "\r\n" +
+		"\n" +
+		`{"ID":2,"Msg":"multi\nline"}` + "\n" +
+		`  {"ID":3,"Msg":"stop"}` // no trailing newline
+
+	// Small chunks split lines across reads
+	events := []Event{{ID: 99}}
+	if err := JsonDecodeLines(&testReader{data: input, chunk: 5, eof: errTestEOF}, &events); err != nil {
+		t.Fatalf("JsonDecodeLines returned error: %v", err)
+	}
+	if len(events) != 3 || events[0].ID != 1 || events[1].Msg != "multi\nline" || events[2].Msg != "stop" {
+		t.Errorf("JsonDecodeLines = %+v", events)
+	}
+
+	var seen []int
+	err := JsonDecodeLines(&testReader{data: input, chunk: 64, eof: errTestEOF},
+		JsonLineFunc(func(line int, decode func(any) error) error {
+			var e Event
+			if err := decode(&e); err != nil {
+				return err
+			}
+			seen = append(seen, line, e.ID)
+			return nil
+		}))
+	if err != nil || len(seen) != 6 || seen[0] != 1 || seen[2] != 3 || seen[4] != 4 || seen[5] != 3 {
+		t.Errorf("JsonDecodeLines(callback) saw %v, %v", seen, err)
+	}
+
+	var empty []Event
+	if err := JsonDecodeLines(&testReader{data: "\n\n", chunk: 1, eof: errTestEOF}, &empty); err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("JsonDecodeLines(blank) = %#v, %v", empty, err)
+	}
+
+	// A value may not continue on the next line
+	var broken []Event
+	err = JsonDecodeLines(&testReader{data: "{\"ID\":1}\n{\"ID\":\n2}\n", chunk: 4, eof: errTestEOF}, &broken)
+	if err == nil || !Contains(err.Error(), "line 2") {
+		t.Errorf("JsonDecodeLines(split value) error = %v", err)
+	}
+	if len(broken) != 1 || broken[0].ID != 1 {
+		t.Errorf("lines before the error should be kept, got %+v", broken)
+	}
+
+	// Reader errors other than EOF are returned
+	failing := Err("disk failure")
+	if err := JsonDecodeLines(&testReader{data: `{"ID":1}`, chunk: 8, eof: failing}, &broken); err != failing {
+		t.Errorf("JsonDecodeLines reader error = %v, expected %v", err, failing)
+	}
+
+	var notSlice Event
+	if err := JsonDecodeLines(&testReader{data: `{}`, chunk: 2, eof: errTestEOF}, &notSlice); err == nil {
+		t.Error("JsonDecodeLines into a struct should fail")
+	}
+}
@@ -0,0 +1,170 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// JSON maps
+// Follows encoding/json key conversion rules: keys are always quoted in JSON,
+// string-kinded keys are used as-is and integer-kinded keys are parsed from the
+// quoted text. Values may be any decodable type, including structs and slices.
+//
+// Encoding writes keys in ascending byte order, as encoding/json does, so the
+// same map always encodes to the same bytes and a map[string]any decoded from
+// a payload can be written back out unchanged. A nil map is written as null.
+
+// parseJsonMapRef parses the JSON object at s[i] into a map using our custom reflection
+// A nil map is allocated; an existing map keeps its entries and gains new ones
+func (jh *jsonH) parseJsonMapRef(s string, i int, target *refValue) (int, error) {
+	if s[i] != '{' {
+		return jh.parseJsonMismatch(s, i, target, "object")
+	}
+
+	mapType := target.Type()
+	keyType := mapType.mapKey()
+	elemType := mapType.mapElem()
+	if keyType == nil || elemType == nil {
+		return i, Err(ErrNoReflection, "map type information is missing")
+	}
+
+	switch keyType.Kind() {
+	case tpString, tpInt, tpInt8, tpInt16, tpInt32, tpInt64, tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+	default:
+		return i, Err(errUnsupportedType, "map key type: "+keyType.Kind().String())
+	}
+
+	if target.refMapIsNil() {
+		if err := target.refMakeMap(0); err != nil {
+			return i, err
+		}
+	}
+
+	i, done, err := jh.openJsonContainer(s, i, '}')
+	for !done {
+		if err != nil {
+			return i, err
+		}
+		var jsonKey string
+		if jsonKey, i, err = jh.scanJsonKey(s, i); err != nil {
+			return i, err
+		}
+
+		var key, elem *refValue
+		if key, err = jh.parseJsonMapKey(jsonKey, keyType); err != nil {
+			return i, err
+		}
+		if elem, err = refNewValue(elemType); err != nil {
+			return i, err
+		}
+		if i, err = jh.parseJsonMapValue(jsonKey, s, i, elem); err != nil {
+			return i, Err(errInvalidJSON, "failed to parse map value for key "+jsonKey+": "+err.Error())
+		}
+
+		if err = target.refSetMapIndex(key, elem); err != nil {
+			return i, err
+		}
+		i, done, err = jh.nextJsonMember(s, i, '}')
+	}
+	return i, nil
+}
+
+// parseJsonMapKey converts an unquoted JSON object key into a value of keyType
+func (jh *jsonH) parseJsonMapKey(jsonKey string, keyType *refType) (*refValue, error) {
+	key, err := refNewValue(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyType.Kind() {
+	case tpString:
+		key.refSetString(jsonKey)
+		return key, nil
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		if len(jsonKey) > 0 && jsonKey[0] == '-' {
+			return nil, Err(errInvalidJSON, "invalid unsigned map key: "+jsonKey)
+		}
+	}
+
+	// Integer keys must be plain integer literals
+	if !isJsonNumber(jsonKey) || !isJsonIntegerLiteral(jsonKey) {
+		return nil, Err(errInvalidJSON, "invalid integer map key: "+jsonKey)
+	}
+	if keyType.Kind() == tpInt || keyType.Kind() == tpInt8 || keyType.Kind() == tpInt16 || keyType.Kind() == tpInt32 || keyType.Kind() == tpInt64 {
+		return key, jh.parseJsonIntRef(jsonKey, key)
+	}
+	return key, jh.parseJsonUintRef(jsonKey, key)
+}
+
+// appendJsonMap appends the map held by v as a JSON object
+func (jh *jsonH) appendJsonMap(dst []byte, v *refValue) ([]byte, error) {
+	v, err := addressableMap(v)
+	if err != nil {
+		return dst, err
+	}
+	if v.refMapIsNil() {
+		return append(dst, "null"...), nil
+	}
+
+	n := v.refMapLen()
+	names := make([]string, 0, n)
+	elems := make([]*refValue, 0, n)
+	err = v.refMapRange(func(key, elem *refValue) error {
+		name, err := jsonMapKeyText(key)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+		elems = append(elems, elem)
+		return nil
+	})
+	if err != nil {
+		return dst, err
+	}
+	// Insertion sort keeps the binary free of package sort
+	for j := 1; j < len(names); j++ {
+		for k := j; k > 0 && names[k] < names[k-1]; k-- {
+			names[k], names[k-1] = names[k-1], names[k]
+			elems[k], elems[k-1] = elems[k-1], elems[k]
+		}
+	}
+
+	dst = append(dst, '{')
+	for i, name := range names {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = jh.quoteJsonString(dst, name)
+		dst = append(dst, ':')
+		if dst, err = jh.appendJsonFieldValue(dst, elems[i]); err != nil {
+			return dst, prefixKindPath(err, name)
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// addressableMap returns v, or an addressable copy of it when v was reached
+// through an interface: the map primitives read the map header through v.ptr
+func addressableMap(v *refValue) (*refValue, error) {
+	if v.flag&flagAddr != 0 {
+		return v, nil
+	}
+	addr, err := refNewValue(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	addr.refSet(v)
+	return addr, nil
+}
+
+// jsonMapKeyText returns the JSON object key written for a map key
+func jsonMapKeyText(key *refValue) (string, error) {
+	switch key.refKind() {
+	case tpString:
+		return key.refString(), nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return Convert(key.refInt()).String(), nil
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return Convert(key.refUint()).String(), nil
+	}
+	return "", unsupportedKindError("for JSON map keys", key.refKind())
+}
@@ -0,0 +1,123 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonDecodeMapOfStructs(t *testing.T) {
+	clearRefStructsCache()
+
+	var result map[string]Address
+	err := Convert(`{"home": {"Street": "Main St", "City": "NYC"}, "work": {"Street": "Oak Ave"}}`).JsonDecode(&result)
+	if err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if len(result) != 2 || result["home"].City != "NYC" || result["work"].Street != "Oak Ave" {
+		t.Errorf("JsonDecode(map[string]Address) = %+v", result)
+	}
+}
+
+func TestJsonDecodeMapIntKeys(t *testing.T) {
+	var result map[int][]string
+	if err := Convert(`{"1": ["a", "b"], "-7": []}`).JsonDecode(&result); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if len(result) != 2 || len(result[1]) != 2 || result[1][1] != "b" || result[-7] == nil {
+		t.Errorf("JsonDecode(map[int][]string) = %v", result)
+	}
+
+	invalid := []string{`{"x": []}`, `{"1.5": []}`, `{"": []}`}
+	for _, input := range invalid {
+		var m map[int][]string
+		if err := Convert(input).JsonDecode(&m); err == nil {
+			t.Errorf("JsonDecode(%s) should reject non-integer key", input)
+		}
+	}
+
+	var unsigned map[uint16]int
+	if err := Convert(`{"-1": 1}`).JsonDecode(&unsigned); err == nil {
+		t.Error("JsonDecode should reject negative keys for unsigned maps")
+	}
+}
+
+func TestJsonDecodeMapMerge(t *testing.T) {
+	result := map[string]int{"keep": 1}
+	if err := Convert(`{"add": 2}`).JsonDecode(&result); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if result["keep"] != 1 || result["add"] != 2 {
+		t.Errorf("JsonDecode into existing map = %v", result)
+	}
+
+	var empty map[string]int
+	if err := Convert(`{}`).JsonDecode(&empty); err != nil || empty == nil {
+		t.Errorf("JsonDecode({}) = %v, %v; expected empty non-nil map", empty, err)
+	}
+}
+
+func TestJsonEncodeMap(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		Name string
+	}
+	type Inventory struct {
+		Counts map[int]uint
+		Items  map[string]Item
+		Empty  map[string]int
+	}
+
+	inv := Inventory{
+		Counts: map[int]uint{10: 1, -2: 3, 3: 0},
+		Items:  map[string]Item{"b": {Name: "bolt"}, "a": {Name: "anchor"}},
+	}
+	expected := `{"Counts":{"-2":3,"10":1,"3":0},"Items":{"a":{"Name":"anchor"},"b":{"Name":"bolt"}},"Empty":null}`
+	out, err := Marshal(inv)
+	if err != nil || string(out) != expected {
+		t.Errorf("Marshal = %s, %v; expected %s", out, err, expected)
+	}
+
+	var back Inventory
+	if err := Convert(out).JsonDecode(&back); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if back.Counts[-2] != 3 || back.Items["b"].Name != "bolt" || back.Empty != nil {
+		t.Errorf("round trip = %+v", back)
+	}
+
+	if _, err := Marshal(map[float64]int{1.5: 1}); err == nil {
+		t.Error("Marshal should reject float map keys")
+	}
+}
+
+func TestRefMapPrimitives(t *testing.T) {
+	m := map[string][]int{"a": {1}, "b": {2, 3}}
+	v := refValueOf(&m).refElem()
+	if v.refKind() != tpMap || v.refMapLen() != 2 {
+		t.Fatalf("kind %v, len %d", v.refKind(), v.refMapLen())
+	}
+
+	keys := ""
+	total := 0
+	err := v.refMapRange(func(key, elem *refValue) error {
+		keys += key.refString()
+		total += len(elem.Interface().([]int))
+		return nil
+	})
+	if err != nil || len(keys) != 2 || total != 3 {
+		t.Errorf("refMapRange visited keys %q holding %d ints, %v; want 2 keys, 3 ints", keys, total, err)
+	}
+
+	var nilMap map[string]int
+	nv := refValueOf(&nilMap).refElem()
+	visited := 0
+	err = nv.refMapRange(func(_, _ *refValue) error {
+		visited++
+		return nil
+	})
+	if visited != 0 || err != nil || nv.refMapLen() != 0 {
+		t.Errorf("nil map: visited %d, %v", visited, err)
+	}
+}
@@ -0,0 +1,101 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Package-level functions mirroring encoding/json
+// Lets projects swap the import path without rewriting call sites:
+//
+//	data, err := tinywodp.Marshal(user)      // was json.Marshal(user)
+//	err := tinywodp.Unmarshal(data, &user)   // was json.Unmarshal(data, &user)
+
+// Marshal returns the JSON encoding of v
+func Marshal(v any) ([]byte, error) {
+	return Convert(v).JsonEncode()
+}
+
+// MarshalIndent is like Marshal but applies indentation to format the output
+// Each JSON element begins on a new line starting with prefix followed by
+// one or more copies of indent according to the nesting depth
+func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return Convert(v).JsonEncodeIndent(prefix, indent)
+}
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v
+func Unmarshal(data []byte, v any) error {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	return jh.decode(string(data), v)
+}
+
+// appendIndentJson appends an indented form of the compact JSON src to dst
+// Whitespace outside strings is dropped and empty objects/arrays stay on one line
+func appendIndentJson(dst, src []byte, prefix, indent string) []byte {
+	depth := 0
+	inString := false
+	escaped := false
+
+	newline := func() {
+		dst = append(dst, '\n')
+		dst = append(dst, prefix...)
+		for range depth {
+			dst = append(dst, indent...)
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		b := src[i]
+
+		if inString {
+			dst = append(dst, b)
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			// Drop insignificant whitespace
+		case '"':
+			inString = true
+			dst = append(dst, b)
+		case '{', '[':
+			dst = append(dst, b)
+			// Keep empty containers compact
+			if next := nextNonSpace(src, i+1); next < len(src) && (src[next] == '}' || src[next] == ']') {
+				dst = append(dst, src[next])
+				i = next
+				continue
+			}
+			depth++
+			newline()
+		case '}', ']':
+			depth--
+			newline()
+			dst = append(dst, b)
+		case ',':
+			dst = append(dst, b)
+			newline()
+		case ':':
+			dst = append(dst, ':', ' ')
+		default:
+			dst = append(dst, b)
+		}
+	}
+	return dst
+}
+
+// nextNonSpace returns the index of the next non-whitespace byte at or after i
+func nextNonSpace(src []byte, i int) int {
+	for i < len(src) && isJsonSpace(src[i]) {
+		i++
+	}
+	return i
+}
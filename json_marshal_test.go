@@ -0,0 +1,61 @@
+package tinywodp
+
+import (
+	"testing"
+)
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+	original := GenerateSimplePersonData()
+
+	data, err := Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	var decoded Person
+	if err := Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+
+	if decoded.Id != original.Id || decoded.Name != original.Name || len(decoded.Addresses) != len(original.Addresses) {
+		t.Errorf("round trip mismatch: got %+v, expected %+v", decoded, original)
+	}
+
+	if err := Unmarshal(nil, &decoded); err == nil {
+		t.Error("Unmarshal(nil) should return error")
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	type Item struct {
+		Name  string
+		Tags  []string
+		Empty []string
+	}
+
+	data, err := MarshalIndent(Item{Name: "a {b}", Tags: []string{"x", "y"}, Empty: []string{}}, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent returned error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"Name\": \"a {b}\",\n" +
+		"  \"Tags\": [\n" +
+		"    \"x\",\n" +
+		"    \"y\"\n" +
+		"  ],\n" +
+		"  \"Empty\": []\n" +
+		"}"
+	if string(data) != expected {
+		t.Errorf("MarshalIndent =\n%s\nexpected\n%s", string(data), expected)
+	}
+}
+
+func TestAppendIndentJsonPrefix(t *testing.T) {
+	result := appendIndentJson(nil, []byte(`{"a":[1,2]}`), ">", "\t")
+	expected := "{\n>\t\"a\": [\n>\t\t1,\n>\t\t2\n>\t]\n>}"
+	if string(result) != expected {
+		t.Errorf("appendIndentJson = %q, expected %q", string(result), expected)
+	}
+}
@@ -0,0 +1,143 @@
+package tinywodp
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Custom marshalers
+// Types that control their own JSON representation (money, UUIDs, timestamps)
+// implement these interfaces; they are checked before reflection, without
+// pulling in encoding/json:
+//
+//	type Cents int64
+//
+//	func (c Cents) MarshalJSONTiny() ([]byte, error) {
+//		return []byte(Fmt(`"%d.%02d"`, int64(c)/100, int64(c)%100).String()), nil
+//	}
+//
+//	func (c *Cents) UnmarshalJSONTiny(data []byte) error { ... }
+//
+// MarshalJSONTiny may use either receiver; UnmarshalJSONTiny needs a pointer receiver.
+
+// JsonMarshaler is implemented by types that encode themselves to JSON
+type JsonMarshaler interface {
+	MarshalJSONTiny() ([]byte, error)
+}
+
+// JsonUnmarshaler is implemented by types that decode themselves from JSON
+// The data is the raw JSON value, including quotes for strings
+type JsonUnmarshaler interface {
+	UnmarshalJSONTiny(data []byte) error
+}
+
+// Per-type custom codec bits, probed once and cached
+const (
+	codecMarshal      uint8 = 1 << iota // T implements JsonMarshaler
+	codecMarshalPtr                     // *T implements JsonMarshaler
+	codecUnmarshalPtr                   // *T implements JsonUnmarshaler
+)
+
+// customCodecs caches the uint8 codec bits per *refType
+var customCodecs sync.Map
+
+// customCodecFor returns the custom codec bits for the type of v
+func customCodecFor(v *refValue) uint8 {
+	t := v.Type()
+	if t == nil || v.refKind() == tpInterface {
+		return 0
+	}
+
+	if bits, ok := customCodecs.Load(t); ok {
+		return bits.(uint8)
+	}
+
+	var bits uint8
+
+	// Type assertions only read the type word, so typed nil values are enough to probe
+	if _, ok := refTypedAny(t, nil).(JsonMarshaler); ok {
+		bits |= codecMarshal
+	}
+	if pt := refPtrTo(t); pt != nil {
+		probe := refTypedAny(pt, nil)
+		if _, ok := probe.(JsonMarshaler); ok {
+			bits |= codecMarshalPtr
+		}
+		if _, ok := probe.(JsonUnmarshaler); ok {
+			bits |= codecUnmarshalPtr
+		}
+	}
+
+	customCodecs.Store(t, bits)
+	return bits
+}
+
+// refTypedAny builds an interface value from a type descriptor and data word
+func refTypedAny(t *refType, data unsafe.Pointer) any {
+	var e any
+	words := (*[2]unsafe.Pointer)(unsafe.Pointer(&e))
+	words[0] = unsafe.Pointer(t)
+	words[1] = data
+	return e
+}
+
+// encodeCustomJson encodes v with its JsonMarshaler, reporting false when it has none
+// Built-in types with their own representation (time.Time, RawJSON) are handled here too
+func encodeCustomJson(v *refValue) (string, bool, error) {
+	if v == nil || !v.refIsValid() {
+		return "", false, nil
+	}
+	if isTimeType(v) {
+		t, _ := v.Interface().(time.Time)
+		return encodeJsonTime(t), true, nil
+	}
+	if isRawJSONType(v) && v.ptr != nil {
+		out, err := encodeJsonRaw(v)
+		return out, true, err
+	}
+	bits := customCodecFor(v)
+
+	var m JsonMarshaler
+	switch {
+	case bits&codecMarshal != 0:
+		m, _ = v.Interface().(JsonMarshaler)
+	case bits&codecMarshalPtr != 0 && v.ptr != nil && v.flag&flagAddr != 0:
+		m, _ = refTypedAny(refPtrTo(v.Type()), v.ptr).(JsonMarshaler)
+	}
+	if m == nil {
+		return "", false, nil
+	}
+
+	data, err := m.MarshalJSONTiny()
+	if err != nil {
+		return "", true, err
+	}
+	if len(data) == 0 {
+		return "", true, Err(errInvalidJSON, "MarshalJSONTiny returned no data")
+	}
+	return string(data), true, nil
+}
+
+// decodeCustomJson decodes into target with its JsonUnmarshaler, reporting false when it has none
+func decodeCustomJson(jsonStr string, target *refValue) (bool, error) {
+	if target == nil || target.ptr == nil || target.flag&flagAddr == 0 {
+		return false, nil
+	}
+	if isTimeType(target) {
+		return true, decodeJsonTime(jsonStr, target)
+	}
+	if isRawJSONType(target) {
+		return true, decodeJsonRaw(jsonStr, target)
+	}
+	if customCodecFor(target)&codecUnmarshalPtr == 0 {
+		return false, nil
+	}
+	u, ok := refTypedAny(refPtrTo(target.Type()), target.ptr).(JsonUnmarshaler)
+	if !ok {
+		return false, nil
+	}
+	return true, u.UnmarshalJSONTiny([]byte(jsonStr))
+}
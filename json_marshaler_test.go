@@ -0,0 +1,95 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// testCents encodes as a decimal string, value receiver marshal
+type testCents int64
+
+func (c testCents) MarshalJSONTiny() ([]byte, error) {
+	return []byte(Fmt(`"%d.%02d"`, int64(c)/100, int64(c)%100).String()), nil
+}
+
+func (c *testCents) UnmarshalJSONTiny(data []byte) error {
+	s := string(data)
+	if len(s) < 2 || s[0] != '"' {
+		return Err(errInvalidJSON, "cents must be a string")
+	}
+	var whole, frac int64
+	_, err := Sscanf(s[1:len(s)-1], "%d.%d", &whole, &frac)
+	if err != nil {
+		return err
+	}
+	*c = testCents(whole*100 + frac)
+	return nil
+}
+
+// testUUID uses pointer receivers for both directions
+type testUUID struct {
+	hi, lo uint32
+}
+
+func (u *testUUID) MarshalJSONTiny() ([]byte, error) {
+	return []byte(Fmt(`"%d-%d"`, u.hi, u.lo).String()), nil
+}
+
+func (u *testUUID) UnmarshalJSONTiny(data []byte) error {
+	if string(data) != `"42-7"` {
+		return Err(errInvalidJSON, "unexpected uuid "+string(data))
+	}
+	u.hi, u.lo = 42, 7
+	return nil
+}
+
+type testInvoice struct {
+	ID     testUUID
+	Total  testCents
+	Lines  []testCents
+	Refund *testCents
+}
+
+func TestJsonCustomMarshalers(t *testing.T) {
+	clearRefStructsCache()
+
+	refund := testCents(50)
+	invoice := testInvoice{ID: testUUID{42, 7}, Total: 1999, Lines: []testCents{1000, 999}, Refund: &refund}
+
+	// Encoding through a pointer keeps fields addressable for pointer receivers
+	encoded, err := Convert(&invoice).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	expected := `{"ID":"42-7","Total":"19.99","Lines":["10.00","9.99"],"Refund":"0.50"}`
+	if string(encoded) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", string(encoded), expected)
+	}
+
+	var decoded testInvoice
+	if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if decoded.ID != invoice.ID || decoded.Total != invoice.Total || len(decoded.Lines) != 2 || decoded.Lines[1] != 999 {
+		t.Errorf("round trip = %+v, expected %+v", decoded, invoice)
+	}
+	if decoded.Refund == nil || *decoded.Refund != refund {
+		t.Errorf("Refund = %v, expected %v", decoded.Refund, refund)
+	}
+
+	// Errors from the custom decoder are returned as-is
+	if err := Convert(`{"Total":12}`).JsonDecode(&decoded); err == nil || !Contains(err.Error(), "cents must be a string") {
+		t.Errorf("expected custom decoder error, got %v", err)
+	}
+}
+
+func TestJsonCustomUnmarshalerTopLevel(t *testing.T) {
+	var total testCents
+	if err := Unmarshal([]byte(` "3.05" `), &total); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if total != 305 {
+		t.Errorf("total = %d, expected 305", total)
+	}
+}
@@ -0,0 +1,116 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// JSON Merge Patch (RFC 7386)
+// A merge patch is a partial document: members replace the target's, null
+// removes them and objects merge recursively. It suits partial updates where
+// the patch reads like the resource itself:
+//
+//	patch, err := JsonMergeDiff(&before, &after) // {"status":"paid","note":null}
+//	err = JsonMergePatch(&order, patch)
+//
+// Values go through their JSON encoding, as for JsonDiff. Arrays are replaced
+// whole, and a removed member of a struct target decodes to its zero value, so
+// null and "unset" are the same thing once applied.
+
+// JsonMergeDiff returns the merge patch turning a into b
+// Equal values give {}; when a or b is not an object the patch is b itself.
+func JsonMergeDiff(a, b any) ([]byte, error) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+
+	from, err := jh.genericJson(a)
+	if err != nil {
+		return nil, err
+	}
+	to, err := jh.genericJson(b)
+	if err != nil {
+		return nil, err
+	}
+	if jh.jOut, err = jh.appendJsonFieldValue(jh.jOut[:0], refValueOf(mergeDiffJson(from, to))); err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, len(jh.jOut))
+	copy(result, jh.jOut)
+	return result, nil
+}
+
+// mergeDiffJson returns the merge patch turning from into to
+func mergeDiffJson(from, to any) any {
+	x, fromObject := from.(map[string]any)
+	y, toObject := to.(map[string]any)
+	if !fromObject || !toObject {
+		return to
+	}
+
+	patch := map[string]any{}
+	for key := range x {
+		if _, found := y[key]; !found {
+			patch[key] = nil
+		}
+	}
+	for key, value := range y {
+		old, found := x[key]
+		switch {
+		case !found:
+			patch[key] = value
+		case jsonValuesEqual(old, value):
+		default:
+			patch[key] = mergeDiffJson(old, value)
+		}
+	}
+	return patch
+}
+
+// JsonMergePatch applies the merge patch to the value target points to
+// Nothing is changed when the patch is malformed or the result does not decode
+// into target's type. The options apply to that final decode.
+//
+// Usage pattern:
+//
+//	err := JsonMergePatch(&user, requestBody) // PATCH /users/1
+func JsonMergePatch(target any, patch []byte, opts ...DecodeOption) error {
+	elem, err := patchTarget(target)
+	if err != nil {
+		return err
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	doc, err := jh.genericJson(target)
+	if err != nil {
+		return err
+	}
+	var changes any
+	if err := Convert(string(patch)).JsonDecode(&changes, UseNumber); err != nil {
+		return Err(errInvalidPatch, err.Error())
+	}
+	return jh.storeGenericJson(elem, mergePatchJson(doc, changes))
+}
+
+// mergePatchJson applies patch to doc as RFC 7386 describes and returns the
+// result; doc's objects are changed in place
+func mergePatchJson(doc, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		obj = map[string]any{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(obj, key)
+		} else {
+			obj[key] = mergePatchJson(obj[key], value)
+		}
+	}
+	return obj
+}
@@ -0,0 +1,100 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonMergePatchRFCExamples(t *testing.T) {
+	// RFC 7386 appendix A
+	tests := []struct {
+		doc, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var doc any
+		if err := Convert(tt.doc).JsonDecode(&doc); err != nil {
+			t.Fatalf("JsonDecode(%s): %v", tt.doc, err)
+		}
+		if err := JsonMergePatch(&doc, []byte(tt.patch)); err != nil {
+			t.Errorf("JsonMergePatch(%s, %s) returned error: %v", tt.doc, tt.patch, err)
+			continue
+		}
+		out, err := Marshal(doc)
+		if err != nil {
+			t.Errorf("Marshal after patch returned error: %v", err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("JsonMergePatch(%s, %s) = %s, expected %s", tt.doc, tt.patch, out, tt.expected)
+		}
+	}
+}
+
+func TestJsonMergeDiffRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	type Address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip"`
+	}
+	type User struct {
+		Name    string   `json:"name"`
+		Email   *string  `json:"email"`
+		Roles   []string `json:"roles"`
+		Address Address  `json:"address"`
+		Age     int      `json:"age"`
+	}
+
+	email := "ana@mail.io"
+	before := User{Name: "ana", Email: &email, Roles: []string{"a"}, Address: Address{City: "Lima", Zip: "15001"}, Age: 30}
+	after := User{Name: "ana", Roles: []string{"a", "b"}, Address: Address{City: "Cusco", Zip: "15001"}, Age: 31}
+
+	patch, err := JsonMergeDiff(&before, &after)
+	if err != nil {
+		t.Fatalf("JsonMergeDiff returned error: %v", err)
+	}
+	expected := `{"address":{"city":"Cusco"},"age":31,"email":null,"roles":["a","b"]}`
+	if string(patch) != expected {
+		t.Errorf("JsonMergeDiff = %s, expected %s", patch, expected)
+	}
+
+	if err := JsonMergePatch(&before, patch); err != nil {
+		t.Fatalf("JsonMergePatch returned error: %v", err)
+	}
+	if before.Email != nil || before.Age != 31 || before.Address.City != "Cusco" ||
+		before.Address.Zip != "15001" || len(before.Roles) != 2 || before.Name != "ana" {
+		t.Errorf("JsonMergePatch result = %+v", before)
+	}
+
+	if same, err := JsonMergeDiff(&after, &after); err != nil || string(same) != "{}" {
+		t.Errorf("JsonMergeDiff of equal values = %s, %v", same, err)
+	}
+
+	// Removing a plain field zeroes it
+	if err := JsonMergePatch(&before, []byte(`{"name":null}`)); err != nil || before.Name != "" || before.Age != 31 {
+		t.Errorf("JsonMergePatch null = %+v, %v", before, err)
+	}
+	if err := JsonMergePatch(&before, []byte(`{"age":"old"}`)); err == nil || before.Age != 31 {
+		t.Errorf("JsonMergePatch type mismatch = %v, age %d", err, before.Age)
+	}
+	if err := JsonMergePatch(&before, []byte(`{"age":`)); err == nil {
+		t.Error("JsonMergePatch with malformed patch should fail")
+	}
+}
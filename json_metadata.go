@@ -0,0 +1,95 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Reflection metadata checks
+// Some TinyGo configurations (-no-debug, -panic=trap, aggressive stripping) drop
+// the type names and struct layout our custom reflection reads. Walking a struct
+// without that metadata would write through bogus offsets, so every struct path
+// validates the metadata once and fails with ErrNoReflection instead.
+//
+// Register and PreloadTypes run the same check up front, so a stripped build
+// fails at startup. Types with a generated codec (tinywodp-gen emits
+// MarshalJSONTiny and UnmarshalJSONTiny) never read their metadata and pass:
+//
+//	//tinywodp:json
+//	type Order struct { ... }
+//
+//	if err := tinywodp.Register("Order", &Order{}); err != nil {
+//		log.Fatal(err) // ErrNoReflection: generate a codec for the type
+//	}
+
+// checkStructMetadata verifies that struct type information is complete enough
+// to map JSON keys to fields. Returns ErrNoReflection when it is not.
+func checkStructMetadata(structInfo *refStructType, numFields int) error {
+	if structInfo == nil || structInfo.refType == nil {
+		return Err(ErrNoReflection, "struct type information is missing")
+	}
+
+	// A struct with fields but no field info means names were stripped
+	if numFields > 0 && len(structInfo.fields) == 0 {
+		return Err(ErrNoReflection, "struct field information is missing")
+	}
+
+	if len(structInfo.fields) != numFields {
+		return Err(ErrNoReflection, "struct field count mismatch: "+Convert(len(structInfo.fields)).String()+" != "+Convert(numFields).String())
+	}
+
+	for _, field := range structInfo.fields {
+		if field.name == "" {
+			return Err(ErrNoReflection, "struct field names were stripped")
+		}
+	}
+	return nil
+}
+
+// structMetadataFor resolves struct type information for a value and validates it
+// Used by encode and decode entry points before touching any field
+func structMetadataFor(v *refValue, structInfo *refStructType) error {
+	getStructType(v.Type(), structInfo)
+	return checkStructMetadata(structInfo, v.refNumField())
+}
+
+// hasGeneratedCodec reports whether the type of v encodes and decodes itself
+// through JsonMarshaler and JsonUnmarshaler, as tinywodp-gen output does, so
+// neither direction walks its fields
+func hasGeneratedCodec(v *refValue) bool {
+	bits := customCodecFor(v)
+	return bits&(codecMarshal|codecMarshalPtr) != 0 && bits&codecUnmarshalPtr != 0
+}
+
+// isCodecSample reports whether sample itself implements both codec interfaces
+// Asserting on the sample finds pointer receivers on TinyGo too, where refPtrTo
+// is not available to hasGeneratedCodec
+func isCodecSample(sample any) bool {
+	_, marshals := sample.(JsonMarshaler)
+	_, unmarshals := sample.(JsonUnmarshaler)
+	return marshals && unmarshals
+}
+
+// checkTypeMetadata validates the struct metadata of the type of sample when it
+// is registered or preloaded; pointers are checked through their element type
+func checkTypeMetadata(sample any) error {
+	if isCodecSample(sample) {
+		return nil
+	}
+
+	t := refValueOf(sample).Type()
+	if t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+	if t.Kind() != tpStruct {
+		return nil
+	}
+	zero, err := refNewValue(t)
+	if err != nil {
+		return err
+	}
+	if hasGeneratedCodec(zero) {
+		return nil
+	}
+	var structInfo refStructType
+	return structMetadataFor(zero, &structInfo)
+}
@@ -0,0 +1,68 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestCheckStructMetadataMissing(t *testing.T) {
+	// Empty struct info simulates a build where getStructType found nothing
+	var structInfo refStructType
+	err := checkStructMetadata(&structInfo, 2)
+	if err == nil {
+		t.Fatal("checkStructMetadata should fail when struct info is missing")
+	}
+	if !Contains(err.Error(), string(ErrNoReflection)) {
+		t.Errorf("expected ErrNoReflection, got: %v", err)
+	}
+
+	if err := checkStructMetadata(nil, 0); err == nil {
+		t.Error("checkStructMetadata(nil) should fail")
+	}
+}
+
+func TestCheckStructMetadataAvailable(t *testing.T) {
+	clearRefStructsCache()
+
+	person := GenerateSimplePersonData()
+	var structInfo refStructType
+	if err := structMetadataFor(refValueOf(person), &structInfo); err != nil {
+		t.Fatalf("structMetadataFor(Person) returned error: %v", err)
+	}
+	if len(structInfo.fields) != 6 {
+		t.Errorf("expected 6 fields, got %d", len(structInfo.fields))
+	}
+}
+
+func TestCheckTypeMetadataAtRegistration(t *testing.T) {
+	clearRefStructsCache()
+	resetWarmup()
+	defer resetWarmup()
+
+	if err := checkTypeMetadata(Person{}); err != nil {
+		t.Errorf("checkTypeMetadata(Person) = %v", err)
+	}
+	if err := checkTypeMetadata(42); err != nil {
+		t.Errorf("checkTypeMetadata(int) = %v, non-structs need no metadata", err)
+	}
+
+	// Generated codecs are detected from the sample and from the type
+	if !isCodecSample(&genItem{}) || isCodecSample(genItem{}) || isCodecSample(&Person{}) {
+		t.Error("isCodecSample should only accept values implementing both codec interfaces")
+	}
+	zero, err := refNewValue(refValueOf(genItem{}).Type())
+	if err != nil || !hasGeneratedCodec(zero) {
+		t.Errorf("hasGeneratedCodec(genItem) = false, %v", err)
+	}
+
+	if err := Register("Item", &genItem{}); err != nil {
+		t.Fatalf("Register(genItem) = %v", err)
+	}
+	if err := PreloadTypes(&genItem{}); err != nil {
+		t.Errorf("PreloadTypes(genItem) = %v", err)
+	}
+	if report := Warmup(); !report.Ready() || report.Checked != 1 {
+		t.Errorf("Warmup = %+v, err %v", report, report.Err())
+	}
+}
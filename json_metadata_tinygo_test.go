@@ -0,0 +1,64 @@
+//go:build tinygo
+
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Build matrix for stripped TinyGo builds:
+//
+//	tinygo test -no-debug .
+//	tinygo test -no-debug -panic=trap .
+//
+// Whatever metadata survives, encoding and decoding must either round-trip
+// correctly or fail with ErrNoReflection - never write through bad offsets.
+func TestTinyGoStrippedMetadataRoundTrip(t *testing.T) {
+	person := GenerateSimplePersonData()
+
+	jsonBytes, err := Convert(person).JsonEncode()
+	if err != nil {
+		if !Contains(err.Error(), string(ErrNoReflection)) {
+			t.Fatalf("expected ErrNoReflection on stripped build, got: %v", err)
+		}
+		t.Skip("reflection metadata stripped in this build")
+	}
+
+	var decoded Person
+	if err := Convert(string(jsonBytes)).JsonDecode(&decoded); err != nil {
+		if !Contains(err.Error(), string(ErrNoReflection)) {
+			t.Fatalf("expected ErrNoReflection on stripped build, got: %v", err)
+		}
+		t.Skip("reflection metadata stripped in this build")
+	}
+
+	if decoded.Id != person.Id || decoded.Name != person.Name {
+		t.Errorf("round trip mismatch: got %s/%s, expected %s/%s", decoded.Id, decoded.Name, person.Id, person.Name)
+	}
+}
+
+// Types with a generated codec never read their struct metadata, so they
+// register and round-trip in every build
+func TestTinyGoStrippedMetadataGeneratedCodec(t *testing.T) {
+	resetWarmup()
+	defer resetWarmup()
+
+	if err := Register("Item", &genItem{}); err != nil {
+		t.Fatalf("Register(genItem) = %v", err)
+	}
+	if err := Register("Person", &Person{}); err != nil && !Contains(err.Error(), string(ErrNoReflection)) {
+		t.Fatalf("Register(Person) = %v, expected nil or ErrNoReflection", err)
+	}
+
+	item := &genItem{SKU: "a", Qty: 2}
+	data, err := item.MarshalJSONTiny()
+	if err != nil {
+		t.Fatalf("MarshalJSONTiny returned error: %v", err)
+	}
+	var decoded genItem
+	if err := Convert(string(data)).JsonDecode(&decoded); err != nil || decoded.SKU != "a" || decoded.Qty != 2 {
+		t.Errorf("JsonDecode(genItem) = %+v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,188 @@
+package tinywodp
+
+import (
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Mock data generation
+// Fills any struct with plausible, deterministic values chosen from field names
+// (or a `mock:"kind"` tag), using the same reflection layer as the codec.
+// Useful for tests, demos and benchmarks without hand-written fixtures.
+//
+//	user := GenerateMock[ComplexUser](42)
+//
+//	type Contact struct {
+//		Primary string `mock:"email"` // force a hint when the name is ambiguous
+//	}
+//
+// Supported hints: email, phone, url, name, date, id, city, country, street,
+// zip, lat, lng. Maps are left nil and pointers are allocated up to mockMaxDepth.
+
+// mockMaxDepth limits recursion through nested structs and pointers
+const mockMaxDepth = 8
+
+// GenerateMock returns a T filled with mock values; the same seed yields the same value
+func GenerateMock[T any](seed int64) T {
+	var v T
+	m := mockGen{state: uint64(seed)*0x9E3779B97F4A7C15 + 1}
+	m.fill(refValueOf(&v).refElem(), "", 0)
+	return v
+}
+
+// mockGen is a small xorshift generator, deterministic across platforms
+type mockGen struct {
+	state uint64
+}
+
+// next returns the next pseudo-random number
+func (m *mockGen) next() uint64 {
+	m.state ^= m.state << 13
+	m.state ^= m.state >> 7
+	m.state ^= m.state << 17
+	return m.state
+}
+
+// intn returns a number in [0, n)
+func (m *mockGen) intn(n int) int {
+	return int(m.next() % uint64(n))
+}
+
+// pick returns a random element of list
+func (m *mockGen) pick(list []string) string {
+	return list[m.intn(len(list))]
+}
+
+var (
+	mockFirstNames = []string{"Ana", "John", "Mei", "Lucas", "Fatima", "Olga", "Diego", "Amara"}
+	mockLastNames  = []string{"Silva", "Doe", "Chen", "Müller", "Haddad", "Petrova", "Rojas", "Okafor"}
+	mockCities     = []string{"Lisbon", "Santiago", "Osaka", "Nairobi", "Toronto", "Berlin"}
+	mockCountries  = []string{"PT", "CL", "JP", "KE", "CA", "DE"}
+	mockStreets    = []string{"Main Street", "Oak Avenue", "Harbor Road", "Pine Lane"}
+	mockWords      = []string{"alpha", "bravo", "delta", "echo", "nova", "orbit", "pixel", "quartz"}
+)
+
+// fill assigns a mock value to v based on its kind and the field name hint
+func (m *mockGen) fill(v *refValue, name string, depth int) {
+	if v == nil || !v.refIsValid() || depth > mockMaxDepth {
+		return
+	}
+	hint := Convert(name).ToLower().String()
+
+	switch v.refKind() {
+	case tpString:
+		v.refSetString(m.mockString(hint))
+	case tpInt, tpInt16, tpInt32, tpInt64:
+		v.refSetInt(int64(m.mockInt(hint)))
+	case tpInt8:
+		v.refSetInt(int64(m.intn(100)))
+	case tpUint, tpUint16, tpUint32, tpUint64:
+		v.refSetUint(uint64(m.mockInt(hint)))
+	case tpUint8:
+		v.refSetUint(uint64(m.intn(200)))
+	case tpFloat32, tpFloat64:
+		v.refSetFloat(m.mockFloat(hint))
+	case tpBool:
+		v.refSetBool(m.intn(2) == 1)
+	case tpStruct:
+		if isTimeType(v) {
+			*(*time.Time)(v.ptr) = time.Unix(1262304000+int64(m.intn(15*365*24*3600)), 0).UTC()
+			return
+		}
+		m.fillStruct(v, depth)
+	case tpSlice:
+		n := 1 + m.intn(3)
+		v.refSet(refMakeSlice(v.Type(), n, n))
+		for i := range n {
+			m.fill(v.refIndex(i), name, depth+1)
+		}
+	case tpArray:
+		for i := range v.refLen() {
+			m.fill(v.refIndex(i), name, depth+1)
+		}
+	case tpPointer:
+		if depth >= mockMaxDepth-1 {
+			return // leave nil to keep recursive types finite
+		}
+		elem, err := refNewValue(v.Type().Elem())
+		if err != nil {
+			return
+		}
+		m.fill(elem, name, depth+1)
+		*(*unsafe.Pointer)(v.ptr) = elem.ptr
+	}
+}
+
+// fillStruct fills every field of a struct using its name or mock tag as hint
+func (m *mockGen) fillStruct(v *refValue, depth int) {
+	var structInfo refStructType
+	if structMetadataFor(v, &structInfo) != nil {
+		return
+	}
+	for i := range v.refNumField() {
+		if !isExportedField(structInfo.fields[i].name) {
+			continue
+		}
+		hint := structInfo.fields[i].tag.Get("mock")
+		if hint == "" {
+			hint = structInfo.fields[i].name
+		}
+		m.fill(v.refField(i), hint, depth+1)
+	}
+}
+
+// mockString returns a plausible string for the hint
+func (m *mockGen) mockString(hint string) string {
+	n := m.intn(10000)
+	switch {
+	case Contains(hint, "email") || Contains(hint, "mail"):
+		return Fmt("%s.%d@example.com", Convert(m.pick(mockFirstNames)).ToLower().String(), n).String()
+	case Contains(hint, "phone") || Contains(hint, "number"):
+		return Fmt("+1-555-%03d-%04d", m.intn(1000), n).String()
+	case Contains(hint, "url") || Contains(hint, "link") || Contains(hint, "avatar"):
+		return Fmt("https://example.com/%s/%d", m.pick(mockWords), n).String()
+	case Contains(hint, "date") || Contains(hint, "time") || Contains(hint, "login") || hint == "createdat" || hint == "updatedat":
+		return Fmt("20%02d-%02d-%02dT%02d:%02d:00Z", 10+m.intn(15), 1+m.intn(12), 1+m.intn(28), m.intn(24), m.intn(60)).String()
+	case hint == "id" || Contains(hint, "_id") || (len(hint) > 2 && hint[len(hint)-2:] == "id"):
+		return Fmt("id_%06d", m.intn(1000000)).String()
+	case Contains(hint, "firstname") || Contains(hint, "first_name"):
+		return m.pick(mockFirstNames)
+	case Contains(hint, "lastname") || Contains(hint, "last_name"):
+		return m.pick(mockLastNames)
+	case Contains(hint, "name"):
+		return m.pick(mockFirstNames) + " " + m.pick(mockLastNames)
+	case Contains(hint, "city"):
+		return m.pick(mockCities)
+	case Contains(hint, "country"):
+		return m.pick(mockCountries)
+	case Contains(hint, "street") || Contains(hint, "address"):
+		return Fmt("%d %s", 1+m.intn(999), m.pick(mockStreets)).String()
+	case Contains(hint, "zip") || Contains(hint, "postal"):
+		return Fmt("%05d", n).String()
+	}
+	return m.pick(mockWords)
+}
+
+// mockInt returns a plausible integer for the hint
+func (m *mockGen) mockInt(hint string) int {
+	switch {
+	case Contains(hint, "count") || Contains(hint, "views") || Contains(hint, "used"):
+		return m.intn(100000)
+	case Contains(hint, "age"):
+		return 18 + m.intn(70)
+	}
+	return m.intn(100)
+}
+
+// mockFloat returns a plausible float for the hint
+func (m *mockGen) mockFloat(hint string) float64 {
+	switch {
+	case Contains(hint, "lat"):
+		return float64(m.intn(180000))/1000 - 90
+	case Contains(hint, "lng") || Contains(hint, "lon"):
+		return float64(m.intn(360000))/1000 - 180
+	}
+	return float64(m.intn(10000)) / 100
+}
@@ -0,0 +1,73 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestGenerateMockDeterministic(t *testing.T) {
+	clearRefStructsCache()
+
+	a := GenerateMock[ComplexUser](7)
+	b := GenerateMock[ComplexUser](7)
+	c := GenerateMock[ComplexUser](8)
+
+	encodedA, _ := Marshal(a)
+	encodedB, _ := Marshal(b)
+	encodedC, _ := Marshal(c)
+	if string(encodedA) != string(encodedB) {
+		t.Error("same seed should generate identical values")
+	}
+	if string(encodedA) == string(encodedC) {
+		t.Error("different seeds should generate different values")
+	}
+}
+
+func TestGenerateMockPlausibleValues(t *testing.T) {
+	clearRefStructsCache()
+	user := GenerateMock[ComplexUser](1)
+
+	if !Contains(user.Email, "@example.com") {
+		t.Errorf("Email = %q, expected an email address", user.Email)
+	}
+	if user.ID == "" || user.Username == "" {
+		t.Errorf("ID/Username should be filled: %q/%q", user.ID, user.Username)
+	}
+	if len(user.Profile.PhoneNumbers) == 0 || !Contains(user.Profile.PhoneNumbers[0].Number, "+1-555-") {
+		t.Errorf("PhoneNumbers = %+v, expected phone numbers", user.Profile.PhoneNumbers)
+	}
+	for _, addr := range user.Profile.Addresses {
+		if addr.Coordinates == nil {
+			t.Fatal("Coordinates pointer should be allocated")
+		}
+		if addr.Coordinates.Latitude < -90 || addr.Coordinates.Latitude > 90 {
+			t.Errorf("Latitude %v out of range", addr.Coordinates.Latitude)
+		}
+		if addr.Coordinates.Longitude < -180 || addr.Coordinates.Longitude > 180 {
+			t.Errorf("Longitude %v out of range", addr.Coordinates.Longitude)
+		}
+	}
+
+	type Tagged struct {
+		Primary string `mock:"email"`
+	}
+	if tagged := GenerateMock[Tagged](3); !Contains(tagged.Primary, "@") {
+		t.Errorf("mock tag ignored: %q", tagged.Primary)
+	}
+}
+
+func TestGenerateMockRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+	original := GenerateMock[ComplexUser](99)
+
+	encoded, err := Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	var decoded ComplexUser
+	if err := Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	validateNestedStructures(t, original, decoded)
+}
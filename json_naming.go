@@ -0,0 +1,127 @@
+package tinywodp
+
+import (
+	"sync"
+)
+
+// Field naming conventions
+// Encoding writes Go field names as declared (PascalCase) unless another
+// convention is selected; decoding accepts the three forms of a field name
+// automatically, so payloads from JS (camelCase) and Python/Rust (snake_case)
+// backends decode without tags:
+//
+//	tinywodp.SetFieldNaming(tinywodp.FieldNamingSnake)
+//	out, err := Convert(&user).JsonEncode() // {"user_name":"ana","user_id":7}
+
+// FieldNaming selects how struct field names are written when encoding
+type FieldNaming uint8
+
+const (
+	// FieldNamingPascal writes Go field names as declared: UserName (default)
+	FieldNamingPascal FieldNaming = iota
+	// FieldNamingSnake writes snake_case names: user_name, user_id
+	FieldNamingSnake
+	// FieldNamingCamel writes camelCase names: userName, userID
+	FieldNamingCamel
+)
+
+// fieldNaming is the active naming convention for encoding
+var fieldNaming = FieldNamingPascal
+
+// SetFieldNaming changes the naming convention used when encoding
+// Not safe to call concurrently with encoding
+func SetFieldNaming(n FieldNaming) {
+	fieldNaming = n
+}
+
+// fieldNamesKey identifies the encoded names of a struct type under a convention
+type fieldNamesKey struct {
+	t      *refType
+	naming FieldNaming
+}
+
+// encodedFieldNames caches a []string of quoted JSON keys per fieldNamesKey
+var encodedFieldNames sync.Map
+
+// jsonFieldKeys returns the quoted JSON keys of the fields of the struct held by
+// v under naming, ready to append before each ':'; computed once per type
+// A tiny name= key is written as is, whatever the convention
+func jsonFieldKeys(v *refValue, plan *decodePlan, naming FieldNaming) []string {
+	key := fieldNamesKey{t: v.Type(), naming: naming}
+	if keys, ok := encodedFieldNames.Load(key); ok {
+		return keys.([]string)
+	}
+
+	keys := make([]string, len(plan.fields))
+	for i := range plan.fields {
+		keys[i] = string(appendQuoteJsonString(nil, plan.fields[i].encodedName(naming)))
+	}
+	stored, _ := encodedFieldNames.LoadOrStore(key, keys)
+	return stored.([]string)
+}
+
+// encodedName returns the key the encoders write for the field under naming
+func (f *planField) encodedName(naming FieldNaming) string {
+	if f.key != "" {
+		return f.key
+	}
+	if f.jsonName != "" {
+		return f.jsonName
+	}
+	return namedField(f.name, naming)
+}
+
+// taggedFieldName returns the key written for a struct field by the encoders
+// that read its tags directly: the tiny name=, else the json tag name, else the
+// Go name in the naming convention, the same order as encodedName
+func taggedFieldName(name, tinyTag, jsonTag string, naming FieldNaming) string {
+	if key := parseTinyTag(tinyTag).name; key != "" {
+		return key
+	}
+	if key := jsonTagName(jsonTag); key != "" {
+		return key
+	}
+	return namedField(name, naming)
+}
+
+// namedField returns the Go field name written in the naming convention
+func namedField(name string, naming FieldNaming) string {
+	switch naming {
+	case FieldNamingSnake:
+		return toSnakeCase(name)
+	case FieldNamingCamel:
+		return toCamelCase(name)
+	}
+	return name
+}
+
+// toCamelCase converts PascalCase to camelCase, lowering a leading acronym
+// as a whole: UserName -> userName, ID -> id, URLPath -> urlPath
+func toCamelCase(s string) string {
+	n := 0
+	for n < len(s) && isUpperASCII(s[n]) {
+		n++
+	}
+	if n == 0 {
+		return s
+	}
+	// Keep the capital that starts the next word: URLPath -> url + Path
+	if n > 1 && n < len(s) && isLowerASCII(s[n]) {
+		n--
+	}
+	b := []byte(s)
+	for i := 0; i < n; i++ {
+		b[i] += 'a' - 'A'
+	}
+	return string(b)
+}
+
+// isUpperASCII reports whether b is an ASCII capital letter
+func isUpperASCII(b byte) bool {
+	return 'A' <= b && b <= 'Z'
+}
+
+// isLowerASCII reports whether b is an ASCII lower-case letter
+func isLowerASCII(b byte) bool {
+	return 'a' <= b && b <= 'z'
+}
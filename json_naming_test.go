@@ -0,0 +1,84 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestFieldNameConversions(t *testing.T) {
+	tests := []struct {
+		name, snake, camel string
+	}{
+		{"UserName", "user_name", "userName"},
+		{"ID", "id", "id"},
+		{"UserID", "user_id", "userID"},
+		{"HTTPServer", "http_server", "httpServer"},
+		{"URLPath2", "url_path2", "urlPath2"},
+		{"Address2Line", "address2_line", "address2Line"},
+		{"name", "name", "name"},
+	}
+	for _, tt := range tests {
+		if got := toSnakeCase(tt.name); got != tt.snake {
+			t.Errorf("toSnakeCase(%q) = %q, expected %q", tt.name, got, tt.snake)
+		}
+		if got := toCamelCase(tt.name); got != tt.camel {
+			t.Errorf("toCamelCase(%q) = %q, expected %q", tt.name, got, tt.camel)
+		}
+	}
+}
+
+func TestJsonEncodeFieldNaming(t *testing.T) {
+	clearRefStructsCache()
+
+	type Owner struct {
+		FullName string
+	}
+	type Account struct {
+		UserID    int
+		UserName  string
+		HTTPProxy string
+		Owner     Owner
+	}
+	in := Account{UserID: 7, UserName: "ana", HTTPProxy: "p", Owner: Owner{FullName: "Ana Paz"}}
+
+	tests := []struct {
+		naming   FieldNaming
+		expected string
+	}{
+		{FieldNamingPascal, `{"UserID":7,"UserName":"ana","HTTPProxy":"p","Owner":{"FullName":"Ana Paz"}}`},
+		{FieldNamingSnake, `{"user_id":7,"user_name":"ana","http_proxy":"p","owner":{"full_name":"Ana Paz"}}`},
+		{FieldNamingCamel, `{"userID":7,"userName":"ana","httpProxy":"p","owner":{"fullName":"Ana Paz"}}`},
+	}
+
+	for _, tt := range tests {
+		SetFieldNaming(tt.naming)
+		out, err := Convert(&in).JsonEncode()
+		var written []byte
+		w := &testWriter{writeFunc: func(p []byte) (int, error) {
+			written = append(written, p...)
+			return len(p), nil
+		}}
+		streamErr := NewJsonEncoder(w).Encode(&in)
+		SetFieldNaming(FieldNamingPascal)
+
+		if err != nil || streamErr != nil {
+			t.Fatalf("naming %d: encode errors %v, %v", tt.naming, err, streamErr)
+		}
+		if string(out) != tt.expected {
+			t.Errorf("naming %d: JsonEncode = %s, expected %s", tt.naming, out, tt.expected)
+		}
+		if string(written) != tt.expected+"\n" {
+			t.Errorf("naming %d: stream encoder wrote %s", tt.naming, written)
+		}
+
+		// Every convention decodes back without tags
+		var decoded Account
+		if err := Convert(tt.expected).JsonDecode(&decoded); err != nil {
+			t.Fatalf("naming %d: JsonDecode returned error: %v", tt.naming, err)
+		}
+		if decoded != in {
+			t.Errorf("naming %d: JsonDecode = %+v, expected %+v", tt.naming, decoded, in)
+		}
+	}
+}
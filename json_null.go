@@ -0,0 +1,87 @@
+package tinywodp
+
+import (
+	"unsafe"
+)
+
+// Null handling
+// Decoding keeps three cases apart for pointers, slices, maps and interfaces:
+//
+//	missing field -> left as it was
+//	null          -> set to nil (NullAsNil) or left as it was (NullKeep)
+//	[] or {}      -> empty, non-nil
+//
+// Arrays ignore null; strings, numbers, booleans and structs (time.Time
+// included) reject it.
+// Encoding writes nil pointers, maps and interfaces as null, and nil slices as
+// [] unless SetNilSliceEncoding(NilSliceAsNull) is used:
+//
+//	err := Convert(patch).JsonDecode(&user, NullKeep) // null means "no change"
+
+// NullPolicy controls what JSON null does to nillable targets
+type NullPolicy uint8
+
+const (
+	// NullAsNil sets pointers, slices, maps and interfaces to nil, like encoding/json
+	NullAsNil NullPolicy = iota
+	// NullKeep leaves the target untouched, treating null like a missing field
+	NullKeep
+)
+
+// applyDecode sets the null policy for the operation
+func (p NullPolicy) applyDecode(jh *jsonH) {
+	jh.jNull = p
+}
+
+// decodeJsonNull applies the null policy when jsonStr is null and target is nillable
+// Reports false for other values so the caller decodes them normally
+func (jh *jsonH) decodeJsonNull(jsonStr string, target *refValue) bool {
+	if jsonStr != "null" {
+		return false
+	}
+	switch target.refKind() {
+	case tpPointer, tpSlice, tpMap, tpInterface:
+	default:
+		return false
+	}
+	if jh.jNull == NullAsNil && target.ptr != nil {
+		memclr(target.ptr, target.Type().Size())
+	}
+	return true
+}
+
+// NilSliceEncoding controls how nil slices are encoded
+type NilSliceEncoding uint8
+
+const (
+	// NilSliceAsEmpty encodes nil slices as [] (default)
+	NilSliceAsEmpty NilSliceEncoding = iota
+	// NilSliceAsNull encodes nil slices as null, like encoding/json;
+	// empty non-nil slices are still encoded as []
+	NilSliceAsNull
+)
+
+// nilSliceEncoding is the active nil slice representation
+var nilSliceEncoding = NilSliceAsEmpty
+
+// SetNilSliceEncoding changes how nil slices are encoded
+// Not safe to call concurrently with encoding
+func SetNilSliceEncoding(e NilSliceEncoding) {
+	nilSliceEncoding = e
+}
+
+// emptySliceJson returns the encoding of a zero-length slice value
+func (jh *jsonH) emptySliceJson(v *refValue) []byte {
+	if jh.jNilSlice == NilSliceAsNull && isNilSlice(v) {
+		return []byte("null")
+	}
+	return []byte("[]")
+}
+
+// isNilSlice reports whether v is a slice that was never allocated
+func isNilSlice(v *refValue) bool {
+	if v.refKind() != tpSlice {
+		return false
+	}
+	return v.ptr == nil || *(*unsafe.Pointer)(v.ptr) == nil
+}
@@ -0,0 +1,144 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type nullCase struct {
+	Name  string
+	Tags  []string
+	Addr  *Address
+	Attrs map[string]int
+	Extra any
+}
+
+func TestJsonDecodeNullSemantics(t *testing.T) {
+	clearRefStructsCache()
+
+	fresh := func() nullCase {
+		return nullCase{Name: "n", Tags: []string{"a"}, Addr: &Address{Id: "1"}, Attrs: map[string]int{"x": 1}, Extra: 1.5}
+	}
+
+	// null clears every nillable field
+	v := fresh()
+	if err := Convert(`{"Tags":null,"Addr":null,"Attrs":null,"Extra":null}`).JsonDecode(&v); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if v.Tags != nil || v.Addr != nil || v.Attrs != nil || v.Extra != nil || v.Name != "n" {
+		t.Errorf("null decode = %+v, expected nil fields and Name kept", v)
+	}
+
+	// Missing fields are left alone
+	v = fresh()
+	if err := Convert(`{}`).JsonDecode(&v); err != nil || len(v.Tags) != 1 || v.Addr == nil || v.Attrs == nil {
+		t.Errorf("missing fields decode = %+v, %v", v, err)
+	}
+
+	// [] and {} give empty non-nil values
+	v = nullCase{}
+	if err := Convert(`{"Tags":[],"Attrs":{}}`).JsonDecode(&v); err != nil || v.Tags == nil || len(v.Tags) != 0 || v.Attrs == nil {
+		t.Errorf("empty decode = %+v, %v", v, err)
+	}
+
+	// NullKeep treats null like a missing field
+	v = fresh()
+	if err := Convert(`{"Tags":null,"Addr":null,"Attrs":null,"Extra":null}`).JsonDecode(&v, NullKeep); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if len(v.Tags) != 1 || v.Addr == nil || v.Attrs["x"] != 1 || v.Extra != 1.5 {
+		t.Errorf("NullKeep decode = %+v, expected fields kept", v)
+	}
+
+	// Scalars still reject null
+	if err := Convert(`{"Name":null}`).JsonDecode(&v); err == nil {
+		t.Error("null into a string field should return an error")
+	}
+	type wrapper struct{ Addr Address }
+	var w wrapper
+	if err := Convert(`{"Addr":null}`).JsonDecode(&w); err == nil {
+		t.Error("null into a struct field should return an error")
+	}
+}
+
+func TestJsonEncodeNilSlices(t *testing.T) {
+	clearRefStructsCache()
+	defer SetNilSliceEncoding(NilSliceAsEmpty)
+
+	type Doc struct {
+		Nil   []int
+		Empty []int
+	}
+	doc := Doc{Empty: []int{}}
+
+	out, err := Marshal(doc)
+	if err != nil || string(out) != `{"Nil":[],"Empty":[]}` {
+		t.Errorf("default Marshal = %s, %v", out, err)
+	}
+
+	SetNilSliceEncoding(NilSliceAsNull)
+	out, err = Marshal(doc)
+	if err != nil || string(out) != `{"Nil":null,"Empty":[]}` {
+		t.Errorf("NilSliceAsNull Marshal = %s, %v", out, err)
+	}
+	if out, err := Marshal([]string(nil)); err != nil || string(out) != "null" {
+		t.Errorf("NilSliceAsNull Marshal([]string(nil)) = %s, %v", out, err)
+	}
+}
+
+type nullReuse struct {
+	Count *int
+	Addr  *Address
+	Deep  **string
+	Items []*Address
+	Pair  [2]*int
+}
+
+func TestJsonDecodeNullResetsReusedPointers(t *testing.T) {
+	clearRefStructsCache()
+
+	var v nullReuse
+	first := `{"Count":3,"Addr":{"Id":"1"},"Deep":"x","Items":[{"Id":"2"}],"Pair":[1,2]}`
+	if err := Convert(first).JsonDecode(&v); err != nil {
+		t.Fatalf("first decode returned error: %v", err)
+	}
+	if v.Count == nil || v.Addr == nil || v.Deep == nil || *v.Deep == nil || v.Pair[1] == nil {
+		t.Fatalf("first decode = %+v, expected every pointer set", v)
+	}
+	oldAddr := v.Addr
+
+	// The same target decoded again: null drops every pointer set before
+	second := `{"Count":null,"Addr":null,"Deep":null,"Items":[null],"Pair":[null,null]}`
+	if err := Convert(second).JsonDecode(&v); err != nil {
+		t.Fatalf("second decode returned error: %v", err)
+	}
+	if v.Count != nil || v.Addr != nil || v.Deep != nil || v.Items[0] != nil || v.Pair[0] != nil || v.Pair[1] != nil {
+		t.Errorf("second decode = %+v, expected nil pointers", v)
+	}
+	if oldAddr.Id != "1" {
+		t.Errorf("old pointee changed to %+v; null must not be decoded into it", oldAddr)
+	}
+
+	// null at an inner level of a pointer chain clears only that level
+	if err := Convert(`{"Deep":"y"}`).JsonDecode(&v); err != nil || v.Deep == nil || **v.Deep != "y" {
+		t.Fatalf("third decode = %+v, %v", v, err)
+	}
+	outer := v.Deep
+	if err := Convert(`{"Deep":null}`).JsonDecode(&v); err != nil || v.Deep != nil || *outer == nil {
+		t.Errorf("null into **string = %v, %v; expected the field cleared and the old chain intact", v.Deep, err)
+	}
+
+	// A top-level pointer target is reset too
+	p := &Address{Id: "9"}
+	if err := Convert(`null`).JsonDecode(&p); err != nil || p != nil {
+		t.Errorf("top-level null = %+v, %v; expected nil", p, err)
+	}
+
+	// NullKeep leaves reused pointers alone
+	n := 5
+	keep := nullReuse{Count: &n}
+	if err := Convert(`{"Count":null}`).JsonDecode(&keep, NullKeep); err != nil || keep.Count != &n {
+		t.Errorf("NullKeep = %+v, %v; expected Count kept", keep, err)
+	}
+}
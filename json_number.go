@@ -0,0 +1,254 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Lossless number handling
+// JSON numbers have arbitrary precision but float64 only holds 53 bits of mantissa,
+// so IDs like Twitter snowflakes (> 2^53) sent unquoted get silently rounded.
+// BigNumber keeps the literal text and NumberPolicy controls what happens
+// when a number would not fit its target exactly.
+
+// BigNumber holds a JSON number as its literal text so it is never rounded
+//
+// Usage patterns:
+//
+//	type Tweet struct {
+//		ID   BigNumber // {"ID": 1234567890123456789012} kept verbatim
+//		Text string
+//	}
+//
+// Decodes from an unquoted number or a quoted numeric string,
+// and encodes back as an unquoted number
+type BigNumber string
+
+// Number is the json.Number-style name for BigNumber: a string-backed number
+// that preserves the exact literal, for large int64s and high-precision decimals
+//
+//	var v any
+//	err := Convert(`{"id":9007199254740993}`).JsonDecode(&v, UseNumber)
+//	id, err := v.(map[string]any)["id"].(Number).Int64()
+type Number = BigNumber
+
+// String returns the literal number text
+func (n BigNumber) String() string {
+	return string(n)
+}
+
+// Int64 returns the number as an int64, failing for fractions and exponents
+func (n BigNumber) Int64() (int64, error) {
+	if !isJsonNumber(string(n)) || !isJsonIntegerLiteral(string(n)) {
+		return 0, Err(errInvalidJSON, "not an integer: "+string(n))
+	}
+	return Convert(string(n)).ToInt64()
+}
+
+// Float64 returns the number as a float64, which may round large values
+func (n BigNumber) Float64() (float64, error) {
+	if !isJsonNumber(string(n)) {
+		return 0, Err(errInvalidJSON, "not a number: "+string(n))
+	}
+	f, ok := parseJsonFloat(string(n), 64)
+	if !ok {
+		return f, Err(errInvalidJSON, "number out of range for float64: "+string(n))
+	}
+	return f, nil
+}
+
+// NumberPolicy controls how JSON numbers are decoded into targets that
+// cannot represent them exactly
+type NumberPolicy uint8
+
+const (
+	// NumberDefault keeps the historical behavior: numbers are converted to the
+	// target type (floats may round) and string fields reject unquoted numbers
+	NumberDefault NumberPolicy = iota
+	// NumberAsString also accepts unquoted numbers into string fields, storing
+	// the literal text so it can be handled losslessly by the caller
+	NumberAsString
+	// NumberStrict returns an error when a number would lose precision:
+	// integers beyond 2^53 into floats, fractions into integers, or overflow
+	NumberStrict
+)
+
+// bigNumberType is the type descriptor used to detect BigNumber targets
+var bigNumberType = refValueOf(BigNumber("")).Type()
+
+// maxExactFloatInt is 2^53, the largest integer float64 represents exactly
+const maxExactFloatInt = "9007199254740992"
+
+// isBigNumberType reports whether the target is a BigNumber
+func isBigNumberType(target *refValue) bool {
+	return target.Type() == bigNumberType
+}
+
+// isJsonNumber reports whether s is a valid JSON number literal (RFC 8259 grammar)
+func isJsonNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	if i >= len(s) {
+		return false
+	}
+
+	// Integer part: 0 or [1-9][0-9]*
+	if s[i] == '0' {
+		i++
+	} else if s[i] >= '1' && s[i] <= '9' {
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	} else {
+		return false
+	}
+
+	// Fraction part
+	if i < len(s) && s[i] == '.' {
+		i++
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	// Exponent part
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	return i == len(s)
+}
+
+// isJsonIntegerLiteral reports whether a valid JSON number has no fraction or exponent
+func isJsonIntegerLiteral(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '.' || s[i] == 'e' || s[i] == 'E' {
+			return false
+		}
+	}
+	return true
+}
+
+// exceedsFloatPrecision reports whether an integer literal is larger in
+// magnitude than 2^53 and would therefore be rounded by float64
+func exceedsFloatPrecision(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	if len(s) != len(maxExactFloatInt) {
+		return len(s) > len(maxExactFloatInt)
+	}
+	return s > maxExactFloatInt
+}
+
+// bigNumberLiteral returns the JSON text for a BigNumber value
+// An empty BigNumber is its zero value and encodes as 0
+func bigNumberLiteral(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// parseJsonBigNumberRef stores a JSON number literal verbatim in a BigNumber target
+// Accepts both 123 and "123" forms, rejecting anything that is not a JSON number
+func (jh *jsonH) parseJsonBigNumberRef(jsonStr string, target *refValue) error {
+	literal := jsonStr
+	if len(literal) >= 2 && literal[0] == '"' && literal[len(literal)-1] == '"' {
+		literal = literal[1 : len(literal)-1]
+	}
+	if !isJsonNumber(literal) {
+		return Err(errInvalidJSON, "expected number but got: "+jsonStr)
+	}
+	target.refSetString(literal)
+	return nil
+}
+
+// checkNumberPrecision validates a number against the target kind under NumberStrict
+func (jh *jsonH) checkNumberPrecision(jsonStr string, target *refValue) error {
+	if jh.jNum != NumberStrict {
+		return nil
+	}
+	if !isJsonNumber(jsonStr) {
+		return Err(errInvalidJSON, "invalid number: "+jsonStr)
+	}
+
+	switch target.refKind() {
+	case tpFloat32, tpFloat64:
+		if isJsonIntegerLiteral(jsonStr) && exceedsFloatPrecision(jsonStr) {
+			return Err(errInvalidJSON, "number exceeds float64 precision: "+jsonStr+" (use BigNumber)")
+		}
+	default:
+		if !isJsonIntegerLiteral(jsonStr) {
+			return Err(errInvalidJSON, "expected integer but got: "+jsonStr)
+		}
+	}
+	return nil
+}
+
+// checkIntRange rejects values that do not fit the width of a signed target,
+// so 300 is never stored into an int8 as 44
+func checkIntRange(v int64, jsonStr string, target *refValue) error {
+	bits := uint(target.Type().Size()) * 8
+	if bits >= 64 {
+		return nil
+	}
+	if limit := int64(1) << (bits - 1); v < -limit || v >= limit {
+		return Err(errInvalidJSON, "number out of range for "+target.refKind().String()+": "+jsonStr+
+			" (min "+Convert(-limit).String()+", max "+Convert(limit-1).String()+")")
+	}
+	return nil
+}
+
+// checkUintRange rejects values that do not fit the width of an unsigned target
+func checkUintRange(v uint64, jsonStr string, target *refValue) error {
+	bits := uint(target.Type().Size()) * 8
+	if bits < 64 && v >= uint64(1)<<bits {
+		return Err(errInvalidJSON, "number out of range for "+target.refKind().String()+": "+jsonStr+
+			" (max "+Convert(uint64(1)<<bits-1).String()+")")
+	}
+	return nil
+}
+
+// isJsonZero reports whether the unsigned number literal s has the value zero
+func isJsonZero(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0', '.':
+		case 'e', 'E':
+			return true // the mantissa is zero whatever the exponent
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseJsonUintLiteral parses a non-negative integer literal into a uint64,
+// reporting false when it overflows
+func parseJsonUintLiteral(s string) (uint64, bool) {
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		d := uint64(s[i] - '0')
+		if v > (1<<64-1-d)/10 {
+			return 0, false
+		}
+		v = v*10 + d
+	}
+	return v, true
+}
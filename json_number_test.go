@@ -0,0 +1,311 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestIsJsonNumber(t *testing.T) {
+	valid := []string{"0", "-0", "42", "-123", "3.14", "1e10", "1E-5", "2.5e+3", "1234567890123456789012"}
+	invalid := []string{"", "-", "01", "1.", ".5", "1e", "+1", "0x10", "1.2.3", "NaN", "12a"}
+
+	for _, s := range valid {
+		if !isJsonNumber(s) {
+			t.Errorf("isJsonNumber(%q) = false, expected true", s)
+		}
+	}
+	for _, s := range invalid {
+		if isJsonNumber(s) {
+			t.Errorf("isJsonNumber(%q) = true, expected false", s)
+		}
+	}
+}
+
+func TestJsonDecodeBigNumber(t *testing.T) {
+	type Tweet struct {
+		ID   BigNumber
+		Text string
+	}
+
+	tests := []struct {
+		input    string
+		expected BigNumber
+	}{
+		{`{"ID": 1234567890123456789012, "Text": "hi"}`, "1234567890123456789012"},
+		{`{"ID": "9007199254740993", "Text": "hi"}`, "9007199254740993"},
+		{`{"ID": -1.5e300, "Text": "hi"}`, "-1.5e300"},
+	}
+
+	for _, test := range tests {
+		var tweet Tweet
+		if err := Convert(test.input).JsonDecode(&tweet); err != nil {
+			t.Errorf("JsonDecode(%s) returned error: %v", test.input, err)
+			continue
+		}
+		if tweet.ID != test.expected {
+			t.Errorf("JsonDecode(%s).ID = %s, expected %s", test.input, tweet.ID, test.expected)
+		}
+	}
+
+	var tweet Tweet
+	if err := Convert(`{"ID": "abc"}`).JsonDecode(&tweet); err == nil {
+		t.Error("JsonDecode should reject non-numeric BigNumber")
+	}
+}
+
+func TestJsonEncodeBigNumber(t *testing.T) {
+	type Tweet struct {
+		ID BigNumber
+	}
+
+	result, err := Convert(Tweet{ID: "1234567890123456789012"}).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	if !Contains(string(result), `"ID":1234567890123456789012`) {
+		t.Errorf("JsonEncode(BigNumber) = %s, expected unquoted number", string(result))
+	}
+}
+
+func TestJsonDecodeNumberPolicy(t *testing.T) {
+	t.Run("number as string", func(t *testing.T) {
+		var s string
+		if err := Convert(`1234567890123456789012`).JsonDecode(&s); err == nil {
+			t.Error("default policy should reject numbers into string targets")
+		}
+		if err := Convert(`1234567890123456789012`).JsonDecode(&s, NumberAsString); err != nil {
+			t.Fatalf("NumberAsString returned error: %v", err)
+		}
+		if s != "1234567890123456789012" {
+			t.Errorf("NumberAsString stored %q", s)
+		}
+	})
+
+	t.Run("strict float precision", func(t *testing.T) {
+		var f float64
+		if err := Convert(`9007199254740993`).JsonDecode(&f); err != nil {
+			t.Errorf("default policy should accept rounding, got: %v", err)
+		}
+		if err := Convert(`9007199254740993`).JsonDecode(&f, NumberStrict); err == nil {
+			t.Error("NumberStrict should reject integers beyond 2^53 into float64")
+		}
+		if err := Convert(`9007199254740992`).JsonDecode(&f, NumberStrict); err != nil {
+			t.Errorf("NumberStrict should accept 2^53, got: %v", err)
+		}
+	})
+
+	t.Run("strict integer fraction", func(t *testing.T) {
+		var i int64
+		if err := Convert(`2.5`).JsonDecode(&i, NumberStrict); err == nil {
+			t.Error("NumberStrict should reject fractions into integers")
+		}
+	})
+}
+
+func TestJsonDecodeUseNumber(t *testing.T) {
+	input := `{"id":9007199254740993,"price":0.10000000000000000555,"n":[1,2.5]}`
+
+	var v any
+	if err := Convert(input).JsonDecode(&v, UseNumber); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	obj := v.(map[string]any)
+
+	id, ok := obj["id"].(Number)
+	if !ok || id != "9007199254740993" {
+		t.Fatalf("id = %#v, expected Number", obj["id"])
+	}
+	if n, err := id.Int64(); err != nil || n != 9007199254740993 {
+		t.Errorf("Int64() = %d, %v", n, err)
+	}
+	if obj["price"] != Number("0.10000000000000000555") {
+		t.Errorf("price = %#v, expected exact literal", obj["price"])
+	}
+	if arr := obj["n"].([]any); arr[1] != Number("2.5") {
+		t.Errorf("n[1] = %#v, expected Number", arr[1])
+	}
+
+	// Without the option generic numbers stay float64
+	v = nil
+	if err := Convert(`2.5`).JsonDecode(&v); err != nil || v != float64(2.5) {
+		t.Errorf("default decode = %#v, %v", v, err)
+	}
+
+	if _, err := Number("2.5").Int64(); err == nil {
+		t.Error("Int64() on a fraction should return an error")
+	}
+	if f, err := Number("2.5").Float64(); err != nil || f != 2.5 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+}
+
+func TestJsonDecoderUseNumber(t *testing.T) {
+	dec := NewJsonDecoder(&testReader{data: "1\n12345678901234567890\n", chunk: 3, eof: errTestEOF})
+	dec.UseNumber()
+
+	for _, expected := range []Number{"1", "12345678901234567890"} {
+		var v any
+		if err := dec.Decode(&v); err != nil || v != expected {
+			t.Errorf("Decode = %#v, %v, expected Number(%s)", v, err, expected)
+		}
+	}
+}
+
+func TestJsonNumericWidths(t *testing.T) {
+	clearRefStructsCache()
+
+	type Widths struct {
+		I8   []int8
+		I16  []int16
+		I32  []int32
+		I64  []int64
+		U8   []uint8
+		U16  []uint16
+		U32  []uint32
+		U64  []uint64
+		F32  []float32
+		Uint uint
+	}
+
+	in := Widths{
+		I8:   []int8{-128, 127},
+		I16:  []int16{-32768, 32767},
+		I32:  []int32{-2147483648, 2147483647},
+		I64:  []int64{-9223372036854775808, 9223372036854775807},
+		U8:   []uint8{0, 255},
+		U16:  []uint16{65535},
+		U32:  []uint32{4294967295},
+		U64:  []uint64{18446744073709551615},
+		F32:  []float32{1.5, -0.25},
+		Uint: 42,
+	}
+	expected := `{"I8":[-128,127],"I16":[-32768,32767],"I32":[-2147483648,2147483647],` +
+		`"I64":[-9223372036854775808,9223372036854775807],"U8":"AP8=","U16":[65535],` +
+		`"U32":[4294967295],"U64":[18446744073709551615],"F32":[1.5,-0.25],"Uint":42}`
+
+	out, err := Convert(&in).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", out, expected)
+	}
+
+	var decoded Widths
+	if err := Convert(expected).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	again, err := Convert(&decoded).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode of decoded value returned error: %v", err)
+	}
+	if string(again) != expected {
+		t.Errorf("round trip = %s, expected %s", again, expected)
+	}
+
+	// Values that do not fit their target fail instead of wrapping
+	overflows := []struct {
+		input  string
+		target func() any
+	}{
+		{`[128]`, func() any { return new([]int8) }},
+		{`[-32769]`, func() any { return new([]int16) }},
+		{`[2147483648]`, func() any { return new([]int32) }},
+		{`[256]`, func() any { return new([]uint8) }},
+		{`[-1]`, func() any { return new([]uint32) }},
+		{`[18446744073709551616]`, func() any { return new([]uint64) }},
+		{`[1e39]`, func() any { return new([]float32) }},
+	}
+	for _, tt := range overflows {
+		if err := Convert(tt.input).JsonDecode(tt.target()); err == nil {
+			t.Errorf("JsonDecode(%s) into %T should fail", tt.input, tt.target())
+		}
+	}
+}
+
+func TestJsonDecodeLargeUnsigned(t *testing.T) {
+	clearRefStructsCache()
+
+	const maxUint64 = uint64(18446744073709551615)
+	type Counter struct {
+		Total uint64
+		Small uint16
+		Ptr   *uint64
+	}
+
+	var c Counter
+	if err := Convert(`{"Total":18446744073709551615,"Small":65535,"Ptr":9223372036854775808}`).JsonDecode(&c); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if c.Total != maxUint64 || c.Small != 65535 || c.Ptr == nil || *c.Ptr != 1<<63 {
+		t.Errorf("JsonDecode = %+v", c)
+	}
+
+	var m map[string]uint64
+	if err := Convert(`{"a":18446744073709551615}`).JsonDecode(&m); err != nil || m["a"] != maxUint64 {
+		t.Errorf("JsonDecode(map[string]uint64) = %v, %v", m, err)
+	}
+
+	dec := NewJsonDecoder(&testReader{data: `18446744073709551614 ` + `{"Total":1}`, chunk: 3, eof: errTestEOF})
+	var u uint64
+	if err := dec.Decode(&u); err != nil || u != maxUint64-1 {
+		t.Errorf("JsonDecoder.Decode = %d, %v", u, err)
+	}
+
+	// Zero with a sign and exponent forms are accepted when they fit
+	accepted := map[string]uint64{`-0`: 0, `-0.0e3`: 0, `1.8e19`: 18000000000000000000, `2E3`: 2000}
+	for input, expected := range accepted {
+		var v uint64
+		if err := Convert(input).JsonDecode(&v); err != nil || v != expected {
+			t.Errorf("JsonDecode(%s) = %d, %v; expected %d", input, v, err, expected)
+		}
+	}
+	for _, input := range []string{`-1`, `2e19`, `18446744073709551616`, `-0.5`} {
+		var v uint64
+		if err := Convert(input).JsonDecode(&v); err == nil {
+			t.Errorf("JsonDecode(%s) into uint64 should fail, got %d", input, v)
+		}
+	}
+}
+
+func TestJsonDecodeOverflowPath(t *testing.T) {
+	clearRefStructsCache()
+
+	type Line struct {
+		Qty   int8
+		Codes []uint16
+	}
+	type Order struct {
+		Lines []Line
+		Meta  map[string]int16
+	}
+
+	tests := []struct {
+		input    string
+		contains []string
+	}{
+		{`{"Lines":[{"Qty":1},{"Qty":300}]}`, []string{"int8: 300", "min -128, max 127", "in Lines[1].Qty", "line 1"}},
+		{`{"Lines":[{"Codes":[1,70000]}]}`, []string{"uint16: 70000", "max 65535", "in Lines[0].Codes[1]"}},
+		{`{"Meta":{"a":40000}}`, []string{"int16: 40000", "in Meta[a]"}},
+	}
+	for _, tt := range tests {
+		var o Order
+		err := Convert(tt.input).JsonDecode(&o)
+		if err == nil {
+			t.Errorf("JsonDecode(%s) should fail", tt.input)
+			continue
+		}
+		for _, want := range tt.contains {
+			if !Contains(err.Error(), want) {
+				t.Errorf("JsonDecode(%s) error %q should contain %q", tt.input, err.Error(), want)
+			}
+		}
+	}
+
+	// Values that fit leave no path behind for the next decode
+	var o Order
+	if err := Convert(`{"Lines":[{"Qty":-128,"Codes":[65535]}]}`).JsonDecode(&o); err != nil {
+		t.Errorf("JsonDecode returned error: %v", err)
+	}
+}
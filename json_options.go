@@ -0,0 +1,151 @@
+package tinywodp
+
+// Decode options
+// Passed as optional trailing arguments to JsonDecode:
+//
+//	err := Convert(data).JsonDecode(&user, NumberStrict, MatchCaseInsensitive)
+
+// DecodeOption configures a single decode operation
+type DecodeOption interface {
+	applyDecode(jh *jsonH)
+}
+
+// applyDecode sets the number policy for the operation
+func (p NumberPolicy) applyDecode(jh *jsonH) {
+	jh.jNum = p
+}
+
+// FieldMatch controls how JSON object keys are matched to struct fields
+type FieldMatch uint8
+
+const (
+	// MatchDefault matches json tag, exact field name, then snake_case field name
+	MatchDefault FieldMatch = iota
+	// MatchCaseInsensitive adds an ASCII case-insensitive fallback after the default
+	// rules, like encoding/json ("username" matches UserName and USERNAME)
+	MatchCaseInsensitive
+	// MatchExact only accepts the json tag or the exact field name
+	MatchExact
+)
+
+// applyDecode sets the field matching mode for the operation
+func (m FieldMatch) applyDecode(jh *jsonH) {
+	jh.jMatch = m
+}
+
+// applyDecodeOptions applies options to a handler in order, later ones win
+func (jh *jsonH) applyDecodeOptions(opts []DecodeOption) {
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyDecode(jh)
+		}
+	}
+}
+
+// UseNumber makes numbers decoded into any/interface{} targets a Number holding
+// the exact literal instead of a float64, like encoding/json's Decoder.UseNumber
+//
+//	err := Convert(payload).JsonDecode(&v, UseNumber)
+var UseNumber DecodeOption = useNumberOption{}
+
+// useNumberOption is the DecodeOption behind UseNumber
+type useNumberOption struct{}
+
+// applyDecode enables Number results for generic values
+func (useNumberOption) applyDecode(jh *jsonH) {
+	jh.jUseNum = true
+}
+
+// JsonOptions gathers every encoding and decoding setting of one operation
+// The zero value means the library defaults; DefaultJsonOptions returns the
+// settings chosen with the Set* functions instead, ready to adjust:
+//
+//	opts := DefaultJsonOptions()
+//	opts.Naming = FieldNamingCamel
+//	out, err := Convert(&user).JsonEncodeWith(opts)
+//	err = Convert(out).JsonDecodeWith(JsonOptions{Match: MatchCaseInsensitive}, &user)
+//
+// JsonOptions is also a DecodeOption, so it combines with the single options:
+//
+//	err := Convert(data).JsonDecode(&user, opts, NullKeep) // later options win
+type JsonOptions struct {
+	// Encoding
+	Naming         FieldNaming       // Struct field names written (SetFieldNaming)
+	NilSlices      NilSliceEncoding  // Representation of nil slices (SetNilSliceEncoding)
+	ByteSlices     ByteSliceEncoding // Representation of []byte (SetByteSliceEncoding)
+	EscapeNonASCII bool              // \uXXXX for runes above U+007F (SetEscapeNonASCII)
+	EscapeHTML     bool              // \uXXXX for <, >, &, U+2028 and U+2029 (SetEscapeHTML)
+	SortKeys       bool              // Struct fields sorted by key (SetSortKeys)
+	NonFinite      NonFiniteEncoding // NaN and ±Inf floats (SetNonFiniteEncoding)
+
+	// Decoding
+	Numbers       NumberPolicy // Numbers that do not fit their target exactly
+	Match         FieldMatch   // Matching of object keys to struct fields
+	UseNumber     bool         // Generic numbers decode as Number
+	Null          NullPolicy   // Effect of null on nillable targets
+	Target        TargetPolicy // Merge into the target or zero it first
+	CollectErrors bool         // Keep decoding past field errors (CollectErrors)
+
+	// Both directions; 0 means DefaultMaxDepth (SetMaxDepth)
+	MaxDepth int
+}
+
+// DefaultJsonOptions returns the settings currently chosen with the Set* functions
+func DefaultJsonOptions() JsonOptions {
+	return JsonOptions{
+		Naming:         fieldNaming,
+		NilSlices:      nilSliceEncoding,
+		ByteSlices:     byteSliceEncoding,
+		EscapeNonASCII: escapeNonASCII,
+		EscapeHTML:     escapeHTML,
+		SortKeys:       sortKeys,
+		NonFinite:      nonFiniteEncoding,
+		MaxDepth:       maxJsonDepth,
+	}
+}
+
+// applyDecode copies the decoding settings, and the depth limit, to the handler
+func (o JsonOptions) applyDecode(jh *jsonH) {
+	jh.jNum = o.Numbers
+	jh.jMatch = o.Match
+	jh.jUseNum = o.UseNumber
+	jh.jNull = o.Null
+	jh.jTarget = o.Target
+	jh.jLenient = o.CollectErrors
+	jh.jMaxDepth = o.maxDepth()
+}
+
+// applyEncode copies the encoding settings, and the depth limit, to the handler
+func (o JsonOptions) applyEncode(jh *jsonH) {
+	jh.jNaming = o.Naming
+	jh.jNilSlice = o.NilSlices
+	jh.jBytes = o.ByteSlices
+	jh.jASCII = o.EscapeNonASCII
+	jh.jHTML = o.EscapeHTML
+	jh.jSortKeys = o.SortKeys
+	jh.jNonFinite = o.NonFinite
+	jh.jMaxDepth = o.maxDepth()
+}
+
+// maxDepth returns the nesting limit, DefaultMaxDepth when unset
+func (o JsonOptions) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+// JsonEncodeWith encodes the current value like JsonEncode, using opts instead
+// of the settings chosen with the Set* functions
+func (c *refValue) JsonEncodeWith(opts JsonOptions, w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	opts.applyEncode(jh)
+	return jh.encodeJsonTo(c, w, 0)
+}
+
+// JsonDecodeWith decodes into target like JsonDecode, using opts for every
+// decoding setting
+func (c *refValue) JsonDecodeWith(opts JsonOptions, target any) error {
+	return c.JsonDecode(target, opts)
+}
@@ -0,0 +1,104 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonEncodeWithOptions(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		ItemName string
+		Tags     []string
+		Data     []byte
+	}
+	in := Item{ItemName: "café", Data: []byte{1, 2}}
+
+	tests := []struct {
+		name     string
+		opts     JsonOptions
+		expected string
+	}{
+		{"zero value", JsonOptions{}, `{"ItemName":"café","Tags":[],"Data":"AQI="}`},
+		{"combined", JsonOptions{
+			Naming:         FieldNamingSnake,
+			NilSlices:      NilSliceAsNull,
+			ByteSlices:     BytesAsArray,
+			EscapeNonASCII: true,
+		}, `{"item_name":"caf\u00e9","tags":null,"data":[1,2]}`},
+	}
+	for _, tt := range tests {
+		out, err := Convert(&in).JsonEncodeWith(tt.opts)
+		if err != nil {
+			t.Fatalf("%s: JsonEncodeWith returned error: %v", tt.name, err)
+		}
+		if string(out) != tt.expected {
+			t.Errorf("%s: JsonEncodeWith = %s, expected %s", tt.name, out, tt.expected)
+		}
+	}
+
+	// Options apply to one call only, the global settings stay in force
+	SetFieldNaming(FieldNamingCamel)
+	defer SetFieldNaming(FieldNamingPascal)
+	if out, _ := Convert(&in).JsonEncodeWith(JsonOptions{}); string(out) != tests[0].expected {
+		t.Errorf("JsonEncodeWith used the global naming: %s", out)
+	}
+	if out, _ := Convert(&in).JsonEncode(); string(out) != `{"itemName":"café","tags":[],"data":"AQI="}` {
+		t.Errorf("JsonEncode ignored the global naming: %s", out)
+	}
+	if opts := DefaultJsonOptions(); opts.Naming != FieldNamingCamel || opts.MaxDepth != DefaultMaxDepth {
+		t.Errorf("DefaultJsonOptions = %+v", opts)
+	}
+
+	// The depth limit covers encoding
+	type Node struct {
+		Next *Node
+	}
+	deep := &Node{Next: &Node{Next: &Node{Next: &Node{}}}}
+	if _, err := Convert(deep).JsonEncodeWith(JsonOptions{MaxDepth: 2}); err == nil {
+		t.Error("expected a depth error with MaxDepth 2")
+	}
+}
+
+func TestJsonDecodeWithOptions(t *testing.T) {
+	clearRefStructsCache()
+
+	type Account struct {
+		UserName string
+		Balance  int
+		Tags     []string
+	}
+
+	var acc Account
+	opts := JsonOptions{Match: MatchCaseInsensitive, Numbers: NumberStrict}
+	if err := Convert(`{"USERNAME":"ana","Balance":10}`).JsonDecodeWith(opts, &acc); err != nil {
+		t.Fatalf("JsonDecodeWith returned error: %v", err)
+	}
+	if acc.UserName != "ana" || acc.Balance != 10 {
+		t.Errorf("JsonDecodeWith = %+v", acc)
+	}
+	if err := Convert(`{"Balance":1.5}`).JsonDecodeWith(opts, &acc); err == nil {
+		t.Error("NumberStrict should reject a fraction into an int")
+	}
+
+	// Later options override the struct, as with single options
+	acc = Account{Tags: []string{"keep"}}
+	if err := Convert(`{"Tags":null}`).JsonDecode(&acc, JsonOptions{}, NullKeep); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if len(acc.Tags) != 1 {
+		t.Errorf("NullKeep after JsonOptions should keep Tags, got %v", acc.Tags)
+	}
+
+	// Collected errors and the depth limit
+	err := Convert(`{"UserName":1,"Balance":2}`).JsonDecodeWith(JsonOptions{CollectErrors: true}, &acc)
+	if err == nil || acc.Balance != 2 {
+		t.Errorf("CollectErrors: err = %v, Balance = %d", err, acc.Balance)
+	}
+	var v any
+	if err := Convert(`[[[[1]]]]`).JsonDecodeWith(JsonOptions{MaxDepth: 3}, &v); err == nil {
+		t.Error("expected a depth error with MaxDepth 3")
+	}
+}
@@ -0,0 +1,127 @@
+package tinywodp
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Parallel array decoding
+// The elements of a top-level array decode independently, so a large batch
+// can be spread over several goroutines: one structural scan finds where each
+// element starts, the target slice is allocated once at its final length and
+// workers fill disjoint ranges of it.
+//
+//	var users []User
+//	err := Convert(batch).JsonDecode(&users, ParallelDecode(0)) // one worker per CPU
+//
+// It is off unless requested, and falls back to the regular single pass for
+// small arrays, CollectErrors, targets with their own decoder and any input
+// that fails: the error and the partially decoded slice are then exactly those
+// of a sequential decode. On TinyGo, where GOMAXPROCS is 1, ParallelDecode(0)
+// decodes sequentially. Decode hooks may be called concurrently, and a
+// DecodeArena only serves the slice itself, not the elements.
+
+// parallelMinPerWorker is the fewest elements worth a worker of their own
+const parallelMinPerWorker = 64
+
+// ParallelDecode returns a DecodeOption decoding the elements of a top-level
+// array on workers goroutines; zero or less means one per CPU (GOMAXPROCS)
+func ParallelDecode(workers int) DecodeOption {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return parallelOption(workers)
+}
+
+// parallelOption is the DecodeOption behind ParallelDecode
+type parallelOption int
+
+// applyDecode sets the worker count for the operation
+func (p parallelOption) applyDecode(jh *jsonH) {
+	jh.jWorkers = int(p)
+}
+
+// decodeParallel decodes the JSON array s into the slice target on jh.jWorkers
+// goroutines, reporting false without touching target when the input should
+// take the sequential path instead
+func (jh *jsonH) decodeParallel(s string, target *refValue) bool {
+	if jh.jWorkers < 2 || jh.jLenient || target.refKind() != tpSlice || needsRawJson(target) {
+		return false
+	}
+	starts, ok := jh.scanJsonElements(s)
+	if !ok || len(starts) < 2*parallelMinPerWorker {
+		return false
+	}
+	workers := min(jh.jWorkers, len(starts)/parallelMinPerWorker)
+
+	// Decoded into a fresh slice so a failure leaves target as it was
+	decoded := *target
+	var header jsonSliceHeader
+	decoded.ptr = unsafe.Pointer(&header)
+	makeJsonSlice(&decoded, len(starts), jh.jArena)
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	per := (len(starts) + workers - 1) / workers
+	for from := 0; from < len(starts); from += per {
+		to := min(from+per, len(starts))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := getJsonH(jh.jSep)
+			defer putJsonH(w)
+			w.copyDecodeSettings(jh)
+			for k := from; k < to && !failed.Load(); k++ {
+				if _, err := w.parseJsonElement(k, s, starts[k], decoded.refIndex(k)); err != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if failed.Load() {
+		return false
+	}
+	*(*jsonSliceHeader)(target.ptr) = header
+	return true
+}
+
+// scanJsonElements returns the offset of each element of the array s, false
+// when s is not a well-formed array on its own
+func (jh *jsonH) scanJsonElements(s string) ([]int, bool) {
+	i := skipJsonSpace(s, 0)
+	if i >= len(s) || s[i] != '[' {
+		return nil, false
+	}
+	i, done, err := jh.openJsonContainer(s, i, ']')
+	var starts []int
+	for !done {
+		if err != nil {
+			return nil, false
+		}
+		i = skipJsonSpace(s, i)
+		starts = append(starts, i)
+		if i, err = jh.skipJsonValueAt(s, i); err != nil {
+			return nil, false
+		}
+		i, done, err = jh.nextJsonMember(s, i, ']')
+	}
+	if err != nil || skipJsonSpace(s, i) < len(s) {
+		return nil, false
+	}
+	return starts, true
+}
+
+// copyDecodeSettings gives a worker the decode options of the operation it
+// serves, one level inside the top-level array
+func (jh *jsonH) copyDecodeSettings(from *jsonH) {
+	jh.jNum = from.jNum
+	jh.jMatch = from.jMatch
+	jh.jUseNum = from.jUseNum
+	jh.jNull = from.jNull
+	jh.jMaxDepth = from.jMaxDepth
+	jh.jInput = from.jInput
+	jh.jDepth = from.jDepth + 1
+}
@@ -0,0 +1,79 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type parallelItem struct {
+	ID   int
+	Name string
+	Tags []string
+}
+
+func parallelInput(n int, broken int) string {
+	out := "["
+	for i := range n {
+		if i > 0 {
+			out += ","
+		}
+		id := Convert(i).String()
+		if i == broken {
+			id = `"x"`
+		}
+		out += `{"ID":` + id + `,"Name":"item é` + Convert(i).String() + `","Tags":["a","b"]}`
+	}
+	return out + "]"
+}
+
+func TestParallelDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	input := parallelInput(1000, -1)
+	var sequential, parallel []parallelItem
+	if err := Convert(input).JsonDecode(&sequential); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if err := Convert(input).JsonDecode(&parallel, ParallelDecode(4)); err != nil {
+		t.Fatalf("parallel JsonDecode failed: %v", err)
+	}
+	if len(parallel) != 1000 {
+		t.Fatalf("got %d items", len(parallel))
+	}
+	for i := range sequential {
+		if parallel[i].ID != i || parallel[i].Name != sequential[i].Name || len(parallel[i].Tags) != 2 {
+			t.Fatalf("item %d: got %+v, want %+v", i, parallel[i], sequential[i])
+		}
+	}
+
+	// Small arrays and other targets take the single pass
+	var few []parallelItem
+	if err := Convert(parallelInput(3, -1)).JsonDecode(&few, ParallelDecode(0)); err != nil || len(few) != 3 {
+		t.Errorf("small array: %d items, %v", len(few), err)
+	}
+	var one parallelItem
+	if err := Convert(`{"ID":7}`).JsonDecode(&one, ParallelDecode(4)); err != nil || one.ID != 7 {
+		t.Errorf("struct target: %+v, %v", one, err)
+	}
+}
+
+func TestParallelDecodeErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	input := parallelInput(500, 321)
+	var sequential, parallel []parallelItem
+	want := Convert(input).JsonDecode(&sequential)
+	got := Convert(input).JsonDecode(&parallel, ParallelDecode(4))
+	if want == nil || got == nil || got.Error() != want.Error() {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	if !Contains(got.Error(), "[321].ID") {
+		t.Errorf("expected the error at [321].ID, got %v", got)
+	}
+
+	// Trailing data is reported like a sequential decode
+	if err := Convert(parallelInput(200, -1)+" []").JsonDecode(&parallel, ParallelDecode(4)); err == nil {
+		t.Error("expected an error for trailing data")
+	}
+}
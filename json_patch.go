@@ -0,0 +1,499 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// JSON Patch (RFC 6902)
+// JsonDiff computes the operations that turn one value into another, and
+// JsonPatch applies them, so a UI can sync state by sending only what changed:
+//
+//	patch, err := JsonDiff(&before, &after)
+//	// [{"op":"replace","path":"/Title","value":"Done"},{"op":"add","path":"/Tags/2","value":"x"}]
+//	err = JsonPatch(&remote, patch)
+//
+// Both sides go through the JSON encoding of the values, so paths use the JSON
+// keys (json tags and the SetFieldNaming convention) and values their JSON form.
+// Generic trees (map[string]any, []any) are used as they are.
+//
+// Diffs compare arrays by index: changed elements are patched in place, extra
+// ones removed from the end or appended. Objects are walked in key order, so the
+// same pair of values always gives the same patch.
+//
+// JsonPatch supports add, remove, replace, move, copy and test, and is atomic:
+// the operations run on a copy and target is only replaced when all of them
+// succeed and the result decodes into target's type.
+
+// JsonDiff returns the JSON Patch document turning a into b
+func JsonDiff(a, b any) ([]byte, error) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+
+	from, err := jh.genericJson(a)
+	if err != nil {
+		return nil, err
+	}
+	to, err := jh.genericJson(b)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := append(jh.jOut[:0], '[')
+	dst, err = jh.appendJsonDiff(dst, "", from, to)
+	jh.jOut = dst
+	if err != nil {
+		return nil, err
+	}
+	jh.jOut = append(dst, ']')
+
+	result := make([]byte, len(jh.jOut))
+	copy(result, jh.jOut)
+	return result, nil
+}
+
+// appendJsonDiff appends the operations turning from into to, both at path
+func (jh *jsonH) appendJsonDiff(dst []byte, path string, from, to any) ([]byte, error) {
+	var err error
+	switch x := from.(type) {
+	case map[string]any:
+		y, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, key := range Keys(x) {
+			child := path + "/" + escapeJsonPointer(key)
+			if value, found := y[key]; found {
+				dst, err = jh.appendJsonDiff(dst, child, x[key], value)
+			} else {
+				dst, err = jh.appendPatchOp(dst, "remove", child, nil, false)
+			}
+			if err != nil {
+				return dst, err
+			}
+		}
+		for _, key := range Keys(y) {
+			if _, found := x[key]; !found {
+				if dst, err = jh.appendPatchOp(dst, "add", path+"/"+escapeJsonPointer(key), y[key], true); err != nil {
+					return dst, err
+				}
+			}
+		}
+		return dst, nil
+
+	case []any:
+		y, ok := to.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if dst, err = jh.appendJsonDiff(dst, path+"/"+Convert(i).String(), x[i], y[i]); err != nil {
+				return dst, err
+			}
+		}
+		// Removed from the end first, so earlier indexes stay valid
+		for i := len(x) - 1; i >= len(y); i-- {
+			if dst, err = jh.appendPatchOp(dst, "remove", path+"/"+Convert(i).String(), nil, false); err != nil {
+				return dst, err
+			}
+		}
+		for i := len(x); i < len(y); i++ {
+			if dst, err = jh.appendPatchOp(dst, "add", path+"/"+Convert(i).String(), y[i], true); err != nil {
+				return dst, err
+			}
+		}
+		return dst, nil
+	}
+
+	if jsonValuesEqual(from, to) {
+		return dst, nil
+	}
+	return jh.appendPatchOp(dst, "replace", path, to, true)
+}
+
+// appendPatchOp appends one operation object, preceded by a comma unless it
+// is the first of the patch
+func (jh *jsonH) appendPatchOp(dst []byte, op, path string, value any, hasValue bool) ([]byte, error) {
+	if dst[len(dst)-1] != '[' {
+		dst = append(dst, ',')
+	}
+	dst = append(dst, `{"op":"`...)
+	dst = append(dst, op...)
+	dst = append(dst, `","path":`...)
+	dst = jh.quoteJsonString(dst, path)
+	if hasValue {
+		dst = append(dst, `,"value":`...)
+		var err error
+		if dst, err = jh.appendJsonFieldValue(dst, refValueOf(value)); err != nil {
+			return dst, err
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// JsonPatch applies the JSON Patch document patch to the value target points to
+// Nothing is changed when an operation fails, a test included. The options
+// apply to decoding the patched document back into target.
+//
+// Usage pattern:
+//
+//	err := JsonPatch(&state, patchFromServer)
+func JsonPatch(target any, patch []byte, opts ...DecodeOption) error {
+	elem, err := patchTarget(target)
+	if err != nil {
+		return err
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	doc, err := jh.genericJson(target)
+	if err != nil {
+		return err
+	}
+	var ops any
+	if err := Convert(string(patch)).JsonDecode(&ops, UseNumber); err != nil {
+		return Err(errInvalidPatch, err.Error())
+	}
+	list, ok := ops.([]any)
+	if !ok {
+		return Err(errInvalidPatch, "patch must be an array of operations")
+	}
+	for i, op := range list {
+		if doc, err = applyJsonPatchOp(doc, op); err != nil {
+			return Err(errInvalidPatch, "operation "+Convert(i).String()+":", err.Error())
+		}
+	}
+	return jh.storeGenericJson(elem, doc)
+}
+
+// patchTarget returns the value a patch target points to
+func patchTarget(target any) (*refValue, error) {
+	if target == nil {
+		return nil, Err(errInvalidPatch, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return nil, Err(errInvalidPatch, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return nil, Err(errInvalidPatch, "target pointer is nil or invalid")
+	}
+	return elem, nil
+}
+
+// storeGenericJson replaces elem with the patched document doc, decoded into a
+// fresh value so removed members do not survive; elem is untouched on failure
+func (jh *jsonH) storeGenericJson(elem *refValue, doc any) error {
+	text, err := jh.appendJsonFieldValue(nil, refValueOf(doc))
+	if err != nil {
+		return err
+	}
+	fresh, err := refNewValue(elem.Type())
+	if err != nil {
+		return err
+	}
+	if err := jh.parseJsonValueWithRefReflect(string(text), fresh); err != nil {
+		return Err(errInvalidPatch, "patched document does not fit the target:", err.Error())
+	}
+	elem.refSet(fresh)
+	return nil
+}
+
+// applyJsonPatchOp applies one operation object to doc and returns the result
+func applyJsonPatchOp(doc, op any) (any, error) {
+	fields, ok := op.(map[string]any)
+	if !ok {
+		return doc, Err(errInvalidPatch, "operation must be an object")
+	}
+	name, _ := fields["op"].(string)
+	path, err := patchPointer(fields, "path")
+	if err != nil {
+		return doc, err
+	}
+	value, hasValue := fields["value"]
+	if !hasValue && (name == "add" || name == "replace" || name == "test") {
+		return doc, Err(errInvalidPatch, name+" needs a value")
+	}
+
+	switch name {
+	case "add", "replace", "remove":
+		return patchJsonAt(doc, path, name, value)
+	case "test":
+		current, err := lookupJsonPointer(doc, path)
+		if err != nil {
+			return doc, err
+		}
+		if !jsonValuesEqual(current, value) {
+			return doc, Err(errInvalidPatch, "test failed at", fields["path"].(string))
+		}
+		return doc, nil
+	case "move", "copy":
+		from, err := patchPointer(fields, "from")
+		if err != nil {
+			return doc, err
+		}
+		if value, err = lookupJsonPointer(doc, from); err != nil {
+			return doc, err
+		}
+		if name == "copy" {
+			return patchJsonAt(doc, path, "add", copyJsonValue(value))
+		}
+		if len(path) > len(from) && isPointerPrefix(from, path) {
+			return doc, Err(errInvalidPatch, "cannot move a value into itself")
+		}
+		if doc, err = patchJsonAt(doc, from, "remove", nil); err != nil {
+			return doc, err
+		}
+		return patchJsonAt(doc, path, "add", value)
+	}
+	return doc, Err(errInvalidPatch, "unknown op: "+name)
+}
+
+// patchPointer reads and splits the JSON Pointer held by fields[member]
+func patchPointer(fields map[string]any, member string) ([]string, error) {
+	pointer, ok := fields[member].(string)
+	if !ok {
+		return nil, Err(errInvalidPatch, "missing "+member)
+	}
+	return parseJsonPointer(pointer)
+}
+
+// parseJsonPointer splits a JSON Pointer (RFC 6901) into unescaped reference
+// tokens; "" is the whole document
+func parseJsonPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, Err(errInvalidPatch, "JSON pointer must start with '/': "+pointer)
+	}
+	var tokens []string
+	for rest := pointer[1:]; ; {
+		token := rest
+		slash := indexByte(rest, '/')
+		if slash != -1 {
+			token, rest = rest[:slash], rest[slash+1:]
+		}
+		if indexByte(token, '~') != -1 {
+			out := make([]byte, 0, len(token))
+			for i := 0; i < len(token); i++ {
+				if token[i] != '~' {
+					out = append(out, token[i])
+					continue
+				}
+				if i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1') {
+					return nil, Err(errInvalidPatch, "invalid escape in JSON pointer: "+pointer)
+				}
+				if i++; token[i] == '0' {
+					out = append(out, '~')
+				} else {
+					out = append(out, '/')
+				}
+			}
+			token = string(out)
+		}
+		tokens = append(tokens, token)
+		if slash == -1 {
+			return tokens, nil
+		}
+	}
+}
+
+// isPointerPrefix reports whether the pointer tokens prefix start path
+func isPointerPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupJsonPointer returns the value at path in doc
+func lookupJsonPointer(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, Err(ErrPathNotFound, token)
+			}
+			doc = value
+		case []any:
+			index, err := patchIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, Err(ErrPathNotFound, token)
+		}
+	}
+	return doc, nil
+}
+
+// patchJsonAt adds, replaces or removes the value at path in doc and returns
+// the updated document; containers are changed in place
+func patchJsonAt(doc any, path []string, op string, value any) (any, error) {
+	if len(path) == 0 {
+		if op == "remove" {
+			return doc, Err(errInvalidPatch, "cannot remove the whole document")
+		}
+		return value, nil
+	}
+	token, last := path[0], len(path) == 1
+
+	switch node := doc.(type) {
+	case map[string]any:
+		current, found := node[token]
+		switch {
+		case !found && (!last || op != "add"):
+			return doc, Err(ErrPathNotFound, token)
+		case !last:
+			child, err := patchJsonAt(current, path[1:], op, value)
+			node[token] = child
+			return node, err
+		case op == "remove":
+			delete(node, token)
+		default:
+			node[token] = value
+		}
+		return node, nil
+
+	case []any:
+		index, err := patchIndex(token, len(node), last && op == "add")
+		if err != nil {
+			return doc, err
+		}
+		switch {
+		case !last:
+			node[index], err = patchJsonAt(node[index], path[1:], op, value)
+			return node, err
+		case op == "add":
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+		case op == "remove":
+			node = append(node[:index], node[index+1:]...)
+		default:
+			node[index] = value
+		}
+		return node, nil
+	}
+	return doc, Err(ErrPathNotFound, token)
+}
+
+// patchIndex parses an array index token; "-", the position after the last
+// element, is only valid where insert allows appending
+func patchIndex(token string, length int, insert bool) (int, error) {
+	if token == "-" && insert {
+		return length, nil
+	}
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, Err(errInvalidPatch, "invalid array index: "+token)
+	}
+	index := 0
+	for i := 0; i < len(token); i++ {
+		if !isDigitByte(token[i]) || index > length {
+			return 0, Err(errInvalidPatch, "invalid array index: "+token)
+		}
+		index = index*10 + int(token[i]-'0')
+	}
+	if index > length || index == length && !insert {
+		return 0, Err(ErrPathNotFound, "index "+token)
+	}
+	return index, nil
+}
+
+// genericJson returns v as a generic JSON tree with Number values: generic
+// trees, or pointers to them, are copied; anything else goes through its JSON
+// encoding
+func (jh *jsonH) genericJson(v any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any, []any:
+		return copyJsonValue(t), nil
+	case *map[string]any:
+		return copyJsonValue(*t), nil
+	case *[]any:
+		return copyJsonValue(*t), nil
+	case *any:
+		return copyJsonValue(*t), nil
+	}
+	data, err := Convert(v).JsonEncode()
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	err = Convert(string(data)).JsonDecode(&doc, UseNumber)
+	return doc, err
+}
+
+// copyJsonValue returns a deep copy of a generic JSON tree
+func copyJsonValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for key, value := range t {
+			out[key] = copyJsonValue(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, value := range t {
+			out[i] = copyJsonValue(value)
+		}
+		return out
+	}
+	return v
+}
+
+// jsonValuesEqual reports whether two generic JSON values are equal, numbers
+// by value so 1 and 1.0 match
+func jsonValuesEqual(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, found := y[key]
+			if !found || !jsonValuesEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonValuesEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	xf, xNum := jsonNumberValue(a)
+	yf, yNum := jsonNumberValue(b)
+	if xNum || yNum {
+		return xNum && yNum && xf == yf
+	}
+	return a == b
+}
+
+// jsonNumberValue returns the value of a generic number, Number or float64
+func jsonNumberValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
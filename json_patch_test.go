@@ -0,0 +1,137 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonPatchRFCExamples(t *testing.T) {
+	// RFC 6902 appendix A
+	tests := []struct {
+		name, doc, patch, expected string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{
+			"move member",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{"move element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"add nested", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{"ignore unknown members", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"baz":"qux","foo":"bar"}`},
+		{"escaped keys", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"copy","from":"/~1","path":"/a~1b"}]`, `{"/":9,"a/b":9,"~1":10}`},
+		{"append to array", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"replace root", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	}
+	for _, tt := range tests {
+		var doc any
+		if err := Convert(tt.doc).JsonDecode(&doc); err != nil {
+			t.Fatalf("%s: JsonDecode: %v", tt.name, err)
+		}
+		if err := JsonPatch(&doc, []byte(tt.patch)); err != nil {
+			t.Errorf("%s: JsonPatch returned error: %v", tt.name, err)
+			continue
+		}
+		out, err := Marshal(doc)
+		if err != nil {
+			t.Errorf("Marshal after patch returned error: %v", err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("%s: JsonPatch = %s, expected %s", tt.name, out, tt.expected)
+		}
+	}
+}
+
+func TestJsonPatchErrors(t *testing.T) {
+	tests := []struct {
+		name, patch string
+	}{
+		{"missing member", `[{"op":"remove","path":"/missing"}]`},
+		{"index past end", `[{"op":"add","path":"/tags/5","value":"x"}]`},
+		{"leading zero", `[{"op":"replace","path":"/tags/01","value":"x"}]`},
+		{"failed test", `[{"op":"test","path":"/name","value":"other"}]`},
+		{"move into child", `[{"op":"move","from":"/tags","path":"/tags/0"}]`},
+		{"missing value", `[{"op":"add","path":"/x"}]`},
+		{"unknown op", `[{"op":"merge","path":"/name"}]`},
+		{"bad pointer", `[{"op":"remove","path":"name"}]`},
+		{"not an array", `{"op":"remove","path":"/name"}`},
+	}
+	for _, tt := range tests {
+		doc := map[string]any{"name": "ana", "tags": []any{"a"}}
+		// A later failure leaves earlier operations unapplied
+		patch := `[{"op":"replace","path":"/name","value":"changed"},` + tt.patch[1:]
+		if tt.patch[0] != '[' {
+			patch = tt.patch
+		}
+		if err := JsonPatch(&doc, []byte(patch)); err == nil {
+			t.Errorf("%s: JsonPatch should fail", tt.name)
+		}
+		if doc["name"] != "ana" || len(doc["tags"].([]any)) != 1 {
+			t.Errorf("%s: target changed on failure: %v", tt.name, doc)
+		}
+	}
+
+	if err := JsonPatch(map[string]any{}, []byte(`[]`)); err == nil {
+		t.Error("JsonPatch with a non-pointer target should fail")
+	}
+}
+
+func TestJsonDiffRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		SKU string  `json:"sku"`
+		Qty int     `json:"qty"`
+		Tag *string `json:"tag"`
+	}
+	type Cart struct {
+		Owner string   `json:"owner"`
+		Items []Item   `json:"items"`
+		Notes []string `json:"notes"`
+		Total float64  `json:"total"`
+	}
+
+	tag := "gift"
+	before := Cart{Owner: "ana", Items: []Item{{SKU: "a", Qty: 1}, {SKU: "b", Qty: 2}}, Notes: []string{"x", "y", "z"}, Total: 3}
+	after := Cart{Owner: "ana", Items: []Item{{SKU: "a", Qty: 5, Tag: &tag}}, Notes: []string{"x", "y/z", "z", "w"}, Total: 7.5}
+
+	patch, err := JsonDiff(&before, &after)
+	if err != nil {
+		t.Fatalf("JsonDiff returned error: %v", err)
+	}
+	expected := `[{"op":"replace","path":"/items/0/qty","value":5},` +
+		`{"op":"replace","path":"/items/0/tag","value":"gift"},` +
+		`{"op":"remove","path":"/items/1"},` +
+		`{"op":"replace","path":"/notes/1","value":"y/z"},` +
+		`{"op":"add","path":"/notes/3","value":"w"},` +
+		`{"op":"replace","path":"/total","value":7.5}]`
+	if string(patch) != expected {
+		t.Errorf("JsonDiff =\n%s\nexpected\n%s", patch, expected)
+	}
+
+	if err := JsonPatch(&before, patch); err != nil {
+		t.Fatalf("JsonPatch returned error: %v", err)
+	}
+	if before.Total != 7.5 || len(before.Items) != 1 || before.Items[0].Qty != 5 ||
+		before.Items[0].Tag == nil || *before.Items[0].Tag != "gift" || len(before.Notes) != 4 || before.Notes[1] != "y/z" {
+		t.Errorf("JsonPatch result = %+v", before)
+	}
+
+	if same, err := JsonDiff(&after, &after); err != nil || string(same) != "[]" {
+		t.Errorf("JsonDiff of equal values = %s, %v", same, err)
+	}
+
+	// A patch that no longer fits the type leaves the target as it was
+	err = JsonPatch(&before, []byte(`[{"op":"replace","path":"/total","value":"many"}]`))
+	if err == nil || !Contains(err.Error(), "target") || before.Total != 7.5 {
+		t.Errorf("JsonPatch type mismatch = %v, total %v", err, before.Total)
+	}
+}
@@ -0,0 +1,232 @@
+package tinywodp
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Struct decode plans
+// Matching a JSON key against a struct used to scan its fields up to five times
+// (json tag, Go name, snake_case, camelCase, case-folded) on every member of every decode.
+// A plan compiles those lookups once per struct type into maps, together with
+// the setter of each plain scalar field, and is cached for the process lifetime,
+// so repeated decodes of the same type only hash the key. The encoders read the
+// tag directives of each field (secure, string, tiny) from the same plan.
+
+// decodePlan holds the precompiled field lookups of a struct type
+type decodePlan struct {
+	byTag    map[string]int // tiny name= key, then json tag name -> field index
+	byName   map[string]int // Go field name -> field index
+	bySnake  map[string]int // snake_case Go field name -> field index
+	byCamel  map[string]int // camelCase Go field name -> field index
+	byFold   map[string]int // lower-cased tag name, then Go field name -> field index
+	fields   []planField
+	defaults []int // indices of the fields with a default, or nested struct fields holding some
+	required bool  // some field is tagged tiny:"required"
+
+	encodedSize atomic.Int64 // average JSON bytes per value, see json_sizehint.go
+}
+
+// planField describes how one struct field is decoded, and the directives
+// the encoders honor
+type planField struct {
+	name        string       // Go field name, used in error paths
+	key         string       // tiny name= key, "" for none
+	jsonName    string       // json tag name, written when there is no tiny name=
+	secure      bool         // value is sealed with the registered Cipher
+	quoted      bool         // tagged json:",string" or tiny string, the value may arrive inside a JSON string
+	omitEmpty   bool         // tiny omitempty: empty values are not encoded
+	omitZero    bool         // tiny omitzero: only the zero value of the type is not encoded
+	skipDecode  bool         // tiny skipdecode: the member is skipped when decoding
+	required    bool         // tiny required: the member must be sent
+	redact      bool         // tiny redact: encoded as "***"
+	tag         string       // tiny tag text, offered to encode hooks
+	unexported  bool         // lower-case Go name: never encoded, decoded only through setter
+	setter      *fieldSetter // registered setter of an unexported field, nil otherwise
+	defaultJson string       // tiny default= framed as JSON, see defaults
+	parse       scalarParser // setter for plain scalar fields, nil otherwise
+}
+
+// scalarParser decodes the framed text of a scalar value into target
+type scalarParser func(jh *jsonH, jsonStr string, target *refValue) error
+
+// decodePlans caches a *decodePlan per *refType
+var decodePlans sync.Map
+
+// decodePlanFor returns the decode plan for the struct held by target,
+// compiling and caching it on first use
+func decodePlanFor(target *refValue) (*decodePlan, error) {
+	t := target.Type()
+	if plan, ok := decodePlans.Load(t); ok {
+		return plan.(*decodePlan), nil
+	}
+
+	var structInfo refStructType
+	if err := structMetadataFor(target, &structInfo); err != nil {
+		return nil, err
+	}
+	plan, _ := decodePlans.LoadOrStore(t, compileDecodePlan(target, &structInfo))
+	return plan.(*decodePlan), nil
+}
+
+// compileDecodePlan builds the lookups for the struct held by target
+// The first field claiming a name keeps it, matching the order in which
+// findStructFieldByJsonName tries fields
+func compileDecodePlan(target *refValue, structInfo *refStructType) *decodePlan {
+	n := len(structInfo.fields)
+	plan := &decodePlan{
+		byTag:   make(map[string]int, n),
+		byName:  make(map[string]int, n),
+		bySnake: make(map[string]int, n),
+		byCamel: make(map[string]int, n),
+		byFold:  make(map[string]int, n),
+		fields:  make([]planField, n),
+	}
+
+	// Unexported fields get no keys unless a setter was registered for them
+	tags := make([]tinyTag, n)
+	hidden := make([]bool, n)
+	for i, field := range structInfo.fields {
+		if !isExportedField(field.name) {
+			plan.fields[i].unexported = true
+			if setter, ok := fieldSetterFor(target.Type(), field.name); ok {
+				plan.fields[i].setter = &setter
+			} else {
+				hidden[i] = true
+				continue
+			}
+		}
+
+		// tiny name= keys come first, ahead of every json tag
+		tags[i] = parseTinyTag(field.tag.Get("tiny"))
+		if def := field.tag.Get("default"); def != "" && !tags[i].hasDefault {
+			tags[i].hasDefault, tags[i].defaultVal = true, def
+		}
+		if tags[i].name != "" {
+			addPlanKey(plan.byTag, tags[i].name, i)
+			addPlanKey(plan.byFold, lowerASCII(tags[i].name), i)
+		}
+	}
+	for i, field := range structInfo.fields {
+		if hidden[i] {
+			plan.fields[i].name = field.name
+			continue
+		}
+		if jsonName := jsonTagName(field.tag.Get("json")); jsonName != "" {
+			addPlanKey(plan.byTag, jsonName, i)
+			addPlanKey(plan.byFold, lowerASCII(jsonName), i)
+		}
+		addPlanKey(plan.byName, field.name, i)
+		addPlanKey(plan.bySnake, toSnakeCase(field.name), i)
+		addPlanKey(plan.byCamel, toCamelCase(field.name), i)
+
+		plan.fields[i] = planField{
+			name:       field.name,
+			unexported: plan.fields[i].unexported,
+			setter:     plan.fields[i].setter,
+			key:        tags[i].name,
+			jsonName:   jsonTagName(field.tag.Get("json")),
+			secure:     isEncryptedField(field.tag.Get("secure")),
+			omitEmpty:  tags[i].omitEmpty,
+			omitZero:   tags[i].omitZero,
+			skipDecode: tags[i].skipDecode,
+			required:   tags[i].required,
+			redact:     tags[i].redact,
+			tag:        field.tag.Get("tiny"),
+		}
+		plan.required = plan.required || tags[i].required
+		if fieldConv := target.refField(i); fieldConv.refIsValid() {
+			quoted := jsonTagHasOption(field.tag.Get("json"), "string") || tags[i].quoted
+			plan.fields[i].parse = scalarParserFor(fieldConv)
+			plan.fields[i].quoted = quoted && isQuotableType(fieldConv.Type()) &&
+				customCodecFor(fieldConv)&codecUnmarshalPtr == 0 && !isBigNumberType(fieldConv)
+			if plan.fields[i].unexported {
+				// Filled by its setter only
+			} else if tags[i].hasDefault {
+				plan.fields[i].defaultJson = tinyDefaultJson(tags[i].defaultVal, fieldConv.Type())
+				plan.defaults = append(plan.defaults, i)
+			} else if hasNestedDefaults(fieldConv) {
+				plan.defaults = append(plan.defaults, i)
+			}
+		}
+	}
+	// Go names fold after every tag name, as tags take precedence
+	for i, field := range structInfo.fields {
+		if !hidden[i] {
+			addPlanKey(plan.byFold, lowerASCII(field.name), i)
+		}
+	}
+	return plan
+}
+
+// addPlanKey maps key to index unless an earlier field already claimed it
+func addPlanKey(m map[string]int, key string, index int) {
+	if _, taken := m[key]; !taken {
+		m[key] = index
+	}
+}
+
+// fieldIndex returns the index of the field matching jsonKey under match, or -1
+// Equivalent to findStructFieldByJsonName without scanning the fields
+func (p *decodePlan) fieldIndex(jsonKey string, match FieldMatch) int {
+	if i, ok := p.byTag[jsonKey]; ok {
+		return i
+	}
+	if i, ok := p.byName[jsonKey]; ok {
+		return i
+	}
+	if match == MatchExact {
+		return -1
+	}
+	if i, ok := p.bySnake[jsonKey]; ok {
+		return i
+	}
+	if i, ok := p.byCamel[jsonKey]; ok {
+		return i
+	}
+	if match != MatchCaseInsensitive {
+		return -1
+	}
+	if i, ok := p.byFold[lowerASCII(jsonKey)]; ok {
+		return i
+	}
+	return -1
+}
+
+// scalarParserFor returns the setter for a plain string, number or bool field
+// Types with their own decoding (time.Time, RawJSON, BigNumber, custom
+// unmarshalers) and containers get nil and take the general path
+func scalarParserFor(v *refValue) scalarParser {
+	if isTimeType(v) || isRawJSONType(v) || isBigNumberType(v) || customCodecFor(v)&codecUnmarshalPtr != 0 {
+		return nil
+	}
+	switch v.refKind() {
+	case tpString:
+		return (*jsonH).parseJsonStringRef
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return (*jsonH).parseJsonIntRef
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return (*jsonH).parseJsonUintRef
+	case tpFloat32, tpFloat64:
+		return (*jsonH).parseJsonFloatRef
+	case tpBool:
+		return (*jsonH).parseJsonBoolRef
+	}
+	return nil
+}
+
+// lowerASCII returns s with ASCII letters lower-cased, s itself when unchanged
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if 'A' <= s[i] && s[i] <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if 'A' <= b[j] && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package tinywodp
+
+import (
+	"testing"
+)
+
+func TestDecodePlanMatchesFieldScan(t *testing.T) {
+	clearRefStructsCache()
+
+	type Account struct {
+		ID        string `json:"id"`
+		UserName  string
+		Email     string `json:"mail,omitempty"`
+		Mail      string
+		CreatedAt int64
+		Balance   float64
+		Active    bool
+		Tags      []string
+	}
+
+	target := refValueOf(&Account{}).refElem()
+	plan, err := decodePlanFor(target)
+	if err != nil {
+		t.Fatalf("decodePlanFor returned error: %v", err)
+	}
+	if again, _ := decodePlanFor(target); again != plan {
+		t.Error("decodePlanFor should return the cached plan")
+	}
+
+	var structInfo refStructType
+	if err := structMetadataFor(target, &structInfo); err != nil {
+		t.Fatalf("structMetadataFor returned error: %v", err)
+	}
+
+	keys := []string{"id", "ID", "UserName", "user_name", "USERNAME", "mail", "Mail", "MAIL",
+		"created_at", "CreatedAt", "balance", "ACTIVE", "tags", "missing", ""}
+	for _, match := range []FieldMatch{MatchDefault, MatchExact, MatchCaseInsensitive} {
+		for _, key := range keys {
+			expected := findStructFieldByJsonName(key, &structInfo, match)
+			if got := plan.fieldIndex(key, match); got != expected {
+				t.Errorf("fieldIndex(%q, %v) = %d, expected %d", key, match, got, expected)
+			}
+		}
+	}
+
+	// Scalars get a direct setter, containers take the general path
+	for i, field := range plan.fields {
+		if (field.parse == nil) != (field.name == "Tags") {
+			t.Errorf("field %d (%s): setter present = %v", i, field.name, field.parse != nil)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tinywodp
+
+import (
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Error positions
+// Decoding works on substrings of the input, so the failing value's offset can
+// be recovered from its address without tracking positions while parsing. The
+// innermost failing value is recorded and the top-level error gains its line,
+// column and a short snippet of the input around it:
+//
+//	invalid json invalid number: abc in Users[1].Age at line 3 column 12 near: "age": abc, "ci
+
+// jsonSnippetRadius is the number of bytes shown on each side of an error
+const jsonSnippetRadius = 16
+
+// trimJsonSpace returns s without leading and trailing JSON whitespace
+// The result shares memory with s, which keeps error offsets recoverable
+func trimJsonSpace(s string) string {
+	start := skipJsonSpace(s, 0)
+	end := len(s)
+	for end > start && isJsonSpace(s[end-1]) {
+		end--
+	}
+	return s[start:end]
+}
+
+// markJsonError records s[i:] as the error position when s lies within the
+// input being decoded; only the first (innermost) position is kept
+func (jh *jsonH) markJsonError(s string, i int) {
+	if jh.jErrAt >= 0 || len(s) == 0 || len(jh.jInput) == 0 {
+		return
+	}
+	base := uintptr(unsafe.Pointer(unsafe.StringData(jh.jInput)))
+	p := uintptr(unsafe.Pointer(unsafe.StringData(s)))
+	if p < base || p+uintptr(len(s)) > base+uintptr(len(jh.jInput)) {
+		return // a decoded copy, the enclosing value will be marked instead
+	}
+	if i > len(s) {
+		i = len(s)
+	}
+	jh.jErrAt = int(p-base) + i
+}
+
+// errorAt marks s[i:] as the error position and returns err
+func (jh *jsonH) errorAt(s string, i int, err error) error {
+	jh.markJsonError(s, i)
+	return err
+}
+
+// positionError adds the Go path, line, column and snippet recorded for err
+func (jh *jsonH) positionError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if jh.jErrPath != "" {
+		err = Err(err.Error(), "in", jh.jErrPath)
+	}
+	if jh.jErrAt < 0 || jh.jErrAt > len(jh.jInput) {
+		return err
+	}
+	line, column := jsonLineColumn(jh.jInput, jh.jErrAt)
+	return Err(err.Error(), "at line", line, "column", column, "near:", jsonSnippet(jh.jInput, jh.jErrAt))
+}
+
+// jsonLineColumn returns the 1-based line and column of offset in s
+// Columns count runes so they match what editors show
+func jsonLineColumn(s string, offset int) (int, int) {
+	line, column := 1, 1
+	for i := 0; i < offset; i++ {
+		switch {
+		case s[i] == '\n':
+			line++
+			column = 1
+		case s[i]&0xC0 != 0x80: // not a UTF-8 continuation byte
+			column++
+		}
+	}
+	return line, column
+}
+
+// jsonSnippet returns the input around offset on a single line
+func jsonSnippet(s string, offset int) string {
+	start, end := offset-jsonSnippetRadius, offset+jsonSnippetRadius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(s) {
+		end = len(s)
+	}
+	// Never cut a multi-byte character in half
+	for start > 0 && s[start]&0xC0 == 0x80 {
+		start--
+	}
+	for end < len(s) && s[end]&0xC0 == 0x80 {
+		end++
+	}
+
+	snippet := []byte(s[start:end])
+	for i, b := range snippet {
+		if b == '\n' || b == '\r' || b == '\t' {
+			snippet[i] = ' '
+		}
+	}
+	return string(snippet)
+}
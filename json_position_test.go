@@ -0,0 +1,65 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonDecodeErrorPosition(t *testing.T) {
+	clearRefStructsCache()
+
+	type Row struct {
+		Name string
+		Age  int
+		Tags []int
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		target   func() any
+		position string
+		near     string
+	}{
+		{"typed field", "{\n  \"Name\": \"ana\",\n  \"Age\": abc\n}", func() any { return new(Row) }, "line 3 column 10", `"Age": abc`},
+		{"slice element", `{"Name":"ñandú","Tags":[1,2,"x"]}`, func() any { return new(Row) }, "line 1 column 29", `[1,2,"x"]`},
+		{"missing comma", "[\n{\"Age\":1}\n{\"Age\":2}]", func() any { return new([]Row) }, "line 3 column 1", `{"Age":2}`},
+		{"generic value", "{\"a\": [1, tru]}", func() any { return new(any) }, "line 1 column 11", "tru]"},
+	}
+
+	for _, test := range tests {
+		err := Convert(test.input).JsonDecode(test.target())
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		msg := err.Error()
+		if !Contains(msg, string(ErrSyntax)) || !Contains(msg, test.position) || !Contains(msg, test.near) {
+			t.Errorf("%s: error %q should contain %q and %q", test.name, msg, test.position, test.near)
+		}
+	}
+
+	// Document paths report positions within the whole document
+	doc, err := ParseDocument([]byte("{\"a\": {\"b\": 1},\n \"c\": {\"d\": nope}}"))
+	if err != nil {
+		t.Fatalf("ParseDocument returned error: %v", err)
+	}
+	var d int
+	if err := doc.DecodePath("c.d", &d); err == nil || !Contains(err.Error(), "line 2 column 13") {
+		t.Errorf("DecodePath error = %v, expected line 2 column 13", err)
+	}
+}
+
+func TestJsonSnippetKeepsRunes(t *testing.T) {
+	s := "ñññññññññññññññññññññ"
+	snippet := jsonSnippet(s, 11) // inside the second byte of a rune
+	for _, r := range snippet {
+		if r != 'ñ' {
+			t.Fatalf("snippet %q cut a multi-byte character", snippet)
+		}
+	}
+	if line, column := jsonLineColumn("a\nñb", 5); line != 2 || column != 3 {
+		t.Errorf("jsonLineColumn = %d:%d, expected 2:3", line, column)
+	}
+}
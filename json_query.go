@@ -0,0 +1,475 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// JSONPath queries
+// A JSONPath subset evaluated over raw JSON text: only the parts of the input on
+// the way to a match are scanned, and matches come back as raw segments or are
+// decoded together into a slice:
+//
+//	doc, err := ParseDocument(body)
+//	cities, err := doc.Query("$.profile.addresses[*].city") // [`"Lima"`, `"Quito"`]
+//
+//	var homes []Address
+//	err = Convert(body).JsonQuery(`$.profile.addresses[?(@.type == 'home')]`, &homes)
+//
+// Supported syntax:
+//
+//	$                   the root, optional
+//	.name  ['name']     object member, matched exactly
+//	[2]  [-1]           array element, negative indexes count from the end
+//	.*  [*]             every member value or element
+//	..name  ..*         recursive descent: the selector applies at every depth
+//	[?(@.a.b == 'x')]   elements (or member values) whose relative path equals a
+//	                    string, number, true, false or null literal; != negates,
+//	                    and a missing path never matches
+//
+// Matches are returned in document order; no match is an empty result, not an
+// error.
+
+// jsonPathStep is one selector of a parsed path
+type jsonPathStep struct {
+	kind   byte   // '.' member, '#' index, '*' wildcard, 'd' descendants, '?' filter
+	name   string // member name
+	index  int    // element index, negative from the end
+	filter *jsonPathFilter
+}
+
+// jsonPathFilter is an equality filter [?(@.path == literal)]
+type jsonPathFilter struct {
+	path    []jsonPathStep // relative to @, members and indexes only
+	equal   bool           // == rather than !=
+	literal string         // JSON text of the literal
+}
+
+// Query returns the raw JSON of every value matched by the JSONPath expression
+func (d *Document) Query(path string) ([]string, error) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.jInput = d.raw
+	matches, err := jh.queryJsonPath(d.raw, path)
+	return matches, jh.positionError(err)
+}
+
+// QueryDecode decodes every value matched by the JSONPath expression into the
+// slice or array target points to, as if the matches formed one JSON array
+func (d *Document) QueryDecode(path string, target any, opts ...DecodeOption) error {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	return jh.decodeQuery(d.raw, path, target)
+}
+
+// JsonQuery decodes every value matched by the JSONPath expression into the
+// slice or array target points to (see Document.Query for the syntax)
+//
+//	var cities []string
+//	err := Convert(jsonStr).JsonQuery("$..city", &cities)
+func (c *refValue) JsonQuery(path string, target any, opts ...DecodeOption) error {
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	return jh.decodeQuery(c.getString(), path, target)
+}
+
+// decodeQuery evaluates path over raw and decodes the matches into target
+func (jh *jsonH) decodeQuery(raw, path string, target any) error {
+	if isJsonBlank(raw) {
+		return Err(ErrEmptyInput)
+	}
+	jh.jInput = raw
+	matches, err := jh.queryJsonPath(raw, path)
+	if err != nil {
+		return jh.positionError(err)
+	}
+
+	size := 2
+	for _, m := range matches {
+		size += len(m) + 1
+	}
+	array := make([]byte, 0, size)
+	array = append(array, '[')
+	for i, m := range matches {
+		if i > 0 {
+			array = append(array, ',')
+		}
+		array = append(array, m...)
+	}
+	array = append(array, ']')
+
+	// Positions of decode errors refer to the gathered matches
+	jh.jInput = string(array)
+	return jh.decode(jh.jInput, target)
+}
+
+// queryJsonPath returns the raw values of raw matched by path
+func (jh *jsonH) queryJsonPath(raw, path string) ([]string, error) {
+	steps, err := parseJsonPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return jh.evalJsonPath(trimJsonSpace(raw), steps)
+}
+
+// evalJsonPath applies the steps in turn to the set of matched nodes
+func (jh *jsonH) evalJsonPath(raw string, steps []jsonPathStep) ([]string, error) {
+	nodes := []string{raw}
+	for _, step := range steps {
+		var next []string
+		for _, node := range nodes {
+			var err error
+			if next, err = jh.applyJsonPathStep(next, node, &step); err != nil {
+				return nil, err
+			}
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// applyJsonPathStep appends the nodes step selects from node to dst
+func (jh *jsonH) applyJsonPathStep(dst []string, node string, step *jsonPathStep) ([]string, error) {
+	switch step.kind {
+	case 'd':
+		return jh.appendJsonDescendants(dst, node)
+	case '#':
+		if node == "" || node[0] != '[' {
+			return dst, nil
+		}
+		var elems []string
+		err := jh.eachJsonChild(node, func(_ string, value string) error {
+			elems = append(elems, value)
+			return nil
+		})
+		index := step.index
+		if index < 0 {
+			index += len(elems)
+		}
+		if err == nil && index >= 0 && index < len(elems) {
+			dst = append(dst, elems[index])
+		}
+		return dst, err
+	case '.':
+		if node == "" || node[0] != '{' {
+			return dst, nil
+		}
+	}
+
+	err := jh.eachJsonChild(node, func(key string, value string) error {
+		switch step.kind {
+		case '.':
+			if key == step.name {
+				dst = append(dst, value)
+			}
+		case '*':
+			dst = append(dst, value)
+		case '?':
+			matched, err := jh.matchJsonPathFilter(value, step.filter)
+			if err != nil {
+				return err
+			}
+			if matched {
+				dst = append(dst, value)
+			}
+		}
+		return nil
+	})
+	return dst, err
+}
+
+// appendJsonDescendants appends node and every value nested in it, in
+// document order
+func (jh *jsonH) appendJsonDescendants(dst []string, node string) ([]string, error) {
+	if err := jh.enterJsonDepth(); err != nil {
+		return dst, err
+	}
+	defer jh.leaveJsonDepth()
+
+	dst = append(dst, node)
+	err := jh.eachJsonChild(node, func(_ string, value string) error {
+		var err error
+		dst, err = jh.appendJsonDescendants(dst, value)
+		return err
+	})
+	return dst, err
+}
+
+// matchJsonPathFilter reports whether value passes the filter
+func (jh *jsonH) matchJsonPathFilter(value string, filter *jsonPathFilter) (bool, error) {
+	found, err := jh.evalJsonPath(value, filter.path)
+	if err != nil || len(found) == 0 {
+		return false, err
+	}
+	equal, err := jh.jsonRawEqual(found[0], filter.literal)
+	return equal == filter.equal, err
+}
+
+// jsonRawEqual compares two raw JSON scalars: strings by their unescaped text,
+// numbers by value and literals exactly; containers never match
+func (jh *jsonH) jsonRawEqual(a, b string) (bool, error) {
+	switch {
+	case a == "" || b == "":
+		return false, nil
+	case a[0] == '"' && b[0] == '"':
+		x, err := jh.unescapeJsonString(a[1 : len(a)-1])
+		if err != nil {
+			return false, err
+		}
+		y, err := jh.unescapeJsonString(b[1 : len(b)-1])
+		return x == y, err
+	case isJsonNumber(a) && isJsonNumber(b):
+		x, _ := parseJsonFloat(a, 64)
+		y, _ := parseJsonFloat(b, 64)
+		return x == y, nil
+	case a[0] == '{' || a[0] == '[':
+		return false, nil
+	}
+	return a == b, nil
+}
+
+// eachJsonChild calls fn with each member of the object raw, or with each
+// element of the array raw and an empty key; scalars have no children
+func (jh *jsonH) eachJsonChild(raw string, fn func(key, value string) error) error {
+	if raw == "" || (raw[0] != '{' && raw[0] != '[') {
+		return nil
+	}
+	object := raw[0] == '{'
+	close := byte(']')
+	if object {
+		close = '}'
+	}
+
+	i := skipJsonSpace(raw, 1)
+	if i < len(raw) && raw[i] == close {
+		return nil
+	}
+	for done := false; !done; {
+		i = skipJsonSpace(raw, i)
+		key := ""
+		if object {
+			if i >= len(raw) || raw[i] != '"' {
+				return jh.errorAt(raw, i, Err(errInvalidJSON, "expected object key"))
+			}
+			keyEnd, err := skipJsonString(raw, i)
+			if err != nil {
+				return jh.errorAt(raw, keyEnd, err)
+			}
+			if key = raw[i+1 : keyEnd-1]; indexByte(key, '\\') != -1 {
+				if key, err = jh.unescapeJsonString(key); err != nil {
+					return jh.errorAt(raw, i, err)
+				}
+			}
+			if i = skipJsonSpace(raw, keyEnd); i >= len(raw) || raw[i] != ':' {
+				return jh.errorAt(raw, i, Err(errInvalidJSON, "missing ':' after key "+key))
+			}
+			i = skipJsonSpace(raw, i+1)
+		}
+
+		end, err := skipJsonValue(raw, i)
+		if err != nil {
+			return jh.errorAt(raw, end, err)
+		}
+		if err := fn(key, raw[i:end]); err != nil {
+			return err
+		}
+		if i, done, err = jh.nextJsonMember(raw, end, close); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseJsonPath compiles a JSONPath expression into steps
+func parseJsonPath(path string) ([]jsonPathStep, error) {
+	var steps []jsonPathStep
+	i := 0
+	if len(path) > 0 && path[0] == '$' {
+		i = 1
+	}
+	for i < len(path) {
+		var step jsonPathStep
+		var err error
+		switch {
+		case path[i] == '[':
+			step, i, err = parseJsonPathBracket(path, i)
+		case path[i] == '.' && i+1 < len(path) && path[i+1] == '.':
+			steps = append(steps, jsonPathStep{kind: 'd'})
+			if i += 2; i < len(path) && path[i] == '[' {
+				continue
+			}
+			step, i, err = parseJsonPathName(path, i)
+		case path[i] == '.':
+			step, i, err = parseJsonPathName(path, i+1)
+		case i == 0:
+			step, i, err = parseJsonPathName(path, i) // "profile.name" without "$."
+		default:
+			err = Err(errInvalidPath, "unexpected character at", Convert(i).String()+":", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseJsonPathName parses a dotted member name or '*' at path[i]
+func parseJsonPathName(path string, i int) (jsonPathStep, int, error) {
+	end := i
+	for end < len(path) && path[end] != '.' && path[end] != '[' {
+		end++
+	}
+	switch name := path[i:end]; name {
+	case "":
+		return jsonPathStep{}, end, Err(errInvalidPath, "empty member name in", path)
+	case "*":
+		return jsonPathStep{kind: '*'}, end, nil
+	default:
+		return jsonPathStep{kind: '.', name: name}, end, nil
+	}
+}
+
+// parseJsonPathBracket parses the bracketed selector at path[i]
+func parseJsonPathBracket(path string, i int) (jsonPathStep, int, error) {
+	var step jsonPathStep
+	i = skipJsonSpace(path, i+1)
+	if i >= len(path) {
+		return step, i, Err(errInvalidPath, "unclosed [ in", path)
+	}
+
+	var err error
+	switch c := path[i]; {
+	case c == '*':
+		step.kind = '*'
+		i++
+	case c == '\'' || c == '"':
+		step.kind = '.'
+		step.name, i, err = parseJsonPathString(path, i)
+	case c == '?':
+		step.kind = '?'
+		step.filter, i, err = parseJsonPathFilter(path, i+1)
+	default:
+		end := i
+		for end < len(path) && path[end] != ']' && path[end] != ' ' {
+			end++
+		}
+		literal := path[i:end]
+		index, convErr := Convert(literal).ToInt64()
+		if convErr != nil || !isJsonNumber(literal) || !isJsonIntegerLiteral(literal) {
+			return step, end, Err(errInvalidPath, "invalid index "+literal+" in", path)
+		}
+		step.kind, step.index, i = '#', int(index), end
+	}
+	if err != nil {
+		return step, i, err
+	}
+
+	if i = skipJsonSpace(path, i); i >= len(path) || path[i] != ']' {
+		return step, i, Err(errInvalidPath, "expected ] in", path)
+	}
+	return step, i + 1, nil
+}
+
+// parseJsonPathString parses a quoted name at path[i]: single quotes hold the
+// text as is, double quotes a JSON string
+func parseJsonPathString(path string, i int) (string, int, error) {
+	if path[i] == '"' {
+		end, err := skipJsonString(path, i)
+		if err != nil {
+			return "", end, Err(errInvalidPath, "invalid string in", path)
+		}
+		jh := getJsonH("_")
+		defer putJsonH(jh)
+		name, err := jh.unescapeJsonString(path[i+1 : end-1])
+		return name, end, err
+	}
+	end := indexByte(path[i+1:], '\'')
+	if end == -1 {
+		return "", len(path), Err(errInvalidPath, "unclosed quote in", path)
+	}
+	return path[i+1 : i+1+end], i + end + 2, nil
+}
+
+// parseJsonPathFilter parses "(@.path == literal)" at path[i]
+func parseJsonPathFilter(path string, i int) (*jsonPathFilter, int, error) {
+	filter := &jsonPathFilter{}
+	if i = skipJsonSpace(path, i); i+1 >= len(path) || path[i] != '(' {
+		return nil, i, Err(errInvalidPath, "expected ?( in", path)
+	}
+	if i = skipJsonSpace(path, i+1); i >= len(path) || path[i] != '@' {
+		return nil, i, Err(errInvalidPath, "filter must start with @ in", path)
+	}
+
+	// Relative path up to the operator
+	for i++; i < len(path) && (path[i] == '.' || path[i] == '['); {
+		var step jsonPathStep
+		var err error
+		if path[i] == '[' {
+			step, i, err = parseJsonPathBracket(path, i)
+		} else {
+			end := i + 1
+			for end < len(path) && path[end] != '.' && path[end] != '[' && path[end] != ' ' &&
+				path[end] != '=' && path[end] != '!' && path[end] != ')' {
+				end++
+			}
+			step, _, err = parseJsonPathName(path[:end], i+1)
+			i = end
+		}
+		if err != nil {
+			return nil, i, err
+		}
+		if step.kind != '.' && step.kind != '#' {
+			return nil, i, Err(errInvalidPath, "filter paths hold members and indexes only:", path)
+		}
+		filter.path = append(filter.path, step)
+	}
+
+	i = skipJsonSpace(path, i)
+	switch {
+	case i+1 < len(path) && path[i:i+2] == "==":
+		filter.equal = true
+	case i+1 < len(path) && path[i:i+2] == "!=":
+	default:
+		return nil, i, Err(errInvalidPath, "expected == or != in", path)
+	}
+
+	// Literal up to the closing parenthesis
+	i = skipJsonSpace(path, i+2)
+	if i >= len(path) {
+		return nil, i, Err(errInvalidPath, "missing filter value in", path)
+	}
+	switch path[i] {
+	case '\'':
+		text, end, err := parseJsonPathString(path, i)
+		if err != nil {
+			return nil, end, err
+		}
+		filter.literal, i = string(escapeAndQuoteJsonString(nil, text, false)), end
+	case '"':
+		end, err := skipJsonString(path, i)
+		if err != nil {
+			return nil, end, Err(errInvalidPath, "invalid string in", path)
+		}
+		filter.literal, i = path[i:end], end
+	default:
+		end := i
+		for end < len(path) && path[end] != ')' && path[end] != ' ' {
+			end++
+		}
+		literal := path[i:end]
+		if literal != "true" && literal != "false" && literal != "null" && !isJsonNumber(literal) {
+			return nil, end, Err(errInvalidPath, "invalid filter value "+literal+" in", path)
+		}
+		filter.literal, i = literal, end
+	}
+
+	if i = skipJsonSpace(path, i); i >= len(path) || path[i] != ')' {
+		return nil, i, Err(errInvalidPath, "expected ) in", path)
+	}
+	return filter, i + 1, nil
+}
@@ -0,0 +1,100 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+const queryTestDocument = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword", "price": 12.99, "tags": ["war"]},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord", "price": 22.99, "isbn": "0-395"}
+		],
+		"bicycle": {"color": "red", "price": 19.95},
+		"a.b": {"x\"y": 1}
+	}
+}`
+
+func TestDocumentQuery(t *testing.T) {
+	doc, err := ParseDocument([]byte(queryTestDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"$.store.book[*].author", []string{`"Nigel Rees"`, `"Evelyn Waugh"`, `"J. R. R. Tolkien"`}},
+		{"store.bicycle.color", []string{`"red"`}},
+		{"$.store.book[-1].title", []string{`"The Lord"`}},
+		{"$['store']['a.b'][\"x\\\"y\"]", []string{`1`}},
+		{"$..price", []string{`8.95`, `12.99`, `22.99`, `19.95`}},
+		{"$..book[0].title", []string{`"Sayings"`}},
+		{"$.store.book[?(@.category == 'fiction')].title", []string{`"Sword"`, `"The Lord"`}},
+		{"$.store.book[?(@.price == 8.950)].title", []string{`"Sayings"`}},
+		{`$.store.book[?(@.category != "fiction")].author`, []string{`"Nigel Rees"`}},
+		{"$.store.book[?(@.tags[0] == 'war')].title", []string{`"Sword"`}},
+		{"$.store.bicycle.*", []string{`"red"`, `19.95`}},
+		{"$.store.book[5]", nil},
+		{"$.store.missing[*]", nil},
+		{"$", []string{trimJsonSpace(queryTestDocument)}},
+	}
+	for _, tt := range tests {
+		got, err := doc.Query(tt.path)
+		if err != nil {
+			t.Errorf("Query(%s) returned error: %v", tt.path, err)
+			continue
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("Query(%s) = %v, expected %v", tt.path, got, tt.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("Query(%s)[%d] = %s, expected %s", tt.path, i, got[i], tt.expected[i])
+			}
+		}
+	}
+
+	for _, path := range []string{"$.", "$.store[", "$.store.book[01]", "$.book[?(@.a = 1)]", "$.book[?(@.a == x)]", "$.book[?(a == 1)]", "$x"} {
+		if _, err := doc.Query(path); err == nil || !Contains(err.Error(), string(errInvalidPath)) {
+			t.Errorf("Query(%s) error = %v", path, err)
+		}
+	}
+}
+
+func TestJsonQueryDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	type Book struct {
+		Title string  `json:"title"`
+		Price float64 `json:"price"`
+	}
+
+	var fiction []Book
+	if err := Convert(queryTestDocument).JsonQuery("$.store.book[?(@.category == 'fiction')]", &fiction); err != nil {
+		t.Fatalf("JsonQuery returned error: %v", err)
+	}
+	if len(fiction) != 2 || fiction[0].Title != "Sword" || fiction[1].Price != 22.99 {
+		t.Errorf("JsonQuery = %+v", fiction)
+	}
+
+	var prices []float64
+	doc, _ := ParseDocument([]byte(queryTestDocument))
+	if err := doc.QueryDecode("$..price", &prices); err != nil || len(prices) != 4 || prices[3] != 19.95 {
+		t.Errorf("QueryDecode = %v, %v", prices, err)
+	}
+
+	var none []string
+	if err := Convert(queryTestDocument).JsonQuery("$.nothing", &none); err != nil || len(none) != 0 {
+		t.Errorf("JsonQuery without matches = %v, %v", none, err)
+	}
+	var titles []int
+	if err := Convert(queryTestDocument).JsonQuery("$..title", &titles); err == nil {
+		t.Error("JsonQuery into a mismatched slice should fail")
+	}
+}
@@ -0,0 +1,85 @@
+package tinywodp
+
+// String-quoted scalars
+// The standard `json:",string"` tag option writes a number, bool or string
+// field inside a JSON string, as many APIs send numeric IDs:
+//
+//	type Order struct {
+//		ID    int64    `json:"id,string"` // {"id":"12345"}
+//		Paid  bool     `json:",string"`   // {"Paid":"true"}
+//		Total *float64 `json:",string"`   // pointers to scalars too; nil stays null
+//	}
+//
+// Decoding such a field accepts the quoted form and the plain one. The option
+// is ignored on other kinds and on types with their own codec, like encoding/json.
+
+// isJsonQuotedField reports whether the field of type t, tagged with tag,
+// is encoded inside a JSON string
+func isJsonQuotedField(tag string, t *refType) bool {
+	return jsonTagHasOption(tag, "string") && isQuotableType(t)
+}
+
+// isQuotableType reports whether values of type t can be written inside a
+// JSON string: scalars and pointers to scalars
+func isQuotableType(t *refType) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+	return t != nil && isScalarType(t)
+}
+
+// appendJsonQuotedValue appends a field tagged json:",string": 42 becomes "42",
+// true becomes "true" and the string go becomes "\"go\""
+func (jh *jsonH) appendJsonQuotedValue(dst []byte, v *refValue) ([]byte, error) {
+	if v.refKind() == tpPointer {
+		elem := v.refElem()
+		if !elem.refIsValid() {
+			return append(dst, "null"...), nil
+		}
+		v = elem
+	}
+	if customCodecFor(v) != 0 || isBigNumberType(v) || stdEncodeHook != nil {
+		return jh.appendJsonFieldValue(dst, v)
+	}
+	if v.refKind() == tpString {
+		// Quoted twice: the JSON string literal becomes the string's content
+		jh.jEsc = jh.quoteJsonString(jh.jEsc[:0], v.refString())
+		return jh.quoteJsonString(dst, string(jh.jEsc)), nil
+	}
+	mark := len(dst)
+	dst = append(dst, '"')
+	dst, err := jh.appendJsonFieldValue(dst, v)
+	if err != nil {
+		return dst, err
+	}
+	if string(dst[mark+1:]) == "null" {
+		// NaN and infinities written as null are not quoted
+		return append(dst[:mark], "null"...), nil
+	}
+	return append(dst, '"'), nil
+}
+
+// parseJsonQuotedField decodes the JSON string at s[i] into a field tagged
+// json:",string" by parsing its content as the field's value
+func (jh *jsonH) parseJsonQuotedField(name, s string, i int, target *refValue) (int, error) {
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return end, err
+	}
+	inner, err := jh.unescapeJsonString(s[i+1 : end-1])
+	if err != nil {
+		jh.prefixErrorPath(name)
+		return i, jh.errorAt(s, i, err)
+	}
+	if trimJsonSpace(inner) == "" {
+		jh.prefixErrorPath(name)
+		return i, jh.errorAt(s, i, Err(errInvalidJSON, "empty quoted value for ,string field"))
+	}
+	if _, err := jh.parseJsonField(name, inner, 0, target); err != nil {
+		return i, err
+	}
+	return end, nil
+}
@@ -0,0 +1,101 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonTagHasOption(t *testing.T) {
+	tests := []struct {
+		tag      string
+		option   string
+		expected bool
+	}{
+		{"id,string", "string", true},
+		{",string", "string", true},
+		{"id,omitempty,string", "string", true},
+		{"id,string,omitempty", "omitempty", true},
+		{"string", "string", false}, // a field named "string"
+		{"id,strings", "string", false},
+		{"", "string", false},
+	}
+	for _, tt := range tests {
+		if got := jsonTagHasOption(tt.tag, tt.option); got != tt.expected {
+			t.Errorf("jsonTagHasOption(%q, %q) = %v, expected %v", tt.tag, tt.option, got, tt.expected)
+		}
+	}
+}
+
+func TestJsonStringTagOption(t *testing.T) {
+	clearRefStructsCache()
+
+	type Account struct {
+		ID      int64    `json:"id,string"`
+		Balance float64  `json:"balance,string"`
+		Active  bool     `json:"active,string"`
+		Limit   *uint32  `json:"limit,string"`
+		Missing *int     `json:"missing,string"`
+		Label   string   `json:"label,string"`
+		Tags    []string `json:"tags,string"` // ignored on non-scalars
+		Plain   int      `json:"plain"`
+	}
+
+	limit := uint32(500)
+	in := Account{ID: 9007199254740993, Balance: 12.5, Active: true, Limit: &limit,
+		Label: `go "fast"`, Tags: []string{"a"}, Plain: 7}
+	expected := `{"id":"9007199254740993","balance":"12.5","active":"true","limit":"500","missing":null,` +
+		`"label":"\"go \\\"fast\\\"\"","tags":["a"],"plain":7}`
+
+	out, err := Convert(&in).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", out, expected)
+	}
+
+	var written []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if err := NewJsonEncoder(w).Encode(&in); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if string(written) != expected+"\n" {
+		t.Errorf("stream encoder wrote %s, expected %s", written, expected)
+	}
+
+	var decoded Account
+	if err := Convert(expected).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if decoded.ID != in.ID || decoded.Balance != in.Balance || !decoded.Active || decoded.Limit == nil ||
+		*decoded.Limit != limit || decoded.Missing != nil || decoded.Label != in.Label || decoded.Plain != 7 {
+		t.Errorf("JsonDecode = %+v, expected %+v", decoded, in)
+	}
+
+	// Tagged fields still accept plain values
+	var plain Account
+	if err := Convert(`{"id":42,"active":false,"limit":"7","balance":"-1e3"}`).JsonDecode(&plain); err != nil {
+		t.Fatalf("JsonDecode(plain) returned error: %v", err)
+	}
+	if plain.ID != 42 || plain.Active || plain.Limit == nil || *plain.Limit != 7 || plain.Balance != -1000 {
+		t.Errorf("JsonDecode(plain) = %+v", plain)
+	}
+
+	for _, input := range []string{`{"id":"12a"}`, `{"id":""}`, `{"active":"yes"}`, `{"limit":"-1"}`} {
+		var bad Account
+		err := Convert(input).JsonDecode(&bad)
+		if err == nil {
+			t.Errorf("JsonDecode(%s) should fail, got %+v", input, bad)
+		}
+	}
+
+	// Without the option a quoted number is still a type mismatch
+	var untagged Account
+	if err := Convert(`{"plain":"7"}`).JsonDecode(&untagged); err == nil {
+		t.Errorf("JsonDecode into untagged int should fail, got %+v", untagged)
+	}
+}
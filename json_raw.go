@@ -0,0 +1,75 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Raw JSON passthrough
+// A RawJSON field keeps its part of the document as-is: decoding copies the bytes
+// without parsing them and encoding writes them back without escaping. This
+// allows envelopes whose payload is decoded later, once its type is known:
+//
+//	type Envelope struct {
+//		Type    string
+//		Payload RawJSON
+//	}
+//
+//	var env Envelope
+//	err := Convert(data).JsonDecode(&env)
+//	if env.Type == "user" {
+//		err = Unmarshal(env.Payload, &user)
+//	}
+
+// RawJSON is an encoded JSON value stored verbatim, like encoding/json.RawMessage
+// A nil or empty RawJSON encodes as null
+type RawJSON []byte
+
+// MarshalJSONTiny returns r unchanged, or null when it is empty
+func (r RawJSON) MarshalJSONTiny() ([]byte, error) {
+	if len(r) == 0 {
+		return []byte("null"), nil
+	}
+	return r, nil
+}
+
+// UnmarshalJSONTiny stores a copy of data in r
+func (r *RawJSON) UnmarshalJSONTiny(data []byte) error {
+	if r == nil {
+		return Err(errInvalidJSON, "RawJSON: UnmarshalJSONTiny on nil pointer")
+	}
+	*r = append((*r)[:0], data...)
+	return nil
+}
+
+// rawJSONType is the type descriptor used to detect RawJSON values
+var rawJSONType = refValueOf(RawJSON(nil)).Type()
+
+// isRawJSONType reports whether v is a RawJSON
+func isRawJSONType(v *refValue) bool {
+	return v.Type() == rawJSONType
+}
+
+// encodeJsonRaw returns the stored text of a RawJSON value
+// The bytes must hold exactly one valid JSON value so the output stays well-formed
+func encodeJsonRaw(v *refValue) (string, error) {
+	raw := *(*RawJSON)(v.ptr)
+	if isJsonBlank(string(raw)) {
+		return "null", nil
+	}
+	s := Convert(string(raw)).Trim().String()
+	end, err := skipJsonValue(s, 0)
+	if err != nil {
+		return "", err
+	}
+	if end != len(s) {
+		return "", Err(errInvalidJSON, "RawJSON holds more than one value")
+	}
+	return s, nil
+}
+
+// decodeJsonRaw stores a copy of the already trimmed JSON text into a RawJSON target
+// The value was framed with skipJsonValue by the decoder, so it is copied without parsing
+func decodeJsonRaw(jsonStr string, target *refValue) error {
+	*(*RawJSON)(target.ptr) = RawJSON(jsonStr)
+	return nil
+}
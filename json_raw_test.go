@@ -0,0 +1,55 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonRawPassthrough(t *testing.T) {
+	type Envelope struct {
+		Type    string
+		Payload RawJSON
+		Extra   []RawJSON
+	}
+
+	input := `{"Type":"address","Payload": {"Id":"7", "Street":"Main \"St\""} ,"Extra":[1, "aé", null]}`
+
+	var env Envelope
+	if err := Convert(input).JsonDecode(&env); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if string(env.Payload) != `{"Id":"7", "Street":"Main \"St\""}` {
+		t.Errorf("Payload = %s, expected the original bytes", env.Payload)
+	}
+	if len(env.Extra) != 3 || string(env.Extra[1]) != `"aé"` || string(env.Extra[2]) != "null" {
+		t.Errorf("Extra = %q", env.Extra)
+	}
+
+	// Delayed decoding of the payload
+	var addr Address
+	if err := Unmarshal(env.Payload, &addr); err != nil || addr.Street != `Main "St"` {
+		t.Errorf("payload decode = %+v, %v", addr, err)
+	}
+
+	// Encoding writes the bytes back without escaping
+	out, err := Marshal(env)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	expected := `{"Type":"address","Payload":{"Id":"7", "Street":"Main \"St\""},"Extra":[1,"aé",null]}`
+	if string(out) != expected {
+		t.Errorf("Marshal = %s\nexpected %s", out, expected)
+	}
+
+	// Empty raw values encode as null, invalid ones fail instead of corrupting output
+	if out, err := Marshal(Envelope{Type: "x"}); err != nil || !Contains(string(out), `"Payload":null`) {
+		t.Errorf("Marshal empty payload = %s, %v", out, err)
+	}
+	if _, err := Marshal(RawJSON(`{"a":}`)); err == nil {
+		t.Error("Marshal of invalid RawJSON should return an error")
+	}
+	if _, err := Marshal(RawJSON(`1 2`)); err == nil {
+		t.Error("Marshal of RawJSON with two values should return an error")
+	}
+}
@@ -0,0 +1,134 @@
+package tinywodp
+
+// Relaxed syntax
+// Hand-written config files often carry comments and the trailing comma left
+// by the last edit. RelaxedSyntax accepts both, and nothing else beyond RFC
+// 8259, for one decode:
+//
+//	{
+//		// listen address
+//		"addr": ":8080",
+//		/* seconds */ "timeout": 30,
+//	}
+//
+//	err := Convert(configText).JsonDecode(&cfg, RelaxedSyntax)
+//
+// Comments and trailing commas are blanked out of a copy of the input before
+// it is decoded, keeping every other byte where it was, so error lines and
+// columns still match the file. Strict parsing stays the default; JsonCheck
+// honors the option too, while streaming decoders stay strict.
+
+// RelaxedSyntax makes a decode accept // and /* */ comments and a comma
+// before the closing } or ]
+var RelaxedSyntax DecodeOption = relaxedOption{}
+
+// relaxedOption is the DecodeOption behind RelaxedSyntax
+type relaxedOption struct{}
+
+// applyDecode enables relaxed syntax for the operation
+func (relaxedOption) applyDecode(jh *jsonH) {
+	jh.jRelaxed = true
+}
+
+// relaxJsonText returns s with comments and trailing commas replaced by
+// spaces; newlines inside block comments are kept, so offsets, lines and
+// columns are those of s. An unterminated block comment is left in place for
+// the decoder to report
+func relaxJsonText(s string) string {
+	b := []byte(s)
+	blankJsonComments(b)
+	blankTrailingCommas(b)
+	return string(b)
+}
+
+// blankJsonComments overwrites the comments of b outside strings with spaces
+func blankJsonComments(b []byte) {
+	inString, escaped := false, false
+	for i := 0; i < len(b); i++ {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b[i] == '\\':
+				escaped = true
+			case b[i] == '"':
+				inString = false
+			}
+			continue
+		}
+		if b[i] == '"' {
+			inString = true
+			continue
+		}
+		if b[i] != '/' || i+1 >= len(b) {
+			continue
+		}
+		switch b[i+1] {
+		case '/':
+			j := i
+			for j < len(b) && b[j] != '\n' {
+				b[j] = ' '
+				j++
+			}
+			i = j
+		case '*':
+			end := indexJsonCommentEnd(b, i+2)
+			if end == -1 {
+				return
+			}
+			for j := i; j < end; j++ {
+				if b[j] != '\n' {
+					b[j] = ' '
+				}
+			}
+			i = end - 1
+		}
+	}
+}
+
+// indexJsonCommentEnd returns the index just past the */ at or after b[i], -1
+// when there is none
+func indexJsonCommentEnd(b []byte, i int) int {
+	for ; i+1 < len(b); i++ {
+		if b[i] == '*' && b[i+1] == '/' {
+			return i + 2
+		}
+	}
+	return -1
+}
+
+// blankTrailingCommas overwrites with a space each comma of b outside strings
+// that follows a value and is followed, whitespace aside, by } or ]; [,] and
+// [1,,] stay invalid
+func blankTrailingCommas(b []byte) {
+	inString, escaped := false, false
+	var prev byte // last byte outside strings and whitespace
+	for i := 0; i < len(b); i++ {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b[i] == '\\':
+				escaped = true
+			case b[i] == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b[i] {
+		case '"':
+			inString = true
+		case ',':
+			if prev == ',' || prev == '[' || prev == '{' {
+				break
+			}
+			if next := nextNonSpace(b, i+1); next < len(b) && (b[next] == '}' || b[next] == ']') {
+				b[i] = ' '
+				continue
+			}
+		}
+		if !isJsonSpace(b[i]) {
+			prev = b[i]
+		}
+	}
+}
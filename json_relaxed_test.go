@@ -0,0 +1,60 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestRelaxedSyntax(t *testing.T) {
+	type config struct {
+		Addr    string
+		Timeout int
+		Hosts   []string
+	}
+	input := `{
+	// listen address
+	"Addr": "http://x//y", /* "//" inside strings is kept */
+	"Timeout": /* seconds */ 30,
+	"Hosts": ["a", "b",],
+}
+// done`
+
+	var cfg config
+	if err := Convert(input).JsonDecode(&cfg, RelaxedSyntax); err != nil {
+		t.Fatalf("relaxed decode returned error: %v", err)
+	}
+	if cfg.Addr != "http://x//y" || cfg.Timeout != 30 || len(cfg.Hosts) != 2 {
+		t.Errorf("decoded %+v", cfg)
+	}
+
+	// Strict by default
+	if err := Convert(input).JsonDecode(&cfg); err == nil || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("strict decode = %v, expected syntax error", err)
+	}
+	if err := Convert(input).JsonCheck(RelaxedSyntax); err != nil {
+		t.Errorf("JsonCheck(RelaxedSyntax) = %v", err)
+	}
+}
+
+func TestRelaxedSyntaxErrors(t *testing.T) {
+	var v any
+	// Positions still point into the original text
+	err := Convert("{\n/* a\nb */ \"x\": tru\n}").JsonDecode(&v, RelaxedSyntax)
+	if err == nil || !Contains(err.Error(), "line 3") {
+		t.Errorf("error = %v, expected a position on line 3", err)
+	}
+	for _, s := range []string{`[1,,]`, `[,]`, `{"a":1 /* open`, `// only a comment`} {
+		if err := Convert(s).JsonDecode(&v, RelaxedSyntax); err == nil {
+			t.Errorf("JsonDecode(%q, RelaxedSyntax) succeeded", s)
+		}
+	}
+}
+
+func TestRelaxJsonText(t *testing.T) {
+	in := "[1, // c\n2 /* d */,]"
+	out := relaxJsonText(in)
+	if len(out) != len(in) || out != "[1,     \n2         ]" {
+		t.Errorf("relaxJsonText = %q", out)
+	}
+}
@@ -0,0 +1,140 @@
+package tinywodp
+
+import (
+	"sync"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Required fields
+// A field tagged tiny:"required" must be sent. After the value is decoded the
+// payload is walked again next to the target, and every required member that
+// is missing or null is reported at once with its full Go path:
+//
+//	type Signup struct {
+//		Email   string `tiny:"required"`
+//		Profile struct {
+//			Name string `tiny:"required"`
+//		}
+//	}
+//	err := Convert(`{"Profile":{}}`).JsonDecode(&signup)
+//	// 2 values failed to decode; Email: missing required field; Profile.Name: missing required field
+//
+// The error is a DecodeErrors whose entries wrap ErrMissingField, together with
+// the field errors of a CollectErrors decode. Structs are checked wherever the
+// payload reaches them through fields, pointers, slices and arrays, not inside
+// map values or interfaces, and only types that can reach a required field pay
+// for the walk.
+
+// requiredChecks caches, per target type, whether a decode into it needs the walk
+var requiredChecks sync.Map
+
+// checkRequiredFields records every required member missing from jsonStr, the
+// payload decoded into target, as a collected error
+func (jh *jsonH) checkRequiredFields(jsonStr string, target *refValue) {
+	t := target.Type()
+	need, ok := requiredChecks.Load(t)
+	if !ok {
+		need, _ = requiredChecks.LoadOrStore(t, reachesRequired(t, map[*refType]bool{}))
+	}
+	if need.(bool) {
+		jh.checkRequiredValue(jsonStr, target, "")
+	}
+}
+
+// reachesRequired reports whether a struct reached from t through fields,
+// pointers, slices and arrays has a required field; seen breaks type cycles
+func reachesRequired(t *refType, seen map[*refType]bool) bool {
+	for t != nil && (t.Kind() == tpPointer || t.Kind() == tpSlice || t.Kind() == tpArray) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != tpStruct || seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	v, err := refNewValue(t)
+	if err != nil || isTimeType(v) || customCodecFor(v) != 0 {
+		return false
+	}
+	plan, err := decodePlanFor(v)
+	if err != nil {
+		return false
+	}
+	if plan.required {
+		return true
+	}
+	for i := range plan.fields {
+		if field := v.refField(i); field.refIsValid() && reachesRequired(field.Type(), seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequiredValue descends into the structs held by v, decoded from raw
+func (jh *jsonH) checkRequiredValue(raw string, v *refValue, path string) {
+	for v.refIsValid() && v.refKind() == tpPointer {
+		v = v.refElem()
+	}
+	raw = trimJsonSpace(raw)
+	if !v.refIsValid() || raw == "" || isTimeType(v) || isRawJSONType(v) || customCodecFor(v) != 0 {
+		return
+	}
+
+	switch v.refKind() {
+	case tpStruct:
+		if raw[0] == '{' {
+			jh.checkRequiredStruct(raw, v, path)
+		}
+	case tpSlice, tpArray:
+		if raw[0] != '[' {
+			return
+		}
+		n := 0
+		jh.eachJsonChild(raw, func(_, value string) error {
+			if n < v.refLen() {
+				jh.checkRequiredValue(value, v.refIndex(n), path+"["+Convert(n).String()+"]")
+			}
+			n++
+			return nil
+		})
+	}
+}
+
+// checkRequiredStruct reports the required fields of v that the object raw
+// leaves out, then descends into the members it sends
+func (jh *jsonH) checkRequiredStruct(raw string, v *refValue, path string) {
+	plan, err := decodePlanFor(v)
+	if err != nil {
+		return
+	}
+
+	// Member text of each field, "" when the key was absent
+	values := make([]string, len(plan.fields))
+	jh.eachJsonChild(raw, func(key, value string) error {
+		if i := plan.fieldIndex(key, jh.jMatch); i != -1 {
+			values[i] = value
+		}
+		return nil
+	})
+
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		fieldConv := v.refField(i)
+		if !fieldConv.refIsValid() || field.skipDecode {
+			continue
+		}
+		fieldPath := joinValidatePath(path, field.name)
+		if values[i] == "" || values[i] == "null" {
+			if field.required {
+				jh.jErrors = append(jh.jErrors, FieldError{Path: fieldPath, Offset: -1, Err: Err(ErrMissingField)})
+			}
+			continue
+		}
+		// Sealed values are opened while decoding only
+		if !field.secure {
+			jh.checkRequiredValue(values[i], fieldConv, fieldPath)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type requiredAddress struct {
+	City string `tiny:"required"`
+	Zip  string
+}
+
+type requiredUser struct {
+	Email     string `json:"email" tiny:"required"`
+	Age       int    `tiny:"required"`
+	Nick      string
+	Home      *requiredAddress
+	Addresses []requiredAddress
+}
+
+func TestRequiredFieldsMissing(t *testing.T) {
+	clearRefStructsCache()
+
+	var user requiredUser
+	err := Convert(`{"Home":{"Zip":"1"},"Addresses":[{"City":"a"},{}]}`).JsonDecode(&user)
+	errs, ok := err.(DecodeErrors)
+	if !ok || len(errs) != 4 {
+		t.Fatalf("expected 4 missing fields, got %v", err)
+	}
+	// Paths name the Go fields, whatever key the payload uses
+	want := []string{"Email", "Age", "Home.City", "Addresses[1].City"}
+	for i, fe := range errs {
+		if fe.Path != want[i] {
+			t.Errorf("entry %d: path %q, want %q", i, fe.Path, want[i])
+		}
+		if fe.Err.Error() != string(ErrMissingField) {
+			t.Errorf("entry %d: got %v", i, fe.Err)
+		}
+	}
+	// Everything that was sent is still decoded
+	if user.Home == nil || user.Home.Zip != "1" || len(user.Addresses) != 2 || user.Addresses[0].City != "a" {
+		t.Errorf("got %+v", user)
+	}
+}
+
+func TestRequiredFieldsPresent(t *testing.T) {
+	clearRefStructsCache()
+
+	var user requiredUser
+	if err := Convert(`{"email":"a@b.c","Age":0,"Home":null}`).JsonDecode(&user); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Types without required fields anywhere skip the walk
+	var plain struct{ Nick string }
+	if err := Convert(`{}`).JsonDecode(&plain); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var users []requiredUser
+	if err := Convert(`[{"email":"x","Age":1},{"Age":2}]`).JsonDecode(&users); err == nil || !Contains(err.Error(), "[1].Email") {
+		t.Errorf("expected [1].Email to be missing, got %v", err)
+	}
+}
+
+func TestRequiredFieldsConfig(t *testing.T) {
+	clearRefStructsCache()
+
+	var cfg struct {
+		Server struct {
+			Host string `tiny:"required"`
+			Port int    `default:"80"`
+		}
+	}
+	err := ConfigDecode("[server]\nport = 81\n", &cfg)
+	if err == nil || !Contains(err.Error(), "Server.Host: missing required field") {
+		t.Errorf("expected Server.Host to be missing, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Field-level encryption
+// Fields tagged `secure:"encrypt"` are encoded normally, then the resulting JSON
+// value is passed to the registered Cipher and emitted as a base64 string. Decoding
+// reverses the process before parsing into the field. The crypto itself is
+// supplied by the caller; the codec only orchestrates it.
+//
+//	type Patient struct {
+//		ID  string
+//		SSN string `secure:"encrypt"`
+//	}
+
+// Cipher encrypts and decrypts field payloads
+type Cipher interface {
+	Encrypt(plain []byte) ([]byte, error)
+	Decrypt(sealed []byte) ([]byte, error)
+}
+
+// fieldCipher is the registered Cipher, nil until RegisterCipher is called
+var fieldCipher Cipher
+
+// RegisterCipher installs c for all `secure:"encrypt"` fields; nil removes it
+// Not safe to call concurrently with encoding or decoding
+func RegisterCipher(c Cipher) {
+	fieldCipher = c
+}
+
+// isEncryptedField reports whether the value of a `secure` tag requests encryption
+func isEncryptedField(secureTag string) bool {
+	return secureTag == "encrypt"
+}
+
+// sealJsonField encrypts an encoded field value and returns it as a quoted base64 JSON string
+func sealJsonField(fieldJson string) (string, error) {
+	if fieldCipher == nil {
+		return "", Err(errNoCipher, "for encrypted field")
+	}
+	sealed, err := fieldCipher.Encrypt([]byte(fieldJson))
+	if err != nil {
+		return "", err
+	}
+
+	result := make([]byte, 0, base64EncodedLen(len(sealed))+2)
+	result = append(result, '"')
+	result = appendBase64(result, sealed)
+	result = append(result, '"')
+	return string(result), nil
+}
+
+// openJsonField reverses sealJsonField, returning the original encoded field value
+func openJsonField(jsonStr string) (string, error) {
+	if fieldCipher == nil {
+		return "", Err(errNoCipher, "for encrypted field")
+	}
+
+	jsonStr = Convert(jsonStr).Trim().String()
+	if jsonStr == "null" {
+		return jsonStr, nil
+	}
+	if len(jsonStr) < 2 || jsonStr[0] != '"' || jsonStr[len(jsonStr)-1] != '"' {
+		return "", Err(errInvalidJSON, "encrypted field must be a string")
+	}
+
+	sealed, err := decodeBase64(jsonStr[1 : len(jsonStr)-1])
+	if err != nil {
+		return "", err
+	}
+	plain, err := fieldCipher.Decrypt(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
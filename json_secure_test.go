@@ -0,0 +1,134 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// xorCipher is a toy reversible cipher for exercising the hooks
+type xorCipher struct{ key byte }
+
+func (c xorCipher) Encrypt(plain []byte) ([]byte, error) {
+	out := make([]byte, len(plain))
+	for i, b := range plain {
+		out[i] = b ^ c.key
+	}
+	return out, nil
+}
+
+func (c xorCipher) Decrypt(sealed []byte) ([]byte, error) {
+	return c.Encrypt(sealed)
+}
+
+type securePatient struct {
+	ID     string
+	SSN    string `secure:"encrypt"`
+	Visits int    `secure:"encrypt"`
+}
+
+func TestSecureFieldRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+	RegisterCipher(xorCipher{key: 0x5A})
+	defer RegisterCipher(nil)
+
+	original := securePatient{ID: "p1", SSN: "123-45-6789", Visits: 3}
+	encoded, err := Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	jsonStr := string(encoded)
+	if Contains(jsonStr, "123-45-6789") {
+		t.Errorf("encrypted field leaked in output: %s", jsonStr)
+	}
+	if !Contains(jsonStr, `"ID":"p1"`) {
+		t.Errorf("plain field missing from output: %s", jsonStr)
+	}
+
+	var decoded securePatient
+	if err := Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded != original {
+		t.Errorf("round trip = %+v, expected %+v", decoded, original)
+	}
+}
+
+func TestSecureFieldWithoutCipher(t *testing.T) {
+	clearRefStructsCache()
+	RegisterCipher(nil)
+
+	if _, err := Marshal(securePatient{SSN: "x"}); err == nil {
+		t.Error("Marshal should fail when no cipher is registered")
+	}
+	var decoded securePatient
+	if err := Unmarshal([]byte(`{"SSN":"AAAA"}`), &decoded); err == nil {
+		t.Error("Unmarshal should fail when no cipher is registered")
+	}
+}
+
+func TestSecureFieldStreamedPastFlushSize(t *testing.T) {
+	clearRefStructsCache()
+	RegisterCipher(xorCipher{key: 0x5A})
+	defer RegisterCipher(nil)
+
+	type secureExport struct {
+		ID    string
+		Notes []string `secure:"encrypt"`
+	}
+	original := secureExport{ID: "p1"}
+	for i := 0; len(original.Notes)*16 < 3*encoderFlushSize; i++ {
+		original.Notes = append(original.Notes, Fmt("private-note-%03d", i).String())
+	}
+
+	var out []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		out = append(out, p...)
+		return len(p), nil
+	}}
+	if err := NewJsonEncoder(w).Encode(original); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if Contains(string(out), "private-note") {
+		t.Fatalf("plaintext of a secure field reached the writer")
+	}
+
+	var decoded secureExport
+	if err := Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded.ID != original.ID || len(decoded.Notes) != len(original.Notes) || decoded.Notes[0] != original.Notes[0] {
+		t.Errorf("round trip lost the secure slice: %d notes, expected %d", len(decoded.Notes), len(original.Notes))
+	}
+}
+
+func TestBase64RoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"f", "Zg=="},
+		{"fo", "Zm8="},
+		{"foo", "Zm9v"},
+		{"foobar", "Zm9vYmFy"},
+	}
+
+	for _, test := range tests {
+		encoded := string(appendBase64(nil, []byte(test.input)))
+		if encoded != test.expected {
+			t.Errorf("appendBase64(%q) = %q, expected %q", test.input, encoded, test.expected)
+		}
+		decoded, err := decodeBase64(encoded)
+		if err != nil || string(decoded) != test.input {
+			t.Errorf("decodeBase64(%q) = %q, %v", encoded, string(decoded), err)
+		}
+	}
+
+	for _, invalid := range []string{"Zg=", "Z===", "Zm9v!A==", "Zg==Zg=="} {
+		if _, err := decodeBase64(invalid); err == nil {
+			t.Errorf("decodeBase64(%q) should return error", invalid)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Streaming array export from iterators and channels
+// Elements are encoded and flushed as they are produced, so a database cursor
+// can be streamed to the client without collecting the full slice first
+
+// EncodeArrayFromSeq writes the elements yielded by seq to w as a JSON array
+// Accepts an iter.Seq[T] (or any func(yield func(T) bool)) without importing iter
+//
+// Usage pattern:
+//
+//	err := EncodeArrayFromSeq(httpResponseWriter, rows.All())
+func EncodeArrayFromSeq[T any](w writer, seq func(yield func(T) bool)) error {
+	if w == nil {
+		return Err(errInvalidJSON, "encoder writer cannot be nil")
+	}
+	enc := NewJsonEncoder(w)
+	enc.jh = getJsonH("_")
+	defer func() {
+		putJsonH(enc.jh)
+		enc.jh = nil
+	}()
+
+	enc.buf = append(enc.buf, '[')
+	count := 0
+	var err error
+	seq(func(v T) bool {
+		if count > 0 {
+			enc.buf = append(enc.buf, ',')
+		}
+		count++
+		if err = enc.encodeAny(v); err != nil {
+			return false
+		}
+		err = enc.maybeFlush()
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	enc.buf = append(enc.buf, ']')
+	return enc.flush()
+}
+
+// EncodeArrayFromChan writes every value received from ch to w as a JSON array
+// Returns once ch is closed and all values have been written
+// On an encode or write error the remaining values are received and discarded
+// until ch is closed, so the producer never blocks on a send; the producer must
+// still close ch for the call to return
+func EncodeArrayFromChan[T any](w writer, ch <-chan T) error {
+	err := EncodeArrayFromSeq(w, func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	})
+	for range ch {
+	}
+	return err
+}
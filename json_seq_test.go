@@ -0,0 +1,95 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeArrayFromSeq(t *testing.T) {
+	clearRefStructsCache()
+	persons := GenerateSimplePersonArray(500)
+
+	var captured []byte
+	writes := 0
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		writes++
+		captured = append(captured, p...)
+		return len(p), nil
+	}}
+
+	seq := func(yield func(Person) bool) {
+		for _, p := range persons {
+			if !yield(p) {
+				return
+			}
+		}
+	}
+	if err := EncodeArrayFromSeq(w, seq); err != nil {
+		t.Fatalf("EncodeArrayFromSeq returned error: %v", err)
+	}
+
+	expected, err := Marshal(persons)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if string(captured) != string(expected) {
+		t.Errorf("EncodeArrayFromSeq output differs from Marshal")
+	}
+	if writes < 2 {
+		t.Errorf("expected streamed output in several writes, got %d", writes)
+	}
+}
+
+func TestEncodeArrayFromChan(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	var captured []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		captured = append(captured, p...)
+		return len(p), nil
+	}}
+	if err := EncodeArrayFromChan(w, ch); err != nil {
+		t.Fatalf("EncodeArrayFromChan returned error: %v", err)
+	}
+	if string(captured) != "[1,2,3]" {
+		t.Errorf("EncodeArrayFromChan wrote %s, expected [1,2,3]", string(captured))
+	}
+
+	// Empty sequence still produces a valid array
+	captured = nil
+	empty := make(chan int)
+	close(empty)
+	if err := EncodeArrayFromChan(w, empty); err != nil || string(captured) != "[]" {
+		t.Errorf("EncodeArrayFromChan(empty) = %s, %v", string(captured), err)
+	}
+}
+
+func TestEncodeArrayFromChanDrainsOnError(t *testing.T) {
+	ch := make(chan any)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ch <- 1
+		ch <- func() {} // cannot be encoded
+		for i := range 100 {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	w := &testWriter{writeFunc: func(p []byte) (int, error) { return len(p), nil }}
+	if err := EncodeArrayFromChan(w, ch); err == nil {
+		t.Error("EncodeArrayFromChan should return the encode error")
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("producer is still blocked after EncodeArrayFromChan returned")
+	}
+}
@@ -0,0 +1,64 @@
+package tinywodp
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Encoded size guard
+// Compares the byte size of our output against encoding/json for the shared corpus,
+// catching accidental verbosity (extra whitespace, bloated number formatting).
+// The allowed delta is a percentage, overridable in CI:
+//
+//	TINYWODP_SIZE_DELTA=2.5 go test -run TestJsonEncodedSizeDelta -v
+//
+// Each case logs a "SIZE corpus=... standard=... tinywodp=... delta=...%" line
+// that the benchmark CLI (benchmark/analyzer.go json) parses and reports.
+
+// defaultSizeDelta is the maximum percentage our output may exceed encoding/json
+const defaultSizeDelta = 5.0
+
+func TestJsonEncodedSizeDelta(t *testing.T) {
+	clearRefStructsCache()
+
+	limit := defaultSizeDelta
+	if env := os.Getenv("TINYWODP_SIZE_DELTA"); env != "" {
+		parsed, err := Convert(env).ToFloat()
+		if err != nil {
+			t.Fatalf("invalid TINYWODP_SIZE_DELTA %q: %v", env, err)
+		}
+		limit = parsed
+	}
+
+	corpus := []struct {
+		name  string
+		value any
+	}{
+		{"complex_single", GenerateComplexTestData(1)[0]},
+		{"complex_batch100", GenerateComplexTestData(100)},
+		{"person_batch100", GenerateSimplePersonArray(100)},
+		{"empty_user", GenerateEmptyComplexUser()},
+		{"mock_user", GenerateMock[ComplexUser](1766)},
+	}
+
+	for _, c := range corpus {
+		standard, err := json.Marshal(c.value)
+		if err != nil {
+			t.Fatalf("%s: json.Marshal returned error: %v", c.name, err)
+		}
+		tiny, err := Marshal(c.value)
+		if err != nil {
+			t.Fatalf("%s: Marshal returned error: %v", c.name, err)
+		}
+
+		delta := float64(len(tiny)-len(standard)) * 100 / float64(len(standard))
+		t.Logf("SIZE corpus=%s standard=%d tinywodp=%d delta=%.2f%%", c.name, len(standard), len(tiny), delta)
+		if delta > limit {
+			t.Errorf("%s: encoded size %d bytes is %.2f%% over encoding/json (%d bytes), limit %.2f%%",
+				c.name, len(tiny), delta, len(standard), limit)
+		}
+	}
+}
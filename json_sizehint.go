@@ -0,0 +1,100 @@
+package tinywodp
+
+// Output size hints
+// The pooled output buffer starts at jsonOutSize bytes and doubles as an
+// encode appends past it, copying everything written so far each time. Callers
+// that know roughly how large the result is can reserve it up front:
+//
+//	data, err := Convert(&export).JsonEncodeWithCap(2 << 20)
+//
+// Without a hint the buffer is sized from experience: each JSON encode of a
+// struct, a pointer to one or a slice or array of them records the bytes per
+// struct in that type's plan, as a running average, and the next encode of the
+// type reserves the average times the number of structs. A hint only sizes the
+// buffer; the output is the same with or without it.
+
+// JsonEncodeWithCap encodes the current value like JsonEncode, reserving at
+// least sizeHint bytes of output buffer before it starts
+// A zero or negative hint falls back to the learned size
+func (c *refValue) JsonEncodeWithCap(sizeHint int, w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	return jh.encodeJsonTo(c, w, sizeHint)
+}
+
+// encodeJsonTo is encodeTo for JSON, reserving sizeHint bytes, or the size
+// learned for the type of c, and recording the size encoded
+func (jh *jsonH) encodeJsonTo(c *refValue, w []writer, sizeHint int) ([]byte, error) {
+	plan, n := encodedStructs(c)
+	if sizeHint <= 0 && plan != nil {
+		if avg := plan.encodedSize.Load(); avg > 0 {
+			sizeHint = int(avg) * n
+		}
+	}
+	jh.reserveOut(sizeHint)
+
+	out, err := jh.encodeTo(c, w, jh.appendJson)
+	if err == nil {
+		if plan == nil {
+			plan, n = encodedStructs(c) // compiled by this encode
+		}
+		if plan != nil && n > 0 {
+			plan.learnEncodedSize(len(jh.jOut) / n)
+		}
+	}
+	return out, err
+}
+
+// reserveOut gives the empty output buffer room for at least n bytes
+func (jh *jsonH) reserveOut(n int) {
+	if n > cap(jh.jOut) {
+		freeHint(jh.jOut)
+		jh.jOut = allocBytes(n)
+	}
+}
+
+// encodedStructs returns the cached plan of the structs c encodes at its top
+// level, c itself, what it points to or its elements, and how many there are
+// The plan is nil until the struct type has been encoded or decoded once
+func encodedStructs(c *refValue) (*decodePlan, int) {
+	switch c.vTpe {
+	case tpStruct, tpPointer, tpSlice, tpArray:
+	default:
+		return nil, 0
+	}
+	if !c.refIsValid() {
+		return nil, 0
+	}
+	t, n := c.Type(), 1
+	switch c.refKind() {
+	case tpSlice, tpArray:
+		t, n = t.Elem(), c.refLen()
+	case tpPointer:
+		if !c.refElem().refIsValid() {
+			return nil, 0 // encodes as null
+		}
+	}
+	if t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+	if t.Kind() != tpStruct {
+		return nil, 0
+	}
+	plan, ok := decodePlans.Load(t)
+	if !ok {
+		return nil, 0
+	}
+	return plan.(*decodePlan), n
+}
+
+// learnEncodedSize folds size, the bytes one struct encoded to, into the
+// running average: a quarter of the gap per sample, so one outlier does not
+// swing the next reservation
+func (p *decodePlan) learnEncodedSize(size int) {
+	avg := p.encodedSize.Load()
+	if avg == 0 {
+		p.encodedSize.Store(int64(size))
+		return
+	}
+	p.encodedSize.Store(avg + (int64(size)-avg)/4)
+}
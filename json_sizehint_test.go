@@ -0,0 +1,65 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type sizeHintRow struct {
+	ID   int
+	Name string
+}
+
+func TestJsonEncodeWithCap(t *testing.T) {
+	row := sizeHintRow{ID: 7, Name: "seven"}
+	plain, err := Convert(&row).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	for _, hint := range []int{-1, 0, 4, 1 << 20} {
+		out, err := Convert(&row).JsonEncodeWithCap(hint)
+		if err != nil || string(out) != string(plain) {
+			t.Errorf("JsonEncodeWithCap(%d) = %s, %v; expected %s", hint, out, err, plain)
+		}
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.reserveOut(1 << 16)
+	if cap(jh.jOut) < 1<<16 || len(jh.jOut) != 0 {
+		t.Errorf("reserveOut left len %d cap %d", len(jh.jOut), cap(jh.jOut))
+	}
+}
+
+func TestEncodedSizeLearned(t *testing.T) {
+	InvalidateTypeCache()
+
+	rows := make([]sizeHintRow, 10)
+	for i := range rows {
+		rows[i] = sizeHintRow{ID: i, Name: "row"}
+	}
+	out, err := Convert(rows).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+
+	plan, n := encodedStructs(Convert(rows))
+	if plan == nil || n != len(rows) {
+		t.Fatalf("encodedStructs = %v, %d; expected a plan for %d rows", plan, n, len(rows))
+	}
+	if got := plan.encodedSize.Load(); got != int64(len(out)/len(rows)) {
+		t.Errorf("learned size = %d, expected %d", got, len(out)/len(rows))
+	}
+
+	// Later samples move the average a quarter of the way
+	before := plan.encodedSize.Load()
+	plan.learnEncodedSize(int(before) + 40)
+	if got := plan.encodedSize.Load(); got != before+10 {
+		t.Errorf("average after outlier = %d, expected %d", got, before+10)
+	}
+
+	if plan, _ := encodedStructs(Convert("text")); plan != nil {
+		t.Error("strings should not have a learned size")
+	}
+}
@@ -0,0 +1,156 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Value skipping
+// Finds where a JSON value ends without decoding or copying it. The decoder
+// skips unknown fields and frames scalar values with it, so they cost one pass
+// over their bytes, and consumers can skip large subtrees themselves:
+//
+//	n, err := SkipValue(data)   // data[n:] starts after the first value
+//	err := dec.Skip()           // drop the next value of a stream
+
+// SkipValue returns the number of bytes taken by the first JSON value in data,
+// including leading whitespace and a UTF-8 byte order mark; the value is validated but never decoded
+// On error the offset points at the first byte that could not be accepted
+func SkipValue(data []byte) (int, error) {
+	rest, err := stripJsonBOMBytes(data)
+	if err != nil {
+		return 0, err
+	}
+	s := string(rest)
+	if isJsonBlank(s) {
+		return 0, Err(ErrEmptyInput)
+	}
+	n, err := skipJsonValue(s, 0)
+	return len(data) - len(rest) + n, err
+}
+
+// skipJsonSpace returns the index of the first non-whitespace byte at or after i
+func skipJsonSpace(s string, i int) int {
+	for i < len(s) && isJsonSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// skipJsonValue returns the index just past the value starting at or after i
+func skipJsonValue(s string, i int) (int, error) {
+	i = skipJsonSpace(s, i)
+	if i >= len(s) {
+		return i, Err(errInvalidJSON, "unexpected end of input")
+	}
+	switch s[i] {
+	case '"':
+		return skipJsonString(s, i)
+	case '{':
+		return skipJsonObject(s, i)
+	case '[':
+		return skipJsonArray(s, i)
+	default:
+		return skipJsonLiteral(s, i)
+	}
+}
+
+// skipJsonString returns the index just past the quoted string starting at i
+func skipJsonString(s string, i int) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++ // the escaped byte is validated when the string is decoded
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return i, Err(errInvalidJSON, "unterminated string")
+}
+
+// skipJsonObject returns the index just past the object starting at i
+func skipJsonObject(s string, i int) (int, error) {
+	i = skipJsonSpace(s, i+1)
+	if i < len(s) && s[i] == '}' {
+		return i + 1, nil
+	}
+	for {
+		if i >= len(s) || s[i] != '"' {
+			return i, Err(errInvalidJSON, "expected object key")
+		}
+		end, err := skipJsonString(s, i)
+		if err != nil {
+			return end, err
+		}
+		i = skipJsonSpace(s, end)
+		if i >= len(s) || s[i] != ':' {
+			return i, Err(errInvalidJSON, "expected ':' after object key")
+		}
+		if i, err = skipJsonValue(s, i+1); err != nil {
+			return i, err
+		}
+		i = skipJsonSpace(s, i)
+		if i >= len(s) {
+			return i, Err(errInvalidJSON, "unterminated object")
+		}
+		switch s[i] {
+		case '}':
+			return i + 1, nil
+		case ',':
+			i = skipJsonSpace(s, i+1)
+		default:
+			return i, Err(errInvalidJSON, "expected ',' or '}' in object")
+		}
+	}
+}
+
+// skipJsonArray returns the index just past the array starting at i
+func skipJsonArray(s string, i int) (int, error) {
+	i = skipJsonSpace(s, i+1)
+	if i < len(s) && s[i] == ']' {
+		return i + 1, nil
+	}
+	for {
+		var err error
+		if i, err = skipJsonValue(s, i); err != nil {
+			return i, err
+		}
+		i = skipJsonSpace(s, i)
+		if i >= len(s) {
+			return i, Err(errInvalidJSON, "unterminated array")
+		}
+		switch s[i] {
+		case ']':
+			return i + 1, nil
+		case ',':
+			i++
+		default:
+			return i, Err(errInvalidJSON, "expected ',' or ']' in array")
+		}
+	}
+}
+
+// skipJsonLiteral returns the index just past true, false, null or a number at i
+// The literal runs to the next delimiter, so prefixes like "truex" are rejected
+func skipJsonLiteral(s string, i int) (int, error) {
+	j := i
+	for j < len(s) && !isJsonDelimiter(s[j]) {
+		j++
+	}
+	literal := s[i:j]
+	if literal == "true" || literal == "false" || literal == "null" || isJsonNumber(literal) {
+		return j, nil
+	}
+	if literal == "" {
+		return i, Err(errInvalidJSON, "unexpected character: "+string(s[i]))
+	}
+	return i, Err(errInvalidJSON, "invalid literal: "+literal)
+}
+
+// isJsonDelimiter reports whether b ends a bare literal
+func isJsonDelimiter(b byte) bool {
+	switch b {
+	case ',', ':', '{', '}', '[', ']', '"':
+		return true
+	}
+	return isJsonSpace(b)
+}
@@ -0,0 +1,157 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestSkipValue(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+	}{
+		{`true,`, 4},
+		{`  -1.5e3]`, 8},
+		{`"a\"b" rest`, 6},
+		{`{"a":[1,{"b":null}],"c":"}"} tail`, 28},
+		{`[]`, 2},
+		{`{ }x`, 3},
+	}
+	for _, tt := range tests {
+		n, err := SkipValue([]byte(tt.input))
+		if err != nil {
+			t.Errorf("SkipValue(%s) returned error: %v", tt.input, err)
+			continue
+		}
+		if n != tt.n {
+			t.Errorf("SkipValue(%s) = %d, expected %d", tt.input, n, tt.n)
+		}
+	}
+
+	for _, invalid := range []string{`truex`, `{"a" 1}`, `[1 2]`, `{"a":1,}`, `"open`, `[`, `}`} {
+		if _, err := SkipValue([]byte(invalid)); err == nil {
+			t.Errorf("SkipValue(%s) should return error", invalid)
+		}
+	}
+	if _, err := SkipValue([]byte(" ")); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("SkipValue(blank) = %v, expected ErrEmptyInput", err)
+	}
+}
+
+func TestJsonDecoderSkip(t *testing.T) {
+	clearRefStructsCache()
+
+	input := `{"debug":"big blob","frames":[{"x":1},{"y":[2,3]}]} {"Id":"2"} 7`
+	dec := NewJsonDecoder(&testReader{data: input, chunk: 5, eof: errTestEOF})
+
+	if err := dec.Skip(); err != nil {
+		t.Fatalf("Skip returned error: %v", err)
+	}
+	var addr Address
+	if err := dec.Decode(&addr); err != nil || addr.Id != "2" {
+		t.Errorf("Decode after Skip = %+v, %v", addr, err)
+	}
+	if err := dec.Skip(); err != nil {
+		t.Fatalf("Skip(scalar) returned error: %v", err)
+	}
+	if err := dec.Skip(); err != errTestEOF {
+		t.Errorf("Skip at end returned %v, expected reader EOF", err)
+	}
+}
+
+func TestDecodeSkipsUnknownFields(t *testing.T) {
+	clearRefStructsCache()
+
+	payload := `{"Id":"1","debug":{"trace":[1,2,{"deep":"x]}"}],"n":null},"City":"Quito"}`
+	var addr Address
+	if err := Convert(payload).JsonDecode(&addr); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if addr.Id != "1" || addr.City != "Quito" {
+		t.Errorf("decoded = %+v", addr)
+	}
+
+	// Unknown fields are still validated while skipped
+	if err := Convert(`{"Id":"1","debug":[1 2]}`).JsonDecode(&addr); err == nil {
+		t.Error("malformed unknown field should return error")
+	}
+}
+
+func TestJsonCursorMembers(t *testing.T) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+
+	s := `{ "b" : [1, 2], "a\u0041": {"x": "}"}, "b": 3 }`
+	var keys, values []string
+	i, done, err := jh.openJsonContainer(s, 0, '}')
+	for !done {
+		if err != nil {
+			t.Fatalf("cursor error at %d: %v", i, err)
+		}
+		var key string
+		if key, i, err = jh.scanJsonKey(s, i); err != nil {
+			t.Fatalf("scanJsonKey error at %d: %v", i, err)
+		}
+		start := i
+		if i, err = jh.skipJsonValueAt(s, i); err != nil {
+			t.Fatalf("skipJsonValueAt error at %d: %v", start, err)
+		}
+		keys, values = append(keys, key), append(values, s[start:i])
+		i, done, err = jh.nextJsonMember(s, i, '}')
+	}
+
+	expectedKeys := []string{"b", "aA", "b"}
+	expectedValues := []string{`[1, 2]`, `{"x": "}"}`, `3`}
+	if i != len(s) || len(keys) != len(expectedKeys) {
+		t.Fatalf("cursor stopped at %d with keys %q", i, keys)
+	}
+	for n := range expectedKeys {
+		if keys[n] != expectedKeys[n] || values[n] != expectedValues[n] {
+			t.Errorf("member %d = %q: %q, expected %q: %q", n, keys[n], values[n], expectedKeys[n], expectedValues[n])
+		}
+	}
+
+	if _, _, err := jh.nextJsonMember(`1 2]`, 1, ']'); err == nil {
+		t.Error("nextJsonMember should reject a missing comma")
+	}
+	if _, _, err := jh.scanJsonKey(`{"a" 1}`, 1); err == nil {
+		t.Error("scanJsonKey should reject a missing colon")
+	}
+}
+
+func TestJsonCursorFramingAllocs(t *testing.T) {
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+
+	// Framing elements returns offsets into the input, however large it is
+	payload := []byte{'['}
+	for n := range 10000 {
+		if n > 0 {
+			payload = append(payload, ',')
+		}
+		payload = append(payload, `{"id":"u1","tags":["a","b"],"score":1.5}`...)
+	}
+	s := string(append(payload, ']'))
+
+	count := 0
+	allocs := testing.AllocsPerRun(5, func() {
+		count = 0
+		i, done, err := jh.openJsonContainer(s, 0, ']')
+		for !done && err == nil {
+			if i, err = jh.skipJsonValueAt(s, i); err == nil {
+				count++
+				i, done, err = jh.nextJsonMember(s, i, ']')
+			}
+		}
+		if err != nil {
+			t.Fatalf("cursor error at %d: %v", i, err)
+		}
+	})
+	if count != 10000 {
+		t.Errorf("framed %d elements, expected 10000", count)
+	}
+	if allocs != 0 {
+		t.Errorf("framing allocated %v times per run, expected none", allocs)
+	}
+}
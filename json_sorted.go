@@ -0,0 +1,63 @@
+package tinywodp
+
+import (
+	"sync"
+)
+
+// Key order
+// Encoded output is deterministic: the same value always encodes to the same
+// bytes. Struct fields are written in declaration order by default, which is
+// also what a reader of the Go type expects. Content-addressed storage and
+// snapshot tests that compare output across type refactors can ask for keys
+// sorted by byte value instead:
+//
+//	SetSortKeys(true)
+//	data, _ := Marshal(User{Name: "ana", Age: 30}) // {"Age":30,"Name":"ana"}
+//
+// Keys are compared as written, after the naming convention and tag renames,
+// so the order follows what a reader of the JSON sees. The sorted order is
+// computed once per struct type and convention. Map keys have no declaration
+// order to keep and are always written sorted, whatever the setting (see
+// json_map.go). JsonEncodeCanonical always sorts, by UTF-16 code units as
+// RFC 8785 requires.
+
+// sortKeys makes the JSON encoders write struct fields sorted by key
+var sortKeys bool
+
+// SetSortKeys makes every later JSON encode write object keys in ascending
+// byte order rather than declaration order; off by default
+// Not safe to call concurrently with encoding
+func SetSortKeys(sort bool) {
+	sortKeys = sort
+}
+
+// sortedFieldOrders caches the sorted field indices, an []int, per fieldNamesKey
+var sortedFieldOrders sync.Map
+
+// jsonFieldOrder returns the indices of the fields of the struct held by v in
+// the order they are written, nil for declaration order
+func (jh *jsonH) jsonFieldOrder(v *refValue, plan *decodePlan) []int {
+	if !jh.jSortKeys {
+		return nil
+	}
+	key := fieldNamesKey{t: v.Type(), naming: jh.jNaming}
+	if order, ok := sortedFieldOrders.Load(key); ok {
+		return order.([]int)
+	}
+
+	names := make([]string, len(plan.fields))
+	order := make([]int, len(plan.fields))
+	for i := range plan.fields {
+		names[i] = plan.fields[i].encodedName(jh.jNaming)
+		order[i] = i
+	}
+	// Insertion sort keeps the binary free of package sort; equal keys keep
+	// declaration order
+	for j := 1; j < len(order); j++ {
+		for k := j; k > 0 && names[order[k]] < names[order[k-1]]; k-- {
+			order[k], order[k-1] = order[k-1], order[k]
+		}
+	}
+	stored, _ := sortedFieldOrders.LoadOrStore(key, order)
+	return stored.([]int)
+}
@@ -0,0 +1,84 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type sortedAccount struct {
+	UserName string
+	Age      int
+	Email    string `json:"contact"`
+	Active   bool
+}
+
+func TestSetSortKeys(t *testing.T) {
+	a := sortedAccount{UserName: "ana", Age: 30, Email: "a@b.c", Active: true}
+
+	out, err := Marshal(a)
+	if err != nil || string(out) != `{"UserName":"ana","Age":30,"contact":"a@b.c","Active":true}` {
+		t.Errorf("default output = %s, %v; expected declaration order", out, err)
+	}
+
+	SetSortKeys(true)
+	defer SetSortKeys(false)
+	out, err = Marshal(a)
+	expected := `{"Active":true,"Age":30,"UserName":"ana","contact":"a@b.c"}`
+	if err != nil || string(out) != expected {
+		t.Errorf("sorted output = %s, %v; expected %s", out, err, expected)
+	}
+
+	SetFieldNaming(FieldNamingSnake)
+	defer SetFieldNaming(FieldNamingPascal)
+	out, err = Marshal(a)
+	expected = `{"active":true,"age":30,"contact":"a@b.c","user_name":"ana"}`
+	if err != nil || string(out) != expected {
+		t.Errorf("sorted snake_case output = %s, %v; expected %s", out, err, expected)
+	}
+}
+
+func TestSortKeysOptionsAndEncoder(t *testing.T) {
+	a := sortedAccount{UserName: "ana", Age: 30}
+	expected := `{"Active":false,"Age":30,"UserName":"ana","contact":""}`
+
+	opts := DefaultJsonOptions()
+	opts.SortKeys = true
+	if out, err := Convert(&a).JsonEncodeWith(opts); err != nil || string(out) != expected {
+		t.Errorf("JsonEncodeWith = %s, %v; expected %s", out, err, expected)
+	}
+	if out, _ := Marshal(a); string(out) == expected {
+		t.Error("SortKeys option leaked into a later encode")
+	}
+
+	SetSortKeys(true)
+	defer SetSortKeys(false)
+	var got []byte
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		got = append(got, p...)
+		return len(p), nil
+	}})
+	if err := enc.Encode(a); err != nil || !Contains(string(got), expected) {
+		t.Errorf("JsonEncoder output = %s, %v; expected %s", got, err, expected)
+	}
+}
+
+func TestSortKeysMaps(t *testing.T) {
+	type Inventory struct {
+		Stock map[string]int
+		Owner string
+	}
+	inv := Inventory{Stock: map[string]int{"pear": 2, "apple": 5, "fig": 1}, Owner: "ana"}
+
+	// Map keys are sorted with or without SetSortKeys, struct fields only with it
+	out, err := Marshal(inv)
+	if expected := `{"Stock":{"apple":5,"fig":1,"pear":2},"Owner":"ana"}`; err != nil || string(out) != expected {
+		t.Errorf("default output = %s, %v; expected %s", out, err, expected)
+	}
+	SetSortKeys(true)
+	defer SetSortKeys(false)
+	out, err = Marshal(inv)
+	if expected := `{"Owner":"ana","Stock":{"apple":5,"fig":1,"pear":2}}`; err != nil || string(out) != expected {
+		t.Errorf("sorted output = %s, %v; expected %s", out, err, expected)
+	}
+}
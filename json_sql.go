@@ -0,0 +1,70 @@
+//go:build tinywodp_sql
+
+package tinywodp
+
+import (
+	"database/sql/driver"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// database/sql integration
+// Enabled with -tags tinywodp_sql so the core never imports database/sql.
+// JSONColumn stores any encodable value in a JSON, JSONB or text column:
+//
+//	type Order struct {
+//		ID    int64
+//		Items JSONColumn[[]Item]
+//	}
+//
+//	db.Exec(`INSERT INTO orders (id, items) VALUES ($1, $2)`, o.ID, o.Items)
+//	db.QueryRow(`SELECT items FROM orders WHERE id = $1`, id).Scan(&o.Items)
+//
+// Like sql.Null, Valid false stands for SQL NULL in both directions.
+
+// JSONColumn wraps a value stored as JSON text in a SQL column
+type JSONColumn[T any] struct {
+	V     T
+	Valid bool // false means SQL NULL
+}
+
+// NewJSONColumn returns a valid column holding v
+func NewJSONColumn[T any](v T) JSONColumn[T] {
+	return JSONColumn[T]{V: v, Valid: true}
+}
+
+// Value implements driver.Valuer, encoding V as JSON
+func (c JSONColumn[T]) Value() (driver.Value, error) {
+	if !c.Valid {
+		return nil, nil
+	}
+	return Convert(&c.V).JsonEncode()
+}
+
+// Scan implements sql.Scanner, decoding a JSON column into V
+// Drivers hand JSON columns over as []byte or string. V is reset first, so a
+// reused column never keeps fields from the previous row, and is left zero
+// when decoding fails.
+func (c *JSONColumn[T]) Scan(src any) error {
+	var zero T
+	c.V, c.Valid = zero, false
+
+	var text string
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		text = string(src) // drivers may reuse src after Scan returns
+	case string:
+		text = src
+	default:
+		return Err(errUnsupportedType, "cannot scan", refValueOf(src).refKind().String(), "into JSONColumn")
+	}
+
+	if err := Convert(text).JsonDecode(&c.V); err != nil {
+		c.V = zero
+		return err
+	}
+	c.Valid = true
+	return nil
+}
@@ -0,0 +1,86 @@
+//go:build tinywodp_sql
+
+package tinywodp
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+var (
+	_ driver.Valuer = JSONColumn[int]{}
+	_ sql.Scanner   = (*JSONColumn[int])(nil)
+)
+
+type sqlTestItem struct {
+	SKU string
+	Qty int
+}
+
+func TestJSONColumnValue(t *testing.T) {
+	clearRefStructsCache()
+
+	value, err := NewJSONColumn([]sqlTestItem{{SKU: "a", Qty: 2}}).Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	if b, ok := value.([]byte); !ok || string(b) != `[{"SKU":"a","Qty":2}]` {
+		t.Errorf("got %#v", value)
+	}
+
+	if value, err := (JSONColumn[sqlTestItem]{}).Value(); value != nil || err != nil {
+		t.Errorf("NULL column: got %#v, %v", value, err)
+	}
+}
+
+func TestJSONColumnScan(t *testing.T) {
+	clearRefStructsCache()
+
+	var col JSONColumn[sqlTestItem]
+	for _, src := range []any{[]byte(`{"SKU":"b","Qty":3}`), `{"SKU":"b","Qty":3}`} {
+		if err := col.Scan(src); err != nil {
+			t.Fatalf("Scan(%T) failed: %v", src, err)
+		}
+		if !col.Valid || col.V != (sqlTestItem{SKU: "b", Qty: 3}) {
+			t.Errorf("Scan(%T): got %+v", src, col)
+		}
+	}
+
+	// Fields of the previous row are not kept
+	if err := col.Scan(`{"Qty":1}`); err != nil || col.V != (sqlTestItem{Qty: 1}) {
+		t.Errorf("got %+v, %v", col, err)
+	}
+
+	if err := col.Scan(nil); err != nil || col.Valid || col.V != (sqlTestItem{}) {
+		t.Errorf("NULL: got %+v, %v", col, err)
+	}
+	if err := col.Scan(42); err == nil {
+		t.Error("expected an error for an integer source")
+	}
+	if err := col.Scan(`{"SKU":"d","Qty":"x"}`); err == nil || col.Valid || col.V != (sqlTestItem{}) {
+		t.Errorf("expected a decode error, got %+v, %v", col, err)
+	}
+}
+
+func TestJSONColumnInStruct(t *testing.T) {
+	clearRefStructsCache()
+
+	type Order struct {
+		ID    int64
+		Items JSONColumn[[]sqlTestItem]
+	}
+	order := Order{ID: 1, Items: NewJSONColumn([]sqlTestItem{{SKU: "c", Qty: 1}})}
+
+	value, err := order.Items.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	var loaded Order
+	if err := loaded.Items.Scan(value); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !loaded.Items.Valid || len(loaded.Items.V) != 1 || loaded.Items.V[0] != order.Items.V[0] {
+		t.Errorf("got %+v", loaded.Items)
+	}
+}
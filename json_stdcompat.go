@@ -0,0 +1,162 @@
+//go:build tinywodp_stdcompat && !tinygo
+
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Standard library interop shim
+// Enabled with -tags tinywodp_stdcompat for codebases migrating gradually from
+// encoding/json: data types keep their MarshalJSON/UnmarshalJSON (or
+// MarshalText/UnmarshalText) methods and work with both codecs.
+//
+//	go build -tags tinywodp_stdcompat ./...
+//
+// The interfaces are matched by method set, so the shim itself imports neither
+// encoding/json nor reflect. A MarshalJSON that calls back into this codec for
+// the same value recurses forever, exactly as it would with encoding/json.
+
+// stdJsonMarshaler mirrors json.Marshaler
+type stdJsonMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// stdJsonUnmarshaler mirrors json.Unmarshaler
+type stdJsonUnmarshaler interface {
+	UnmarshalJSON(data []byte) error
+}
+
+// stdTextMarshaler mirrors encoding.TextMarshaler
+type stdTextMarshaler interface {
+	MarshalText() ([]byte, error)
+}
+
+// stdTextUnmarshaler mirrors encoding.TextUnmarshaler
+type stdTextUnmarshaler interface {
+	UnmarshalText(text []byte) error
+}
+
+func init() {
+	stdEncodeHook = encodeStdMarshaler
+	stdDecodeHook = decodeStdUnmarshaler
+}
+
+// MarshalJSON implements json.Marshaler, emitting the literal number
+func (n BigNumber) MarshalJSON() ([]byte, error) {
+	return []byte(bigNumberLiteral(string(n))), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting 123 and "123"
+func (n *BigNumber) UnmarshalJSON(data []byte) error {
+	literal := string(data)
+	if len(literal) >= 2 && literal[0] == '"' && literal[len(literal)-1] == '"' {
+		literal = literal[1 : len(literal)-1]
+	}
+	if !isJsonNumber(literal) {
+		return Err(errInvalidJSON, "expected number but got: "+string(data))
+	}
+	*n = BigNumber(literal)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (n BigNumber) MarshalText() ([]byte, error) {
+	return []byte(bigNumberLiteral(string(n))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (n *BigNumber) UnmarshalText(text []byte) error {
+	if !isJsonNumber(string(text)) {
+		return Err(errInvalidJSON, "expected number but got: "+string(text))
+	}
+	*n = BigNumber(text)
+	return nil
+}
+
+// stdAddr returns a pointer to the value held by v, typed as *T, so methods with
+// pointer receivers are visible; nil when v is not addressable
+func stdAddr(v *refValue) any {
+	if v.ptr == nil || v.flag&flagAddr == 0 {
+		return nil
+	}
+	pt := refPtrTo(v.Type())
+	if pt == nil {
+		return nil
+	}
+	return refTypedAny(pt, v.ptr)
+}
+
+// checkStdJson reports whether data holds exactly one valid JSON value,
+// string bodies and escapes included
+func checkStdJson(data []byte) error {
+	jh := getJsonH("")
+	defer putJsonH(jh)
+	return jh.checkJson(string(data))
+}
+
+// encodeStdMarshaler encodes v with json.Marshaler or encoding.TextMarshaler
+func encodeStdMarshaler(v *refValue) (string, bool, error) {
+	if v == nil || !v.refIsValid() || v.refKind() == tpInterface {
+		return "", false, nil
+	}
+	val := v.Interface()
+	if val == nil {
+		return "", false, nil
+	}
+
+	for _, candidate := range []any{val, stdAddr(v)} {
+		switch m := candidate.(type) {
+		case stdJsonMarshaler:
+			data, err := m.MarshalJSON()
+			if err != nil {
+				return "", true, err
+			}
+			if err := checkStdJson(data); err != nil {
+				return "", true, Err(errInvalidJSON, "MarshalJSON returned invalid JSON:", err.Error())
+			}
+			return string(data), true, nil
+		case stdTextMarshaler:
+			text, err := m.MarshalText()
+			if err != nil {
+				return "", true, err
+			}
+			return string(appendQuoteJsonString(nil, string(text))), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// decodeStdUnmarshaler decodes into target with json.Unmarshaler or encoding.TextUnmarshaler
+func decodeStdUnmarshaler(jsonStr string, target *refValue) (bool, error) {
+	if target == nil || !target.refIsValid() || target.refKind() == tpInterface {
+		return false, nil
+	}
+	val := target.Interface()
+	if val == nil {
+		return false, nil
+	}
+
+	switch u := stdAddr(target).(type) {
+	case stdJsonUnmarshaler:
+		return true, u.UnmarshalJSON([]byte(jsonStr))
+	case stdTextUnmarshaler:
+		if jsonStr == "null" {
+			return true, nil
+		}
+		if len(jsonStr) < 2 || jsonStr[0] != '"' || jsonStr[len(jsonStr)-1] != '"' {
+			return true, Err(errInvalidJSON, "expected string for TextUnmarshaler but got: "+jsonStr)
+		}
+		jh := getJsonH("")
+		text, err := jh.unescapeJsonString(jsonStr[1 : len(jsonStr)-1])
+		if err != nil {
+			putJsonH(jh)
+			return true, err
+		}
+		// text may alias the pooled escape buffer, copy before releasing it
+		data := []byte(text)
+		putJsonH(jh)
+		return true, u.UnmarshalText(data)
+	}
+	return false, nil
+}
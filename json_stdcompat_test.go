@@ -0,0 +1,118 @@
+//go:build tinywodp_stdcompat && !tinygo
+
+package tinywodp
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// stdCelsius uses encoding/json style methods only
+type stdCelsius float64
+
+func (c stdCelsius) MarshalJSON() ([]byte, error) {
+	return []byte(`{"celsius":` + Convert(float64(c)).String() + `}`), nil
+}
+
+func (c *stdCelsius) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Celsius float64 `json:"celsius"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*c = stdCelsius(aux.Celsius)
+	return nil
+}
+
+// stdLevel uses text methods only
+type stdLevel int
+
+func (l stdLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+func (l *stdLevel) UnmarshalText(text []byte) error {
+	*l = 0
+	if string(text) == "high" {
+		*l = 1
+	}
+	return nil
+}
+
+type stdReading struct {
+	Temp  stdCelsius
+	Level stdLevel
+	ID    BigNumber
+}
+
+func TestStdCompatUserTypes(t *testing.T) {
+	clearRefStructsCache()
+
+	reading := stdReading{Temp: 21.5, Level: 1, ID: "9007199254740993"}
+	encoded, err := Convert(reading).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	expected := `{"Temp":{"celsius":21.5},"Level":"high","ID":9007199254740993}`
+	if string(encoded) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", string(encoded), expected)
+	}
+
+	var decoded stdReading
+	if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if decoded != reading {
+		t.Errorf("round trip = %+v, expected %+v", decoded, reading)
+	}
+}
+
+func TestStdCompatBigNumberWithEncodingJson(t *testing.T) {
+	var out struct{ ID BigNumber }
+	if err := json.Unmarshal([]byte(`{"ID":12345678901234567890}`), &out); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if out.ID != "12345678901234567890" {
+		t.Errorf("ID = %q", out.ID)
+	}
+	data, err := json.Marshal(out)
+	if err != nil || string(data) != `{"ID":12345678901234567890}` {
+		t.Errorf("json.Marshal = %s, %v", string(data), err)
+	}
+}
+
+// stdBroken returns malformed JSON from MarshalJSON
+type stdBroken string
+
+func (b stdBroken) MarshalJSON() ([]byte, error) {
+	return []byte(b), nil
+}
+
+func TestStdCompatRejectsInvalidMarshalJSON(t *testing.T) {
+	clearRefStructsCache()
+
+	for _, out := range []string{`{"a":}`, `"tab	inside"`, `"\q"`, `1 2`, ``} {
+		if _, err := Convert(struct{ B stdBroken }{stdBroken(out)}).JsonEncode(); err == nil {
+			t.Errorf("%q: expected an invalid JSON error", out)
+		}
+	}
+	encoded, err := Convert(struct{ B stdBroken }{` ["ñ", {"k": null}] `}).JsonEncode()
+	if err != nil || !Contains(string(encoded), `"ñ"`) {
+		t.Errorf("valid output rejected: %s, %v", encoded, err)
+	}
+}
+
+func TestStdCompatTextUnescapes(t *testing.T) {
+	clearRefStructsCache()
+
+	var decoded stdReading
+	if err := Convert(`{"Level":"hi\u0067h"}`).JsonDecode(&decoded); err != nil || decoded.Level != 1 {
+		t.Errorf("got %+v, %v", decoded, err)
+	}
+	if err := Convert(`{"Level":1}`).JsonDecode(&decoded); err == nil {
+		t.Error("expected an error for a non-string TextUnmarshaler input")
+	}
+}
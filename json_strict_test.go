@@ -0,0 +1,93 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// invalidLiteralCases are inputs that look like valid JSON up to a point
+// Every decode entry point must reject all of them
+var invalidLiteralCases = []struct {
+	input     string
+	newTarget func() any
+}{
+	{"truex", func() any { return new(bool) }},
+	{"tru", func() any { return new(bool) }},
+	{"falsey", func() any { return new(bool) }},
+	{"nul", func() any { return new(*int) }},
+	{"nulll", func() any { return new(any) }},
+	{"123e", func() any { return new(int) }},
+	{"1e", func() any { return new(float64) }},
+	{"1.2.3", func() any { return new(float64) }},
+	{".5", func() any { return new(float64) }},
+	{"--1", func() any { return new(int) }},
+	{"+1", func() any { return new(int) }},
+	{"01", func() any { return new(int) }},
+	{"0x10", func() any { return new(int) }},
+	{"1_000", func() any { return new(uint) }},
+	{"NaN", func() any { return new(float64) }},
+	{"Infinity", func() any { return new(any) }},
+	{`"a"b"`, func() any { return new(string) }},
+	{"\"tab\there\"", func() any { return new(string) }},
+	{`[1,]`, func() any { return new([]int) }},
+	{`[1,,2]`, func() any { return new([]int) }},
+	{`[true false]`, func() any { return new([]bool) }},
+	{`[1]]`, func() any { return new([]int) }},
+	{`{"Id":"1",}`, func() any { return new(Address) }},
+	{`{"Id":}`, func() any { return new(Address) }},
+	{`{"a":1}}`, func() any { return new(map[string]int) }},
+	{`{"a":[1}`, func() any { return new(any) }},
+}
+
+func TestInvalidLiteralsAllEntryPoints(t *testing.T) {
+	clearRefStructsCache()
+
+	for _, tc := range invalidLiteralCases {
+		if err := Convert(tc.input).JsonDecode(tc.newTarget()); err == nil {
+			t.Errorf("JsonDecode(%s) should return error", tc.input)
+		}
+		if err := Unmarshal([]byte(tc.input), tc.newTarget()); err == nil {
+			t.Errorf("Unmarshal(%s) should return error", tc.input)
+		}
+
+		doc, err := ParseDocument([]byte(tc.input))
+		if err != nil {
+			t.Fatalf("ParseDocument(%s) returned error: %v", tc.input, err)
+		}
+		if err := doc.DecodePath("", tc.newTarget()); err == nil {
+			t.Errorf("Document.DecodePath(%s) should return error", tc.input)
+		}
+
+		// A stream may frame valid leading values; draining it must still fail
+		dec := NewJsonDecoder(&testReader{data: tc.input, chunk: 3, eof: errTestEOF})
+		var streamErr error
+		for dec.More() {
+			if streamErr = dec.Decode(tc.newTarget()); streamErr != nil {
+				break
+			}
+		}
+		if streamErr == nil {
+			t.Errorf("JsonDecoder(%s) should return error", tc.input)
+		}
+	}
+}
+
+func TestValidLiteralsStillAccepted(t *testing.T) {
+	var b bool
+	var n float64
+	var s string
+	var ints []int
+	if err := Convert(" true ").JsonDecode(&b); err != nil || !b {
+		t.Errorf("true: %v", err)
+	}
+	if err := Convert("-0.5e+3").JsonDecode(&n); err != nil || n != -500 {
+		t.Errorf("-0.5e+3 = %v, %v", n, err)
+	}
+	if err := Convert(`"a\"b"`).JsonDecode(&s); err != nil || s != `a"b` {
+		t.Errorf("escaped quote = %q, %v", s, err)
+	}
+	if err := Convert(`[ 1 , 2 ]`).JsonDecode(&ints); err != nil || len(ints) != 2 {
+		t.Errorf("spaced array = %v, %v", ints, err)
+	}
+}
@@ -0,0 +1,198 @@
+package tinywodp
+
+import (
+	"unsafe"
+)
+
+// Package tag namespace
+// The json tag keeps its encoding/json meaning; directives it has no room for
+// live in a tiny tag, parsed once per struct type into the cached struct plan:
+//
+//	type Order struct {
+//		ID     int64   `tiny:"name=id,string"` // {"id":"12345"} both ways
+//		Note   string  `tiny:"omitempty"`      // left out of the output when ""
+//		Ship   Address `tiny:"omitzero"`       // left out when every field is zero
+//		Total  float64 `tiny:"skipdecode"`     // encoded, ignored when decoding
+//		Status string  `tiny:"default=new"`    // "new" unless the payload sets it
+//		Email  string  `tiny:"required"`       // the payload must send it
+//	}
+//
+// name= sets the key written by the encoders whatever the naming convention,
+// and is matched first by the decoder. omitempty drops false, 0, "", nil
+// pointers and interfaces, and empty slices and maps. omitzero is stricter
+// about what counts as nothing: it drops a field only when it equals the zero
+// value of its type, so a non-nil empty slice is kept while a struct or array
+// whose every element is zero is dropped. string behaves like
+// json:",string". default= fills a field still holding its zero value before
+// the object is decoded, so a member present in the payload always wins; it
+// takes the rest of the tag, commas included, as the text of a string field or
+// as JSON for any other kind, and must come last. A standalone
+// `default:"..."` tag does the same. Defaults inside a nested struct field are
+// filled even when the payload leaves its whole object out, as config files do
+// with optional tables. required is described in json_required.go, and redact
+// with the encode hooks in json_hooks.go.
+
+// tinyTag holds the directives of a tiny struct tag
+type tinyTag struct {
+	name       string // key override, "" for none
+	omitEmpty  bool   // omitempty: skip empty values when encoding
+	omitZero   bool   // omitzero: skip the zero value of the type when encoding
+	quoted     bool   // string: same as json:",string"
+	skipDecode bool   // skipdecode: the decoder ignores the member
+	required   bool   // required: a decode fails when the member is missing
+	redact     bool   // redact: encoded as "***"
+	hasDefault bool   // default= was given
+	defaultVal string // text after default=
+}
+
+// parseTinyTag splits a tiny tag into its directives; unknown ones are ignored,
+// like unknown json tag options
+func parseTinyTag(tag string) tinyTag {
+	var t tinyTag
+	for tag != "" {
+		if t.defaultVal, t.hasDefault = cutTagPrefix(tag, "default="); t.hasDefault {
+			break
+		}
+		option := tag
+		if comma := indexByte(tag, ','); comma != -1 {
+			option, tag = tag[:comma], tag[comma+1:]
+		} else {
+			tag = ""
+		}
+		if name, ok := cutTagPrefix(option, "name="); ok {
+			t.name = name
+			continue
+		}
+		switch option {
+		case "omitempty":
+			t.omitEmpty = true
+		case "omitzero":
+			t.omitZero = true
+		case "string":
+			t.quoted = true
+		case "skipdecode":
+			t.skipDecode = true
+		case "required":
+			t.required = true
+		case "redact":
+			t.redact = true
+		}
+	}
+	return t
+}
+
+// cutTagPrefix returns s without prefix, reporting whether s started with it
+func cutTagPrefix(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || s[:len(prefix)] != prefix {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
+
+// tinyDefaultJson frames the default= text of a field of type t as JSON:
+// strings, and pointers to strings, are quoted and anything else is taken as is
+func tinyDefaultJson(text string, t *refType) string {
+	if t != nil && t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == tpString && t != bigNumberType {
+		return string(appendQuoteJsonString(nil, text))
+	}
+	return text
+}
+
+// isEmptyJsonValue reports whether v is skipped by omitempty
+func isEmptyJsonValue(v *refValue) bool {
+	switch v.refKind() {
+	case tpString:
+		return v.refString() == ""
+	case tpBool:
+		return !v.refBool()
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return v.refInt() == 0
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return v.refUint() == 0
+	case tpFloat32, tpFloat64:
+		return v.refFloat() == 0
+	case tpSlice, tpStrSlice:
+		return v.refLen() == 0
+	case tpMap:
+		return v.refMapIsNil()
+	case tpPointer:
+		return !v.refElem().refIsValid()
+	case tpInterface:
+		return v.refInterfaceValue() == nil
+	}
+	return false
+}
+
+// isZeroJsonValue reports whether v is the zero value of its type, which
+// omitzero skips; structs and arrays are compared element by element
+func isZeroJsonValue(v *refValue) bool {
+	switch v.refKind() {
+	case tpSlice, tpMap, tpPointer, tpInterface:
+		return v.refIsNil()
+	case tpStrSlice:
+		return v.ptr == nil || *(*unsafe.Pointer)(v.ptr) == nil
+	case tpStruct:
+		for i := range v.refNumField() {
+			if field := v.refField(i); field.refIsValid() && !isZeroJsonValue(field) {
+				return false
+			}
+		}
+		return true
+	case tpArray:
+		for i := range v.refLen() {
+			if !isZeroJsonValue(v.refIndex(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return isEmptyJsonValue(v)
+}
+
+// omitted reports whether the encoders leave out the field holding v
+func (f *planField) omitted(v *refValue) bool {
+	return f.unexported || f.omitEmpty && isEmptyJsonValue(v) || f.omitZero && isZeroJsonValue(v)
+}
+
+// hasNestedDefaults reports whether v is a struct decoded field by field with
+// defaults of its own
+func hasNestedDefaults(v *refValue) bool {
+	if v.refKind() != tpStruct || isTimeType(v) || customCodecFor(v)&codecUnmarshalPtr != 0 {
+		return false
+	}
+	plan, err := decodePlanFor(v)
+	return err == nil && len(plan.defaults) > 0
+}
+
+// applyJsonDefaults sets the default value of every field of target that
+// still holds its zero value, descending into nested struct fields
+func (jh *jsonH) applyJsonDefaults(target *refValue, plan *decodePlan) error {
+	for _, i := range plan.defaults {
+		fieldConv := target.refField(i)
+		if !fieldConv.refIsValid() {
+			continue
+		}
+		field := &plan.fields[i]
+		if field.defaultJson == "" && fieldConv.refKind() == tpStruct {
+			nested, err := decodePlanFor(fieldConv)
+			if err == nil {
+				err = jh.applyJsonDefaults(fieldConv, nested)
+			}
+			if err != nil {
+				jh.prefixErrorPath(field.name)
+				return err
+			}
+			continue
+		}
+		if !isEmptyJsonValue(fieldConv) {
+			continue
+		}
+		if _, err := jh.parseJsonField(field.name, field.defaultJson, 0, fieldConv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
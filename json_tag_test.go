@@ -0,0 +1,201 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type tagOrder struct {
+	ID     int64   `tiny:"name=id,string"`
+	Note   string  `tiny:"omitempty"`
+	Items  []int   `tiny:"omitempty"`
+	Owner  *string `tiny:"omitempty,name=owner"`
+	Total  float64 `tiny:"skipdecode"`
+	Status string  `json:"state" tiny:"default=new, unpaid"`
+	Retry  int     `tiny:"default=3"`
+}
+
+func TestParseTinyTag(t *testing.T) {
+	got := parseTinyTag("name=id,omitempty,string,skipdecode,unknown,default=a,b")
+	want := tinyTag{name: "id", omitEmpty: true, quoted: true, skipDecode: true, hasDefault: true, defaultVal: "a,b"}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := parseTinyTag(""); got != (tinyTag{}) {
+		t.Errorf("empty tag: got %+v", got)
+	}
+	if got := parseTinyTag("default="); !got.hasDefault || got.defaultVal != "" {
+		t.Errorf("empty default: got %+v", got)
+	}
+}
+
+func TestTinyTagEncode(t *testing.T) {
+	clearRefStructsCache()
+
+	order := tagOrder{ID: 12345, Total: 9.5, Status: "paid"}
+	out, err := Convert(&order).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode failed: %v", err)
+	}
+	// name= and json tag names win over the naming convention, omitempty drops
+	// the zero fields
+	expected := `{"id":"12345","Total":9.5,"state":"paid","Retry":0}`
+	if string(out) != expected {
+		t.Errorf("got  %s\nwant %s", out, expected)
+	}
+
+	owner := "ana"
+	order.Note, order.Items, order.Owner = "x", []int{1}, &owner
+	SetFieldNaming(FieldNamingSnake)
+	defer SetFieldNaming(FieldNamingPascal)
+	out, err = Convert(&order).JsonEncode()
+	expected = `{"id":"12345","note":"x","items":[1],"owner":"ana","total":9.5,"state":"paid","retry":0}`
+	if err != nil || string(out) != expected {
+		t.Errorf("got  %s, %v\nwant %s", out, err, expected)
+	}
+
+	var captured []byte
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		captured = append(captured, p...)
+		return len(p), nil
+	}})
+	if err := enc.Encode(&order); err != nil || string(captured) != expected+"\n" {
+		t.Errorf("Encode wrote %s, %v", captured, err)
+	}
+}
+
+func TestTinyTagDecode(t *testing.T) {
+	clearRefStructsCache()
+
+	var order tagOrder
+	if err := Convert(`{"id":"77","Total":1.5,"owner":"bo"}`).JsonDecode(&order); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if order.ID != 77 || order.Total != 0 || order.Owner == nil || *order.Owner != "bo" {
+		t.Errorf("got %+v", order)
+	}
+	if order.Status != "new, unpaid" || order.Retry != 3 {
+		t.Errorf("defaults not applied: %+v", order)
+	}
+
+	// Members present in the payload win over defaults, zero values included
+	order = tagOrder{}
+	if err := Convert(`{"state":"paid","Retry":0,"ID":5}`).JsonDecode(&order); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if order.Status != "paid" || order.Retry != 0 || order.ID != 5 {
+		t.Errorf("got %+v", order)
+	}
+
+	// Values already set are kept
+	order = tagOrder{Retry: 9}
+	if err := Convert(`{}`).JsonDecode(&order); err != nil || order.Retry != 9 {
+		t.Errorf("got %+v, %v", order, err)
+	}
+}
+
+func TestTinyTagBadDefault(t *testing.T) {
+	clearRefStructsCache()
+
+	var v struct {
+		N int `tiny:"default=many"`
+	}
+	err := Convert(`{}`).JsonDecode(&v)
+	if err == nil || !Contains(err.Error(), "N") {
+		t.Errorf("expected an error naming the field, got %v", err)
+	}
+}
+
+type tagServerConfig struct {
+	Host    string  `default:"localhost"`
+	Port    int     `default:"8080"`
+	Verbose bool    `default:"true"`
+	Timeout float64 `tiny:"default=2.5"`
+}
+
+type tagConfig struct {
+	Name   string
+	Server tagServerConfig
+}
+
+func TestDefaultTagNestedConfig(t *testing.T) {
+	clearRefStructsCache()
+
+	// The [server] table is missing altogether
+	var cfg tagConfig
+	if err := ConfigDecode("name = \"api\"\n", &cfg); err != nil {
+		t.Fatalf("ConfigDecode failed: %v", err)
+	}
+	want := tagServerConfig{Host: "localhost", Port: 8080, Verbose: true, Timeout: 2.5}
+	if cfg.Name != "api" || cfg.Server != want {
+		t.Errorf("got %+v", cfg)
+	}
+
+	cfg = tagConfig{}
+	if err := ConfigDecode("[server]\nport = 9000\nverbose = false\n", &cfg); err != nil {
+		t.Fatalf("ConfigDecode failed: %v", err)
+	}
+	want = tagServerConfig{Host: "localhost", Port: 9000, Verbose: false, Timeout: 2.5}
+	if cfg.Server != want {
+		t.Errorf("got %+v", cfg.Server)
+	}
+
+	var bad struct {
+		Server struct {
+			Port int `default:"http"`
+		}
+	}
+	if err := Convert(`{}`).JsonDecode(&bad); err == nil || !Contains(err.Error(), "Server.Port") {
+		t.Errorf("expected an error naming Server.Port, got %v", err)
+	}
+}
+
+type zeroShipment struct {
+	Street string
+	Zip    int
+}
+
+type zeroOrder struct {
+	Items   []int        `tiny:"omitzero"`
+	Tags    []string     `tiny:"omitempty"`
+	Ship    zeroShipment `tiny:"omitzero"`
+	Grid    [2]int       `tiny:"omitzero"`
+	Note    *string      `tiny:"omitzero"`
+	Retries int          `tiny:"omitzero,name=retries"`
+}
+
+func TestTinyTagOmitZero(t *testing.T) {
+	clearRefStructsCache()
+
+	if got := parseTinyTag("omitzero"); !got.omitZero || got.omitEmpty {
+		t.Errorf("parseTinyTag(omitzero) = %+v", got)
+	}
+
+	out, err := Marshal(zeroOrder{})
+	if err != nil || string(out) != `{}` {
+		t.Errorf("zero value = %s, %v; expected {}", out, err)
+	}
+
+	// A non-nil empty slice is not the zero value, unlike with omitempty
+	out, err = Marshal(zeroOrder{Items: []int{}, Tags: []string{}})
+	if err != nil || string(out) != `{"Items":[]}` {
+		t.Errorf("empty slices = %s, %v; expected {\"Items\":[]}", out, err)
+	}
+
+	o := zeroOrder{Ship: zeroShipment{Zip: 1000}, Grid: [2]int{0, 3}, Retries: 2}
+	expected := `{"Ship":{"Street":"","Zip":1000},"Grid":[0,3],"retries":2}`
+	out, err = Marshal(o)
+	if err != nil || string(out) != expected {
+		t.Errorf("partly set = %s, %v; expected %s", out, err, expected)
+	}
+
+	var captured []byte
+	enc := NewJsonEncoder(&testWriter{writeFunc: func(p []byte) (int, error) {
+		captured = append(captured, p...)
+		return len(p), nil
+	}})
+	if err := enc.Encode(&o); err != nil || string(captured) != expected+"\n" {
+		t.Errorf("Encode wrote %s, %v", captured, err)
+	}
+}
@@ -0,0 +1,49 @@
+package tinywodp
+
+// Decoding into a value that already holds data
+// By default a decode merges the payload into the target, like encoding/json:
+//
+//   - a member present in the payload overwrites its field; fields the payload
+//     leaves out keep their value
+//   - a nested struct, and the pointee of a non-nil pointer, are merged the
+//     same way, member by member; the pointee is updated in place, so other
+//     holders of that pointer see the change
+//   - a slice is replaced by a new one holding the decoded elements
+//   - a fixed-size array is decoded element by element and elements past the
+//     end of the JSON array are zeroed
+//   - a map keeps its entries and gains or overwrites the decoded keys
+//   - null follows the NullPolicy: nillable fields are set to nil unless
+//     NullKeep is given
+//
+// TargetReset zeroes the whole target first, so the result depends on the
+// payload alone, as if a fresh value had been decoded, and no pointee or map
+// reached from the old value is written to:
+//
+//	err := Convert(data).JsonDecode(&cfg, TargetReset)
+//
+// tiny default= values still apply to the zeroed fields. A failed decode
+// leaves the target holding what was decoded before the error, in both modes.
+// Targets with their own JsonUnmarshaler decide for themselves.
+
+// TargetPolicy controls what decoding does with the data already in the target
+type TargetPolicy uint8
+
+const (
+	// TargetMerge overwrites the members present in the payload and leaves the
+	// others, like encoding/json (default)
+	TargetMerge TargetPolicy = iota
+	// TargetReset zeroes the target before decoding into it
+	TargetReset
+)
+
+// applyDecode sets the target policy for the operation
+func (p TargetPolicy) applyDecode(jh *jsonH) {
+	jh.jTarget = p
+}
+
+// resetJsonTarget zeroes target before decoding when TargetReset is chosen
+func (jh *jsonH) resetJsonTarget(target *refValue) {
+	if jh.jTarget == TargetReset && target.ptr != nil {
+		memclr(target.ptr, target.Type().Size())
+	}
+}
@@ -0,0 +1,93 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type targetConfig struct {
+	Name    string
+	Port    int
+	Retry   int `tiny:"default=3"`
+	Tags    []string
+	Limits  map[string]int
+	Owner   *Address
+	Backups [2]Address
+}
+
+func filledTargetConfig(owner *Address) targetConfig {
+	return targetConfig{
+		Name:    "old",
+		Port:    80,
+		Retry:   9,
+		Tags:    []string{"a", "b"},
+		Limits:  map[string]int{"cpu": 1},
+		Owner:   owner,
+		Backups: [2]Address{{Id: "b1", City: "Lima"}, {Id: "b2"}},
+	}
+}
+
+func TestDecodeMergesIntoTarget(t *testing.T) {
+	clearRefStructsCache()
+
+	owner := &Address{Id: "1", City: "NYC"}
+	cfg := filledTargetConfig(owner)
+	data := `{"Port":8080,"Tags":["c"],"Limits":{"mem":2},"Owner":{"City":"Paris"},"Backups":[{"Id":"n1"}]}`
+	if err := Convert(data).JsonDecode(&cfg); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+
+	if cfg.Name != "old" || cfg.Port != 8080 || cfg.Retry != 9 {
+		t.Errorf("scalars = %q %d %d; expected absent fields kept", cfg.Name, cfg.Port, cfg.Retry)
+	}
+	if len(cfg.Tags) != 1 || cfg.Tags[0] != "c" {
+		t.Errorf("Tags = %v; expected the slice replaced", cfg.Tags)
+	}
+	if cfg.Limits["cpu"] != 1 || cfg.Limits["mem"] != 2 {
+		t.Errorf("Limits = %v; expected the map merged", cfg.Limits)
+	}
+	if cfg.Owner != owner || owner.Id != "1" || owner.City != "Paris" {
+		t.Errorf("Owner = %+v; expected the pointee merged in place", cfg.Owner)
+	}
+	if cfg.Backups[0].Id != "n1" || cfg.Backups[0].City != "Lima" || cfg.Backups[1] != (Address{}) {
+		t.Errorf("Backups = %+v; expected element merged and the missing one zeroed", cfg.Backups)
+	}
+}
+
+func TestDecodeTargetReset(t *testing.T) {
+	clearRefStructsCache()
+
+	owner := &Address{Id: "1", City: "NYC"}
+	limits := map[string]int{"cpu": 1}
+	cfg := filledTargetConfig(owner)
+	cfg.Limits = limits
+	data := `{"Port":8080,"Limits":{"mem":2},"Owner":{"City":"Paris"}}`
+	if err := Convert(data).JsonDecode(&cfg, TargetReset); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+
+	expected := targetConfig{Port: 8080, Retry: 3, Owner: &Address{City: "Paris"}}
+	if cfg.Name != "" || cfg.Port != 8080 || cfg.Retry != expected.Retry || cfg.Tags != nil || cfg.Backups != expected.Backups {
+		t.Errorf("reset decode = %+v; expected %+v", cfg, expected)
+	}
+	if len(cfg.Limits) != 1 || cfg.Limits["mem"] != 2 {
+		t.Errorf("Limits = %v; expected only the decoded key", cfg.Limits)
+	}
+	if cfg.Owner == owner || *cfg.Owner != *expected.Owner {
+		t.Errorf("Owner = %+v; expected a fresh pointee", cfg.Owner)
+	}
+	if owner.City != "NYC" || limits["mem"] != 0 {
+		t.Errorf("old values written to: owner %+v, limits %v", owner, limits)
+	}
+
+	// JsonOptions carries the policy too, and it does not outlive the call
+	cfg = filledTargetConfig(owner)
+	if err := Convert(`{}`).JsonDecodeWith(JsonOptions{Target: TargetReset}, &cfg); err != nil || cfg.Name != "" || cfg.Retry != 3 {
+		t.Errorf("JsonDecodeWith reset = %+v, %v", cfg, err)
+	}
+	cfg = filledTargetConfig(owner)
+	if err := Convert(`{}`).JsonDecode(&cfg); err != nil || cfg.Name != "old" {
+		t.Errorf("later decode = %+v, %v; expected TargetMerge again", cfg, err)
+	}
+}
@@ -0,0 +1,92 @@
+package tinywodp
+
+import (
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// time.Time support
+// time.Time is encoded as an RFC 3339 string by default instead of being walked
+// as an opaque struct. The package-level format selects an alternative
+// representation for every encode and decode:
+//
+//	SetTimeFormat(TimeUnixMillis)           // {"CreatedAt":1718000000000}
+//	SetTimeFormat(TimeFormat("2006-01-02")) // custom time.Parse layout
+//
+// Set it once at program start; it is not synchronized with running operations.
+
+// TimeFormat selects how time.Time values are represented in JSON
+// Any value other than the predefined ones is used as a time layout string
+type TimeFormat string
+
+const (
+	// TimeRFC3339 encodes as an RFC 3339 string with nanoseconds when present (default)
+	TimeRFC3339 TimeFormat = time.RFC3339Nano
+	// TimeUnixSeconds encodes as a JSON number of seconds since the Unix epoch
+	TimeUnixSeconds TimeFormat = "unix"
+	// TimeUnixMillis encodes as a JSON number of milliseconds since the Unix epoch
+	TimeUnixMillis TimeFormat = "unixmilli"
+)
+
+// jsonTimeFormat is the active time representation
+var jsonTimeFormat = TimeRFC3339
+
+// timeType is the type descriptor used to detect time.Time values
+var timeType = refValueOf(time.Time{}).Type()
+
+// SetTimeFormat changes the representation of time.Time values; empty restores RFC 3339
+func SetTimeFormat(f TimeFormat) {
+	if f == "" {
+		f = TimeRFC3339
+	}
+	jsonTimeFormat = f
+}
+
+// isTimeType reports whether v holds a time.Time
+func isTimeType(v *refValue) bool {
+	return v.Type() == timeType
+}
+
+// encodeJsonTime returns the JSON text for t in the active format
+func encodeJsonTime(t time.Time) string {
+	switch jsonTimeFormat {
+	case TimeUnixSeconds:
+		return Convert(t.Unix()).String()
+	case TimeUnixMillis:
+		return Convert(t.UnixMilli()).String()
+	}
+	return `"` + t.Format(string(jsonTimeFormat)) + `"`
+}
+
+// decodeJsonTime parses a JSON value in the active format into an addressable time.Time
+// null is rejected as for other struct kinds; use *time.Time for an optional time
+func decodeJsonTime(jsonStr string, target *refValue) error {
+	var parsed time.Time
+	switch jsonTimeFormat {
+	case TimeUnixSeconds, TimeUnixMillis:
+		if !isJsonNumber(jsonStr) || !isJsonIntegerLiteral(jsonStr) {
+			return Err(errInvalidJSON, "expected unix timestamp but got: "+jsonStr)
+		}
+		n, err := Convert(jsonStr).ToInt64()
+		if err != nil {
+			return Err(errInvalidJSON, "invalid unix timestamp: "+jsonStr)
+		}
+		if jsonTimeFormat == TimeUnixSeconds {
+			parsed = time.Unix(n, 0).UTC()
+		} else {
+			parsed = time.UnixMilli(n).UTC()
+		}
+	default:
+		if len(jsonStr) < 2 || jsonStr[0] != '"' || jsonStr[len(jsonStr)-1] != '"' {
+			return Err(errInvalidJSON, "expected time string but got: "+jsonStr)
+		}
+		var err error
+		if parsed, err = time.Parse(string(jsonTimeFormat), jsonStr[1:len(jsonStr)-1]); err != nil {
+			return Err(errInvalidJSON, "invalid time: "+err.Error())
+		}
+	}
+
+	*(*time.Time)(target.ptr) = parsed
+	return nil
+}
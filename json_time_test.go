@@ -0,0 +1,90 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type timeEvent struct {
+	Name string
+	At   time.Time
+	Seen []time.Time
+	Next *time.Time
+}
+
+func TestJsonTimeRFC3339(t *testing.T) {
+	clearRefStructsCache()
+
+	at := time.Date(2024, 6, 10, 8, 30, 0, 0, time.UTC)
+	next := at.Add(1500 * time.Millisecond)
+	event := timeEvent{Name: "deploy", At: at, Seen: []time.Time{at}, Next: &next}
+
+	encoded, err := Convert(event).JsonEncode()
+	if err != nil {
+		t.Fatalf("JsonEncode returned error: %v", err)
+	}
+	expected := `{"Name":"deploy","At":"2024-06-10T08:30:00Z","Seen":["2024-06-10T08:30:00Z"],"Next":"2024-06-10T08:30:01.5Z"}`
+	if string(encoded) != expected {
+		t.Errorf("JsonEncode = %s, expected %s", string(encoded), expected)
+	}
+
+	var decoded timeEvent
+	if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode returned error: %v", err)
+	}
+	if !decoded.At.Equal(at) || len(decoded.Seen) != 1 || !decoded.Seen[0].Equal(at) {
+		t.Errorf("decoded = %+v", decoded)
+	}
+	if decoded.Next == nil || !decoded.Next.Equal(next) {
+		t.Errorf("Next = %v, expected %v", decoded.Next, next)
+	}
+
+	if err := Convert(`{"At":"yesterday"}`).JsonDecode(&decoded); err == nil {
+		t.Error("invalid time should return error")
+	}
+
+	// null is rejected by time.Time like any struct, and clears a *time.Time
+	if err := Convert(`{"At":null}`).JsonDecode(&decoded); err == nil {
+		t.Error("null into time.Time should return error")
+	}
+	if err := Convert(`{"Next":null}`).JsonDecode(&decoded); err != nil || decoded.Next != nil {
+		t.Errorf("null into *time.Time = %v, %v; expected nil", decoded.Next, err)
+	}
+}
+
+func TestJsonTimeFormats(t *testing.T) {
+	clearRefStructsCache()
+	defer SetTimeFormat("")
+
+	at := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format   TimeFormat
+		expected string
+	}{
+		{TimeUnixSeconds, `{"Name":"","At":1717977600,"Seen":[],"Next":null}`},
+		{TimeUnixMillis, `{"Name":"","At":1717977600000,"Seen":[],"Next":null}`},
+		{TimeFormat("2006-01-02"), `{"Name":"","At":"2024-06-10","Seen":[],"Next":null}`},
+	}
+
+	for _, tt := range tests {
+		SetTimeFormat(tt.format)
+
+		encoded, err := Convert(timeEvent{At: at}).JsonEncode()
+		if err != nil {
+			t.Fatalf("%s: JsonEncode returned error: %v", tt.format, err)
+		}
+		if string(encoded) != tt.expected {
+			t.Errorf("%s: JsonEncode = %s, expected %s", tt.format, string(encoded), tt.expected)
+		}
+
+		var decoded timeEvent
+		if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+			t.Fatalf("%s: JsonDecode returned error: %v", tt.format, err)
+		}
+		if !decoded.At.Equal(at) {
+			t.Errorf("%s: At = %v, expected %v", tt.format, decoded.At, at)
+		}
+	}
+}
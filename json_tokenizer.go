@@ -0,0 +1,325 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Streaming tokenizer
+// A SAX-style view of a JSON stream: each call to Next returns the next token
+// without building any intermediate value, so huge documents can be filtered or
+// transformed in constant memory. The grammar is checked as tokens are read.
+//
+//	tok := NewJsonTokenizer(file)
+//	for {
+//		t, err := tok.Next()
+//		if err != nil {
+//			break // io.EOF once the stream ends cleanly
+//		}
+//		if t.Kind == TokenKey && t.Value == "password" {
+//			...
+//		}
+//	}
+//
+// Like JsonDecoder, several top-level values may follow each other (NDJSON).
+
+// TokenKind identifies the type of a Token
+type TokenKind uint8
+
+const (
+	TokenInvalid     TokenKind = iota // zero value, never returned by Next
+	TokenObjectStart                  // {
+	TokenObjectEnd                    // }
+	TokenArrayStart                   // [
+	TokenArrayEnd                     // ]
+	TokenKey                          // object member name, Value holds the unescaped text
+	TokenString                       // Value holds the unescaped text
+	TokenNumber                       // Value holds the literal, never rounded
+	TokenBool                         // Value is "true" or "false"
+	TokenNull                         // null
+)
+
+// tokenKindNames are the names returned by TokenKind.String
+var tokenKindNames = [...]string{"invalid", "object start", "object end", "array start", "array end", "key", "string", "number", "bool", "null"}
+
+// String returns a readable name for the token kind
+func (k TokenKind) String() string {
+	if int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return tokenKindNames[TokenInvalid]
+}
+
+// Token is a single JSON token
+type Token struct {
+	Kind  TokenKind
+	Value string // text of keys, strings, numbers and booleans; empty otherwise
+}
+
+// Number returns the token as a lossless Number
+func (t Token) Number() Number {
+	return Number(t.Value)
+}
+
+// Bool reports whether the token is the literal true
+func (t Token) Bool() bool {
+	return t.Kind == TokenBool && t.Value == "true"
+}
+
+// tokenizer grammar states: what may come next
+const (
+	tokExpectValue      = iota // top level, after ':' or after ',' in an array
+	tokExpectValueOrEnd        // after '['
+	tokExpectKey               // after ',' in an object
+	tokExpectKeyOrEnd          // after '{'
+	tokExpectColon             // after a key
+	tokExpectCommaOrEnd        // after a value inside a container
+)
+
+// JsonTokenizer reads JSON tokens from an input stream
+type JsonTokenizer struct {
+	r      reader
+	buf    []byte // unread input starts at buf[pos]
+	pos    int
+	offset int   // bytes of input dropped before buf[0], for error offsets
+	err    error // sticky reader error
+	expect int
+	stack  []byte // open containers, '{' or '['
+}
+
+// NewJsonTokenizer returns a tokenizer that reads from r
+func NewJsonTokenizer(r reader) *JsonTokenizer {
+	return &JsonTokenizer{
+		r:   r,
+		buf: allocBytes(decoderReadSize),
+	}
+}
+
+// Depth returns the number of objects and arrays currently open
+func (t *JsonTokenizer) Depth() int {
+	return len(t.stack)
+}
+
+// Next returns the next token
+// When the stream ends cleanly between top-level values the reader's error is
+// returned unchanged (io.EOF for standard readers)
+func (t *JsonTokenizer) Next() (Token, error) {
+	if t.r == nil {
+		return Token{}, Err(errInvalidJSON, "tokenizer reader cannot be nil")
+	}
+
+	for {
+		c, ok := t.peekNonSpace()
+		if !ok {
+			if len(t.stack) == 0 && t.expect == tokExpectValue {
+				return Token{}, t.err
+			}
+			return Token{}, t.syntaxError("unexpected end of input")
+		}
+
+		switch t.expect {
+		case tokExpectColon:
+			if c != ':' {
+				return Token{}, t.syntaxError("expected ':' after object key")
+			}
+			t.pos++
+			t.expect = tokExpectValue
+			continue
+
+		case tokExpectCommaOrEnd:
+			switch {
+			case c == ',':
+				t.pos++
+				if t.stack[len(t.stack)-1] == '{' {
+					t.expect = tokExpectKey
+				} else {
+					t.expect = tokExpectValue
+				}
+				continue
+			case c == '}' || c == ']':
+				return t.closeContainer(c)
+			}
+			return Token{}, t.syntaxError("expected ',' or end of container")
+
+		case tokExpectKeyOrEnd, tokExpectKey:
+			if c == '}' && t.expect == tokExpectKeyOrEnd {
+				return t.closeContainer(c)
+			}
+			if c != '"' {
+				return Token{}, t.syntaxError("expected object key")
+			}
+			key, err := t.readString()
+			if err != nil {
+				return Token{}, err
+			}
+			t.expect = tokExpectColon
+			return Token{Kind: TokenKey, Value: key}, nil
+
+		case tokExpectValueOrEnd:
+			if c == ']' {
+				return t.closeContainer(c)
+			}
+		}
+
+		return t.readValue(c)
+	}
+}
+
+// readValue reads the value starting with c
+func (t *JsonTokenizer) readValue(c byte) (Token, error) {
+	switch c {
+	case '{', '[':
+		if len(t.stack) >= maxJsonDepth {
+			return Token{}, Err(ErrMaxDepth, Convert(maxJsonDepth).String())
+		}
+		t.pos++
+		t.stack = append(t.stack, c)
+		if c == '{' {
+			t.expect = tokExpectKeyOrEnd
+			return Token{Kind: TokenObjectStart}, nil
+		}
+		t.expect = tokExpectValueOrEnd
+		return Token{Kind: TokenArrayStart}, nil
+	case '"':
+		s, err := t.readString()
+		if err != nil {
+			return Token{}, err
+		}
+		t.afterValue()
+		return Token{Kind: TokenString, Value: s}, nil
+	case '}', ']', ',', ':':
+		return Token{}, t.syntaxError("unexpected '" + string(c) + "'")
+	}
+
+	literal, err := t.readLiteral()
+	if err != nil {
+		return Token{}, err
+	}
+	t.afterValue()
+	switch {
+	case literal == "true" || literal == "false":
+		return Token{Kind: TokenBool, Value: literal}, nil
+	case literal == "null":
+		return Token{Kind: TokenNull}, nil
+	}
+	return Token{Kind: TokenNumber, Value: literal}, nil
+}
+
+// closeContainer consumes c, which must match the innermost open container
+func (t *JsonTokenizer) closeContainer(c byte) (Token, error) {
+	open := t.stack[len(t.stack)-1]
+	if (open == '{') != (c == '}') {
+		return Token{}, t.syntaxError("mismatched '" + string(c) + "'")
+	}
+	t.pos++
+	t.stack = t.stack[:len(t.stack)-1]
+	t.afterValue()
+	if c == '}' {
+		return Token{Kind: TokenObjectEnd}, nil
+	}
+	return Token{Kind: TokenArrayEnd}, nil
+}
+
+// afterValue sets what may follow a complete value
+func (t *JsonTokenizer) afterValue() {
+	if len(t.stack) == 0 {
+		t.expect = tokExpectValue // next top-level value
+	} else {
+		t.expect = tokExpectCommaOrEnd
+	}
+}
+
+// readString reads the quoted string at pos and returns it unescaped
+func (t *JsonTokenizer) readString() (string, error) {
+	escaped := false
+	for n := 1; ; n++ {
+		for t.pos+n >= len(t.buf) {
+			if !t.fill() {
+				return "", t.syntaxError("unterminated string")
+			}
+		}
+		b := t.buf[t.pos+n]
+		switch {
+		case escaped:
+			escaped = false
+		case b == '\\':
+			escaped = true
+		case b == '"':
+			raw := string(t.buf[t.pos+1 : t.pos+n])
+			jh := getJsonH("_")
+			s, err := jh.unescapeJsonString(raw)
+			putJsonH(jh)
+			if err != nil {
+				return "", t.syntaxError(err.Error())
+			}
+			t.pos += n + 1
+			return s, nil
+		}
+	}
+}
+
+// readLiteral reads true, false, null or a number at pos
+func (t *JsonTokenizer) readLiteral() (string, error) {
+	n := 0
+	for {
+		for t.pos+n >= len(t.buf) && t.fill() {
+		}
+		if t.pos+n >= len(t.buf) || isJsonDelimiter(t.buf[t.pos+n]) {
+			break // a literal may end the input
+		}
+		n++
+	}
+	literal := string(t.buf[t.pos : t.pos+n])
+	if literal != "true" && literal != "false" && literal != "null" && !isJsonNumber(literal) {
+		return "", t.syntaxError("invalid literal: " + literal)
+	}
+	t.pos += n
+	return literal, nil
+}
+
+// peekNonSpace skips whitespace and returns the next byte without consuming it
+func (t *JsonTokenizer) peekNonSpace() (byte, bool) {
+	for {
+		for t.pos < len(t.buf) {
+			if !isJsonSpace(t.buf[t.pos]) {
+				return t.buf[t.pos], true
+			}
+			t.pos++
+		}
+		if !t.fill() {
+			return 0, false
+		}
+	}
+}
+
+// fill drops consumed bytes and reads the next chunk; positions relative to
+// pos stay valid. Returns false once the reader has reported an error.
+func (t *JsonTokenizer) fill() bool {
+	if t.err != nil {
+		return false
+	}
+
+	if t.pos > 0 {
+		remaining := copy(t.buf, t.buf[t.pos:])
+		t.buf = t.buf[:remaining]
+		t.offset += t.pos
+		t.pos = 0
+	}
+	if cap(t.buf)-len(t.buf) < decoderReadSize {
+		grown := allocBytes(2*cap(t.buf) + decoderReadSize)[:len(t.buf)]
+		copy(grown, t.buf)
+		freeHint(t.buf)
+		t.buf = grown
+	}
+
+	n, err := t.r.Read(t.buf[len(t.buf) : len(t.buf)+decoderReadSize])
+	t.buf = t.buf[:len(t.buf)+n]
+	if err != nil {
+		t.err = err
+	}
+	return n > 0 || err == nil
+}
+
+// syntaxError returns a syntax error at the current input offset
+func (t *JsonTokenizer) syntaxError(msg string) error {
+	return Err(errInvalidJSON, msg, "at offset", t.offset+t.pos)
+}
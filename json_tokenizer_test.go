@@ -0,0 +1,73 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonTokenizer(t *testing.T) {
+	input := `{"name": "a\"b", "n": [1, -2.5e3, 12345678901234567890], "ok": true, "none": null, "empty": {}}` + "\n" + `[]`
+	expected := []Token{
+		{Kind: TokenObjectStart},
+		{Kind: TokenKey, Value: "name"}, {Kind: TokenString, Value: `a"b`},
+		{Kind: TokenKey, Value: "n"}, {Kind: TokenArrayStart},
+		{Kind: TokenNumber, Value: "1"}, {Kind: TokenNumber, Value: "-2.5e3"}, {Kind: TokenNumber, Value: "12345678901234567890"},
+		{Kind: TokenArrayEnd},
+		{Kind: TokenKey, Value: "ok"}, {Kind: TokenBool, Value: "true"},
+		{Kind: TokenKey, Value: "none"}, {Kind: TokenNull},
+		{Kind: TokenKey, Value: "empty"}, {Kind: TokenObjectStart}, {Kind: TokenObjectEnd},
+		{Kind: TokenObjectEnd},
+		{Kind: TokenArrayStart}, {Kind: TokenArrayEnd},
+	}
+
+	// Tiny chunks force strings and numbers to span reads
+	for _, chunk := range []int{1, 3, 4096} {
+		tok := NewJsonTokenizer(&testReader{data: input, chunk: chunk, eof: errTestEOF})
+		for i, want := range expected {
+			got, err := tok.Next()
+			if err != nil {
+				t.Fatalf("chunk %d: token %d returned error: %v", chunk, i, err)
+			}
+			if got != want {
+				t.Fatalf("chunk %d: token %d = %+v, expected %+v", chunk, i, got, want)
+			}
+		}
+		if _, err := tok.Next(); err != errTestEOF {
+			t.Errorf("chunk %d: Next at end returned %v, expected reader EOF", chunk, err)
+		}
+	}
+}
+
+func TestJsonTokenizerErrors(t *testing.T) {
+	invalid := []string{
+		`{"a" 1}`,
+		`{"a":1,}`,
+		`[1 2]`,
+		`[1,]`,
+		`{"a":1]`,
+		`[tru]`,
+		`{1:2}`,
+		`"open`,
+		`[1,`,
+	}
+	for _, input := range invalid {
+		tok := NewJsonTokenizer(&testReader{data: input, chunk: 2, eof: errTestEOF})
+		var err error
+		for err == nil {
+			_, err = tok.Next()
+		}
+		if err == errTestEOF || !Contains(err.Error(), string(ErrSyntax)) {
+			t.Errorf("%s: got %v, expected a syntax error", input, err)
+		}
+	}
+
+	// Depth is tracked as containers open and close
+	tok := NewJsonTokenizer(&testReader{data: `[{"a":[`, chunk: 64, eof: errTestEOF})
+	for range 4 {
+		tok.Next()
+	}
+	if tok.Depth() != 3 {
+		t.Errorf("Depth() = %d, expected 3", tok.Depth())
+	}
+}
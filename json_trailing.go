@@ -0,0 +1,90 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Trailing data
+// A decode consumes exactly one value: `{"a":1} garbage` fails with
+// ErrTrailingData at the first byte after the value, whatever the target,
+// custom unmarshalers included. Input holding several concatenated documents
+// is read one value at a time instead:
+//
+//	for len(data) > 0 {
+//		var event Event
+//		if data, err = UnmarshalNext(data, &event); err != nil {
+//			break
+//		}
+//	}
+//
+// AllowTrailingData keeps only the first value and ignores what follows it
+// unread; JsonDecoder does the same over a reader.
+
+// AllowTrailingData makes a decode stop after the first value without
+// checking the rest of the input
+//
+//	err := Convert(`{"a":1} {"a":2}`).JsonDecode(&v, AllowTrailingData) // a == 1
+var AllowTrailingData DecodeOption = allowTrailingOption{}
+
+// allowTrailingOption is the DecodeOption behind AllowTrailingData
+type allowTrailingOption struct{}
+
+// applyDecode lets the operation ignore data after the first value
+func (allowTrailingOption) applyDecode(jh *jsonH) {
+	jh.jTrailing = true
+}
+
+// UnmarshalNext decodes the first JSON value of data into v and returns the
+// input after it, whitespace trimmed, empty once every value was read
+// RelaxedSyntax and LegacySyntax apply to the framing too; with LegacySyntax
+// the rest comes back already rewritten as standard JSON
+func UnmarshalNext(data []byte, v any, opts ...DecodeOption) ([]byte, error) {
+	data, err := stripJsonBOMBytes(data)
+	if err != nil {
+		return data, err
+	}
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	s := jh.rewriteJsonSyntax(string(data))
+	if isJsonBlank(s) {
+		return nil, Err(ErrEmptyInput)
+	}
+	jh.jInput = s // errors point into the whole of data
+
+	end, err := jh.skipJsonValueAt(s, 0)
+	if err != nil {
+		return data, jh.positionError(err)
+	}
+	if err := jh.decode(s[:end], v); err != nil {
+		return data, err
+	}
+	next := skipJsonSpace(s, end)
+	if len(s) != len(data) {
+		// Legacy quoting moved the offsets, data no longer lines up with s
+		return []byte(s[next:]), nil
+	}
+	return data[next:], nil
+}
+
+// trailingDataError reports the non-whitespace data at s[end:], nil when
+// there is none or the operation allows it
+func (jh *jsonH) trailingDataError(s string, end int) error {
+	if jh.jTrailing {
+		return nil
+	}
+	if end = skipJsonSpace(s, end); end < len(s) {
+		return jh.errorAt(s, end, Err(errInvalidJSON, ErrTrailingData))
+	}
+	return nil
+}
+
+// firstJsonValue frames the value at the start of s, checking what follows it
+func (jh *jsonH) firstJsonValue(s string) (string, error) {
+	start := skipJsonSpace(s, 0)
+	end, err := jh.skipJsonValueAt(s, start)
+	if err != nil {
+		return "", err
+	}
+	return s[start:end], jh.trailingDataError(s, end)
+}
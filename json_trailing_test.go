@@ -0,0 +1,104 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestTrailingDataRejected(t *testing.T) {
+	var v struct{ A int }
+	err := Convert(`{"A":1} garbage`).JsonDecode(&v)
+	if err == nil || !Contains(err.Error(), string(ErrTrailingData)) || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("JsonDecode with trailing data = %v, expected ErrTrailingData", err)
+	}
+	if err := Convert("{\"A\":1}  \n\t").JsonDecode(&v); err != nil {
+		t.Errorf("trailing whitespace should be accepted, got %v", err)
+	}
+
+	// Custom unmarshalers only see the value itself
+	var cents testCents
+	if err := Convert(`"3.05" "4.00"`).JsonDecode(&cents); err == nil || !Contains(err.Error(), string(ErrTrailingData)) {
+		t.Errorf("unmarshaler target with trailing data = %v, expected ErrTrailingData", err)
+	}
+
+	if Convert(`[1] [2]`).JsonValid() {
+		t.Error("JsonValid should reject concatenated documents")
+	}
+}
+
+func TestAllowTrailingData(t *testing.T) {
+	var v struct{ A int }
+	if err := Convert(`{"A":1} {"A":2}`).JsonDecode(&v, AllowTrailingData); err != nil || v.A != 1 {
+		t.Errorf("AllowTrailingData = %+v, %v; expected A 1", v, err)
+	}
+	if err := Convert(`[1] garbage`).JsonCheck(AllowTrailingData); err != nil {
+		t.Errorf("JsonCheck(AllowTrailingData) = %v", err)
+	}
+}
+
+func TestUnmarshalNext(t *testing.T) {
+	data := []byte(" {\"A\":1}\n{\"A\":2} 3 ")
+	var got []int
+	for len(data) > 0 {
+		var v any
+		var err error
+		if data, err = UnmarshalNext(data, &v); err != nil {
+			t.Fatalf("UnmarshalNext returned error: %v", err)
+		}
+		switch x := v.(type) {
+		case map[string]any:
+			got = append(got, int(x["A"].(float64)))
+		case float64:
+			got = append(got, int(x))
+		}
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("values = %v, expected [1 2 3]", got)
+	}
+
+	rest, err := UnmarshalNext([]byte("{\"A\":1}\n{\"A\":"), new(any))
+	if err != nil || string(rest) != `{"A":` {
+		t.Fatalf("first value: rest %q, err %v", rest, err)
+	}
+	if _, err := UnmarshalNext(rest, new(any)); err == nil || !Contains(err.Error(), string(ErrSyntax)) {
+		t.Errorf("truncated value = %v, expected syntax error", err)
+	}
+	if _, err := UnmarshalNext([]byte("  "), new(any)); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("blank input = %v, expected ErrEmptyInput", err)
+	}
+}
+
+func TestUnmarshalNextRelaxedSyntax(t *testing.T) {
+	// Comments between and inside documents are framed like whitespace
+	data := []byte("// first\n{\"A\": 1, /* x */}\n// second\n[2,] // done\n")
+	var first map[string]any
+	rest, err := UnmarshalNext(data, &first, RelaxedSyntax)
+	if err != nil || first["A"] != float64(1) {
+		t.Fatalf("first value = %v, %v", first, err)
+	}
+	var second []int
+	if rest, err = UnmarshalNext(rest, &second, RelaxedSyntax); err != nil || len(second) != 1 || second[0] != 2 {
+		t.Fatalf("second value = %v, %v", second, err)
+	}
+	if len(rest) != 0 {
+		t.Errorf("rest = %q, expected the trailing comment to be consumed", rest)
+	}
+	if _, err := UnmarshalNext(data, new(any)); err == nil {
+		t.Error("comments should still fail without RelaxedSyntax")
+	}
+
+	// Single quotes and bare keys are framed as the strings they stand for
+	data = []byte(`{name: 'a}b'} {name: 'c'}`)
+	var names []string
+	for len(data) > 0 {
+		var v struct{ Name string }
+		if data, err = UnmarshalNext(data, &v, LegacySyntax); err != nil {
+			t.Fatalf("UnmarshalNext(LegacySyntax) returned error: %v", err)
+		}
+		names = append(names, v.Name)
+	}
+	if len(names) != 2 || names[0] != "a}b" || names[1] != "c" {
+		t.Errorf("names = %q, expected [a}b c]", names)
+	}
+}
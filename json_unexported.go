@@ -0,0 +1,112 @@
+package tinywodp
+
+import (
+	"sync"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Unexported fields
+// Fields whose name starts with a lower-case letter are skipped by every codec,
+// encoding and decoding, as with encoding/json; the custom reflection could
+// otherwise write into them. Types that do need a private field filled from
+// JSON register a setter, written in their own package:
+//
+//	tinywodp.RegisterFieldSetter(&User{}, "password", func(target any, raw string) error {
+//		return Convert(raw).JsonDecode(&target.(*User).password)
+//	})
+//
+// The field is then matched like an exported one (name, snake_case, camelCase)
+// when decoding JSON, JsDecode included, and the setter receives a pointer to
+// the struct being decoded together with the member's JSON text. Other formats
+// and all encoders keep skipping it.
+
+// FieldSetter stores raw, the JSON text of a member, into an unexported field
+// of the struct target points to
+type FieldSetter func(target any, raw string) error
+
+// fieldSetterKey identifies an unexported field of a struct type
+type fieldSetterKey struct {
+	t    *refType
+	name string
+}
+
+// fieldSetter is a registered setter with the pointer type it is called with
+type fieldSetter struct {
+	ptrType *refType
+	set     FieldSetter
+}
+
+// fieldSetters holds the registered setters, read when a plan is compiled
+var fieldSetters = struct {
+	sync.RWMutex
+	m map[fieldSetterKey]fieldSetter
+}{m: map[fieldSetterKey]fieldSetter{}}
+
+// RegisterFieldSetter makes JSON decoding fill the unexported field name of the
+// struct sample points to through set; a nil set removes the setter
+// Register before the type is first decoded or encoded
+func RegisterFieldSetter(sample any, name string, set FieldSetter) error {
+	v := refValueOf(sample)
+	if v.refKind() != tpPointer || v.Type().Elem() == nil || v.Type().Elem().Kind() != tpStruct {
+		return Err(errUnsupportedType, "field setter sample must be a pointer to a struct")
+	}
+	if isExportedField(name) {
+		return Err(errUnsupportedType, "field setter for exported field "+name)
+	}
+
+	key := fieldSetterKey{t: v.Type().Elem(), name: name}
+	fieldSetters.Lock()
+	if set == nil {
+		delete(fieldSetters.m, key)
+	} else {
+		fieldSetters.m[key] = fieldSetter{ptrType: v.Type(), set: set}
+	}
+	fieldSetters.Unlock()
+
+	// A plan compiled earlier would not see the change
+	decodePlans.Delete(key.t)
+	return nil
+}
+
+// fieldSetterFor returns the setter registered for field name of struct type t
+func fieldSetterFor(t *refType, name string) (fieldSetter, bool) {
+	fieldSetters.RLock()
+	setter, ok := fieldSetters.m[fieldSetterKey{t: t, name: name}]
+	fieldSetters.RUnlock()
+	return setter, ok
+}
+
+// isExportedField reports whether a Go field name is exported: it starts with
+// an upper-case letter
+func isExportedField(name string) bool {
+	if name == "" {
+		return false
+	}
+	if name[0] < 0x80 {
+		return isUpperASCII(name[0])
+	}
+	// Non-ASCII initials are upper case when lower-casing changes them
+	first := name
+	for i := range name {
+		if i > 0 {
+			first = name[:i]
+			break
+		}
+	}
+	return Convert(first).ToLower().String() != first
+}
+
+// setUnexportedField decodes the member at s[i] into the unexported field of
+// target described by field, through its registered setter
+func (jh *jsonH) setUnexportedField(field *planField, s string, i int, target *refValue) (int, error) {
+	end, err := jh.skipJsonValueAt(s, i)
+	if err != nil {
+		return end, err
+	}
+	if err := field.setter.set(refTypedAny(field.setter.ptrType, target.ptr), s[i:end]); err != nil {
+		jh.prefixErrorPath(field.name)
+		return i, jh.errorAt(s, i, err)
+	}
+	return end, nil
+}
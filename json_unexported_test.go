@@ -0,0 +1,102 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+type hiddenUser struct {
+	Name     string
+	password string
+	visits   int
+	Éclair   bool
+	ñandu    string
+}
+
+type hiddenVault struct {
+	Label  string
+	secret string
+}
+
+func TestIsExportedField(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Name": true, "ID": true, "Éclair": true,
+		"name": false, "_x": false, "ñandu": false, "": false,
+	} {
+		if got := isExportedField(name); got != want {
+			t.Errorf("isExportedField(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestUnexportedFieldsSkipped(t *testing.T) {
+	clearRefStructsCache()
+
+	user := hiddenUser{Name: "ana", password: "pw", visits: 3, Éclair: true, ñandu: "x"}
+	out, err := Convert(&user).JsonEncode()
+	if err != nil || string(out) != `{"Name":"ana","Éclair":true}` {
+		t.Errorf("JsonEncode: got %s, %v", out, err)
+	}
+
+	decoded := hiddenUser{password: "kept"}
+	if err := Convert(`{"Name":"bo","password":"leak","Password":"leak","visits":9,"ñandu":"y"}`).JsonDecode(&decoded); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if decoded.Name != "bo" || decoded.password != "kept" || decoded.visits != 0 || decoded.ñandu != "" {
+		t.Errorf("got %+v", decoded)
+	}
+
+	// Binary stays positional over exported fields only
+	bin, err := Convert(&user).BinEncode()
+	if err != nil {
+		t.Fatalf("BinEncode failed: %v", err)
+	}
+	var back hiddenUser
+	if err := Convert(bin).BinDecode(&back); err != nil || back.Name != "ana" || !back.Éclair || back.password != "" {
+		t.Errorf("binary round trip: got %+v, %v", back, err)
+	}
+}
+
+func TestFieldSetter(t *testing.T) {
+	clearRefStructsCache()
+
+	err := RegisterFieldSetter(&hiddenVault{}, "secret", func(target any, raw string) error {
+		var s string
+		if err := Convert(raw).JsonDecode(&s); err != nil {
+			return err
+		}
+		target.(*hiddenVault).secret = "set:" + s
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterFieldSetter failed: %v", err)
+	}
+	defer RegisterFieldSetter(&hiddenVault{}, "secret", nil)
+
+	var vaults []hiddenVault
+	if err := Convert(`[{"Label":"a","secret":"s1"},{"Secret":"s2"}]`).JsonDecode(&vaults); err != nil {
+		t.Fatalf("JsonDecode failed: %v", err)
+	}
+	if len(vaults) != 2 || vaults[0].secret != "set:s1" || vaults[1].secret != "set:s2" || vaults[0].Label != "a" {
+		t.Errorf("got %+v", vaults)
+	}
+
+	// Still never encoded
+	if out, _ := Convert(&vaults[0]).JsonEncode(); string(out) != `{"Label":"a"}` {
+		t.Errorf("JsonEncode: got %s", out)
+	}
+
+	// Setter errors carry the field path
+	err = Convert(`{"secret":1}`).JsonDecode(&vaults[0])
+	if err == nil || !Contains(err.Error(), "secret") {
+		t.Errorf("expected a setter error at secret, got %v", err)
+	}
+
+	if err := RegisterFieldSetter(hiddenVault{}, "secret", nil); err == nil {
+		t.Error("expected an error for a non-pointer sample")
+	}
+	if err := RegisterFieldSetter(&hiddenVault{}, "Label", nil); err == nil {
+		t.Error("expected an error for an exported field")
+	}
+}
@@ -0,0 +1,156 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Unicode helpers for JSON escaping
+// Implemented without unicode/utf8 to keep binary size minimal
+
+const (
+	runeError = '\uFFFD' // replacement for invalid input, as encoding/json does
+	maxRune   = '\U0010FFFF'
+
+	surrogateMin  = 0xD800
+	surrogateHigh = 0xDBFF // last high (leading) surrogate
+	surrogateMax  = 0xDFFF
+)
+
+// decodeJsonUnicodeEscape decodes a \uXXXX escape at the start of s, combining a
+// following \uXXXX low surrogate when s starts with a high surrogate.
+// Returns the rune and the number of bytes consumed (6 or 12). Unpaired
+// surrogates decode to U+FFFD.
+func decodeJsonUnicodeEscape(s string) (rune, int, error) {
+	r, ok := parseHex4(s)
+	if !ok {
+		return 0, 0, Err(errInvalidJSON, "invalid unicode escape: "+s[:min(len(s), 6)])
+	}
+
+	if r < surrogateMin || r > surrogateMax {
+		return r, 6, nil
+	}
+
+	// Low surrogate without a preceding high surrogate
+	if r > surrogateHigh {
+		return runeError, 6, nil
+	}
+
+	// High surrogate must be followed by a \u low surrogate
+	if len(s) >= 12 && s[6] == '\\' && s[7] == 'u' {
+		if low, ok := parseHex4(s[6:]); ok && low > surrogateHigh && low <= surrogateMax {
+			return 0x10000 + (r-surrogateMin)<<10 + (low - (surrogateHigh + 1)), 12, nil
+		}
+	}
+	return runeError, 6, nil
+}
+
+// parseHex4 parses the four hex digits of a \uXXXX escape at the start of s
+func parseHex4(s string) (rune, bool) {
+	if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+		return 0, false
+	}
+	var r rune
+	for i := 2; i < 6; i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// runeLenUTF8 returns the number of bytes needed to encode r in UTF-8
+// Invalid runes count as U+FFFD (3 bytes)
+func runeLenUTF8(r rune) int {
+	switch {
+	case r < 0:
+		return 3
+	case r < 0x80:
+		return 1
+	case r < 0x800:
+		return 2
+	case r >= surrogateMin && r <= surrogateMax:
+		return 3
+	case r < 0x10000:
+		return 3
+	case r <= maxRune:
+		return 4
+	}
+	return 3
+}
+
+// putRuneUTF8 writes the UTF-8 encoding of r into buf and returns the bytes written
+// buf must have room for runeLenUTF8(r) bytes
+func putRuneUTF8(buf []byte, r rune) int {
+	if r < 0 || r > maxRune || (r >= surrogateMin && r <= surrogateMax) {
+		r = runeError
+	}
+	switch {
+	case r < 0x80:
+		buf[0] = byte(r)
+		return 1
+	case r < 0x800:
+		buf[0] = 0xC0 | byte(r>>6)
+		buf[1] = 0x80 | byte(r)&0x3F
+		return 2
+	case r < 0x10000:
+		buf[0] = 0xE0 | byte(r>>12)
+		buf[1] = 0x80 | byte(r>>6)&0x3F
+		buf[2] = 0x80 | byte(r)&0x3F
+		return 3
+	default:
+		buf[0] = 0xF0 | byte(r>>18)
+		buf[1] = 0x80 | byte(r>>12)&0x3F
+		buf[2] = 0x80 | byte(r>>6)&0x3F
+		buf[3] = 0x80 | byte(r)&0x3F
+		return 4
+	}
+}
+
+// appendRuneUTF8 appends the UTF-8 encoding of r to dst
+func appendRuneUTF8(dst []byte, r rune) []byte {
+	var buf [4]byte
+	n := putRuneUTF8(buf[:], r)
+	return append(dst, buf[:n]...)
+}
+
+// escapeNonASCII makes encoded strings escape every non-ASCII rune as \uXXXX
+var escapeNonASCII bool
+
+// SetEscapeNonASCII makes encoded strings pure ASCII: runes above U+007F are
+// written as \uXXXX escapes, as UTF-16 surrogate pairs beyond the BMP
+// The default writes them as raw UTF-8. Not safe to call concurrently with encoding
+func SetEscapeNonASCII(escape bool) {
+	escapeNonASCII = escape
+}
+
+// appendJsonRune appends a rune that needs no JSON escape of its own, either as
+// UTF-8 or, when ascii is set, as \uXXXX escapes
+func appendJsonRune(dst []byte, r rune, ascii bool) []byte {
+	if r < 0x80 || !ascii {
+		return appendRuneUTF8(dst, r)
+	}
+	if r > maxRune || (r >= surrogateMin && r <= surrogateMax) {
+		r = runeError
+	}
+	if r < 0x10000 {
+		return appendHex4Escape(dst, r)
+	}
+	r -= 0x10000
+	dst = appendHex4Escape(dst, surrogateMin+r>>10)
+	return appendHex4Escape(dst, surrogateHigh+1+r&0x3FF)
+}
+
+// appendHex4Escape appends the \uXXXX escape of the 16-bit code unit r
+func appendHex4Escape(dst []byte, r rune) []byte {
+	const hex = "0123456789abcdef"
+	return append(dst, '\\', 'u', hex[r>>12&0xF], hex[r>>8&0xF], hex[r>>4&0xF], hex[r&0xF])
+}
@@ -0,0 +1,131 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestUnescapeJsonUnicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"basic escapes", `a\/b\bc\fd`, "a/b\bc\fd"},
+		{"bmp escape", `caf\u00e9`, "caf\u00e9"},
+		{"uppercase hex", `\u00C9`, "\u00c9"},
+		{"cjk", `\u65e5\u672c`, "日本"},
+		{"surrogate pair", `\ud83d\ude00`, "😀"},
+		{"lone high surrogate", `\ud83dx`, "\uFFFDx"},
+		{"lone low surrogate", `\ude00`, "\uFFFD"},
+		{"raw utf8 untouched", "José 😀", "José 😀"},
+	}
+
+	jh := getJsonH("_")
+	defer putJsonH(jh)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jh.unescapeJsonString(tt.input)
+			if err != nil {
+				t.Fatalf("unescapeJsonString(%q) returned error: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("unescapeJsonString(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	for _, invalid := range []string{`\x41`, `\u12`, `\u12G4`, `abc\`} {
+		if _, err := jh.unescapeJsonString(invalid); err == nil {
+			t.Errorf("unescapeJsonString(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestJsonUnicodeRoundTrip(t *testing.T) {
+	type Message struct {
+		Text string
+	}
+
+	for _, text := range []string{"José Müller", "日本語テキスト", "emoji 😀 👍🏽", "ctrl \u001f"} {
+		encoded, err := Convert(Message{Text: text}).JsonEncode()
+		if err != nil {
+			t.Fatalf("JsonEncode returned error: %v", err)
+		}
+		if Contains(string(encoded), "?") {
+			t.Errorf("JsonEncode(%q) replaced runes: %s", text, string(encoded))
+		}
+
+		var decoded Message
+		if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+			t.Fatalf("JsonDecode(%s) returned error: %v", string(encoded), err)
+		}
+		if decoded.Text != text {
+			t.Errorf("round trip = %q, expected %q", decoded.Text, text)
+		}
+	}
+}
+
+func TestPutRuneUTF8(t *testing.T) {
+	for _, r := range []rune{'a', 'é', '日', '😀'} {
+		result := appendRuneUTF8(nil, r)
+		if string(result) != string(r) {
+			t.Errorf("appendRuneUTF8(%q) = %q", r, string(result))
+		}
+		if runeLenUTF8(r) != len(result) {
+			t.Errorf("runeLenUTF8(%q) = %d, expected %d", r, runeLenUTF8(r), len(result))
+		}
+	}
+	if string(appendRuneUTF8(nil, 0xD800)) != "\uFFFD" {
+		t.Error("surrogate code points should encode as U+FFFD")
+	}
+}
+
+func TestJsonEncodeMultiByteRunes(t *testing.T) {
+	type Name struct {
+		Full string
+	}
+
+	tests := []struct {
+		input   string
+		raw     string
+		escaped string
+	}{
+		{"Zoë Ñúñez", `"Zoë Ñúñez"`, `"Zo\u00eb \u00d1\u00fa\u00f1ez"`},
+		{"東京", `"東京"`, `"\u6771\u4eac"`},
+		{"hi 😀", `"hi 😀"`, `"hi \ud83d\ude00"`},
+		{"bad \xff byte", `"bad ` + "�" + ` byte"`, `"bad \ufffd byte"`},
+	}
+
+	defer SetEscapeNonASCII(false)
+	for _, tt := range tests {
+		// Raw UTF-8 by default, through both quoting paths
+		SetEscapeNonASCII(false)
+		if got := string(appendQuoteJsonString(nil, tt.input)); got != tt.raw {
+			t.Errorf("appendQuoteJsonString(%q) = %s, expected %s", tt.input, got, tt.raw)
+		}
+		if got := string(escapeAndQuoteJsonString(nil, tt.input, false)); got != tt.raw {
+			t.Errorf("escapeAndQuoteJsonString(%q) = %s, expected %s", tt.input, got, tt.raw)
+		}
+
+		SetEscapeNonASCII(true)
+		encoded, err := Convert(Name{Full: tt.input}).JsonEncode()
+		if err != nil {
+			t.Fatalf("JsonEncode returned error: %v", err)
+		}
+		if expected := `{"Full":` + tt.escaped + `}`; string(encoded) != expected {
+			t.Errorf("escaped JsonEncode(%q) = %s, expected %s", tt.input, encoded, expected)
+		}
+
+		// Escaped output decodes back to the same text
+		var decoded Name
+		if err := Convert(string(encoded)).JsonDecode(&decoded); err != nil {
+			t.Fatalf("JsonDecode(%s) returned error: %v", encoded, err)
+		}
+		if tt.input != "bad \xff byte" && decoded.Full != tt.input {
+			t.Errorf("round trip = %q, expected %q", decoded.Full, tt.input)
+		}
+	}
+}
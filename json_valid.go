@@ -0,0 +1,156 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Syntax validation
+// JsonValid answers whether a payload is well-formed JSON without building any
+// value, a cheap gate before storing or forwarding raw text:
+//
+//	if !Convert(body).JsonValid() {
+//		return errBadRequest
+//	}
+//	err := Convert(body).JsonCheck() // the reason, with line and column
+//
+// Input is checked as strictly as decoding would: string escapes and control
+// characters, number grammar, nesting up to the maximum depth and nothing but
+// whitespace after the value. Strings are never unescaped, so nothing is
+// allocated for them.
+
+// JsonValid reports whether the current value is exactly one well-formed JSON value
+func (c *refValue) JsonValid() bool {
+	return c.JsonCheck() == nil
+}
+
+// JsonCheck returns nil when the current value is exactly one well-formed JSON
+// value, otherwise the syntax error with its line, column and snippet
+// DecodeLimits, RelaxedSyntax and LegacySyntax among opts apply too, so a payload can be
+// vetted once and decoded later
+func (c *refValue) JsonCheck(opts ...DecodeOption) error {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+	s, err := stripJsonBOM(c.getString())
+	if err != nil {
+		return err
+	}
+	return jh.positionError(jh.checkJsonLimits(jh.rewriteJsonSyntax(s)))
+}
+
+// checkJson validates s as a whole document
+func (jh *jsonH) checkJson(s string) error {
+	if isJsonBlank(s) {
+		return Err(ErrEmptyInput)
+	}
+	if jh.jInput == "" {
+		jh.jInput = s
+	}
+	end, err := jh.checkJsonValue(s, 0)
+	if err != nil {
+		return jh.errorAt(s, end, err)
+	}
+	return jh.trailingDataError(s, end)
+}
+
+// checkJsonValue returns the index just past the valid value at or after s[i],
+// or the index of the first byte that could not be accepted
+func (jh *jsonH) checkJsonValue(s string, i int) (int, error) {
+	i = skipJsonSpace(s, i)
+	if i >= len(s) {
+		return i, Err(errInvalidJSON, "unexpected end of input")
+	}
+	switch s[i] {
+	case '"':
+		return jh.checkJsonStringAt(s, i)
+	case '{', '[':
+		if err := jh.enterJsonDepth(); err != nil {
+			return i, err
+		}
+		defer jh.leaveJsonDepth()
+		return jh.checkJsonContainer(s, i)
+	}
+	return skipJsonLiteral(s, i)
+}
+
+// checkJsonContainer validates the object or array opening at s[i]
+func (jh *jsonH) checkJsonContainer(s string, i int) (int, error) {
+	close, limit, what := byte(']'), jh.jLimits.MaxArrayLen, "array elements"
+	if s[i] == '{' {
+		close, limit, what = '}', jh.jLimits.MaxObjectKeys, "object keys"
+	}
+	if i = skipJsonSpace(s, i+1); i < len(s) && s[i] == close {
+		return i + 1, nil
+	}
+	for n := 1; ; n++ {
+		err := checkJsonCount(n, limit, what)
+		if err != nil {
+			return i, err
+		}
+		if close == '}' {
+			if i >= len(s) || s[i] != '"' {
+				return i, Err(errInvalidJSON, "expected object key")
+			}
+			if i, err = jh.checkJsonStringAt(s, i); err != nil {
+				return i, err
+			}
+			if i = skipJsonSpace(s, i); i >= len(s) || s[i] != ':' {
+				return i, Err(errInvalidJSON, "expected ':' after object key")
+			}
+			i++
+		}
+		if i, err = jh.checkJsonValue(s, i); err != nil {
+			return i, err
+		}
+		i = skipJsonSpace(s, i)
+		switch {
+		case i >= len(s):
+			return i, Err(errInvalidJSON, "unexpected end of input, expected ',' or '"+string(close)+"'")
+		case s[i] == close:
+			return i + 1, nil
+		case s[i] != ',':
+			return i, Err(errInvalidJSON, "expected ',' or '"+string(close)+"' but got: "+string(s[i]))
+		}
+		i = skipJsonSpace(s, i+1)
+	}
+}
+
+// checkJsonStringAt is checkJsonString under the operation's string limit
+func (jh *jsonH) checkJsonStringAt(s string, i int) (int, error) {
+	end, err := checkJsonString(s, i)
+	if err == nil && jh.jLimits.MaxStringLen > 0 && end-i-2 > jh.jLimits.MaxStringLen {
+		return i, Err(ErrLimitExceeded, "string longer than", jh.jLimits.MaxStringLen, "bytes")
+	}
+	return end, err
+}
+
+// checkJsonString returns the index just past the quoted string at s[i],
+// validating its escapes and bytes like unescapeJsonString
+func checkJsonString(s string, i int) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		switch b := s[j]; {
+		case b == '"':
+			return j + 1, nil
+		case b == '\\':
+			if j+1 >= len(s) {
+				return j, Err(errInvalidJSON, "unterminated escape sequence")
+			}
+			switch s[j+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				j++
+			case 'u':
+				if _, ok := parseHex4(s[j:]); !ok {
+					return j, Err(errInvalidJSON, "invalid unicode escape: "+s[j:min(len(s), j+6)])
+				}
+				j += 5
+			default:
+				return j, Err(errInvalidJSON, "invalid escape sequence: \\"+string(s[j+1]))
+			}
+		default:
+			if err := checkJsonStringByte(b); err != nil {
+				return j, err
+			}
+		}
+	}
+	return i, Err(errInvalidJSON, "unterminated string")
+}
@@ -0,0 +1,52 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonValid(t *testing.T) {
+	valid := []string{
+		`{}`, `[]`, `null`, ` true `, `-1.5e3`, `"aé\n\/"`,
+		`{"a":[1,{"b":null}],"c\"d":"e"}`,
+		"[\n  1,\n  2\n]\n",
+	}
+	for _, s := range valid {
+		if !Convert(s).JsonValid() {
+			t.Errorf("JsonValid(%q) = false, err %v", s, Convert(s).JsonCheck())
+		}
+	}
+	if !Convert([]byte(`{"k":1}`)).JsonValid() {
+		t.Error("JsonValid should accept a byte slice")
+	}
+
+	invalid := []string{
+		``, `  `, `{`, `[1,]`, `{"a":1,}`, `{"a" 1}`, `{a:1}`, `[1 2]`, `01`, `1.`,
+		`"unterminated`, `"bad \x escape"`, `"\u12G4"`, "\"tab\there\"", `tru`, `{} {}`, `nul`,
+	}
+	for _, s := range invalid {
+		if Convert(s).JsonValid() {
+			t.Errorf("JsonValid(%q) = true, expected false", s)
+		}
+	}
+}
+
+func TestJsonCheckPosition(t *testing.T) {
+	err := Convert("{\n  \"a\": [1, 2,],\n}").JsonCheck()
+	if err == nil || !Contains(err.Error(), string(ErrSyntax)) || !Contains(err.Error(), "line 2") {
+		t.Errorf("JsonCheck = %v, expected a syntax error on line 2", err)
+	}
+	if err := Convert("  ").JsonCheck(); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("JsonCheck(blank) = %v, expected ErrEmptyInput", err)
+	}
+
+	SetMaxDepth(3)
+	defer SetMaxDepth(0)
+	if err := Convert(`[[[1]]]`).JsonCheck(); err != nil {
+		t.Errorf("JsonCheck at the depth limit = %v", err)
+	}
+	if err := Convert(`[[[[1]]]]`).JsonCheck(); err == nil || !Contains(err.Error(), string(ErrMaxDepth)) {
+		t.Errorf("JsonCheck past the depth limit = %v, expected ErrMaxDepth", err)
+	}
+}
@@ -0,0 +1,492 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Validated decoding
+// JsonDecodeValidated decodes like JsonDecode, then checks the payload against
+// the rules in validate tags and returns every violation with its field path:
+//
+//	type Post struct {
+//		Title  string   `json:"title" validate:"required,min=1,max=120"`
+//		Status string   `json:"status" validate:"enum=draft|published"`
+//		Score  int      `json:"score" validate:"min=0,max=100"`
+//		Tags   []string `json:"tags" validate:"max=5,pattern=[a-z]*"`
+//	}
+//	err := Convert(payload).JsonDecodeValidated(nil, &post)
+//	if errs, ok := err.(DecodeErrors); ok {
+//		for _, e := range errs {
+//			log(e.Path, e.Err) // Tags[1]: validation failed does not match pattern [a-z]*
+//		}
+//	}
+//
+// Rules, separated by commas:
+//
+//	required     the key must be present and not null
+//	enum=a|b|c   the value's text (string, number literal, true/false) is one of the options
+//	min=n,max=n  bounds on numbers, on the rune count of strings and on the
+//	             length of slices and arrays
+//	pattern=p    the value's text matches the glob p: '*' any run, '?' one rune,
+//	             '[a-z]' or '[!0-9]' a class, '\' escapes the next rune; it takes
+//	             the rest of the tag, so it must come last
+//
+// On slice and array fields, enum and pattern apply to each element. Rules are
+// checked on nested structs reached through fields, pointers and slices, but
+// not inside map values. Values are checked as sent, so a secure field is only
+// checked for presence.
+
+// Schema supplies validation rules for struct types that cannot be tagged,
+// keyed by Go field path without indexes; a rule replaces the field's validate tag
+//
+//	schema := Schema{"Items.SKU": "required,pattern=[A-Z][A-Z]-*", "Total": "min=0"}
+type Schema map[string]string
+
+// validateRules holds the parsed rules of one field
+type validateRules struct {
+	required bool
+	enum     []string
+	pattern  string
+	min, max string // bound literals, kept for messages
+	minValue float64
+	maxValue float64
+}
+
+// JsonDecodeValidated decodes into target like JsonDecode and validates the
+// payload against its validate tags and schema, which may be nil
+// Decoding errors are returned as is; violations come back together as a
+// DecodeErrors whose entries wrap ErrValidation. With CollectErrors the field
+// errors of the decode come first in the same list.
+//
+// Usage pattern:
+//
+//	var post Post
+//	if err := Convert(body).JsonDecodeValidated(nil, &post); err != nil {
+//		return badRequest(err)
+//	}
+func (c *refValue) JsonDecodeValidated(schema Schema, target any, opts ...DecodeOption) error {
+	if target == nil {
+		return Err(errInvalidJSON, "target cannot be nil")
+	}
+
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	input := c.getString()
+	var errs DecodeErrors
+	if err := jh.decode(input, target); err != nil {
+		var ok bool
+		if errs, ok = err.(DecodeErrors); !ok {
+			return err
+		}
+	}
+
+	// The payload again as generic values, numbers kept verbatim: it tells
+	// which keys were sent and holds the values exactly as written
+	jh.jUseNum = true
+	var doc any
+	if err := jh.parseJsonValueWithRefReflect(input, refValueOf(&doc).refElem()); err != nil {
+		return jh.positionError(err)
+	}
+
+	jv := jsonValidator{jh: jh, schema: schema, errs: errs}
+	if err := jv.validateValue(refValueOf(target).refElem(), doc, "", ""); err != nil {
+		return err
+	}
+	if len(jv.errs) > 0 {
+		return jv.errs
+	}
+	return nil
+}
+
+// jsonValidator walks a decoded value together with its generic payload
+type jsonValidator struct {
+	jh     *jsonH
+	schema Schema
+	errs   DecodeErrors
+}
+
+// violation records a rule the value at path breaks
+func (jv *jsonValidator) violation(path, msg string) {
+	jv.errs = append(jv.errs, FieldError{Path: path, Offset: -1, Err: Err(ErrValidation, msg)})
+}
+
+// validateValue descends into the structs held by v, whose payload is doc;
+// path is the error path and rulePath the schema key prefix
+func (jv *jsonValidator) validateValue(v *refValue, doc any, path, rulePath string) error {
+	for v.refIsValid() && v.refKind() == tpPointer {
+		v = v.refElem()
+	}
+	if !v.refIsValid() || doc == nil || isTimeType(v) || isRawJSONType(v) ||
+		isBigNumberType(v) || customCodecFor(v) != 0 {
+		return nil
+	}
+
+	switch v.refKind() {
+	case tpStruct:
+		return jv.validateStruct(v, doc, path, rulePath)
+	case tpSlice, tpArray:
+		items, _ := doc.([]any)
+		for i := 0; i < len(items) && i < v.refLen(); i++ {
+			index := "[" + Convert(i).String() + "]"
+			if err := jv.validateValue(v.refIndex(i), items[i], path+index, rulePath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateStruct checks the rules of every field of the struct v, then
+// descends into the fields that were sent
+func (jv *jsonValidator) validateStruct(v *refValue, doc any, path, rulePath string) error {
+	plan, err := decodePlanFor(v)
+	if err != nil {
+		return err
+	}
+	var structInfo refStructType
+	if err := structMetadataFor(v, &structInfo); err != nil {
+		return err
+	}
+
+	// Payload value of each field, nil when the key was absent or null
+	values := make([]any, len(plan.fields))
+	obj, _ := doc.(map[string]any)
+	for key, value := range obj {
+		if index := plan.fieldIndex(key, jv.jh.jMatch); index != -1 {
+			values[index] = value
+		}
+	}
+
+	for i := range plan.fields {
+		field := v.refField(i)
+		if !field.refIsValid() || plan.fields[i].unexported && plan.fields[i].setter == nil {
+			continue
+		}
+		name := plan.fields[i].name
+		fieldPath, fieldRulePath := joinValidatePath(path, name), joinValidatePath(rulePath, name)
+
+		text, ok := jv.schema[fieldRulePath]
+		if !ok {
+			text = structInfo.fields[i].tag.Get("validate")
+		}
+		if text != "" {
+			rules, err := parseValidateRules(text)
+			if err != nil {
+				return Err(err.Error(), "on field", fieldPath)
+			}
+			switch {
+			case values[i] == nil:
+				if rules.required {
+					jv.violation(fieldPath, "is required")
+				}
+				continue
+			case !plan.fields[i].secure:
+				jv.checkRules(&rules, field.Type(), values[i], fieldPath)
+			}
+		}
+
+		if !plan.fields[i].secure {
+			if err := jv.validateValue(field, values[i], fieldPath, fieldRulePath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkRules checks the value rules of a field of type t against its payload
+func (jv *jsonValidator) checkRules(rules *validateRules, t *refType, value any, path string) {
+	for t != nil && t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+
+	if isFormList(t) {
+		items, _ := value.([]any)
+		jv.checkBounds(rules, float64(len(items)), path, "items")
+		for i, item := range items {
+			if item != nil {
+				jv.checkText(rules, item, path+"["+Convert(i).String()+"]")
+			}
+		}
+		return
+	}
+
+	jv.checkText(rules, value, path)
+	switch t.Kind() {
+	case tpString:
+		if s, ok := value.(string); ok {
+			n := 0
+			for range s {
+				n++
+			}
+			jv.checkBounds(rules, float64(n), path, "characters")
+		}
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64,
+		tpUint, tpUint8, tpUint16, tpUint32, tpUint64, tpFloat32, tpFloat64:
+		// Quoted fields (json:",string") send their numbers as strings
+		var literal string
+		switch n := value.(type) {
+		case Number:
+			literal = string(n)
+		case string:
+			literal = n
+		}
+		if isJsonNumber(literal) {
+			f, _ := parseJsonFloat(literal, 64)
+			jv.checkBounds(rules, f, path, "")
+		}
+	}
+}
+
+// checkText checks the enum and pattern rules against a scalar payload value
+func (jv *jsonValidator) checkText(rules *validateRules, value any, path string) {
+	if rules.enum == nil && rules.pattern == "" {
+		return
+	}
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case Number:
+		text = string(v)
+	case bool:
+		text = "false"
+		if v {
+			text = "true"
+		}
+	default:
+		return
+	}
+
+	if rules.enum != nil {
+		found := false
+		for _, option := range rules.enum {
+			if option == text {
+				found = true
+				break
+			}
+		}
+		if !found {
+			msg := "must be one of"
+			for i, option := range rules.enum {
+				if i > 0 {
+					msg += ","
+				}
+				msg += " " + option
+			}
+			jv.violation(path, msg)
+		}
+	}
+	if rules.pattern != "" && !matchPattern(rules.pattern, text) {
+		jv.violation(path, "does not match pattern "+rules.pattern)
+	}
+}
+
+// checkBounds checks the min and max rules against n, a number or a length
+// counted in unit
+func (jv *jsonValidator) checkBounds(rules *validateRules, n float64, path, unit string) {
+	if unit != "" {
+		unit = " " + unit
+	}
+	if rules.min != "" && n < rules.minValue {
+		jv.violation(path, "must be at least "+rules.min+unit)
+	}
+	if rules.max != "" && n > rules.maxValue {
+		jv.violation(path, "must be at most "+rules.max+unit)
+	}
+}
+
+// joinValidatePath appends a field name to a path
+func joinValidatePath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// parseValidateRules parses a validate tag or schema entry
+func parseValidateRules(text string) (validateRules, error) {
+	var rules validateRules
+	for text != "" {
+		rule := text
+		if len(text) >= len("pattern=") && text[:len("pattern=")] == "pattern=" {
+			text = "" // the pattern may hold commas
+		} else if comma := indexByte(text, ','); comma != -1 {
+			rule, text = text[:comma], text[comma+1:]
+		} else {
+			text = ""
+		}
+
+		name, arg := rule, ""
+		if eq := indexByte(rule, '='); eq != -1 {
+			name, arg = rule[:eq], rule[eq+1:]
+		}
+		switch name {
+		case "":
+			// empty rule, e.g. a trailing comma
+		case "required":
+			rules.required = true
+		case "enum":
+			for {
+				bar := indexByte(arg, '|')
+				if bar == -1 {
+					rules.enum = append(rules.enum, arg)
+					break
+				}
+				rules.enum = append(rules.enum, arg[:bar])
+				arg = arg[bar+1:]
+			}
+		case "pattern":
+			if err := checkPattern(arg); err != nil {
+				return rules, err
+			}
+			rules.pattern = arg
+		case "min", "max":
+			if !isJsonNumber(arg) {
+				return rules, Err(errUnsupportedValue, "invalid validate bound: "+rule)
+			}
+			f, _ := parseJsonFloat(arg, 64)
+			if name == "min" {
+				rules.min, rules.minValue = arg, f
+			} else {
+				rules.max, rules.maxValue = arg, f
+			}
+		default:
+			return rules, Err(errUnsupportedValue, "unknown validate rule: "+rule)
+		}
+	}
+	return rules, nil
+}
+
+// checkPattern reports a class without its closing ']' or a trailing '\'
+func checkPattern(p string) error {
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			if i++; i == len(p) {
+				return Err(errUnsupportedValue, "invalid validate pattern, trailing \\: "+p)
+			}
+		case '[':
+			if i = patternClassEnd(p, i); i == -1 {
+				return Err(errUnsupportedValue, "invalid validate pattern, unclosed [: "+p)
+			}
+		}
+	}
+	return nil
+}
+
+// matchPattern reports whether all of s matches the glob p, checked by checkPattern
+// Only the last '*' is ever backtracked to: any match found by revisiting an
+// earlier one is also found by letting the last one absorb more input.
+func matchPattern(p, s string) bool {
+	pi, si := 0, 0
+	starP, starS := -1, 0
+	for si < len(s) {
+		if pi < len(p) && p[pi] == '*' {
+			starP, starS = pi, si
+			pi++
+			continue
+		}
+		r, n := runeAt(s, si)
+		if pi < len(p) {
+			if ok, width := matchPatternItem(p, pi, r); ok {
+				pi, si = pi+width, si+n
+				continue
+			}
+		}
+		if starP == -1 {
+			return false
+		}
+		// Let the last '*' absorb one more rune and retry after it
+		_, n = runeAt(s, starS)
+		starS += n
+		pi, si = starP+1, starS
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// matchPatternItem matches r against the pattern item at p[i], other than '*',
+// and returns the item's length
+func matchPatternItem(p string, i int, r rune) (bool, int) {
+	switch p[i] {
+	case '?':
+		return true, 1
+	case '[':
+		end := patternClassEnd(p, i)
+		return matchPatternClass(p[i+1:end], r), end + 1 - i
+	}
+	c, n := patternRune(p, i)
+	return c == r, n
+}
+
+// patternClassEnd returns the index of the ']' closing the class at p[i], or -1
+// A ']' right after '[' or '[!' is a member of the class
+func patternClassEnd(p string, i int) int {
+	j := i + 1
+	if j < len(p) && (p[j] == '!' || p[j] == '^') {
+		j++
+	}
+	if j < len(p) && p[j] == ']' {
+		j++
+	}
+	for ; j < len(p); j++ {
+		switch p[j] {
+		case '\\':
+			j++
+		case ']':
+			return j
+		}
+	}
+	return -1
+}
+
+// matchPatternClass reports whether r belongs to the class body, e.g. "!a-z_"
+func matchPatternClass(class string, r rune) bool {
+	negate := len(class) > 0 && (class[0] == '!' || class[0] == '^')
+	if negate {
+		class = class[1:]
+	}
+	for i := 0; i < len(class); {
+		lo, n := patternRune(class, i)
+		i += n
+		hi := lo
+		if i+1 < len(class) && class[i] == '-' {
+			hi, n = patternRune(class, i+1)
+			i += 1 + n
+		}
+		if lo <= r && r <= hi {
+			return !negate
+		}
+	}
+	return negate
+}
+
+// patternRune returns the rune at p[i], unescaping '\', and the bytes it spans
+func patternRune(p string, i int) (rune, int) {
+	if p[i] == '\\' && i+1 < len(p) {
+		r, n := runeAt(p, i+1)
+		return r, n + 1
+	}
+	return runeAt(p, i)
+}
+
+// runeAt decodes the rune at s[i] and returns its length in bytes; invalid
+// UTF-8 gives runeError over one byte
+func runeAt(s string, i int) (rune, int) {
+	var first rune
+	for j, r := range s[i:] {
+		if j > 0 {
+			return first, j
+		}
+		first = r
+	}
+	return first, len(s) - i
+}
@@ -0,0 +1,136 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestJsonDecodeValidated(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		SKU string `json:"sku" validate:"required,pattern=[A-Z][A-Z]-*"`
+		Qty int    `json:"qty" validate:"min=1,max=10"`
+	}
+	type Order struct {
+		ID     string   `json:"id" validate:"required"`
+		Status string   `json:"status" validate:"enum=new|paid|shipped"`
+		Note   *string  `json:"note" validate:"max=5"`
+		Tags   []string `json:"tags" validate:"max=2,pattern=[a-z]*"`
+		Items  []Item   `json:"items" validate:"required,min=1"`
+		Total  float64  `json:"total"`
+	}
+
+	valid := `{"id":"o1","status":"paid","tags":["gift"],"items":[{"sku":"AB-1","qty":2}],"total":9.5}`
+	var order Order
+	if err := Convert(valid).JsonDecodeValidated(nil, &order); err != nil {
+		t.Fatalf("JsonDecodeValidated returned error: %v", err)
+	}
+	if order.ID != "o1" || len(order.Items) != 1 || order.Items[0].Qty != 2 {
+		t.Errorf("JsonDecodeValidated decoded %+v", order)
+	}
+
+	invalid := `{
+		"id": null,
+		"status": "lost",
+		"note": "señores",
+		"tags": ["ok", "Bad", "x"],
+		"items": [{"sku": "AB-1", "qty": 0}, {"qty": 11}, {"sku": "ab-2", "qty": 3}],
+		"total": -1
+	}`
+	var bad Order
+	err := Convert(invalid).JsonDecodeValidated(Schema{"Total": "min=0"}, &bad)
+	errs, ok := err.(DecodeErrors)
+	if !ok {
+		t.Fatalf("JsonDecodeValidated error = %v (%T), expected DecodeErrors", err, err)
+	}
+
+	expected := []struct{ path, msg string }{
+		{"ID", "is required"},
+		{"Status", "must be one of new, paid, shipped"},
+		{"Note", "must be at most 5 characters"},
+		{"Tags", "must be at most 2 items"},
+		{"Tags[1]", "does not match pattern [a-z]*"},
+		{"Items[0].Qty", "must be at least 1"},
+		{"Items[1].SKU", "is required"},
+		{"Items[1].Qty", "must be at most 10"},
+		{"Items[2].SKU", "does not match pattern [A-Z][A-Z]-*"},
+		{"Total", "must be at least 0"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("got %d violations, expected %d: %v", len(errs), len(expected), errs)
+	}
+	for i, e := range expected {
+		if errs[i].Path != e.path || !Contains(errs[i].Err.Error(), e.msg) ||
+			!Contains(errs[i].Err.Error(), string(ErrValidation)) {
+			t.Errorf("errs[%d] = %v, expected %s: %s", i, errs[i], e.path, e.msg)
+		}
+	}
+	// Violations do not stop decoding
+	if bad.Status != "lost" || len(bad.Items) != 3 || bad.Items[2].Qty != 3 {
+		t.Errorf("JsonDecodeValidated decoded %+v", bad)
+	}
+}
+
+func TestJsonDecodeValidatedErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	type Account struct {
+		Name string `validate:"required"`
+		Age  int    `validate:"min=0"`
+	}
+
+	// Decoding errors are returned as is
+	var a Account
+	if err := Convert(`{"Name":`).JsonDecodeValidated(nil, &a); err == nil || Contains(err.Error(), string(ErrValidation)) {
+		t.Errorf("malformed JSON error = %v", err)
+	}
+
+	// With CollectErrors decode failures come first, then violations
+	err := Convert(`{"Age":"old"}`).JsonDecodeValidated(nil, &a, CollectErrors)
+	errs, ok := err.(DecodeErrors)
+	if !ok || len(errs) != 2 || errs[0].Path != "Age" || errs[1].Path != "Name" {
+		t.Errorf("CollectErrors error = %v", err)
+	}
+
+	// A schema entry replaces the tag; broken rules are reported, not violated
+	if err := Convert(`{"Age":5}`).JsonDecodeValidated(Schema{"Name": ""}, &a); err != nil {
+		t.Errorf("schema override returned error: %v", err)
+	}
+	for _, rule := range []string{"min=ten", "pattern=[a-", "requird"} {
+		err := Convert(`{"Name":"x"}`).JsonDecodeValidated(Schema{"Name": rule}, &a)
+		if err == nil || !Contains(err.Error(), "Name") {
+			t.Errorf("rule %q error = %v", rule, err)
+		}
+		if _, ok := err.(DecodeErrors); ok {
+			t.Errorf("rule %q should not be reported as a violation", rule)
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*@*.*", "ana@mail.io", true},
+		{"*@*.*", "ana.io", false},
+		{"a?c", "aéc", true},
+		{"a?c", "ac", false},
+		{"[!0-9]*", "x1", true},
+		{"[!0-9]*", "1x", false},
+		{"[]a]", "]", true},
+		{`\*x`, "*x", true},
+		{`\*x`, "ax", false},
+		{"*a*b*c", "xxaxbxxc", true},
+		{"*a*b*c", "xxaxcxxb", false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, expected %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Ordered iteration over generic JSON values
+// Generic values are the map[string]any / []any / string / float64 / bool / nil
+// trees produced when decoding unknown payloads. Go map iteration order is random,
+// so these helpers always visit object keys in sorted order to keep output stable.
+//
+//	for _, key := range Keys(doc) { ... }
+//
+//	Walk(doc, func(path string, value any) bool {
+//		// path is a JSON Pointer: "", "/user", "/user/tags/0"
+//		return true // false stops the walk
+//	})
+
+// Keys returns the keys of a generic JSON object in sorted order, or nil for other values
+func Keys(v any) []string {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sortStrings(keys)
+	return keys
+}
+
+// Values returns the elements of a generic JSON array, or the values of an object
+// ordered by key; nil for scalars
+func Values(v any) []any {
+	switch t := v.(type) {
+	case []any:
+		values := make([]any, len(t))
+		copy(values, t)
+		return values
+	case map[string]any:
+		keys := Keys(t)
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			values[i] = t[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// Walk visits v and every nested value depth-first in stable order, passing the
+// JSON Pointer path of each node. Returning false from fn stops the walk.
+func Walk(v any, fn func(path string, value any) bool) {
+	if fn == nil {
+		return
+	}
+	walkJsonValue("", v, fn)
+}
+
+// walkJsonValue reports whether the walk should continue
+func walkJsonValue(path string, v any, fn func(path string, value any) bool) bool {
+	if !fn(path, v) {
+		return false
+	}
+	switch t := v.(type) {
+	case []any:
+		for i, elem := range t {
+			if !walkJsonValue(path+"/"+Convert(i).String(), elem, fn) {
+				return false
+			}
+		}
+	case map[string]any:
+		for _, key := range Keys(t) {
+			if !walkJsonValue(path+"/"+escapeJsonPointer(key), t[key], fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// escapeJsonPointer escapes a key as a JSON Pointer reference token (RFC 6901)
+func escapeJsonPointer(key string) string {
+	if indexByte(key, '~') == -1 && indexByte(key, '/') == -1 {
+		return key
+	}
+	out := make([]byte, 0, len(key)+2)
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '~':
+			out = append(out, '~', '0')
+		case '/':
+			out = append(out, '~', '1')
+		default:
+			out = append(out, key[i])
+		}
+	}
+	return string(out)
+}
+
+// sortStrings sorts keys in place; insertion sort keeps the binary free of package sort
+func sortStrings(keys []string) {
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
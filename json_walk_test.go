@@ -0,0 +1,71 @@
+package tinywodp
+
+import (
+	"testing"
+)
+
+func genericTestDocument() any {
+	return map[string]any{
+		"name": "widget",
+		"tags": []any{"a", "b"},
+		"meta": map[string]any{"a/b": true, "x~y": nil},
+		"id":   float64(7),
+	}
+}
+
+func TestKeysAndValuesOrdered(t *testing.T) {
+	doc := genericTestDocument()
+
+	keys := Keys(doc)
+	expected := []string{"id", "meta", "name", "tags"}
+	if len(keys) != len(expected) {
+		t.Fatalf("Keys = %v, expected %v", keys, expected)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Keys[%d] = %q, expected %q", i, keys[i], expected[i])
+		}
+	}
+
+	values := Values(doc)
+	if len(values) != 4 || values[0] != float64(7) || values[2] != "widget" {
+		t.Errorf("Values = %v, expected values ordered by key", values)
+	}
+	if arr := Values([]any{1, 2}); len(arr) != 2 {
+		t.Errorf("Values(array) = %v", arr)
+	}
+	if Keys("scalar") != nil || Values(true) != nil {
+		t.Error("scalars should have no keys or values")
+	}
+}
+
+func TestWalkPathsStable(t *testing.T) {
+	expected := []string{"", "/id", "/meta", "/meta/a~1b", "/meta/x~0y", "/name", "/tags", "/tags/0", "/tags/1"}
+
+	for run := 0; run < 5; run++ {
+		var paths []string
+		Walk(genericTestDocument(), func(path string, value any) bool {
+			paths = append(paths, path)
+			return true
+		})
+		if len(paths) != len(expected) {
+			t.Fatalf("Walk paths = %v, expected %v", paths, expected)
+		}
+		for i := range expected {
+			if paths[i] != expected[i] {
+				t.Fatalf("Walk path %d = %q, expected %q", i, paths[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestWalkStopsEarly(t *testing.T) {
+	visited := 0
+	Walk(genericTestDocument(), func(path string, value any) bool {
+		visited++
+		return path != "/meta"
+	})
+	if visited != 3 {
+		t.Errorf("Walk visited %d nodes, expected to stop at /meta (3)", visited)
+	}
+}
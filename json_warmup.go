@@ -0,0 +1,164 @@
+package tinywodp
+
+import (
+	"sync"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Startup warm-up
+// Types are registered once (by hand or from a generated inventory) and Warmup
+// runs at service start: it builds the struct metadata caches and round-trips
+// the zero value of each type, so schema problems such as unsupported field
+// kinds or stripped reflection metadata surface at boot instead of on the first
+// production request.
+//
+//	func init() {
+//		tinywodp.Register("User", User{})
+//		tinywodp.Register("Order", &Order{})
+//	}
+//
+//	if report := tinywodp.Warmup(); !report.Ready() {
+//		log.Fatal(report.Err())
+//	}
+
+// WarmupFailure describes a registered type that failed its self-test
+type WarmupFailure struct {
+	Name  string // name given to Register
+	Stage string // "type", "encode", "decode" or "roundtrip"
+	Err   error
+}
+
+// WarmupReport summarises a Warmup run
+type WarmupReport struct {
+	Checked  int // types self-tested by this run
+	Skipped  int // types already verified by an earlier run
+	Failures []WarmupFailure
+}
+
+// Ready reports whether every checked type passed
+func (r WarmupReport) Ready() bool {
+	return len(r.Failures) == 0
+}
+
+// Err returns nil when ready, otherwise one error listing every failure
+func (r WarmupReport) Err() error {
+	if r.Ready() {
+		return nil
+	}
+	msg := ""
+	for i, f := range r.Failures {
+		if i > 0 {
+			msg += "; "
+		}
+		msg += f.Name + " (" + f.Stage + "): " + f.Err.Error()
+	}
+	return Err(errWarmup, msg)
+}
+
+// warmupEntry is one registered type
+type warmupEntry struct {
+	name  string
+	typ   *refType
+	codec bool // the registered sample has a generated codec
+}
+
+// warmupRegistry holds registered types and the ones that already passed
+var warmupRegistry = struct {
+	sync.Mutex
+	entries []warmupEntry
+	passed  map[*refType]bool
+}{passed: map[*refType]bool{}}
+
+// Register adds the type of sample to the warm-up inventory under name
+// Only the type is used; pointers register their element type
+// Stripped struct metadata is reported here as ErrNoReflection and the type is
+// not registered, unless it has a generated codec
+func Register(name string, sample any) error {
+	if sample == nil {
+		return nil
+	}
+	if err := checkTypeMetadata(sample); err != nil {
+		return err
+	}
+	t := refValueOf(sample).Type()
+	if t.Kind() == tpPointer {
+		t = t.Elem()
+	}
+
+	warmupRegistry.Lock()
+	warmupRegistry.entries = append(warmupRegistry.entries, warmupEntry{name: name, typ: t, codec: isCodecSample(sample)})
+	warmupRegistry.Unlock()
+	return nil
+}
+
+// Warmup self-tests every registered type that has not passed yet
+// Calling it again after registering more types only checks the new ones
+func Warmup() WarmupReport {
+	warmupRegistry.Lock()
+	defer warmupRegistry.Unlock()
+
+	var report WarmupReport
+	for _, e := range warmupRegistry.entries {
+		if warmupRegistry.passed[e.typ] {
+			report.Skipped++
+			continue
+		}
+		report.Checked++
+		if stage, err := warmupType(e.typ, e.codec); err != nil {
+			report.Failures = append(report.Failures, WarmupFailure{Name: e.name, Stage: stage, Err: err})
+			continue
+		}
+		warmupRegistry.passed[e.typ] = true
+	}
+	return report
+}
+
+// warmupType encodes the zero value of t, decodes it into a fresh value and
+// checks that re-encoding gives the same bytes. Returns the failing stage.
+// Types with a generated codec skip the struct metadata check
+func warmupType(t *refType, codec bool) (string, error) {
+	zero, err := refNewValue(t)
+	if err != nil {
+		return "type", err
+	}
+	if zero.refKind() == tpStruct && !codec && !hasGeneratedCodec(zero) {
+		var structInfo refStructType
+		if err := structMetadataFor(zero, &structInfo); err != nil {
+			return "type", err
+		}
+	}
+
+	data, err := Marshal(zero.Interface())
+	if err != nil {
+		return "encode", err
+	}
+
+	decoded, err := refNewValue(t)
+	if err != nil {
+		return "type", err
+	}
+	jh := getJsonH("_")
+	err = jh.parseJsonValueWithRefReflect(string(data), decoded)
+	putJsonH(jh)
+	if err != nil {
+		return "decode", err
+	}
+
+	again, err := Marshal(decoded.Interface())
+	if err != nil {
+		return "roundtrip", err
+	}
+	if string(again) != string(data) {
+		return "roundtrip", Err(errWarmup, "re-encoded "+string(again)+" != "+string(data))
+	}
+	return "", nil
+}
+
+// resetWarmup clears the inventory; used by tests
+func resetWarmup() {
+	warmupRegistry.Lock()
+	warmupRegistry.entries = nil
+	warmupRegistry.passed = map[*refType]bool{}
+	warmupRegistry.Unlock()
+}
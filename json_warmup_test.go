@@ -0,0 +1,44 @@
+package tinywodp
+
+import (
+	"testing"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestWarmup(t *testing.T) {
+	resetWarmup()
+	defer resetWarmup()
+
+	type Hook struct {
+		Name   string
+		OnSave func()
+	}
+
+	Register("Address", Address{})
+	Register("Person", &Person{})
+
+	report := Warmup()
+	if !report.Ready() || report.Checked != 2 || report.Skipped != 0 {
+		t.Fatalf("first Warmup = %+v, err %v", report, report.Err())
+	}
+
+	// Only the new type is checked; the failure names the type and stage
+	Register("Hook", Hook{})
+	report = Warmup()
+	if report.Ready() || report.Checked != 1 || report.Skipped != 2 {
+		t.Fatalf("second Warmup = %+v", report)
+	}
+	f := report.Failures[0]
+	if f.Name != "Hook" || f.Stage != "encode" {
+		t.Errorf("failure = %+v, expected Hook at encode", f)
+	}
+	if err := report.Err(); err == nil || !Contains(err.Error(), "Hook (encode)") {
+		t.Errorf("Err() = %v", err)
+	}
+
+	// Failed types are retried on the next run
+	if report = Warmup(); report.Checked != 1 || report.Ready() {
+		t.Errorf("third Warmup = %+v", report)
+	}
+}
@@ -0,0 +1,749 @@
+package tinywodp
+
+import (
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// MessagePack decoding
+// Walks the binary input once, writing straight into the target through the
+// reflection core. Struct fields are found with the same decode plans as JSON,
+// so keys match json tags, Go names and the snake_case/camelCase forms, and the
+// decode options (FieldMatch, NullPolicy, MaxDepth via JsonOptions) apply.
+// Numbers convert between integer and float formats when the value fits the
+// target exactly. Decoding into any gives nil, bool, int64 (uint64 above the
+// int64 range), float64, string, []byte, time.Time, []any and map[string]any.
+
+// MsgpackDecode decodes the MessagePack data held by the current value into target
+//
+// Usage pattern:
+//
+//	err := Convert(data).MsgpackDecode(&user)
+func (c *refValue) MsgpackDecode(target any, opts ...DecodeOption) error {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	jh.applyDecodeOptions(opts)
+
+	data := c.getString()
+	if len(data) == 0 {
+		return Err(ErrEmptyInput)
+	}
+	if target == nil {
+		return Err(errInvalidMsgpack, "target cannot be nil")
+	}
+	rv := refValueOf(target)
+	if rv.refKind() != tpPointer {
+		return Err(errInvalidMsgpack, "target must be a pointer, got: "+rv.refKind().String())
+	}
+	elem := rv.refElem()
+	if !elem.refIsValid() {
+		return Err(errInvalidMsgpack, "target pointer is nil or invalid")
+	}
+
+	d := msgpackDecoder{jh: jh, s: data}
+	if err := d.decodeValue(elem); err != nil {
+		return d.errorAt(err)
+	}
+	if d.pos < len(d.s) {
+		d.at = d.pos
+		return d.errorAt(Err(errInvalidMsgpack, "unexpected data after MessagePack value"))
+	}
+	return nil
+}
+
+// msgpackDecoder reads one MessagePack value from s
+type msgpackDecoder struct {
+	jh  *jsonH // decode settings and depth tracking
+	s   string
+	pos int // next byte to read
+	at  int // start of the value being decoded, for error offsets
+}
+
+// errorAt adds the offset of the failing value to err
+func (d *msgpackDecoder) errorAt(err error) error {
+	return Err(err.Error(), "at offset", Convert(d.at).String())
+}
+
+// truncated is returned when the input ends inside a value
+func (d *msgpackDecoder) truncated() error {
+	return Err(errInvalidMsgpack, "unexpected end of MessagePack input")
+}
+
+// take returns the next n bytes
+func (d *msgpackDecoder) take(n int) (string, error) {
+	if n < 0 || n > len(d.s)-d.pos {
+		return "", d.truncated()
+	}
+	b := d.s[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// readUint reads an n-byte big-endian unsigned integer
+func (d *msgpackDecoder) readUint(n int) (uint64, error) {
+	b, err := d.take(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for i := 0; i < len(b); i++ {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, nil
+}
+
+// readLength reads the n-byte length that follows a str, bin, array or map
+// format byte and checks that many items can still follow
+func (d *msgpackDecoder) readLength(n int) (int, error) {
+	v, err := d.readUint(n)
+	if err != nil {
+		return 0, err
+	}
+	if v > uint64(len(d.s)-d.pos) {
+		return 0, d.truncated()
+	}
+	return int(v), nil
+}
+
+// binaryNumber is a number decoded from a binary format, in the widest
+// representation of its kind
+type binaryNumber struct {
+	kind byte // 'i' signed, 'u' unsigned, 'f' float
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// String formats the number for error messages
+func (n binaryNumber) String() string {
+	switch n.kind {
+	case 'i':
+		return Convert(n.i).String()
+	case 'u':
+		return Convert(n.u).String()
+	}
+	return string(appendJsonFloat(nil, n.f, 64))
+}
+
+// readNumber reads an integer or float of any format, false when the next
+// value is not a number
+func (d *msgpackDecoder) readNumber() (binaryNumber, bool, error) {
+	b := d.s[d.pos]
+	switch {
+	case b <= 0x7f:
+		d.pos++
+		return binaryNumber{kind: 'u', u: uint64(b)}, true, nil
+	case b >= 0xe0:
+		d.pos++
+		return binaryNumber{kind: 'i', i: int64(int8(b))}, true, nil
+	}
+
+	var n binaryNumber
+	var size int
+	switch b {
+	case mpUint8, mpUint16, mpUint32, mpUint64:
+		n.kind, size = 'u', 1<<(b-mpUint8)
+	case mpInt8, mpInt16, mpInt32, mpInt64:
+		n.kind, size = 'i', 1<<(b-mpInt8)
+	case mpFloat32:
+		n.kind, size = 'f', 4
+	case mpFloat64:
+		n.kind, size = 'f', 8
+	default:
+		return n, false, nil
+	}
+	d.pos++
+	v, err := d.readUint(size)
+	if err != nil {
+		return n, true, err
+	}
+	switch {
+	case n.kind == 'u':
+		n.u = v
+	case n.kind == 'i':
+		// Sign-extend from the format width
+		shift := 64 - 8*size
+		n.i = int64(v<<shift) >> shift
+	case size == 4:
+		bits := uint32(v)
+		n.f = float64(*(*float32)(unsafe.Pointer(&bits)))
+	default:
+		n.f = *(*float64)(unsafe.Pointer(&v))
+	}
+	return n, true, nil
+}
+
+// readString reads a str or bin value, false when the next value is neither
+func (d *msgpackDecoder) readString() (string, bool, error) {
+	b := d.s[d.pos]
+	var n int
+	var err error
+	switch {
+	case b&0xe0 == mpFixStr:
+		d.pos++
+		n = int(b & 0x1f)
+	case b == mpStr8 || b == mpBin8:
+		d.pos++
+		n, err = d.readLength(1)
+	case b == mpStr16 || b == mpBin16:
+		d.pos++
+		n, err = d.readLength(2)
+	case b == mpStr32 || b == mpBin32:
+		d.pos++
+		n, err = d.readLength(4)
+	default:
+		return "", false, nil
+	}
+	if err != nil {
+		return "", true, err
+	}
+	s, err := d.take(n)
+	return s, true, err
+}
+
+// readContainer reads an array or map header and returns its item count,
+// false when the next value is not that container
+func (d *msgpackDecoder) readContainer(isMap bool) (int, bool, error) {
+	b := d.s[d.pos]
+	fix, wide := byte(mpFixArray), byte(mpArray16)
+	if isMap {
+		fix, wide = mpFixMap, mpMap16
+	}
+	var n int
+	var err error
+	switch {
+	case b&0xf0 == fix:
+		d.pos++
+		n = int(b & 0x0f)
+	case b == wide:
+		d.pos++
+		n, err = d.readLength(2)
+	case b == wide+1:
+		d.pos++
+		n, err = d.readLength(4)
+	default:
+		return 0, false, nil
+	}
+	return n, true, err
+}
+
+// readExt reads an extension header and returns its type and data
+func (d *msgpackDecoder) readExt() (byte, string, bool, error) {
+	b := d.s[d.pos]
+	var n int
+	var err error
+	switch b {
+	case mpFixExt1, mpFixExt1 + 1, mpFixExt4, mpFixExt8, mpFixExt16:
+		d.pos++
+		n = 1 << (b - mpFixExt1)
+	case mpExt8, mpExt16, mpExt32:
+		d.pos++
+		n, err = d.readLength(1 << (b - mpExt8))
+	default:
+		return 0, "", false, nil
+	}
+	if err != nil {
+		return 0, "", true, err
+	}
+	typ, err := d.take(1)
+	if err != nil {
+		return 0, "", true, err
+	}
+	data, err := d.take(n)
+	return typ[0], data, true, err
+}
+
+// skipValue moves past the next value
+func (d *msgpackDecoder) skipValue() error {
+	if d.pos >= len(d.s) {
+		return d.truncated()
+	}
+	if d.s[d.pos] == mpNil || d.s[d.pos] == mpTrue || d.s[d.pos] == mpFalse {
+		d.pos++
+		return nil
+	}
+	if _, ok, err := d.readNumber(); ok {
+		return err
+	}
+	if _, ok, err := d.readString(); ok {
+		return err
+	}
+	if _, _, ok, err := d.readExt(); ok {
+		return err
+	}
+	for _, isMap := range [2]bool{false, true} {
+		n, ok, err := d.readContainer(isMap)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if isMap {
+			n *= 2
+		}
+		if err := d.jh.enterJsonDepth(); err != nil {
+			return err
+		}
+		defer d.jh.leaveJsonDepth()
+		for ; n > 0; n-- {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return Err(errInvalidMsgpack, "unknown format byte "+Convert(int(d.s[d.pos])).String())
+}
+
+// decodeValue decodes the next value into target
+func (d *msgpackDecoder) decodeValue(target *refValue) error {
+	if d.pos >= len(d.s) {
+		return d.truncated()
+	}
+	d.at = d.pos
+	if d.s[d.pos] == mpNil {
+		d.pos++
+		// nil clears nillable targets like JSON null and leaves the rest untouched
+		d.jh.decodeJsonNull("null", target)
+		return nil
+	}
+	if isTimeType(target) {
+		return d.decodeTime(target)
+	}
+
+	switch target.refKind() {
+	case tpPointer:
+		return d.decodePointer(target)
+	case tpInterface:
+		if !target.Type().isEmptyInterface() {
+			if pointee := target.refInterfacePointee(); pointee != nil {
+				return d.decodeValue(pointee)
+			}
+			return Err(errUnsupportedType, "for MessagePack decoding: only empty interface targets (any) or interfaces holding a pointer are supported")
+		}
+		v, err := d.decodeGeneric()
+		if err != nil {
+			return err
+		}
+		return target.refSetInterface(v)
+	case tpString:
+		s, ok, err := d.readString()
+		if !ok {
+			return d.mismatch(target)
+		}
+		if err == nil {
+			target.refSetString(s)
+		}
+		return err
+	case tpBool:
+		switch d.s[d.pos] {
+		case mpTrue:
+			target.refSetBool(true)
+		case mpFalse:
+			target.refSetBool(false)
+		default:
+			return d.mismatch(target)
+		}
+		d.pos++
+		return nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64,
+		tpUint, tpUint8, tpUint16, tpUint32, tpUint64,
+		tpFloat32, tpFloat64:
+		n, ok, err := d.readNumber()
+		if !ok {
+			return d.mismatch(target)
+		}
+		if err != nil {
+			return err
+		}
+		return setBinaryNumber(n, target, errInvalidMsgpack)
+	case tpSlice:
+		return d.decodeSlice(target)
+	case tpArray:
+		return d.decodeArray(target)
+	case tpStruct:
+		return d.decodeStruct(target)
+	case tpMap:
+		return d.decodeMap(target)
+	}
+	return unsupportedKindError("for MessagePack decoding", target.refKind())
+}
+
+// mismatch reports a value whose format does not suit target
+func (d *msgpackDecoder) mismatch(target *refValue) error {
+	return Err(errInvalidMsgpack, "format byte "+Convert(int(d.s[d.pos])).String()+" cannot be decoded into "+target.refKind().String())
+}
+
+// setBinaryNumber stores n into a numeric target when it fits exactly
+// errType names the format in the out-of-range error
+func setBinaryNumber(n binaryNumber, target *refValue, errType errorType) error {
+	outOfRange := Err(errType, "number out of range for "+target.refKind().String()+": "+n.String())
+	switch target.refKind() {
+	case tpFloat32, tpFloat64:
+		switch n.kind {
+		case 'i':
+			target.refSetFloat(float64(n.i))
+		case 'u':
+			target.refSetFloat(float64(n.u))
+		default:
+			if target.refKind() == tpFloat32 && n.f-n.f == 0 && float64(float32(n.f)) != n.f {
+				return outOfRange
+			}
+			target.refSetFloat(n.f)
+		}
+		return nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		v := n.i
+		switch {
+		case n.kind == 'u' && n.u > 1<<63-1:
+			return outOfRange
+		case n.kind == 'u':
+			v = int64(n.u)
+		case n.kind == 'f':
+			if !(n.f >= -1<<63 && n.f < 1<<63) || n.f != float64(int64(n.f)) {
+				return outOfRange
+			}
+			v = int64(n.f)
+		}
+		if err := checkIntRange(v, n.String(), target); err != nil {
+			return err
+		}
+		target.refSetInt(v)
+		return nil
+	}
+	u := n.u
+	switch {
+	case n.kind == 'i' && n.i < 0:
+		return outOfRange
+	case n.kind == 'i':
+		u = uint64(n.i)
+	case n.kind == 'f':
+		if !(n.f >= 0 && n.f < 1<<64) || n.f != float64(uint64(n.f)) {
+			return outOfRange
+		}
+		u = uint64(n.f)
+	}
+	if err := checkUintRange(u, n.String(), target); err != nil {
+		return err
+	}
+	target.refSetUint(u)
+	return nil
+}
+
+// decodePointer decodes into the element of a pointer, allocating it if needed
+func (d *msgpackDecoder) decodePointer(target *refValue) error {
+	if elem := target.refElem(); elem.refIsValid() {
+		return d.decodeValue(elem)
+	}
+	elemType := target.Type().Elem()
+	if elemType == nil {
+		return Err(errUnsupportedType, "pointer element type is nil")
+	}
+	elem, err := refNewValue(elemType)
+	if err != nil {
+		return err
+	}
+	elem.separator = d.jh.jSep
+	if err := d.decodeValue(elem); err != nil {
+		return err
+	}
+	*(*unsafe.Pointer)(target.ptr) = elem.ptr
+	return nil
+}
+
+// decodeSlice decodes an array, or a bin/str into []byte, replacing the slice
+func (d *msgpackDecoder) decodeSlice(target *refValue) error {
+	if isJsonByteSlice(target) {
+		if s, ok, err := d.readString(); ok {
+			if err == nil {
+				// Named byte slice types share the layout of []byte
+				*(*[]byte)(target.ptr) = []byte(s)
+			}
+			return err
+		}
+	}
+	n, ok, err := d.readContainer(false)
+	if !ok {
+		return d.mismatch(target)
+	}
+	if err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	makeJsonSlice(target, n, d.jh.jArena)
+	for i := range n {
+		if err := d.decodeValue(target.refIndex(i)); err != nil {
+			return prefixKindPath(err, indexSegment(i))
+		}
+	}
+	return nil
+}
+
+// decodeArray decodes an array into a fixed-size array; like JSON, extra
+// elements are ignored and missing ones are zeroed
+func (d *msgpackDecoder) decodeArray(target *refValue) error {
+	n, ok, err := d.readContainer(false)
+	if !ok {
+		return d.mismatch(target)
+	}
+	if err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	length := target.refLen()
+	for i := range n {
+		if i >= length {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(target.refIndex(i)); err != nil {
+			return prefixKindPath(err, indexSegment(i))
+		}
+	}
+	for i := n; i < length; i++ {
+		elem := target.refIndex(i)
+		memclr(elem.ptr, elem.Type().Size())
+	}
+	return nil
+}
+
+// decodeStruct decodes a map into the fields matching its keys; unknown keys
+// are skipped
+func (d *msgpackDecoder) decodeStruct(target *refValue) error {
+	n, ok, err := d.readContainer(true)
+	if !ok {
+		return d.mismatch(target)
+	}
+	if err != nil {
+		return err
+	}
+	plan, err := decodePlanFor(target)
+	if err != nil {
+		return err
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	for ; n > 0; n-- {
+		if d.pos >= len(d.s) {
+			return d.truncated()
+		}
+		key, ok, err := d.readString()
+		if !ok {
+			return Err(errInvalidMsgpack, "struct keys must be strings")
+		}
+		if err != nil {
+			return err
+		}
+		index := plan.fieldIndex(key, d.jh.jMatch)
+		if index == -1 || plan.fields[index].secure || plan.fields[index].unexported {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		field := target.refField(index)
+		if !field.refIsValid() {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(field); err != nil {
+			if _, ok := err.(*kindError); ok {
+				return prefixKindPath(err, plan.fields[index].name)
+			}
+			return Err(errInvalidMsgpack, "field "+plan.fields[index].name+": "+err.Error())
+		}
+	}
+	return nil
+}
+
+// decodeMap decodes a map into a Go map with string or integer keys
+// A nil map is allocated; an existing map keeps its entries and gains new ones
+func (d *msgpackDecoder) decodeMap(target *refValue) error {
+	n, ok, err := d.readContainer(true)
+	if !ok {
+		return d.mismatch(target)
+	}
+	if err != nil {
+		return err
+	}
+	mapType := target.Type()
+	keyType, elemType := mapType.mapKey(), mapType.mapElem()
+	if keyType == nil || elemType == nil {
+		return Err(ErrNoReflection, "map type information is missing")
+	}
+	switch keyType.Kind() {
+	case tpString, tpInt, tpInt8, tpInt16, tpInt32, tpInt64, tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+	default:
+		return Err(errUnsupportedType, "map key type: "+keyType.Kind().String())
+	}
+	if target.refMapIsNil() {
+		if err := target.refMakeMap(n); err != nil {
+			return err
+		}
+	}
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return err
+	}
+	defer d.jh.leaveJsonDepth()
+
+	for ; n > 0; n-- {
+		key, err := refNewValue(keyType)
+		if err != nil {
+			return err
+		}
+		if err := d.decodeValue(key); err != nil {
+			return err
+		}
+		elem, err := refNewValue(elemType)
+		if err != nil {
+			return err
+		}
+		elem.separator = d.jh.jSep
+		if err := d.decodeValue(elem); err != nil {
+			return err
+		}
+		if err := target.refSetMapIndex(key, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTime decodes a timestamp extension into a time.Time target
+func (d *msgpackDecoder) decodeTime(target *refValue) error {
+	typ, data, ok, err := d.readExt()
+	if !ok || err == nil && typ != mpExtTimestamp {
+		return Err(errInvalidMsgpack, "expected timestamp for time.Time")
+	}
+	if err != nil {
+		return err
+	}
+	t, err := parseMsgpackTime(data)
+	if err == nil {
+		*(*time.Time)(target.ptr) = t
+	}
+	return err
+}
+
+// parseMsgpackTime converts the data of a timestamp extension
+func parseMsgpackTime(data string) (time.Time, error) {
+	var v uint64
+	for i := 0; i < len(data) && i < 8; i++ {
+		v = v<<8 | uint64(data[i])
+	}
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(v), 0), nil
+	case 8:
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		var sec uint64
+		for i := 4; i < 12; i++ {
+			sec = sec<<8 | uint64(data[i])
+		}
+		return time.Unix(int64(sec), int64(v>>32)), nil
+	}
+	return time.Time{}, Err(errInvalidMsgpack, "invalid timestamp length")
+}
+
+// decodeGeneric decodes the next value into its generic Go representation
+func (d *msgpackDecoder) decodeGeneric() (any, error) {
+	if d.pos >= len(d.s) {
+		return nil, d.truncated()
+	}
+	d.at = d.pos
+	switch b := d.s[d.pos]; {
+	case b == mpNil:
+		d.pos++
+		return nil, nil
+	case b == mpTrue || b == mpFalse:
+		d.pos++
+		return b == mpTrue, nil
+	case b == mpBin8 || b == mpBin16 || b == mpBin32:
+		s, _, err := d.readString()
+		return []byte(s), err
+	}
+	if n, ok, err := d.readNumber(); ok {
+		switch {
+		case err != nil:
+			return nil, err
+		case n.kind == 'f':
+			return n.f, nil
+		case n.kind == 'u' && n.u > 1<<63-1:
+			return n.u, nil
+		case n.kind == 'u':
+			return int64(n.u), nil
+		}
+		return n.i, nil
+	}
+	if s, ok, err := d.readString(); ok {
+		return s, err
+	}
+	if typ, data, ok, err := d.readExt(); ok {
+		if err != nil {
+			return nil, err
+		}
+		if typ != mpExtTimestamp {
+			return nil, Err(errUnsupportedType, "MessagePack extension type "+Convert(int(int8(typ))).String())
+		}
+		return parseMsgpackTime(data)
+	}
+
+	if err := d.jh.enterJsonDepth(); err != nil {
+		return nil, err
+	}
+	defer d.jh.leaveJsonDepth()
+	if n, ok, err := d.readContainer(false); ok {
+		if err != nil {
+			return nil, err
+		}
+		arr := make([]any, n)
+		for i := range arr {
+			if arr[i], err = d.decodeGeneric(); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+	if n, ok, err := d.readContainer(true); ok {
+		if err != nil {
+			return nil, err
+		}
+		obj := make(map[string]any, n)
+		for ; n > 0; n-- {
+			if d.pos >= len(d.s) {
+				return nil, d.truncated()
+			}
+			key, ok, err := d.readString()
+			if !ok {
+				return nil, Err(errInvalidMsgpack, "map keys must be strings for any targets")
+			}
+			if err != nil {
+				return nil, err
+			}
+			if obj[key], err = d.decodeGeneric(); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	}
+	return nil, Err(errInvalidMsgpack, "unknown format byte "+Convert(int(d.s[d.pos])).String())
+}
@@ -0,0 +1,146 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	clearRefStructsCache()
+
+	type Address struct {
+		City string
+		Zip  uint32
+	}
+	type Profile struct {
+		ID       int64
+		Name     string
+		Score    float64
+		Ratio    float32
+		Active   bool
+		Avatar   []byte
+		Tags     []string
+		Home     *Address
+		Visits   [3]int8
+		Created  time.Time
+		Extra    any
+		Previous []Address
+	}
+
+	in := Profile{
+		ID: -1 << 40, Name: "ana", Score: 0.1, Ratio: 2.5, Active: true,
+		Avatar: []byte{0, 255}, Tags: []string{"a", "b"},
+		Home:     &Address{City: "Lima", Zip: 70000},
+		Visits:   [3]int8{-128, 0, 127},
+		Created:  time.Unix(1700000000, 123456789),
+		Extra:    []any{int64(1), "two", map[string]any{"three": 3.5}},
+		Previous: []Address{{City: "Quito"}},
+	}
+	data, err := Convert(&in).MsgpackEncode()
+	if err != nil {
+		t.Fatalf("MsgpackEncode returned error: %v", err)
+	}
+
+	var out Profile
+	if err := Convert(data).MsgpackDecode(&out); err != nil {
+		t.Fatalf("MsgpackDecode returned error: %v", err)
+	}
+	if out.ID != in.ID || out.Name != in.Name || out.Score != in.Score || out.Ratio != in.Ratio || !out.Active {
+		t.Errorf("scalars = %+v, expected %+v", out, in)
+	}
+	if string(out.Avatar) != string(in.Avatar) || len(out.Tags) != 2 || out.Tags[1] != "b" {
+		t.Errorf("slices = %v %v", out.Avatar, out.Tags)
+	}
+	if out.Home == nil || *out.Home != *in.Home || out.Visits != in.Visits {
+		t.Errorf("nested = %+v %v", out.Home, out.Visits)
+	}
+	if !out.Created.Equal(in.Created) {
+		t.Errorf("Created = %v, expected %v", out.Created, in.Created)
+	}
+	extra, ok := out.Extra.([]any)
+	if !ok || len(extra) != 3 || extra[0] != int64(1) || extra[1] != "two" {
+		t.Fatalf("Extra = %#v", out.Extra)
+	}
+	if m, ok := extra[2].(map[string]any); !ok || m["three"] != 3.5 {
+		t.Errorf("Extra[2] = %#v", extra[2])
+	}
+	if len(out.Previous) != 1 || out.Previous[0].City != "Quito" {
+		t.Errorf("Previous = %+v", out.Previous)
+	}
+}
+
+func TestMsgpackDecodeConversions(t *testing.T) {
+	clearRefStructsCache()
+
+	type Target struct {
+		Count  int8
+		Size   uint16
+		Amount float64
+		Items  map[string]int
+		Ptr    *int
+		Name   string `json:"user_name"`
+	}
+
+	// {"count": 5.0, "size": 7, "amount": -2, "items": {"a": 1}, "Unknown": [1, {}], "user_name": bin "x"}
+	data := "\x86" +
+		"\xa5count\xcb\x40\x14\x00\x00\x00\x00\x00\x00" +
+		"\xa4size\x07" +
+		"\xa6amount\xfe" +
+		"\xa5items\x81\xa1a\x01" +
+		"\xa7Unknown\x92\x01\x80" +
+		"\xa9user_name\xc4\x01x"
+	target := Target{Ptr: new(int)}
+	if err := Convert(data).MsgpackDecode(&target); err != nil {
+		t.Fatalf("MsgpackDecode returned error: %v", err)
+	}
+	if target.Count != 5 || target.Size != 7 || target.Amount != -2 || target.Items["a"] != 1 || target.Name != "x" {
+		t.Errorf("MsgpackDecode = %+v", target)
+	}
+	if target.Ptr == nil {
+		t.Error("absent fields should be left untouched")
+	}
+
+	// nil clears pointers
+	if err := Convert("\x81\xa3Ptr\xc0").MsgpackDecode(&target); err != nil || target.Ptr != nil {
+		t.Errorf("MsgpackDecode(nil) = %v, %v", target.Ptr, err)
+	}
+
+	var big any
+	if err := Convert("\xcf\xff\xff\xff\xff\xff\xff\xff\xff").MsgpackDecode(&big); err != nil || big != uint64(1<<64-1) {
+		t.Errorf("MsgpackDecode(uint64 max) into any = %#v, %v", big, err)
+	}
+}
+
+func TestMsgpackDecodeErrors(t *testing.T) {
+	clearRefStructsCache()
+
+	type Small struct {
+		N int8
+	}
+	tests := []struct {
+		name   string
+		input  string
+		target any
+	}{
+		{"out of range", "\x81\xa1N\xcc\xc8", &Small{}},
+		{"fractional", "\x81\xa1N\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00", &Small{}},
+		{"type mismatch", "\x81\xa1N\xa1x", &Small{}},
+		{"truncated string", "\xa5abc", new(string)},
+		{"truncated array", "\xdc\xff\xff\x01", new([]int)},
+		{"trailing data", "\x01\x02", new(int)},
+		{"unknown format", "\xc1", new(any)},
+		{"negative unsigned", "\xff", new(uint)},
+		{"not a pointer", "\x01", 0},
+	}
+	for _, tt := range tests {
+		if err := Convert(tt.input).MsgpackDecode(tt.target); err == nil {
+			t.Errorf("%s: MsgpackDecode should fail", tt.name)
+		}
+	}
+
+	if err := Convert("").MsgpackDecode(new(int)); err == nil || !Contains(err.Error(), string(ErrEmptyInput)) {
+		t.Errorf("MsgpackDecode(empty) error = %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package tinywodp
+
+import (
+	"time"
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// MessagePack encoding
+// A compact binary alternative to JSON for WASM<->server payloads, built on the
+// same reflection core and pooled handler as the JSON encoder, so it adds little
+// to the binary. Values map onto MessagePack as:
+//
+//	struct          -> map keyed by field name (SetFieldNaming applies)
+//	[]byte          -> bin; other slices and arrays -> array
+//	integers        -> the smallest int/uint format holding the value
+//	float32/float64 -> float 32/float 64; NaN and ±Inf are kept
+//	time.Time       -> timestamp extension (type -1)
+//	nil pointer/any -> nil; nil slices follow SetNilSliceEncoding
+//
+// Maps are decode-only, as in the JSON encoder. Secure fields are rejected
+// instead of being written in clear, since sealing is defined over JSON text.
+
+// MessagePack format bytes (https://github.com/msgpack/msgpack/blob/master/spec.md)
+const (
+	mpNil      = 0xc0
+	mpFalse    = 0xc2
+	mpTrue     = 0xc3
+	mpBin8     = 0xc4
+	mpBin16    = 0xc5
+	mpBin32    = 0xc6
+	mpExt8     = 0xc7
+	mpExt16    = 0xc8
+	mpExt32    = 0xc9
+	mpFloat32  = 0xca
+	mpFloat64  = 0xcb
+	mpUint8    = 0xcc
+	mpUint16   = 0xcd
+	mpUint32   = 0xce
+	mpUint64   = 0xcf
+	mpInt8     = 0xd0
+	mpInt16    = 0xd1
+	mpInt32    = 0xd2
+	mpInt64    = 0xd3
+	mpFixExt1  = 0xd4
+	mpFixExt4  = 0xd6
+	mpFixExt8  = 0xd7
+	mpFixExt16 = 0xd8
+	mpStr8     = 0xd9
+	mpStr16    = 0xda
+	mpStr32    = 0xdb
+	mpArray16  = 0xdc
+	mpArray32  = 0xdd
+	mpMap16    = 0xde
+	mpMap32    = 0xdf
+
+	mpFixMap   = 0x80 // 1000xxxx, up to 15 pairs
+	mpFixArray = 0x90 // 1001xxxx, up to 15 elements
+	mpFixStr   = 0xa0 // 101xxxxx, up to 31 bytes
+
+	mpExtTimestamp = 0xff // extension type -1, timestamps
+)
+
+// MsgpackEncode converts the current value to MessagePack
+//
+// Usage patterns:
+//
+//	data, err := Convert(&user).MsgpackEncode()
+//	_, err := Convert(&user).MsgpackEncode(httpResponseWriter)
+//
+// Like JsonEncode, it returns a copy of the output, or writes it to the
+// optional writer and returns nil bytes
+func (c *refValue) MsgpackEncode(w ...writer) ([]byte, error) {
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	return jh.encodeTo(c, w, jh.appendMsgpack)
+}
+
+// appendMsgpack appends the MessagePack representation of c to dst
+func (jh *jsonH) appendMsgpack(dst []byte, c *refValue) ([]byte, error) {
+	switch c.vTpe {
+	case tpString:
+		return appendMsgpackString(dst, c.getString()), nil
+	case tpBool:
+		return appendMsgpackBool(dst, c.getBool()), nil
+	case tpStrSlice:
+		if c.stringSliceVal == nil && jh.jNilSlice == NilSliceAsNull {
+			return append(dst, mpNil), nil
+		}
+		dst = appendMsgpackArrayHeader(dst, len(c.stringSliceVal))
+		for _, s := range c.stringSliceVal {
+			dst = appendMsgpackString(dst, s)
+		}
+		return dst, nil
+	}
+	return jh.appendMsgpackValue(dst, c)
+}
+
+// appendMsgpackValue appends a value reached through reflection
+func (jh *jsonH) appendMsgpackValue(dst []byte, v *refValue) ([]byte, error) {
+	if v == nil || !v.refIsValid() {
+		return append(dst, mpNil), nil
+	}
+	if isTimeType(v) {
+		t, _ := v.Interface().(time.Time)
+		return appendMsgpackTime(dst, t), nil
+	}
+
+	switch v.refKind() {
+	case tpString:
+		return appendMsgpackString(dst, v.refString()), nil
+	case tpInt, tpInt8, tpInt16, tpInt32, tpInt64:
+		return appendMsgpackInt(dst, v.refInt()), nil
+	case tpUint, tpUint8, tpUint16, tpUint32, tpUint64:
+		return appendMsgpackUint(dst, v.refUint()), nil
+	case tpFloat32:
+		f := float32(v.refFloat())
+		return appendUint32BE(append(dst, mpFloat32), *(*uint32)(unsafe.Pointer(&f))), nil
+	case tpFloat64:
+		f := v.refFloat()
+		return appendUint64BE(append(dst, mpFloat64), *(*uint64)(unsafe.Pointer(&f))), nil
+	case tpBool:
+		return appendMsgpackBool(dst, v.refBool()), nil
+	case tpSlice, tpArray:
+		return jh.appendMsgpackSlice(dst, v)
+	case tpStruct:
+		return jh.appendMsgpackStruct(dst, v)
+	case tpPointer:
+		return jh.appendMsgpackValue(dst, v.refElem())
+	case tpInterface:
+		inner := v.refInterfaceValue()
+		if inner == nil {
+			return append(dst, mpNil), nil
+		}
+		return jh.appendMsgpackValue(dst, refValueOf(inner))
+	}
+	return dst, unsupportedKindError("for MessagePack encoding", v.refKind())
+}
+
+// appendMsgpackSlice appends a slice or array; []byte is written as bin
+func (jh *jsonH) appendMsgpackSlice(dst []byte, v *refValue) ([]byte, error) {
+	if isNilSlice(v) && jh.jNilSlice == NilSliceAsNull {
+		return append(dst, mpNil), nil
+	}
+	if isJsonByteSlice(v) {
+		var b []byte
+		if v.ptr != nil {
+			b = *(*[]byte)(v.ptr)
+		}
+		dst = appendMsgpackBinHeader(dst, len(b))
+		return append(dst, b...), nil
+	}
+
+	n := v.refLen()
+	dst = appendMsgpackArrayHeader(dst, n)
+	var err error
+	for i := range n {
+		if dst, err = jh.appendMsgpackValue(dst, v.refIndex(i)); err != nil {
+			return dst, prefixKindPath(err, indexSegment(i))
+		}
+	}
+	return dst, nil
+}
+
+// appendMsgpackStruct appends a struct as a map from field names to values
+func (jh *jsonH) appendMsgpackStruct(dst []byte, v *refValue) ([]byte, error) {
+	var structInfo refStructType
+	if err := structMetadataFor(v, &structInfo); err != nil {
+		return dst, err
+	}
+
+	numFields := v.refNumField()
+	count := 0
+	for i := range numFields {
+		if v.refField(i).refIsValid() && isExportedField(structInfo.fields[i].name) {
+			count++
+		}
+	}
+
+	dst = appendMsgpackMapHeader(dst, count)
+	for i := range numFields {
+		field := v.refField(i)
+		if !field.refIsValid() || !isExportedField(structInfo.fields[i].name) {
+			continue
+		}
+		if isEncryptedField(structInfo.fields[i].tag.Get("secure")) {
+			return dst, Err(errUnsupportedType, "secure field "+structInfo.fields[i].name+" for MessagePack encoding")
+		}
+		info := &structInfo.fields[i]
+		dst = appendMsgpackString(dst, taggedFieldName(info.name, info.tag.Get("tiny"), info.tag.Get("json"), jh.jNaming))
+		var err error
+		if dst, err = jh.appendMsgpackValue(dst, field); err != nil {
+			return dst, prefixKindPath(err, structInfo.fields[i].name)
+		}
+	}
+	return dst, nil
+}
+
+// appendMsgpackStrHeader appends the header of a str of n bytes
+func appendMsgpackStrHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 32:
+		return append(dst, mpFixStr|byte(n))
+	case n <= 0xff:
+		return append(dst, mpStr8, byte(n))
+	case n <= 0xffff:
+		return append(dst, mpStr16, byte(n>>8), byte(n))
+	}
+	return appendUint32BE(append(dst, mpStr32), uint32(n))
+}
+
+// appendMsgpackBinHeader appends the header of a bin of n bytes
+func appendMsgpackBinHeader(dst []byte, n int) []byte {
+	switch {
+	case n <= 0xff:
+		return append(dst, mpBin8, byte(n))
+	case n <= 0xffff:
+		return append(dst, mpBin16, byte(n>>8), byte(n))
+	}
+	return appendUint32BE(append(dst, mpBin32), uint32(n))
+}
+
+// appendMsgpackArrayHeader appends the header of an array of n elements
+func appendMsgpackArrayHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, mpFixArray|byte(n))
+	case n <= 0xffff:
+		return append(dst, mpArray16, byte(n>>8), byte(n))
+	}
+	return appendUint32BE(append(dst, mpArray32), uint32(n))
+}
+
+// appendMsgpackMapHeader appends the header of a map of n key/value pairs
+func appendMsgpackMapHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, mpFixMap|byte(n))
+	case n <= 0xffff:
+		return append(dst, mpMap16, byte(n>>8), byte(n))
+	}
+	return appendUint32BE(append(dst, mpMap32), uint32(n))
+}
+
+// appendMsgpackString appends s as str
+func appendMsgpackString(dst []byte, s string) []byte {
+	dst = appendMsgpackStrHeader(dst, len(s))
+	return append(dst, s...)
+}
+
+// appendMsgpackBool appends true or false
+func appendMsgpackBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, mpTrue)
+	}
+	return append(dst, mpFalse)
+}
+
+// appendMsgpackInt appends a signed integer in its smallest format
+func appendMsgpackInt(dst []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(dst, uint64(v))
+	case v >= -32:
+		return append(dst, byte(v)) // negative fixint
+	case v >= -1<<7:
+		return append(dst, mpInt8, byte(v))
+	case v >= -1<<15:
+		return append(dst, mpInt16, byte(v>>8), byte(v))
+	case v >= -1<<31:
+		return appendUint32BE(append(dst, mpInt32), uint32(v))
+	}
+	return appendUint64BE(append(dst, mpInt64), uint64(v))
+}
+
+// appendMsgpackUint appends an unsigned integer in its smallest format
+func appendMsgpackUint(dst []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(dst, byte(v)) // positive fixint
+	case v <= 0xff:
+		return append(dst, mpUint8, byte(v))
+	case v <= 0xffff:
+		return append(dst, mpUint16, byte(v>>8), byte(v))
+	case v <= 0xffffffff:
+		return appendUint32BE(append(dst, mpUint32), uint32(v))
+	}
+	return appendUint64BE(append(dst, mpUint64), v)
+}
+
+// appendUint32BE appends v big-endian
+func appendUint32BE(dst []byte, v uint32) []byte {
+	return append(dst, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// appendUint64BE appends v big-endian
+func appendUint64BE(dst []byte, v uint64) []byte {
+	return appendUint32BE(appendUint32BE(dst, uint32(v>>32)), uint32(v))
+}
+
+// appendMsgpackTime appends t as a timestamp extension in its smallest form
+func appendMsgpackTime(dst []byte, t time.Time) []byte {
+	sec, nsec := t.Unix(), uint32(t.Nanosecond())
+	switch {
+	case nsec == 0 && sec >= 0 && sec < 1<<32:
+		// timestamp 32: seconds only
+		dst = append(dst, mpFixExt4, mpExtTimestamp)
+		return appendUint32BE(dst, uint32(sec))
+	case sec >= 0 && sec < 1<<34:
+		// timestamp 64: 30-bit nanoseconds and 34-bit seconds
+		dst = append(dst, mpFixExt8, mpExtTimestamp)
+		return appendUint64BE(dst, uint64(nsec)<<34|uint64(sec))
+	}
+	// timestamp 96: nanoseconds and signed 64-bit seconds
+	dst = append(dst, mpExt8, 12, mpExtTimestamp)
+	dst = appendUint32BE(dst, nsec)
+	return appendUint64BE(dst, uint64(sec))
+}
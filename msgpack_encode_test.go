@@ -0,0 +1,85 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestMsgpackEncodeScalars(t *testing.T) {
+	clearRefStructsCache()
+
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{"positive fixint", 7, "\x07"},
+		{"negative fixint", -3, "\xfd"},
+		{"uint8", 200, "\xcc\xc8"},
+		{"int8", -100, "\xd0\x9c"},
+		{"uint16", 1000, "\xcd\x03\xe8"},
+		{"int32", -70000, "\xd2\xff\xfe\xee\x90"},
+		{"uint64", uint64(1) << 40, "\xcf\x00\x00\x01\x00\x00\x00\x00\x00"},
+		{"float64", 1.5, "\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00"},
+		{"float32", float32(1.5), "\xca\x3f\xc0\x00\x00"},
+		{"true", true, "\xc3"},
+		{"fixstr", "hi", "\xa2hi"},
+		{"bin", []byte{1, 2}, "\xc4\x02\x01\x02"},
+		{"string slice", []string{"a", ""}, "\x92\xa1a\xa0"},
+		{"int slice", []int{1, -1}, "\x92\x01\xff"},
+		{"timestamp 32", time.Unix(1, 0), "\xd6\xff\x00\x00\x00\x01"},
+		{"timestamp 64", time.Unix(1, 2), "\xd7\xff\x00\x00\x00\x08\x00\x00\x00\x01"},
+		{"timestamp 96", time.Unix(-1, 0), "\xc7\x0c\xff\x00\x00\x00\x00\xff\xff\xff\xff\xff\xff\xff\xff"},
+	}
+	for _, tt := range tests {
+		out, err := Convert(tt.input).MsgpackEncode()
+		if err != nil {
+			t.Errorf("%s: MsgpackEncode returned error: %v", tt.name, err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("%s: MsgpackEncode = % x, expected % x", tt.name, out, tt.expected)
+		}
+	}
+}
+
+func TestMsgpackEncodeStruct(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		ID   uint16
+		Name string `json:"name"`
+		Next *Item
+	}
+
+	out, err := Convert(&Item{ID: 300, Name: "x"}).MsgpackEncode()
+	if err != nil {
+		t.Fatalf("MsgpackEncode returned error: %v", err)
+	}
+	expected := "\x83\xa2ID\xcd\x01\x2c\xa4name\xa1x\xa4Next\xc0"
+	if string(out) != expected {
+		t.Errorf("MsgpackEncode = % x, expected % x", out, expected)
+	}
+
+	var written []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if out, err := Convert(&Item{ID: 300, Name: "x"}).MsgpackEncode(w); err != nil || out != nil || string(written) != expected {
+		t.Errorf("MsgpackEncode(w) wrote % x, returned %v, %v", written, out, err)
+	}
+
+	if _, err := Convert(map[string]int{"a": 1}).MsgpackEncode(); err == nil {
+		t.Error("MsgpackEncode(map) should fail")
+	}
+
+	type Sealed struct {
+		Token string `secure:"encrypt"`
+	}
+	if _, err := Convert(&Sealed{Token: "t"}).MsgpackEncode(); err == nil {
+		t.Error("MsgpackEncode should reject secure fields")
+	}
+}
@@ -0,0 +1,70 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Interface values in the reflection core
+// An interface-kinded refValue holds the interface itself; refInterfaceElem
+// unwraps it to the concrete value and refSetInterface stores one back. The
+// layout-specific parts live in reflect_interface_go.go and
+// reflect_interface_tinygo.go.
+
+// refIsNil reports whether the pointer, map, slice or interface held by v is nil
+// Other kinds are never nil
+func (v *refValue) refIsNil() bool {
+	switch v.refKind() {
+	case tpInterface:
+		return v.refInterfaceValue() == nil
+	case tpMap:
+		return v.refMapIsNil()
+	case tpSlice:
+		return isNilSlice(v)
+	case tpPointer:
+		return !v.refElem().refIsValid()
+	}
+	return false
+}
+
+// refInterfaceElem returns the concrete value held by the interface-kinded v,
+// nil when the interface is nil
+// The result is a copy; a pointer in it still reaches the original pointee
+func (v *refValue) refInterfaceElem() *refValue {
+	inner := v.refInterfaceValue()
+	if inner == nil {
+		return nil
+	}
+	return refValueOf(inner)
+}
+
+// refInterfacePointee returns the addressable value a non-nil pointer held by
+// the interface-kinded v points to, nil otherwise
+// Decoders fill it in place, as encoding/json does, when the interface cannot
+// be assigned a fresh generic value
+func (v *refValue) refInterfacePointee() *refValue {
+	elem := v.refInterfaceElem()
+	if elem == nil || elem.refKind() != tpPointer {
+		return nil
+	}
+	if pointee := elem.refElem(); pointee.refIsValid() {
+		return pointee
+	}
+	return nil
+}
+
+// refSetInterface stores value in the addressable interface-kinded v; nil
+// clears it
+func (v *refValue) refSetInterface(value any) error {
+	if v.ptr == nil || v.flag&flagAddr == 0 {
+		return Err(errUnsupportedType, "interface value is not addressable")
+	}
+	if value == nil {
+		memclr(v.ptr, v.typ.Size())
+		return nil
+	}
+	if v.typ.isEmptyInterface() {
+		*(*any)(v.ptr) = value
+		return nil
+	}
+	return runtimeSetIface(v, value)
+}
@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package tinywodp
+
+import (
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// Interface support for the reflection core
+// Interface type descriptors extend the common type header with the package path
+// and method table, mirroring the runtime layout (internal/abi.InterfaceType).
+
+//go:linkname reflect_ifaceE2I reflect.ifaceE2I
+func reflect_ifaceE2I(t unsafe.Pointer, src any, dst unsafe.Pointer)
+
+// refInterfaceType is the runtime descriptor of an interface type
+type refInterfaceType struct {
+	refType
+	pkgPath unsafe.Pointer
+	methods []struct {
+		name int32
+		typ  int32
+	}
+}
+
+// isEmptyInterface reports whether t is an interface type without methods (any)
+func (t *refType) isEmptyInterface() bool {
+	if t == nil || t.Kind() != tpInterface {
+		return false
+	}
+	return len((*refInterfaceType)(unsafe.Pointer(t)).methods) == 0
+}
+
+// refInterfaceValue returns the dynamic value held by an interface-kinded v, nil when unset
+// Both interface layouts keep the type word first, so a non-empty interface converts
+// to any through the regular iface-to-eface conversion
+func (v *refValue) refInterfaceValue() any {
+	if v.ptr == nil {
+		return nil
+	}
+	if v.typ.isEmptyInterface() {
+		return *(*any)(v.ptr)
+	}
+	return any(*(*interface{ refIface() })(v.ptr))
+}
+
+// runtimeSetIface stores value in the non-empty interface held by v, reporting
+// an error when its dynamic type lacks a method of the interface
+func runtimeSetIface(v *refValue, value any) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = Err(errUnsupportedType, "value does not implement the interface")
+		}
+	}()
+	reflect_ifaceE2I(unsafe.Pointer(v.typ), value, v.ptr)
+	return nil
+}
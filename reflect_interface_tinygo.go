@@ -0,0 +1,30 @@
+//go:build tinygo
+
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Interface support on TinyGo
+
+// isEmptyInterface is not available on TinyGo, whose interface descriptors use a
+// different layout, so interface targets are reported as unsupported
+func (t *refType) isEmptyInterface() bool {
+	return false
+}
+
+// refInterfaceValue returns the dynamic value held by an interface-kinded v, nil when unset
+// TinyGo stores every interface as a type code and value pair, so any layout applies
+func (v *refValue) refInterfaceValue() any {
+	if v.ptr == nil {
+		return nil
+	}
+	return *(*any)(v.ptr)
+}
+
+// runtimeSetIface is not available on TinyGo, which has no hook converting a
+// value to a non-empty interface
+func runtimeSetIface(v *refValue, value any) error {
+	return Err(errUnsupportedType, "interface assignment is not available on TinyGo")
+}
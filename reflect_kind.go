@@ -0,0 +1,57 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Kinds without a representation
+// Channels, functions, complex numbers and unsafe pointers have no encoding in
+// any format. Every codec reaches them in the default branch of its kind switch
+// and reports a kindError there instead of skipping them or writing null; the
+// error carries the Go path of the value, completed as it travels up through
+// the structs and slices that hold it:
+//
+//	unsupported type for JSON encoding: chan in Jobs[2].Done
+
+// kindError reports a value of an unsupported kind at path
+type kindError struct {
+	op   string // "for JSON encoding", "for CBOR decoding"...
+	kind Kind
+	path string
+}
+
+// Error returns the message: unsupported type <op>: <kind> [in <path>]
+func (e *kindError) Error() string {
+	if e.path == "" {
+		return Err(errUnsupportedType, e.op+": "+e.kind.String()).Error()
+	}
+	return Err(errUnsupportedType, e.op+": "+e.kind.String(), "in", e.path).Error()
+}
+
+// unsupportedKindError returns the error for a value of kind k met by op
+func unsupportedKindError(op string, k Kind) error {
+	return &kindError{op: op, kind: k}
+}
+
+// prefixKindPath adds segment, a field name or an [index], in front of the
+// path of a kindError and returns err; other errors are returned unchanged
+func prefixKindPath(err error, segment string) error {
+	e, ok := err.(*kindError)
+	if !ok {
+		return err
+	}
+	switch {
+	case e.path == "":
+		e.path = segment
+	case e.path[0] == '[':
+		e.path = segment + e.path
+	default:
+		e.path = segment + "." + e.path
+	}
+	return err
+}
+
+// indexSegment returns the path segment of element i
+func indexSegment(i int) string {
+	return "[" + Convert(i).String() + "]"
+}
@@ -0,0 +1,109 @@
+package tinywodp
+
+import (
+	"unsafe"
+)
+
+// Map support for the reflection core
+// Map type descriptors extend the common type header with key and element types,
+// mirroring the runtime layout (internal/abi.MapType). Allocation, assignment,
+// length and iteration are delegated to the runtime hooks in reflect_map_go.go /
+// reflect_map_tinygo.go.
+
+// refMapType is the runtime descriptor of a map type
+type refMapType struct {
+	refType
+	key  *refType
+	elem *refType
+}
+
+// mapKey returns the key type of a map type, nil for other kinds
+func (t *refType) mapKey() *refType {
+	if t == nil || t.Kind() != tpMap {
+		return nil
+	}
+	return (*refMapType)(unsafe.Pointer(t)).key
+}
+
+// mapElem returns the element type of a map type, nil for other kinds
+func (t *refType) mapElem() *refType {
+	if t == nil || t.Kind() != tpMap {
+		return nil
+	}
+	return (*refMapType)(unsafe.Pointer(t)).elem
+}
+
+// refMapIsNil reports whether the map held by v has not been allocated
+func (v *refValue) refMapIsNil() bool {
+	return v.ptr == nil || *(*unsafe.Pointer)(v.ptr) == nil
+}
+
+// refNewValue allocates a zeroed, addressable value of type t
+func refNewValue(t *refType) (*refValue, error) {
+	ptr, err := runtimeNew(t)
+	if err != nil {
+		return nil, err
+	}
+	return &refValue{
+		separator: "_",
+		typ:       t,
+		ptr:       ptr,
+		flag:      refFlag(t.Kind()) | flagAddr,
+	}, nil
+}
+
+// refMakeMap allocates a new map for the addressable map value v
+func (v *refValue) refMakeMap(hint int) error {
+	m, err := runtimeMakeMap(v.typ, hint)
+	if err != nil {
+		return err
+	}
+	*(*unsafe.Pointer)(v.ptr) = m
+	return nil
+}
+
+// refSetMapIndex stores elem under key in the map held by v
+// Both key and elem must be addressable values of the map's key and element types
+func (v *refValue) refSetMapIndex(key, elem *refValue) error {
+	return runtimeMapAssign(v.typ, *(*unsafe.Pointer)(v.ptr), key.ptr, elem.ptr)
+}
+
+// refMapLen returns the number of entries of the map held by v, 0 when nil
+func (v *refValue) refMapLen() int {
+	if v.refMapIsNil() {
+		return 0
+	}
+	return runtimeMapLen(*(*unsafe.Pointer)(v.ptr))
+}
+
+// refMapRange calls fn with a copy of every key and element of the map held by
+// v, stopping at the first error
+// Entries added or removed by fn may or may not be visited, as with range
+func (v *refValue) refMapRange(fn func(key, elem *refValue) error) error {
+	if v.refMapIsNil() {
+		return nil
+	}
+	keyType, elemType := v.typ.mapKey(), v.typ.mapElem()
+	return runtimeMapRange(v.typ, *(*unsafe.Pointer)(v.ptr), func(kp, ep unsafe.Pointer) error {
+		key, err := refCopyValue(keyType, kp)
+		if err != nil {
+			return err
+		}
+		elem, err := refCopyValue(elemType, ep)
+		if err != nil {
+			return err
+		}
+		return fn(key, elem)
+	})
+}
+
+// refCopyValue returns an addressable copy of the value of type t at ptr, so
+// that later map writes cannot move it
+func refCopyValue(t *refType, ptr unsafe.Pointer) (*refValue, error) {
+	v, err := refNewValue(t)
+	if err != nil {
+		return nil, err
+	}
+	runtimeCopy(t, v.ptr, ptr)
+	return v, nil
+}
@@ -0,0 +1,87 @@
+//go:build !tinygo
+
+package tinywodp
+
+import (
+	"unsafe"
+)
+
+// Runtime hooks for map and value allocation on the standard Go runtime
+// These are the same entry points the reflect package uses
+
+//go:linkname reflect_makemap reflect.makemap
+func reflect_makemap(t unsafe.Pointer, cap int) unsafe.Pointer
+
+//go:linkname reflect_mapassign reflect.mapassign0
+func reflect_mapassign(t unsafe.Pointer, m unsafe.Pointer, key, elem unsafe.Pointer)
+
+//go:linkname reflect_maplen reflect.maplen
+func reflect_maplen(m unsafe.Pointer) int
+
+//go:linkname reflect_mapiterinit reflect.mapiterinit
+func reflect_mapiterinit(t unsafe.Pointer, m unsafe.Pointer, it *mapIter)
+
+//go:linkname reflect_mapiternext reflect.mapiternext
+func reflect_mapiternext(it *mapIter)
+
+//go:linkname reflect_typedmemmove reflect.typedmemmove
+func reflect_typedmemmove(t unsafe.Pointer, dst, src unsafe.Pointer)
+
+//go:linkname reflect_unsafe_New reflect.unsafe_New
+func reflect_unsafe_New(t unsafe.Pointer) unsafe.Pointer
+
+//go:linkname reflect_unsafe_NewArray reflect.unsafe_NewArray
+func reflect_unsafe_NewArray(t unsafe.Pointer, n int) unsafe.Pointer
+
+// runtimeNew allocates a zeroed value of type t tracked by the garbage collector
+func runtimeNew(t *refType) (unsafe.Pointer, error) {
+	return reflect_unsafe_New(unsafe.Pointer(t)), nil
+}
+
+// runtimeNewArray allocates n zeroed, contiguous values of type t tracked by the
+// garbage collector as one object
+func runtimeNewArray(t *refType, n int) (unsafe.Pointer, error) {
+	return reflect_unsafe_NewArray(unsafe.Pointer(t), n), nil
+}
+
+// runtimeMakeMap allocates a map of type t with room for hint elements
+func runtimeMakeMap(t *refType, hint int) (unsafe.Pointer, error) {
+	return reflect_makemap(unsafe.Pointer(t), hint), nil
+}
+
+// runtimeMapAssign copies key and elem into the map m of type t
+func runtimeMapAssign(t *refType, m, key, elem unsafe.Pointer) error {
+	reflect_mapassign(unsafe.Pointer(t), m, key, elem)
+	return nil
+}
+
+// runtimeMapLen returns the number of entries of the map m
+func runtimeMapLen(m unsafe.Pointer) int {
+	return reflect_maplen(m)
+}
+
+// mapIter is the iterator state shared with the runtime. Every runtime version
+// starts it with the current key and element pointers; the rest is opaque and
+// sized for the largest layout (the pre-swiss hiter)
+type mapIter struct {
+	key    unsafe.Pointer
+	elem   unsafe.Pointer
+	opaque [14]unsafe.Pointer
+}
+
+// runtimeMapRange calls fn with pointers to every key and element of the map m
+// of type t
+func runtimeMapRange(t *refType, m unsafe.Pointer, fn func(key, elem unsafe.Pointer) error) error {
+	var it mapIter
+	for reflect_mapiterinit(unsafe.Pointer(t), m, &it); it.key != nil; reflect_mapiternext(&it) {
+		if err := fn(it.key, it.elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runtimeCopy copies the value of type t at src to dst, with write barriers
+func runtimeCopy(t *refType, dst, src unsafe.Pointer) {
+	reflect_typedmemmove(unsafe.Pointer(t), dst, src)
+}
@@ -0,0 +1,3 @@
+//go:build !tinygo
+
+// Empty assembly file: allows the body-less linkname declarations in reflect_map_go.go
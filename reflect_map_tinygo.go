@@ -0,0 +1,67 @@
+//go:build tinygo
+
+package tinywodp
+
+import (
+	"unsafe"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+// TinyGo uses a different map runtime without the reflect hooks used on the
+// standard Go runtime, so map targets report a typed error instead
+
+//go:linkname runtime_alloc runtime.alloc
+func runtime_alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer
+
+// runtimeNew allocates a zeroed value of type t tracked by the garbage collector
+// A nil layout makes the collector scan the object conservatively, so pointers
+// stored in the value keep their targets alive; make([]byte) memory is never scanned
+func runtimeNew(t *refType) (unsafe.Pointer, error) {
+	size := t.Size()
+	if size == 0 {
+		return nil, Err(errUnsupportedType, "type has zero size")
+	}
+	return runtime_alloc(size, nil), nil
+}
+
+// runtimeNewArray allocates n zeroed, contiguous values of type t tracked by the
+// garbage collector as one object
+func runtimeNewArray(t *refType, n int) (unsafe.Pointer, error) {
+	size := t.Size() * uintptr(n)
+	if size == 0 {
+		return nil, Err(errUnsupportedType, "type has zero size")
+	}
+	return runtime_alloc(size, nil), nil
+}
+
+// runtimeMakeMap is not available on TinyGo
+func runtimeMakeMap(t *refType, hint int) (unsafe.Pointer, error) {
+	return nil, Err(errUnsupportedType, "map decoding is not available on TinyGo")
+}
+
+// runtimeMapAssign is not available on TinyGo
+func runtimeMapAssign(t *refType, m, key, elem unsafe.Pointer) error {
+	return Err(errUnsupportedType, "map decoding is not available on TinyGo")
+}
+
+// runtimeMapLen is not available on TinyGo
+func runtimeMapLen(m unsafe.Pointer) int {
+	return 0
+}
+
+// runtimeMapRange is not available on TinyGo
+func runtimeMapRange(t *refType, m unsafe.Pointer, fn func(key, elem unsafe.Pointer) error) error {
+	return Err(errUnsupportedType, "map iteration is not available on TinyGo")
+}
+
+// runtimeCopy copies the value of type t at src to dst
+func runtimeCopy(t *refType, dst, src unsafe.Pointer) {
+	copy(unsafe.Slice((*byte)(dst), t.Size()), unsafe.Slice((*byte)(src), t.Size()))
+}
+
+// refPtrTo is not available on TinyGo, so only value receivers and the top-level
+// decode target are checked for custom codecs
+func refPtrTo(t *refType) *refType {
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build !tinygo
+
+package tinywodp
+
+import (
+	"unsafe"
+)
+
+// Pointer type lookup on the standard Go runtime
+// Named types record the offset of their pointer type (internal/abi.Type.PtrToThis),
+// resolved through the same hook the reflect package uses
+
+// refTypeHeader mirrors the leading fields of internal/abi.Type
+type refTypeHeader struct {
+	size       uintptr
+	ptrBytes   uintptr
+	hash       uint32
+	tflag      uint8
+	align      uint8
+	fieldAlign uint8
+	kind       uint8
+	equal      func(unsafe.Pointer, unsafe.Pointer) bool
+	gcdata     *byte
+	str        int32
+	ptrToThis  int32
+}
+
+//go:linkname reflect_resolveTypeOff reflect.resolveTypeOff
+func reflect_resolveTypeOff(rtype unsafe.Pointer, off int32) unsafe.Pointer
+
+// refPtrTo returns the type *T for t, nil when the program never uses *T
+func refPtrTo(t *refType) *refType {
+	if t == nil {
+		return nil
+	}
+	off := (*refTypeHeader)(unsafe.Pointer(t)).ptrToThis
+	if off == 0 {
+		return nil
+	}
+	return (*refType)(reflect_resolveTypeOff(unsafe.Pointer(t), off))
+}
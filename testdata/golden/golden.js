@@ -0,0 +1,65 @@
+// Reference fixtures for WASM <-> server parity.
+//
+// Every *.json file in this directory is the exact output of JSON.stringify
+// for the documents below, i.e. what a browser sends to a tinywodp server.
+//
+//   node golden.js          regenerate the fixtures
+//   node golden.js check    verify fixtures still match JSON.stringify byte for byte
+//
+// json_golden_test.go decodes each fixture and, for the exact ones, checks that
+// tinywodp encodes the same value back to identical bytes.
+
+const fs = require("fs");
+const path = require("path");
+
+const documents = {
+  // Non-ASCII text is emitted raw; only quotes, backslashes and control
+  // characters are escaped, control characters as lowercase \u00xx
+  unicode: {
+    Name: "José Müller",
+    Emoji: "😀 👍🏽",
+    Cjk: "日本語テキスト",
+    Escaped: 'line\nbreak "quoted" \\ tab\t',
+    Control: "\u0001\u001f",
+  },
+
+  // Integers at the edge of float64 precision plus exponent forms.
+  // JavaScript numbers are float64, so 2^53+1 cannot be represented here.
+  numbers: {
+    MaxSafe: 9007199254740991,
+    MinSafe: -9007199254740991,
+    Zero: 0,
+    Half: 0.5,
+    Negative: -2.25,
+    Big: 1e21,
+    Tiny: 5e-324,
+  },
+
+  // Explicit nulls at several nesting levels
+  nulls: {
+    Name: "root",
+    Child: null,
+    Children: [null, { Name: "leaf", Child: null, Children: [] }],
+  },
+};
+
+const check = process.argv[2] === "check";
+let failed = false;
+
+for (const [name, doc] of Object.entries(documents)) {
+  const file = path.join(__dirname, name + ".json");
+  const encoded = JSON.stringify(doc);
+
+  if (!check) {
+    fs.writeFileSync(file, encoded);
+    continue;
+  }
+
+  const fixture = fs.readFileSync(file, "utf8");
+  if (fixture !== encoded || JSON.stringify(JSON.parse(fixture)) !== fixture) {
+    console.error(name + ".json does not match JSON.stringify output");
+    failed = true;
+  }
+}
+
+process.exit(failed ? 1 : 0);
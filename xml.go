@@ -0,0 +1,236 @@
+package tinywodp
+
+import (
+	. "github.com/cdvelop/tinystring"
+)
+
+// Minimal XML encoding
+// Element-per-field XML for the integrations that still require it (sitemaps,
+// RSS, SOAP-lite endpoints) without importing encoding/xml. The output is
+// written with the same reflection walk as JSON:
+//
+//	type URL struct {
+//		Loc     string `xml:"loc"`
+//		Updated string `xml:"lastmod"`
+//	}
+//	type URLSet struct {
+//		URLs []URL `xml:"url"`
+//	}
+//	// Convert(&set).XmlEncode("urlset") ->
+//	// <urlset><url><loc>https://a.io/</loc><lastmod>2024-01-02</lastmod></url></urlset>
+//
+// Element names come from the xml tag, else the JSON key rules (tiny name=,
+// json tag, then the Go name in the SetFieldNaming convention); `xml:"-"`
+// skips a field. Scalars, times and []byte are written as the text of their
+// JSON value, so formats match the JSON output. A slice repeats its field's element once per item; nil
+// pointers, interfaces and slices are left out. Maps and secure fields are rejected.
+// Attributes, namespaces and decoding are out of scope.
+
+// XmlHeader is the XML declaration to write before XmlEncode output when the
+// receiving side requires one
+const XmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+// XmlEncode converts the current value to XML inside an element named root
+//
+// Usage patterns:
+//
+//	data, err := Convert(&set).XmlEncode("urlset")
+//	_, err := Convert(&feed).XmlEncode("rss", httpResponseWriter)
+//
+// Like JsonEncode, it returns a copy of the output, or writes it to the
+// optional writer and returns nil bytes
+func (c *refValue) XmlEncode(root string, w ...writer) ([]byte, error) {
+	if !isXmlName(root) {
+		return nil, Err(errUnsupportedValue, "invalid XML root element name: "+root)
+	}
+	jh := getJsonH(c.separator)
+	defer putJsonH(jh)
+	return jh.encodeTo(c, w, func(dst []byte, v *refValue) ([]byte, error) {
+		return jh.appendXml(dst, root, v)
+	})
+}
+
+// appendXml appends c as the element named root
+func (jh *jsonH) appendXml(dst []byte, root string, c *refValue) ([]byte, error) {
+	switch c.vTpe {
+	case tpString:
+		return appendXmlText(dst, root, c.getString()), nil
+	case tpBool:
+		if c.getBool() {
+			return appendXmlText(dst, root, "true"), nil
+		}
+		return appendXmlText(dst, root, "false"), nil
+	case tpStrSlice:
+		return dst, Err(errUnsupportedType, "XML root must be a single value, got a slice")
+	}
+	v := c
+	for v.refIsValid() && v.refKind() == tpPointer {
+		v = v.refElem()
+	}
+	if v.refIsValid() && (v.refKind() == tpSlice || v.refKind() == tpArray) && !isJsonByteSlice(v) {
+		return dst, Err(errUnsupportedType, "XML root must be a single value, got a slice")
+	}
+	if !v.refIsValid() {
+		// A nil root is still a well-formed, empty document
+		return appendXmlText(dst, root, ""), nil
+	}
+	return jh.appendXmlElement(dst, root, v)
+}
+
+// appendXmlElement appends v as one element named name, or one per item for
+// slices; nil pointers, interfaces and slices append nothing
+func (jh *jsonH) appendXmlElement(dst []byte, name string, v *refValue) ([]byte, error) {
+	for v.refKind() == tpPointer || v.refKind() == tpInterface {
+		if v.refKind() == tpInterface {
+			inner := v.refInterfaceValue()
+			if inner == nil {
+				return dst, nil
+			}
+			v = refValueOf(inner)
+			continue
+		}
+		if v = v.refElem(); !v.refIsValid() {
+			return dst, nil
+		}
+	}
+
+	switch {
+	case isFormBytes(v.Type()) && isNilSlice(v):
+		return dst, nil
+	case isFormScalar(v.Type()) || customCodecFor(v) != 0 || isRawJSONType(v):
+		return jh.appendXmlScalar(dst, name, v)
+	case v.refKind() == tpSlice || v.refKind() == tpArray:
+		var err error
+		for i := range v.refLen() {
+			if dst, err = jh.appendXmlElement(dst, name, v.refIndex(i)); err != nil {
+				return dst, prefixKindPath(err, indexSegment(i))
+			}
+		}
+		return dst, nil
+	case v.refKind() == tpStruct:
+		return jh.appendXmlStruct(dst, name, v)
+	}
+	return dst, unsupportedKindError("for XML encoding", v.refKind())
+}
+
+// appendXmlStruct appends a struct as an element holding one element per field
+func (jh *jsonH) appendXmlStruct(dst []byte, name string, v *refValue) ([]byte, error) {
+	var structInfo refStructType
+	if err := structMetadataFor(v, &structInfo); err != nil {
+		return dst, err
+	}
+	if err := jh.enterJsonDepth(); err != nil {
+		return dst, err
+	}
+	defer jh.leaveJsonDepth()
+
+	dst = append(dst, '<')
+	dst = append(dst, name...)
+	dst = append(dst, '>')
+	for i := range v.refNumField() {
+		field := v.refField(i)
+		if !field.refIsValid() || !isExportedField(structInfo.fields[i].name) {
+			continue
+		}
+		info := &structInfo.fields[i]
+		fieldName := taggedFieldName(info.name, info.tag.Get("tiny"), info.tag.Get("json"), jh.jNaming)
+		if tag := info.tag.Get("xml"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if comma := indexByte(tag, ','); comma != -1 {
+				tag = tag[:comma]
+			}
+			if tag != "" {
+				fieldName = tag
+			}
+		}
+		if isEncryptedField(info.tag.Get("secure")) {
+			return dst, Err(errUnsupportedType, "secure field "+info.name+" for XML encoding")
+		}
+		if !isXmlName(fieldName) {
+			return dst, Err(errUnsupportedValue, "invalid XML element name: "+fieldName)
+		}
+
+		var err error
+		if dst, err = jh.appendXmlElement(dst, fieldName, field); err != nil {
+			return dst, prefixKindPath(err, info.name)
+		}
+	}
+	dst = append(dst, '<', '/')
+	dst = append(dst, name...)
+	return append(dst, '>'), nil
+}
+
+// appendXmlScalar appends a scalar element whose text is the value's JSON text,
+// without the quotes of JSON strings
+func (jh *jsonH) appendXmlScalar(dst []byte, name string, v *refValue) ([]byte, error) {
+	var err error
+	if jh.jEsc, err = jh.appendJsonFieldValue(jh.jEsc[:0], v); err != nil {
+		return dst, err
+	}
+	text := string(jh.jEsc)
+	if len(text) >= 2 && text[0] == '"' {
+		if text, err = jh.unescapeJsonString(text[1 : len(text)-1]); err != nil {
+			return dst, err
+		}
+	}
+	return appendXmlText(dst, name, text), nil
+}
+
+// appendXmlText appends <name>text</name> with text escaped
+func appendXmlText(dst []byte, name, text string) []byte {
+	dst = append(dst, '<')
+	dst = append(dst, name...)
+	dst = append(dst, '>')
+	dst = appendXmlEscaped(dst, text)
+	dst = append(dst, '<', '/')
+	dst = append(dst, name...)
+	return append(dst, '>')
+}
+
+// appendXmlEscaped appends s as XML character data: markup characters become
+// entities, \r a character reference so parsers keep it, and characters XML
+// does not allow (most controls, invalid UTF-8) U+FFFD
+func appendXmlEscaped(dst []byte, s string) []byte {
+	for _, r := range s {
+		switch {
+		case r == '&':
+			dst = append(dst, "&amp;"...)
+		case r == '<':
+			dst = append(dst, "&lt;"...)
+		case r == '>':
+			dst = append(dst, "&gt;"...)
+		case r == '"':
+			dst = append(dst, "&quot;"...)
+		case r == '\'':
+			dst = append(dst, "&apos;"...)
+		case r == '\r':
+			dst = append(dst, "&#xD;"...)
+		case r < 0x20 && r != '\t' && r != '\n', r == 0xfffe, r == 0xffff:
+			dst = appendRuneUTF8(dst, runeError)
+		case r < 0x80:
+			dst = append(dst, byte(r))
+		default:
+			// Invalid UTF-8 ranges as runeError, written as U+FFFD
+			dst = appendRuneUTF8(dst, r)
+		}
+	}
+	return dst
+}
+
+// isXmlName reports whether s is usable as an element name: an ASCII letter or
+// '_' followed by letters, digits, '_', '-', '.' or ':'
+func isXmlName(s string) bool {
+	if s == "" || !(isUpperASCII(s[0]) || isLowerASCII(s[0]) || s[0] == '_') {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if !(isUpperASCII(c) || isLowerASCII(c) || c >= '0' && c <= '9' ||
+			c == '_' || c == '-' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package tinywodp
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/cdvelop/tinystring"
+)
+
+func TestXmlEncodeSitemap(t *testing.T) {
+	clearRefStructsCache()
+
+	type URL struct {
+		Loc      string    `xml:"loc"`
+		Modified time.Time `xml:"lastmod"`
+		Priority *float64  `xml:"priority,omitempty"`
+		Internal string    `xml:"-"`
+	}
+	type URLSet struct {
+		URLs []URL `xml:"url"`
+	}
+
+	priority := 0.8
+	set := URLSet{URLs: []URL{
+		{Loc: "https://a.io/?q=1&p=2", Modified: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Priority: &priority, Internal: "x"},
+		{Loc: "https://a.io/b", Modified: time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+	}}
+	out, err := Convert(&set).XmlEncode("urlset")
+	if err != nil {
+		t.Fatalf("XmlEncode returned error: %v", err)
+	}
+	expected := "<urlset>" +
+		"<url><loc>https://a.io/?q=1&amp;p=2</loc><lastmod>2024-01-02T00:00:00Z</lastmod><priority>0.8</priority></url>" +
+		"<url><loc>https://a.io/b</loc><lastmod>2024-02-03T00:00:00Z</lastmod></url>" +
+		"</urlset>"
+	if string(out) != expected {
+		t.Errorf("XmlEncode = %s, expected %s", out, expected)
+	}
+
+	var written []byte
+	w := &testWriter{writeFunc: func(p []byte) (int, error) {
+		written = append(written, p...)
+		return len(p), nil
+	}}
+	if out, err := Convert(&set).XmlEncode("urlset", w); err != nil || out != nil || string(written) != expected {
+		t.Errorf("XmlEncode(w) wrote %s, returned %v, %v", written, out, err)
+	}
+}
+
+func TestXmlEncodeValues(t *testing.T) {
+	clearRefStructsCache()
+
+	type Item struct {
+		Title   string `json:"title"`
+		Count   uint16
+		Active  bool
+		Raw     []byte
+		Tags    []string
+		Nested  *Item
+		Comment any
+	}
+
+	in := Item{Title: `<b>"Tom" & 'Jerry'</b>` + "\r\x01", Count: 3, Active: true, Raw: []byte("hi"),
+		Tags: []string{"a", "b"}, Nested: &Item{Title: "inner"}, Comment: 1.5}
+	out, err := Convert(in).XmlEncode("item")
+	if err != nil {
+		t.Fatalf("XmlEncode returned error: %v", err)
+	}
+	expected := "<item><title>&lt;b&gt;&quot;Tom&quot; &amp; &apos;Jerry&apos;&lt;/b&gt;&#xD;�</title>" +
+		"<Count>3</Count><Active>true</Active><Raw>aGk=</Raw><Tags>a</Tags><Tags>b</Tags>" +
+		"<Nested><title>inner</title><Count>0</Count><Active>false</Active></Nested>" +
+		"<Comment>1.5</Comment></item>"
+	if string(out) != expected {
+		t.Errorf("XmlEncode = %s, expected %s", out, expected)
+	}
+
+	if out, err := Convert("a<b").XmlEncode("note"); err != nil || string(out) != "<note>a&lt;b</note>" {
+		t.Errorf("XmlEncode(string) = %s, %v", out, err)
+	}
+
+	type Bad struct {
+		M map[string]int
+	}
+	tests := []struct {
+		name  string
+		input any
+		root  string
+	}{
+		{"map field", &Bad{M: map[string]int{}}, "bad"},
+		{"slice root", []int{1}, "list"},
+		{"invalid root name", &Item{}, "1item"},
+		{"empty root name", &Item{}, ""},
+	}
+	for _, tt := range tests {
+		if _, err := Convert(tt.input).XmlEncode(tt.root); err == nil {
+			t.Errorf("%s: XmlEncode should fail", tt.name)
+		}
+	}
+}